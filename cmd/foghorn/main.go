@@ -1,20 +1,39 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
 	"github.com/jenkins-x/lighthouse/pkg/clients"
+	"github.com/jenkins-x/lighthouse/pkg/events"
+	"github.com/jenkins-x/lighthouse/pkg/flakes"
 	"github.com/jenkins-x/lighthouse/pkg/foghorn"
+	"github.com/jenkins-x/lighthouse/pkg/interrupts"
+	"github.com/jenkins-x/lighthouse/pkg/jobadmission"
+	"github.com/jenkins-x/lighthouse/pkg/jobhistory"
 	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
 type options struct {
-	namespace string
+	namespace    string
+	historyStore string
+	historyDir   string
+	port         int
+	drainTimeout time.Duration
+
+	admissionCertDir string
+	admissionPort    int
+
+	events events.Options
 }
 
 func (o *options) Validate() error {
@@ -24,11 +43,24 @@ func (o *options) Validate() error {
 func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	var o options
 	fs.StringVar(&o.namespace, "namespace", "", "The namespace to listen in")
+	fs.StringVar(&o.historyStore, "history-store", "", "The store recording the history of completed jobs, either memory or file. The history is not recorded if empty")
+	fs.StringVar(&o.historyDir, "history-dir", "", "The directory the file history store keeps its records in")
+	fs.StringVar(&o.admissionCertDir, "admission-cert-dir", "", "The directory holding the tls.crt and tls.key of the admission webhooks which default and validate LighthouseJobs. The webhooks are not served if empty")
+	fs.IntVar(&o.admissionPort, "admission-port", 9443, "The port the admission webhooks are served on")
+	fs.DurationVar(&o.drainTimeout, "drain-timeout", 25*time.Second, "How long to wait for the commit statuses being reported when shutting down. Should be less than the termination grace period of the pod.")
+	fs.IntVar(&o.port, "port", 8888, "The port the job history, flakes and log level endpoints are served on")
+	fs.IntVar(&o.port, "history-port", 8888, "Deprecated: use --port instead")
+	o.events.AddFlags(fs)
 
 	err := fs.Parse(args)
 	if err != nil {
 		logrus.WithError(err).Fatal("Invalid options")
 	}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "history-port" {
+			logrus.Warn("--history-port is deprecated, use --port instead")
+		}
+	})
 
 	return o
 }
@@ -46,15 +78,26 @@ func main() {
 		logrus.WithError(err).Fatal("Invalid options")
 	}
 
+	publishers, err := o.events.Publishers()
+	if err != nil {
+		logrus.WithError(err).Fatal("Unable to set up the event publishers")
+	}
+	events.SetPublishers("foghorn", publishers...)
+
 	cfg, err := clients.GetConfig("", "")
 	if err != nil {
 		logrus.WithError(err).Fatal("Could not create kubeconfig")
 	}
 
-	mgr, err := ctrl.NewManager(cfg, ctrl.Options{Scheme: scheme, Namespace: o.namespace})
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{Scheme: scheme, Namespace: o.namespace, Port: o.admissionPort, CertDir: o.admissionCertDir})
 	if err != nil {
 		logrus.WithError(err).Fatal("Unable to start manager")
 	}
+	if o.admissionCertDir != "" {
+		admissionServer := mgr.GetWebhookServer()
+		admissionServer.Register(jobadmission.DefaultPath, &webhook.Admission{Handler: jobadmission.NewDefaulter(o.namespace)})
+		admissionServer.Register(jobadmission.ValidatePath, &webhook.Admission{Handler: jobadmission.NewValidator()})
+	}
 
 	reconciler, err := foghorn.NewLighthouseJobReconciler(mgr.GetClient(), mgr.GetScheme(), o.namespace)
 	if err != nil {
@@ -66,7 +109,28 @@ func main() {
 
 	defer reconciler.ConfigMapWatcher.Stop()
 
+	mux := http.NewServeMux()
+	if o.historyStore != "" {
+		store, err := jobhistory.NewStore(o.historyStore, o.historyDir)
+		if err != nil {
+			logrus.WithError(err).Fatal("Unable to create job history store")
+		}
+		reconciler.History = store
+		mux.Handle("/history", &jobhistory.Handler{Store: store})
+	}
+	reconciler.Flakes = flakes.NewTracker()
+	mux.Handle("/flakes", reconciler.Flakes)
+	mux.Handle(logrusutil.LevelPath, logrusutil.LevelHandler{})
+	server := &http.Server{Addr: ":" + strconv.Itoa(o.port), Handler: mux}
+	interrupts.ListenAndServe(server, 5*time.Second)
+
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		logrus.WithError(err).Fatal("Problem running manager")
 	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.drainTimeout)
+	defer cancel()
+	if err := reconciler.Drain(ctx); err != nil {
+		logrus.WithError(err).Warn("exiting before all commit statuses were reported")
+	}
 }
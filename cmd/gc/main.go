@@ -6,24 +6,31 @@ import (
 	"os"
 	"time"
 
-	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
 	clientset "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned"
-	lhclient "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/typed/lighthouse/v1alpha1"
 	"github.com/jenkins-x/lighthouse/pkg/clients"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/gc"
+	"github.com/jenkins-x/lighthouse/pkg/interrupts"
 	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
+	"github.com/jenkins-x/lighthouse/pkg/metrics"
 	"github.com/sirupsen/logrus"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	tektonclient "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
 )
 
 type options struct {
-	namespace string
-	maxAge    time.Duration
+	namespace     string
+	gcOptions     gc.Options
+	resyncPeriod  time.Duration
+	buildClusters string
 }
 
 func (o *options) Validate() error {
 	if o.namespace == "" {
 		return fmt.Errorf("no --namespace given")
 	}
+	if o.gcOptions.MaxRetainedPerJob < 0 {
+		return fmt.Errorf("--max-per-job must be a non-negative number")
+	}
 	return nil
 }
 
@@ -31,8 +38,14 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	logrusutil.ComponentInit("lighthouse-gc-jobs")
 
 	var o options
-	fs.DurationVar(&o.maxAge, "max-age", 7*24*time.Hour, "Maximum age to keep LighthouseJobs.")
+	fs.DurationVar(&o.gcOptions.MaxJobAge, "max-age", 7*24*time.Hour, "Maximum age to keep LighthouseJobs.")
+	fs.DurationVar(&o.gcOptions.SucceededTTL, "succeeded-ttl", 0, "How long to keep the PipelineRuns of succeeded jobs after they complete, 0 keeps them until their LighthouseJob is deleted.")
+	fs.DurationVar(&o.gcOptions.FailedTTL, "failed-ttl", 0, "How long to keep the PipelineRuns of failed jobs after they complete, 0 keeps them until their LighthouseJob is deleted.")
+	fs.DurationVar(&o.gcOptions.AbortedTTL, "aborted-ttl", 0, "How long to keep the PipelineRuns of aborted jobs after they complete, 0 keeps them until their LighthouseJob is deleted.")
+	fs.IntVar(&o.gcOptions.MaxRetainedPerJob, "max-per-job", 0, "Maximum number of completed PipelineRuns to keep for each job of a repository, 0 means no limit.")
+	fs.DurationVar(&o.resyncPeriod, "resync-period", 0, "How often to collect garbage when running as a controller, 0 collects once and exits.")
 	fs.StringVar(&o.namespace, "namespace", "", "The namespace to listen in")
+	fs.StringVar(&o.buildClusters, "build-cluster-kubeconfig", "", "Path to a kubeconfig whose contexts are the build clusters jobs can run in, their PipelineRuns are collected too")
 
 	err := fs.Parse(args)
 	if err != nil {
@@ -56,36 +69,37 @@ func main() {
 	if err != nil {
 		logrus.WithError(err).Fatal("Could not create Lighthouse API client")
 	}
-
-	lhInterface := lhClient.LighthouseV1alpha1().LighthouseJobs(o.namespace)
-
-	jobList, err := lhInterface.List(metav1.ListOptions{})
+	tektonClient, err := tektonclient.NewForConfig(cfg)
 	if err != nil {
-		logrus.WithError(err).Fatalf("Could not list LighthouseJobs in namespace %s", o.namespace)
+		logrus.WithError(err).Fatal("Could not create Tekton API client")
 	}
 
-	now := time.Now()
-
-	for _, job := range jobList.Items {
-		j := job
-		completionTime := j.Status.CompletionTime
-		if completionTime != nil && completionTime.Add(o.maxAge).Before(now) {
-			// The job completed at least maxAge ago, so delete it.
-			err = deleteLighthouseJob(lhInterface, &j)
-			if err != nil {
-				logrus.WithError(err).Fatalf("Failed to delete LighthouseJob %s", j.Name)
-			}
-		} else if completionTime == nil && j.Status.StartTime.Add(o.maxAge).Before(now) {
-			// The job never completed, but was created at least maxAge ago, so delete it.
-			err = deleteLighthouseJob(lhInterface, &j)
-			if err != nil {
-				logrus.WithError(err).Fatalf("Failed to delete LighthouseJob %s", j.Name)
-			}
+	collector := gc.NewCollector(lhClient, tektonClient, o.namespace, o.gcOptions)
+	buildClusters, err := clients.LoadBuildClusterConfigs(o.buildClusters)
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not load build clusters")
+	}
+	for alias, clusterCfg := range buildClusters {
+		clusterClient, err := tektonclient.NewForConfig(clusterCfg)
+		if err != nil {
+			logrus.WithError(err).Fatalf("Could not create Tekton API client for build cluster %s", alias)
 		}
+		collector.AddBuildCluster(alias, clusterClient)
+	}
+	if o.resyncPeriod == 0 {
+		if err := collector.Clean(time.Now()); err != nil {
+			logrus.WithError(err).Fatal("Failed to collect garbage")
+		}
+		return
 	}
-}
 
-func deleteLighthouseJob(lhInterface lhclient.LighthouseJobInterface, lhJob *v1alpha1.LighthouseJob) error {
-	logrus.Infof("Deleting LighthouseJob %s", lhJob.Name)
-	return lhInterface.Delete(lhJob.Name, metav1.NewDeleteOptions(0))
+	metrics.ExposeMetrics("gc", lighthouse.PushGateway{})
+	interrupts.TickLiteral(func() {
+		start := time.Now()
+		if err := collector.Clean(start); err != nil {
+			logrus.WithError(err).Error("Failed to collect garbage")
+		}
+		logrus.WithField("duration", time.Since(start).String()).Info("Garbage collection complete")
+	}, o.resyncPeriod)
+	interrupts.WaitForGracefulShutdown()
 }
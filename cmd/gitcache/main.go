@@ -0,0 +1,146 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/gitcache"
+	"github.com/jenkins-x/lighthouse/pkg/health"
+	"github.com/jenkins-x/lighthouse/pkg/interrupts"
+	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
+	"github.com/jenkins-x/lighthouse/pkg/metrics"
+	"github.com/jenkins-x/lighthouse/pkg/watcher"
+	"github.com/sirupsen/logrus"
+)
+
+type options struct {
+	port            int
+	namespace       string
+	maintainPeriod  time.Duration
+	tokenFile       string
+	authTokenFile   string
+	gitCacheOptions gitcache.Options
+}
+
+func (o *options) Validate() error {
+	if o.gitCacheOptions.Dir == "" {
+		return fmt.Errorf("no --dir given")
+	}
+	if o.gitCacheOptions.UpstreamURL == "" {
+		return fmt.Errorf("no --upstream-url given")
+	}
+	if o.maintainPeriod <= 0 {
+		return fmt.Errorf("--maintain-period must be positive")
+	}
+	return nil
+}
+
+func gatherOptions(fs *flag.FlagSet, args ...string) options {
+	logrusutil.ComponentInit("lighthouse-gitcache")
+
+	upstreamURL := os.Getenv("GIT_SERVER")
+	if upstreamURL == "" {
+		upstreamURL = "https://github.com"
+	}
+
+	var o options
+	fs.IntVar(&o.port, "port", 8080, "Port to serve the mirrors on.")
+	fs.StringVar(&o.namespace, "namespace", "", "The namespace to read the configuration from.")
+	fs.StringVar(&o.gitCacheOptions.Dir, "dir", "/var/cache/git", "Directory the mirrors are kept in, typically a persistent volume.")
+	fs.StringVar(&o.gitCacheOptions.UpstreamURL, "upstream-url", upstreamURL, "Base URL of the git server the repositories are mirrored from, defaults to the $GIT_SERVER environment variable.")
+	fs.StringVar(&o.gitCacheOptions.User, "user", os.Getenv("GIT_USER"), "User to authenticate to the git server with.")
+	fs.StringVar(&o.tokenFile, "token-file", "", "File holding the token to authenticate to the git server with, defaults to the $GIT_TOKEN environment variable.")
+	fs.StringVar(&o.authTokenFile, "auth-token-file", "", "File holding the token the clients authenticate to the cache with, defaults to the $GITCACHE_TOKEN environment variable.")
+	fs.DurationVar(&o.gitCacheOptions.MinFetchInterval, "min-fetch-interval", 10*time.Second, "Minimum time between two fetches of a mirror when it is cloned from, 0 fetches on every clone. A mirror missing a wanted revision is always fetched.")
+	fs.DurationVar(&o.gitCacheOptions.PruneAfter, "prune-after", 7*24*time.Hour, "How long a mirror is kept once it was last cloned from, 0 keeps it forever.")
+	fs.DurationVar(&o.maintainPeriod, "maintain-period", 5*time.Minute, "How often the mirrors are fetched and the unused ones pruned.")
+
+	err := fs.Parse(args)
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+
+	return o
+}
+
+func main() {
+	o := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
+	if err := o.Validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+
+	o.gitCacheOptions.Token = readToken(o.tokenFile, "GIT_TOKEN")
+	o.gitCacheOptions.AuthToken = readToken(o.authTokenFile, "GITCACHE_TOKEN")
+
+	configAgent := &config.Agent{}
+	cfgMapWatcher, err := watcher.SetupConfigMapWatchers(o.namespace, configAgent, nil)
+	if err != nil {
+		logrus.WithError(err).Fatal("error starting config map watcher")
+	}
+	defer cfgMapWatcher.Stop()
+	o.gitCacheOptions.Allowed = func(repo string) bool {
+		return configured(configAgent.Config(), repo)
+	}
+
+	cache, err := gitcache.NewCache(o.gitCacheOptions)
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not create git cache")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", cache)
+	mux.Handle(health.LivenessPath, &health.Handler{})
+	mux.Handle(health.ReadinessPath, &health.Handler{})
+	server := &http.Server{Addr: fmt.Sprintf(":%d", o.port), Handler: mux}
+
+	metrics.ExposeMetrics("gitcache", lighthouse.PushGateway{})
+	interrupts.TickLiteral(func() {
+		start := time.Now()
+		if err := cache.Maintain(); err != nil {
+			logrus.WithError(err).Error("Failed to maintain the mirrors")
+		}
+		logrus.WithField("duration", time.Since(start).String()).Info("Mirror maintenance complete")
+	}, o.maintainPeriod)
+	interrupts.ListenAndServe(server, 10*time.Second)
+	interrupts.WaitForGracefulShutdown()
+}
+
+// readToken reads the token from the file if given or from the environment variable otherwise
+func readToken(file, envVar string) string {
+	if file == "" {
+		return os.Getenv(envVar)
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		logrus.WithError(err).Fatalf("Could not read token file %s", file)
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// configured returns whether the repository is part of the configuration, either through its jobs, in repository
+// configuration or the keeper queries, so that the cache only mirrors the repositories Lighthouse builds
+func configured(cfg *config.Config, repo string) bool {
+	if cfg == nil {
+		return false
+	}
+	if len(cfg.Presubmits[repo]) > 0 || len(cfg.Postsubmits[repo]) > 0 || cfg.InRepoConfigEnabled(repo) {
+		return true
+	}
+	idx := strings.LastIndex(repo, "/")
+	if idx < 0 {
+		return false
+	}
+	for _, query := range cfg.Keeper.Queries {
+		if query.ForRepo(repo[:idx], repo[idx+1:]) {
+			return true
+		}
+	}
+	return false
+}
@@ -23,19 +23,26 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/jenkins-x/lighthouse/pkg/admin"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	"github.com/jenkins-x/lighthouse/pkg/clients"
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	configutil "github.com/jenkins-x/lighthouse/pkg/config/util"
 	"github.com/jenkins-x/lighthouse/pkg/interrupts"
 	"github.com/jenkins-x/lighthouse/pkg/jobutil"
 	"github.com/jenkins-x/lighthouse/pkg/keeper"
 	"github.com/jenkins-x/lighthouse/pkg/keeper/githubapp"
+	"github.com/jenkins-x/lighthouse/pkg/leaderelection"
 	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
 	"github.com/jenkins-x/lighthouse/pkg/metrics"
 	"github.com/jenkins-x/lighthouse/pkg/util"
 	"github.com/jenkins-x/lighthouse/pkg/watcher"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 )
 
+const leaderElectionLease = "lighthouse-keeper"
+
 type options struct {
 	port int
 
@@ -46,7 +53,8 @@ type options struct {
 	gitKind       string
 	namespace     string
 
-	runOnce bool
+	runOnce     bool
+	leaderElect bool
 
 	maxRecordsPerPool int
 	// historyURI where Keeper should store its action history.
@@ -62,6 +70,8 @@ type options struct {
 	// a) the gcs credentials can write to this bucket
 	// b) the default acls do not expose any private info
 	statusURI string
+
+	audit audit.Options
 }
 
 func (o *options) Validate() error {
@@ -77,11 +87,13 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	fs.StringVar(&o.gitServerURL, "git-url", "", "The git provider URL")
 	fs.StringVar(&o.gitKind, "git-kind", "", "The git provider kind (e.g. github, gitlab, bitbucketserver")
 	fs.BoolVar(&o.runOnce, "run-once", false, "If true, run only once then quit.")
+	fs.BoolVar(&o.leaderElect, "leader-elect", false, "If true, only sync when elected as the leader so that multiple replicas can be run.")
 
 	fs.IntVar(&o.maxRecordsPerPool, "max-records-per-pool", 1000, "The maximum number of history records stored for an individual Keeper pool.")
 	fs.StringVar(&o.historyURI, "history-uri", "", "The /local/path or gs://path/to/object to store keeper action history. GCS writes will use the default object ACL for the bucket")
 	fs.StringVar(&o.statusURI, "status-path", "", "The /local/path or gs://path/to/object to store status controller state. GCS writes will use the default object ACL for the bucket.")
 	fs.StringVar(&o.namespace, "namespace", "", "The namespace to listen in")
+	o.audit.AddFlags(fs)
 
 	err := fs.Parse(args)
 	if err != nil {
@@ -103,6 +115,12 @@ func main() {
 		logrus.WithError(err).Fatal("Invalid options")
 	}
 
+	auditSinks, err := o.audit.Sinks()
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to set up the audit log")
+	}
+	audit.SetSinks("keeper", auditSinks...)
+
 	configAgent := &config.Agent{}
 	cfgMapWatcher, err := watcher.SetupConfigMapWatchers(o.namespace, configAgent, nil)
 	if err != nil {
@@ -144,21 +162,53 @@ func main() {
 	defer c.Shutdown()
 	http.Handle("/", c)
 	http.Handle("/history", c.GetHistory())
+	dashboard := keeper.NewDashboard(c, configAgent.Config)
+	http.HandleFunc(keeper.DashboardPath, dashboard.ServeHTML)
+	http.HandleFunc(keeper.DashboardAPIPath, dashboard.ServeJSON)
+	http.HandleFunc(keeper.MaintenanceAPIPath, keeper.MaintenanceHandler(configAgent.Config))
+
+	_, kubeClient, _, _, err := clients.GetAPIClients()
+	if err != nil {
+		logrus.WithError(err).Fatal("Error creating kubernetes client.")
+	}
+	adminToken := util.AdminToken()
+	pausesHandler := &admin.Handler{
+		Store: admin.NewConfigMapStore(kubeClient.CoreV1().ConfigMaps(o.namespace)),
+		Token: adminToken,
+	}
+	http.Handle(admin.PausesAPIPath, pausesHandler)
+	http.Handle(admin.PausesAPIPath+"/", pausesHandler)
+	prometheus.MustRegister(admin.NewPausesCollector(configAgent.Config))
+	// keeper is exposed with its dashboard so changing its log level requires the admin token
+	http.Handle(logrusutil.LevelPath, logrusutil.LevelHandler{Authorize: func(r *http.Request) bool {
+		return admin.Authorized(r, adminToken)
+	}})
 	server := &http.Server{Addr: ":" + strconv.Itoa(o.port)}
 
-	start := time.Now()
-	sync(c)
 	if o.runOnce {
+		sync(c)
 		return
 	}
 
-	// run the controller, but only after one sync period expires after our first run
-	time.Sleep(time.Until(start.Add(cfg().Keeper.SyncPeriod)))
-	interrupts.Tick(func() {
+	runController := func() {
+		start := time.Now()
 		sync(c)
-	}, func() time.Duration {
-		return cfg().Keeper.SyncPeriod
-	})
+
+		// run the controller, but only after one sync period expires after our first run
+		time.Sleep(time.Until(start.Add(cfg().Keeper.SyncPeriod)))
+		interrupts.Tick(func() {
+			sync(c)
+		}, func() time.Duration {
+			return cfg().Keeper.SyncPeriod
+		})
+	}
+	if o.leaderElect {
+		if err := leaderelection.Run(kubeClient, o.namespace, leaderElectionLease, runController); err != nil {
+			logrus.WithError(err).Fatal("Error starting leader election.")
+		}
+	} else {
+		runController()
+	}
 
 	// Push metrics to the configured prometheus pushgateway endpoint or serve them
 	gateway := cfg().PushGateway
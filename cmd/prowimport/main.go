@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/prowimport"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	// registers the plugins Lighthouse implements
+	_ "github.com/jenkins-x/lighthouse/pkg/webhook"
+)
+
+type options struct {
+	configPath  string
+	pluginsPath string
+	outputDir   string
+}
+
+func (o *options) Validate() error {
+	if o.configPath == "" && o.pluginsPath == "" {
+		return errors.New("at least one of --config or --plugins is required")
+	}
+	return nil
+}
+
+func gatherOptions(fs *flag.FlagSet, args ...string) options {
+	var o options
+	fs.StringVar(&o.configPath, "config", "", "Path to the Prow config.yaml to convert")
+	fs.StringVar(&o.pluginsPath, "plugins", "", "Path to the Prow plugins.yaml to convert")
+	fs.StringVar(&o.outputDir, "output-dir", ".", "Directory the Lighthouse config.yaml and plugins.yaml are written to")
+
+	err := fs.Parse(args)
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+
+	return o
+}
+
+func main() {
+	o := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
+	if err := o.Validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+
+	failed := false
+	if o.configPath != "" {
+		if err := convert(o.configPath, filepath.Join(o.outputDir, "config.yaml"), prowimport.ConvertConfig); err != nil {
+			logrus.WithError(err).Error("Failed to convert the Prow config")
+			failed = true
+		}
+	}
+	if o.pluginsPath != "" {
+		knownPlugins := sets.NewString()
+		for name := range plugins.HelpProviders() {
+			knownPlugins.Insert(name)
+		}
+		convertPlugins := func(data []byte) ([]byte, []string, error) {
+			return prowimport.ConvertPlugins(data, knownPlugins)
+		}
+		if err := convert(o.pluginsPath, filepath.Join(o.outputDir, "plugins.yaml"), convertPlugins); err != nil {
+			logrus.WithError(err).Error("Failed to convert the Prow plugins")
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// convert converts the file at the given path, reporting the settings which were dropped, and writes the result even
+// if it is invalid so that it can be fixed by hand
+func convert(path, outputPath string, converter func([]byte) ([]byte, []string, error)) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", path)
+	}
+	out, warnings, convertErr := converter(data)
+	for _, w := range warnings {
+		logrus.WithField("file", path).Warn(w)
+	}
+	if out != nil {
+		if err := ioutil.WriteFile(outputPath, out, 0644); err != nil {
+			return errors.Wrapf(err, "failed to write %s", outputPath)
+		}
+		logrus.Infof("Wrote %s with %d unsupported settings dropped", outputPath, len(warnings))
+	}
+	return convertErr
+}
@@ -6,11 +6,14 @@ import (
 
 	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
 	"github.com/jenkins-x/lighthouse/pkg/clients"
+	"github.com/jenkins-x/lighthouse/pkg/config"
 	tektonengine "github.com/jenkins-x/lighthouse/pkg/engines/tekton"
 	"github.com/jenkins-x/lighthouse/pkg/interrupts"
 	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
+	"github.com/jenkins-x/lighthouse/pkg/watcher"
 	"github.com/sirupsen/logrus"
 	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
@@ -19,6 +22,8 @@ type options struct {
 	namespace         string
 	dashboardURL      string
 	dashboardTemplate string
+	strictSecrets     bool
+	buildClusters     string
 }
 
 func (o *options) Validate() error {
@@ -30,6 +35,8 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	fs.StringVar(&o.namespace, "namespace", "", "The namespace to listen in")
 	fs.StringVar(&o.dashboardURL, "dashboard-url", "", "The base URL for the Tekton Dashboard to link to for build reports")
 	fs.StringVar(&o.dashboardTemplate, "dashboard-template", "", "The template expression for generating the URL to the build report based on the PipelineRun parameters. If not specified defaults to $LIGHTHOUSE_DASHBOARD_TEMPLATE")
+	fs.BoolVar(&o.strictSecrets, "strict-secrets", false, "Fail jobs referencing secrets in env_from_secrets which do not exist rather than only logging a warning")
+	fs.StringVar(&o.buildClusters, "build-cluster-kubeconfig", "", "Path to a kubeconfig whose contexts are the build clusters jobs can run in, keyed by the alias used in the cluster field of jobs")
 	err := fs.Parse(args)
 	if err != nil {
 		logrus.WithError(err).Fatal("Invalid options")
@@ -48,6 +55,9 @@ func main() {
 	if err := pipelinev1beta1.AddToScheme(scheme); err != nil {
 		logrus.WithError(err).Fatal("Failed to register scheme")
 	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		logrus.WithError(err).Fatal("Failed to register scheme")
+	}
 
 	o := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
 	if err := o.Validate(); err != nil {
@@ -64,7 +74,24 @@ func main() {
 		logrus.WithError(err).Fatal("Unable to start manager")
 	}
 
-	reconciler := tektonengine.NewLighthouseJobReconciler(mgr.GetClient(), mgr.GetAPIReader(), mgr.GetScheme(), o.dashboardURL, o.dashboardTemplate, o.namespace)
+	configAgent := &config.Agent{}
+	cfgMapWatcher, err := watcher.SetupConfigMapWatchers(o.namespace, configAgent, nil)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error starting config map watcher")
+	}
+	defer cfgMapWatcher.Stop()
+
+	reconciler := tektonengine.NewLighthouseJobReconciler(mgr.GetClient(), mgr.GetAPIReader(), mgr.GetScheme(), o.dashboardURL, o.dashboardTemplate, o.namespace, o.strictSecrets)
+	reconciler.Config = configAgent.Config
+	buildClusters, err := clients.LoadBuildClusterConfigs(o.buildClusters)
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not load build clusters")
+	}
+	for alias, clusterCfg := range buildClusters {
+		if err := reconciler.AddBuildCluster(alias, clusterCfg); err != nil {
+			logrus.WithError(err).Fatalf("Unable to add build cluster %s", alias)
+		}
+	}
 	if err = reconciler.SetupWithManager(mgr); err != nil {
 		logrus.WithError(err).Fatal("Unable to create controller")
 	}
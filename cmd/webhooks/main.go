@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	"github.com/jenkins-x/lighthouse/pkg/events"
+	"github.com/jenkins-x/lighthouse/pkg/health"
 	"github.com/jenkins-x/lighthouse/pkg/interrupts"
 	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
 	"github.com/jenkins-x/lighthouse/pkg/webhook"
@@ -23,12 +28,20 @@ type options struct {
 	bindAddress string
 	path        string
 	port        int
+	adminPort   int
 	jsonLog     bool
 
+	drainTimeout time.Duration
+	checkTekton  bool
+
 	namespace      string
 	pluginFilename string
 	configFilename string
 	botName        string
+
+	audit   audit.Options
+	events  events.Options
+	workers webhook.WorkerOptions
 }
 
 func (o *options) Validate() error {
@@ -39,6 +52,7 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	var o options
 	fs.BoolVar(&o.jsonLog, "json", true, "Enable JSON logging")
 	fs.IntVar(&o.port, "port", 8080, "The TCP port to listen on.")
+	fs.IntVar(&o.adminPort, "admin-port", 8081, "The TCP port the admin endpoints, e.g. the log level, are served on. Disabled if 0.")
 	fs.StringVar(&o.bindAddress, "bind", "",
 		"The interface address to bind to (by default, will listen on all interfaces/addresses).")
 	fs.StringVar(&o.path, "path", "/hook",
@@ -47,6 +61,11 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	fs.StringVar(&o.configFilename, "config-file", "", "7Path to the config.yaml file. If not specified it is loaded from the 'config' ConfigMap")
 	fs.StringVar(&o.botName, "bot-name", "", "The name of the bot user to run as. Defaults to $GIT_USER if not specified.")
 	fs.StringVar(&o.namespace, "namespace", "", "The namespace to listen in")
+	fs.BoolVar(&o.checkTekton, "check-tekton-crds", false, "Whether the readiness endpoint checks that the Tekton CRDs are installed.")
+	fs.DurationVar(&o.drainTimeout, "drain-timeout", 25*time.Second, "How long to wait for the in-flight events to be processed when shutting down. Should be less than the termination grace period of the pod.")
+	o.audit.AddFlags(fs)
+	o.events.AddFlags(fs)
+	o.workers.AddFlags(fs)
 
 	err := fs.Parse(args)
 	if err != nil {
@@ -58,8 +77,6 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 
 // Entrypoint for the command
 func main() {
-	defer interrupts.WaitForGracefulShutdown()
-
 	o := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
 	if err := o.Validate(); err != nil {
 		logrus.WithError(err).Fatal("Invalid options")
@@ -69,6 +86,18 @@ func main() {
 		logrus.SetFormatter(logrusutil.CreateDefaultFormatter())
 	}
 
+	auditSinks, err := o.audit.Sinks()
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to set up the audit log")
+	}
+	audit.SetSinks("webhooks", auditSinks...)
+
+	publishers, err := o.events.Publishers()
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to set up the event publishers")
+	}
+	events.SetPublishers("webhooks", publishers...)
+
 	controller, err := webhook.NewWebhooksController(o.path, o.namespace, o.botName, o.pluginFilename, o.configFilename)
 	if err != nil {
 		logrus.WithError(err).Fatal("failed to set up controller")
@@ -77,15 +106,47 @@ func main() {
 		controller.CleanupGitClientDir()
 		controller.ConfigMapWatcher.Stop()
 	}()
+	controller.StartWorkers(o.workers)
+
+	if o.adminPort > 0 {
+		adminMux := http.NewServeMux()
+		adminMux.Handle(logrusutil.LevelPath, logrusutil.LevelHandler{})
+		go func() {
+			err := http.ListenAndServe(":"+strconv.Itoa(o.adminPort), adminMux)
+			logrus.WithError(err).Errorf("failed to serve the admin endpoints")
+		}()
+	}
 
 	mux := http.NewServeMux()
 	mux.Handle(HealthPath, http.HandlerFunc(controller.Health))
 	mux.Handle(ReadyPath, http.HandlerFunc(controller.Ready))
+	mux.Handle(health.LivenessPath, &health.Handler{Checks: controller.LivenessChecks()})
+	mux.Handle(health.ReadinessPath, &health.Handler{Checks: controller.ReadinessChecks(o.checkTekton)})
+	mux.Handle(webhook.PluginHelpPath, http.HandlerFunc(controller.PluginHelp))
+	mux.Handle(webhook.CloudEventsPath, http.HandlerFunc(controller.HandleCloudEvents))
+	mux.Handle(webhook.ReleaseNotesPath, http.HandlerFunc(controller.ReleaseNotes))
 
 	mux.Handle("/", http.HandlerFunc(controller.DefaultHandler))
 	mux.Handle(o.path, http.HandlerFunc(controller.HandleWebhookRequests))
 
-	logrus.Infof("Lighthouse is now listening on path %s and port %d for WebHooks", o.path, o.port)
-	err = http.ListenAndServe(":"+strconv.Itoa(o.port), mux)
-	logrus.WithError(err).Errorf("failed to server HTTP")
+	server := &http.Server{Addr: ":" + strconv.Itoa(o.port), Handler: mux}
+	go func() {
+		logrus.Infof("Lighthouse is now listening on path %s and port %d for WebHooks", o.path, o.port)
+		if err := server.ListenAndServe(); err != http.ErrServerClosed {
+			logrus.WithError(err).Fatal("failed to serve HTTP")
+		}
+	}()
+
+	// keep serving while draining so that new webhooks are answered with 503 and delivered again rather than lost
+	interrupts.OnInterrupt(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), o.drainTimeout)
+		defer cancel()
+		if err := controller.Shutdown(ctx); err != nil {
+			logrus.WithError(err).Warn("exiting before all in-flight events were processed")
+		}
+		if err := server.Shutdown(ctx); err != nil {
+			logrus.WithError(err).Warn("failed to shut down the HTTP server")
+		}
+	})
+	interrupts.WaitForGracefulShutdown()
 }
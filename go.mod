@@ -12,6 +12,7 @@ require (
 	github.com/hashicorp/go-multierror v1.1.0
 	github.com/jenkins-x/go-scm v1.5.190
 	github.com/mattn/go-zglob v0.0.1
+	github.com/nats-io/nats.go v1.9.1
 	github.com/onsi/ginkgo v1.11.0
 	github.com/onsi/gomega v1.8.1
 	github.com/pkg/errors v0.9.1
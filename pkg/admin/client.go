@@ -0,0 +1,88 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/pkg/errors"
+)
+
+// Client calls the admin API of the pauses served by keeper
+type Client struct {
+	// URL is the base URL of keeper, e.g. http://lighthouse-keeper
+	URL string
+	// Token is the admin token
+	Token string
+	// HTTPClient is the client sending the requests, http.DefaultClient if nil
+	HTTPClient *http.Client
+}
+
+// NewClient creates a client of the admin API of the keeper at the URL
+func NewClient(url, token string) *Client {
+	return &Client{URL: url, Token: token}
+}
+
+// Pauses returns the paused components
+func (c *Client) Pauses() (lighthouse.Pauses, error) {
+	return c.do(http.MethodGet, "", "", nil)
+}
+
+// Pause pauses the component, merges, periodics or triggers, for the target, '*', 'org' or 'org/repo'
+func (c *Client) Pause(component, target string, req PauseRequest) (lighthouse.Pauses, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the pause request")
+	}
+	return c.do(http.MethodPost, component, target, body)
+}
+
+// Resume resumes the component for the target
+func (c *Client) Resume(component, target string) (lighthouse.Pauses, error) {
+	return c.do(http.MethodDelete, component, target, nil)
+}
+
+func (c *Client) do(method, component, target string, body []byte) (lighthouse.Pauses, error) {
+	u := strings.TrimSuffix(c.URL, "/") + PausesAPIPath
+	if component != "" {
+		u += "/" + component
+		if target != "" && target != lighthouse.PauseAll {
+			u += "/" + target
+		}
+	}
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the request to %s", u)
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to %s %s", method, u)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the response of %s %s", method, u)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s %s failed: %s", method, u, strings.TrimSpace(string(data)))
+	}
+	pauses := lighthouse.Pauses{}
+	if err := json.Unmarshal(data, &pauses); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the response of %s %s", method, u)
+	}
+	return pauses, nil
+}
@@ -0,0 +1,137 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/sirupsen/logrus"
+)
+
+// PausesAPIPath is the path of the admin API of the pauses
+const PausesAPIPath = "/api/pauses"
+
+// PauseRequest is the optional body of the requests pausing a component
+type PauseRequest struct {
+	// Reason is why the component is paused
+	Reason string `json:"reason,omitempty"`
+	// By is who pauses the component
+	By string `json:"by,omitempty"`
+}
+
+// Handler serves the admin API of the pauses:
+//
+//	GET    /api/pauses                          returns the pauses
+//	POST   /api/pauses/<component>[/org[/repo]] pauses the component for all the repositories, an org or a repository
+//	DELETE /api/pauses/<component>[/org[/repo]] resumes the component
+//
+// The components are merges, periodics and triggers. The requests changing the pauses are authenticated with the
+// token as a bearer token, and refused if the token is empty.
+type Handler struct {
+	Store Store
+	Token string
+}
+
+// ServeHTTP serves the admin API of the pauses
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logrus.WithField("component", "admin")
+	if r.Method != http.MethodGet && !Authorized(r, h.Token) {
+		http.Error(w, "401 Unauthorized: invalid or missing bearer token", http.StatusUnauthorized)
+		return
+	}
+	component, target := parsePath(r.URL.Path)
+	var pauses lighthouse.Pauses
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		pauses, err = h.Store.Get()
+	case http.MethodPost, http.MethodDelete:
+		if err := lighthouse.ValidatePause(component, target); err != nil {
+			http.Error(w, fmt.Sprintf("400 Bad Request: %s", err.Error()), http.StatusBadRequest)
+			return
+		}
+		var change func(lighthouse.Pauses) error
+		action := "Resumed"
+		if r.Method == http.MethodPost {
+			action = "Paused"
+			var req PauseRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+				http.Error(w, fmt.Sprintf("400 Bad Request: invalid body: %s", err.Error()), http.StatusBadRequest)
+				return
+			}
+			change = pause(component, target, req)
+		} else {
+			change = resume(component, target)
+		}
+		pauses, err = h.Store.Update(change)
+		if err == errNotPaused {
+			http.Error(w, fmt.Sprintf("404 Not Found: %s are not paused for %s", component, target), http.StatusNotFound)
+			return
+		}
+		if err == nil {
+			logger.Infof("%s %s for %s.", action, component, target)
+		}
+	default:
+		http.Error(w, fmt.Sprintf("405 Method Not Allowed: %s", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		logger.WithError(err).Error("Failed to access the pauses.")
+		http.Error(w, fmt.Sprintf("500 Internal Server Error: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	b, err := json.Marshal(pauses)
+	if err != nil {
+		logger.WithError(err).Error("Encoding JSON.")
+		b = []byte("{}")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(b); err != nil {
+		logger.WithError(err).Error("Writing JSON response.")
+	}
+}
+
+var errNotPaused = errors.New("not paused")
+
+func pause(component, target string, req PauseRequest) func(lighthouse.Pauses) error {
+	return func(pauses lighthouse.Pauses) error {
+		pauses.Set(component, target, lighthouse.Pause{Reason: req.Reason, By: req.By, Since: time.Now().UTC()})
+		return nil
+	}
+}
+
+func resume(component, target string) func(lighthouse.Pauses) error {
+	return func(pauses lighthouse.Pauses) error {
+		if !pauses.Delete(component, target) {
+			return errNotPaused
+		}
+		return nil
+	}
+}
+
+// parsePath returns the component and the target of the path, '*' if the path has no org
+func parsePath(path string) (string, string) {
+	parts := strings.SplitN(strings.Trim(strings.TrimPrefix(path, PausesAPIPath), "/"), "/", 2)
+	if len(parts) < 2 || parts[1] == "" {
+		return parts[0], lighthouse.PauseAll
+	}
+	return parts[0], parts[1]
+}
+
+// Authorized returns whether the request carries the token as a bearer token, always false if the token is empty
+func Authorized(r *http.Request, token string) bool {
+	if token == "" {
+		return false
+	}
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(header, "Bearer ")), []byte(token)) == 1
+}
@@ -0,0 +1,74 @@
+package admin_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/admin"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
+)
+
+func TestPausesAPI(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	configMaps := kubeClient.CoreV1().ConfigMaps("jx")
+	handler := &admin.Handler{Store: admin.NewConfigMapStore(configMaps), Token: "secret"}
+	mux := http.NewServeMux()
+	mux.Handle(admin.PausesAPIPath, handler)
+	mux.Handle(admin.PausesAPIPath+"/", handler)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := admin.NewClient(server.URL, "secret")
+	pauses, err := client.Pauses()
+	require.NoError(t, err)
+	assert.Empty(t, pauses)
+
+	pauses, err = client.Pause(lighthouse.PauseMerges, "", admin.PauseRequest{Reason: "release", By: "alice"})
+	require.NoError(t, err)
+	require.NotNil(t, pauses.Paused(lighthouse.PauseMerges, "org", "repo"))
+	assert.Equal(t, "release", pauses.Paused(lighthouse.PauseMerges, "org", "repo").Reason)
+
+	_, err = client.Pause(lighthouse.PauseTriggers, "org/repo", admin.PauseRequest{})
+	require.NoError(t, err)
+
+	cm, err := configMaps.Get(util.PausesConfigMapName, metav1.GetOptions{})
+	require.NoError(t, err)
+	stored := lighthouse.Pauses{}
+	require.NoError(t, yaml.Unmarshal([]byte(cm.Data[util.PausesFilename]), &stored))
+	assert.NotNil(t, stored.Paused(lighthouse.PauseMerges, "other", "repo"))
+	assert.NotNil(t, stored.Paused(lighthouse.PauseTriggers, "org", "repo"))
+	assert.Nil(t, stored.Paused(lighthouse.PauseTriggers, "org", "other"))
+
+	pauses, err = client.Resume(lighthouse.PauseMerges, "")
+	require.NoError(t, err)
+	assert.Nil(t, pauses.Paused(lighthouse.PauseMerges, "org", "repo"))
+	assert.NotNil(t, pauses.Paused(lighthouse.PauseTriggers, "org", "repo"))
+
+	_, err = client.Resume(lighthouse.PauseMerges, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404 Not Found")
+
+	_, err = client.Pause("builds", "", admin.PauseRequest{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400 Bad Request")
+
+	_, err = admin.NewClient(server.URL, "wrong").Pause(lighthouse.PauseMerges, "", admin.PauseRequest{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401 Unauthorized")
+
+	// the API is read only without a token
+	handler.Token = ""
+	_, err = admin.NewClient(server.URL, "").Pause(lighthouse.PauseMerges, "", admin.PauseRequest{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401 Unauthorized")
+	pauses, err = admin.NewClient(server.URL, "").Pauses()
+	require.NoError(t, err)
+	assert.Len(t, pauses, 1)
+}
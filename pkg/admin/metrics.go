@@ -0,0 +1,41 @@
+package admin
+
+import (
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var pausedDesc = prometheus.NewDesc(
+	"lighthouse_paused_since_seconds",
+	"The time the component was paused for the target since, in seconds since the epoch, for each paused component.",
+	[]string{"component", "target"},
+	nil,
+)
+
+// pausesCollector exposes the current pauses of the config as metrics
+type pausesCollector struct {
+	config config.Getter
+}
+
+// NewPausesCollector creates a collector of the pauses of the config
+func NewPausesCollector(cfg config.Getter) prometheus.Collector {
+	return &pausesCollector{config: cfg}
+}
+
+// Describe implements prometheus.Collector
+func (c *pausesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- pausedDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *pausesCollector) Collect(ch chan<- prometheus.Metric) {
+	cfg := c.config()
+	if cfg == nil {
+		return
+	}
+	for component, targets := range cfg.Pauses {
+		for target, pause := range targets {
+			ch <- prometheus.MustNewConstMetric(pausedDesc, prometheus.GaugeValue, float64(pause.Since.Unix()), component, target)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Package admin serves the admin API pausing and resuming the merges of keeper, the periodic jobs and the triggers of
+// repositories at runtime. The pauses are stored in a ConfigMap which every Lighthouse component watches.
+package admin
+
+import (
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/yaml"
+)
+
+// Store persists the pauses
+type Store interface {
+	// Get returns the current pauses
+	Get() (lighthouse.Pauses, error)
+	// Update applies the change to the current pauses and persists them
+	Update(change func(lighthouse.Pauses) error) (lighthouse.Pauses, error)
+}
+
+// NewConfigMapStore creates a store keeping the pauses in the pauses ConfigMap
+func NewConfigMapStore(configMaps corev1.ConfigMapInterface) Store {
+	return &configMapStore{configMaps: configMaps}
+}
+
+type configMapStore struct {
+	configMaps corev1.ConfigMapInterface
+}
+
+func (s *configMapStore) Get() (lighthouse.Pauses, error) {
+	_, pauses, err := s.get()
+	return pauses, err
+}
+
+func (s *configMapStore) Update(change func(lighthouse.Pauses) error) (lighthouse.Pauses, error) {
+	var answer lighthouse.Pauses
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, pauses, err := s.get()
+		if err != nil {
+			return err
+		}
+		if err := change(pauses); err != nil {
+			return err
+		}
+		data, err := yaml.Marshal(pauses)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal the pauses")
+		}
+		if cm == nil {
+			cm = &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: util.PausesConfigMapName}}
+			cm.Data = map[string]string{util.PausesFilename: string(data)}
+			_, err = s.configMaps.Create(cm)
+		} else {
+			if cm.Data == nil {
+				cm.Data = map[string]string{}
+			}
+			cm.Data[util.PausesFilename] = string(data)
+			_, err = s.configMaps.Update(cm)
+		}
+		if kerrors.IsAlreadyExists(err) {
+			// another replica has just created the ConfigMap
+			return kerrors.NewConflict(v1.Resource("configmaps"), util.PausesConfigMapName, err)
+		}
+		answer = pauses
+		return err
+	})
+	return answer, err
+}
+
+// get returns the pauses ConfigMap, nil if it does not exist yet, and the pauses it holds
+func (s *configMapStore) get() (*v1.ConfigMap, lighthouse.Pauses, error) {
+	pauses := lighthouse.Pauses{}
+	cm, err := s.configMaps.Get(util.PausesConfigMapName, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, pauses, nil
+	}
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to get ConfigMap %s", util.PausesConfigMapName)
+	}
+	if err := yaml.Unmarshal([]byte(cm.Data[util.PausesFilename]), &pauses); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to parse %s of ConfigMap %s", util.PausesFilename, util.PausesConfigMapName)
+	}
+	if pauses == nil {
+		pauses = lighthouse.Pauses{}
+	}
+	return cm, pauses, nil
+}
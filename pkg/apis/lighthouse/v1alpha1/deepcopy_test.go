@@ -0,0 +1,115 @@
+package v1alpha1_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/lighthouse/pkg/client/informers/externalversions"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// fullJob returns a job with every reference field of its spec and status set, so that the deep copy functions are
+// checked to copy them all
+func fullJob() *v1alpha1.LighthouseJob {
+	now := metav1.Now()
+	return &v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: "jx", Labels: map[string]string{"a": "b"}},
+		Spec: v1alpha1.LighthouseJobSpec{
+			Type: job.PresubmitJob,
+			Job:  "unit",
+			Refs: &v1alpha1.Refs{Org: "org", Repo: "repo", Pulls: []v1alpha1.Pull{{Number: 1, SHA: "abc"}}},
+			ExtraRefs: []v1alpha1.Refs{
+				{Org: "org", Repo: "other", Pulls: []v1alpha1.Pull{{Number: 2}}},
+			},
+			PipelineRunSpec:   &tektonv1beta1.PipelineRunSpec{ServiceAccountName: "tekton-bot"},
+			PipelineRunParams: []job.PipelineRunParam{{Name: "version", ValueTemplate: "{{ .Version }}"}},
+			PodSpec:           &corev1.PodSpec{Containers: []corev1.Container{{Name: "test", Args: []string{"make"}}}},
+			JenkinsSpec:       &v1alpha1.JenkinsSpec{BranchSourceJob: true},
+			Deployment:        &v1alpha1.DeploymentSpec{Environment: "staging"},
+			PodTemplate: &job.PodTemplate{
+				ServiceAccountName: "builder",
+				NodeSelector:       map[string]string{"disk": "ssd"},
+				Resources: &corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+				},
+			},
+			Params:         map[string]string{"FOO": "bar"},
+			EnvFromSecrets: []string{"docker-creds"},
+			Retry:          &job.RetryPolicy{MaxRetries: 2},
+		},
+		Status: v1alpha1.LighthouseJobStatus{
+			State:          v1alpha1.SuccessState,
+			StartTime:      now,
+			CompletionTime: &now,
+			Activity: &v1alpha1.ActivityRecord{
+				Name:   "activity",
+				Stages: []*v1alpha1.ActivityStageOrStep{{Name: "build", Steps: []*v1alpha1.ActivityStageOrStep{{Name: "compile"}}}},
+			},
+		},
+	}
+}
+
+func TestLighthouseJobDeepCopy(t *testing.T) {
+	original := fullJob()
+	copied := original.DeepCopy()
+	require.Equal(t, original, copied)
+
+	// changing the original must leave the copy untouched
+	original.Labels["a"] = "changed"
+	original.Spec.Refs.Pulls[0].SHA = "changed"
+	original.Spec.ExtraRefs[0].Pulls[0].Number = 3
+	original.Spec.PipelineRunSpec.ServiceAccountName = "changed"
+	original.Spec.PipelineRunParams[0].Name = "changed"
+	original.Spec.PodSpec.Containers[0].Args[0] = "changed"
+	original.Spec.JenkinsSpec.BranchSourceJob = false
+	original.Spec.Deployment.Environment = "changed"
+	original.Spec.PodTemplate.NodeSelector["disk"] = "changed"
+	original.Spec.PodTemplate.Resources.Limits[corev1.ResourceCPU] = resource.MustParse("2")
+	original.Spec.Params["FOO"] = "changed"
+	original.Spec.EnvFromSecrets[0] = "changed"
+	original.Spec.Retry.MaxRetries = 5
+	original.Status.CompletionTime.Time = original.Status.CompletionTime.Add(time.Hour)
+	original.Status.Activity.Stages[0].Steps[0].Name = "changed"
+
+	assert.Equal(t, fullJob().Labels, copied.Labels)
+	assert.Equal(t, fullJob().Spec, copied.Spec)
+	assert.Equal(t, fullJob().Status.Activity, copied.Status.Activity)
+	assert.NotEqual(t, original.Status.CompletionTime, copied.Status.CompletionTime)
+
+	var obj runtime.Object = copied
+	assert.Equal(t, copied, obj.DeepCopyObject())
+	list := &v1alpha1.LighthouseJobList{Items: []v1alpha1.LighthouseJob{*copied}}
+	assert.Equal(t, list, list.DeepCopyObject())
+}
+
+func TestLighthouseJobInformer(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	gvks, _, err := scheme.ObjectKinds(&v1alpha1.LighthouseJob{})
+	require.NoError(t, err)
+	assert.Equal(t, "LighthouseJob", gvks[0].Kind)
+	assert.Equal(t, v1alpha1.SchemeGroupVersion, gvks[0].GroupVersion())
+
+	client := fake.NewSimpleClientset(fullJob())
+	factory := externalversions.NewSharedInformerFactory(client, 0)
+	lister := factory.Lighthouse().V1alpha1().LighthouseJobs().Lister()
+	stop := make(chan struct{})
+	defer close(stop)
+	factory.Start(stop)
+	factory.WaitForCacheSync(stop)
+
+	jobs, err := lister.LighthouseJobs("jx").List(labels.Everything())
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, fullJob().Spec, jobs[0].Spec)
+}
@@ -40,6 +40,30 @@ const (
 	ErrorState PipelineState = "error"
 )
 
+// SkippedDescriptionPrefix prefixes the description of the aborted jobs which were skipped rather than run, e.g. the
+// periodics triggered during a maintenance window, which are not failures
+const SkippedDescriptionPrefix = "Skipped: "
+
+// MaxChangedFiles bounds the changed files kept in the spec of a job so that LighthouseJobs stay far below the size
+// limit of the objects of the API server
+const MaxChangedFiles = 1000
+
+// FailureClass classifies why a pipeline did not succeed
+type FailureClass string
+
+// Various failure classes.
+const (
+	// InfraFailure means the pipeline failed because of its infrastructure, e.g. its pod was evicted, an image could
+	// not be pulled or the repository could not be cloned
+	InfraFailure FailureClass = "infra"
+
+	// ConfigFailure means the pipeline could not run because of its configuration, e.g. an invalid pipeline
+	ConfigFailure FailureClass = "config"
+
+	// TestFailure means the steps of the pipeline failed, e.g. its tests
+	TestFailure FailureClass = "test"
+)
+
 // Environment variables to be added to the pipeline we kick off
 const (
 	// BuildIDEnv is an optional unique build ID environment variable that can be used by an engine.
@@ -68,6 +92,14 @@ const (
 	PullNumberEnv = "PULL_NUMBER"
 	// PullPullShaEnv is the pull request's sha
 	PullPullShaEnv = "PULL_PULL_SHA"
+	// TagNameEnv is the name of the git tag a release job was triggered by
+	TagNameEnv = "TAG_NAME"
+	// DeployEnvironmentEnv is the name of the environment a deployment job deploys to
+	DeployEnvironmentEnv = "DEPLOY_ENVIRONMENT"
+	// DeployTaskEnv is the task of the deployment, such as deploy or deploy:migrations
+	DeployTaskEnv = "DEPLOY_TASK"
+	// DeployPayloadEnv is the JSON payload of the deployment
+	DeployPayloadEnv = "DEPLOY_PAYLOAD"
 )
 
 // +genclient
@@ -105,6 +137,10 @@ type LighthouseJobStatus struct {
 	LastCommitSHA string `json:"lastCommitSHA,omitempty"`
 	// Activity is the most recent activity recorded for the pipeline associated with this job.
 	Activity *ActivityRecord `json:"activity,omitempty"`
+	// PublishedEvents are the types of the lifecycle events of the job which have been published to the message bus.
+	PublishedEvents []string `json:"publishedEvents,omitempty"`
+	// FailureClass classifies why the job did not succeed, if known
+	FailureClass FailureClass `json:"failureClass,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -144,15 +180,41 @@ type LighthouseJobSpec struct {
 	// MaxConcurrency restricts the total number of instances
 	// of this job that can run in parallel at once
 	MaxConcurrency int `json:"max_concurrency,omitempty"`
+	// Priority orders the jobs waiting for capacity to run, higher first. Defaults by job type.
+	Priority *int `json:"priority,omitempty"`
 	// PipelineRunSpec provides the basis for running the test as a Tekton Pipeline
 	// https://github.com/tektoncd/pipeline
 	PipelineRunSpec *tektonv1beta1.PipelineRunSpec `json:"pipeline_run_spec,omitempty"`
 	// PipelineRunParams are the params used by the pipeline run
 	PipelineRunParams []job.PipelineRunParam `json:"pipeline_run_params,omitempty"`
+	// ChangedFiles are the files changed by the pull request or push which triggered the job, only set when the
+	// pipeline run params are templated from them. Only the first 1000 of the sorted files are kept.
+	// +kubebuilder:validation:MaxItems=1000
+	ChangedFiles []string `json:"changed_files,omitempty"`
 	// PodSpec provides the basis for running the test under a Kubernetes agent
 	PodSpec *corev1.PodSpec `json:"pod_spec,omitempty"`
 	// JenkinsSpec holds configuration specific to Jenkins jobs
 	JenkinsSpec *JenkinsSpec `json:"jenkins_spec,omitempty"`
+	// Deployment describes the deployment which triggered a deployment job
+	Deployment *DeploymentSpec `json:"deployment,omitempty"`
+	// PodTemplate overrides the resources, scheduling and service account of the pods running the job
+	PodTemplate *job.PodTemplate `json:"pod_template,omitempty"`
+	// Cluster is the alias of the cluster the pipeline of the job runs in
+	Cluster string `json:"cluster,omitempty"`
+	// Params are extra params passed to the pipeline run
+	Params map[string]string `json:"params,omitempty"`
+	// EnvFromSecrets are the names of secrets exposed as environment variables to each step of the pipeline run
+	EnvFromSecrets []string `json:"env_from_secrets,omitempty"`
+	// Retry configures the automatic retries of the job when it errors because of its infrastructure
+	Retry *job.RetryPolicy `json:"retry,omitempty"`
+	// Timeout is how long the pipeline of the job may run before it is aborted and reported as timed out
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// GracePeriod is how long the pipeline of a timed out job is given to stop once cancelled before it is deleted
+	GracePeriod *metav1.Duration `json:"grace_period,omitempty"`
+	// Report lists how the results of the job are reported, the defaults being used if empty
+	Report []job.ReportMode `json:"report,omitempty"`
+	// IssueRepo is the org/repo the issues of the issue report mode of a periodic are opened in
+	IssueRepo string `json:"issue_repo,omitempty"`
 }
 
 // Complete returns true if the prow job has finished
@@ -167,10 +229,56 @@ func (j *LighthouseJob) SetComplete() {
 	*j.Status.CompletionTime = metav1.Now()
 }
 
+// GetPriority returns the priority of the job, or the default priority of its type if it does not set one
+func (s *LighthouseJobSpec) GetPriority() int {
+	if s.Priority != nil {
+		return *s.Priority
+	}
+	return s.Type.DefaultPriority()
+}
+
+// Validate ensures the spec can be run by the controllers, e.g. that the refs of jobs triggered by git changes are set.
+func (s *LighthouseJobSpec) Validate() error {
+	switch s.Type {
+	case job.PeriodicJob:
+	case job.PresubmitJob, job.BatchJob:
+		if s.Refs == nil || len(s.Refs.Pulls) == 0 {
+			return fmt.Errorf("%s jobs require refs with at least one pull request", s.Type)
+		}
+	case job.PostsubmitJob, job.ReleaseJob, job.DeploymentJob:
+		if s.Refs == nil {
+			return fmt.Errorf("%s jobs require refs", s.Type)
+		}
+	default:
+		return fmt.Errorf("unknown job type %q", s.Type)
+	}
+	if s.Refs != nil && (s.Refs.Org == "" || s.Refs.Repo == "") {
+		return fmt.Errorf("refs require an org and a repo")
+	}
+	if s.Job == "" {
+		return fmt.Errorf("job name is required")
+	}
+	if s.MaxConcurrency < 0 {
+		return fmt.Errorf("max_concurrency: %d must be a non-negative number", s.MaxConcurrency)
+	}
+	if s.Retry != nil {
+		if err := s.Retry.Validate(); err != nil {
+			return fmt.Errorf("retry: %v", err)
+		}
+	}
+	if s.Timeout != nil && s.Timeout.Duration <= 0 {
+		return fmt.Errorf("timeout: %s must be a positive duration", s.Timeout.Duration)
+	}
+	if s.GracePeriod != nil && s.GracePeriod.Duration < 0 {
+		return fmt.Errorf("grace_period: %s must be a non-negative duration", s.GracePeriod.Duration)
+	}
+	return nil
+}
+
 // GetBranch returns the branch name corresponding to the refs on this spec.
 func (s *LighthouseJobSpec) GetBranch() string {
 	branch := s.Refs.BaseRef
-	if s.Type == job.PostsubmitJob {
+	if s.Type == job.PostsubmitJob || s.Type == job.ReleaseJob || s.Type == job.DeploymentJob {
 		return branch
 	}
 	if s.Type == job.BatchJob {
@@ -203,6 +311,22 @@ func (s *LighthouseJobSpec) GetEnvVars() map[string]string {
 		env[PullRefsEnv] = s.Refs.String()
 	}
 
+	if s.Type == job.ReleaseJob {
+		if s.Refs != nil {
+			env[TagNameEnv] = s.Refs.BaseRef
+		}
+		return env
+	}
+
+	if s.Type == job.DeploymentJob {
+		if s.Deployment != nil {
+			env[DeployEnvironmentEnv] = s.Deployment.Environment
+			env[DeployTaskEnv] = s.Deployment.Task
+			env[DeployPayloadEnv] = s.Deployment.Payload
+		}
+		return env
+	}
+
 	if s.Type == job.PostsubmitJob || s.Type == job.BatchJob {
 		return env
 	}
@@ -389,6 +513,14 @@ type ActivityRecord struct {
 	CompletionTime  *metav1.Time           `json:"completionTime,omitempty"`
 	Stages          []*ActivityStageOrStep `json:"stages,omitempty"`
 	Steps           []*ActivityStageOrStep `json:"steps,omitEmpty"`
+	// TestResults contains the JUnit XML test report produced by the pipeline, if any
+	TestResults string `json:"testResults,omitempty"`
+	// SARIFResults contains the SARIF static analysis report produced by the pipeline, if any
+	SARIFResults string `json:"sarifResults,omitempty"`
+	// Description explains the status when it is not obvious, e.g. why the pipeline was aborted
+	Description string `json:"description,omitempty"`
+	// FailureClass classifies why the pipeline did not succeed, if known
+	FailureClass FailureClass `json:"failureClass,omitempty"`
 }
 
 // ActivityStageOrStep represents a stage of an activity
@@ -413,6 +545,20 @@ func (a *ActivityRecord) RunningStages() []string {
 	return running
 }
 
+// DeploymentSpec describes a deployment of a repository to an environment requested on the git provider
+type DeploymentSpec struct {
+	// Environment is the name of the environment being deployed to
+	Environment string `json:"environment,omitempty"`
+	// EnvironmentURL is the URL of the environment, if known
+	EnvironmentURL string `json:"environment_url,omitempty"`
+	// Task is the task of the deployment, such as deploy or deploy:migrations
+	Task string `json:"task,omitempty"`
+	// Description is the optional description of the deployment
+	Description string `json:"description,omitempty"`
+	// Payload is the JSON encoded payload of the deployment
+	Payload string `json:"payload,omitempty"`
+}
+
 // JenkinsSpec is optional parameters for Jenkins jobs.
 // Currently, the only parameter supported is for telling
 // jenkins-operator that the job is generated by the https://go.cloudbees.com/docs/plugins/github-branch-source/#github-branch-source plugin
@@ -139,3 +139,67 @@ func TestPipelineOptionsSpec_GetEnvVars(t *testing.T) {
 		})
 	}
 }
+
+func TestLighthouseJobSpec_Validate(t *testing.T) {
+	refs := &v1alpha1.Refs{Org: "org", Repo: "repo", BaseSHA: "abc"}
+	pullRefs := &v1alpha1.Refs{Org: "org", Repo: "repo", Pulls: []v1alpha1.Pull{{Number: 1, SHA: "def"}}}
+	tests := []struct {
+		name string
+		spec v1alpha1.LighthouseJobSpec
+		pass bool
+	}{
+		{
+			name: "periodic",
+			spec: v1alpha1.LighthouseJobSpec{Type: job.PeriodicJob, Job: "nightly"},
+			pass: true,
+		},
+		{
+			name: "postsubmit",
+			spec: v1alpha1.LighthouseJobSpec{Type: job.PostsubmitJob, Job: "release", Refs: refs},
+			pass: true,
+		},
+		{
+			name: "presubmit",
+			spec: v1alpha1.LighthouseJobSpec{Type: job.PresubmitJob, Job: "unit", Refs: pullRefs},
+			pass: true,
+		},
+		{
+			name: "presubmit without pull requests",
+			spec: v1alpha1.LighthouseJobSpec{Type: job.PresubmitJob, Job: "unit", Refs: refs},
+		},
+		{
+			name: "postsubmit without refs",
+			spec: v1alpha1.LighthouseJobSpec{Type: job.PostsubmitJob, Job: "release"},
+		},
+		{
+			name: "refs without repo",
+			spec: v1alpha1.LighthouseJobSpec{Type: job.PostsubmitJob, Job: "release", Refs: &v1alpha1.Refs{Org: "org"}},
+		},
+		{
+			name: "unknown type",
+			spec: v1alpha1.LighthouseJobSpec{Type: "nightly", Job: "nightly"},
+		},
+		{
+			name: "missing job name",
+			spec: v1alpha1.LighthouseJobSpec{Type: job.PeriodicJob},
+		},
+		{
+			name: "negative max concurrency",
+			spec: v1alpha1.LighthouseJobSpec{Type: job.PeriodicJob, Job: "nightly", MaxConcurrency: -1},
+		},
+		{
+			name: "too many retries",
+			spec: v1alpha1.LighthouseJobSpec{Type: job.PeriodicJob, Job: "nightly", Retry: &job.RetryPolicy{MaxRetries: 100}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.spec.Validate()
+			if tc.pass && err != nil {
+				t.Errorf("expected the spec to be valid but got %v", err)
+			} else if !tc.pass && err == nil {
+				t.Error("expected the spec to be invalid")
+			}
+		})
+	}
+}
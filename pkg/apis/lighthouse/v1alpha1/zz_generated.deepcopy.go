@@ -8,6 +8,7 @@ import (
 	job "github.com/jenkins-x/lighthouse/pkg/config/job"
 	v1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -164,6 +165,22 @@ func (in *DecorationConfig) DeepCopy() *DecorationConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentSpec) DeepCopyInto(out *DeploymentSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentSpec.
+func (in *DeploymentSpec) DeepCopy() *DeploymentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Duration) DeepCopyInto(out *Duration) {
 	*out = *in
@@ -272,6 +289,11 @@ func (in *LighthouseJobSpec) DeepCopyInto(out *LighthouseJobSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int)
+		**out = **in
+	}
 	if in.PipelineRunSpec != nil {
 		in, out := &in.PipelineRunSpec, &out.PipelineRunSpec
 		*out = new(v1beta1.PipelineRunSpec)
@@ -282,6 +304,11 @@ func (in *LighthouseJobSpec) DeepCopyInto(out *LighthouseJobSpec) {
 		*out = make([]job.PipelineRunParam, len(*in))
 		copy(*out, *in)
 	}
+	if in.ChangedFiles != nil {
+		in, out := &in.ChangedFiles, &out.ChangedFiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.PodSpec != nil {
 		in, out := &in.PodSpec, &out.PodSpec
 		*out = new(v1.PodSpec)
@@ -292,6 +319,48 @@ func (in *LighthouseJobSpec) DeepCopyInto(out *LighthouseJobSpec) {
 		*out = new(JenkinsSpec)
 		**out = **in
 	}
+	if in.Deployment != nil {
+		in, out := &in.Deployment, &out.Deployment
+		*out = new(DeploymentSpec)
+		**out = **in
+	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(job.PodTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.EnvFromSecrets != nil {
+		in, out := &in.EnvFromSecrets, &out.EnvFromSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(job.RetryPolicy)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Report != nil {
+		in, out := &in.Report, &out.Report
+		*out = make([]job.ReportMode, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -318,6 +387,11 @@ func (in *LighthouseJobStatus) DeepCopyInto(out *LighthouseJobStatus) {
 		*out = new(ActivityRecord)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PublishedEvents != nil {
+		in, out := &in.PublishedEvents, &out.PublishedEvents
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -0,0 +1,111 @@
+// Package audit records the state-changing actions of Lighthouse: merges, label changes, commit statuses and their
+// overrides, pipeline triggers and bot comments. Each entry records who did what, when and why, and the event which
+// caused it, and is written to the configured sinks, e.g. a file or a webhook, for compliance review.
+package audit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Action is the kind of a recorded action
+type Action string
+
+const (
+	// ActionMerge is the merge of a pull request
+	ActionMerge Action = "merge"
+	// ActionAddLabel is the addition of a label to an issue or pull request
+	ActionAddLabel Action = "add-label"
+	// ActionRemoveLabel is the removal of a label from an issue or pull request
+	ActionRemoveLabel Action = "remove-label"
+	// ActionStatus is the creation or update of a commit status
+	ActionStatus Action = "status"
+	// ActionOverride is the override of a failed commit status by a user
+	ActionOverride Action = "override"
+	// ActionTrigger is the trigger of a pipeline
+	ActionTrigger Action = "trigger"
+	// ActionComment is the creation or update of a bot comment
+	ActionComment Action = "comment"
+)
+
+// Entry is a recorded action
+type Entry struct {
+	// Time is when the action was taken
+	Time time.Time `json:"time"`
+	// Component is the Lighthouse component which took the action, e.g. webhooks or keeper
+	Component string `json:"component,omitempty"`
+	// Action is the kind of action
+	Action Action `json:"action"`
+	// Actor is the user who took or requested the action, e.g. the bot or the user who commented a command
+	Actor string `json:"actor,omitempty"`
+	// Org, Repo and Number identify the repository and the issue or pull request the action applies to
+	Org    string `json:"org,omitempty"`
+	Repo   string `json:"repo,omitempty"`
+	Number int    `json:"number,omitempty"`
+	// Ref is the git ref or SHA the action applies to, e.g. for commit statuses
+	Ref string `json:"ref,omitempty"`
+	// Target is what the action changed, e.g. the label, the status context or the pipeline
+	Target string `json:"target,omitempty"`
+	// Details describes the change, e.g. the state and description of a status or the body of a comment
+	Details string `json:"details,omitempty"`
+	// Reason is why the action was taken, e.g. the plugin which took it
+	Reason string `json:"reason,omitempty"`
+	// EventGUID identifies the webhook event which caused the action
+	EventGUID string `json:"event_guid,omitempty"`
+}
+
+// Sink writes the recorded actions somewhere they can be reviewed
+type Sink interface {
+	Write(entry Entry) error
+}
+
+var (
+	lock      sync.RWMutex
+	component string
+	sinks     []Sink
+	now       = time.Now
+)
+
+// SetSinks configures the component recording actions, e.g. webhooks, and the sinks the actions are written to.
+// Nothing is recorded until sinks are configured.
+func SetSinks(componentName string, s ...Sink) {
+	lock.Lock()
+	defer lock.Unlock()
+	component = componentName
+	sinks = s
+}
+
+// Enabled returns whether actions are recorded, so that callers can skip building expensive entries
+func Enabled() bool {
+	lock.RLock()
+	defer lock.RUnlock()
+	return len(sinks) > 0
+}
+
+// Record writes the entry to the configured sinks. Failures are logged rather than returned so that auditing never
+// prevents an action from being taken.
+func Record(entry Entry) {
+	lock.RLock()
+	defer lock.RUnlock()
+	if len(sinks) == 0 {
+		return
+	}
+	if entry.Time.IsZero() {
+		entry.Time = now()
+	}
+	if entry.Component == "" {
+		entry.Component = component
+	}
+	for _, s := range sinks {
+		if err := s.Write(entry); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"action": entry.Action,
+				"org":    entry.Org,
+				"repo":   entry.Repo,
+				"number": entry.Number,
+			}).Error("failed to write audit entry")
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingSink struct{}
+
+func (failingSink) Write(Entry) error {
+	return errors.New("unavailable")
+}
+
+func TestRecord(t *testing.T) {
+	defer SetSinks("")
+	now = func() time.Time { return time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	Record(Entry{Action: ActionMerge})
+	assert.False(t, Enabled())
+
+	var buf bytes.Buffer
+	SetSinks("keeper", failingSink{}, NewWriterSink(&buf))
+	assert.True(t, Enabled())
+
+	Record(Entry{Action: ActionMerge, Actor: "bot", Org: "org", Repo: "repo", Number: 1, Ref: "abc"})
+	Record(Entry{Action: ActionTrigger, Component: "webhooks", Target: "unit"})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, `{"time":"2020-01-02T03:04:05Z","component":"keeper","action":"merge","actor":"bot","org":"org","repo":"repo","number":1,"ref":"abc"}`, lines[0])
+	assert.Equal(t, `{"time":"2020-01-02T03:04:05Z","component":"webhooks","action":"trigger","target":"unit"}`, lines[1])
+}
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	path := filepath.Join(dir, "audit.log")
+
+	for i := 0; i < 2; i++ {
+		s, err := NewFileSink(path)
+		require.NoError(t, err)
+		require.NoError(t, s.Write(Entry{Action: ActionComment, Number: i}))
+	}
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"time\":\"0001-01-01T00:00:00Z\",\"action\":\"comment\"}\n{\"time\":\"0001-01-01T00:00:00Z\",\"action\":\"comment\",\"number\":1}\n", string(b))
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received []Entry
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var entry Entry
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&entry))
+		received = append(received, entry)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	s := NewWebhookSink(server.URL)
+	require.NoError(t, s.Write(Entry{Action: ActionAddLabel, Target: "lgtm"}))
+	status = http.StatusInternalServerError
+	assert.Error(t, s.Write(Entry{Action: ActionRemoveLabel, Target: "lgtm"}))
+
+	require.Len(t, received, 2)
+	assert.Equal(t, ActionAddLabel, received[0].Action)
+	assert.Equal(t, "lgtm", received[0].Target)
+}
+
+func TestOptionsSinks(t *testing.T) {
+	o := &Options{}
+	sinks, err := o.Sinks()
+	require.NoError(t, err)
+	assert.Empty(t, sinks)
+
+	o = &Options{File: "-", WebhookURL: "http://audit"}
+	sinks, err = o.Sinks()
+	require.NoError(t, err)
+	assert.Len(t, sinks, 2)
+
+	o = &Options{File: filepath.Join("does", "not", "exist", "audit.log")}
+	_, err = o.Sinks()
+	assert.Error(t, err)
+}
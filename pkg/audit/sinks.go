@@ -0,0 +1,110 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// WriterSink writes the entries as JSON lines, e.g. to a file or stdout
+type WriterSink struct {
+	lock sync.Mutex
+	w    io.Writer
+}
+
+// NewWriterSink creates a sink writing the entries as JSON lines to the writer
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// NewFileSink creates a sink appending the entries as JSON lines to the file, which is created if needed
+func NewFileSink(path string) (*WriterSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file %s: %v", path, err)
+	}
+	return NewWriterSink(f), nil
+}
+
+// Write writes the entry as a JSON line
+func (s *WriterSink) Write(entry Entry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, err = s.w.Write(append(b, '\n'))
+	return err
+}
+
+// WebhookSink posts the entries as JSON to a URL, e.g. of a log collector or of a gateway to an object storage
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink creates a sink posting the entries to the URL
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Write posts the entry
+func (s *WebhookSink) Write(entry Entry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("failed to post audit entry to %s: %v", s.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to post audit entry to %s: status %s", s.url, resp.Status)
+	}
+	return nil
+}
+
+// Options configures the sinks from command line flags
+type Options struct {
+	// File is the path of the file the entries are appended to, or - for stdout
+	File string
+	// WebhookURL is the URL the entries are posted to
+	WebhookURL string
+}
+
+// AddFlags adds the flags configuring the sinks
+func (o *Options) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.File, "audit-file", "", "Path of the file the audit log of the actions taken is appended to as JSON lines, or - for stdout. Disabled if empty.")
+	fs.StringVar(&o.WebhookURL, "audit-webhook-url", "", "URL the audit log entries of the actions taken are posted to as JSON. Disabled if empty.")
+}
+
+// Sinks creates the sinks configured by the options
+func (o *Options) Sinks() ([]Sink, error) {
+	var answer []Sink
+	switch o.File {
+	case "":
+	case "-":
+		answer = append(answer, NewWriterSink(os.Stdout))
+	default:
+		s, err := NewFileSink(o.File)
+		if err != nil {
+			return nil, err
+		}
+		answer = append(answer, s)
+	}
+	if o.WebhookURL != "" {
+		answer = append(answer, NewWebhookSink(o.WebhookURL))
+	}
+	return answer, nil
+}
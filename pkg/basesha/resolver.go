@@ -0,0 +1,142 @@
+// Package basesha resolves the heads of the base branches the jobs test against and caches them for a short time, so
+// that all the jobs triggered together test the same base and the SCM provider is not asked for the same branch head
+// over and over. The cached heads are updated by the push events and invalidated by the merges.
+package basesha
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTTL is how long a branch head is cached by default
+	DefaultTTL = 30 * time.Second
+
+	// pruneThreshold is the number of cached heads above which the expired ones are removed
+	pruneThreshold = 1000
+)
+
+// RefGetter gets the SHA a ref, such as heads/master, points at
+type RefGetter interface {
+	GetRef(org, repo, ref string) (string, error)
+}
+
+// Resolver resolves and caches the heads of the branches. A nil Resolver resolves every head without caching.
+type Resolver struct {
+	ttl time.Duration
+	now func() time.Time
+
+	lock    sync.Mutex
+	entries map[string]*entry
+}
+
+// entry is a cached head, or a head being resolved until ready is closed
+type entry struct {
+	ready   chan struct{}
+	sha     string
+	err     error
+	expires time.Time
+}
+
+// NewResolver creates a resolver caching the heads for the given duration
+func NewResolver(ttl time.Duration) *Resolver {
+	return &Resolver{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]*entry{},
+	}
+}
+
+// Resolve returns the head of the branch, from the cache if it was resolved or updated less than the TTL ago.
+// Concurrent resolutions of the same branch share a single request to the SCM provider.
+func (r *Resolver) Resolve(getter RefGetter, org, repo, branch string) (string, error) {
+	branch = strings.TrimPrefix(branch, "refs/heads/")
+	if r == nil {
+		return getter.GetRef(org, repo, "heads/"+branch)
+	}
+	k := key(org, repo, branch)
+
+	r.lock.Lock()
+	e, ok := r.entries[k]
+	if ok {
+		select {
+		case <-e.ready:
+			if r.now().After(e.expires) {
+				ok = false
+			}
+		default:
+			// resolving
+		}
+	}
+	if !ok {
+		e = &entry{ready: make(chan struct{})}
+		r.entries[k] = e
+		r.prune()
+	}
+	r.lock.Unlock()
+
+	if ok {
+		<-e.ready
+		return e.sha, e.err
+	}
+	e.sha, e.err = getter.GetRef(org, repo, "heads/"+branch)
+	r.lock.Lock()
+	e.expires = r.now().Add(r.ttl)
+	if e.err != nil {
+		// do not cache the failures
+		if r.entries[k] == e {
+			delete(r.entries, k)
+		}
+	}
+	r.lock.Unlock()
+	close(e.ready)
+	return e.sha, e.err
+}
+
+// Update records the new head of a branch, e.g. from a push event
+func (r *Resolver) Update(org, repo, branch, sha string) {
+	if r == nil {
+		return
+	}
+	branch = strings.TrimPrefix(branch, "refs/heads/")
+	e := &entry{ready: make(chan struct{}), sha: sha}
+	close(e.ready)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	e.expires = r.now().Add(r.ttl)
+	r.entries[key(org, repo, branch)] = e
+	r.prune()
+}
+
+// Invalidate forgets the head of a branch, e.g. once a pull request is merged into it, so that it is resolved again
+func (r *Resolver) Invalidate(org, repo, branch string) {
+	if r == nil {
+		return
+	}
+	branch = strings.TrimPrefix(branch, "refs/heads/")
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.entries, key(org, repo, branch))
+}
+
+// prune removes the expired heads once there are many of them. It must be called with the lock held.
+func (r *Resolver) prune() {
+	if len(r.entries) <= pruneThreshold {
+		return
+	}
+	now := r.now()
+	for k, e := range r.entries {
+		select {
+		case <-e.ready:
+			if now.After(e.expires) {
+				delete(r.entries, k)
+			}
+		default:
+		}
+	}
+}
+
+func key(org, repo, branch string) string {
+	return org + "/" + repo + ":" + branch
+}
@@ -0,0 +1,109 @@
+package basesha
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRefs struct {
+	lock  sync.Mutex
+	refs  map[string]string
+	calls int
+	err   error
+	delay time.Duration
+}
+
+func (f *fakeRefs) GetRef(org, repo, ref string) (string, error) {
+	time.Sleep(f.delay)
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.calls++
+	if f.err != nil {
+		return "", f.err
+	}
+	return f.refs[org+"/"+repo+":"+ref], nil
+}
+
+func TestResolver(t *testing.T) {
+	refs := &fakeRefs{refs: map[string]string{"org/repo:heads/master": "sha1"}}
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := NewResolver(time.Minute)
+	r.now = func() time.Time { return now }
+
+	sha, err := r.Resolve(refs, "org", "repo", "master")
+	require.NoError(t, err)
+	assert.Equal(t, "sha1", sha)
+
+	// cached, even when the branch moved
+	refs.refs["org/repo:heads/master"] = "sha2"
+	sha, err = r.Resolve(refs, "org", "repo", "refs/heads/master")
+	require.NoError(t, err)
+	assert.Equal(t, "sha1", sha)
+	assert.Equal(t, 1, refs.calls)
+
+	// resolved again once expired
+	now = now.Add(2 * time.Minute)
+	sha, err = r.Resolve(refs, "org", "repo", "master")
+	require.NoError(t, err)
+	assert.Equal(t, "sha2", sha)
+	assert.Equal(t, 2, refs.calls)
+
+	// updated by a push
+	r.Update("org", "repo", "refs/heads/master", "sha3")
+	sha, err = r.Resolve(refs, "org", "repo", "master")
+	require.NoError(t, err)
+	assert.Equal(t, "sha3", sha)
+	assert.Equal(t, 2, refs.calls)
+
+	// invalidated by a merge
+	r.Invalidate("org", "repo", "master")
+	sha, err = r.Resolve(refs, "org", "repo", "master")
+	require.NoError(t, err)
+	assert.Equal(t, "sha2", sha)
+	assert.Equal(t, 3, refs.calls)
+
+	// failures are not cached
+	r.Invalidate("org", "repo", "master")
+	refs.err = errors.New("unavailable")
+	_, err = r.Resolve(refs, "org", "repo", "master")
+	assert.Error(t, err)
+	refs.err = nil
+	sha, err = r.Resolve(refs, "org", "repo", "master")
+	require.NoError(t, err)
+	assert.Equal(t, "sha2", sha)
+	assert.Equal(t, 5, refs.calls)
+
+	// a nil resolver does not cache
+	var disabled *Resolver
+	sha, err = disabled.Resolve(refs, "org", "repo", "master")
+	require.NoError(t, err)
+	assert.Equal(t, "sha2", sha)
+	assert.Equal(t, 6, refs.calls)
+	disabled.Update("org", "repo", "master", "sha3")
+	disabled.Invalidate("org", "repo", "master")
+}
+
+func TestResolverSharesConcurrentResolutions(t *testing.T) {
+	refs := &fakeRefs{refs: map[string]string{"org/repo:heads/master": "sha1"}, delay: 50 * time.Millisecond}
+	r := NewResolver(time.Minute)
+
+	var wg sync.WaitGroup
+	shas := make([]string, 10)
+	for i := range shas {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			shas[i], _ = r.Resolve(refs, "org", "repo", "master")
+		}(i)
+	}
+	wg.Wait()
+	for _, sha := range shas {
+		assert.Equal(t, "sha1", sha)
+	}
+	assert.Equal(t, 1, refs.calls)
+}
@@ -0,0 +1,205 @@
+// Package botcomment manages the comments the bot keeps up to date on issues and pull requests.
+// The comments of each kind carry a hidden marker so that they are found again later: they are
+// updated in place rather than posting duplicates, and pruned once obsolete, e.g. an old failure
+// report after a successful rerun.
+package botcomment
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+type scmProviderClient interface {
+	BotName() (string, error)
+	ListIssueComments(org, repo string, number int) ([]*scm.Comment, error)
+	ListPullRequestComments(org, repo string, number int) ([]*scm.Comment, error)
+	CreateComment(org, repo string, number int, pr bool, comment string) error
+	EditComment(org, repo string, number int, id int, comment string, pr bool) error
+	DeleteComment(org, repo string, number, id int, pr bool) error
+}
+
+// Marker returns the hidden HTML comment identifying the bot comments of the given kind, e.g. the
+// name of the plugin posting them.
+func Marker(kind string) string {
+	return fmt.Sprintf("<!-- %s -->", kind)
+}
+
+// HasMarker returns whether the comment body carries the marker of the given kind.
+func HasMarker(body, kind string) bool {
+	return strings.Contains(body, Marker(kind))
+}
+
+// Client finds, updates and prunes the bot comments of an issue or pull request.
+// The comments are listed once, the first time they are needed, and the list is kept up to date
+// with the changes made by the client so that it can be shared by the handlers of an event.
+type Client struct {
+	org    string
+	repo   string
+	number int
+	pr     bool
+
+	spc scmProviderClient
+
+	lock     sync.Mutex
+	listed   bool
+	comments []*scm.Comment
+}
+
+// NewClient creates a client of the bot comments of an issue, or a pull request if pr is true.
+func NewClient(spc scmProviderClient, org, repo string, number int, pr bool) *Client {
+	return &Client{
+		org:    org,
+		repo:   repo,
+		number: number,
+		pr:     pr,
+		spc:    spc,
+	}
+}
+
+// Find returns the bot comments of the given kind, oldest first.
+func (c *Client) Find(kind string) ([]*scm.Comment, error) {
+	return c.FindFunc(func(comment *scm.Comment) bool {
+		return HasMarker(comment.Body, kind)
+	})
+}
+
+// FindFunc returns the bot comments matching the given function, oldest first.
+func (c *Client) FindFunc(matches func(*scm.Comment) bool) ([]*scm.Comment, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.list(); err != nil {
+		return nil, err
+	}
+	var answer []*scm.Comment
+	for _, comment := range c.comments {
+		if matches(comment) {
+			answer = append(answer, comment)
+		}
+	}
+	return answer, nil
+}
+
+// Upsert makes the given body the sticky bot comment of the given kind: the latest comment of
+// the kind is updated if its body changed, or a comment is created if there is none, and the
+// other comments of the kind are deleted. The marker of the kind is appended to the body.
+func (c *Client) Upsert(kind, body string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.list(); err != nil {
+		return err
+	}
+	if !HasMarker(body, kind) {
+		body = body + "\n" + Marker(kind)
+	}
+	var latest *scm.Comment
+	var stale []*scm.Comment
+	for _, comment := range c.comments {
+		if !HasMarker(comment.Body, kind) {
+			continue
+		}
+		if latest != nil {
+			stale = append(stale, latest)
+		}
+		latest = comment
+	}
+	if err := c.delete(stale); err != nil {
+		return err
+	}
+
+	if latest == nil {
+		if err := c.spc.CreateComment(c.org, c.repo, c.number, c.pr, body); err != nil {
+			return fmt.Errorf("failed to create comment on %s/%s#%d: %v", c.org, c.repo, c.number, err)
+		}
+		// the created comment is not returned so the comments are listed again when needed
+		c.listed = false
+		c.comments = nil
+		return nil
+	}
+	if latest.Body == body {
+		return nil
+	}
+	if err := c.spc.EditComment(c.org, c.repo, c.number, latest.ID, body, c.pr); err != nil {
+		return fmt.Errorf("failed to update comment %d on %s/%s#%d: %v", latest.ID, c.org, c.repo, c.number, err)
+	}
+	latest.Body = body
+	return nil
+}
+
+// Prune deletes the bot comments of the given kind.
+func (c *Client) Prune(kind string) error {
+	return c.PruneFunc(func(comment *scm.Comment) bool {
+		return HasMarker(comment.Body, kind)
+	})
+}
+
+// PruneFunc deletes the bot comments the given function identifies as obsolete, e.g. to also
+// delete the comments posted before the markers were introduced.
+func (c *Client) PruneFunc(isObsolete func(*scm.Comment) bool) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.list(); err != nil {
+		return err
+	}
+	var obsolete []*scm.Comment
+	for _, comment := range c.comments {
+		if isObsolete(comment) {
+			obsolete = append(obsolete, comment)
+		}
+	}
+	return c.delete(obsolete)
+}
+
+// list fetches the bot comments if they have not been fetched yet
+func (c *Client) list() error {
+	if c.listed {
+		return nil
+	}
+	botName, err := c.spc.BotName()
+	if err != nil {
+		return fmt.Errorf("failed to get the bot name: %v", err)
+	}
+	var comments []*scm.Comment
+	if c.pr {
+		comments, err = c.spc.ListPullRequestComments(c.org, c.repo, c.number)
+	} else {
+		comments, err = c.spc.ListIssueComments(c.org, c.repo, c.number)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list comments of %s/%s#%d: %v", c.org, c.repo, c.number, err)
+	}
+	c.comments = nil
+	for _, comment := range comments {
+		if comment.Author.Login == botName {
+			c.comments = append(c.comments, comment)
+		}
+	}
+	c.listed = true
+	return nil
+}
+
+// delete deletes the given comments and removes them from the listed comments
+func (c *Client) delete(comments []*scm.Comment) error {
+	deleted := map[int]bool{}
+	var err error
+	for _, comment := range comments {
+		if err = c.spc.DeleteComment(c.org, c.repo, c.number, comment.ID, c.pr); err != nil {
+			err = fmt.Errorf("failed to delete comment %d on %s/%s#%d: %v", comment.ID, c.org, c.repo, c.number, err)
+			break
+		}
+		deleted[comment.ID] = true
+	}
+	var remaining []*scm.Comment
+	for _, comment := range c.comments {
+		if !deleted[comment.ID] {
+			remaining = append(remaining, comment)
+		}
+	}
+	c.comments = remaining
+	return err
+}
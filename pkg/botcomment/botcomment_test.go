@@ -0,0 +1,87 @@
+package botcomment
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const botName = "k8s-ci-robot"
+
+func comment(id int, author, body string) *scm.Comment {
+	return &scm.Comment{ID: id, Author: scm.User{Login: author}, Body: body}
+}
+
+func TestUpsert(t *testing.T) {
+	testCases := []struct {
+		name     string
+		comments []*scm.Comment
+
+		expectedAdded   []string
+		expectedEdited  []string
+		expectedDeleted []string
+	}{
+		{
+			name:          "no previous comment",
+			comments:      []*scm.Comment{comment(1, "alice", "hello\n"+Marker("report"))},
+			expectedAdded: []string{"org/repo#1:report\n" + Marker("report")},
+		},
+		{
+			name: "previous comments are updated and deduplicated",
+			comments: []*scm.Comment{
+				comment(1, botName, "old\n"+Marker("report")),
+				comment(2, botName, "other\n"+Marker("other")),
+				comment(3, botName, "older\n"+Marker("report")),
+			},
+			expectedEdited:  []string{"org/repo#3:report\n" + Marker("report")},
+			expectedDeleted: []string{"org/repo#1"},
+		},
+		{
+			name:     "unchanged comment",
+			comments: []*scm.Comment{comment(1, botName, "report\n"+Marker("report"))},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := &fake.SCMClient{PullRequestComments: map[int][]*scm.Comment{1: tc.comments}}
+			c := NewClient(fc, "org", "repo", 1, true)
+
+			require.NoError(t, c.Upsert("report", "report"))
+			assert.Equal(t, tc.expectedAdded, fc.PullRequestCommentsAdded)
+			assert.Equal(t, tc.expectedEdited, fc.PullRequestCommentsEdited)
+			assert.Equal(t, tc.expectedDeleted, fc.PullRequestCommentsDeleted)
+
+			comments, err := c.Find("report")
+			require.NoError(t, err)
+			require.Len(t, comments, 1)
+			assert.Equal(t, "report\n"+Marker("report"), comments[0].Body)
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	fc := &fake.SCMClient{IssueComments: map[int][]*scm.Comment{1: {
+		comment(1, botName, "failed\n"+Marker("report")),
+		comment(2, "alice", "failed\n"+Marker("report")),
+		comment(3, botName, "failed before the markers"),
+		comment(4, botName, "hello\n"+Marker("welcome")),
+		comment(5, botName, "failed again\n"+Marker("report")),
+	}}}
+	c := NewClient(fc, "org", "repo", 1, false)
+
+	require.NoError(t, c.Prune("report"))
+	assert.Equal(t, []string{"org/repo#1", "org/repo#5"}, fc.IssueCommentsDeleted)
+
+	require.NoError(t, c.PruneFunc(func(comment *scm.Comment) bool {
+		return comment.Body == "failed before the markers"
+	}))
+	assert.Equal(t, []string{"org/repo#1", "org/repo#5", "org/repo#3"}, fc.IssueCommentsDeleted)
+
+	comments, err := c.FindFunc(func(*scm.Comment) bool { return true })
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.Equal(t, 4, comments[0].ID)
+}
@@ -0,0 +1,28 @@
+package clients
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// LoadBuildClusterConfigs loads the build clusters defined as contexts of the given kubeconfig file. The name of
+// each context is the alias used by the `cluster` field of jobs to select the cluster they run in.
+func LoadBuildClusterConfigs(kubeconfig string) (map[string]*rest.Config, error) {
+	if kubeconfig == "" {
+		return nil, nil
+	}
+	config, err := clientcmd.LoadFromFile(kubeconfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load build clusters kubeconfig %s", kubeconfig)
+	}
+	configs := map[string]*rest.Config{}
+	for name := range config.Contexts {
+		restConfig, err := clientcmd.NewNonInteractiveClientConfig(*config, name, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create config for build cluster %s", name)
+		}
+		configs[name] = restConfig
+	}
+	return configs, nil
+}
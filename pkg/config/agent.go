@@ -19,6 +19,8 @@ package config
 import (
 	"sync"
 	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
 )
 
 // Delta represents the before and after states of a Config change detected by the Agent.
@@ -34,6 +36,7 @@ type DeltaChan = chan<- Delta
 type Agent struct {
 	mut           sync.RWMutex // do not export Lock, etc methods
 	c             *Config
+	pauses        lighthouse.Pauses
 	subscriptions []DeltaChan
 }
 
@@ -65,6 +68,7 @@ func (ca *Agent) Set(c *Config) {
 	if ca.c != nil {
 		oldConfig = *ca.c
 	}
+	c.Pauses = ca.pauses
 	delta := Delta{oldConfig, *c}
 	ca.c = c
 	for _, subscription := range ca.subscriptions {
@@ -80,3 +84,16 @@ func (ca *Agent) Set(c *Config) {
 		}(subscription)
 	}
 }
+
+// SetPauses sets the components paused at runtime, which are kept when the config is reloaded.
+func (ca *Agent) SetPauses(pauses lighthouse.Pauses) {
+	ca.mut.Lock()
+	defer ca.mut.Unlock()
+	ca.pauses = pauses
+	if ca.c != nil {
+		// the current config may be in use so it is replaced rather than modified
+		c := *ca.c
+		c.Pauses = pauses
+		ca.c = &c
+	}
+}
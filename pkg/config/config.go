@@ -252,6 +252,26 @@ func (c *Config) GetPostsubmits(repository scm.Repository) []job.Postsubmit {
 	return answer
 }
 
+// GetReleases returns all the release jobs for the given repo
+func (c *Config) GetReleases(repository scm.Repository) []job.Release {
+	fullNames := util.FullNames(repository)
+	var answer []job.Release
+	for _, fn := range fullNames {
+		answer = append(answer, c.Releases[fn]...)
+	}
+	return answer
+}
+
+// GetDeployments returns all the deployment jobs for the given repo
+func (c *Config) GetDeployments(repository scm.Repository) []job.Deployment {
+	fullNames := util.FullNames(repository)
+	var answer []job.Deployment
+	for _, fn := range fullNames {
+		answer = append(answer, c.Deployments[fn]...)
+	}
+	return answer
+}
+
 // GetPresubmits lets return all the pre submits for the given repo
 func (c *Config) GetPresubmits(repository scm.Repository) []job.Presubmit {
 	fullNames := util.FullNames(repository)
@@ -262,6 +282,46 @@ func (c *Config) GetPresubmits(repository scm.Repository) []job.Presubmit {
 	return answer
 }
 
+// GetMonorepo returns the groups of presubmits scoped to the paths of the given repo, or nil if it is not a monorepo
+func (c *Config) GetMonorepo(repository scm.Repository) *job.Monorepo {
+	for _, fn := range util.FullNames(repository) {
+		if m, ok := c.Monorepos[fn]; ok && len(m.Groups) > 0 {
+			return &m
+		}
+	}
+	return nil
+}
+
+// branchRequirements partitions the status contexts of the branch like BranchRequirements, with the contexts of the
+// presubmits of a monorepo which may be skipped being only required if present and the context summarizing the
+// groups of the monorepo being required
+func (c *Config) branchRequirements(org, repo, branch string) ([]string, []string, []string) {
+	required, requiredIfPresent, optional := BranchRequirements(org, repo, branch, c.Presubmits)
+	m, ok := c.Monorepos[org+"/"+repo]
+	if !ok || len(m.Groups) == 0 {
+		return required, requiredIfPresent, optional
+	}
+	scoped := sets.NewString()
+	for _, j := range c.Presubmits[org+"/"+repo] {
+		if m.Scopes(j.Name) {
+			scoped.Insert(j.Context)
+		}
+	}
+	var alwaysRequired []string
+	for _, context := range required {
+		if scoped.Has(context) {
+			requiredIfPresent = append(requiredIfPresent, context)
+		} else {
+			alwaysRequired = append(alwaysRequired, context)
+		}
+	}
+	context := m.Context
+	if context == "" {
+		context = job.DefaultMonorepoContext
+	}
+	return append(alwaysRequired, context), requiredIfPresent, optional
+}
+
 // BranchRequirements partitions status contexts for a given org, repo branch into three buckets:
 //  - contexts that are always required to be present
 //  - contexts that are required, _if_ present
@@ -314,7 +374,7 @@ func (c *Config) GetPolicy(org, repo, branch string, b branchprotection.Branch)
 	policy := b.Policy
 
 	// Automatically require contexts from prow which must always be present
-	if prowContexts, _, _ := BranchRequirements(org, repo, branch, c.Presubmits); len(prowContexts) > 0 {
+	if prowContexts, _, _ := c.branchRequirements(org, repo, branch); len(prowContexts) > 0 {
 		// Error if protection is disabled
 		if policy.Protect != nil && !*policy.Protect {
 			if c.BranchProtection.AllowDisabledJobPolicies {
@@ -370,7 +430,7 @@ func (c Config) GetKeeperContextPolicy(org, repo, branch string) (*keeper.Contex
 	optional := sets.NewString(options.OptionalContexts...)
 
 	// automatically generate required and optional entries for Prow Pipelines
-	prowRequired, prowRequiredIfPresent, prowOptional := BranchRequirements(org, repo, branch, c.Presubmits)
+	prowRequired, prowRequiredIfPresent, prowOptional := c.branchRequirements(org, repo, branch)
 	required.Insert(prowRequired...)
 	requiredIfPresent.Insert(prowRequiredIfPresent...)
 	optional.Insert(prowOptional...)
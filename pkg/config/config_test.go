@@ -29,6 +29,7 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/config/secret"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 func TestDefaultJobBase(t *testing.T) {
@@ -114,6 +115,84 @@ func TestDefaultJobBase(t *testing.T) {
 	}
 }
 
+func TestDefaultPresubmitCommands(t *testing.T) {
+	cases := []struct {
+		name         string
+		context      string
+		trigger      string
+		rerunCommand string
+		expected     string
+		matches      []string
+		notMatches   []string
+		expectErr    bool
+	}{
+		{
+			name:       "defaults to the job name",
+			expected:   "/test unit",
+			matches:    []string{"/test unit", "/test lint unit"},
+			notMatches: []string{"/test units"},
+		},
+		{
+			name:       "the context triggers the job too",
+			context:    "pr-unit",
+			expected:   "/test unit",
+			matches:    []string{"/test unit", "/test pr-unit"},
+			notMatches: []string{"/test pr"},
+		},
+		{
+			name:       "contexts which cannot be passed to /test are ignored",
+			context:    "ci/unit tests",
+			expected:   "/test unit",
+			matches:    []string{"/test unit"},
+			notMatches: []string{"/test ci/unit tests"},
+		},
+		{
+			name:     "the rerun command is derived from the trigger",
+			context:  "pr-unit",
+			trigger:  `(?m)^/test (pr-unit|all)$`,
+			expected: "/test pr-unit",
+			matches:  []string{"/test pr-unit", "/test all"},
+		},
+		{
+			name:         "the trigger is derived from the rerun command",
+			rerunCommand: "/run unit+",
+			expected:     "/run unit+",
+			matches:      []string{"/run unit+", "/run unit+ please"},
+			notMatches:   []string{"/run unittt"},
+		},
+		{
+			name:      "a trigger matching none of the default rerun commands is invalid",
+			trigger:   `(?m)^/build$`,
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := job.Presubmit{
+				Base:         job.Base{Name: "unit"},
+				Reporter:     job.Reporter{Context: tc.context},
+				Trigger:      tc.trigger,
+				RerunCommand: tc.rerunCommand,
+			}
+			p.SetDefaults("jx")
+			err := p.SetRegexes()
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, p.RerunCommand)
+			for _, body := range tc.matches {
+				assert.True(t, p.TriggerMatches(body), "%q should trigger the job", body)
+			}
+			for _, body := range tc.notMatches {
+				assert.False(t, p.TriggerMatches(body), "%q should not trigger the job", body)
+			}
+		})
+	}
+}
+
 func TestValidateAgent(t *testing.T) {
 	k := string(job.JenkinsXAgent)
 	ns := "default"
@@ -227,6 +306,135 @@ func TestValidateJobBase(t *testing.T) {
 				Namespace:      &ns,
 			},
 		},
+		{
+			name: "valid pod template",
+			base: job.Base{
+				Name:      "name",
+				Agent:     ka,
+				Namespace: &ns,
+				PodTemplate: &job.PodTemplate{
+					Resources: &v1.ResourceRequirements{
+						Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), v1.ResourceMemory: resource.MustParse("4Gi")},
+						Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")},
+					},
+					NodeSelector:       map[string]string{"node.kubernetes.io/instance-type": "m5.4xlarge"},
+					Tolerations:        []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "builds", Effect: v1.TaintEffectNoSchedule}},
+					ServiceAccountName: "builder",
+				},
+			},
+			pass: true,
+		},
+		{
+			name: "pod template request greater than limit",
+			base: job.Base{
+				Name:      "name",
+				Agent:     ka,
+				Namespace: &ns,
+				PodTemplate: &job.PodTemplate{
+					Resources: &v1.ResourceRequirements{
+						Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("8")},
+						Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")},
+					},
+				},
+			},
+		},
+		{
+			name: "pod template invalid node selector",
+			base: job.Base{
+				Name:        "name",
+				Agent:       ka,
+				Namespace:   &ns,
+				PodTemplate: &job.PodTemplate{NodeSelector: map[string]string{"not a label": "value"}},
+			},
+		},
+		{
+			name: "pod template invalid toleration",
+			base: job.Base{
+				Name:        "name",
+				Agent:       ka,
+				Namespace:   &ns,
+				PodTemplate: &job.PodTemplate{Tolerations: []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpExists, Value: "builds"}}},
+			},
+		},
+		{
+			name: "pod template invalid service account",
+			base: job.Base{
+				Name:        "name",
+				Agent:       ka,
+				Namespace:   &ns,
+				PodTemplate: &job.PodTemplate{ServiceAccountName: "Not_Valid"},
+			},
+		},
+		{
+			name: "valid params and secrets",
+			base: job.Base{
+				Name:           "name",
+				Agent:          ka,
+				Namespace:      &ns,
+				Params:         map[string]string{"DOCKER_REGISTRY": "gcr.io", "build-args": "--pull"},
+				EnvFromSecrets: []string{"docker-creds", "npm.token"},
+			},
+			pass: true,
+		},
+		{
+			name: "invalid param name",
+			base: job.Base{
+				Name:      "name",
+				Agent:     ka,
+				Namespace: &ns,
+				Params:    map[string]string{"1st param": "value"},
+			},
+		},
+		{
+			name: "valid pipeline run params",
+			base: job.Base{
+				Name:      "name",
+				Agent:     ka,
+				Namespace: &ns,
+				PipelineRunParams: []job.PipelineRunParam{
+					{Name: "PR_NUMBER", ValueTemplate: "{{ .PullRequest.Number }}"},
+					{Name: "DOCS", ValueTemplate: `{{ .ChangedFiles | filter "docs/*" | join "," }}`},
+				},
+			},
+			pass: true,
+		},
+		{
+			name: "invalid pipeline run param template",
+			base: job.Base{
+				Name:              "name",
+				Agent:             ka,
+				Namespace:         &ns,
+				PipelineRunParams: []job.PipelineRunParam{{Name: "HOME", ValueTemplate: `{{ env "HOME" }}`}},
+			},
+		},
+		{
+			name: "invalid secret name",
+			base: job.Base{
+				Name:           "name",
+				Agent:          ka,
+				Namespace:      &ns,
+				EnvFromSecrets: []string{"Docker_Creds"},
+			},
+		},
+		{
+			name: "valid retry policy",
+			base: job.Base{
+				Name:      "name",
+				Agent:     ka,
+				Namespace: &ns,
+				Retry:     &job.RetryPolicy{MaxRetries: 3},
+			},
+			pass: true,
+		},
+		{
+			name: "too many retries",
+			base: job.Base{
+				Name:      "name",
+				Agent:     ka,
+				Namespace: &ns,
+				Retry:     &job.RetryPolicy{MaxRetries: 50},
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -241,6 +449,70 @@ func TestValidateJobBase(t *testing.T) {
 	}
 }
 
+func TestValidateReport(t *testing.T) {
+	cases := []struct {
+		name     string
+		jobType  job.PipelineKind
+		reporter job.Reporter
+		pass     bool
+	}{
+		{
+			name:    "default report modes",
+			jobType: job.PresubmitJob,
+			pass:    true,
+		},
+		{
+			name:     "check run without comment",
+			jobType:  job.PresubmitJob,
+			reporter: job.Reporter{Report: []job.ReportMode{job.ReportCheck}},
+			pass:     true,
+		},
+		{
+			name:     "postsubmit failures reported in issues",
+			jobType:  job.PostsubmitJob,
+			reporter: job.Reporter{Report: []job.ReportMode{job.ReportStatus, job.ReportIssue}},
+			pass:     true,
+		},
+		{
+			name:     "skip report with none",
+			jobType:  job.PresubmitJob,
+			reporter: job.Reporter{SkipReport: true, Report: []job.ReportMode{job.ReportNone}},
+			pass:     true,
+		},
+		{
+			name:     "skip report with status",
+			jobType:  job.PresubmitJob,
+			reporter: job.Reporter{SkipReport: true, Report: []job.ReportMode{job.ReportStatus}},
+		},
+		{
+			name:     "none combined with status",
+			jobType:  job.PresubmitJob,
+			reporter: job.Reporter{Report: []job.ReportMode{job.ReportNone, job.ReportStatus}},
+		},
+		{
+			name:     "presubmit failures reported in issues",
+			jobType:  job.PresubmitJob,
+			reporter: job.Reporter{Report: []job.ReportMode{job.ReportIssue}},
+		},
+		{
+			name:     "unknown report mode",
+			jobType:  job.PresubmitJob,
+			reporter: job.Reporter{Report: []job.ReportMode{"email"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			switch err := tc.reporter.ValidateReport(tc.jobType); {
+			case err == nil && !tc.pass:
+				t.Error("validation failed to raise an error")
+			case err != nil && tc.pass:
+				t.Errorf("validation should have passed, got: %v", err)
+			}
+		})
+	}
+}
+
 // integration test for fake config loading
 func TestValidConfigLoading(t *testing.T) {
 	var testCases = []struct {
@@ -368,6 +640,57 @@ periodics:
 - cron: '* * * * *'
   agent: tekton
   name: foo
+  spec:
+    containers:
+    - image: alpine`,
+			},
+			expectError: true,
+		},
+		{
+			name:       "periodic failures reported in issues",
+			prowConfig: ``,
+			jobConfigs: []string{
+				`
+periodics:
+- cron: '* * * * *'
+  agent: tekton
+  name: foo
+  report:
+  - issue
+  issue_repo: org/repo
+  spec:
+    containers:
+    - image: alpine`,
+			},
+		},
+		{
+			name:       "periodic failures reported in issues without issue repo",
+			prowConfig: ``,
+			jobConfigs: []string{
+				`
+periodics:
+- cron: '* * * * *'
+  agent: tekton
+  name: foo
+  report:
+  - issue
+  spec:
+    containers:
+    - image: alpine`,
+			},
+			expectError: true,
+		},
+		{
+			name:       "periodic reporting a status",
+			prowConfig: ``,
+			jobConfigs: []string{
+				`
+periodics:
+- cron: '* * * * *'
+  agent: tekton
+  name: foo
+  report:
+  - status
   spec:
     containers:
     - image: alpine`,
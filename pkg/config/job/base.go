@@ -24,7 +24,9 @@ import (
 
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
@@ -32,7 +34,10 @@ const (
 	DefaultClusterAlias = "default"
 )
 
-var jobNameRegex = regexp.MustCompile(`^[A-Za-z0-9-._]+$`)
+var (
+	jobNameRegex   = regexp.MustCompile(`^[A-Za-z0-9-._]+$`)
+	paramNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+)
 
 // Base contains attributes common to all job types
 type Base struct {
@@ -46,6 +51,9 @@ type Base struct {
 	Annotations map[string]string `json:"annotations,omitempty"`
 	// MaximumConcurrency of this job, 0 implies no limit.
 	MaxConcurrency int `json:"max_concurrency,omitempty"`
+	// Priority orders the jobs waiting for capacity to run, higher first. Defaults by job type so that presubmits start
+	// before postsubmits, releases and deployments, which start before periodics.
+	Priority *int `json:"priority,omitempty"`
 	// Agent that will take care of running this job.
 	Agent string `json:"agent"`
 	// Cluster is the alias of the cluster to run this job in.
@@ -68,6 +76,19 @@ type Base struct {
 	PipelineRunSpec *tektonv1beta1.PipelineRunSpec `json:"pipeline_run_spec,omitempty"`
 	// PipelineRunParams are the params used by the pipeline run
 	PipelineRunParams []PipelineRunParam `json:"pipeline_run_params,omitempty"`
+	// PodTemplate overrides the resources, scheduling and service account of the pods running the job
+	PodTemplate *PodTemplate `json:"pod_template,omitempty"`
+	// Params are extra params passed to the pipeline run, they do not override the params lighthouse sets itself
+	Params map[string]string `json:"params,omitempty"`
+	// EnvFromSecrets are the names of secrets exposed as environment variables to each step of the pipeline run
+	EnvFromSecrets []string `json:"env_from_secrets,omitempty"`
+	// Retry configures the automatic retries of the job when it errors because of its infrastructure
+	Retry *RetryPolicy `json:"retry,omitempty"`
+	// Timeout is how long the pipeline of the job may run before it is aborted and reported as timed out
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// GracePeriod is how long the pipeline of a timed out job is given to stop once cancelled before it is deleted.
+	// Defaults to 1 minute.
+	GracePeriod *metav1.Duration `json:"grace_period,omitempty"`
 }
 
 // SetDefaults initializes default values
@@ -103,12 +124,57 @@ func (b *Base) Validate(jobType PipelineKind, podNamespace string) error {
 	if err := ValidateLabels(b.Labels); err != nil {
 		return err
 	}
+	if b.PodTemplate != nil {
+		if err := b.PodTemplate.Validate(); err != nil {
+			return fmt.Errorf("pod_template: %v", err)
+		}
+	}
+	if b.Retry != nil {
+		if err := b.Retry.Validate(); err != nil {
+			return fmt.Errorf("retry: %v", err)
+		}
+	}
+	if b.Timeout != nil && b.Timeout.Duration <= 0 {
+		return fmt.Errorf("timeout: %s must be a positive duration", b.Timeout.Duration)
+	}
+	if b.GracePeriod != nil && b.GracePeriod.Duration < 0 {
+		return fmt.Errorf("grace_period: %s must be a non-negative duration", b.GracePeriod.Duration)
+	}
+	for name := range b.Params {
+		if !paramNameRegex.MatchString(name) {
+			return fmt.Errorf("params: name %q must match regex %q", name, paramNameRegex.String())
+		}
+	}
+	for i := range b.PipelineRunParams {
+		param := &b.PipelineRunParams[i]
+		if !paramNameRegex.MatchString(param.Name) {
+			return fmt.Errorf("pipeline_run_params: name %q must match regex %q", param.Name, paramNameRegex.String())
+		}
+		if _, err := param.Parse(); err != nil {
+			return fmt.Errorf("pipeline_run_params: invalid value_template of %s: %v", param.Name, err)
+		}
+	}
+	for _, secret := range b.EnvFromSecrets {
+		if errs := validation.IsDNS1123Subdomain(secret); len(errs) > 0 {
+			return fmt.Errorf("env_from_secrets: invalid secret name %q: %s", secret, strings.Join(errs, "; "))
+		}
+	}
 	if b.Spec == nil || len(b.Spec.Containers) == 0 {
 		return nil // knative-build and jenkins jobs have no spec
 	}
 	return nil
 }
 
+// UsesChangedFiles returns true if the params of the pipeline run of the job are templated from the changed files
+func (b *Base) UsesChangedFiles() bool {
+	for i := range b.PipelineRunParams {
+		if b.PipelineRunParams[i].UsesChangedFiles() {
+			return true
+		}
+	}
+	return false
+}
+
 // ValidateAgent validates job agent
 func (b *Base) ValidateAgent(podNamespace string) error {
 	agents := sets.NewString(AvailablePipelineAgentTypes()...)
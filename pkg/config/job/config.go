@@ -32,8 +32,14 @@ type Config struct {
 	// Full repo name (such as "kubernetes/kubernetes") -> list of jobs.
 	Presubmits  map[string][]Presubmit  `json:"presubmits,omitempty"`
 	Postsubmits map[string][]Postsubmit `json:"postsubmits,omitempty"`
+	// Full repo name -> list of jobs triggered by pushing a git tag.
+	Releases map[string][]Release `json:"releases,omitempty"`
+	// Full repo name -> list of jobs triggered by deployment events.
+	Deployments map[string][]Deployment `json:"deployments,omitempty"`
 	// Periodics are not associated with any repo.
 	Periodics []Periodic `json:"periodics,omitempty"`
+	// Full repo name -> groups of presubmits scoped to the paths of the repository.
+	Monorepos map[string]Monorepo `json:"monorepos,omitempty"`
 }
 
 func resolvePresets(name string, labels map[string]string, spec *v1.PodSpec, presets []Preset) error {
@@ -73,6 +79,27 @@ func (c *Config) Merge(other Config) error {
 	for repo, jobs := range other.Postsubmits {
 		c.Postsubmits[repo] = append(c.Postsubmits[repo], jobs...)
 	}
+	if c.Releases == nil {
+		c.Releases = make(map[string][]Release)
+	}
+	for repo, jobs := range other.Releases {
+		c.Releases[repo] = append(c.Releases[repo], jobs...)
+	}
+	if c.Deployments == nil {
+		c.Deployments = make(map[string][]Deployment)
+	}
+	for repo, jobs := range other.Deployments {
+		c.Deployments[repo] = append(c.Deployments[repo], jobs...)
+	}
+	if c.Monorepos == nil {
+		c.Monorepos = make(map[string]Monorepo)
+	}
+	for repo, monorepo := range other.Monorepos {
+		if _, ok := c.Monorepos[repo]; ok {
+			return fmt.Errorf("duplicated monorepo config for %s", repo)
+		}
+		c.Monorepos[repo] = monorepo
+	}
 	return nil
 }
 
@@ -134,6 +161,35 @@ func (c *Config) Init(lh lighthouse.Config) error {
 			}
 		}
 	}
+	for _, rs := range c.Releases {
+		for i := range rs {
+			rs[i].SetDefaults(lh.PodNamespace)
+			if err := rs[i].SetRegexes(); err != nil {
+				return fmt.Errorf("could not set regex: %v", err)
+			}
+			if err := resolvePresets(rs[i].Name, rs[i].Labels, rs[i].Spec, c.Presets); err != nil {
+				return err
+			}
+		}
+	}
+	for _, ds := range c.Deployments {
+		for i := range ds {
+			ds[i].SetDefaults(lh.PodNamespace)
+			if err := ds[i].SetRegexes(); err != nil {
+				return fmt.Errorf("could not set regex: %v", err)
+			}
+			if err := resolvePresets(ds[i].Name, ds[i].Labels, ds[i].Spec, c.Presets); err != nil {
+				return err
+			}
+		}
+	}
+	for repo, m := range c.Monorepos {
+		m.SetDefaults()
+		if err := m.SetRegexes(); err != nil {
+			return fmt.Errorf("invalid monorepo config for %s: %v", repo, err)
+		}
+		c.Monorepos[repo] = m
+	}
 	for i := range c.Periodics {
 		c.Periodics[i].SetDefaults(lh.PodNamespace)
 		if err := resolvePresets(c.Periodics[i].Name, c.Periodics[i].Labels, c.Periodics[i].Spec, c.Presets); err != nil {
@@ -188,6 +244,41 @@ func (c *Config) Validate(lh lighthouse.Config) error {
 			if err := j.Base.Validate(PostsubmitJob, lh.PodNamespace); err != nil {
 				return fmt.Errorf("invalid postsubmit job %s: %v", j.Name, err)
 			}
+			if err := j.ValidateReport(PostsubmitJob); err != nil {
+				return fmt.Errorf("invalid postsubmit job %s: %v", j.Name, err)
+			}
+		}
+	}
+	// Validate releases.
+	for repo, jobs := range c.Releases {
+		names := sets.NewString()
+		for _, j := range jobs {
+			if names.Has(j.Name) {
+				return fmt.Errorf("duplicated release job: %s", j.Name)
+			}
+			names.Insert(j.Name)
+			if err := j.Base.Validate(ReleaseJob, lh.PodNamespace); err != nil {
+				return fmt.Errorf("invalid release job %s in %s: %v", j.Name, repo, err)
+			}
+			if err := j.ValidateReport(ReleaseJob); err != nil {
+				return fmt.Errorf("invalid release job %s in %s: %v", j.Name, repo, err)
+			}
+		}
+	}
+	// Validate deployments.
+	for repo, jobs := range c.Deployments {
+		names := sets.NewString()
+		for _, j := range jobs {
+			if names.Has(j.Name) {
+				return fmt.Errorf("duplicated deployment job: %s", j.Name)
+			}
+			names.Insert(j.Name)
+			if err := j.Base.Validate(DeploymentJob, lh.PodNamespace); err != nil {
+				return fmt.Errorf("invalid deployment job %s in %s: %v", j.Name, repo, err)
+			}
+			if err := j.ValidateReport(DeploymentJob); err != nil {
+				return fmt.Errorf("invalid deployment job %s in %s: %v", j.Name, repo, err)
+			}
 		}
 	}
 	// validate no duplicated periodics
@@ -201,6 +292,9 @@ func (c *Config) Validate(lh lighthouse.Config) error {
 		if err := p.Base.Validate(PeriodicJob, lh.PodNamespace); err != nil {
 			return fmt.Errorf("invalid periodic job %s: %v", p.Name, err)
 		}
+		if err := p.ValidateReport(); err != nil {
+			return fmt.Errorf("invalid periodic job %s: %v", p.Name, err)
+		}
 	}
 	// Set the interval on the periodic jobs. It doesn't make sense to do this
 	// for child jobs.
@@ -282,6 +376,27 @@ func (c *Config) AllPostsubmits(repos []string) []Postsubmit {
 	return res
 }
 
+// AllReleases returns all release jobs in repos.
+// if repos is empty, return all releases.
+func (c *Config) AllReleases(repos []string) []Release {
+	var res []Release
+
+	for repo, v := range c.Releases {
+		if len(repos) == 0 {
+			res = append(res, v...)
+		} else {
+			for _, r := range repos {
+				if r == repo {
+					res = append(res, v...)
+					break
+				}
+			}
+		}
+	}
+
+	return res
+}
+
 // AllPeriodics returns all prow periodic jobs.
 func (c *Config) AllPeriodics() []Periodic {
 	return c.Periodics
@@ -303,6 +418,38 @@ func (c *Config) SetPresubmits(jobs map[string][]Presubmit) error {
 	return nil
 }
 
+// SetReleases updates c.Releases to jobs, after compiling and validating their regexes.
+func (c *Config) SetReleases(jobs map[string][]Release) error {
+	nj := map[string][]Release{}
+	for k, v := range jobs {
+		for i := range v {
+			if err := v[i].SetRegexes(); err != nil {
+				return err
+			}
+		}
+		nj[k] = make([]Release, len(v))
+		copy(nj[k], v)
+	}
+	c.Releases = nj
+	return nil
+}
+
+// SetDeployments updates c.Deployments to jobs, after compiling and validating their regexes.
+func (c *Config) SetDeployments(jobs map[string][]Deployment) error {
+	nj := map[string][]Deployment{}
+	for k, v := range jobs {
+		for i := range v {
+			if err := v[i].SetRegexes(); err != nil {
+				return err
+			}
+		}
+		nj[k] = make([]Deployment, len(v))
+		copy(nj[k], v)
+	}
+	c.Deployments = nj
+	return nil
+}
+
 // SetPostsubmits updates c.Postsubmits to jobs, after compiling and validating their regexes.
 func (c *Config) SetPostsubmits(jobs map[string][]Postsubmit) error {
 	nj := map[string][]Postsubmit{}
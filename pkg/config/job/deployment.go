@@ -0,0 +1,60 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import "fmt"
+
+// Deployment runs when a deployment of the repository to an environment is requested on the git provider,
+// e.g. to drive GitOps promotion pipelines. The environment, task and payload of the deployment are passed
+// to the pipeline.
+type Deployment struct {
+	Base
+	Reporter
+	// Only run for deployments to environments matching these regexes. Default is all environments.
+	Environments []string `json:"environments,omitempty"`
+	// Do not run for deployments to environments matching these regexes. Default is no environments.
+	SkipEnvironments []string `json:"skip_environments,omitempty"`
+
+	// We'll set this when we load it.
+	environmentMatcher *Brancher
+}
+
+// SetDefaults initializes default values
+func (d *Deployment) SetDefaults(namespace string) {
+	d.Base.SetDefaults(namespace)
+	if d.Context == "" {
+		d.Context = d.Name
+	}
+}
+
+// SetRegexes compiles and validates all the regular expressions
+func (d *Deployment) SetRegexes() error {
+	b, err := Brancher{Branches: d.Environments, SkipBranches: d.SkipEnvironments}.SetBrancherRegexes()
+	if err != nil {
+		return fmt.Errorf("could not set environment regexes for %s: %v", d.Name, err)
+	}
+	d.environmentMatcher = &b
+	return nil
+}
+
+// ShouldRun determines if the deployment job should run for a deployment to the given environment
+func (d Deployment) ShouldRun(environment string) bool {
+	if d.environmentMatcher != nil {
+		return d.environmentMatcher.ShouldRun(environment)
+	}
+	return Brancher{Branches: d.Environments, SkipBranches: d.SkipEnvironments}.ShouldRun(environment)
+}
@@ -46,11 +46,20 @@ const (
 	// the k8s garbage collector would immediately delete these
 	// resources
 	CreatedByLighthouseLabel = "created-by-lighthouse"
+	// LighthouseJobRetryLabel is added on LighthouseJobs retried automatically
+	// and carries the number of the retry.
+	LighthouseJobRetryLabel = "lighthouse.jenkins-x.io/retry"
+	// AuthorizedByAnnotation is added to the LighthouseJobs of pull requests
+	// and carries the login of the user who authorized running them.
+	AuthorizedByAnnotation = "lighthouse.jenkins-x.io/authorizedBy"
+	// AuthorizationAnnotation is added to the LighthouseJobs of pull requests
+	// and carries why the user could authorize running them, e.g. ok-to-test.
+	AuthorizationAnnotation = "lighthouse.jenkins-x.io/authorization"
 )
 
 // Labels returns a string slice with label consts from kube.
 func Labels() []string {
-	return []string{LighthouseJobTypeLabel, CreatedByLighthouseLabel, LighthouseJobIDLabel}
+	return []string{LighthouseJobTypeLabel, CreatedByLighthouseLabel, LighthouseJobIDLabel, LighthouseJobRetryLabel}
 }
 
 // ValidateLabels validates labels (not using reserved labels, valid names and valid values)
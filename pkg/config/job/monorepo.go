@@ -0,0 +1,196 @@
+package job
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultMonorepoContext is the default context of the status summarizing the groups of a monorepo skipped by a
+// pull request
+const DefaultMonorepoContext = "monorepo"
+
+// Monorepo maps the paths of a repository hosting several projects to the presubmits of the projects, so that a pull
+// request only runs the presubmits of the projects it changes
+type Monorepo struct {
+	// Context is the context of the status summarizing the groups which are tested and the ones which are skipped,
+	// which should be required by the branch protection in place of the contexts of the grouped presubmits. Defaults
+	// to "monorepo".
+	Context string `json:"context,omitempty"`
+	// Groups are the groups of presubmits scoped to paths. The presubmits which are not in any group run as usual.
+	Groups []MonorepoGroup `json:"groups,omitempty"`
+}
+
+// MonorepoGroup is a set of presubmits which only run automatically when some files matching its paths are changed
+type MonorepoGroup struct {
+	// Name identifies the group in the summary status, e.g. the name of the project
+	Name string `json:"name"`
+	// Paths are the globs of the files owned by the group, e.g. services/foo/**. '*' matches any characters but '/'
+	// and '**' any characters.
+	Paths []string `json:"paths"`
+	// Jobs are the names of the presubmits of the group. A presubmit can belong to several groups, in which case it
+	// runs if any of them is changed.
+	Jobs []string `json:"jobs"`
+
+	rePaths *regexp.Regexp // from Paths
+}
+
+// SetDefaults initializes default values
+func (m *Monorepo) SetDefaults() {
+	if m.Context == "" {
+		m.Context = DefaultMonorepoContext
+	}
+}
+
+// SetRegexes validates the groups and compiles their paths
+func (m *Monorepo) SetRegexes() error {
+	names := map[string]bool{}
+	for i := range m.Groups {
+		g := &m.Groups[i]
+		if g.Name == "" {
+			return fmt.Errorf("monorepo group %d has no name", i)
+		}
+		if names[g.Name] {
+			return fmt.Errorf("duplicate monorepo group %s", g.Name)
+		}
+		names[g.Name] = true
+		if len(g.Paths) == 0 {
+			return fmt.Errorf("monorepo group %s has no paths", g.Name)
+		}
+		var exprs []string
+		for _, path := range g.Paths {
+			if path == "" {
+				return fmt.Errorf("monorepo group %s has an empty path", g.Name)
+			}
+			exprs = append(exprs, globToRegexp(path))
+		}
+		re, err := regexp.Compile("^(?:" + strings.Join(exprs, "|") + ")$")
+		if err != nil {
+			return fmt.Errorf("could not compile the paths of monorepo group %s: %v", g.Name, err)
+		}
+		g.rePaths = re
+	}
+	return nil
+}
+
+// Merge adds the groups of another monorepo config, replacing the groups of the same name
+func (m *Monorepo) Merge(other *Monorepo) {
+	if other == nil {
+		return
+	}
+	if other.Context != "" {
+		m.Context = other.Context
+	}
+	for _, g := range other.Groups {
+		found := false
+		for i := range m.Groups {
+			if m.Groups[i].Name == g.Name {
+				m.Groups[i] = g
+				found = true
+				break
+			}
+		}
+		if !found {
+			m.Groups = append(m.Groups, g)
+		}
+	}
+}
+
+// Scopes returns whether the presubmit belongs to a group and so only runs automatically when the group is changed
+func (m *Monorepo) Scopes(jobName string) bool {
+	if m == nil {
+		return false
+	}
+	for _, g := range m.Groups {
+		if g.hasJob(jobName) {
+			return true
+		}
+	}
+	return false
+}
+
+// RunsAgainstChanges returns whether the presubmit belongs to a group changed by the given files. The presubmits
+// which are not in any group always run against the changes.
+func (m *Monorepo) RunsAgainstChanges(jobName string, changes []string) bool {
+	if !m.Scopes(jobName) {
+		return true
+	}
+	for _, g := range m.Groups {
+		if g.hasJob(jobName) && g.Changed(changes) {
+			return true
+		}
+	}
+	return false
+}
+
+// ChangedGroups partitions the names of the groups into those changed by the given files and the others
+func (m *Monorepo) ChangedGroups(changes []string) (changed []string, unchanged []string) {
+	if m == nil {
+		return nil, nil
+	}
+	for _, g := range m.Groups {
+		if g.Changed(changes) {
+			changed = append(changed, g.Name)
+		} else {
+			unchanged = append(unchanged, g.Name)
+		}
+	}
+	sort.Strings(changed)
+	sort.Strings(unchanged)
+	return changed, unchanged
+}
+
+// Changed returns whether any of the files matches the paths of the group
+func (g *MonorepoGroup) Changed(changes []string) bool {
+	re := g.rePaths
+	if re == nil {
+		var exprs []string
+		for _, path := range g.Paths {
+			exprs = append(exprs, globToRegexp(path))
+		}
+		re = regexp.MustCompile("^(?:" + strings.Join(exprs, "|") + ")$")
+	}
+	for _, change := range changes {
+		if re.MatchString(change) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *MonorepoGroup) hasJob(jobName string) bool {
+	for _, name := range g.Jobs {
+		if name == jobName {
+			return true
+		}
+	}
+	return false
+}
+
+// globToRegexp converts a glob into a regular expression, '**/' matching any directories, '**' any characters, '*'
+// any characters but '/' and '?' any character but '/'
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
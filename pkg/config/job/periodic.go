@@ -16,6 +16,11 @@ limitations under the License.
 
 package job
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Periodic runs on a timer.
 type Periodic struct {
 	Base
@@ -23,9 +28,24 @@ type Periodic struct {
 	Cron string `json:"cron"`
 	// Tags for config entries
 	Tags []string `json:"tags,omitempty"`
+	// Report lists how the results of the periodic are reported, only the issue and none modes being supported
+	Report []ReportMode `json:"report,omitempty"`
+	// IssueRepo is the org/repo the issues of the issue report mode are opened in
+	IssueRepo string `json:"issue_repo,omitempty"`
 }
 
 // SetDefaults initializes default values
 func (p *Periodic) SetDefaults(namespace string) {
 	p.Base.SetDefaults(namespace)
 }
+
+// ValidateReport validates the report modes of the periodic
+func (p *Periodic) ValidateReport() error {
+	if err := validateReportModes(p.Report, PeriodicJob); err != nil {
+		return err
+	}
+	if Reports(p.Report, ReportIssue) && len(strings.Split(p.IssueRepo, "/")) != 2 {
+		return fmt.Errorf("the %s report mode requires the issue_repo to be set to an org/repo", ReportIssue)
+	}
+	return nil
+}
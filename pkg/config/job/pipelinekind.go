@@ -37,4 +37,31 @@ const (
 	PeriodicJob PipelineKind = "periodic"
 	// BatchJob tests multiple unmerged PRs at the same time.
 	BatchJob PipelineKind = "batch"
+	// ReleaseJob means it runs when a git tag is pushed.
+	ReleaseJob PipelineKind = "release"
+	// DeploymentJob means it runs when a deployment to an environment is requested.
+	DeploymentJob PipelineKind = "deployment"
 )
+
+// Default priorities of the job types, see DefaultPriority.
+const (
+	// PresubmitPriority is the default priority of presubmit and batch jobs.
+	PresubmitPriority = 300
+	// PostsubmitPriority is the default priority of postsubmit, release and deployment jobs.
+	PostsubmitPriority = 200
+	// PeriodicPriority is the default priority of periodic jobs.
+	PeriodicPriority = 100
+)
+
+// DefaultPriority returns the priority of the jobs of this type which do not set one, so that the jobs giving feedback
+// on pull requests start first, then the jobs triggered by merges, tags and deployments, then the periodic jobs.
+func (k PipelineKind) DefaultPriority() int {
+	switch k {
+	case PresubmitJob, BatchJob:
+		return PresubmitPriority
+	case PeriodicJob:
+		return PeriodicPriority
+	default:
+		return PostsubmitPriority
+	}
+}
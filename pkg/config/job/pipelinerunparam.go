@@ -16,10 +16,82 @@ limitations under the License.
 
 package job
 
+import (
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+)
+
 // PipelineRunParam represents a param used by the pipeline run
 type PipelineRunParam struct {
 	// Name is the name of the param
 	Name string `json:"name,omitempty"`
-	// ValueTemplate is the template used to build the value from well know variables
+	// ValueTemplate is the Go template the value is rendered from with the context of the job, e.g. {{ .PullRequest.Number }}
 	ValueTemplate string `json:"value_template,omitempty"`
 }
+
+// paramTemplateFuncs is the restricted set of functions available to the value templates, on top of the builtin ones.
+// None of them can reach the environment, the file system or the network. The value they act on is their last
+// argument so that they can be chained, e.g. {{ .ChangedFiles | filter "docs/*" | join "," }}.
+var paramTemplateFuncs = template.FuncMap{
+	"join":       func(sep string, elems []string) string { return strings.Join(elems, sep) },
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"trim":       strings.TrimSpace,
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+	"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+	"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+	"base":       path.Base,
+	"dir":        path.Dir,
+	"dirs":       uniqueDirs,
+	"filter":     filterGlob,
+	"default": func(def, value string) string {
+		if value == "" {
+			return def
+		}
+		return value
+	},
+}
+
+// Parse parses the value template with the restricted set of functions, referencing a missing key being an error
+func (p *PipelineRunParam) Parse() (*template.Template, error) {
+	return template.New(p.Name).Funcs(paramTemplateFuncs).Option("missingkey=error").Parse(p.ValueTemplate)
+}
+
+// UsesChangedFiles returns true if the value template references the files changed by the pull request or push
+func (p *PipelineRunParam) UsesChangedFiles() bool {
+	return strings.Contains(p.ValueTemplate, ".ChangedFiles")
+}
+
+// uniqueDirs returns the sorted directories of the paths, without duplicates
+func uniqueDirs(paths []string) []string {
+	dirs := map[string]bool{}
+	for _, p := range paths {
+		dirs[path.Dir(p)] = true
+	}
+	var answer []string
+	for d := range dirs {
+		answer = append(answer, d)
+	}
+	sort.Strings(answer)
+	return answer
+}
+
+// filterGlob returns the paths matching the glob pattern, e.g. docs/*.md
+func filterGlob(pattern string, paths []string) ([]string, error) {
+	var answer []string
+	for _, p := range paths {
+		matched, err := path.Match(pattern, p)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			answer = append(answer, p)
+		}
+	}
+	return answer, nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// PodTemplate overrides the pods created to run a job, e.g. so that heavy jobs can request big nodes
+type PodTemplate struct {
+	// Resources are the compute resources of each step of the job which does not define its own
+	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+	// NodeSelector constrains the nodes the pods of the job are scheduled on
+	NodeSelector map[string]string `json:"node_selector,omitempty"`
+	// Tolerations allow the pods of the job to be scheduled on nodes with matching taints
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+	// ServiceAccountName is the service account the pods of the job run as
+	ServiceAccountName string `json:"service_account_name,omitempty"`
+	// PriorityClassName is the Kubernetes PriorityClass of the pods of the job, e.g. so that the pods of presubmits
+	// preempt those of periodics when the cluster is full
+	PriorityClassName string `json:"priority_class_name,omitempty"`
+}
+
+// Validate validates the pod template
+func (t *PodTemplate) Validate() error {
+	if t.Resources != nil {
+		for name, quantity := range t.Resources.Requests {
+			if quantity.Sign() < 0 {
+				return fmt.Errorf("resources: request of %s cannot be negative", name)
+			}
+			if limit, ok := t.Resources.Limits[name]; ok && quantity.Cmp(limit) > 0 {
+				return fmt.Errorf("resources: request of %s (%s) cannot be greater than its limit (%s)", name, quantity.String(), limit.String())
+			}
+		}
+		for name, quantity := range t.Resources.Limits {
+			if quantity.Sign() < 0 {
+				return fmt.Errorf("resources: limit of %s cannot be negative", name)
+			}
+		}
+	}
+	for k, v := range t.NodeSelector {
+		if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+			return fmt.Errorf("node_selector: invalid key %q: %s", k, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(v); len(errs) > 0 {
+			return fmt.Errorf("node_selector: invalid value %q for key %q: %s", v, k, strings.Join(errs, "; "))
+		}
+	}
+	for i, toleration := range t.Tolerations {
+		switch toleration.Operator {
+		case v1.TolerationOpEqual, "":
+			if toleration.Key == "" {
+				return fmt.Errorf("tolerations[%d]: the key must be set when the operator is %s", i, v1.TolerationOpEqual)
+			}
+		case v1.TolerationOpExists:
+			if toleration.Value != "" {
+				return fmt.Errorf("tolerations[%d]: the value must be empty when the operator is %s", i, v1.TolerationOpExists)
+			}
+		default:
+			return fmt.Errorf("tolerations[%d]: operator must be one of %s or %s (found %q)", i, v1.TolerationOpEqual, v1.TolerationOpExists, toleration.Operator)
+		}
+		switch toleration.Effect {
+		case "", v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute:
+		default:
+			return fmt.Errorf("tolerations[%d]: invalid effect %q", i, toleration.Effect)
+		}
+	}
+	if t.ServiceAccountName != "" {
+		if errs := validation.IsDNS1123Subdomain(t.ServiceAccountName); len(errs) > 0 {
+			return fmt.Errorf("service_account_name: invalid name %q: %s", t.ServiceAccountName, strings.Join(errs, "; "))
+		}
+	}
+	if t.PriorityClassName != "" {
+		if errs := validation.IsDNS1123Subdomain(t.PriorityClassName); len(errs) > 0 {
+			return fmt.Errorf("priority_class_name: invalid name %q: %s", t.PriorityClassName, strings.Join(errs, "; "))
+		}
+	}
+	return nil
+}
+
+// DeepCopyInto copies the receiver into out. in must be non-nil.
+func (t *PodTemplate) DeepCopyInto(out *PodTemplate) {
+	*out = *t
+	if t.Resources != nil {
+		out.Resources = t.Resources.DeepCopy()
+	}
+	if t.NodeSelector != nil {
+		out.NodeSelector = make(map[string]string, len(t.NodeSelector))
+		for k, v := range t.NodeSelector {
+			out.NodeSelector[k] = v
+		}
+	}
+	if t.Tolerations != nil {
+		out.Tolerations = make([]v1.Toleration, len(t.Tolerations))
+		for i := range t.Tolerations {
+			t.Tolerations[i].DeepCopyInto(&out.Tolerations[i])
+		}
+	}
+}
+
+// DeepCopy creates a copy of the pod template
+func (t *PodTemplate) DeepCopy() *PodTemplate {
+	if t == nil {
+		return nil
+	}
+	out := new(PodTemplate)
+	t.DeepCopyInto(out)
+	return out
+}
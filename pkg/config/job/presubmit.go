@@ -35,12 +35,12 @@ type Presubmit struct {
 	Optional bool `json:"optional,omitempty"`
 	// Trigger is the regular expression to trigger the job.
 	// e.g. `@k8s-bot e2e test this`
-	// RerunCommand must also be specified if this field is specified.
-	// (Default: `(?m)^/test (?:.*? )?<job name>(?: .*?)?$`)
+	// (Default: matches the RerunCommand if it is specified, otherwise
+	// `/test <job name>` or `/test <context>`)
 	Trigger string `json:"trigger,omitempty"`
 	// The RerunCommand to give users. Must match Trigger.
-	// Trigger must also be specified if this field is specified.
-	// (Default: `/test <job name>`)
+	// (Default: `/test <job name>` or `/test <context>`, whichever matches
+	// Trigger)
 	RerunCommand string       `json:"rerun_command,omitempty"`
 	JenkinsSpec  *JenkinsSpec `json:"jenkins_spec,omitempty"`
 
@@ -54,12 +54,17 @@ func (p *Presubmit) SetDefaults(namespace string) {
 	if p.Context == "" {
 		p.Context = p.Name
 	}
-	// Default the values of Trigger and RerunCommand if both fields are
-	// specified. Otherwise let validation fail as both or neither should have
-	// been specified.
-	if p.Trigger == "" && p.RerunCommand == "" {
-		p.Trigger = util.DefaultTriggerFor(p.Name)
+	// Derive the values of Trigger and RerunCommand which are not specified
+	// from the other one, or from the name and context of the job. Validation
+	// fails if no rerun command matching the trigger can be derived.
+	switch {
+	case p.Trigger == "" && p.RerunCommand == "":
+		p.Trigger = util.DefaultTriggerFor(p.Name, p.Context)
 		p.RerunCommand = util.DefaultRerunCommandFor(p.Name)
+	case p.Trigger == "":
+		p.Trigger = util.TriggerForRerunCommand(p.RerunCommand)
+	case p.RerunCommand == "":
+		p.RerunCommand = util.DeriveRerunCommand(p.Trigger, p.Name, p.Context)
 	}
 }
 
@@ -150,7 +155,7 @@ func (p Presubmit) TriggerMatches(body string) bool {
 
 // ContextRequired checks whether a context is required from github points of view (required check).
 func (p Presubmit) ContextRequired() bool {
-	return !p.Optional && !p.SkipReport
+	return !p.Optional && p.ReportsContext()
 }
 
 // Validate validates job base
@@ -161,7 +166,10 @@ func (p *Presubmit) Validate(podNamespace string) error {
 	if p.AlwaysRun && p.RunIfChanged != "" {
 		return fmt.Errorf("job %s is set to always run but also declares run_if_changed targets, which are mutually exclusive", p.Name)
 	}
-	if !p.SkipReport && p.Context == "" {
+	if err := p.ValidateReport(PresubmitJob); err != nil {
+		return fmt.Errorf("invalid presubmit job %s: %v", p.Name, err)
+	}
+	if p.ReportsContext() && p.Context == "" {
 		return fmt.Errorf("job %s is set to report but has no context configured", p.Name)
 	}
 	return nil
@@ -0,0 +1,60 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import "fmt"
+
+// Release runs when a git tag is pushed, e.g. when a release is published on the git provider.
+// The tag name is exposed to the pipeline as the base ref and the TAG_NAME environment variable.
+// Release webhooks are ignored as they do not include the tag of the release, the push of the tag triggers the job.
+type Release struct {
+	Base
+	Reporter
+	// Only run against tags matching these regexes. Default is all tags.
+	Tags []string `json:"tags,omitempty"`
+	// Do not run against tags matching these regexes. Default is no tags.
+	SkipTags []string `json:"skip_tags,omitempty"`
+
+	// We'll set this when we load it.
+	tagMatcher *Brancher
+}
+
+// SetDefaults initializes default values
+func (r *Release) SetDefaults(namespace string) {
+	r.Base.SetDefaults(namespace)
+	if r.Context == "" {
+		r.Context = r.Name
+	}
+}
+
+// SetRegexes compiles and validates all the regular expressions
+func (r *Release) SetRegexes() error {
+	b, err := Brancher{Branches: r.Tags, SkipBranches: r.SkipTags}.SetBrancherRegexes()
+	if err != nil {
+		return fmt.Errorf("could not set tag regexes for %s: %v", r.Name, err)
+	}
+	r.tagMatcher = &b
+	return nil
+}
+
+// ShouldRun determines if the release job should run for the given tag
+func (r Release) ShouldRun(tag string) bool {
+	if r.tagMatcher != nil {
+		return r.tagMatcher.ShouldRun(tag)
+	}
+	return Brancher{Branches: r.Tags, SkipBranches: r.SkipTags}.ShouldRun(tag)
+}
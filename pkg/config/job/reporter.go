@@ -16,6 +16,27 @@ limitations under the License.
 
 package job
 
+import (
+	"fmt"
+	"strings"
+)
+
+// ReportMode is a way the results of a job are reported
+type ReportMode string
+
+const (
+	// ReportStatus reports the results as a commit status
+	ReportStatus ReportMode = "status"
+	// ReportCheck reports the results as a check run, or as a commit status if the SCM provider does not support them
+	ReportCheck ReportMode = "check"
+	// ReportComment summarizes the failed presubmits of a pull request in a comment
+	ReportComment ReportMode = "comment"
+	// ReportIssue opens an issue when a postsubmit or periodic fails, or comments on the issue already open for it
+	ReportIssue ReportMode = "issue"
+	// ReportNone does not report the results at all, e.g. for experimental jobs
+	ReportNone ReportMode = "none"
+)
+
 // Reporter keeps various details for status reporting
 type Reporter struct {
 	// Context is the name of the GitHub status context for the job.
@@ -23,4 +44,69 @@ type Reporter struct {
 	Context string `json:"context,omitempty"`
 	// SkipReport skips commenting and setting status on GitHub.
 	SkipReport bool `json:"skip_report,omitempty"`
+	// Report lists how the results of the job are reported: status, check, comment, issue or none.
+	// Defaults: a commit status, or a check run if they are enabled for the repository, and a comment.
+	Report []ReportMode `json:"report,omitempty"`
+}
+
+// ReportModes returns how the results of the job are reported, none if SkipReport is set or nil for the defaults
+func (r Reporter) ReportModes() []ReportMode {
+	if r.SkipReport {
+		return []ReportMode{ReportNone}
+	}
+	return r.Report
+}
+
+// ReportsContext returns whether the job reports its results on its context, as a commit status or a check run
+func (r Reporter) ReportsContext() bool {
+	modes := r.ReportModes()
+	return Reports(modes, ReportStatus) || Reports(modes, ReportCheck)
+}
+
+// ValidateReport validates the report modes of a job of the given type
+func (r Reporter) ValidateReport(jobType PipelineKind) error {
+	if r.SkipReport && len(r.Report) > 0 && !(len(r.Report) == 1 && r.Report[0] == ReportNone) {
+		return fmt.Errorf("skip_report cannot be set with report modes other than %s", ReportNone)
+	}
+	return validateReportModes(r.Report, jobType)
+}
+
+// Reports returns whether the report modes of a job include the mode, the default modes being the status and the
+// comment
+func Reports(modes []ReportMode, mode ReportMode) bool {
+	if len(modes) == 0 {
+		return mode == ReportStatus || mode == ReportComment
+	}
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+func validateReportModes(modes []ReportMode, jobType PipelineKind) error {
+	for _, m := range modes {
+		switch m {
+		case ReportStatus, ReportCheck, ReportComment:
+			if jobType == PeriodicJob {
+				return fmt.Errorf("the %s report mode is not supported by periodics", m)
+			}
+		case ReportIssue:
+			if jobType != PostsubmitJob && jobType != PeriodicJob {
+				return fmt.Errorf("the %s report mode is only supported by postsubmits and periodics", m)
+			}
+		case ReportNone:
+			if len(modes) > 1 {
+				return fmt.Errorf("the %s report mode cannot be combined with other modes", m)
+			}
+		default:
+			var valid []string
+			for _, v := range []ReportMode{ReportStatus, ReportCheck, ReportComment, ReportIssue, ReportNone} {
+				valid = append(valid, string(v))
+			}
+			return fmt.Errorf("unknown report mode %q, valid modes are %s", m, strings.Join(valid, ", "))
+		}
+	}
+	return nil
 }
@@ -0,0 +1,22 @@
+package job
+
+import "fmt"
+
+// maxRetries caps the automatic retries of a job so that a broken cluster does not trigger endless retries
+const maxRetries = 10
+
+// RetryPolicy configures the automatic retries of a job
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of times the job is retried when it fails because of its infrastructure,
+	// e.g. its pod was evicted or an image could not be pulled. Jobs which fail because of their configuration or
+	// tests are never retried.
+	MaxRetries int `json:"max_retries,omitempty"`
+}
+
+// Validate validates the retry policy
+func (r *RetryPolicy) Validate() error {
+	if r.MaxRetries < 0 || r.MaxRetries > maxRetries {
+		return fmt.Errorf("max_retries: %d must be between 0 and %d", r.MaxRetries, maxRetries)
+	}
+	return nil
+}
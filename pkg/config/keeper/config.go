@@ -19,6 +19,8 @@ package keeper
 import (
 	"fmt"
 	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/labels"
 )
 
 // Config is the config for the keeper pool.
@@ -82,6 +84,30 @@ type Config struct {
 	//  0 => unlimited batch size
 	// -1 => batch merging disabled :(
 	BatchSizeLimitMap map[string]int `json:"batch_size_limit,omitempty"`
+	// MaxCommitsBehindMap is a key/value pair of an org or org/repo as the key and
+	// the number of commits the base branch may have advanced since a presubmit ran
+	// before the PR is retested prior to merging. The "*" key can be used as a
+	// global default.
+	// Special values:
+	//  0 => presubmits must have run against the current base branch HEAD
+	MaxCommitsBehindMap map[string]int `json:"max_commits_behind,omitempty"`
+	// MergeTrainSizeMap is a key/value pair of an org or org/repo as the key and
+	// the maximum number of PRs queued in the merge train of each branch as the value.
+	// Each PR of a merge train is tested on top of the PRs ahead of it in the train.
+	// The "*" key can be used as a global default.
+	// Special values:
+	//  0 => merge trains disabled
+	MergeTrainSizeMap map[string]int `json:"merge_train_size,omitempty"`
+	// ReviewRequirementsMap is a key/value pair of an org or org/repo as the key and
+	// the review requirements of its PRs as the value. The "*" key can be used as a
+	// global default.
+	ReviewRequirementsMap map[string]ReviewRequirements `json:"review_requirements,omitempty"`
+	// Freezes declares windows during which Keeper does not merge PRs into some branches,
+	// except the PRs with the merge override label.
+	Freezes []BranchFreeze `json:"freezes,omitempty"`
+	// MergeOverrideLabel is the label of the PRs which are merged even though their branch is
+	// frozen or blocked by an issue. Defaults to tide/merge-override.
+	MergeOverrideLabel string `json:"merge_override_label,omitempty"`
 }
 
 // MergeMethod returns the merge method to use for a repo. The default of merge is
@@ -114,6 +140,51 @@ func (c *Config) BatchSizeLimit(org, repo string) int {
 	//return t.BatchSizeLimitMap["*"]
 }
 
+// MaxCommitsBehind returns how many commits the base branch of the given repo may have
+// advanced since a presubmit ran for its result to still be used when merging
+func (c *Config) MaxCommitsBehind(org, repo string) int {
+	if behind, ok := c.MaxCommitsBehindMap[fmt.Sprintf("%s/%s", org, repo)]; ok {
+		return behind
+	}
+	if behind, ok := c.MaxCommitsBehindMap[org]; ok {
+		return behind
+	}
+	return c.MaxCommitsBehindMap["*"]
+}
+
+// MergeTrainSize returns the maximum number of PRs in the merge trains of the given repo,
+// 0 if merge trains are disabled
+func (c *Config) MergeTrainSize(org, repo string) int {
+	if size, ok := c.MergeTrainSizeMap[fmt.Sprintf("%s/%s", org, repo)]; ok {
+		return size
+	}
+	if size, ok := c.MergeTrainSizeMap[org]; ok {
+		return size
+	}
+	return c.MergeTrainSizeMap["*"]
+}
+
+// ReviewRequirements returns the requirements on the reviews of the PRs of the given repo
+func (c *Config) ReviewRequirements(org, repo string) ReviewRequirements {
+	if reqs, ok := c.ReviewRequirementsMap[fmt.Sprintf("%s/%s", org, repo)]; ok {
+		return reqs
+	}
+	if reqs, ok := c.ReviewRequirementsMap[org]; ok {
+		return reqs
+	}
+	return c.ReviewRequirementsMap["*"]
+}
+
+// FreezeFor returns the freeze of the given branch at the given time, or nil if it is not frozen
+func (c *Config) FreezeFor(org, repo, branch string, now time.Time) *BranchFreeze {
+	for i := range c.Freezes {
+		if c.Freezes[i].Applies(org, repo, branch, now) {
+			return &c.Freezes[i]
+		}
+	}
+	return nil
+}
+
 // MergeCommitTemplate returns a struct with Go template string(s) or nil
 func (c *Config) MergeCommitTemplate(org, repo string) MergeCommitTemplate {
 	name := org + "/" + repo
@@ -157,6 +228,29 @@ func (c *Config) Parse() error {
 			return fmt.Errorf("merge type %q for %s is not a valid type", method, name)
 		}
 	}
+	for name, behind := range c.MaxCommitsBehindMap {
+		if behind < 0 {
+			return fmt.Errorf("keeper has invalid max_commits_behind (%d) for %s, it cannot be negative", behind, name)
+		}
+	}
+	for name, size := range c.MergeTrainSizeMap {
+		if size < 0 {
+			return fmt.Errorf("keeper has invalid merge_train_size (%d) for %s, it cannot be negative", size, name)
+		}
+	}
+	for name, reqs := range c.ReviewRequirementsMap {
+		if reqs.MinApprovals < 0 {
+			return fmt.Errorf("keeper has invalid review_requirements for %s, min_approvals (%d) cannot be negative", name, reqs.MinApprovals)
+		}
+	}
+	for i := range c.Freezes {
+		if err := c.Freezes[i].Parse(); err != nil {
+			return fmt.Errorf("keeper freeze (index %d) is invalid: %v", i, err)
+		}
+	}
+	if c.MergeOverrideLabel == "" {
+		c.MergeOverrideLabel = labels.MergeOverride
+	}
 	for i, tq := range c.Queries {
 		if err := tq.Validate(); err != nil {
 			return fmt.Errorf("keeper query (index %d) is invalid: %v", i, err)
@@ -0,0 +1,110 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keeper
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BranchFreeze declares a window during which Keeper does not merge PRs into some branches,
+// e.g. to stabilize a release. PRs with the merge override label are still merged.
+type BranchFreeze struct {
+	// Repos is either of the form org/repo or just org.
+	Repos []string `json:"repos,omitempty"`
+	// Branches are the frozen branches. All the branches are frozen if empty.
+	Branches []string `json:"branches,omitempty"`
+	// StartString is the RFC3339 time the freeze starts at, compiles into Start at load time.
+	// The freeze is effective immediately if empty.
+	StartString string `json:"start,omitempty"`
+	// EndString is the RFC3339 time the freeze ends at, compiles into End at load time.
+	// The freeze lasts until it is removed from the config if empty.
+	EndString string `json:"end,omitempty"`
+	// Reason is displayed in the Keeper status context of the PRs which are not merged.
+	Reason string `json:"reason,omitempty"`
+
+	Start time.Time `json:"-"`
+	End   time.Time `json:"-"`
+}
+
+// Parse compiles the start and end times of the freeze
+func (f *BranchFreeze) Parse() error {
+	if len(f.Repos) == 0 {
+		return fmt.Errorf("at least one org or org/repo must be frozen")
+	}
+	var err error
+	if f.StartString != "" {
+		if f.Start, err = time.Parse(time.RFC3339, f.StartString); err != nil {
+			return fmt.Errorf("cannot parse start time: %v", err)
+		}
+	}
+	if f.EndString != "" {
+		if f.End, err = time.Parse(time.RFC3339, f.EndString); err != nil {
+			return fmt.Errorf("cannot parse end time: %v", err)
+		}
+	}
+	if !f.Start.IsZero() && !f.End.IsZero() && !f.End.After(f.Start) {
+		return fmt.Errorf("end time %s must be after start time %s", f.EndString, f.StartString)
+	}
+	return nil
+}
+
+// Applies returns true if the freeze covers the given branch at the given time
+func (f *BranchFreeze) Applies(org, repo, branch string, now time.Time) bool {
+	if !f.Start.IsZero() && now.Before(f.Start) {
+		return false
+	}
+	if !f.End.IsZero() && !now.Before(f.End) {
+		return false
+	}
+	matchesRepo := false
+	for _, r := range f.Repos {
+		if r == org || r == org+"/"+repo {
+			matchesRepo = true
+			break
+		}
+	}
+	if !matchesRepo {
+		return false
+	}
+	if len(f.Branches) == 0 {
+		return true
+	}
+	for _, b := range f.Branches {
+		if b == branch {
+			return true
+		}
+	}
+	return false
+}
+
+// Description describes the freeze for status contexts
+func (f *BranchFreeze) Description() string {
+	var sb strings.Builder
+	sb.WriteString("Merging is frozen")
+	if !f.End.IsZero() {
+		sb.WriteString(" until ")
+		sb.WriteString(f.End.UTC().Format("2006-01-02 15:04 MST"))
+	}
+	if f.Reason != "" {
+		sb.WriteString(": ")
+		sb.WriteString(f.Reason)
+	}
+	sb.WriteString(".")
+	return sb.String()
+}
@@ -0,0 +1,42 @@
+package keeper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReviewRequirements are the requirements on the reviews of a PR for Keeper to merge it,
+// in addition to those of the queries.
+type ReviewRequirements struct {
+	// MinApprovals is the minimum number of reviewers whose latest review approves the PR.
+	MinApprovals int `json:"min_approvals,omitempty"`
+	// NoChangesRequested prevents merging PRs with a reviewer whose latest review requests changes.
+	NoChangesRequested bool `json:"no_changes_requested,omitempty"`
+	// ResolvedDiscussions prevents merging PRs with unresolved discussions. Only supported for GitLab.
+	ResolvedDiscussions bool `json:"resolved_discussions,omitempty"`
+}
+
+// NeedsReviews returns whether the reviews of a PR must be listed to check the requirements
+func (r ReviewRequirements) NeedsReviews() bool {
+	return r.MinApprovals > 0 || r.NoChangesRequested
+}
+
+// Unmet describes the requirements a PR does not meet, given the logins of the reviewers
+// approving it and requesting changes and whether it has unresolved discussions
+func (r ReviewRequirements) Unmet(approvers, changesRequestedBy []string, unresolvedDiscussions bool) []string {
+	var unmet []string
+	if len(approvers) < r.MinApprovals {
+		s := ""
+		if r.MinApprovals > 1 {
+			s = "s"
+		}
+		unmet = append(unmet, fmt.Sprintf("Needs %d approving review%s, has %d.", r.MinApprovals, s, len(approvers)))
+	}
+	if r.NoChangesRequested && len(changesRequestedBy) > 0 {
+		unmet = append(unmet, fmt.Sprintf("Changes requested by %s.", strings.Join(changesRequestedBy, ", ")))
+	}
+	if r.ResolvedDiscussions && unresolvedDiscussions {
+		unmet = append(unmet, "Has unresolved discussions.")
+	}
+	return unmet
+}
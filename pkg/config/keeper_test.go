@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/jenkins-x/lighthouse/pkg/config/branchprotection"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
@@ -14,6 +15,7 @@ func TestConfigGetKeeperContextPolicy(t *testing.T) {
 		name                 string
 		bpOrgs               map[string]branchprotection.Org
 		presubmits           []job.Presubmit
+		monorepo             *job.Monorepo
 		skipUnknownContexts  bool
 		fromBranchProtection bool
 
@@ -278,6 +280,31 @@ func TestConfigGetKeeperContextPolicy(t *testing.T) {
 			expectedIfPresent:   []string{"run-if-changed", "not-always"},
 			expectedOptional:    []string{"optional"},
 		},
+		{
+			name: "monorepo",
+			presubmits: []job.Presubmit{
+				{
+					Base:      job.Base{Name: "foo"},
+					AlwaysRun: true,
+					Reporter:  job.Reporter{Context: "foo"},
+				},
+				{
+					Base:      job.Base{Name: "lint"},
+					AlwaysRun: true,
+					Reporter:  job.Reporter{Context: "lint"},
+				},
+			},
+			monorepo: &job.Monorepo{
+				Context: "monorepo",
+				Groups: []job.MonorepoGroup{{
+					Name:  "foo",
+					Paths: []string{"foo/**"},
+					Jobs:  []string{"foo"},
+				}},
+			},
+			expectedRequired:  []string{"lint", "monorepo"},
+			expectedIfPresent: []string{"foo"},
+		},
 	}
 
 	for _, tc := range cases {
@@ -305,6 +332,9 @@ func TestConfigGetKeeperContextPolicy(t *testing.T) {
 					},
 				},
 			}
+			if tc.monorepo != nil {
+				cfg.Monorepos = map[string]job.Monorepo{"o/r": *tc.monorepo}
+			}
 			if tc.bpOrgs != nil {
 				cfg.ProwConfig.BranchProtection = branchprotection.Config{
 					ProtectTested: true,
@@ -321,3 +351,79 @@ func TestConfigGetKeeperContextPolicy(t *testing.T) {
 		})
 	}
 }
+
+func TestKeeperFreezeFor(t *testing.T) {
+	k := keeper.Config{
+		Freezes: []keeper.BranchFreeze{
+			{
+				Repos:       []string{"org/repo"},
+				Branches:    []string{"release-1.0"},
+				StartString: "2020-06-01T00:00:00Z",
+				EndString:   "2020-06-15T00:00:00Z",
+				Reason:      "1.0 stabilization",
+			},
+			{
+				Repos: []string{"frozen-org"},
+			},
+		},
+	}
+	assert.NoError(t, k.Parse())
+
+	during := time.Date(2020, 6, 10, 0, 0, 0, 0, time.UTC)
+	after := time.Date(2020, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		org            string
+		repo           string
+		branch         string
+		now            time.Time
+		expectedReason string
+		expectedFrozen bool
+	}{
+		{name: "frozen branch during the window", org: "org", repo: "repo", branch: "release-1.0", now: during, expectedFrozen: true, expectedReason: "1.0 stabilization"},
+		{name: "frozen branch after the window", org: "org", repo: "repo", branch: "release-1.0", now: after},
+		{name: "other branch", org: "org", repo: "repo", branch: "master", now: during},
+		{name: "other repo", org: "org", repo: "other", branch: "release-1.0", now: during},
+		{name: "whole org without window", org: "frozen-org", repo: "any", branch: "master", now: after, expectedFrozen: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			freeze := k.FreezeFor(tc.org, tc.repo, tc.branch, tc.now)
+			if !tc.expectedFrozen {
+				assert.Nil(t, freeze)
+				return
+			}
+			if assert.NotNil(t, freeze) {
+				assert.Equal(t, tc.expectedReason, freeze.Reason)
+			}
+		})
+	}
+	assert.Equal(t, "tide/merge-override", k.MergeOverrideLabel)
+
+	invalid := keeper.Config{Freezes: []keeper.BranchFreeze{{Repos: []string{"org"}, StartString: "2020-06-15T00:00:00Z", EndString: "2020-06-01T00:00:00Z"}}}
+	assert.Error(t, invalid.Parse())
+	invalid = keeper.Config{Freezes: []keeper.BranchFreeze{{StartString: "tomorrow"}}}
+	assert.Error(t, invalid.Parse())
+}
+
+func TestKeeperReviewRequirements(t *testing.T) {
+	k := keeper.Config{
+		ReviewRequirementsMap: map[string]keeper.ReviewRequirements{
+			"*":        {MinApprovals: 1},
+			"org":      {MinApprovals: 2, NoChangesRequested: true},
+			"org/repo": {ResolvedDiscussions: true},
+		},
+	}
+	assert.NoError(t, k.Parse())
+	assert.Equal(t, keeper.ReviewRequirements{ResolvedDiscussions: true}, k.ReviewRequirements("org", "repo"))
+	assert.Equal(t, keeper.ReviewRequirements{MinApprovals: 2, NoChangesRequested: true}, k.ReviewRequirements("org", "other"))
+	assert.Equal(t, keeper.ReviewRequirements{MinApprovals: 1}, k.ReviewRequirements("other", "repo"))
+
+	reqs := k.ReviewRequirements("org", "other")
+	assert.Empty(t, reqs.Unmet([]string{"alice", "bob"}, nil, true))
+	assert.Equal(t, []string{"Needs 2 approving reviews, has 1.", "Changes requested by carol."}, reqs.Unmet([]string{"alice"}, []string{"carol"}, false))
+
+	invalid := keeper.Config{ReviewRequirementsMap: map[string]keeper.ReviewRequirements{"org": {MinApprovals: -1}}}
+	assert.Error(t, invalid.Parse())
+}
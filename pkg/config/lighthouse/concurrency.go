@@ -0,0 +1,60 @@
+package lighthouse
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Concurrency caps the number of pipelines running simultaneously, in the whole cluster, per org and per repository,
+// so that a busy repository cannot starve the others. Triggered jobs wait in a queue until they fit within the caps,
+// the jobs with the highest priority starting first and the available capacity being shared fairly between the
+// repositories with waiting jobs of the same priority.
+type Concurrency struct {
+	// MaxPipelines is the maximum number of pipelines running in the cluster. Unlimited if 0.
+	MaxPipelines int `json:"max_pipelines,omitempty"`
+	// MaxPerOrg is the maximum number of pipelines running for the repositories of an org. Unlimited if 0.
+	MaxPerOrg int `json:"max_per_org,omitempty"`
+	// MaxPerRepo is the maximum number of pipelines running for a repository. Unlimited if 0.
+	MaxPerRepo int `json:"max_per_repo,omitempty"`
+	// Limits overrides MaxPerOrg and MaxPerRepo for some orgs and repositories, using 'org' or 'org/repo' as key.
+	// A limit of 0 means unlimited.
+	Limits map[string]int `json:"limits,omitempty"`
+}
+
+// Parse initializes and validates the Config
+func (c *Concurrency) Parse() error {
+	if c.MaxPipelines < 0 {
+		return fmt.Errorf("concurrency.max_pipelines (%d) must be a non-negative number", c.MaxPipelines)
+	}
+	if c.MaxPerOrg < 0 {
+		return fmt.Errorf("concurrency.max_per_org (%d) must be a non-negative number", c.MaxPerOrg)
+	}
+	if c.MaxPerRepo < 0 {
+		return fmt.Errorf("concurrency.max_per_repo (%d) must be a non-negative number", c.MaxPerRepo)
+	}
+	for key, limit := range c.Limits {
+		if key == "" || strings.Count(key, "/") > 1 {
+			return fmt.Errorf("concurrency.limits key %q must be 'org' or 'org/repo'", key)
+		}
+		if limit < 0 {
+			return fmt.Errorf("concurrency.limits of %s (%d) must be a non-negative number", key, limit)
+		}
+	}
+	return nil
+}
+
+// OrgLimit returns the maximum number of pipelines running for the repositories of the org, 0 meaning unlimited
+func (c *Concurrency) OrgLimit(org string) int {
+	if limit, ok := c.Limits[org]; ok {
+		return limit
+	}
+	return c.MaxPerOrg
+}
+
+// RepoLimit returns the maximum number of pipelines running for the repository, 0 meaning unlimited
+func (c *Concurrency) RepoLimit(org, repo string) int {
+	if limit, ok := c.Limits[org+"/"+repo]; ok {
+		return limit
+	}
+	return c.MaxPerRepo
+}
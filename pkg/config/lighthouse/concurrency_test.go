@@ -0,0 +1,36 @@
+package lighthouse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrency(t *testing.T) {
+	c := Concurrency{
+		MaxPerOrg:  10,
+		MaxPerRepo: 2,
+		Limits: map[string]int{
+			"big":         20,
+			"org/mono":    5,
+			"org/nolimit": 0,
+		},
+	}
+	assert.NoError(t, c.Parse())
+	assert.Equal(t, 10, c.OrgLimit("org"))
+	assert.Equal(t, 20, c.OrgLimit("big"))
+	assert.Equal(t, 2, c.RepoLimit("org", "repo"))
+	assert.Equal(t, 5, c.RepoLimit("org", "mono"))
+	assert.Equal(t, 0, c.RepoLimit("org", "nolimit"))
+
+	invalid := []Concurrency{
+		{MaxPipelines: -1},
+		{MaxPerOrg: -1},
+		{MaxPerRepo: -1},
+		{Limits: map[string]int{"org/repo": -1}},
+		{Limits: map[string]int{"org/repo/extra": 1}},
+	}
+	for _, c := range invalid {
+		assert.Error(t, c.Parse(), "%+v", c)
+	}
+}
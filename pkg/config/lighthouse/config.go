@@ -18,8 +18,10 @@ package lighthouse
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/jenkins-x/lighthouse/pkg/config/branchprotection"
 	"github.com/jenkins-x/lighthouse/pkg/config/keeper"
@@ -66,6 +68,24 @@ type Config struct {
 	GitHubOptions GitHubOptions `json:"github,omitempty"`
 	// ProviderConfig contains optional SCM provider information
 	ProviderConfig *ProviderConfig `json:"providerConfig,omitempty"`
+	// GitHubChecks configures which repositories report pipeline results as GitHub Check Runs
+	GitHubChecks GitHubChecks `json:"github_checks,omitempty"`
+	// RepoFilter configures which repositories Lighthouse acts on when receiving org level webhooks
+	RepoFilter RepoFilter `json:"repo_filter,omitempty"`
+	// WebhookDedupe configures how redelivered webhooks are detected
+	WebhookDedupe WebhookDedupe `json:"webhook_dedupe,omitempty"`
+	// Concurrency caps the number of pipelines running simultaneously in the cluster, per org and per repository
+	Concurrency Concurrency `json:"concurrency,omitempty"`
+	// Notifications configures the messages posted to Slack or Microsoft Teams about pipelines and merges
+	Notifications Notifications `json:"notifications,omitempty"`
+	// Maintenance declares the windows during which the periodic jobs are skipped and keeper stops merging
+	Maintenance Maintenance `json:"maintenance,omitempty"`
+	// FailureIssues configures the tracking issues opened when the postsubmits of the main branch fail repeatedly
+	FailureIssues FailureIssues `json:"failure_issues,omitempty"`
+	// ReviewComments configures the inline review comments created on pull requests for the failures of their presubmits
+	ReviewComments ReviewComments `json:"review_comments,omitempty"`
+	// Pauses are the components paused at runtime through the admin API, they are not read from the configuration
+	Pauses Pauses `json:"-"`
 }
 
 // Parse initializes and validates the Config
@@ -100,6 +120,27 @@ func (c *Config) Parse() error {
 	if err := c.GitHubOptions.Parse(); err != nil {
 		return err
 	}
+	if err := c.RepoFilter.Parse(); err != nil {
+		return err
+	}
+	if err := c.WebhookDedupe.Parse(); err != nil {
+		return err
+	}
+	if err := c.Concurrency.Parse(); err != nil {
+		return err
+	}
+	if err := c.Notifications.Parse(); err != nil {
+		return err
+	}
+	if err := c.Maintenance.Parse(); err != nil {
+		return err
+	}
+	if err := c.FailureIssues.Parse(); err != nil {
+		return err
+	}
+	if err := c.ReviewComments.Parse(); err != nil {
+		return err
+	}
 	if c.LogLevel == "" {
 		c.LogLevel = os.Getenv("LOG_LEVEL")
 		if c.LogLevel == "" {
@@ -109,6 +150,39 @@ func (c *Config) Parse() error {
 	return nil
 }
 
+// FreezeFor returns the freeze of the given branch at the given time, or nil if it is not frozen. A maintenance window
+// pausing the merges of the repository freezes all its branches until the end of its current occurrence, and pausing
+// the merges of keeper through the admin API until they are resumed.
+func (c *Config) FreezeFor(org, repo, branch string, now time.Time) *keeper.BranchFreeze {
+	if freeze := c.Keeper.FreezeFor(org, repo, branch, now); freeze != nil {
+		return freeze
+	}
+	if pause := c.Pauses.Paused(PauseMerges, org, repo); pause != nil {
+		return &keeper.BranchFreeze{
+			Repos:  []string{org + "/" + repo},
+			Reason: "keeper " + pause.Description(),
+			Start:  pause.Since,
+		}
+	}
+	window, period := c.Maintenance.Paused(PauseMerges, org, repo, now)
+	if window == nil {
+		return nil
+	}
+	reason := "maintenance window " + window.Name
+	if detail := window.Reason; detail != "" || period.Summary != "" {
+		if detail == "" {
+			detail = period.Summary
+		}
+		reason = fmt.Sprintf("%s, %s", reason, detail)
+	}
+	return &keeper.BranchFreeze{
+		Repos:  []string{org + "/" + repo},
+		Reason: reason,
+		Start:  period.Start,
+		End:    period.End,
+	}
+}
+
 // InRepoConfig to enable configuration inside the source code of a repository
 //
 // this struct mirrors the similar struct inside prow
@@ -121,15 +195,21 @@ type InRepoConfig struct {
 
 // InRepoConfigEnabled returns whether InRepoConfig is enabled for a given repository.
 func (c *Config) InRepoConfigEnabled(identifier string) bool {
-	if c.InRepoConfig.Enabled[identifier] != nil {
-		return *c.InRepoConfig.Enabled[identifier]
+	return narrowestMatch(c.InRepoConfig.Enabled, identifier)
+}
+
+// narrowestMatch looks up the flag for an 'org/repo' identifier in a map keyed by '*', 'org' or 'org/repo',
+// preferring the narrowest match. It returns false if there is no match at all.
+func narrowestMatch(enabled map[string]*bool, identifier string) bool {
+	if enabled[identifier] != nil {
+		return *enabled[identifier]
 	}
 	identifierSlashSplit := strings.Split(identifier, "/")
-	if len(identifierSlashSplit) == 2 && c.InRepoConfig.Enabled[identifierSlashSplit[0]] != nil {
-		return *c.InRepoConfig.Enabled[identifierSlashSplit[0]]
+	if len(identifierSlashSplit) == 2 && enabled[identifierSlashSplit[0]] != nil {
+		return *enabled[identifierSlashSplit[0]]
 	}
-	if c.InRepoConfig.Enabled["*"] != nil {
-		return *c.InRepoConfig.Enabled["*"]
+	if enabled["*"] != nil {
+		return *enabled["*"]
 	}
 	return false
 }
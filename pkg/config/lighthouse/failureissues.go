@@ -0,0 +1,56 @@
+package lighthouse
+
+import (
+	"fmt"
+)
+
+const (
+	// defaultFailureIssueThreshold is the number of consecutive failures of a postsubmit before its tracking issue is
+	// opened
+	defaultFailureIssueThreshold = 2
+)
+
+// defaultFailureIssueBranches are the main branches whose postsubmits are tracked by default
+var defaultFailureIssueBranches = []string{"main", "master"}
+
+// FailureIssues configures the tracking issues opened when the postsubmits of the main branch of a repository fail
+// repeatedly. The issues are assigned to the authors of the commits since the last successful run and closed once the
+// postsubmits succeed again.
+type FailureIssues struct {
+	// Enabled describes whether tracking issues are opened for a given repository. This can be set globally, per org
+	// or per repo using '*', 'org' or 'org/repo' as key. The narrowest match always takes precedence.
+	Enabled map[string]*bool `json:"enabled,omitempty"`
+	// Threshold is the number of consecutive failures of a postsubmit before its tracking issue is opened. Defaults
+	// to 2.
+	Threshold int `json:"threshold,omitempty"`
+	// Branches are the main branches whose postsubmits are tracked. Defaults to main and master.
+	Branches []string `json:"branches,omitempty"`
+}
+
+// Parse initializes and validates the Config
+func (f *FailureIssues) Parse() error {
+	if f.Threshold < 0 {
+		return fmt.Errorf("failure_issues.threshold cannot be negative: %d", f.Threshold)
+	}
+	if f.Threshold == 0 {
+		f.Threshold = defaultFailureIssueThreshold
+	}
+	if len(f.Branches) == 0 {
+		f.Branches = defaultFailureIssueBranches
+	}
+	return nil
+}
+
+// FailureIssuesEnabled returns whether tracking issues are opened for the failing postsubmits of the branch of a
+// repository
+func (c *Config) FailureIssuesEnabled(org, repo, branch string) bool {
+	if !narrowestMatch(c.FailureIssues.Enabled, fmt.Sprintf("%s/%s", org, repo)) {
+		return false
+	}
+	for _, b := range c.FailureIssues.Branches {
+		if b == branch {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package lighthouse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailureIssues(t *testing.T) {
+	enabled, disabled := true, false
+	c := Config{FailureIssues: FailureIssues{
+		Enabled: map[string]*bool{
+			"org":        &enabled,
+			"org/legacy": &disabled,
+		},
+	}}
+	assert.NoError(t, c.FailureIssues.Parse())
+	assert.Equal(t, 2, c.FailureIssues.Threshold)
+	assert.True(t, c.FailureIssuesEnabled("org", "repo", "main"))
+	assert.True(t, c.FailureIssuesEnabled("org", "repo", "master"))
+	assert.False(t, c.FailureIssuesEnabled("org", "repo", "feature"))
+	assert.False(t, c.FailureIssuesEnabled("org", "legacy", "master"))
+	assert.False(t, c.FailureIssuesEnabled("other", "repo", "master"))
+
+	c.FailureIssues.Branches = []string{"develop"}
+	assert.True(t, c.FailureIssuesEnabled("org", "repo", "develop"))
+	assert.False(t, c.FailureIssuesEnabled("org", "repo", "master"))
+
+	invalid := FailureIssues{Threshold: -1}
+	assert.Error(t, invalid.Parse())
+}
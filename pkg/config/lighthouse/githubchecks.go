@@ -0,0 +1,30 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lighthouse
+
+// GitHubChecks configures reporting pipeline results as GitHub Check Runs rather than commit statuses.
+type GitHubChecks struct {
+	// Enabled describes whether results are reported as check runs for a given repository. This can
+	// be set globally, per org or per repo using '*', 'org' or 'org/repo' as key. The
+	// narrowest match always takes precedence.
+	Enabled map[string]*bool `json:"enabled,omitempty"`
+}
+
+// CheckRunsEnabled returns whether pipeline results should be reported as check runs for a given repository.
+func (c *Config) CheckRunsEnabled(identifier string) bool {
+	return narrowestMatch(c.GitHubChecks.Enabled, identifier)
+}
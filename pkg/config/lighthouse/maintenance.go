@@ -0,0 +1,378 @@
+package lighthouse
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	cron "gopkg.in/robfig/cron.v2"
+)
+
+const (
+	// PausePeriodics skips the periodic jobs triggered during a maintenance window
+	PausePeriodics = "periodics"
+	// PauseMerges stops keeper merging pull requests during a maintenance window
+	PauseMerges = "merges"
+
+	// maxMaintenanceLookback bounds how far back the start of the current occurrence of a recurring window is looked for
+	maxMaintenanceLookback = 7 * 24 * time.Hour
+)
+
+// Maintenance declares the maintenance windows, or blackouts, during which the periodic jobs are skipped and keeper
+// stops merging, e.g. while the infrastructure is upgraded. Both resume automatically once the windows are over.
+type Maintenance struct {
+	// Windows are the maintenance windows
+	Windows []MaintenanceWindow `json:"windows,omitempty"`
+}
+
+// MaintenanceWindow is either a recurring window, starting on a cron schedule and lasting for a duration, a one-off
+// window between a start and an end time, or the events of an iCalendar.
+type MaintenanceWindow struct {
+	// Name identifies the window in the logs and the status endpoint
+	Name string `json:"name"`
+	// Reason is displayed in the keeper status context of the pull requests which are not merged and in the
+	// description of the skipped periodic jobs
+	Reason string `json:"reason,omitempty"`
+	// Repos are the 'org' or 'org/repo' the window applies to. All of them, and the periodics without repository, if
+	// empty.
+	Repos []string `json:"repos,omitempty"`
+	// Pause lists what is paused during the window, periodics and merges. Both of them if empty.
+	Pause []string `json:"pause,omitempty"`
+	// ScheduleString is the cron schedule the recurring window starts on, e.g. "0 22 * * SAT", in the time zone of
+	// the TZ= prefix if any, compiles into Schedule at load time.
+	ScheduleString string `json:"schedule,omitempty"`
+	// DurationString is how long each occurrence of a recurring window lasts, e.g. 4h, compiles into Duration at load
+	// time.
+	DurationString string `json:"duration,omitempty"`
+	// StartString is the RFC3339 time a one-off window starts at, compiles into Start at load time.
+	StartString string `json:"start,omitempty"`
+	// EndString is the RFC3339 time a one-off window ends at, compiles into End at load time.
+	EndString string `json:"end,omitempty"`
+	// ICal is an iCalendar whose events, with a DTSTART and either a DTEND or a DURATION, are the occurrences of the
+	// window. Recurring events are not supported.
+	ICal string `json:"ical,omitempty"`
+
+	Schedule    cron.Schedule       `json:"-"`
+	Duration    time.Duration       `json:"-"`
+	Start       time.Time           `json:"-"`
+	End         time.Time           `json:"-"`
+	Occurrences []MaintenancePeriod `json:"-"`
+}
+
+// MaintenancePeriod is an occurrence of a maintenance window
+type MaintenancePeriod struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Summary is the summary of the iCalendar event of the occurrence, if any
+	Summary string `json:"summary,omitempty"`
+}
+
+// Parse initializes and validates the Config
+func (m *Maintenance) Parse() error {
+	names := map[string]bool{}
+	for i := range m.Windows {
+		w := &m.Windows[i]
+		if w.Name == "" {
+			return fmt.Errorf("maintenance.windows[%d] needs a name", i)
+		}
+		if names[w.Name] {
+			return fmt.Errorf("duplicated maintenance window %s", w.Name)
+		}
+		names[w.Name] = true
+		if err := w.Parse(); err != nil {
+			return fmt.Errorf("maintenance window %s: %v", w.Name, err)
+		}
+	}
+	return nil
+}
+
+// Parse compiles the schedule, the times or the calendar of the window
+func (w *MaintenanceWindow) Parse() error {
+	for _, key := range w.Repos {
+		if key == "" || strings.Count(key, "/") > 1 {
+			return fmt.Errorf("repos %q must be 'org' or 'org/repo'", key)
+		}
+	}
+	for _, p := range w.Pause {
+		if p != PausePeriodics && p != PauseMerges {
+			return fmt.Errorf("pause %q must be %s or %s", p, PausePeriodics, PauseMerges)
+		}
+	}
+	kinds := 0
+	for _, s := range []string{w.ScheduleString, w.StartString + w.EndString, w.ICal} {
+		if s != "" {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return fmt.Errorf("exactly one of a schedule, start and end times or an ical is needed")
+	}
+	var err error
+	switch {
+	case w.ScheduleString != "":
+		if w.Schedule, err = cron.Parse(w.ScheduleString); err != nil {
+			return fmt.Errorf("invalid schedule %s: %v", w.ScheduleString, err)
+		}
+		if w.DurationString == "" {
+			return fmt.Errorf("a recurring window needs a duration")
+		}
+		if w.Duration, err = time.ParseDuration(w.DurationString); err != nil {
+			return fmt.Errorf("invalid duration %s: %v", w.DurationString, err)
+		}
+		if w.Duration <= 0 || w.Duration > maxMaintenanceLookback {
+			return fmt.Errorf("duration %s must be positive and at most %s", w.DurationString, maxMaintenanceLookback)
+		}
+	case w.ICal != "":
+		if w.Occurrences, err = parseICal(w.ICal); err != nil {
+			return fmt.Errorf("invalid ical: %v", err)
+		}
+	default:
+		if w.StartString == "" || w.EndString == "" {
+			return fmt.Errorf("a one-off window needs both a start and an end time")
+		}
+		if w.Start, err = time.Parse(time.RFC3339, w.StartString); err != nil {
+			return fmt.Errorf("cannot parse start time: %v", err)
+		}
+		if w.End, err = time.Parse(time.RFC3339, w.EndString); err != nil {
+			return fmt.Errorf("cannot parse end time: %v", err)
+		}
+		if !w.End.After(w.Start) {
+			return fmt.Errorf("end time %s must be after start time %s", w.EndString, w.StartString)
+		}
+	}
+	return nil
+}
+
+// Pauses returns whether the window pauses the periodics or the merges
+func (w *MaintenanceWindow) Pauses(what string) bool {
+	if len(w.Pause) == 0 {
+		return true
+	}
+	for _, p := range w.Pause {
+		if p == what {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches returns whether the window applies to the given repository, which is empty for the periodics without
+// repository
+func (w *MaintenanceWindow) Matches(org, repo string) bool {
+	if len(w.Repos) == 0 {
+		return true
+	}
+	for _, key := range w.Repos {
+		if key == org || key == org+"/"+repo {
+			return true
+		}
+	}
+	return false
+}
+
+// Current returns the occurrence of the window in progress at the given time, if any
+func (w *MaintenanceWindow) Current(now time.Time) (MaintenancePeriod, bool) {
+	switch {
+	case w.Schedule != nil:
+		// the latest start within the duration before now, if any, is the current occurrence
+		var current MaintenancePeriod
+		found := false
+		for start := w.Schedule.Next(now.Add(-w.Duration)); !start.After(now) && !start.IsZero(); start = w.Schedule.Next(start) {
+			current, found = MaintenancePeriod{Start: start, End: start.Add(w.Duration)}, true
+		}
+		return current, found
+	case w.Occurrences != nil:
+		for _, o := range w.Occurrences {
+			if !now.Before(o.Start) && now.Before(o.End) {
+				return o, true
+			}
+		}
+	case !w.Start.IsZero():
+		if !now.Before(w.Start) && now.Before(w.End) {
+			return MaintenancePeriod{Start: w.Start, End: w.End}, true
+		}
+	}
+	return MaintenancePeriod{}, false
+}
+
+// Next returns the next occurrence of the window starting after the given time, if any
+func (w *MaintenanceWindow) Next(now time.Time) (MaintenancePeriod, bool) {
+	switch {
+	case w.Schedule != nil:
+		if start := w.Schedule.Next(now); !start.IsZero() {
+			return MaintenancePeriod{Start: start, End: start.Add(w.Duration)}, true
+		}
+	case w.Occurrences != nil:
+		for _, o := range w.Occurrences {
+			if o.Start.After(now) {
+				return o, true
+			}
+		}
+	case !w.Start.IsZero():
+		if w.Start.After(now) {
+			return MaintenancePeriod{Start: w.Start, End: w.End}, true
+		}
+	}
+	return MaintenancePeriod{}, false
+}
+
+// Description describes the window, until the end of its current occurrence, for status contexts and job descriptions
+func (w *MaintenanceWindow) Description(period MaintenancePeriod) string {
+	var sb strings.Builder
+	sb.WriteString("Maintenance window ")
+	sb.WriteString(w.Name)
+	if !period.End.IsZero() {
+		sb.WriteString(" until ")
+		sb.WriteString(period.End.UTC().Format("2006-01-02 15:04 MST"))
+	}
+	reason := w.Reason
+	if reason == "" {
+		reason = period.Summary
+	}
+	if reason != "" {
+		sb.WriteString(": ")
+		sb.WriteString(reason)
+	}
+	sb.WriteString(".")
+	return sb.String()
+}
+
+// Paused returns the window pausing the periodics or the merges of the given repository at the given time along with
+// its current occurrence, or nil if none does
+func (m *Maintenance) Paused(what, org, repo string, now time.Time) (*MaintenanceWindow, MaintenancePeriod) {
+	for i := range m.Windows {
+		w := &m.Windows[i]
+		if !w.Pauses(what) || !w.Matches(org, repo) {
+			continue
+		}
+		if period, ok := w.Current(now); ok {
+			return w, period
+		}
+	}
+	return nil, MaintenancePeriod{}
+}
+
+// parseICal returns the events of an iCalendar, sorted by start time
+func parseICal(text string) ([]MaintenancePeriod, error) {
+	// unfold the lines continued on the next ones
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	answer := []MaintenancePeriod{}
+	var event *MaintenancePeriod
+	var duration time.Duration
+	for _, line := range lines {
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
+		name, value := line[:i], line[i+1:]
+		params := strings.Split(name, ";")
+		name = strings.ToUpper(params[0])
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			event, duration = &MaintenancePeriod{}, 0
+		case event == nil:
+			continue
+		case name == "END" && value == "VEVENT":
+			if event.Start.IsZero() {
+				return nil, fmt.Errorf("event %q has no DTSTART", event.Summary)
+			}
+			if event.End.IsZero() {
+				event.End = event.Start.Add(duration)
+			}
+			if !event.End.After(event.Start) {
+				return nil, fmt.Errorf("event %q needs a DTEND after its DTSTART or a positive DURATION", event.Summary)
+			}
+			answer = append(answer, *event)
+			event = nil
+		case name == "DTSTART" || name == "DTEND":
+			t, err := parseICalTime(value, params[1:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %s: %v", name, value, err)
+			}
+			if name == "DTSTART" {
+				event.Start = t
+			} else {
+				event.End = t
+			}
+		case name == "DURATION":
+			d, err := parseICalDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid DURATION %s: %v", value, err)
+			}
+			duration = d
+		case name == "SUMMARY":
+			event.Summary = strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\\`, `\`).Replace(value)
+		case name == "RRULE" || name == "RDATE":
+			return nil, fmt.Errorf("recurring events are not supported, use a schedule instead")
+		}
+	}
+	if event != nil {
+		return nil, fmt.Errorf("unterminated VEVENT")
+	}
+	sort.Slice(answer, func(i, j int) bool { return answer[i].Start.Before(answer[j].Start) })
+	return answer, nil
+}
+
+// parseICalTime parses a DATE-TIME in UTC, in the time zone of its TZID parameter or floating, i.e. in UTC, or a DATE
+func parseICalTime(value string, params []string) (time.Time, error) {
+	loc := time.UTC
+	for _, p := range params {
+		if strings.HasPrefix(strings.ToUpper(p), "TZID=") {
+			var err error
+			if loc, err = time.LoadLocation(strings.Trim(p[len("TZID="):], `"`)); err != nil {
+				return time.Time{}, err
+			}
+		}
+	}
+	switch {
+	case strings.HasSuffix(value, "Z"):
+		return time.Parse("20060102T150405Z", value)
+	case strings.Contains(value, "T"):
+		return time.ParseInLocation("20060102T150405", value, loc)
+	}
+	return time.ParseInLocation("20060102", value, loc)
+}
+
+// parseICalDuration parses a DURATION such as PT4H, P1D or P1DT2H30M
+func parseICalDuration(value string) (time.Duration, error) {
+	if !strings.HasPrefix(value, "P") {
+		return 0, fmt.Errorf("must start with P")
+	}
+	var d time.Duration
+	units := map[byte]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour, 'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+	n := 0
+	digits := false
+	for i := 1; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == 'T':
+		case c >= '0' && c <= '9':
+			n = n*10 + int(c-'0')
+			digits = true
+		case units[c] != 0 && digits:
+			d += time.Duration(n) * units[c]
+			n, digits = 0, false
+		default:
+			return 0, fmt.Errorf("unexpected %q", c)
+		}
+	}
+	if digits {
+		return 0, fmt.Errorf("missing unit")
+	}
+	return d, nil
+}
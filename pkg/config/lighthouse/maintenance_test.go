@@ -0,0 +1,101 @@
+package lighthouse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceWindows(t *testing.T) {
+	at := func(s string) time.Time {
+		answer, err := time.Parse(time.RFC3339, s)
+		require.NoError(t, err)
+		return answer
+	}
+	m := Maintenance{
+		Windows: []MaintenanceWindow{
+			{Name: "weekly", ScheduleString: "TZ=UTC 0 22 * * SAT", DurationString: "4h", Repos: []string{"org"}, Pause: []string{PauseMerges}},
+			{Name: "upgrade", StartString: "2020-06-01T08:00:00Z", EndString: "2020-06-01T12:00:00Z", Reason: "cluster upgrade"},
+			{Name: "calendar", Repos: []string{"other/repo"}, ICal: "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nSUMMARY:Database\r\n  migration\r\nDTSTART;TZID=Europe/Paris:20200701T100000\r\nDURATION:PT2H30M\r\nEND:VEVENT\r\nBEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20200614\r\nDTEND;VALUE=DATE:20200615\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"},
+		},
+	}
+	require.NoError(t, m.Parse())
+
+	testCases := []struct {
+		name     string
+		what     string
+		org      string
+		repo     string
+		now      string
+		expected string
+		end      string
+	}{
+		{name: "recurring", what: PauseMerges, org: "org", repo: "repo", now: "2020-06-07T01:30:00Z", expected: "weekly", end: "2020-06-07T02:00:00Z"},
+		{name: "recurring over", what: PauseMerges, org: "org", repo: "repo", now: "2020-06-07T02:00:00Z"},
+		{name: "recurring not paused", what: PausePeriodics, org: "org", repo: "repo", now: "2020-06-07T01:30:00Z"},
+		{name: "recurring other org", what: PauseMerges, org: "other", repo: "repo", now: "2020-06-07T01:30:00Z"},
+		{name: "one-off", what: PausePeriodics, now: "2020-06-01T08:00:00Z", expected: "upgrade", end: "2020-06-01T12:00:00Z"},
+		{name: "one-off over", what: PausePeriodics, now: "2020-06-01T12:00:00Z"},
+		{name: "ical", what: PausePeriodics, org: "other", repo: "repo", now: "2020-07-01T08:30:00Z", expected: "calendar", end: "2020-07-01T10:30:00Z"},
+		{name: "ical date", what: PauseMerges, org: "other", repo: "repo", now: "2020-06-14T23:00:00Z", expected: "calendar", end: "2020-06-15T00:00:00Z"},
+		{name: "ical before", what: PausePeriodics, org: "other", repo: "repo", now: "2020-07-01T07:59:59Z"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			window, period := m.Paused(tc.what, tc.org, tc.repo, at(tc.now))
+			if tc.expected == "" {
+				assert.Nil(t, window)
+				return
+			}
+			require.NotNil(t, window)
+			assert.Equal(t, tc.expected, window.Name)
+			assert.True(t, at(tc.end).Equal(period.End), "ends at %s", period.End)
+		})
+	}
+
+	next, ok := m.Windows[0].Next(at("2020-06-07T01:30:00Z"))
+	require.True(t, ok)
+	assert.True(t, at("2020-06-13T22:00:00Z").Equal(next.Start))
+	assert.Equal(t, "Maintenance window calendar until 2020-07-01 10:30 UTC: Database migration.", m.Windows[2].Description(m.Windows[2].Occurrences[1]))
+
+	invalid := []MaintenanceWindow{
+		{ScheduleString: "@daily", DurationString: "1h"},
+		{Name: "none"},
+		{Name: "both", ScheduleString: "@daily", DurationString: "1h", StartString: "2020-06-01T08:00:00Z", EndString: "2020-06-01T12:00:00Z"},
+		{Name: "no duration", ScheduleString: "@daily"},
+		{Name: "bad schedule", ScheduleString: "every day", DurationString: "1h"},
+		{Name: "too long", ScheduleString: "@daily", DurationString: "200h"},
+		{Name: "no end", StartString: "2020-06-01T08:00:00Z"},
+		{Name: "backwards", StartString: "2020-06-01T12:00:00Z", EndString: "2020-06-01T08:00:00Z"},
+		{Name: "recurring event", ICal: "BEGIN:VEVENT\nDTSTART:20200701T100000Z\nDURATION:PT1H\nRRULE:FREQ=WEEKLY\nEND:VEVENT\n"},
+		{Name: "no start", ICal: "BEGIN:VEVENT\nDTEND:20200701T100000Z\nEND:VEVENT\n"},
+		{Name: "pause", StartString: "2020-06-01T08:00:00Z", EndString: "2020-06-01T12:00:00Z", Pause: []string{"presubmits"}},
+	}
+	for _, w := range invalid {
+		m := Maintenance{Windows: []MaintenanceWindow{w}}
+		assert.Error(t, m.Parse(), "%+v", w)
+	}
+}
+
+func TestFreezeForMaintenance(t *testing.T) {
+	now := time.Now()
+	c := Config{}
+	c.Maintenance.Windows = []MaintenanceWindow{{
+		Name:        "upgrade",
+		Reason:      "cluster upgrade",
+		Repos:       []string{"org"},
+		Pause:       []string{PauseMerges},
+		StartString: now.Add(-time.Hour).UTC().Format(time.RFC3339),
+		EndString:   now.Add(time.Hour).UTC().Format(time.RFC3339),
+	}}
+	require.NoError(t, c.Maintenance.Parse())
+
+	freeze := c.FreezeFor("org", "repo", "master", now)
+	require.NotNil(t, freeze)
+	assert.Equal(t, "maintenance window upgrade, cluster upgrade", freeze.Reason)
+	assert.Equal(t, c.Maintenance.Windows[0].End, freeze.End)
+	assert.Nil(t, c.FreezeFor("other", "repo", "master", now))
+	assert.Nil(t, c.FreezeFor("org", "repo", "master", now.Add(2*time.Hour)))
+}
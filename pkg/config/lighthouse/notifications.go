@@ -0,0 +1,144 @@
+package lighthouse
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+const (
+	// NotificationKindSlack posts to a Slack incoming webhook
+	NotificationKindSlack = "slack"
+	// NotificationKindTeams posts to a Microsoft Teams incoming webhook
+	NotificationKindTeams = "teams"
+
+	// NotificationEventPipeline is the completion of a pipeline
+	NotificationEventPipeline = "pipeline"
+	// NotificationEventMerge is the merge of a pull request by keeper
+	NotificationEventMerge = "merge"
+
+	// NotificationSeverityInfo is the severity of successful pipelines and merges
+	NotificationSeverityInfo = "info"
+	// NotificationSeverityWarning is the severity of aborted pipelines
+	NotificationSeverityWarning = "warning"
+	// NotificationSeverityError is the severity of failed pipelines
+	NotificationSeverityError = "error"
+
+	// NotifyAlways posts all the pipelines
+	NotifyAlways = "always"
+	// NotifyOnFailure only posts the failed pipelines
+	NotifyOnFailure = "failure"
+	// NotifyOnChange only posts the failed pipelines and the recovered ones, i.e. the first success after a failure
+	NotifyOnChange = "change"
+)
+
+var notificationSeverities = []string{NotificationSeverityInfo, NotificationSeverityWarning, NotificationSeverityError}
+
+// Notifications configures the messages posted to Slack or Microsoft Teams channels about the outcomes of the
+// pipelines, including the periodic ones, and of the merges of keeper. Each outcome is posted to every matching route.
+type Notifications struct {
+	// Routes decide which outcomes are posted to which channels
+	Routes []NotificationRoute `json:"routes,omitempty"`
+}
+
+// NotificationRoute posts the matching outcomes to a Slack or Microsoft Teams channel
+type NotificationRoute struct {
+	// Name identifies the route in the logs
+	Name string `json:"name,omitempty"`
+	// Kind is the kind of channel, either slack or teams
+	Kind string `json:"kind"`
+	// WebhookURL is the URL of the incoming webhook of the channel
+	WebhookURL string `json:"webhook_url,omitempty"`
+	// WebhookURLEnv is the environment variable holding the URL of the incoming webhook, e.g. from a secret, used if
+	// WebhookURL is empty
+	WebhookURLEnv string `json:"webhook_url_env,omitempty"`
+	// Channel overrides the channel of the Slack incoming webhook
+	Channel string `json:"channel,omitempty"`
+	// Repos are the 'org' or 'org/repo' whose outcomes are posted. All of them, and the periodics without
+	// repository, if empty.
+	Repos []string `json:"repos,omitempty"`
+	// Events are the kinds of outcomes posted, pipeline and merge. All of them if empty.
+	Events []string `json:"events,omitempty"`
+	// JobTypes are the types of the jobs whose pipelines are posted, e.g. periodic. All of them if empty.
+	JobTypes []string `json:"job_types,omitempty"`
+	// MinSeverity is the minimum severity of the posted outcomes: info for successful pipelines and merges, warning
+	// for aborted pipelines and error for failed pipelines. Defaults to info.
+	MinSeverity string `json:"min_severity,omitempty"`
+	// When restricts the posted pipelines: always, failure to only post the failed ones, or change to only post the
+	// failed ones and the recovered ones, i.e. the first success after a failure. Defaults to always.
+	When string `json:"when,omitempty"`
+	// Template is the Go template of the messages, replacing the default ones
+	Template string `json:"template,omitempty"`
+}
+
+// Parse initializes and validates the Config
+func (n *Notifications) Parse() error {
+	for i := range n.Routes {
+		r := &n.Routes[i]
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("%d", i)
+		}
+		switch r.Kind {
+		case NotificationKindSlack, NotificationKindTeams:
+		default:
+			return fmt.Errorf("notifications.routes[%s].kind %q must be %s or %s", name, r.Kind, NotificationKindSlack, NotificationKindTeams)
+		}
+		if r.WebhookURL == "" && r.WebhookURLEnv == "" {
+			return fmt.Errorf("notifications.routes[%s] needs a webhook_url or webhook_url_env", name)
+		}
+		for _, key := range r.Repos {
+			if key == "" || strings.Count(key, "/") > 1 {
+				return fmt.Errorf("notifications.routes[%s].repos %q must be 'org' or 'org/repo'", name, key)
+			}
+		}
+		for _, event := range r.Events {
+			if event != NotificationEventPipeline && event != NotificationEventMerge {
+				return fmt.Errorf("notifications.routes[%s].events %q must be %s or %s", name, event, NotificationEventPipeline, NotificationEventMerge)
+			}
+		}
+		if r.MinSeverity == "" {
+			r.MinSeverity = NotificationSeverityInfo
+		}
+		if SeverityLevel(r.MinSeverity) < 0 {
+			return fmt.Errorf("notifications.routes[%s].min_severity %q must be one of %s", name, r.MinSeverity, strings.Join(notificationSeverities, ", "))
+		}
+		switch r.When {
+		case "":
+			r.When = NotifyAlways
+		case NotifyAlways, NotifyOnFailure, NotifyOnChange:
+		default:
+			return fmt.Errorf("notifications.routes[%s].when %q must be %s, %s or %s", name, r.When, NotifyAlways, NotifyOnFailure, NotifyOnChange)
+		}
+		if r.Template != "" {
+			if _, err := template.New(name).Parse(r.Template); err != nil {
+				return fmt.Errorf("notifications.routes[%s].template is invalid: %v", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// SeverityLevel returns the level of the severity, higher being more severe, or -1 if it is unknown
+func SeverityLevel(severity string) int {
+	for i, s := range notificationSeverities {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
+
+// Matches returns whether the route posts the outcomes of the given repository, which is empty for the periodics
+// without repository
+func (r *NotificationRoute) Matches(org, repo string) bool {
+	if len(r.Repos) == 0 {
+		return true
+	}
+	for _, key := range r.Repos {
+		if key == org || key == org+"/"+repo {
+			return true
+		}
+	}
+	return false
+}
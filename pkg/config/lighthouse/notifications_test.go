@@ -0,0 +1,37 @@
+package lighthouse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotifications(t *testing.T) {
+	n := Notifications{
+		Routes: []NotificationRoute{
+			{Kind: NotificationKindSlack, WebhookURL: "https://hooks.slack.com/services/x", Repos: []string{"org", "other/repo"}},
+			{Kind: NotificationKindTeams, WebhookURLEnv: "TEAMS_URL", Events: []string{"merge"}, MinSeverity: "error", When: "change"},
+		},
+	}
+	assert.NoError(t, n.Parse())
+	assert.Equal(t, NotificationSeverityInfo, n.Routes[0].MinSeverity)
+	assert.Equal(t, NotifyAlways, n.Routes[0].When)
+	assert.True(t, n.Routes[0].Matches("org", "repo"))
+	assert.True(t, n.Routes[0].Matches("other", "repo"))
+	assert.False(t, n.Routes[0].Matches("other", "repo2"))
+	assert.True(t, n.Routes[1].Matches("", ""))
+
+	invalid := []NotificationRoute{
+		{Kind: "irc", WebhookURL: "https://irc"},
+		{Kind: NotificationKindSlack},
+		{Kind: NotificationKindSlack, WebhookURL: "https://slack", Repos: []string{"org/repo/extra"}},
+		{Kind: NotificationKindSlack, WebhookURL: "https://slack", Events: []string{"push"}},
+		{Kind: NotificationKindSlack, WebhookURL: "https://slack", MinSeverity: "critical"},
+		{Kind: NotificationKindSlack, WebhookURL: "https://slack", When: "sometimes"},
+		{Kind: NotificationKindSlack, WebhookURL: "https://slack", Template: "{{.Job"},
+	}
+	for _, r := range invalid {
+		n := Notifications{Routes: []NotificationRoute{r}}
+		assert.Error(t, n.Parse(), "%+v", r)
+	}
+}
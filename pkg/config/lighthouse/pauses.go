@@ -0,0 +1,106 @@
+package lighthouse
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// PauseTriggers stops triggering the jobs of the repositories while they are paused
+	PauseTriggers = "triggers"
+
+	// PauseAll is the target pausing a component for all the repositories
+	PauseAll = "*"
+)
+
+// Pauses are the components paused at runtime through the admin API of keeper rather than in the configuration, keyed
+// by component, merges, periodics or triggers, then by '*', 'org' or 'org/repo' they are paused for. The narrowest match
+// always takes precedence.
+type Pauses map[string]map[string]Pause
+
+// Pause describes why and since when a component is paused
+type Pause struct {
+	// Reason is displayed in the keeper status context of the pull requests which are not merged, in the description of
+	// the skipped periodic jobs and in the errors triggering jobs
+	Reason string `json:"reason,omitempty"`
+	// By is who paused the component
+	By string `json:"by,omitempty"`
+	// Since is when the component was paused
+	Since time.Time `json:"since"`
+}
+
+// ValidatePause validates the component and the target of a pause
+func ValidatePause(component, target string) error {
+	switch component {
+	case PauseMerges, PausePeriodics, PauseTriggers:
+	default:
+		return fmt.Errorf("unknown component %q, must be %s, %s or %s", component, PauseMerges, PausePeriodics, PauseTriggers)
+	}
+	if target == PauseAll {
+		return nil
+	}
+	parts := strings.Split(target, "/")
+	if len(parts) > 2 || parts[0] == "" || len(parts) == 2 && parts[1] == "" {
+		return fmt.Errorf("target %q must be %s, org or org/repo", target, PauseAll)
+	}
+	return nil
+}
+
+// Paused returns the pause of the component for the given repository, or nil if it is not paused. The org and repo are
+// empty for the periodics without repository, only paused with the '*' target.
+func (p Pauses) Paused(component, org, repo string) *Pause {
+	targets := p[component]
+	if len(targets) == 0 {
+		return nil
+	}
+	keys := []string{PauseAll}
+	if org != "" {
+		keys = []string{org + "/" + repo, org, PauseAll}
+	}
+	for _, key := range keys {
+		if pause, ok := targets[key]; ok {
+			return &pause
+		}
+	}
+	return nil
+}
+
+// Set pauses the component for the target
+func (p Pauses) Set(component, target string, pause Pause) {
+	if p[component] == nil {
+		p[component] = map[string]Pause{}
+	}
+	p[component][target] = pause
+}
+
+// Delete resumes the component for the target, it returns false if the component was not paused for the target
+func (p Pauses) Delete(component, target string) bool {
+	if _, ok := p[component][target]; !ok {
+		return false
+	}
+	delete(p[component], target)
+	if len(p[component]) == 0 {
+		delete(p, component)
+	}
+	return true
+}
+
+// Description describes the pause for status contexts and job descriptions
+func (p *Pause) Description() string {
+	var sb strings.Builder
+	sb.WriteString("paused")
+	if p.By != "" {
+		sb.WriteString(" by ")
+		sb.WriteString(p.By)
+	}
+	if !p.Since.IsZero() {
+		sb.WriteString(" since ")
+		sb.WriteString(p.Since.UTC().Format("2006-01-02 15:04 MST"))
+	}
+	if p.Reason != "" {
+		sb.WriteString(": ")
+		sb.WriteString(p.Reason)
+	}
+	return sb.String()
+}
@@ -0,0 +1,62 @@
+package lighthouse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauses(t *testing.T) {
+	since := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+	p := Pauses{}
+	p.Set(PauseMerges, "org", Pause{Reason: "release", By: "alice", Since: since})
+	p.Set(PauseMerges, "org/repo", Pause{Reason: "migration"})
+	p.Set(PausePeriodics, PauseAll, Pause{})
+
+	assert.Equal(t, "migration", p.Paused(PauseMerges, "org", "repo").Reason)
+	assert.Equal(t, "release", p.Paused(PauseMerges, "org", "other").Reason)
+	assert.Nil(t, p.Paused(PauseMerges, "other", "repo"))
+	assert.Nil(t, p.Paused(PauseMerges, "", ""))
+	assert.NotNil(t, p.Paused(PausePeriodics, "", ""))
+	assert.NotNil(t, p.Paused(PausePeriodics, "other", "repo"))
+	assert.Nil(t, p.Paused(PauseTriggers, "org", "repo"))
+
+	assert.Equal(t, "paused by alice since 2020-06-01 08:00 UTC: release", p.Paused(PauseMerges, "org", "other").Description())
+	assert.Equal(t, "paused: migration", p.Paused(PauseMerges, "org", "repo").Description())
+
+	cfg := &Config{Pauses: p}
+	freeze := cfg.FreezeFor("org", "other", "master", since)
+	require.NotNil(t, freeze)
+	assert.Equal(t, "keeper paused by alice since 2020-06-01 08:00 UTC: release", freeze.Reason)
+	assert.Nil(t, cfg.FreezeFor("other", "repo", "master", since))
+
+	assert.True(t, p.Delete(PauseMerges, "org/repo"))
+	assert.False(t, p.Delete(PauseMerges, "org/repo"))
+	assert.True(t, p.Delete(PausePeriodics, PauseAll))
+	assert.NotContains(t, p, PausePeriodics)
+}
+
+func TestValidatePause(t *testing.T) {
+	for _, tc := range []struct {
+		component string
+		target    string
+		valid     bool
+	}{
+		{component: PauseMerges, target: PauseAll, valid: true},
+		{component: PausePeriodics, target: "org", valid: true},
+		{component: PauseTriggers, target: "org/repo", valid: true},
+		{component: "builds", target: PauseAll},
+		{component: PauseMerges, target: ""},
+		{component: PauseMerges, target: "org/"},
+		{component: PauseMerges, target: "org/repo/dir"},
+	} {
+		err := ValidatePause(tc.component, tc.target)
+		if tc.valid {
+			assert.NoError(t, err, "%s %s", tc.component, tc.target)
+		} else {
+			assert.Error(t, err, "%s %s", tc.component, tc.target)
+		}
+	}
+}
@@ -18,7 +18,11 @@ package lighthouse
 
 import (
 	"fmt"
+	"net/url"
+	"strings"
 	"text/template"
+
+	"github.com/jenkins-x/lighthouse/pkg/templates"
 )
 
 // Plank is config for the plank controller.
@@ -27,16 +31,71 @@ type Plank struct {
 	ReportTemplateString string `json:"report_template,omitempty"`
 	// ReportTemplate is compiled at load time from ReportTemplateString. It
 	// will be passed a builder.PipelineOptions and can provide an optional blurb below
-	// the test failures comment.
+	// the test failures comment. The helper functions of the templates package are available to it.
 	ReportTemplate *template.Template `json:"-"`
+	// DefaultDecorationConfigs holds the default decoration config of the jobs of the repositories, keyed by '*',
+	// 'org' or 'org/repo', the narrowest match taking precedence.
+	DefaultDecorationConfigs map[string]*DecorationConfig `json:"default_decoration_configs,omitempty"`
+}
+
+// DecorationConfig specifies how the pipelines of the jobs are augmented
+type DecorationConfig struct {
+	// GitCacheURL is the URL of the git cache the repositories are cloned from instead of the git server, e.g.
+	// http://lighthouse-gitcache. The repository org/repo is cloned from <GitCacheURL>/org/repo.git.
+	GitCacheURL string `json:"git_cache_url,omitempty"`
 }
 
 // Parse initializes and validates the Config
 func (c *Plank) Parse() error {
-	reportTmpl, err := template.New("Report").Parse(c.ReportTemplateString)
+	reportTmpl, err := template.New("Report").Funcs(templates.FuncMap()).Parse(c.ReportTemplateString)
 	if err != nil {
 		return fmt.Errorf("parsing template: %v", err)
 	}
 	c.ReportTemplate = reportTmpl
+	for key, dc := range c.DefaultDecorationConfigs {
+		if key != "*" && (key == "" || strings.Count(key, "/") > 1) {
+			return fmt.Errorf("default_decoration_configs key %q must be '*', 'org' or 'org/repo'", key)
+		}
+		if dc == nil {
+			continue
+		}
+		if err := dc.Validate(); err != nil {
+			return fmt.Errorf("invalid default_decoration_configs[%s]: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// GuessDefaultDecorationConfig returns the default decoration config of the jobs of the given repository, or nil if
+// there is none
+func (c *Plank) GuessDefaultDecorationConfig(org, repo string) *DecorationConfig {
+	for _, key := range []string{org + "/" + repo, org, "*"} {
+		if dc := c.DefaultDecorationConfigs[key]; dc != nil {
+			return dc
+		}
+	}
 	return nil
 }
+
+// Validate ensures all the values set in the DecorationConfig are valid
+func (d *DecorationConfig) Validate() error {
+	if d.GitCacheURL != "" {
+		u, err := url.Parse(d.GitCacheURL)
+		if err != nil {
+			return fmt.Errorf("invalid git_cache_url %s: %v", d.GitCacheURL, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("git_cache_url %s must be an http or https URL", d.GitCacheURL)
+		}
+	}
+	return nil
+}
+
+// GitCacheCloneURI returns the URI the given repository is cloned from through the git cache, or an empty string if
+// no git cache is configured
+func (d *DecorationConfig) GitCacheCloneURI(org, repo string) string {
+	if d == nil || d.GitCacheURL == "" || org == "" || repo == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/%s.git", strings.TrimSuffix(d.GitCacheURL, "/"), org, repo)
+}
@@ -0,0 +1,92 @@
+package lighthouse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitCacheCloneURI(t *testing.T) {
+	plank := Plank{
+		DefaultDecorationConfigs: map[string]*DecorationConfig{
+			"*":            {GitCacheURL: "http://lighthouse-gitcache/"},
+			"other":        {GitCacheURL: "https://gitcache.example.com"},
+			"other/direct": {},
+		},
+	}
+	testCases := []struct {
+		name     string
+		plank    Plank
+		org      string
+		repo     string
+		expected string
+	}{
+		{
+			name: "no decoration config",
+			org:  "org",
+			repo: "repo",
+		},
+		{
+			name:     "default",
+			plank:    plank,
+			org:      "org",
+			repo:     "repo",
+			expected: "http://lighthouse-gitcache/org/repo.git",
+		},
+		{
+			name:     "org",
+			plank:    plank,
+			org:      "other",
+			repo:     "repo",
+			expected: "https://gitcache.example.com/other/repo.git",
+		},
+		{
+			name:  "repo cloned from the git server",
+			plank: plank,
+			org:   "other",
+			repo:  "direct",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.plank.GuessDefaultDecorationConfig(tc.org, tc.repo).GitCacheCloneURI(tc.org, tc.repo))
+		})
+	}
+}
+
+func TestPlankParseDecorationConfigs(t *testing.T) {
+	testCases := []struct {
+		name    string
+		configs map[string]*DecorationConfig
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			configs: map[string]*DecorationConfig{
+				"*":        {GitCacheURL: "http://lighthouse-gitcache"},
+				"org/repo": {},
+			},
+		},
+		{
+			name:    "invalid key",
+			configs: map[string]*DecorationConfig{"org/repo/sub": {}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid git cache URL",
+			configs: map[string]*DecorationConfig{"*": {GitCacheURL: "lighthouse-gitcache"}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plank := Plank{DefaultDecorationConfigs: tc.configs}
+			err := plank.Parse()
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
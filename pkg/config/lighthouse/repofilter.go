@@ -0,0 +1,71 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lighthouse
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// RepoFilter configures which repositories Lighthouse acts on. This allows a single organisation webhook to be
+// installed while only the opted-in repositories get pipelines, labels and merges.
+type RepoFilter struct {
+	// Allow is a list of glob patterns matched against 'org/repo'. A pattern without a '/' matches the whole
+	// org. If empty, all repositories are allowed.
+	Allow []string `json:"allow,omitempty"`
+	// Deny is a list of glob patterns matched against 'org/repo'. A pattern without a '/' matches the whole
+	// org. A repository matching any deny pattern is ignored even if it is also allowed.
+	Deny []string `json:"deny,omitempty"`
+}
+
+// Parse validates the glob patterns
+func (f *RepoFilter) Parse() error {
+	for _, pattern := range append(append([]string{}, f.Allow...), f.Deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid repo_filter pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Matches returns true if the filter allows the given repository
+func (f *RepoFilter) Matches(org, repo string) bool {
+	if matchesAny(f.Deny, org, repo) {
+		return false
+	}
+	return len(f.Allow) == 0 || matchesAny(f.Allow, org, repo)
+}
+
+func matchesAny(patterns []string, org, repo string) bool {
+	fullName := org + "/" + repo
+	for _, pattern := range patterns {
+		name := fullName
+		if !strings.Contains(pattern, "/") {
+			name = org
+		}
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// RepoEnabled returns whether Lighthouse should act on events for the given repository
+func (c *Config) RepoEnabled(org, repo string) bool {
+	return c.RepoFilter.Matches(org, repo)
+}
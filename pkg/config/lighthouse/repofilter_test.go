@@ -0,0 +1,73 @@
+package lighthouse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoFilter(t *testing.T) {
+	testCases := []struct {
+		name     string
+		filter   RepoFilter
+		org      string
+		repo     string
+		expected bool
+	}{
+		{
+			name:     "empty filter allows everything",
+			org:      "org",
+			repo:     "repo",
+			expected: true,
+		},
+		{
+			name:     "allowed by org pattern",
+			filter:   RepoFilter{Allow: []string{"org"}},
+			org:      "org",
+			repo:     "repo",
+			expected: true,
+		},
+		{
+			name:     "not in allow list",
+			filter:   RepoFilter{Allow: []string{"other-org", "org/lighthouse-*"}},
+			org:      "org",
+			repo:     "repo",
+			expected: false,
+		},
+		{
+			name:     "allowed by repo glob",
+			filter:   RepoFilter{Allow: []string{"org/lighthouse-*"}},
+			org:      "org",
+			repo:     "lighthouse-config",
+			expected: true,
+		},
+		{
+			name:     "deny wins over allow",
+			filter:   RepoFilter{Allow: []string{"org/*"}, Deny: []string{"org/*-archived"}},
+			org:      "org",
+			repo:     "old-archived",
+			expected: false,
+		},
+		{
+			name:     "denied org",
+			filter:   RepoFilter{Deny: []string{"forks-*"}},
+			org:      "forks-org",
+			repo:     "repo",
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.filter.Matches(tc.org, tc.repo))
+		})
+	}
+}
+
+func TestRepoFilterParse(t *testing.T) {
+	f := RepoFilter{Allow: []string{"org/[repo"}}
+	assert.Error(t, f.Parse())
+
+	f = RepoFilter{Allow: []string{"org/*"}, Deny: []string{"org/repo"}}
+	assert.NoError(t, f.Parse())
+}
@@ -0,0 +1,38 @@
+package lighthouse
+
+import (
+	"fmt"
+)
+
+const (
+	// defaultMaxReviewComments is the number of inline comments created for the failures of a pipeline by default
+	defaultMaxReviewComments = 10
+)
+
+// ReviewComments configures the inline review comments created on pull requests for the failures found in the JUnit
+// and SARIF reports of their presubmits. Only the failures at lines changed by the pull request are commented inline,
+// the others being listed in the body of the review.
+type ReviewComments struct {
+	// Enabled describes whether failures are commented inline for a given repository. This can be set globally, per
+	// org or per repo using '*', 'org' or 'org/repo' as key. The narrowest match always takes precedence.
+	Enabled map[string]*bool `json:"enabled,omitempty"`
+	// MaxComments is the maximum number of inline comments created for the failures of a pipeline. Defaults to 10.
+	MaxComments int `json:"max_comments,omitempty"`
+}
+
+// Parse initializes and validates the Config
+func (r *ReviewComments) Parse() error {
+	if r.MaxComments < 0 {
+		return fmt.Errorf("review_comments.max_comments cannot be negative: %d", r.MaxComments)
+	}
+	if r.MaxComments == 0 {
+		r.MaxComments = defaultMaxReviewComments
+	}
+	return nil
+}
+
+// ReviewCommentsEnabled returns whether the failures of the presubmits of a repository are commented inline on its
+// pull requests
+func (c *Config) ReviewCommentsEnabled(org, repo string) bool {
+	return narrowestMatch(c.ReviewComments.Enabled, fmt.Sprintf("%s/%s", org, repo))
+}
@@ -0,0 +1,25 @@
+package lighthouse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewComments(t *testing.T) {
+	enabled, disabled := true, false
+	c := Config{ReviewComments: ReviewComments{
+		Enabled: map[string]*bool{
+			"org":        &enabled,
+			"org/legacy": &disabled,
+		},
+	}}
+	assert.NoError(t, c.ReviewComments.Parse())
+	assert.Equal(t, 10, c.ReviewComments.MaxComments)
+	assert.True(t, c.ReviewCommentsEnabled("org", "repo"))
+	assert.False(t, c.ReviewCommentsEnabled("org", "legacy"))
+	assert.False(t, c.ReviewCommentsEnabled("other", "repo"))
+
+	invalid := ReviewComments{MaxComments: -1}
+	assert.Error(t, invalid.Parse())
+}
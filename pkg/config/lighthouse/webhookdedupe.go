@@ -0,0 +1,52 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lighthouse
+
+import (
+	"fmt"
+	"time"
+)
+
+// WebhookDedupe configures how redelivered webhooks are detected so they are not processed twice.
+type WebhookDedupe struct {
+	// TTLString compiles into TTL at load time.
+	TTLString string `json:"ttl,omitempty"`
+	// TTL is how long processed webhook deliveries are remembered for. Defaults to 1h.
+	TTL time.Duration `json:"-"`
+	// MaxEntries is the maximum number of deliveries remembered in memory and in the shared store. Defaults to 10000.
+	MaxEntries int `json:"max_entries,omitempty"`
+	// SharedStore records processed deliveries as Leases in the cluster so that redeliveries are
+	// also detected when they are handled by a different webhook replica.
+	SharedStore bool `json:"shared_store,omitempty"`
+}
+
+// Parse initializes and validates the Config
+func (c *WebhookDedupe) Parse() error {
+	if c.TTLString == "" {
+		c.TTL = time.Hour
+	} else {
+		ttl, err := time.ParseDuration(c.TTLString)
+		if err != nil {
+			return fmt.Errorf("cannot parse duration for webhook_dedupe.ttl: %v", err)
+		}
+		c.TTL = ttl
+	}
+	if c.MaxEntries <= 0 {
+		c.MaxEntries = 10000
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package util
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
@@ -37,10 +38,23 @@ func PathOrDefault(value string) string {
 	return DefaultConfigPath
 }
 
+// commandArgRegex matches the names which can be passed to the /test command
+var commandArgRegex = regexp.MustCompile(`^[-\w]+$`)
+
 // DefaultTriggerFor returns the default regexp string used to match comments
-// that should trigger the job with this name.
-func DefaultTriggerFor(name string) string {
-	return fmt.Sprintf(`(?m)^/test( | .* )%s,?($|\s.*)`, name)
+// that should trigger the job with this name. The aliases, e.g. the context of
+// the job, trigger the job too unless they cannot be passed to /test.
+func DefaultTriggerFor(name string, aliases ...string) string {
+	names := []string{name}
+	for _, alias := range aliases {
+		if alias != name && commandArgRegex.MatchString(alias) {
+			names = append(names, regexp.QuoteMeta(alias))
+		}
+	}
+	if len(names) == 1 {
+		return fmt.Sprintf(`(?m)^/test( | .* )%s,?($|\s.*)`, name)
+	}
+	return fmt.Sprintf(`(?m)^/test( | .* )(?:%s),?($|\s.*)`, strings.Join(names, "|"))
 }
 
 // DefaultRerunCommandFor returns the default rerun command for the job with
@@ -48,3 +62,25 @@ func DefaultTriggerFor(name string) string {
 func DefaultRerunCommandFor(name string) string {
 	return fmt.Sprintf("/test %s", name)
 }
+
+// DeriveRerunCommand returns the first default rerun command of the names,
+// e.g. the name and the context of a job, which matches the trigger, or an
+// empty string if none does.
+func DeriveRerunCommand(trigger string, names ...string) string {
+	re, err := regexp.Compile(trigger)
+	if err != nil {
+		return ""
+	}
+	for _, name := range names {
+		if command := DefaultRerunCommandFor(name); name != "" && re.MatchString(command) {
+			return command
+		}
+	}
+	return ""
+}
+
+// TriggerForRerunCommand returns the regexp string used to match comments
+// which are the rerun command, optionally followed by other text.
+func TriggerForRerunCommand(command string) string {
+	return fmt.Sprintf(`(?m)^%s,?($|\s.*)`, regexp.QuoteMeta(command))
+}
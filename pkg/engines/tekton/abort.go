@@ -0,0 +1,46 @@
+package tekton
+
+import (
+	"context"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
+	"github.com/pkg/errors"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// AbortedAnnotation is added to the pipeline runs cancelled by the controller because their job was aborted
+	AbortedAnnotation = "lighthouse.jenkins-x.io/aborted"
+	// AbortedDescription is the description of the pipelines cancelled because their job was aborted
+	AbortedDescription = "Aborted"
+)
+
+// abortJob stops the pipeline of a job aborted before it completed, e.g. because a new job superseded it: its pipeline
+// run is cancelled so that Tekton stops its pods, the job completing once the pipeline run reports it stopped. A job
+// aborted before its pipeline run was created completes right away.
+func (r *LighthouseJobReconciler) abortJob(ctx context.Context, job *lighthousev1alpha1.LighthouseJob, pipelineRun *pipelinev1beta1.PipelineRun, runClient client.Client) error {
+	logger := logrusutil.FromContext(ctx)
+	if pipelineRun == nil {
+		logger.Infof("Completing LighthouseJob %s aborted before it started", job.Name)
+		job.SetComplete()
+		if err := r.client.Status().Update(ctx, job); err != nil {
+			return errors.Wrapf(err, "failed to complete LighthouseJob %s", job.Name)
+		}
+		return nil
+	}
+	if pipelineRun.IsDone() || pipelineRun.Annotations[AbortedAnnotation] != "" {
+		return nil
+	}
+	logger.Infof("Cancelling PipelineRun %s as LighthouseJob %s was aborted", pipelineRun.Name, job.Name)
+	if pipelineRun.Annotations == nil {
+		pipelineRun.Annotations = map[string]string{}
+	}
+	pipelineRun.Annotations[AbortedAnnotation] = "true"
+	pipelineRun.Spec.Status = pipelinev1beta1.PipelineRunSpecStatusCancelled
+	if err := runClient.Update(ctx, pipelineRun); err != nil {
+		return errors.Wrapf(err, "failed to cancel PipelineRun %s", pipelineRun.Name)
+	}
+	return nil
+}
@@ -13,6 +13,12 @@ import (
 	"knative.dev/pkg/apis"
 )
 
+// JUnitResultName is the name of the pipeline result which pipelines can use to expose their JUnit XML test report
+const JUnitResultName = "junit"
+
+// SARIFResultName is the name of the pipeline result which pipelines can use to expose their SARIF analysis report
+const SARIFResultName = "sarif"
+
 // ConvertPipelineRun translates a PipelineRun into an ActivityRecord
 func ConvertPipelineRun(pr *v1beta1.PipelineRun) *v1alpha1.ActivityRecord {
 	if pr == nil {
@@ -39,6 +45,31 @@ func ConvertPipelineRun(pr *v1beta1.PipelineRun) *v1alpha1.ActivityRecord {
 	cond := pr.Status.GetCondition(apis.ConditionSucceeded)
 
 	record.Status = convertTektonStatus(cond, record.StartTime, record.CompletionTime)
+	// report pipelines stopped because of their timeout, by Tekton or by the controller, as aborted
+	if record.Status == v1alpha1.FailureState && cond != nil && (cond.Reason == string(v1beta1.PipelineRunReasonTimedOut) || pr.Annotations[TimedOutAnnotation] != "") {
+		record.Status = v1alpha1.AbortedState
+		record.Description = TimedOutDescription
+	}
+	// report pipelines cancelled because their job was aborted as aborted
+	if record.Status == v1alpha1.FailureState && pr.Annotations[AbortedAnnotation] != "" {
+		record.Status = v1alpha1.AbortedState
+		record.Description = AbortedDescription
+	}
+	switch record.Status {
+	case v1alpha1.FailureState:
+		// report pipelines which failed because of the cluster or of their definition as errors, the former being
+		// retried
+		if class, cause := classifyFailure(pr, cond); class != "" {
+			record.Status = v1alpha1.ErrorState
+			record.Description = cause
+			record.FailureClass = class
+		}
+	case v1alpha1.AbortedState:
+		// pipelines may time out waiting for the cluster, e.g. for their images
+		if class, _ := classifyFailure(pr, cond); class == v1alpha1.InfraFailure && record.Description == TimedOutDescription {
+			record.FailureClass = class
+		}
+	}
 
 	for _, taskName := range sets.StringKeySet(pr.Status.TaskRuns).List() {
 		task := pr.Status.TaskRuns[taskName]
@@ -77,6 +108,14 @@ func ConvertPipelineRun(pr *v1beta1.PipelineRun) *v1alpha1.ActivityRecord {
 
 		record.Stages = append(record.Stages, t)
 	}
+	for _, result := range pr.Status.PipelineResults {
+		switch result.Name {
+		case JUnitResultName:
+			record.TestResults = result.Value
+		case SARIFResultName:
+			record.SARIFResults = result.Value
+		}
+	}
 	// log URL is definitely gonna wait
 
 	return record
@@ -24,6 +24,27 @@ func TestConvertPipelineRun(t *testing.T) {
 		{
 			name: "failed_single_task",
 		},
+		{
+			name: "infra_failed_single_task",
+		},
+		{
+			name: "evicted_single_task",
+		},
+		{
+			name: "image_pull_failed_single_task",
+		},
+		{
+			name: "clone_failed_single_task",
+		},
+		{
+			name: "merge_conflict_single_task",
+		},
+		{
+			name: "invalid_single_task",
+		},
+		{
+			name: "timed_out_single_task",
+		},
 		{
 			name: "running_single_task",
 		},
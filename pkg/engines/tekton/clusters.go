@@ -0,0 +1,90 @@
+package tekton
+
+import (
+	configjob "github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/pkg/errors"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// buildCluster is a remote cluster pipeline runs can be created in. As owner references cannot span clusters
+// the pipeline runs are matched to their LighthouseJob using the job label.
+type buildCluster struct {
+	client client.Client
+	config *rest.Config
+}
+
+// AddBuildCluster registers a remote cluster jobs are dispatched to when their `cluster` field matches the alias
+func (r *LighthouseJobReconciler) AddBuildCluster(alias string, config *rest.Config) error {
+	if isLocalCluster(alias) {
+		return errors.Errorf("build cluster alias %q is reserved for the cluster lighthouse runs in", alias)
+	}
+	c, err := client.New(config, client.Options{Scheme: r.scheme})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create client for build cluster %s", alias)
+	}
+	if r.buildClusters == nil {
+		r.buildClusters = map[string]*buildCluster{}
+	}
+	r.buildClusters[alias] = &buildCluster{client: c, config: config}
+	return nil
+}
+
+// isLocalCluster returns true if the alias refers to the cluster lighthouse runs in
+func isLocalCluster(alias string) bool {
+	return alias == "" || alias == configjob.DefaultClusterAlias
+}
+
+// clientsForCluster returns the client and reader used to manage the pipeline runs of the given cluster
+func (r *LighthouseJobReconciler) clientsForCluster(alias string) (client.Client, client.Reader, bool) {
+	if isLocalCluster(alias) {
+		return r.client, r.apiReader, true
+	}
+	cluster, ok := r.buildClusters[alias]
+	if !ok {
+		return nil, nil, false
+	}
+	return cluster.client, cluster.client, true
+}
+
+// watchBuildClusters watches the pipeline runs of the build clusters so that the status of their jobs is updated
+func (r *LighthouseJobReconciler) watchBuildClusters(mgr ctrl.Manager, blder *builder.Builder) (*builder.Builder, error) {
+	for alias, cluster := range r.buildClusters {
+		if cluster.config == nil {
+			continue
+		}
+		c, err := cache.New(cluster.config, cache.Options{Scheme: r.scheme, Namespace: r.namespace})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create cache for build cluster %s", alias)
+		}
+		if err := mgr.Add(c); err != nil {
+			return nil, errors.Wrapf(err, "failed to add cache for build cluster %s", alias)
+		}
+		// the cache is injected before the manager would inject its own so that the build cluster is watched
+		src := &source.Kind{Type: &pipelinev1beta1.PipelineRun{}}
+		if err := src.InjectCache(c); err != nil {
+			return nil, errors.Wrapf(err, "failed to inject cache for build cluster %s", alias)
+		}
+		blder = blder.Watches(src, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.jobForPipelineRun),
+		})
+	}
+	return blder, nil
+}
+
+// jobForPipelineRun maps a pipeline run of a build cluster to its LighthouseJob
+func (r *LighthouseJobReconciler) jobForPipelineRun(o handler.MapObject) []reconcile.Request {
+	name := o.Meta.GetLabels()[configjob.LighthouseJobIDLabel]
+	if name == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: r.namespace, Name: name}}}
+}
@@ -5,10 +5,17 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"text/template"
+	"time"
 
 	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
 	configjob "github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/jobqueue"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
 	"github.com/jenkins-x/lighthouse/pkg/util"
 	"github.com/sirupsen/logrus"
 	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
@@ -23,8 +30,15 @@ const jobOwnerKey = ".metadata.controller"
 
 var apiGVStr = lighthousev1alpha1.SchemeGroupVersion.String()
 
+// queuedRequeueInterval is how often jobs waiting for capacity check whether they can start
+const queuedRequeueInterval = 10 * time.Second
+
 // LighthouseJobReconciler reconciles a LighthouseJob object
 type LighthouseJobReconciler struct {
+	// Config returns the Lighthouse config whose concurrency limits are enforced. Only the max_concurrency of the
+	// jobs is enforced if nil.
+	Config config.Getter
+
 	client            client.Client
 	apiReader         client.Reader
 	logger            *logrus.Entry
@@ -33,10 +47,13 @@ type LighthouseJobReconciler struct {
 	dashboardURL      string
 	dashboardTemplate string
 	namespace         string
+	strictSecrets     bool
+	buildClusters     map[string]*buildCluster
 }
 
-// NewLighthouseJobReconciler creates a LighthouseJob reconciler
-func NewLighthouseJobReconciler(client client.Client, apiReader client.Reader, scheme *runtime.Scheme, dashboardURL string, dashboardTemplate string, namespace string) *LighthouseJobReconciler {
+// NewLighthouseJobReconciler creates a LighthouseJob reconciler. When strictSecrets is true jobs referencing
+// secrets which do not exist are failed rather than started.
+func NewLighthouseJobReconciler(client client.Client, apiReader client.Reader, scheme *runtime.Scheme, dashboardURL string, dashboardTemplate string, namespace string, strictSecrets bool) *LighthouseJobReconciler {
 	if dashboardTemplate == "" {
 		dashboardTemplate = os.Getenv("LIGHTHOUSE_DASHBOARD_TEMPLATE")
 	}
@@ -48,6 +65,7 @@ func NewLighthouseJobReconciler(client client.Client, apiReader client.Reader, s
 		dashboardURL:      dashboardURL,
 		dashboardTemplate: dashboardTemplate,
 		namespace:         namespace,
+		strictSecrets:     strictSecrets,
 		idGenerator:       &epochBuildIDGenerator{},
 	}
 }
@@ -66,11 +84,15 @@ func (r *LighthouseJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	blder := ctrl.NewControllerManagedBy(mgr).
 		For(&lighthousev1alpha1.LighthouseJob{}).
 		WithEventFilter(predicate.ResourceVersionChangedPredicate{}).
-		Owns(&pipelinev1beta1.PipelineRun{}).
-		Complete(r)
+		Owns(&pipelinev1beta1.PipelineRun{})
+	blder, err := r.watchBuildClusters(mgr, blder)
+	if err != nil {
+		return err
+	}
+	return blder.Complete(r)
 }
 
 // Reconcile represents an iteration of the reconciliation loop
@@ -89,32 +111,92 @@ func (r *LighthouseJobReconciler) Reconcile(req ctrl.Request) (ctrl.Result, erro
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	// log with the fields of the job from now on
+	logger := r.logger.WithFields(jobutil.LighthouseJobFields(&job))
+	ctx = logrusutil.NewContext(ctx, logger)
+
 	// filter on job agent
 	if job.Spec.Agent != configjob.TektonPipelineAgent {
 		return ctrl.Result{}, nil
 	}
 
+	// get the cluster the job runs in
+	runClient, runReader, ok := r.clientsForCluster(job.Spec.Cluster)
+	if !ok {
+		if job.Status.State != lighthousev1alpha1.TriggeredState {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, r.failJob(ctx, &job, fmt.Sprintf("Unknown cluster: %s", job.Spec.Cluster))
+	}
+	local := isLocalCluster(job.Spec.Cluster)
+
 	// get job's pipeline runs
 	var pipelineRunList pipelinev1beta1.PipelineRunList
-	if err := r.client.List(ctx, &pipelineRunList, client.InNamespace(req.Namespace), client.MatchingFields{jobOwnerKey: req.Name}); err != nil {
-		r.logger.Errorf("Failed list pipeline runs: %s", err)
+	listOptions := []client.ListOption{client.InNamespace(r.namespace), client.MatchingLabels{configjob.LighthouseJobIDLabel: req.Name}}
+	if local {
+		listOptions = []client.ListOption{client.InNamespace(req.Namespace), client.MatchingFields{jobOwnerKey: req.Name}}
+	}
+	if err := runClient.List(ctx, &pipelineRunList, listOptions...); err != nil {
+		logger.Errorf("Failed list pipeline runs: %s", err)
 		return ctrl.Result{}, err
 	}
 
+	// stop the pipelines of the jobs aborted before they completed
+	if job.Status.State == lighthousev1alpha1.AbortedState && !job.Complete() {
+		var pipelineRun *pipelinev1beta1.PipelineRun
+		if len(pipelineRunList.Items) > 0 {
+			pipelineRun = &pipelineRunList.Items[0]
+		}
+		if err := r.abortJob(ctx, &job, pipelineRun, runClient); err != nil {
+			logger.Errorf("Failed to abort LighthouseJob: %s", err)
+			return ctrl.Result{}, err
+		}
+		if pipelineRun == nil {
+			return ctrl.Result{}, nil
+		}
+	}
+
 	// if pipeline run does not exist, create it
 	if len(pipelineRunList.Items) == 0 {
 		if job.Status.State == lighthousev1alpha1.TriggeredState {
-			// construct a pipeline run
-			pipelineRun, err := makePipelineRun(ctx, job, r.namespace, r.logger, r.idGenerator, r.apiReader)
+			// skip the periodics during the maintenance windows
+			if skipped, err := r.skipPeriodic(ctx, &job); skipped || err != nil {
+				return ctrl.Result{}, err
+			}
+			// wait for capacity within the concurrency limits
+			start, reason, err := r.canStart(ctx, &job)
 			if err != nil {
-				r.logger.Errorf("Failed to make pipeline run: %s", err)
+				logger.Errorf("Failed to check the concurrency limits: %s", err)
 				return ctrl.Result{}, err
 			}
-			// link it to the current lighthouse job
-			if err := ctrl.SetControllerReference(&job, pipelineRun, r.scheme); err != nil {
-				r.logger.Errorf("Failed to set owner reference: %s", err)
+			if !start {
+				return ctrl.Result{RequeueAfter: queuedRequeueInterval}, r.queueJob(ctx, &job, reason)
+			}
+			// check the secrets exposed to the pipeline exist
+			missing, err := missingSecrets(ctx, job, r.namespace, runReader)
+			if err != nil {
+				logger.Errorf("Failed to check secrets: %s", err)
+				return ctrl.Result{}, err
+			}
+			if len(missing) > 0 && r.strictSecrets {
+				return ctrl.Result{}, r.failJob(ctx, &job, fmt.Sprintf("Missing secrets: %s", strings.Join(missing, ", ")))
+			}
+			if len(missing) > 0 {
+				logger.Warnf("LighthouseJob %s references secrets which do not exist: %s", job.Name, strings.Join(missing, ", "))
+			}
+			// construct a pipeline run
+			pipelineRun, err := makePipelineRun(ctx, job, r.namespace, logger, r.idGenerator, runReader, r.gitCacheCloneURI(&job))
+			if err != nil {
+				logger.Errorf("Failed to make pipeline run: %s", err)
 				return ctrl.Result{}, err
 			}
+			// link it to the current lighthouse job, pipeline runs of build clusters are linked by their labels
+			if local {
+				if err := ctrl.SetControllerReference(&job, pipelineRun, r.scheme); err != nil {
+					logger.Errorf("Failed to set owner reference: %s", err)
+					return ctrl.Result{}, err
+				}
+			}
 			// TODO: changing the status should be a consequence of a pipeline run being created
 			// update status
 			job.Status = lighthousev1alpha1.LighthouseJobStatus{
@@ -122,40 +204,123 @@ func (r *LighthouseJobReconciler) Reconcile(req ctrl.Request) (ctrl.Result, erro
 				StartTime: metav1.Now(),
 			}
 			if err := r.client.Status().Update(ctx, &job); err != nil {
-				r.logger.Errorf("Failed to update LighthouseJob status: %s", err)
+				logger.Errorf("Failed to update LighthouseJob status: %s", err)
 				return ctrl.Result{}, err
 			}
 			// create pipeline run
-			if err := r.client.Create(ctx, pipelineRun); err != nil {
-				r.logger.Errorf("Failed to create pipeline run: %s", err)
+			if err := runClient.Create(ctx, pipelineRun); err != nil {
+				logger.Errorf("Failed to create pipeline run: %s", err)
 				return ctrl.Result{}, err
 			}
 		}
 	} else if len(pipelineRunList.Items) == 1 {
 		// if pipeline run exists, create it and update status
 		pipelineRun := pipelineRunList.Items[0]
-		r.logger.Infof("Reconcile PipelineRun %+v", pipelineRun)
+		logger.Infof("Reconcile PipelineRun %+v", pipelineRun)
 		// update build id
 		job.Labels[util.BuildNumLabel] = pipelineRun.Labels[util.BuildNumLabel]
 		if err := r.client.Update(ctx, &job); err != nil {
-			r.logger.Errorf("failed to update Project status: %s", err)
+			logger.Errorf("failed to update Project status: %s", err)
 			return ctrl.Result{}, err
 		}
 		if r.dashboardURL != "" {
 			job.Status.ReportURL = r.getPipelingetPipelineTargetURLeTargetURL(pipelineRun)
 		}
-		job.Status.Activity = ConvertPipelineRun(&pipelineRun)
+		activity := ConvertPipelineRun(&pipelineRun)
+		observeActivity(&job, activity)
+		job.Status.Activity = activity
 		if err := r.client.Status().Update(ctx, &job); err != nil {
-			r.logger.Errorf("Failed to update LighthouseJob status: %s", err)
+			logger.Errorf("Failed to update LighthouseJob status: %s", err)
+			return ctrl.Result{}, err
+		}
+		// check the job again when it exceeds its timeout as its pipeline run may not change by then
+		requeueAfter, err := r.enforceTimeout(ctx, &job, &pipelineRun, runClient)
+		if err != nil {
+			logger.Errorf("Failed to enforce the timeout: %s", err)
 			return ctrl.Result{}, err
 		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	} else {
-		r.logger.Errorf("A lighthouse job should never have more than 1 pipeline run")
+		logger.Errorf("A lighthouse job should never have more than 1 pipeline run")
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// gitCacheCloneURI returns the URI the repository of the job is cloned from through the git cache configured in the
+// default decoration configs, or an empty string if the repository is cloned from the git server
+func (r *LighthouseJobReconciler) gitCacheCloneURI(job *lighthousev1alpha1.LighthouseJob) string {
+	if r.Config == nil || job.Spec.Refs == nil {
+		return ""
+	}
+	cfg := r.Config()
+	if cfg == nil {
+		return ""
+	}
+	org, repo := job.Spec.Refs.Org, job.Spec.Refs.Repo
+	return cfg.Plank.GuessDefaultDecorationConfig(org, repo).GitCacheCloneURI(org, repo)
+}
+
+// canStart returns whether the triggered job fits within the concurrency limits, or why it has to wait
+func (r *LighthouseJobReconciler) canStart(ctx context.Context, job *lighthousev1alpha1.LighthouseJob) (bool, string, error) {
+	var limits lighthouse.Concurrency
+	if r.Config != nil {
+		if cfg := r.Config(); cfg != nil {
+			limits = cfg.Concurrency
+		}
+	}
+	if job.Spec.MaxConcurrency == 0 && limits.MaxPipelines == 0 && limits.MaxPerOrg == 0 && limits.MaxPerRepo == 0 && len(limits.Limits) == 0 {
+		return true, "", nil
+	}
+	var jobList lighthousev1alpha1.LighthouseJobList
+	if err := r.client.List(ctx, &jobList, client.InNamespace(job.Namespace)); err != nil {
+		return false, "", err
+	}
+	var jobs []lighthousev1alpha1.LighthouseJob
+	for _, j := range jobList.Items {
+		if j.Spec.Agent == configjob.TektonPipelineAgent {
+			jobs = append(jobs, j)
+		}
+	}
+	start, reason := jobqueue.CanStart(limits, job, jobs)
+	return start, reason, nil
+}
+
+// queueJob records why a triggered job is waiting to start
+func (r *LighthouseJobReconciler) queueJob(ctx context.Context, job *lighthousev1alpha1.LighthouseJob, reason string) error {
+	description := "Waiting: " + reason
+	if job.Status.Description == description {
+		return nil
+	}
+	logger := logrusutil.FromContext(ctx)
+	logger.Infof("LighthouseJob %s is waiting to start as %s", job.Name, reason)
+	job.Status.Description = description
+	if err := r.client.Status().Update(ctx, job); err != nil {
+		logger.Errorf("Failed to update LighthouseJob status: %s", err)
+		return err
+	}
+	return nil
+}
+
+// failJob marks a job which cannot be started because of its configuration as errored
+func (r *LighthouseJobReconciler) failJob(ctx context.Context, job *lighthousev1alpha1.LighthouseJob, description string) error {
+	logger := logrusutil.FromContext(ctx)
+	logger.Errorf("Failing LighthouseJob %s: %s", job.Name, description)
+	now := metav1.Now()
+	job.Status = lighthousev1alpha1.LighthouseJobStatus{
+		State:          lighthousev1alpha1.ErrorState,
+		Description:    description,
+		FailureClass:   lighthousev1alpha1.ConfigFailure,
+		StartTime:      now,
+		CompletionTime: &now,
+	}
+	if err := r.client.Status().Update(ctx, job); err != nil {
+		logger.Errorf("Failed to update LighthouseJob status: %s", err)
+		return err
+	}
+	return nil
+}
+
 func (r *LighthouseJobReconciler) getPipelingetPipelineTargetURLeTargetURL(pipelineRun pipelinev1beta1.PipelineRun) string {
 	if r.dashboardTemplate == "" {
 		return fmt.Sprintf("%s/#/namespaces/%s/pipelineruns/%s", trimDashboardURL(r.dashboardURL), r.namespace, pipelineRun.Name)
@@ -7,12 +7,18 @@ import (
 	"path/filepath"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
 	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	configjob "github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
 	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -22,6 +28,8 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/yaml"
 )
 
@@ -83,7 +91,7 @@ func TestReconcile(t *testing.T) {
 			err = pipelinev1beta1.AddToScheme(scheme)
 			assert.NoError(t, err)
 			c := fake.NewFakeClientWithScheme(scheme, state...)
-			reconciler := NewLighthouseJobReconciler(c, c, scheme, dashboardBaseURL, dashboardTemplate, ns)
+			reconciler := NewLighthouseJobReconciler(c, c, scheme, dashboardBaseURL, dashboardTemplate, ns, false)
 			reconciler.idGenerator = &seededRandIDGenerator{}
 
 			// invoke reconcile
@@ -198,3 +206,274 @@ func loadObservedPipeline(dir string) (*tektonv1beta1.Pipeline, error) {
 	}
 	return nil, nil
 }
+
+func TestReconcileBuildCluster(t *testing.T) {
+	utilrand.Seed(12345)
+	ns := "jx"
+	testData := path.Join("test_data", "controller", "start-pullrequest")
+	observedJob, err := loadLighthouseJob(true, testData)
+	require.NoError(t, err)
+	observedPipeline, err := loadObservedPipeline(testData)
+	require.NoError(t, err)
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+	require.NoError(t, pipelinev1beta1.AddToScheme(scheme))
+
+	remoteJob := observedJob.DeepCopy()
+	remoteJob.Spec.Cluster = "remote"
+	unknownJob := observedJob.DeepCopy()
+	unknownJob.Name = "unknown-cluster"
+	unknownJob.Spec.Cluster = "unknown"
+	c := fake.NewFakeClientWithScheme(scheme, remoteJob, unknownJob)
+	remote := fake.NewFakeClientWithScheme(scheme, observedPipeline)
+
+	reconciler := NewLighthouseJobReconciler(c, c, scheme, dashboardBaseURL, dashboardTemplate, ns, false)
+	reconciler.idGenerator = &seededRandIDGenerator{}
+	reconciler.buildClusters = map[string]*buildCluster{"remote": {client: remote}}
+
+	// the pipeline run is created in the build cluster
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: remoteJob.Name}}
+	_, err = reconciler.Reconcile(request)
+	require.NoError(t, err)
+
+	var localRuns, remoteRuns tektonv1beta1.PipelineRunList
+	require.NoError(t, c.List(nil, &localRuns, client.InNamespace(ns)))
+	require.NoError(t, remote.List(nil, &remoteRuns, client.InNamespace(ns)))
+	assert.Empty(t, localRuns.Items)
+	require.Len(t, remoteRuns.Items, 1)
+	assert.Empty(t, remoteRuns.Items[0].OwnerReferences)
+	assert.Equal(t, remoteJob.Name, remoteRuns.Items[0].Labels[configjob.LighthouseJobIDLabel])
+	assert.Equal(t, []reconcile.Request{request}, reconciler.jobForPipelineRun(handler.MapObject{Meta: &remoteRuns.Items[0]}))
+
+	// the status of the job is updated from the pipeline run of the build cluster
+	_, err = reconciler.Reconcile(request)
+	require.NoError(t, err)
+	var job lighthousev1alpha1.LighthouseJob
+	require.NoError(t, c.Get(nil, request.NamespacedName, &job))
+	assert.Equal(t, lighthousev1alpha1.PendingState, job.Status.State)
+	assert.NotNil(t, job.Status.Activity)
+	assert.Equal(t, remoteRuns.Items[0].Labels[util.BuildNumLabel], job.Labels[util.BuildNumLabel])
+
+	// jobs for unknown clusters are failed
+	request = ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: unknownJob.Name}}
+	_, err = reconciler.Reconcile(request)
+	require.NoError(t, err)
+	require.NoError(t, c.Get(nil, request.NamespacedName, &job))
+	assert.Equal(t, lighthousev1alpha1.ErrorState, job.Status.State)
+	assert.Equal(t, "Unknown cluster: unknown", job.Status.Description)
+	assert.Equal(t, lighthousev1alpha1.ConfigFailure, job.Status.FailureClass)
+}
+
+func TestReconcileQueuesJobs(t *testing.T) {
+	ns := "jx"
+	testData := path.Join("test_data", "controller", "start-pullrequest")
+	observedJob, err := loadLighthouseJob(true, testData)
+	require.NoError(t, err)
+	observedPipeline, err := loadObservedPipeline(testData)
+	require.NoError(t, err)
+	runningJob := observedJob.DeepCopy()
+	runningJob.Name = "running"
+	runningJob.Status.State = lighthousev1alpha1.PendingState
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+	require.NoError(t, pipelinev1beta1.AddToScheme(scheme))
+	var state []runtime.Object
+	state = append(state, observedJob, runningJob)
+	if observedPipeline != nil {
+		state = append(state, observedPipeline)
+	}
+	c := fake.NewFakeClientWithScheme(scheme, state...)
+	reconciler := NewLighthouseJobReconciler(c, c, scheme, dashboardBaseURL, dashboardTemplate, ns, false)
+	reconciler.idGenerator = &seededRandIDGenerator{}
+	cfg := &config.Config{}
+	cfg.Concurrency = lighthouse.Concurrency{MaxPerRepo: 1}
+	reconciler.Config = func() *config.Config {
+		return cfg
+	}
+
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: observedJob.GetName()}}
+	result, err := reconciler.Reconcile(request)
+	require.NoError(t, err)
+	assert.Equal(t, queuedRequeueInterval, result.RequeueAfter)
+
+	var pipelineRunList tektonv1beta1.PipelineRunList
+	require.NoError(t, c.List(nil, &pipelineRunList, client.InNamespace(ns)))
+	assert.Empty(t, pipelineRunList.Items, "the job waits for the running one")
+	var job lighthousev1alpha1.LighthouseJob
+	require.NoError(t, c.Get(nil, request.NamespacedName, &job))
+	assert.Equal(t, lighthousev1alpha1.TriggeredState, job.Status.State)
+	fullName := observedJob.Spec.Refs.Org + "/" + observedJob.Spec.Refs.Repo
+	assert.Equal(t, "Waiting: 1 pipelines of "+fullName+" running or queued ahead, the maximum is 1", job.Status.Description)
+
+	// the job starts once the running one completes
+	runningJob = runningJob.DeepCopy()
+	require.NoError(t, c.Get(nil, types.NamespacedName{Namespace: ns, Name: "running"}, runningJob))
+	runningJob.Status.State = lighthousev1alpha1.SuccessState
+	require.NoError(t, c.Status().Update(nil, runningJob))
+	result, err = reconciler.Reconcile(request)
+	require.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+	require.NoError(t, c.List(nil, &pipelineRunList, client.InNamespace(ns)))
+	assert.Len(t, pipelineRunList.Items, 1)
+}
+
+func TestReconcileTimesOutJobs(t *testing.T) {
+	ns := "jx"
+	testData := path.Join("test_data", "controller", "update-job")
+	observedJob, err := loadLighthouseJob(true, testData)
+	require.NoError(t, err)
+	observedPR, err := loadControllerPipelineRun(true, testData)
+	require.NoError(t, err)
+	observedJob.Spec.Timeout = &metav1.Duration{Duration: time.Minute}
+	observedJob.Spec.GracePeriod = &metav1.Duration{Duration: time.Hour}
+	observedJob.Status.StartTime = metav1.NewTime(time.Now().Add(-2 * time.Minute))
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+	require.NoError(t, pipelinev1beta1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme, observedJob, observedPR)
+	reconciler := NewLighthouseJobReconciler(c, c, scheme, dashboardBaseURL, dashboardTemplate, ns, false)
+	timeouts := testutil.ToFloat64(jobTimeouts.WithLabelValues(observedJob.Spec.Job, observedJob.Spec.Refs.Org, observedJob.Spec.Refs.Repo))
+
+	// the pipeline run is cancelled once the job exceeds its timeout
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: observedJob.GetName()}}
+	result, err := reconciler.Reconcile(request)
+	require.NoError(t, err)
+	assert.True(t, result.RequeueAfter > 58*time.Minute && result.RequeueAfter <= 59*time.Minute, "requeued at the end of the grace period, not after %s", result.RequeueAfter)
+	var pipelineRun tektonv1beta1.PipelineRun
+	require.NoError(t, c.Get(nil, types.NamespacedName{Namespace: ns, Name: observedPR.Name}, &pipelineRun))
+	assert.Equal(t, tektonv1beta1.PipelineRunSpecStatus(tektonv1beta1.PipelineRunSpecStatusCancelled), pipelineRun.Spec.Status)
+	assert.Equal(t, "true", pipelineRun.Annotations[TimedOutAnnotation])
+
+	// the pipeline run is deleted if it did not stop within the grace period
+	var job lighthousev1alpha1.LighthouseJob
+	require.NoError(t, c.Get(nil, request.NamespacedName, &job))
+	job.Spec.GracePeriod = &metav1.Duration{}
+	require.NoError(t, c.Update(nil, &job))
+	result, err = reconciler.Reconcile(request)
+	require.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+	var pipelineRunList tektonv1beta1.PipelineRunList
+	require.NoError(t, c.List(nil, &pipelineRunList, client.InNamespace(ns)))
+	assert.Empty(t, pipelineRunList.Items)
+	require.NoError(t, c.Get(nil, request.NamespacedName, &job))
+	require.NotNil(t, job.Status.Activity)
+	assert.Equal(t, lighthousev1alpha1.AbortedState, job.Status.Activity.Status)
+	assert.Equal(t, TimedOutDescription, job.Status.Activity.Description)
+	assert.NotNil(t, job.Status.Activity.CompletionTime)
+	assert.Equal(t, timeouts+1, testutil.ToFloat64(jobTimeouts.WithLabelValues(observedJob.Spec.Job, observedJob.Spec.Refs.Org, observedJob.Spec.Refs.Repo)))
+}
+
+func TestReconcileAbortsJobs(t *testing.T) {
+	ns := "jx"
+	testData := path.Join("test_data", "controller", "update-job")
+	observedJob, err := loadLighthouseJob(true, testData)
+	require.NoError(t, err)
+	observedPR, err := loadControllerPipelineRun(true, testData)
+	require.NoError(t, err)
+	observedJob.Status.State = lighthousev1alpha1.AbortedState
+	observedJob.Status.Description = "Superseded by LighthouseJob retest"
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+	require.NoError(t, pipelinev1beta1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme, observedJob, observedPR)
+	reconciler := NewLighthouseJobReconciler(c, c, scheme, dashboardBaseURL, dashboardTemplate, ns, false)
+
+	// the pipeline run of the aborted job is cancelled
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: observedJob.GetName()}}
+	_, err = reconciler.Reconcile(request)
+	require.NoError(t, err)
+	var pipelineRun tektonv1beta1.PipelineRun
+	require.NoError(t, c.Get(nil, types.NamespacedName{Namespace: ns, Name: observedPR.Name}, &pipelineRun))
+	assert.Equal(t, tektonv1beta1.PipelineRunSpecStatus(tektonv1beta1.PipelineRunSpecStatusCancelled), pipelineRun.Spec.Status)
+	assert.Equal(t, "true", pipelineRun.Annotations[AbortedAnnotation])
+	var job lighthousev1alpha1.LighthouseJob
+	require.NoError(t, c.Get(nil, request.NamespacedName, &job))
+	assert.Equal(t, lighthousev1alpha1.AbortedState, job.Status.State)
+
+	// a job aborted before its pipeline run was created completes right away
+	require.NoError(t, c.Delete(nil, &pipelineRun))
+	_, err = reconciler.Reconcile(request)
+	require.NoError(t, err)
+	var pipelineRunList tektonv1beta1.PipelineRunList
+	require.NoError(t, c.List(nil, &pipelineRunList, client.InNamespace(ns)))
+	assert.Empty(t, pipelineRunList.Items, "the aborted job is not started")
+	require.NoError(t, c.Get(nil, request.NamespacedName, &job))
+	assert.Equal(t, lighthousev1alpha1.AbortedState, job.Status.State)
+	assert.True(t, job.Complete())
+}
+
+func TestReconcileSkipsPeriodicsDuringMaintenance(t *testing.T) {
+	ns := "jx"
+	testData := path.Join("test_data", "controller", "start-pullrequest")
+	observedJob, err := loadLighthouseJob(true, testData)
+	require.NoError(t, err)
+	observedJob.Spec.Type = configjob.PeriodicJob
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+	require.NoError(t, pipelinev1beta1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme, observedJob)
+	reconciler := NewLighthouseJobReconciler(c, c, scheme, dashboardBaseURL, dashboardTemplate, ns, false)
+	reconciler.idGenerator = &seededRandIDGenerator{}
+	cfg := &config.Config{}
+	cfg.Maintenance.Windows = []lighthouse.MaintenanceWindow{{
+		Name:        "upgrade",
+		Reason:      "cluster upgrade",
+		Pause:       []string{lighthouse.PausePeriodics},
+		StartString: time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+		EndString:   time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+	}}
+	require.NoError(t, cfg.Maintenance.Parse())
+	reconciler.Config = func() *config.Config {
+		return cfg
+	}
+
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: observedJob.GetName()}}
+	_, err = reconciler.Reconcile(request)
+	require.NoError(t, err)
+
+	var pipelineRunList tektonv1beta1.PipelineRunList
+	require.NoError(t, c.List(nil, &pipelineRunList, client.InNamespace(ns)))
+	assert.Empty(t, pipelineRunList.Items, "the periodic is not started")
+	var skipped lighthousev1alpha1.LighthouseJob
+	require.NoError(t, c.Get(nil, request.NamespacedName, &skipped))
+	assert.Equal(t, lighthousev1alpha1.AbortedState, skipped.Status.State)
+	assert.Equal(t, "Skipped: "+cfg.Maintenance.Windows[0].Description(lighthouse.MaintenancePeriod{End: cfg.Maintenance.Windows[0].End}), skipped.Status.Description)
+}
+
+func TestReconcileSkipsPausedPeriodics(t *testing.T) {
+	ns := "jx"
+	testData := path.Join("test_data", "controller", "start-pullrequest")
+	observedJob, err := loadLighthouseJob(true, testData)
+	require.NoError(t, err)
+	observedJob.Spec.Type = configjob.PeriodicJob
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+	require.NoError(t, pipelinev1beta1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme, observedJob)
+	reconciler := NewLighthouseJobReconciler(c, c, scheme, dashboardBaseURL, dashboardTemplate, ns, false)
+	reconciler.idGenerator = &seededRandIDGenerator{}
+	cfg := &config.Config{}
+	cfg.Pauses = lighthouse.Pauses{}
+	cfg.Pauses.Set(lighthouse.PausePeriodics, observedJob.Spec.Refs.Org, lighthouse.Pause{Reason: "flaky cluster", By: "alice"})
+	reconciler.Config = func() *config.Config {
+		return cfg
+	}
+
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: observedJob.GetName()}}
+	_, err = reconciler.Reconcile(request)
+	require.NoError(t, err)
+
+	var pipelineRunList tektonv1beta1.PipelineRunList
+	require.NoError(t, c.List(nil, &pipelineRunList, client.InNamespace(ns)))
+	assert.Empty(t, pipelineRunList.Items, "the periodic is not started")
+	var skipped lighthousev1alpha1.LighthouseJob
+	require.NoError(t, c.Get(nil, request.NamespacedName, &skipped))
+	assert.Equal(t, lighthousev1alpha1.AbortedState, skipped.Status.State)
+	assert.Equal(t, "Skipped: Periodics paused by alice: flaky cluster.", skipped.Status.Description)
+}
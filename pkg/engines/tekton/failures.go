@@ -0,0 +1,98 @@
+package tekton
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// InvalidPipelineDescription is the description of the jobs whose pipeline could not run because of its definition
+	InvalidPipelineDescription = "Invalid pipeline"
+	// SchedulingFailedDescription is the description of the jobs whose pods could not be created or scheduled
+	SchedulingFailedDescription = "Pod could not be scheduled"
+	// EvictedDescription is the description of the jobs whose pods were evicted from their node
+	EvictedDescription = "Pod evicted"
+	// ImagePullFailedDescription is the description of the jobs whose step images could not be pulled
+	ImagePullFailedDescription = "Image pull failed"
+	// CloneFailedDescription is the description of the jobs which failed to clone their repository
+	CloneFailedDescription = "Clone failed"
+)
+
+var (
+	// configFailureReasons are the reasons of the pipeline and task runs which could not run because of their
+	// definition, these mirror the reasons of the tekton reconcilers and pod package.
+	configFailureReasons = sets.NewString(
+		"CouldntGetPipeline", "CouldntGetTask", "CouldntGetResource", "CouldntGetCondition",
+		"InvalidPipelineResourceBindings", "InvalidWorkspaceBindings", "InvalidServiceAccountMappings",
+		"ParameterTypeMismatch", "ParameterMissing", "PipelineValidationFailed", "PipelineInvalidGraph",
+		"TaskRunResolutionFailed", "TaskRunValidationFailed", "CreateContainerConfigError",
+	)
+
+	// infraFailureReasons are the reasons of the task runs which failed because of the cluster rather than the steps
+	// of the pipeline, these mirror the reasons of the tekton pod package.
+	infraFailureReasons = sets.NewString("ExceededResourceQuota", "ExceededNodeResources", "PodCreationFailed")
+
+	// imagePullReasons are the reasons of the steps waiting for their image to be pulled after a failed pull
+	imagePullReasons = sets.NewString("ErrImagePull", "ImagePullBackOff")
+
+	// cloneNames are the names of the tasks and steps which only clone the repository of the pipeline, their failures
+	// are blamed on the network or the git server
+	cloneNames = sets.NewString(gitCloneCatalogTaskName)
+
+	// mergeNames are the names of the tasks and steps which merge the pull requests into their base, their failures
+	// are usually merge conflicts to be fixed in the pull requests, unless their message shows a network failure
+	mergeNames = sets.NewString(gitMergeCatalogTaskName, "git-merge")
+
+	// networkFailureRe matches the messages of the git commands which failed to reach the git server
+	networkFailureRe = regexp.MustCompile(`(?i)could not resolve host|unable to access|connection (refused|reset|timed out)|the remote end hung up|early eof|rpc failed`)
+)
+
+// classifyFailure returns the class of the failure of a pipeline run which did not succeed along with its cause, or
+// an empty class if nothing but its steps is to blame
+func classifyFailure(pr *v1beta1.PipelineRun, cond *apis.Condition) (v1alpha1.FailureClass, string) {
+	if cond != nil && configFailureReasons.Has(cond.Reason) {
+		return v1alpha1.ConfigFailure, InvalidPipelineDescription
+	}
+	for _, taskName := range sets.StringKeySet(pr.Status.TaskRuns).List() {
+		task := pr.Status.TaskRuns[taskName]
+		taskCond := task.Status.GetCondition(apis.ConditionSucceeded)
+		if taskCond != nil && configFailureReasons.Has(taskCond.Reason) {
+			return v1alpha1.ConfigFailure, InvalidPipelineDescription
+		}
+		if taskCond != nil && taskCond.IsFalse() {
+			if infraFailureReasons.Has(taskCond.Reason) {
+				return v1alpha1.InfraFailure, SchedulingFailedDescription
+			}
+			// tekton reports the message of the pods evicted by the kubelet
+			if strings.Contains(taskCond.Message, "The node was low on resource") || strings.Contains(strings.ToLower(taskCond.Message), "evicted") {
+				return v1alpha1.InfraFailure, EvictedDescription
+			}
+		}
+		for _, step := range task.Status.Steps {
+			switch {
+			case step.Waiting != nil && imagePullReasons.Has(step.Waiting.Reason):
+				return v1alpha1.InfraFailure, ImagePullFailedDescription
+			case step.Terminated != nil && step.Terminated.ExitCode != 0 && cloneFailed(step.Name, step.Terminated.Message):
+				return v1alpha1.InfraFailure, CloneFailedDescription
+			}
+		}
+		if taskCond != nil && taskCond.IsFalse() && cloneFailed(task.PipelineTaskName, taskCond.Message) {
+			return v1alpha1.InfraFailure, CloneFailedDescription
+		}
+	}
+	return "", ""
+}
+
+// cloneFailed returns whether the failure of the named task or step is a failure to clone the repository rather than a
+// failure of the pull request, such as a merge conflict, given its message
+func cloneFailed(name, message string) bool {
+	if cloneNames.Has(name) || strings.HasPrefix(name, "git-source-") {
+		return true
+	}
+	return mergeNames.Has(name) && networkFailureRe.MatchString(message)
+}
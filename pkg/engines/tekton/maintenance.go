@@ -0,0 +1,55 @@
+package tekton
+
+import (
+	"context"
+	"time"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	configjob "github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// skipPeriodic aborts a triggered periodic job instead of starting it if the periodics of its repository are paused
+// through the admin API or by a maintenance window in progress. It returns whether the job was skipped.
+func (r *LighthouseJobReconciler) skipPeriodic(ctx context.Context, job *lighthousev1alpha1.LighthouseJob) (bool, error) {
+	if job.Spec.Type != configjob.PeriodicJob || r.Config == nil {
+		return false, nil
+	}
+	cfg := r.Config()
+	if cfg == nil {
+		return false, nil
+	}
+	var org, repo string
+	if refs := job.Spec.Refs; refs != nil {
+		org, repo = refs.Org, refs.Repo
+	} else if len(job.Spec.ExtraRefs) > 0 {
+		org, repo = job.Spec.ExtraRefs[0].Org, job.Spec.ExtraRefs[0].Repo
+	}
+	logger := logrusutil.FromContext(ctx)
+	var description string
+	if pause := cfg.Pauses.Paused(lighthouse.PausePeriodics, org, repo); pause != nil {
+		logger.Infof("Skipping LighthouseJob %s as the periodics are paused", job.Name)
+		description = "Periodics " + pause.Description() + "."
+	} else {
+		window, period := cfg.Maintenance.Paused(lighthouse.PausePeriodics, org, repo, time.Now())
+		if window == nil {
+			return false, nil
+		}
+		logger.Infof("Skipping LighthouseJob %s during the maintenance window %s", job.Name, window.Name)
+		description = window.Description(period)
+	}
+	now := metav1.Now()
+	job.Status = lighthousev1alpha1.LighthouseJobStatus{
+		State:          lighthousev1alpha1.AbortedState,
+		Description:    lighthousev1alpha1.SkippedDescriptionPrefix + description,
+		StartTime:      now,
+		CompletionTime: &now,
+	}
+	if err := r.client.Status().Update(ctx, job); err != nil {
+		logger.Errorf("Failed to update LighthouseJob status: %s", err)
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,115 @@
+package tekton
+
+import (
+	"context"
+	"time"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// TimedOutAnnotation is added to the pipeline runs cancelled by the controller because their job exceeded its timeout
+	TimedOutAnnotation = "lighthouse.jenkins-x.io/timedOut"
+	// TimedOutDescription is the description of the jobs aborted because they exceeded their timeout
+	TimedOutDescription = "Timed out"
+
+	// defaultGracePeriod is how long a cancelled pipeline run is given to stop before it is deleted by default
+	defaultGracePeriod = time.Minute
+)
+
+var jobTimeouts = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "lighthouse_job_timeouts",
+	Help: "A counter of the jobs aborted because they exceeded their timeout.",
+}, []string{"job", "org", "repo"})
+
+func init() {
+	// registered with the registry served by the controller manager
+	metrics.Registry.MustRegister(jobTimeouts)
+}
+
+// enforceTimeout aborts the pipeline of a job which exceeded its timeout, in case Tekton did not stop it itself, e.g.
+// because its pods never started: the pipeline run is cancelled so that Tekton stops its pods, then deleted if it is
+// still running once the grace period is over, the job being reported as timed out. It returns how long to wait before
+// checking the job again, or 0 if it has no timeout or its pipeline is done.
+func (r *LighthouseJobReconciler) enforceTimeout(ctx context.Context, job *lighthousev1alpha1.LighthouseJob, pipelineRun *pipelinev1beta1.PipelineRun, runClient client.Client) (time.Duration, error) {
+	if job.Spec.Timeout == nil || job.Status.StartTime.IsZero() || pipelineRun.IsDone() {
+		return 0, nil
+	}
+	logger := logrusutil.FromContext(ctx)
+	deadline := job.Status.StartTime.Add(job.Spec.Timeout.Duration)
+	now := time.Now()
+	if now.Before(deadline) {
+		return deadline.Sub(now), nil
+	}
+
+	gracePeriod := defaultGracePeriod
+	if job.Spec.GracePeriod != nil {
+		gracePeriod = job.Spec.GracePeriod.Duration
+	}
+	if pipelineRun.Annotations[TimedOutAnnotation] == "" {
+		logger.Infof("Cancelling PipelineRun %s as LighthouseJob %s exceeded its timeout of %s", pipelineRun.Name, job.Name, job.Spec.Timeout.Duration)
+		if pipelineRun.Annotations == nil {
+			pipelineRun.Annotations = map[string]string{}
+		}
+		pipelineRun.Annotations[TimedOutAnnotation] = "true"
+		pipelineRun.Spec.Status = pipelinev1beta1.PipelineRunSpecStatusCancelled
+		if err := runClient.Update(ctx, pipelineRun); err != nil {
+			return 0, errors.Wrapf(err, "failed to cancel PipelineRun %s", pipelineRun.Name)
+		}
+	}
+	if stop := deadline.Add(gracePeriod); now.Before(stop) {
+		return stop.Sub(now), nil
+	}
+
+	logger.Warnf("Deleting PipelineRun %s as it did not stop within the grace period of %s", pipelineRun.Name, gracePeriod)
+	if err := runClient.Delete(ctx, pipelineRun); client.IgnoreNotFound(err) != nil {
+		return 0, errors.Wrapf(err, "failed to delete PipelineRun %s", pipelineRun.Name)
+	}
+	// the pipeline run will not report its completion any more
+	activity := ConvertPipelineRun(pipelineRun)
+	completed := metav1.NewTime(now)
+	activity.Status = lighthousev1alpha1.AbortedState
+	activity.Description = TimedOutDescription
+	activity.CompletionTime = &completed
+	for _, stage := range activity.Stages {
+		if !isFinished(stage.Status) {
+			stage.Status = lighthousev1alpha1.AbortedState
+		}
+	}
+	observeActivity(job, activity)
+	job.Status.Activity = activity
+	if err := r.client.Status().Update(ctx, job); err != nil {
+		return 0, errors.Wrapf(err, "failed to update the status of LighthouseJob %s", job.Name)
+	}
+	return 0, nil
+}
+
+// observeActivity counts the timeout of the job the first time its activity reports it
+func observeActivity(job *lighthousev1alpha1.LighthouseJob, activity *lighthousev1alpha1.ActivityRecord) {
+	if activity.Description != TimedOutDescription {
+		return
+	}
+	if previous := job.Status.Activity; previous != nil && previous.Description == TimedOutDescription {
+		return
+	}
+	var org, repo string
+	if job.Spec.Refs != nil {
+		org, repo = job.Spec.Refs.Org, job.Spec.Refs.Repo
+	}
+	jobTimeouts.WithLabelValues(job.Spec.Job, org, repo).Inc()
+}
+
+func isFinished(state lighthousev1alpha1.PipelineState) bool {
+	switch state {
+	case lighthousev1alpha1.SuccessState, lighthousev1alpha1.FailureState, lighthousev1alpha1.AbortedState, lighthousev1alpha1.ErrorState:
+		return true
+	}
+	return false
+}
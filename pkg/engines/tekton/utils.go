@@ -1,20 +1,21 @@
 package tekton
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
-	"text/template"
 	"time"
 
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
 	"github.com/jenkins-x/lighthouse/pkg/jobutil"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -48,7 +49,8 @@ func trimDashboardURL(base string) string {
 
 // makePipeline creates a PipelineRun and substitutes LighthouseJob managed pipeline resources with ResourceSpec instead of ResourceRef
 // so that we don't have to take care of potentially dangling created pipeline resources.
-func makePipelineRun(ctx context.Context, lj v1alpha1.LighthouseJob, namespace string, logger *logrus.Entry, idGen buildIDGenerator, c client.Reader) (*tektonv1beta1.PipelineRun, error) {
+// The repository is cloned from cloneURI if set, e.g. from a git cache, otherwise from the clone URI of the job.
+func makePipelineRun(ctx context.Context, lj v1alpha1.LighthouseJob, namespace string, logger *logrus.Entry, idGen buildIDGenerator, c client.Reader, cloneURI string) (*tektonv1beta1.PipelineRun, error) {
 	// First validate.
 	if lj.Spec.PipelineRunSpec == nil {
 		return nil, errors.New("no PipelineSpec defined")
@@ -58,6 +60,9 @@ func makePipelineRun(ctx context.Context, lj v1alpha1.LighthouseJob, namespace s
 	if buildID == "" {
 		return nil, errors.New("empty BuildID in status")
 	}
+	if cloneURI == "" && lj.Spec.Refs != nil {
+		cloneURI = lj.Spec.Refs.CloneURI
+	}
 
 	prLabels, annotations := jobutil.LabelsAndAnnotationsForJob(lj, buildID)
 	specCopy := lj.Spec.PipelineRunSpec.DeepCopy()
@@ -70,15 +75,21 @@ func makePipelineRun(ctx context.Context, lj v1alpha1.LighthouseJob, namespace s
 		},
 		Spec: *specCopy,
 	}
+	// let Tekton enforce the timeout of the job, the controller only aborts the pipelines Tekton fails to stop
+	if lj.Spec.Timeout != nil {
+		p.Spec.Timeout = lj.Spec.Timeout.DeepCopy()
+	}
 	// Set a default timeout of 1 day if no timeout is specified
 	if p.Spec.Timeout == nil {
 		p.Spec.Timeout = &metav1.Duration{Duration: 24 * time.Hour}
 	}
+	applyPodTemplate(&p, lj.Spec.PodTemplate, logger)
+	applyEnvFromSecrets(&p, lj.Spec.EnvFromSecrets, logger)
 
 	// Add parameters instead of env vars.
 	env := lj.Spec.GetEnvVars()
 	env[v1alpha1.BuildIDEnv] = buildID
-	env[v1alpha1.RepoURLEnv] = lj.Spec.Refs.CloneURI
+	env[v1alpha1.RepoURLEnv] = cloneURI
 	var batchedRefsVals []string
 	for _, pull := range lj.Spec.Refs.Pulls {
 		if pull.Ref != "" {
@@ -89,20 +100,12 @@ func makePipelineRun(ctx context.Context, lj v1alpha1.LighthouseJob, namespace s
 		env[v1alpha1.PullPullRefEnv] = strings.Join(batchedRefsVals, " ")
 	}
 	if len(lj.Spec.PipelineRunParams) > 0 {
-		payload := map[string]interface{}{
-			"Refs": lj.Spec.Refs,
+		params, err := jobutil.RenderPipelineRunParams(&lj.Spec)
+		if err != nil {
+			return nil, err
 		}
-		for _, param := range lj.Spec.PipelineRunParams {
-			parsedTemplate, err := template.New(param.Name).Parse(param.ValueTemplate)
-			if err != nil {
-				return nil, err
-			}
-			var msgBuffer bytes.Buffer
-			err = parsedTemplate.Execute(&msgBuffer, payload)
-			if err != nil {
-				return nil, err
-			}
-			env[param.Name] = msgBuffer.String()
+		for name, value := range params {
+			env[name] = value
 		}
 	} else {
 		paramNames, err := determineGitCloneOrMergeTaskParams(ctx, &p, c)
@@ -112,7 +115,7 @@ func makePipelineRun(ctx context.Context, lj v1alpha1.LighthouseJob, namespace s
 		if paramNames == nil {
 			logger.Warnf("git-clone and/or git-batch-merge task parameters not found in Pipeline for PipelineRun, so skipping setting PipelineRun parameters for revision")
 		} else {
-			env[paramNames.urlParam] = lj.Spec.Refs.CloneURI
+			env[paramNames.urlParam] = cloneURI
 			if paramNames.revParam != "" {
 				if len(lj.Spec.Refs.Pulls) > 0 {
 					env[paramNames.revParam] = lj.Spec.Refs.Pulls[0].SHA
@@ -128,6 +131,13 @@ func makePipelineRun(ctx context.Context, lj v1alpha1.LighthouseJob, namespace s
 			}
 		}
 	}
+	for _, key := range sets.StringKeySet(lj.Spec.Params).List() {
+		if _, ok := env[key]; ok {
+			logger.Warnf("ignoring param %s of job %s as it is already set by lighthouse", key, lj.Spec.Job)
+			continue
+		}
+		env[key] = lj.Spec.Params[key]
+	}
 	for _, key := range sets.StringKeySet(env).List() {
 		val := env[key]
 		// TODO: make this handle existing values/substitutions.
@@ -143,6 +153,98 @@ func makePipelineRun(ctx context.Context, lj v1alpha1.LighthouseJob, namespace s
 	return &p, nil
 }
 
+// applyPodTemplate applies the pod template of the job to the PipelineRun. The resources are set on the step
+// template of the inline tasks so that they only apply to the steps which do not define their own.
+func applyPodTemplate(p *tektonv1beta1.PipelineRun, t *job.PodTemplate, logger *logrus.Entry) {
+	if t == nil {
+		return
+	}
+	if t.ServiceAccountName != "" {
+		p.Spec.ServiceAccountName = t.ServiceAccountName
+	}
+	if len(t.NodeSelector) > 0 || len(t.Tolerations) > 0 || t.PriorityClassName != "" {
+		if p.Spec.PodTemplate == nil {
+			p.Spec.PodTemplate = &tektonv1beta1.PodTemplate{}
+		}
+		if len(t.NodeSelector) > 0 && p.Spec.PodTemplate.NodeSelector == nil {
+			p.Spec.PodTemplate.NodeSelector = map[string]string{}
+		}
+		for k, v := range t.NodeSelector {
+			p.Spec.PodTemplate.NodeSelector[k] = v
+		}
+		p.Spec.PodTemplate.Tolerations = append(p.Spec.PodTemplate.Tolerations, t.Tolerations...)
+		if t.PriorityClassName != "" {
+			priorityClassName := t.PriorityClassName
+			p.Spec.PodTemplate.PriorityClassName = &priorityClassName
+		}
+	}
+	if t.Resources == nil {
+		return
+	}
+	if p.Spec.PipelineSpec == nil {
+		if p.Spec.PipelineRef != nil {
+			logger.Warnf("cannot apply the resources of the pod template to the referenced pipeline %s, only inline pipelines are supported", p.Spec.PipelineRef.Name)
+		}
+		return
+	}
+	for i := range p.Spec.PipelineSpec.Tasks {
+		taskSpec := p.Spec.PipelineSpec.Tasks[i].TaskSpec
+		if taskSpec == nil {
+			continue
+		}
+		if taskSpec.StepTemplate == nil {
+			taskSpec.StepTemplate = &corev1.Container{}
+		}
+		taskSpec.StepTemplate.Resources = *t.Resources.DeepCopy()
+	}
+}
+
+// applyEnvFromSecrets exposes the given secrets as environment variables to the steps of the inline tasks
+// of the PipelineRun.
+func applyEnvFromSecrets(p *tektonv1beta1.PipelineRun, secrets []string, logger *logrus.Entry) {
+	if len(secrets) == 0 {
+		return
+	}
+	if p.Spec.PipelineSpec == nil {
+		if p.Spec.PipelineRef != nil {
+			logger.Warnf("cannot expose secrets %s to the referenced pipeline %s, only inline pipelines are supported", strings.Join(secrets, ", "), p.Spec.PipelineRef.Name)
+		}
+		return
+	}
+	for i := range p.Spec.PipelineSpec.Tasks {
+		taskSpec := p.Spec.PipelineSpec.Tasks[i].TaskSpec
+		if taskSpec == nil {
+			continue
+		}
+		if taskSpec.StepTemplate == nil {
+			taskSpec.StepTemplate = &corev1.Container{}
+		}
+		for _, secret := range secrets {
+			taskSpec.StepTemplate.EnvFrom = append(taskSpec.StepTemplate.EnvFrom, corev1.EnvFromSource{
+				SecretRef: &corev1.SecretEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secret},
+				},
+			})
+		}
+	}
+}
+
+// missingSecrets returns the names of the secrets referenced by the job which do not exist in the namespace
+func missingSecrets(ctx context.Context, lj v1alpha1.LighthouseJob, namespace string, c client.Reader) ([]string, error) {
+	var missing []string
+	for _, name := range lj.Spec.EnvFromSecrets {
+		var secret corev1.Secret
+		if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				missing = append(missing, name)
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to get secret %s", name)
+		}
+	}
+	return missing, nil
+}
+
 type gitTaskParamNames struct {
 	urlParam          string
 	revParam          string
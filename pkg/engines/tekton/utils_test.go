@@ -0,0 +1,102 @@
+package tekton
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestApplyPodTemplate(t *testing.T) {
+	resources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+	}
+	pr := &tektonv1beta1.PipelineRun{
+		Spec: tektonv1beta1.PipelineRunSpec{
+			ServiceAccountName: "tekton-bot",
+			PodTemplate: &tektonv1beta1.PodTemplate{
+				NodeSelector: map[string]string{"zone": "a"},
+			},
+			PipelineSpec: &tektonv1beta1.PipelineSpec{
+				Tasks: []tektonv1beta1.PipelineTask{
+					{Name: "build", TaskSpec: &tektonv1beta1.TaskSpec{}},
+					{Name: "referenced", TaskRef: &tektonv1beta1.TaskRef{Name: "git-clone"}},
+				},
+			},
+		},
+	}
+	template := &job.PodTemplate{
+		Resources:          &resources,
+		NodeSelector:       map[string]string{"size": "big"},
+		Tolerations:        []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}},
+		ServiceAccountName: "builder",
+		PriorityClassName:  "presubmit",
+	}
+
+	applyPodTemplate(pr, template, logrus.WithField("test", t.Name()))
+
+	assert.Equal(t, "builder", pr.Spec.ServiceAccountName)
+	assert.Equal(t, map[string]string{"zone": "a", "size": "big"}, pr.Spec.PodTemplate.NodeSelector)
+	assert.Equal(t, template.Tolerations, pr.Spec.PodTemplate.Tolerations)
+	require.NotNil(t, pr.Spec.PodTemplate.PriorityClassName)
+	assert.Equal(t, "presubmit", *pr.Spec.PodTemplate.PriorityClassName)
+	assert.Equal(t, resources, pr.Spec.PipelineSpec.Tasks[0].TaskSpec.StepTemplate.Resources)
+	assert.Nil(t, pr.Spec.PipelineSpec.Tasks[1].TaskSpec)
+
+	unchanged := pr.DeepCopy()
+	applyPodTemplate(pr, nil, logrus.WithField("test", t.Name()))
+	assert.Equal(t, unchanged, pr)
+}
+
+func TestApplyEnvFromSecrets(t *testing.T) {
+	pr := &tektonv1beta1.PipelineRun{
+		Spec: tektonv1beta1.PipelineRunSpec{
+			PipelineSpec: &tektonv1beta1.PipelineSpec{
+				Tasks: []tektonv1beta1.PipelineTask{
+					{Name: "build", TaskSpec: &tektonv1beta1.TaskSpec{}},
+					{Name: "referenced", TaskRef: &tektonv1beta1.TaskRef{Name: "git-clone"}},
+				},
+			},
+		},
+	}
+
+	applyEnvFromSecrets(pr, []string{"docker-creds", "npm-token"}, logrus.WithField("test", t.Name()))
+
+	expected := []corev1.EnvFromSource{
+		{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "docker-creds"}}},
+		{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "npm-token"}}},
+	}
+	assert.Equal(t, expected, pr.Spec.PipelineSpec.Tasks[0].TaskSpec.StepTemplate.EnvFrom)
+	assert.Nil(t, pr.Spec.PipelineSpec.Tasks[1].TaskSpec)
+
+	unchanged := pr.DeepCopy()
+	applyEnvFromSecrets(pr, nil, logrus.WithField("test", t.Name()))
+	assert.Equal(t, unchanged, pr)
+}
+
+func TestMissingSecrets(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "jx", Name: "docker-creds"},
+	})
+	lj := v1alpha1.LighthouseJob{
+		Spec: v1alpha1.LighthouseJobSpec{
+			EnvFromSecrets: []string{"docker-creds", "npm-token"},
+		},
+	}
+
+	missing, err := missingSecrets(context.TODO(), lj, "jx", c)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"npm-token"}, missing)
+}
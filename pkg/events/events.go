@@ -0,0 +1,214 @@
+// Package events publishes the lifecycle of the pipelines and pull requests handled by Lighthouse, i.e. pipelines
+// being triggered, starting and finishing and pull requests being merged, to a message bus such as NATS or Kafka so that
+// external systems, e.g. dashboards or DORA metrics collectors, can subscribe to them rather than poll. Events are
+// delivered at least once: their IDs are stable so that consumers can discard the redelivered ones.
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/sirupsen/logrus"
+)
+
+// SchemaVersion is the version of the schema of the published events. It only changes when fields are removed or
+// change meaning, new fields being added without changing it.
+const SchemaVersion = "lighthouse.jenkins-x.io/v1"
+
+// Type is the type of an event
+type Type string
+
+const (
+	// PipelineTriggered is published when a pipeline is triggered, before it waits in the queue
+	PipelineTriggered Type = "pipeline.triggered"
+	// PipelineStarted is published when the pipeline of a job starts
+	PipelineStarted Type = "pipeline.started"
+	// PipelineFinished is published when a pipeline completes, whatever its state
+	PipelineFinished Type = "pipeline.finished"
+	// PullRequestMerged is published when a pull request is merged, by Lighthouse or not
+	PullRequestMerged Type = "pullrequest.merged"
+)
+
+// Event is a published event
+type Event struct {
+	// SchemaVersion is the version of the schema of the event
+	SchemaVersion string `json:"schema_version"`
+	// ID identifies the event. Redeliveries of an event have the same ID.
+	ID string `json:"id"`
+	// Type is the type of the event
+	Type Type `json:"type"`
+	// Time is when the event was published
+	Time time.Time `json:"time"`
+	// Component is the Lighthouse component which published the event, e.g. foghorn or webhooks
+	Component string `json:"component,omitempty"`
+	// Org, Repo and Number identify the repository and the pull request of the event, if any
+	Org    string `json:"org,omitempty"`
+	Repo   string `json:"repo,omitempty"`
+	Number int    `json:"number,omitempty"`
+	// BaseRef is the branch the pipeline runs against or the pull request was merged into
+	BaseRef string `json:"base_ref,omitempty"`
+	// SHA is the commit the pipeline runs against, or the commit of the merge of the pull request
+	SHA string `json:"sha,omitempty"`
+	// Author is the login of the author of the pull request
+	Author string `json:"author,omitempty"`
+	// Job describes the pipeline of the pipeline events
+	Job *Job `json:"job,omitempty"`
+}
+
+// Job describes the pipeline of a pipeline event
+type Job struct {
+	// Name is the name of the LighthouseJob
+	Name string `json:"name"`
+	// Job is the name of the job in the configuration
+	Job string `json:"job"`
+	// Type is the type of the job, e.g. presubmit
+	Type string `json:"type"`
+	// Context is the commit status context of the job
+	Context string `json:"context,omitempty"`
+	// State is the state of the job when the event was published
+	State string `json:"state,omitempty"`
+	// Description describes the state of the job
+	Description string `json:"description,omitempty"`
+	// URL is the URL of the pipeline, e.g. of its logs
+	URL string `json:"url,omitempty"`
+	// StartTime and CompletionTime are when the job was triggered and completed
+	StartTime      *time.Time `json:"start_time,omitempty"`
+	CompletionTime *time.Time `json:"completion_time,omitempty"`
+	// DurationSeconds is how long the job took to complete
+	DurationSeconds float64 `json:"duration_seconds,omitempty"`
+}
+
+// Publisher publishes the events to a message bus
+type Publisher interface {
+	// Publish returns once the message bus acknowledged the event
+	Publish(ctx context.Context, event Event) error
+}
+
+var (
+	lock       sync.RWMutex
+	component  string
+	publishers []Publisher
+	now        = time.Now
+	// maxElapsedTime is how long publishing an event is retried before giving up
+	maxElapsedTime = 30 * time.Second
+)
+
+// SetPublishers configures the component publishing the events, e.g. foghorn, and the publishers the events are
+// published with. Nothing is published until publishers are configured.
+func SetPublishers(componentName string, p ...Publisher) {
+	lock.Lock()
+	defer lock.Unlock()
+	component = componentName
+	publishers = p
+}
+
+// Enabled returns whether events are published, so that callers can skip tracking their delivery
+func Enabled() bool {
+	lock.RLock()
+	defer lock.RUnlock()
+	return len(publishers) > 0
+}
+
+// Publish publishes the event with all the configured publishers, retrying each of them with an exponential backoff.
+// It returns an error if any of them failed, in which case the caller publishes the event again later, the publishers
+// which succeeded delivering it again.
+func Publish(ctx context.Context, event Event) error {
+	// the publishers are retried without holding the lock so that they can be reconfigured meanwhile
+	lock.RLock()
+	currentComponent, currentPublishers := component, publishers
+	lock.RUnlock()
+	if len(currentPublishers) == 0 {
+		return nil
+	}
+	event.SchemaVersion = SchemaVersion
+	if event.Time.IsZero() {
+		event.Time = now().UTC()
+	}
+	if event.Component == "" {
+		event.Component = currentComponent
+	}
+	var failed error
+	for _, p := range currentPublishers {
+		b := backoff.NewExponentialBackOff()
+		b.MaxElapsedTime = maxElapsedTime
+		err := backoff.RetryNotify(func() error {
+			return p.Publish(ctx, event)
+		}, backoff.WithContext(b, ctx), func(err error, wait time.Duration) {
+			logrus.WithError(err).WithField("event", event.ID).Debugf("failed to publish event, retrying in %s", wait)
+		})
+		if err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"event": event.ID,
+				"type":  event.Type,
+				"org":   event.Org,
+				"repo":  event.Repo,
+			}).Error("failed to publish event")
+			failed = err
+		}
+	}
+	if failed != nil {
+		return fmt.Errorf("failed to publish event %s: %v", event.ID, failed)
+	}
+	return nil
+}
+
+// ForJob creates the event of the given type for a LighthouseJob. Its ID is derived from the name of the job and the
+// type so that it is the same when the event is published again.
+func ForJob(eventType Type, job *v1alpha1.LighthouseJob) Event {
+	event := Event{
+		ID:   fmt.Sprintf("%s.%s", job.Name, eventType),
+		Type: eventType,
+		Job: &Job{
+			Name:        job.Name,
+			Job:         job.Spec.Job,
+			Type:        string(job.Spec.Type),
+			Context:     job.Spec.Context,
+			State:       string(job.Status.State),
+			Description: job.Status.Description,
+			URL:         job.Status.ReportURL,
+		},
+	}
+	if refs := job.Spec.Refs; refs != nil {
+		event.Org = refs.Org
+		event.Repo = refs.Repo
+		event.BaseRef = refs.BaseRef
+		event.SHA = refs.BaseSHA
+		if len(refs.Pulls) > 0 {
+			event.Number = refs.Pulls[0].Number
+			event.SHA = refs.Pulls[0].SHA
+			event.Author = refs.Pulls[0].Author
+		}
+	}
+	if !job.Status.StartTime.IsZero() {
+		start := job.Status.StartTime.Time.UTC()
+		event.Job.StartTime = &start
+	}
+	if t := job.Status.CompletionTime; t != nil && !t.IsZero() {
+		completion := t.Time.UTC()
+		event.Job.CompletionTime = &completion
+		if event.Job.StartTime != nil {
+			event.Job.DurationSeconds = completion.Sub(*event.Job.StartTime).Seconds()
+		}
+	}
+	return event
+}
+
+// DueForJob returns the types of the events of the lifecycle of the job which are due given its state, in the order
+// they are published
+func DueForJob(job *v1alpha1.LighthouseJob) []Type {
+	answer := []Type{PipelineTriggered}
+	switch {
+	case job.Complete():
+		if job.Status.Activity != nil {
+			answer = append(answer, PipelineStarted)
+		}
+		answer = append(answer, PipelineFinished)
+	case job.Status.Activity != nil || job.Status.State == v1alpha1.PendingState || job.Status.State == v1alpha1.RunningState:
+		answer = append(answer, PipelineStarted)
+	}
+	return answer
+}
@@ -0,0 +1,226 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakePublisher struct {
+	failures  int
+	published []Event
+}
+
+func (p *fakePublisher) Publish(_ context.Context, event Event) error {
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("unavailable")
+	}
+	p.published = append(p.published, event)
+	return nil
+}
+
+func TestPublish(t *testing.T) {
+	defer SetPublishers("")
+	now = func() time.Time { return time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	assert.NoError(t, Publish(context.Background(), Event{ID: "1"}))
+	assert.False(t, Enabled())
+
+	flaky := &fakePublisher{failures: 1}
+	SetPublishers("foghorn", flaky)
+	assert.True(t, Enabled())
+	require.NoError(t, Publish(context.Background(), Event{ID: "1", Type: PipelineTriggered}))
+	assert.Equal(t, []Event{{SchemaVersion: SchemaVersion, ID: "1", Type: PipelineTriggered, Time: now(), Component: "foghorn"}}, flaky.published)
+
+	maxElapsedTime = 0
+	defer func() { maxElapsedTime = 30 * time.Second }()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	SetPublishers("foghorn", &fakePublisher{failures: 1000})
+	assert.EqualError(t, Publish(ctx, Event{ID: "2"}), "failed to publish event 2: unavailable")
+}
+
+func TestForJob(t *testing.T) {
+	start := metav1.NewTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	completion := metav1.NewTime(start.Add(90 * time.Second))
+	lhjob := &v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "myorg-myrepo-pr-1-abcde"},
+		Spec: v1alpha1.LighthouseJobSpec{
+			Type:    job.PresubmitJob,
+			Job:     "unit",
+			Context: "unit",
+			Refs: &v1alpha1.Refs{
+				Org:     "myorg",
+				Repo:    "myrepo",
+				BaseRef: "main",
+				BaseSHA: "base",
+				Pulls:   []v1alpha1.Pull{{Number: 1, SHA: "head", Author: "someone"}},
+			},
+		},
+		Status: v1alpha1.LighthouseJobStatus{
+			State:          v1alpha1.SuccessState,
+			Description:    "Pipeline successful",
+			ReportURL:      "https://dashboard/unit",
+			StartTime:      start,
+			CompletionTime: &completion,
+		},
+	}
+
+	startTime, completionTime := start.Time, completion.Time
+	assert.Equal(t, Event{
+		ID:      "myorg-myrepo-pr-1-abcde.pipeline.finished",
+		Type:    PipelineFinished,
+		Org:     "myorg",
+		Repo:    "myrepo",
+		Number:  1,
+		BaseRef: "main",
+		SHA:     "head",
+		Author:  "someone",
+		Job: &Job{
+			Name:            "myorg-myrepo-pr-1-abcde",
+			Job:             "unit",
+			Type:            "presubmit",
+			Context:         "unit",
+			State:           "success",
+			Description:     "Pipeline successful",
+			URL:             "https://dashboard/unit",
+			StartTime:       &startTime,
+			CompletionTime:  &completionTime,
+			DurationSeconds: 90,
+		},
+	}, ForJob(PipelineFinished, lhjob))
+}
+
+func TestDueForJob(t *testing.T) {
+	completion := metav1.Now()
+	testCases := []struct {
+		name     string
+		status   v1alpha1.LighthouseJobStatus
+		expected []Type
+	}{
+		{
+			name:     "triggered",
+			status:   v1alpha1.LighthouseJobStatus{State: v1alpha1.TriggeredState},
+			expected: []Type{PipelineTriggered},
+		},
+		{
+			name:     "pending",
+			status:   v1alpha1.LighthouseJobStatus{State: v1alpha1.PendingState},
+			expected: []Type{PipelineTriggered, PipelineStarted},
+		},
+		{
+			name:     "completed",
+			status:   v1alpha1.LighthouseJobStatus{State: v1alpha1.SuccessState, CompletionTime: &completion, Activity: &v1alpha1.ActivityRecord{}},
+			expected: []Type{PipelineTriggered, PipelineStarted, PipelineFinished},
+		},
+		{
+			name:     "aborted before starting",
+			status:   v1alpha1.LighthouseJobStatus{State: v1alpha1.AbortedState, CompletionTime: &completion},
+			expected: []Type{PipelineTriggered, PipelineFinished},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, DueForJob(&v1alpha1.LighthouseJob{Status: tc.status}))
+		})
+	}
+}
+
+// fakeNATSServer accepts a connection and acknowledges the published messages, like a JetStream stream if jetStream
+// is set. It sends the published messages on the channel.
+func fakeNATSServer(t *testing.T, jetStream bool, published chan<- string) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		defer l.Close()
+		r := bufio.NewReader(conn)
+		fmt.Fprint(conn, "INFO {\"server_id\":\"fake\",\"max_payload\":1048576}\r\n")
+		inbox := ""
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			fields := strings.Fields(line)
+			switch fields[0] {
+			case "PING":
+				fmt.Fprint(conn, "PONG\r\n")
+			case "SUB":
+				inbox = strings.TrimSuffix(fields[1], "*")
+			case "PUB":
+				size, _ := strconv.Atoi(fields[len(fields)-1])
+				payload := make([]byte, size+2)
+				_, _ = io.ReadFull(r, payload)
+				published <- fields[1] + " " + string(payload[:size])
+				if jetStream {
+					assert.True(t, strings.HasPrefix(fields[2], inbox))
+					ack := `{"stream":"EVENTS","seq":1}`
+					fmt.Fprintf(conn, "MSG %s 1 %d\r\n%s\r\n", fields[2], len(ack), ack)
+				}
+			}
+		}
+	}()
+	return "nats://token@" + l.Addr().String()
+}
+
+func TestNATSPublisher(t *testing.T) {
+	for _, jetStream := range []bool{false, true} {
+		t.Run(fmt.Sprintf("jetstream %t", jetStream), func(t *testing.T) {
+			published := make(chan string, 1)
+			p, err := NewNATSPublisher(fakeNATSServer(t, jetStream, published), "", jetStream)
+			require.NoError(t, err)
+			defer p.Close()
+
+			require.NoError(t, p.Publish(context.Background(), Event{ID: "1", Type: PipelineStarted}))
+			assert.Equal(t, `lighthouse.events.pipeline.started {"schema_version":"","id":"1","type":"pipeline.started","time":"0001-01-01T00:00:00Z"}`, <-published)
+		})
+	}
+
+	_, err := NewNATSPublisher("http://nats", "", false)
+	assert.Error(t, err)
+}
+
+func TestKafkaPublisher(t *testing.T) {
+	var records []map[string]interface{}
+	response := `{"offsets":[{"partition":0,"offset":1}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/topics/lighthouse-events", r.URL.Path)
+		assert.Equal(t, "application/vnd.kafka.json.v2+json", r.Header.Get("Content-Type"))
+		body := map[string][]map[string]interface{}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		records = append(records, body["records"]...)
+		_, _ = w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	p := NewKafkaPublisher(server.URL+"/", "")
+	require.NoError(t, p.Publish(context.Background(), Event{ID: "1", Org: "myorg", Repo: "myrepo"}))
+	require.Len(t, records, 1)
+	assert.Equal(t, "myorg/myrepo", records[0]["key"])
+
+	response = `{"offsets":[{"partition":null,"offset":null,"error_code":50301,"error":"unavailable"}]}`
+	assert.EqualError(t, p.Publish(context.Background(), Event{ID: "2"}), "failed to write event 2 to topic lighthouse-events: unavailable")
+}
@@ -0,0 +1,87 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultKafkaTopic is the topic the events are published to by default
+	DefaultKafkaTopic = "lighthouse-events"
+
+	kafkaContentType = "application/vnd.kafka.json.v2+json"
+)
+
+// KafkaPublisher publishes the events to a Kafka topic through a Kafka REST proxy, keyed by their repository so that
+// the events of a repository are kept in order. Each event is acknowledged once the proxy wrote it to the topic.
+type KafkaPublisher struct {
+	url    string
+	topic  string
+	client *http.Client
+}
+
+// NewKafkaPublisher creates a publisher posting the events to the topic of the Kafka REST proxy at the URL
+func NewKafkaPublisher(proxyURL, topic string) *KafkaPublisher {
+	if topic == "" {
+		topic = DefaultKafkaTopic
+	}
+	return &KafkaPublisher{
+		url:    strings.TrimSuffix(proxyURL, "/"),
+		topic:  topic,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type kafkaRecord struct {
+	Key   string `json:"key,omitempty"`
+	Value Event  `json:"value"`
+}
+
+type kafkaOffsets struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// Publish posts the event and checks that the proxy wrote it
+func (p *KafkaPublisher) Publish(ctx context.Context, event Event) error {
+	key := ""
+	if event.Org != "" {
+		key = event.Org + "/" + event.Repo
+	}
+	b, err := json.Marshal(map[string][]kafkaRecord{"records": {{Key: key, Value: event}}})
+	if err != nil {
+		return err
+	}
+	topicURL := fmt.Sprintf("%s/topics/%s", p.url, p.topic)
+	req, err := http.NewRequest(http.MethodPost, topicURL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	resp, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to post event %s to %s: %v", event.ID, topicURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to post event %s to %s: status %s", event.ID, topicURL, resp.Status)
+	}
+	offsets := kafkaOffsets{}
+	if err := json.NewDecoder(resp.Body).Decode(&offsets); err != nil {
+		return fmt.Errorf("failed to parse the response to event %s from %s: %v", event.ID, topicURL, err)
+	}
+	for _, o := range offsets.Offsets {
+		if o.ErrorCode != nil || o.Error != "" {
+			return fmt.Errorf("failed to write event %s to topic %s: %s", event.ID, p.topic, o.Error)
+		}
+	}
+	return nil
+}
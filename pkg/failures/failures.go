@@ -0,0 +1,55 @@
+// Package failures classifies why jobs did not succeed, so that the failures caused by the infrastructure can be told
+// apart from those caused by the configuration of the pipelines and by their tests.
+package failures
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+)
+
+// descriptions are the descriptions of the failure classes reported in the commit statuses
+var descriptions = map[v1alpha1.FailureClass]string{
+	v1alpha1.InfraFailure:  "infrastructure error",
+	v1alpha1.ConfigFailure: "configuration error",
+	v1alpha1.TestFailure:   "test failure",
+}
+
+// Classify returns the class of the failure of a pipeline in the given state, or an empty class if it did not fail.
+// The class recorded by the engine takes precedence, otherwise the errors are attributed to the infrastructure and
+// the failures to the tests.
+func Classify(state v1alpha1.PipelineState, class v1alpha1.FailureClass) v1alpha1.FailureClass {
+	switch state {
+	case v1alpha1.SuccessState, v1alpha1.TriggeredState, v1alpha1.PendingState, v1alpha1.RunningState:
+		return ""
+	}
+	if class != "" {
+		return class
+	}
+	switch state {
+	case v1alpha1.ErrorState:
+		return v1alpha1.InfraFailure
+	case v1alpha1.FailureState:
+		return v1alpha1.TestFailure
+	}
+	return ""
+}
+
+// ForJob returns the class of the failure of a job, or an empty class if it did not fail
+func ForJob(lhj *v1alpha1.LighthouseJob) v1alpha1.FailureClass {
+	return Classify(lhj.Status.State, lhj.Status.FailureClass)
+}
+
+// Retryable returns whether a job failed because of its infrastructure, so that running it again may succeed
+func Retryable(lhj *v1alpha1.LighthouseJob) bool {
+	return ForJob(lhj) == v1alpha1.InfraFailure
+}
+
+// Describe adds the class of the failure to the description of a commit status
+func Describe(description string, class v1alpha1.FailureClass) string {
+	text, ok := descriptions[class]
+	if !ok {
+		return description
+	}
+	return fmt.Sprintf("%s (%s)", description, text)
+}
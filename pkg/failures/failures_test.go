@@ -0,0 +1,67 @@
+package failures
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	testCases := []struct {
+		name     string
+		state    v1alpha1.PipelineState
+		class    v1alpha1.FailureClass
+		expected v1alpha1.FailureClass
+	}{
+		{
+			name:  "success",
+			state: v1alpha1.SuccessState,
+		},
+		{
+			name:  "running",
+			state: v1alpha1.RunningState,
+			class: v1alpha1.InfraFailure,
+		},
+		{
+			name:     "failure",
+			state:    v1alpha1.FailureState,
+			expected: v1alpha1.TestFailure,
+		},
+		{
+			name:     "error",
+			state:    v1alpha1.ErrorState,
+			expected: v1alpha1.InfraFailure,
+		},
+		{
+			name:     "error classified by the engine",
+			state:    v1alpha1.ErrorState,
+			class:    v1alpha1.ConfigFailure,
+			expected: v1alpha1.ConfigFailure,
+		},
+		{
+			name:  "aborted",
+			state: v1alpha1.AbortedState,
+		},
+		{
+			name:     "timed out waiting for the cluster",
+			state:    v1alpha1.AbortedState,
+			class:    v1alpha1.InfraFailure,
+			expected: v1alpha1.InfraFailure,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Classify(tc.state, tc.class))
+			lhj := &v1alpha1.LighthouseJob{Status: v1alpha1.LighthouseJobStatus{State: tc.state, FailureClass: tc.class}}
+			assert.Equal(t, tc.expected == v1alpha1.InfraFailure, Retryable(lhj))
+		})
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	assert.Equal(t, "Image pull failed (infrastructure error)", Describe("Image pull failed", v1alpha1.InfraFailure))
+	assert.Equal(t, "Invalid pipeline (configuration error)", Describe("Invalid pipeline", v1alpha1.ConfigFailure))
+	assert.Equal(t, "Pipeline failed (test failure)", Describe("Pipeline failed", v1alpha1.TestFailure))
+	assert.Equal(t, "Pipeline successful", Describe("Pipeline successful", ""))
+}
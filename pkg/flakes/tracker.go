@@ -0,0 +1,141 @@
+// Package flakes tracks the contexts which fail then pass on the same commit so that the flakiness of each
+// context can be quantified.
+package flakes
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultMaxCommits is the number of commits whose last result is remembered
+const defaultMaxCommits = 10000
+
+// ContextStats are the flakiness statistics of a context of a repository
+type ContextStats struct {
+	Org     string `json:"org"`
+	Repo    string `json:"repo"`
+	Context string `json:"context"`
+	// Runs is the number of completed runs of the context
+	Runs int `json:"runs"`
+	// Failures is the number of runs which failed or errored
+	Failures int `json:"failures"`
+	// Flakes is the number of times the context passed on a commit it previously failed on
+	Flakes int `json:"flakes"`
+	// FlakeRate is the ratio of flakes to runs
+	FlakeRate float64 `json:"flake_rate"`
+	// LastFlakeSHA is the commit the context last flaked on
+	LastFlakeSHA string `json:"last_flake_sha,omitempty"`
+	// LastFlakeTime is when the context last flaked
+	LastFlakeTime *time.Time `json:"last_flake_time,omitempty"`
+}
+
+// Tracker records the results of jobs to detect flakes
+type Tracker struct {
+	sync.Mutex
+	maxCommits int
+	// failed records whether the last run of a context on a commit failed
+	failed map[string]bool
+	order  []string
+	stats  map[string]*ContextStats
+}
+
+// NewTracker creates a tracker
+func NewTracker() *Tracker {
+	return &Tracker{
+		maxCommits: defaultMaxCommits,
+		failed:     map[string]bool{},
+		stats:      map[string]*ContextStats{},
+	}
+}
+
+// Observe records the result of a completed job, returning true if it is a flake
+func (t *Tracker) Observe(lj *v1alpha1.LighthouseJob) bool {
+	if lj.Spec.Refs == nil || lj.Spec.Context == "" {
+		return false
+	}
+	var failed bool
+	switch lj.Status.State {
+	case v1alpha1.SuccessState:
+	case v1alpha1.FailureState, v1alpha1.ErrorState:
+		failed = true
+	default:
+		// aborted jobs tell us nothing about flakiness
+		return false
+	}
+	refs := lj.Spec.Refs
+	sha := refs.BaseSHA
+	if len(refs.Pulls) > 0 {
+		sha = refs.Pulls[0].SHA
+	}
+	contextKey := refs.Org + "/" + refs.Repo + "/" + lj.Spec.Context
+	commitKey := contextKey + "@" + sha
+
+	t.Lock()
+	defer t.Unlock()
+	stats, ok := t.stats[contextKey]
+	if !ok {
+		stats = &ContextStats{Org: refs.Org, Repo: refs.Repo, Context: lj.Spec.Context}
+		t.stats[contextKey] = stats
+	}
+	stats.Runs++
+	if failed {
+		stats.Failures++
+	}
+	previouslyFailed, seen := t.failed[commitKey]
+	flaked := seen && previouslyFailed && !failed
+	if flaked {
+		stats.Flakes++
+		stats.LastFlakeSHA = sha
+		completed := time.Now()
+		if lj.Status.CompletionTime != nil {
+			completed = lj.Status.CompletionTime.Time
+		}
+		stats.LastFlakeTime = &completed
+	}
+	stats.FlakeRate = float64(stats.Flakes) / float64(stats.Runs)
+
+	if !seen {
+		t.order = append(t.order, commitKey)
+		for len(t.order) > t.maxCommits {
+			delete(t.failed, t.order[0])
+			t.order = t.order[1:]
+		}
+	}
+	t.failed[commitKey] = failed
+	return flaked
+}
+
+// Report returns the statistics of the contexts of the given repository, or of all repositories if empty, the
+// flakiest first
+func (t *Tracker) Report(org, repo string) []ContextStats {
+	t.Lock()
+	defer t.Unlock()
+	report := []ContextStats{}
+	for _, stats := range t.stats {
+		if (org == "" || org == stats.Org) && (repo == "" || repo == stats.Repo) {
+			report = append(report, *stats)
+		}
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].FlakeRate != report[j].FlakeRate {
+			return report[i].FlakeRate > report[j].FlakeRate
+		}
+		return report[i].Org+"/"+report[i].Repo+"/"+report[i].Context < report[j].Org+"/"+report[j].Repo+"/"+report[j].Context
+	})
+	return report
+}
+
+// ServeHTTP serves the report as JSON, filtered by the org and repo query parameters
+func (t *Tracker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(t.Report(params.Get("org"), params.Get("repo"))); err != nil {
+		logrus.WithError(err).Error("Failed to write flakes report")
+	}
+}
@@ -0,0 +1,75 @@
+package flakes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func job(repo, context, sha string, state v1alpha1.PipelineState) *v1alpha1.LighthouseJob {
+	return &v1alpha1.LighthouseJob{
+		Spec: v1alpha1.LighthouseJobSpec{
+			Context: context,
+			Refs: &v1alpha1.Refs{
+				Org:   "org",
+				Repo:  repo,
+				Pulls: []v1alpha1.Pull{{Number: 1, SHA: sha}},
+			},
+		},
+		Status: v1alpha1.LighthouseJobStatus{State: state},
+	}
+}
+
+func TestTracker(t *testing.T) {
+	testCases := []struct {
+		name   string
+		job    *v1alpha1.LighthouseJob
+		flaked bool
+	}{
+		{name: "first failure", job: job("a", "unit", "sha1", v1alpha1.FailureState)},
+		{name: "pass after failure on the same sha", job: job("a", "unit", "sha1", v1alpha1.SuccessState), flaked: true},
+		{name: "pass again", job: job("a", "unit", "sha1", v1alpha1.SuccessState)},
+		{name: "failure on a new sha", job: job("a", "unit", "sha2", v1alpha1.ErrorState)},
+		{name: "aborted is ignored", job: job("a", "unit", "sha2", v1alpha1.AbortedState)},
+		{name: "pass after error on the same sha", job: job("a", "unit", "sha2", v1alpha1.SuccessState), flaked: true},
+		{name: "pass on a new sha", job: job("a", "unit", "sha3", v1alpha1.SuccessState)},
+		{name: "other context passing", job: job("a", "e2e", "sha1", v1alpha1.SuccessState)},
+		{name: "other repo failing", job: job("b", "unit", "sha1", v1alpha1.FailureState)},
+	}
+
+	tracker := NewTracker()
+	for _, tc := range testCases {
+		assert.Equal(t, tc.flaked, tracker.Observe(tc.job), tc.name)
+	}
+
+	report := tracker.Report("org", "a")
+	require.Len(t, report, 2)
+	assert.Equal(t, "unit", report[0].Context)
+	assert.Equal(t, 6, report[0].Runs)
+	assert.Equal(t, 2, report[0].Failures)
+	assert.Equal(t, 2, report[0].Flakes)
+	assert.InDelta(t, 1.0/3, report[0].FlakeRate, 0.0001)
+	assert.Equal(t, "sha2", report[0].LastFlakeSHA)
+	assert.Equal(t, "e2e", report[1].Context)
+	assert.Equal(t, 0, report[1].Flakes)
+
+	rr := httptest.NewRecorder()
+	tracker.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/flakes?repo=b", nil))
+	var served []ContextStats
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &served))
+	require.Len(t, served, 1)
+	assert.Equal(t, 1, served[0].Failures)
+}
+
+func TestTrackerForgetsOldCommits(t *testing.T) {
+	tracker := NewTracker()
+	tracker.maxCommits = 1
+	tracker.Observe(job("a", "unit", "sha1", v1alpha1.FailureState))
+	tracker.Observe(job("a", "unit", "sha2", v1alpha1.FailureState))
+	assert.False(t, tracker.Observe(job("a", "unit", "sha1", v1alpha1.SuccessState)))
+}
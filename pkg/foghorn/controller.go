@@ -12,8 +12,16 @@ import (
 	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/failures"
+	"github.com/jenkins-x/lighthouse/pkg/flakes"
+	"github.com/jenkins-x/lighthouse/pkg/interrupts"
+	"github.com/jenkins-x/lighthouse/pkg/jobhistory"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
+	"github.com/jenkins-x/lighthouse/pkg/notifier"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider/reporter"
+	"github.com/jenkins-x/lighthouse/pkg/testreport"
 	"github.com/jenkins-x/lighthouse/pkg/util"
 	"github.com/jenkins-x/lighthouse/pkg/watcher"
 	"github.com/pkg/errors"
@@ -33,6 +41,10 @@ const (
 type LighthouseJobReconciler struct {
 	// ConfigMapWatcher watches for changes in our relevant config maps and updates the reconciler's versions when required.
 	ConfigMapWatcher *watcher.ConfigMapWatcher
+	// History records the completed jobs if set.
+	History jobhistory.Store
+	// Flakes tracks the contexts which fail then pass on the same commit if set.
+	Flakes *flakes.Tracker
 
 	client client.Client
 	logger *logrus.Entry
@@ -40,9 +52,14 @@ type LighthouseJobReconciler struct {
 
 	jobConfig    *config.Agent
 	pluginConfig *plugins.ConfigAgent
+	notifier     *notifier.Notifier
 
 	wg *sync.WaitGroup
 	ns string
+
+	// stopLock guards stopping, which is set once LighthouseJobs are no longer reconciled
+	stopLock sync.RWMutex
+	stopping bool
 }
 
 // NewLighthouseJobReconciler returns a new controller for syncing LighthouseJobs and commit statuses
@@ -76,6 +93,7 @@ func NewLighthouseJobReconcilerWithConfig(client client.Client, scheme *runtime.
 		ns:               ns,
 		jobConfig:        jobConfig,
 		pluginConfig:     pluginConfig,
+		notifier:         notifier.New(jobConfig.Config),
 		ConfigMapWatcher: configMapWatcher,
 		wg:               &sync.WaitGroup{},
 	}, nil
@@ -93,6 +111,12 @@ func (r *LighthouseJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
 func (r *LighthouseJobReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
 
+	if !r.startReconcile() {
+		r.logger.Infof("Not reconciling LighthouseJob %+v as stopping", req)
+		return ctrl.Result{Requeue: true}, nil
+	}
+	defer r.wg.Done()
+
 	r.logger.Infof("Reconcile LighthouseJob %+v", req)
 
 	// get lighthouse job
@@ -105,30 +129,100 @@ func (r *LighthouseJobReconciler) Reconcile(req ctrl.Request) (ctrl.Result, erro
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	activityRecord := job.Status.Activity
+	// log with the fields of the job from now on
+	logger := r.logger.WithFields(jobutil.LighthouseJobFields(&job))
+	ctx = logrusutil.NewContext(ctx, logger)
 
-	if activityRecord == nil {
-		// There's no activity on the job, so there's nothing for us to do.
-		return ctrl.Result{}, nil
+	// the older runs of the context of the commit stop reporting as soon as the new job is seen
+	if err := r.abortSuperseded(ctx, &job); err != nil {
+		logger.WithError(err).Warnf("Failed to abort the LighthouseJobs superseded by LighthouseJob %s", job.Name)
 	}
 
-	// Update the job's status for the activity.
+	// Update the job's status for the activity, if any.
 	jobCopy := job.DeepCopy()
-	r.updateJobStatusForActivity(activityRecord, jobCopy)
-	r.reportStatus(activityRecord, jobCopy)
+	if activityRecord := job.Status.Activity; activityRecord != nil {
+		r.updateJobStatusForActivity(activityRecord, jobCopy)
+		// the new job reports to the context of a superseded one
+		if !isSuperseded(jobCopy) {
+			r.reportStatus(activityRecord, jobCopy)
+		}
+	} else if job.Complete() {
+		// jobs failed by their engine before they ran, e.g. because of their configuration, complete without any
+		// activity so they are recorded as they are seen, recording a job again replaces its record
+		r.recordHistory(ctx, &job)
+	}
+	// published once the state is updated so that a job which just completed is published as finished
+	publishErr := r.publishEvents(ctx, jobCopy)
 
 	if !reflect.DeepEqual(job.Status, jobCopy.Status) {
 		if err := r.client.Status().Update(ctx, jobCopy); err != nil {
-			r.logger.Errorf("Failed to update LighthouseJob status: %s", err)
+			logger.Errorf("Failed to update LighthouseJob status: %s", err)
 			return ctrl.Result{}, err
 		}
+		if jobCopy.Complete() && !job.Complete() {
+			r.onJobCompleted(ctx, jobCopy)
+		}
+	}
+
+	// requeued with a backoff so that the events which failed to be published are published again
+	return ctrl.Result{}, publishErr
+}
+
+// Drain stops reconciling LighthouseJobs and waits for the reconciles in progress, and the external plugin calls they
+// started, to finish so that the commit statuses being reported are not lost. It gives up waiting when the context is
+// done.
+func (r *LighthouseJobReconciler) Drain(ctx context.Context) error {
+	r.stopLock.Lock()
+	r.stopping = true
+	r.stopLock.Unlock()
+
+	if err := interrupts.Drain(ctx, r.wg); err != nil {
+		return errors.Wrap(err, "failed to wait for the status reports in progress")
 	}
+	return nil
+}
+
+// startReconcile registers a reconcile in progress so that draining waits for it, or returns false when stopping
+func (r *LighthouseJobReconciler) startReconcile() bool {
+	r.stopLock.RLock()
+	defer r.stopLock.RUnlock()
+	if r.stopping {
+		return false
+	}
+	r.wg.Add(1)
+	return true
+}
 
-	return ctrl.Result{}, nil
+// onJobCompleted records the history, flakiness and failure class of a job which just completed, posts its outcome to
+// the notification routes and to its issue and retries it if it failed because of its infrastructure
+func (r *LighthouseJobReconciler) onJobCompleted(ctx context.Context, job *lighthousev1alpha1.LighthouseJob) {
+	logger := logrusutil.FromContext(ctx)
+	r.recordHistory(ctx, job)
+	if r.Flakes != nil && r.Flakes.Observe(job) {
+		logger.Infof("Context %s of LighthouseJob %s flaked", job.Spec.Context, job.Name)
+	}
+	observeFailure(job)
+	r.notifyCompleted(ctx, job)
+	r.reportIssue(ctx, job)
+	if _, err := r.retryJob(ctx, job); err != nil {
+		logger.WithError(err).Errorf("Failed to retry LighthouseJob %s", job.Name)
+	}
+}
+
+// recordHistory records a completed job in the history, if any
+func (r *LighthouseJobReconciler) recordHistory(ctx context.Context, job *lighthousev1alpha1.LighthouseJob) {
+	if r.History == nil {
+		return
+	}
+	if err := r.History.Put(jobhistory.RecordForJob(job)); err != nil {
+		logrusutil.FromContext(ctx).WithError(err).Warnf("Failed to record the history of LighthouseJob %s", job.Name)
+	}
 }
 
 func (r *LighthouseJobReconciler) updateJobStatusForActivity(activity *lighthousev1alpha1.ActivityRecord, job *lighthousev1alpha1.LighthouseJob) {
-	if activity.Status != job.Status.State {
+	// a job aborted before it completed remains aborted while its pipeline stops
+	aborting := job.Status.State == lighthousev1alpha1.AbortedState && activity.CompletionTime == nil
+	if activity.Status != job.Status.State && !aborting {
 		job.Status.State = activity.Status
 	}
 	if activity.LastCommitSHA != job.Status.LastCommitSHA {
@@ -137,6 +231,9 @@ func (r *LighthouseJobReconciler) updateJobStatusForActivity(activity *lighthous
 	if activity.CompletionTime != nil && activity.CompletionTime != job.Status.CompletionTime {
 		job.Status.CompletionTime = activity.CompletionTime
 	}
+	if activity.FailureClass != job.Status.FailureClass {
+		job.Status.FailureClass = activity.FailureClass
+	}
 }
 
 func (r *LighthouseJobReconciler) reportStatus(activity *lighthousev1alpha1.ActivityRecord, j *lighthousev1alpha1.LighthouseJob) {
@@ -181,6 +278,10 @@ func (r *LighthouseJobReconciler) reportStatus(activity *lighthousev1alpha1.Acti
 	if statusInfo.scmStatus == scm.StateUnknown {
 		return
 	}
+	modes := j.Spec.Report
+	if job.Reports(modes, job.ReportNone) {
+		return
+	}
 
 	switch scm.ToState(j.Status.LastReportState) {
 	// already completed - avoid reporting again if a promotion happens after a PR has merged and the pipeline updates status
@@ -199,7 +300,7 @@ func (r *LighthouseJobReconciler) reportStatus(activity *lighthousev1alpha1.Acti
 
 	// Trigger external plugins if appropriate
 	if external := util.ExternalPluginsForEvent(r.pluginConfig, util.LighthousePayloadTypeActivity, fmt.Sprintf("%s/%s", owner, repo)); len(external) > 0 {
-		go util.CallExternalPluginsWithActivityRecord(r.logger, external, activity, util.HMACToken(), r.wg)
+		util.CallExternalPluginsWithActivityRecord(r.logger, external, activity, util.HMACToken(), r.wg)
 	}
 
 	pipelineContext := activity.Context
@@ -219,23 +320,64 @@ func (r *LighthouseJobReconciler) reportStatus(activity *lighthousev1alpha1.Acti
 		return
 	}
 
-	_, err = scmClient.CreateStatus(owner, repo, sha, gitRepoStatus)
+	// explicit check or status modes take precedence over the check runs enabled for the repository
+	checkRuns := job.Reports(modes, job.ReportCheck) || len(modes) == 0 && r.jobConfig.Config().CheckRunsEnabled(fmt.Sprintf("%s/%s", owner, repo))
+	results := r.testResults(activity)
+	switch {
+	case checkRuns && scmClient.SupportsCheckRuns():
+		err = reporter.ReportCheckRun(scmClient, j, activity, sha, results)
+	case job.Reports(modes, job.ReportStatus) || job.Reports(modes, job.ReportCheck):
+		_, err = scmClient.CreateStatus(owner, repo, sha, gitRepoStatus)
+	}
 	if err != nil {
 		r.logger.WithFields(fields).WithError(err).Warnf("failed to report git status with target URL '%s'", gitRepoStatus.Target)
 		// TODO: Need something here to prevent infinite attempts to create status from just bombing us. (apb)
 		return
 	}
 
-	err = reporter.Report(scmClient, r.jobConfig.Config().Plank.ReportTemplate, j, []job.PipelineKind{job.PresubmitJob})
-	if err != nil {
-		// For now, we're just going to ignore failures here.
-		r.logger.WithFields(fields).WithError(err).Warnf("failed to update comments on the PR")
+	if job.Reports(modes, job.ReportComment) {
+		err = reporter.Report(scmClient, r.pluginConfig.Config(), r.jobConfig.Config().Plank.ReportTemplate, j, []job.PipelineKind{job.PresubmitJob})
+		if err != nil {
+			// For now, we're just going to ignore failures here.
+			r.logger.WithFields(fields).WithError(err).Warnf("failed to update comments on the PR")
+		}
+	}
+	cfg := r.jobConfig.Config()
+	if j.Spec.Type == job.PresubmitJob && statusInfo.scmStatus == scm.StateFailure && cfg.ReviewCommentsEnabled(owner, repo) && scmClient.SupportsReviewComments() {
+		err = reporter.ReportReviewComments(scmClient, j, sha, results, cfg.ReviewComments.MaxComments)
+		if err != nil {
+			r.logger.WithFields(fields).WithError(err).Warnf("failed to comment on the failures inline on the PR")
+		}
 	}
 	r.logger.WithFields(fields).Info("reported git status")
 	j.Status.Description = statusInfo.description
 	j.Status.LastReportState = statusInfo.scmStatus.String()
 }
 
+// testResults returns the failures of the JUnit and SARIF reports produced by the pipeline, if any
+func (r *LighthouseJobReconciler) testResults(activity *lighthousev1alpha1.ActivityRecord) *testreport.Summary {
+	var results *testreport.Summary
+	if activity.TestResults != "" {
+		report, err := testreport.ParseJUnit([]byte(activity.TestResults))
+		if err != nil {
+			r.logger.WithError(err).Warnf("failed to parse test results for pipeline %s", activity.Name)
+		} else {
+			results = report.Summarize()
+		}
+	}
+	if activity.SARIFResults != "" {
+		report, err := testreport.ParseSARIF([]byte(activity.SARIFResults))
+		if err != nil {
+			r.logger.WithError(err).Warnf("failed to parse SARIF results for pipeline %s", activity.Name)
+		} else if results == nil {
+			results = report.Summarize()
+		} else {
+			results.Add(report.Summarize())
+		}
+	}
+	return results
+}
+
 type reportStatusInfo struct {
 	scmStatus     scm.State
 	description   string
@@ -261,10 +403,17 @@ func toScmStatusDescriptionRunningStages(activity *lighthousev1alpha1.ActivityRe
 	case lighthousev1alpha1.FailureState:
 		info.scmStatus = scm.StateFailure
 		info.description = "Pipeline failed"
+	case lighthousev1alpha1.ErrorState:
+		info.scmStatus = scm.StateError
+		info.description = "Error executing pipeline"
 	default:
 		info.scmStatus = scm.StateUnknown
 		info.description = "Pipeline in unknown state"
 	}
+	if activity.Description != "" {
+		info.description = activity.Description
+	}
+	info.description = failures.Describe(info.description, failures.Classify(activity.Status, activity.FailureClass))
 
 	runningStages := activity.RunningStages()
 	// GitLab does not currently support updating description without changing state, so we need simple descriptions there.
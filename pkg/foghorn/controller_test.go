@@ -1,6 +1,7 @@
 package foghorn
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
 	"path"
@@ -14,6 +15,8 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
 	"github.com/jenkins-x/lighthouse/pkg/config/keeper"
 	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/events"
+	"github.com/jenkins-x/lighthouse/pkg/jobhistory"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/jenkins-x/lighthouse/pkg/util"
 	"github.com/jenkins-x/lighthouse/pkg/watcher"
@@ -157,3 +160,105 @@ func loadLighthouseJob(dir string, baseFn string) (*lighthousev1alpha1.Lighthous
 	}
 	return nil, nil
 }
+
+func TestDrain(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := lighthousev1alpha1.AddToScheme(scheme)
+	assert.NoError(t, err)
+	observedJob, err := loadLighthouseJob(path.Join("test_data", "status-change"), "observed-lhjob.yml")
+	assert.NoError(t, err)
+	c := fake.NewFakeClientWithScheme(scheme, observedJob)
+	reconciler, err := NewLighthouseJobReconcilerWithConfig(c, scheme, "jx", &watcher.ConfigMapWatcher{}, &config.Agent{}, &plugins.ConfigAgent{})
+	assert.NoError(t, err)
+
+	assert.NoError(t, reconciler.Drain(context.Background()))
+
+	result, err := reconciler.Reconcile(ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: "jx",
+			Name:      observedJob.GetName(),
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue, "jobs are no longer reconciled once drained")
+
+	var updatedJob lighthousev1alpha1.LighthouseJob
+	err = c.Get(context.Background(), types.NamespacedName{Namespace: "jx", Name: observedJob.GetName()}, &updatedJob)
+	assert.NoError(t, err)
+	assert.Equal(t, observedJob.Status, updatedJob.Status)
+}
+
+type recordingPublisher struct {
+	published []events.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, event events.Event) error {
+	p.published = append(p.published, event)
+	return nil
+}
+
+func TestReconcilePublishesEvents(t *testing.T) {
+	publisher := &recordingPublisher{}
+	events.SetPublishers("foghorn", publisher)
+	defer events.SetPublishers("")
+
+	scheme := runtime.NewScheme()
+	err := lighthousev1alpha1.AddToScheme(scheme)
+	assert.NoError(t, err)
+	triggered := &lighthousev1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "triggered", Namespace: "jx"},
+		Spec:       lighthousev1alpha1.LighthouseJobSpec{Job: "unit", Refs: &lighthousev1alpha1.Refs{Org: "myorg", Repo: "myrepo"}},
+		Status:     lighthousev1alpha1.LighthouseJobStatus{State: lighthousev1alpha1.TriggeredState},
+	}
+	c := fake.NewFakeClientWithScheme(scheme, triggered)
+	reconciler, err := NewLighthouseJobReconcilerWithConfig(c, scheme, "jx", &watcher.ConfigMapWatcher{}, &config.Agent{}, &plugins.ConfigAgent{})
+	assert.NoError(t, err)
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "jx", Name: "triggered"}}
+
+	_, err = reconciler.Reconcile(request)
+	assert.NoError(t, err)
+	_, err = reconciler.Reconcile(request)
+	assert.NoError(t, err)
+
+	var updatedJob lighthousev1alpha1.LighthouseJob
+	err = c.Get(context.Background(), request.NamespacedName, &updatedJob)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pipeline.triggered"}, updatedJob.Status.PublishedEvents)
+	if assert.Len(t, publisher.published, 1, "the published events are not published again") {
+		assert.Equal(t, "triggered.pipeline.triggered", publisher.published[0].ID)
+		assert.Equal(t, "myorg", publisher.published[0].Org)
+	}
+}
+
+func TestReconcileRecordsJobsFailedWithoutActivity(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := lighthousev1alpha1.AddToScheme(scheme)
+	assert.NoError(t, err)
+	completed := metav1.Now()
+	failed := &lighthousev1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "failed", Namespace: "jx"},
+		Spec:       lighthousev1alpha1.LighthouseJobSpec{Job: "unit", Refs: &lighthousev1alpha1.Refs{Org: "myorg", Repo: "myrepo"}},
+		Status: lighthousev1alpha1.LighthouseJobStatus{
+			State:          lighthousev1alpha1.ErrorState,
+			Description:    "Missing secrets: token",
+			FailureClass:   lighthousev1alpha1.ConfigFailure,
+			StartTime:      completed,
+			CompletionTime: &completed,
+		},
+	}
+	c := fake.NewFakeClientWithScheme(scheme, failed)
+	reconciler, err := NewLighthouseJobReconcilerWithConfig(c, scheme, "jx", &watcher.ConfigMapWatcher{}, &config.Agent{}, &plugins.ConfigAgent{})
+	assert.NoError(t, err)
+	reconciler.History = jobhistory.NewMemoryStore(10)
+
+	_, err = reconciler.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "jx", Name: "failed"}})
+	assert.NoError(t, err)
+
+	records, err := reconciler.History.Query(jobhistory.Query{Org: "myorg", Repo: "myrepo"})
+	assert.NoError(t, err)
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, "failed", records[0].Name)
+		assert.Equal(t, lighthousev1alpha1.ErrorState, records[0].State)
+		assert.Equal(t, "Missing secrets: token", records[0].Description)
+	}
+}
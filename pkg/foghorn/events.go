@@ -0,0 +1,44 @@
+package foghorn
+
+import (
+	"context"
+	"time"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/events"
+)
+
+// maxEventAge is how long after their completion the events of a job are still published, so that enabling the events
+// does not publish the lifecycle of all the past jobs
+const maxEventAge = 24 * time.Hour
+
+// publishEvents publishes the lifecycle events of the job which are due and have not been published yet. They are
+// recorded in the status of the job once published, so that they are published again if publishing them or updating
+// the status fails.
+func (r *LighthouseJobReconciler) publishEvents(ctx context.Context, job *lighthousev1alpha1.LighthouseJob) error {
+	if !events.Enabled() {
+		return nil
+	}
+	if job.Complete() && time.Since(job.Status.CompletionTime.Time) > maxEventAge {
+		return nil
+	}
+	for _, eventType := range events.DueForJob(job) {
+		if published(job, eventType) {
+			continue
+		}
+		if err := events.Publish(ctx, events.ForJob(eventType, job)); err != nil {
+			return err
+		}
+		job.Status.PublishedEvents = append(job.Status.PublishedEvents, string(eventType))
+	}
+	return nil
+}
+
+func published(job *lighthousev1alpha1.LighthouseJob, eventType events.Type) bool {
+	for _, t := range job.Status.PublishedEvents {
+		if t == string(eventType) {
+			return true
+		}
+	}
+	return false
+}
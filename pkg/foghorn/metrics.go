@@ -0,0 +1,31 @@
+package foghorn
+
+import (
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/failures"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var jobFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "lighthouse_job_failures",
+	Help: "A counter of the jobs which did not succeed, by class of failure: infra, config or test.",
+}, []string{"class", "job", "org", "repo"})
+
+func init() {
+	// registered with the registry served by the controller manager
+	metrics.Registry.MustRegister(jobFailures)
+}
+
+// observeFailure counts a completed job which did not succeed under its failure class
+func observeFailure(job *lighthousev1alpha1.LighthouseJob) {
+	class := failures.ForJob(job)
+	if class == "" {
+		return
+	}
+	var org, repo string
+	if job.Spec.Refs != nil {
+		org, repo = job.Spec.Refs.Org, job.Spec.Refs.Repo
+	}
+	jobFailures.WithLabelValues(string(class), job.Spec.Job, org, repo).Inc()
+}
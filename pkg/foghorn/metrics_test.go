@@ -0,0 +1,47 @@
+package foghorn
+
+import (
+	"testing"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObserveFailure(t *testing.T) {
+	job := &lighthousev1alpha1.LighthouseJob{
+		Spec: lighthousev1alpha1.LighthouseJobSpec{
+			Job:  "unit",
+			Refs: &lighthousev1alpha1.Refs{Org: "org", Repo: "repo"},
+		},
+	}
+	count := func(class lighthousev1alpha1.FailureClass) float64 {
+		return testutil.ToFloat64(jobFailures.WithLabelValues(string(class), "unit", "org", "repo"))
+	}
+	infra, test := count(lighthousev1alpha1.InfraFailure), count(lighthousev1alpha1.TestFailure)
+
+	job.Status = lighthousev1alpha1.LighthouseJobStatus{State: lighthousev1alpha1.SuccessState}
+	observeFailure(job)
+	job.Status = lighthousev1alpha1.LighthouseJobStatus{State: lighthousev1alpha1.ErrorState, FailureClass: lighthousev1alpha1.InfraFailure}
+	observeFailure(job)
+	job.Status = lighthousev1alpha1.LighthouseJobStatus{State: lighthousev1alpha1.FailureState}
+	observeFailure(job)
+
+	assert.Equal(t, infra+1, count(lighthousev1alpha1.InfraFailure))
+	assert.Equal(t, test+1, count(lighthousev1alpha1.TestFailure))
+}
+
+func TestStatusDescription(t *testing.T) {
+	activity := &lighthousev1alpha1.ActivityRecord{
+		Status:       lighthousev1alpha1.ErrorState,
+		Description:  "Image pull failed",
+		FailureClass: lighthousev1alpha1.InfraFailure,
+	}
+	info := toScmStatusDescriptionRunningStages(activity, "github")
+	assert.Equal(t, "error", info.scmStatus.String())
+	assert.Equal(t, "Image pull failed (infrastructure error)", info.description)
+
+	activity = &lighthousev1alpha1.ActivityRecord{Status: lighthousev1alpha1.FailureState}
+	info = toScmStatusDescriptionRunningStages(activity, "github")
+	assert.Equal(t, "Pipeline failed (test failure)", info.description)
+}
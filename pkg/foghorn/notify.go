@@ -0,0 +1,120 @@
+package foghorn
+
+import (
+	"context"
+	"sort"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/jobhistory"
+	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider/reporter"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxPreviousRuns bounds the number of previous runs of a job looked up in the job history
+const maxPreviousRuns = 100
+
+// notifyCompleted posts the outcome of the completed job to the configured notification routes
+func (r *LighthouseJobReconciler) notifyCompleted(ctx context.Context, job *lighthousev1alpha1.LighthouseJob) {
+	if !r.notifier.Enabled() {
+		return
+	}
+	r.notifier.NotifyPipeline(job, r.previousState(ctx, job))
+}
+
+// reportIssue opens or updates the issue of a failed job with the issue report mode, or the tracking issue of a
+// postsubmit failing repeatedly on a main branch
+func (r *LighthouseJobReconciler) reportIssue(ctx context.Context, lhj *lighthousev1alpha1.LighthouseJob) {
+	cfg := r.jobConfig.Config()
+	track := lhj.Spec.Type == job.PostsubmitJob && lhj.Spec.Refs != nil && cfg != nil &&
+		cfg.FailureIssuesEnabled(lhj.Spec.Refs.Org, lhj.Spec.Refs.Repo, lhj.Spec.GetBranch())
+	if !track && !job.Reports(lhj.Spec.Report, job.ReportIssue) {
+		return
+	}
+	logger := logrusutil.FromContext(ctx)
+	owner, _ := reporter.IssueRepo(lhj)
+	scmClient, _, _, _, err := util.GetSCMClient(owner, r.jobConfig.Config)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to create SCM client")
+		return
+	}
+	if track {
+		if err := reporter.TrackFailures(scmClient, lhj, r.previousRuns(ctx, lhj), cfg.FailureIssues.Threshold); err != nil {
+			logger.WithError(err).Warnf("Failed to track the failures of LighthouseJob %s in an issue", lhj.Name)
+		}
+		return
+	}
+	if err := reporter.ReportIssue(scmClient, lhj); err != nil {
+		logger.WithError(err).Warnf("Failed to report LighthouseJob %s in an issue", lhj.Name)
+	}
+}
+
+// previousRuns returns the completed runs of the same job on the same repository and branch, most recently started
+// first, from the job history if any or from the LighthouseJobs which were not garbage collected yet
+func (r *LighthouseJobReconciler) previousRuns(ctx context.Context, job *lighthousev1alpha1.LighthouseJob) []*jobhistory.Record {
+	if r.History != nil && job.Spec.Refs != nil {
+		records, err := r.History.Query(jobhistory.Query{
+			Org:    job.Spec.Refs.Org,
+			Repo:   job.Spec.Refs.Repo,
+			Job:    job.Spec.Job,
+			Branch: job.Spec.GetBranch(),
+			Limit:  maxPreviousRuns,
+		})
+		if err != nil {
+			r.logger.WithError(err).Warnf("Failed to query the history of LighthouseJob %s", job.Name)
+		}
+		return records
+	}
+	var records []*jobhistory.Record
+	runs := r.listRuns(ctx, job)
+	for i := range runs {
+		if runs[i].Complete() {
+			records = append(records, jobhistory.RecordForJob(&runs[i]))
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].StartTime.After(records[j].StartTime)
+	})
+	return records
+}
+
+// previousState returns the state of the most recently completed previous run of the same job on the same repository
+// and branch, or an empty state if there is none
+func (r *LighthouseJobReconciler) previousState(ctx context.Context, job *lighthousev1alpha1.LighthouseJob) lighthousev1alpha1.PipelineState {
+	var previous *lighthousev1alpha1.LighthouseJob
+	runs := r.listRuns(ctx, job)
+	for i := range runs {
+		j := &runs[i]
+		if j.Name == job.Name || !j.Complete() || !j.Status.CompletionTime.Before(job.Status.CompletionTime) {
+			continue
+		}
+		if previous == nil || previous.Status.CompletionTime.Before(j.Status.CompletionTime) {
+			previous = j
+		}
+	}
+	if previous == nil {
+		return ""
+	}
+	return previous.Status.State
+}
+
+// listRuns lists the LighthouseJobs of the same job on the same repository and branch
+func (r *LighthouseJobReconciler) listRuns(ctx context.Context, job *lighthousev1alpha1.LighthouseJob) []lighthousev1alpha1.LighthouseJob {
+	selector := client.MatchingLabels{}
+	for _, label := range []string{util.LighthouseJobAnnotation, util.OrgLabel, util.RepoLabel, util.BranchLabel} {
+		if value, ok := job.Labels[label]; ok {
+			selector[label] = value
+		}
+	}
+	if len(selector) == 0 {
+		return nil
+	}
+	var jobs lighthousev1alpha1.LighthouseJobList
+	if err := r.client.List(ctx, &jobs, client.InNamespace(job.Namespace), selector); err != nil {
+		r.logger.WithError(err).Warnf("Failed to list the previous runs of LighthouseJob %s", job.Name)
+		return nil
+	}
+	return jobs.Items
+}
@@ -0,0 +1,45 @@
+package foghorn
+
+import (
+	"context"
+	"strconv"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/failures"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
+	"github.com/pkg/errors"
+)
+
+// retryJob launches a new attempt of a job which failed because of its infrastructure if its retry policy allows it,
+// returning the new job if one was launched
+func (r *LighthouseJobReconciler) retryJob(ctx context.Context, lj *lighthousev1alpha1.LighthouseJob) (*lighthousev1alpha1.LighthouseJob, error) {
+	if !failures.Retryable(lj) || lj.Spec.Retry == nil {
+		return nil, nil
+	}
+	attempt := 0
+	if value, ok := lj.Labels[job.LighthouseJobRetryLabel]; ok {
+		var err error
+		if attempt, err = strconv.Atoi(value); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s label %q", job.LighthouseJobRetryLabel, value)
+		}
+	}
+	if attempt >= lj.Spec.Retry.MaxRetries {
+		return nil, nil
+	}
+
+	retry := jobutil.NewLighthouseJob(*lj.Spec.DeepCopy(), map[string]string{job.LighthouseJobRetryLabel: strconv.Itoa(attempt + 1)}, nil)
+	retry.Namespace = lj.Namespace
+	if err := r.client.Create(ctx, &retry); err != nil {
+		return nil, errors.Wrapf(err, "failed to create retry of LighthouseJob %s", lj.Name)
+	}
+	retry.Status = lighthousev1alpha1.LighthouseJobStatus{
+		State: lighthousev1alpha1.TriggeredState,
+	}
+	if err := r.client.Status().Update(ctx, &retry); err != nil {
+		return nil, errors.Wrapf(err, "failed to set status on LighthouseJob %s", retry.Name)
+	}
+	logrusutil.FromContext(ctx).Infof("Retrying LighthouseJob %s as %s (attempt %d of %d)", lj.Name, retry.Name, attempt+1, lj.Spec.Retry.MaxRetries)
+	return &retry, nil
+}
@@ -0,0 +1,115 @@
+package foghorn
+
+import (
+	"context"
+	"testing"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRetryJob(t *testing.T) {
+	testCases := []struct {
+		name            string
+		state           lighthousev1alpha1.PipelineState
+		class           lighthousev1alpha1.FailureClass
+		retry           *job.RetryPolicy
+		attempt         string
+		expectedAttempt string
+	}{
+		{
+			name:  "no retry policy",
+			state: lighthousev1alpha1.ErrorState,
+		},
+		{
+			name:  "test failures are not retried",
+			state: lighthousev1alpha1.FailureState,
+			retry: &job.RetryPolicy{MaxRetries: 2},
+		},
+		{
+			name:  "configuration errors are not retried",
+			state: lighthousev1alpha1.ErrorState,
+			class: lighthousev1alpha1.ConfigFailure,
+			retry: &job.RetryPolicy{MaxRetries: 2},
+		},
+		{
+			name:            "timeouts caused by the infrastructure are retried",
+			state:           lighthousev1alpha1.AbortedState,
+			class:           lighthousev1alpha1.InfraFailure,
+			retry:           &job.RetryPolicy{MaxRetries: 2},
+			expectedAttempt: "1",
+		},
+		{
+			name:  "other aborted jobs are not retried",
+			state: lighthousev1alpha1.AbortedState,
+			retry: &job.RetryPolicy{MaxRetries: 2},
+		},
+		{
+			name:            "first retry",
+			state:           lighthousev1alpha1.ErrorState,
+			retry:           &job.RetryPolicy{MaxRetries: 2},
+			expectedAttempt: "1",
+		},
+		{
+			name:            "second retry",
+			state:           lighthousev1alpha1.ErrorState,
+			retry:           &job.RetryPolicy{MaxRetries: 2},
+			attempt:         "1",
+			expectedAttempt: "2",
+		},
+		{
+			name:    "retries exhausted",
+			state:   lighthousev1alpha1.ErrorState,
+			retry:   &job.RetryPolicy{MaxRetries: 2},
+			attempt: "2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+			c := fake.NewFakeClientWithScheme(scheme)
+			r := &LighthouseJobReconciler{client: c, logger: logrus.WithField("test", t.Name()), ns: "jx"}
+
+			lj := &lighthousev1alpha1.LighthouseJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "errored", Namespace: "jx", Labels: map[string]string{}},
+				Spec: lighthousev1alpha1.LighthouseJobSpec{
+					Type:    job.PresubmitJob,
+					Job:     "unit",
+					Context: "unit",
+					Refs:    &lighthousev1alpha1.Refs{Org: "org", Repo: "repo", Pulls: []lighthousev1alpha1.Pull{{Number: 1, SHA: "sha"}}},
+					Retry:   tc.retry,
+				},
+				Status: lighthousev1alpha1.LighthouseJobStatus{State: tc.state, FailureClass: tc.class},
+			}
+			if tc.attempt != "" {
+				lj.Labels[job.LighthouseJobRetryLabel] = tc.attempt
+			}
+
+			retry, err := r.retryJob(context.TODO(), lj)
+			require.NoError(t, err)
+
+			var jobs lighthousev1alpha1.LighthouseJobList
+			require.NoError(t, c.List(context.TODO(), &jobs, client.InNamespace("jx")))
+			if tc.expectedAttempt == "" {
+				assert.Nil(t, retry)
+				assert.Empty(t, jobs.Items)
+				return
+			}
+			require.NotNil(t, retry)
+			require.Len(t, jobs.Items, 1)
+			created := jobs.Items[0]
+			assert.Equal(t, tc.expectedAttempt, created.Labels[job.LighthouseJobRetryLabel])
+			assert.Equal(t, lighthousev1alpha1.TriggeredState, created.Status.State)
+			assert.Equal(t, lj.Spec, created.Spec)
+		})
+	}
+}
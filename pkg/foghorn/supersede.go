@@ -0,0 +1,77 @@
+package foghorn
+
+import (
+	"context"
+	"strings"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/logrusutil"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// supersededPrefix prefixes the description of the jobs aborted as superseded by a newer job
+const supersededPrefix = "Superseded by LighthouseJob "
+
+// abortSuperseded aborts the jobs created before the given one which have not completed yet and report to the same
+// context of the same commit: like plank does for the older runs of a context, only the latest run reports its status.
+// The engines stop the pipelines of the aborted jobs. Only older jobs are aborted so that the jobs can be reconciled
+// in any order.
+func (r *LighthouseJobReconciler) abortSuperseded(ctx context.Context, lj *lighthousev1alpha1.LighthouseJob) error {
+	commit := commitOf(&lj.Spec)
+	if commit == "" || lj.Spec.Context == "" || lj.Complete() || lj.Status.State == lighthousev1alpha1.AbortedState {
+		return nil
+	}
+	var jobs lighthousev1alpha1.LighthouseJobList
+	if err := r.client.List(ctx, &jobs, client.InNamespace(lj.Namespace)); err != nil {
+		return errors.Wrapf(err, "failed to list the LighthouseJobs of namespace %s", lj.Namespace)
+	}
+	for i := range jobs.Items {
+		existing := &jobs.Items[i]
+		if !createdBefore(existing, lj) || existing.Complete() || existing.Status.State == lighthousev1alpha1.AbortedState {
+			continue
+		}
+		if existing.Spec.Context != lj.Spec.Context || existing.Spec.Refs == nil ||
+			existing.Spec.Refs.Org != lj.Spec.Refs.Org || existing.Spec.Refs.Repo != lj.Spec.Refs.Repo ||
+			commitOf(&existing.Spec) != commit {
+			continue
+		}
+		existing.Status.State = lighthousev1alpha1.AbortedState
+		existing.Status.Description = supersededPrefix + lj.Name
+		if err := r.client.Status().Update(ctx, existing); err != nil {
+			return errors.Wrapf(err, "failed to abort LighthouseJob %s", existing.Name)
+		}
+		logrusutil.FromContext(ctx).Infof("Aborted LighthouseJob %s superseded by LighthouseJob %s", existing.Name, lj.Name)
+	}
+	return nil
+}
+
+// isSuperseded returns whether the job was aborted as superseded by a newer job reporting to the same context of the
+// same commit, whose status must not be reported any more
+func isSuperseded(lj *lighthousev1alpha1.LighthouseJob) bool {
+	return lj.Status.State == lighthousev1alpha1.AbortedState && strings.HasPrefix(lj.Status.Description, supersededPrefix)
+}
+
+// createdBefore returns whether the job a was created before the job b, the jobs created in the same second being
+// ordered by name
+func createdBefore(a, b *lighthousev1alpha1.LighthouseJob) bool {
+	if a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.Name < b.Name
+	}
+	return a.CreationTimestamp.Before(&b.CreationTimestamp)
+}
+
+// commitOf returns the commit a job reports its status to, the SHAs of all its pull requests for batches
+func commitOf(spec *lighthousev1alpha1.LighthouseJobSpec) string {
+	if spec.Refs == nil {
+		return ""
+	}
+	if len(spec.Refs.Pulls) == 0 {
+		return spec.Refs.BaseSHA
+	}
+	var shas []string
+	for _, pull := range spec.Refs.Pulls {
+		shas = append(shas, pull.SHA)
+	}
+	return strings.Join(shas, ",")
+}
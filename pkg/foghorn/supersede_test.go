@@ -0,0 +1,83 @@
+package foghorn
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	lighthousev1alpha1 "github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/watcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileAbortsSupersededJobs(t *testing.T) {
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	presubmit := func(name, context, sha string, age time.Duration, completed bool) *lighthousev1alpha1.LighthouseJob {
+		lj := &lighthousev1alpha1.LighthouseJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "jx", CreationTimestamp: metav1.NewTime(created.Add(-age))},
+			Spec: lighthousev1alpha1.LighthouseJobSpec{
+				Type:    job.PresubmitJob,
+				Job:     context,
+				Context: context,
+				Refs: &lighthousev1alpha1.Refs{
+					Org:     "org",
+					Repo:    "repo",
+					BaseSHA: "base",
+					Pulls:   []lighthousev1alpha1.Pull{{Number: 1, SHA: sha}},
+				},
+			},
+			Status: lighthousev1alpha1.LighthouseJobStatus{State: lighthousev1alpha1.PendingState},
+		}
+		if completed {
+			now := metav1.Now()
+			lj.Status.CompletionTime = &now
+			lj.Status.State = lighthousev1alpha1.SuccessState
+		}
+		return lj
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, lighthousev1alpha1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme,
+		presubmit("retest", "unit", "abc", 0, false),
+		presubmit("running", "unit", "abc", time.Hour, false),
+		presubmit("lint", "lint", "abc", time.Hour, false),
+		presubmit("older-commit", "unit", "old", time.Hour, false),
+		presubmit("completed", "unit", "abc", time.Hour, true),
+	)
+	reconciler, err := NewLighthouseJobReconcilerWithConfig(c, scheme, "jx", &watcher.ConfigMapWatcher{}, &config.Agent{}, &plugins.ConfigAgent{})
+	require.NoError(t, err)
+
+	get := func(name string) *lighthousev1alpha1.LighthouseJob {
+		lj := &lighthousev1alpha1.LighthouseJob{}
+		require.NoError(t, c.Get(context.TODO(), client.ObjectKey{Namespace: "jx", Name: name}, lj))
+		return lj
+	}
+	reconcile := func(name string) {
+		_, err := reconciler.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "jx", Name: name}})
+		require.NoError(t, err)
+	}
+
+	reconcile("running")
+	assert.Equal(t, lighthousev1alpha1.PendingState, get("retest").Status.State, "older jobs do not abort newer ones")
+
+	reconcile("retest")
+	running := get("running")
+	assert.Equal(t, lighthousev1alpha1.AbortedState, running.Status.State)
+	assert.Equal(t, "Superseded by LighthouseJob retest", running.Status.Description)
+	assert.True(t, isSuperseded(running))
+	assert.Equal(t, lighthousev1alpha1.PendingState, get("lint").Status.State)
+	assert.Equal(t, lighthousev1alpha1.PendingState, get("older-commit").Status.State)
+	assert.Equal(t, lighthousev1alpha1.SuccessState, get("completed").Status.State)
+	assert.Equal(t, lighthousev1alpha1.PendingState, get("retest").Status.State)
+}
@@ -0,0 +1,218 @@
+// Package gc deletes the LighthouseJobs and PipelineRuns of completed jobs once they are no longer needed so that
+// the cluster does not fill up with stale runs.
+package gc
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	clientset "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	tektonclient "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	lighthouseJobKind = "lighthousejob"
+	pipelineRunKind   = "pipelinerun"
+
+	reasonMaxAge      = "max_age"
+	reasonMaxRetained = "max_retained"
+	reasonSucceeded   = "succeeded_ttl"
+	reasonFailed      = "failed_ttl"
+	reasonAborted     = "aborted_ttl"
+	reasonOrphaned    = "orphaned"
+)
+
+var deleted = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "gc_deleted_resources",
+	Help: "Number of resources deleted by the garbage collector.",
+}, []string{
+	// kind of the deleted resource
+	"kind",
+	// reason the resource was deleted
+	"reason",
+})
+
+func init() {
+	prometheus.MustRegister(deleted)
+}
+
+// Options configures how long completed jobs and their pipeline runs are kept
+type Options struct {
+	// MaxJobAge is the age after which LighthouseJobs are deleted along with their pipeline runs
+	MaxJobAge time.Duration
+	// SucceededTTL is how long the pipeline runs of succeeded jobs are kept after they complete, 0 keeps them until
+	// their LighthouseJob is deleted
+	SucceededTTL time.Duration
+	// FailedTTL is how long the pipeline runs of failed jobs are kept after they complete, 0 keeps them until their
+	// LighthouseJob is deleted
+	FailedTTL time.Duration
+	// AbortedTTL is how long the pipeline runs of aborted jobs are kept after they complete, 0 keeps them until
+	// their LighthouseJob is deleted
+	AbortedTTL time.Duration
+	// MaxRetainedPerJob is the maximum number of completed pipeline runs kept for each job of a repository, 0 means
+	// there is no limit
+	MaxRetainedPerJob int
+}
+
+// Collector deletes the LighthouseJobs and PipelineRuns of a namespace which are no longer needed
+type Collector struct {
+	lhClient      clientset.Interface
+	tektonClient  tektonclient.Interface
+	buildClusters map[string]tektonclient.Interface
+	namespace     string
+	options       Options
+	logger        *logrus.Entry
+}
+
+// NewCollector creates a garbage collector for the given namespace
+func NewCollector(lhClient clientset.Interface, tektonClient tektonclient.Interface, namespace string, options Options) *Collector {
+	return &Collector{
+		lhClient:     lhClient,
+		tektonClient: tektonClient,
+		namespace:    namespace,
+		options:      options,
+		logger:       logrus.WithField("component", "gc"),
+	}
+}
+
+// AddBuildCluster registers a remote build cluster whose PipelineRuns are collected too. As they cannot be owned by
+// their LighthouseJob, the PipelineRuns of build clusters are also deleted once their LighthouseJob no longer exists.
+func (c *Collector) AddBuildCluster(alias string, tektonClient tektonclient.Interface) {
+	if c.buildClusters == nil {
+		c.buildClusters = map[string]tektonclient.Interface{}
+	}
+	c.buildClusters[alias] = tektonClient
+}
+
+// Clean deletes the LighthouseJobs and PipelineRuns which have expired at the given time
+func (c *Collector) Clean(now time.Time) error {
+	var errs []error
+	remaining, err := c.cleanLighthouseJobs(now)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.cleanPipelineRuns(now, c.tektonClient, nil); err != nil {
+		errs = append(errs, err)
+	}
+	aliases := make([]string, 0, len(c.buildClusters))
+	for alias := range c.buildClusters {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		if err := c.cleanPipelineRuns(now, c.buildClusters[alias], remaining); err != nil {
+			errs = append(errs, fmt.Errorf("build cluster %s: %v", alias, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// cleanLighthouseJobs deletes the expired LighthouseJobs and returns the names of the others, nil if they could not
+// be listed
+func (c *Collector) cleanLighthouseJobs(now time.Time) (sets.String, error) {
+	jobs := c.lhClient.LighthouseV1alpha1().LighthouseJobs(c.namespace)
+	jobList, err := jobs.List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not list LighthouseJobs in namespace %s: %v", c.namespace, err)
+	}
+	remaining := sets.NewString()
+	var errs []error
+	for _, j := range jobList.Items {
+		// Jobs which never completed expire from their start time.
+		expiresFrom := j.Status.StartTime.Time
+		if j.Status.CompletionTime != nil {
+			expiresFrom = j.Status.CompletionTime.Time
+		}
+		if !expiresFrom.Add(c.options.MaxJobAge).Before(now) {
+			remaining.Insert(j.Name)
+			continue
+		}
+		c.logger.Infof("Deleting LighthouseJob %s", j.Name)
+		if err := jobs.Delete(j.Name, metav1.NewDeleteOptions(0)); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete LighthouseJob %s: %v", j.Name, err))
+			remaining.Insert(j.Name)
+			continue
+		}
+		deleted.WithLabelValues(lighthouseJobKind, reasonMaxAge).Inc()
+	}
+	return remaining, utilerrors.NewAggregate(errs)
+}
+
+// cleanPipelineRuns deletes the expired PipelineRuns of the cluster of the client. When the names of the existing
+// LighthouseJobs are given, the PipelineRuns created before the given time whose LighthouseJob no longer exists are
+// deleted too.
+func (c *Collector) cleanPipelineRuns(now time.Time, tektonClient tektonclient.Interface, jobs sets.String) error {
+	runs := tektonClient.TektonV1beta1().PipelineRuns(c.namespace)
+	runList, err := runs.List(metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=true", job.CreatedByLighthouseLabel)})
+	if err != nil {
+		return fmt.Errorf("could not list PipelineRuns in namespace %s: %v", c.namespace, err)
+	}
+
+	var errs []error
+	remove := func(pr *pipelinev1beta1.PipelineRun, reason string) {
+		c.logger.Infof("Deleting PipelineRun %s as %s expired", pr.Name, reason)
+		propagation := metav1.DeletePropagationBackground
+		if err := runs.Delete(pr.Name, &metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete PipelineRun %s: %v", pr.Name, err))
+			return
+		}
+		deleted.WithLabelValues(pipelineRunKind, reason).Inc()
+	}
+
+	retained := map[string][]*pipelinev1beta1.PipelineRun{}
+	for i := range runList.Items {
+		pr := &runList.Items[i]
+		if jobs != nil && pr.CreationTimestamp.Time.Before(now) && !jobs.Has(pr.Labels[job.LighthouseJobIDLabel]) {
+			remove(pr, reasonOrphaned)
+			continue
+		}
+		if pr.Status.CompletionTime == nil {
+			continue
+		}
+		ttl, reason := c.ttlFor(pr)
+		if ttl > 0 && pr.Status.CompletionTime.Add(ttl).Before(now) {
+			remove(pr, reason)
+			continue
+		}
+		key := fmt.Sprintf("%s/%s/%s", pr.Labels[util.OrgLabel], pr.Labels[util.RepoLabel], pr.Labels[util.LighthouseJobAnnotation])
+		retained[key] = append(retained[key], pr)
+	}
+
+	if c.options.MaxRetainedPerJob > 0 {
+		for _, prs := range retained {
+			if len(prs) <= c.options.MaxRetainedPerJob {
+				continue
+			}
+			// keep the most recently completed runs
+			sort.Slice(prs, func(i, j int) bool {
+				return prs[i].Status.CompletionTime.After(prs[j].Status.CompletionTime.Time)
+			})
+			for _, pr := range prs[c.options.MaxRetainedPerJob:] {
+				remove(pr, reasonMaxRetained)
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// ttlFor returns how long a completed pipeline run is kept depending on its outcome
+func (c *Collector) ttlFor(pr *pipelinev1beta1.PipelineRun) (time.Duration, string) {
+	if pr.IsCancelled() {
+		return c.options.AbortedTTL, reasonAborted
+	}
+	if cond := pr.Status.GetCondition(apis.ConditionSucceeded); cond != nil && cond.IsTrue() {
+		return c.options.SucceededTTL, reasonSucceeded
+	}
+	return c.options.FailedTTL, reasonFailed
+}
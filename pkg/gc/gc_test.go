@@ -0,0 +1,142 @@
+package gc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	tektonfake "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
+)
+
+const ns = "jx"
+
+var now = time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func lighthouseJob(name string, started, completed time.Duration) *v1alpha1.LighthouseJob {
+	j := &v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+		Status: v1alpha1.LighthouseJobStatus{
+			StartTime: metav1.NewTime(now.Add(-started)),
+		},
+	}
+	if completed > 0 {
+		t := metav1.NewTime(now.Add(-completed))
+		j.Status.CompletionTime = &t
+	}
+	return j
+}
+
+func pipelineRun(name, jobName string, status corev1.ConditionStatus, cancelled bool, completed time.Duration) *pipelinev1beta1.PipelineRun {
+	pr := &pipelinev1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+			Labels: map[string]string{
+				job.CreatedByLighthouseLabel: "true",
+				util.OrgLabel:                "org",
+				util.RepoLabel:               "repo",
+				util.LighthouseJobAnnotation: jobName,
+			},
+		},
+	}
+	if cancelled {
+		pr.Spec.Status = pipelinev1beta1.PipelineRunSpecStatusCancelled
+	}
+	pr.Status.Conditions = duckv1beta1.Conditions{{Type: apis.ConditionSucceeded, Status: status}}
+	if completed > 0 {
+		t := metav1.NewTime(now.Add(-completed))
+		pr.Status.CompletionTime = &t
+	}
+	return pr
+}
+
+func TestClean(t *testing.T) {
+	lhObjects := []runtime.Object{
+		lighthouseJob("old-completed", 10*24*time.Hour, 9*24*time.Hour),
+		lighthouseJob("old-never-completed", 8*24*time.Hour, 0),
+		lighthouseJob("recent", 2*time.Hour, time.Hour),
+	}
+	tektonObjects := []runtime.Object{
+		pipelineRun("succeeded-expired", "unit", corev1.ConditionTrue, false, 3*time.Hour),
+		pipelineRun("succeeded-recent", "unit", corev1.ConditionTrue, false, time.Hour),
+		pipelineRun("failed-recent", "unit", corev1.ConditionFalse, false, 3*time.Hour),
+		pipelineRun("failed-expired", "unit", corev1.ConditionFalse, false, 25*time.Hour),
+		pipelineRun("aborted-expired", "unit", corev1.ConditionFalse, true, 2*time.Hour),
+		pipelineRun("running", "unit", corev1.ConditionUnknown, false, 0),
+		pipelineRun("e2e-1", "e2e", corev1.ConditionFalse, false, time.Hour),
+		pipelineRun("e2e-2", "e2e", corev1.ConditionFalse, false, 2*time.Hour),
+		pipelineRun("e2e-3", "e2e", corev1.ConditionFalse, false, 3*time.Hour),
+	}
+	lhClient := fake.NewSimpleClientset(lhObjects...)
+	tektonClient := tektonfake.NewSimpleClientset(tektonObjects...)
+
+	before := testutil.ToFloat64(deleted.WithLabelValues(pipelineRunKind, reasonMaxRetained))
+	c := NewCollector(lhClient, tektonClient, ns, Options{
+		MaxJobAge:         7 * 24 * time.Hour,
+		SucceededTTL:      2 * time.Hour,
+		FailedTTL:         24 * time.Hour,
+		AbortedTTL:        time.Hour,
+		MaxRetainedPerJob: 2,
+	})
+	require.NoError(t, c.Clean(now))
+
+	jobs, err := lhClient.LighthouseV1alpha1().LighthouseJobs(ns).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	var jobNames []string
+	for _, j := range jobs.Items {
+		jobNames = append(jobNames, j.Name)
+	}
+	assert.ElementsMatch(t, []string{"recent"}, jobNames)
+
+	runs, err := tektonClient.TektonV1beta1().PipelineRuns(ns).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	var runNames []string
+	for _, pr := range runs.Items {
+		runNames = append(runNames, pr.Name)
+	}
+	assert.ElementsMatch(t, []string{"succeeded-recent", "failed-recent", "running", "e2e-1", "e2e-2"}, runNames)
+	assert.Equal(t, before+1, testutil.ToFloat64(deleted.WithLabelValues(pipelineRunKind, reasonMaxRetained)))
+}
+
+func TestCleanBuildClusters(t *testing.T) {
+	lhClient := fake.NewSimpleClientset(
+		lighthouseJob("old", 10*24*time.Hour, 9*24*time.Hour),
+		lighthouseJob("recent", 2*time.Hour, time.Hour),
+	)
+	remoteRun := func(name, jobName string, created time.Duration) *pipelinev1beta1.PipelineRun {
+		pr := pipelineRun(name, "unit", corev1.ConditionTrue, false, time.Hour)
+		pr.Labels[job.LighthouseJobIDLabel] = jobName
+		pr.CreationTimestamp = metav1.NewTime(now.Add(-created))
+		return pr
+	}
+	remoteClient := tektonfake.NewSimpleClientset(
+		remoteRun("of-old", "old", 9*24*time.Hour),
+		remoteRun("of-deleted", "deleted", 2*time.Hour),
+		remoteRun("of-recent", "recent", 2*time.Hour),
+		remoteRun("of-new", "new", -time.Minute),
+	)
+
+	c := NewCollector(lhClient, tektonfake.NewSimpleClientset(), ns, Options{MaxJobAge: 7 * 24 * time.Hour})
+	c.AddBuildCluster("remote", remoteClient)
+	require.NoError(t, c.Clean(now))
+
+	runs, err := remoteClient.TektonV1beta1().PipelineRuns(ns).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	var runNames []string
+	for _, pr := range runs.Items {
+		runNames = append(runNames, pr.Name)
+	}
+	assert.ElementsMatch(t, []string{"of-recent", "of-new"}, runNames)
+}
@@ -17,6 +17,7 @@ import (
 
 const (
 	kindBitbucketServer = "bitbucketserver"
+	kindAzure           = "azure"
 )
 
 // Client represents a git client
@@ -141,6 +142,10 @@ func (c *client) Clone(repo string) (*Repo, error) {
 	user, pass := c.getCredentials()
 	if user != "" && pass != "" {
 		host := gitHost(c.base)
+		if c.gitKind == kindAzure {
+			// the repositories of Azure Repos are below the path of their organization
+			host = gitHostAndPath(c.base)
+		}
 		base = fmt.Sprintf("https://%s:%s@%s", user, pass, host)
 	}
 	cache := filepath.Join(c.dir, repo) + ".git"
@@ -161,6 +166,12 @@ func (c *client) Clone(repo string) (*Repo, error) {
 				repoText = fmt.Sprintf("%s/%s", strings.ToLower(repo[0:idx]), repo[idx+1:])
 			}
 		}
+		if c.gitKind == kindAzure {
+			// the repositories of Azure Repos are cloned from project/_git/repo
+			if idx := strings.Index(repo, "/"); idx > 0 {
+				repoText = fmt.Sprintf("%s/_git/%s", repo[0:idx], repo[idx+1:])
+			}
+		}
 		remote := fmt.Sprintf("%s/%s%s", base, prefix, repoText)
 		if b, err := retryCmd(c.logger, "", c.git, "clone", "--mirror", remote, cache); err != nil {
 			return nil, fmt.Errorf("git cache clone error: %v. output: %s", err, string(b))
@@ -201,6 +212,14 @@ func gitHost(s string) string {
 	return strings.TrimPrefix(s, "https://")
 }
 
+func gitHostAndPath(s string) string {
+	u, err := url.Parse(s)
+	if err == nil {
+		return u.Host + strings.TrimSuffix(u.Path, "/")
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(s, "https://"), "/")
+}
+
 // Repo is a clone of a git repository. Launch with Client.Clone, and don't
 // forget to clean it up after.
 type Repo struct {
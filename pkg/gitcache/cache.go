@@ -0,0 +1,455 @@
+// Package gitcache serves read only mirrors of the repositories of the git server over HTTP so that the pipelines
+// clone them from within the cluster rather than over the network. The mirrors are cloned on their first use, fetched
+// when they are used again and pruned once they have not been used for a while. Only the clients presenting the token
+// of the cache are served, and only the repositories it is allowed to mirror.
+package gitcache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/cgi"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+const (
+	// lastUsedFile is the file within a mirror whose modification time records when the mirror was last used
+	lastUsedFile = "lighthouse-last-used"
+
+	resultCloned  = "cloned"
+	resultFetched = "fetched"
+	resultCached  = "cached"
+	resultFailed  = "failed"
+)
+
+var (
+	// reRepoPath matches the path of a repository, e.g. org/repo, subgroups included
+	reRepoPath = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]*(/[A-Za-z0-9_][A-Za-z0-9._-]*)+$`)
+	// reWant matches the revisions a client wants in an upload-pack request, e.g. want <sha> side-band-64k
+	reWant = regexp.MustCompile(`^want ([0-9a-f]{40}|[0-9a-f]{64})\b`)
+
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gitcache_requests",
+		Help: "Number of clones and fetches served by the git cache.",
+	}, []string{
+		// result is whether the mirror was cloned, fetched, served from the cache or failed to be cloned
+		"result",
+	})
+	pruned = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "gitcache_pruned_mirrors",
+		Help: "Number of mirrors pruned by the git cache as they were no longer used.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(requests, pruned)
+}
+
+// Options configures the git cache
+type Options struct {
+	// Dir is the directory the mirrors are kept in
+	Dir string
+	// UpstreamURL is the base URL of the git server the repositories are mirrored from, e.g. https://github.com
+	UpstreamURL string
+	// User is the user to authenticate to the git server with
+	User string
+	// Token is the token to authenticate to the git server with
+	Token string
+	// AuthToken is the token the clients of the cache authenticate with, either as a bearer token or as the password
+	// of basic authentication
+	AuthToken string
+	// Allowed reports whether a repository, e.g. org/repo, may be mirrored, nil allows all of them
+	Allowed func(repo string) bool
+	// MinFetchInterval is the minimum time between two fetches of a mirror when it is cloned from, 0 fetches on every
+	// clone. A mirror missing a revision a client wants is always fetched.
+	MinFetchInterval time.Duration
+	// PruneAfter is how long a mirror is kept once it was last cloned from, 0 keeps it forever
+	PruneAfter time.Duration
+}
+
+// Cache serves the mirrors of the repositories through the git smart HTTP protocol
+type Cache struct {
+	options Options
+	git     string
+	backend http.Handler
+	logger  *logrus.Entry
+	now     func() time.Time
+
+	lock      sync.Mutex
+	repoLocks map[string]*sync.Mutex
+	fetched   map[string]time.Time
+}
+
+// NewCache creates a git cache keeping its mirrors in the directory of the options
+func NewCache(options Options) (*Cache, error) {
+	if options.Dir == "" {
+		return nil, fmt.Errorf("no directory given for the mirrors")
+	}
+	if options.UpstreamURL == "" {
+		return nil, fmt.Errorf("no upstream git server URL given")
+	}
+	if options.AuthToken == "" {
+		return nil, fmt.Errorf("no token given to authenticate the clients with")
+	}
+	git, err := exec.LookPath("git")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find git: %v", err)
+	}
+	dir, err := filepath.Abs(options.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid directory %s: %v", options.Dir, err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %v", dir, err)
+	}
+	options.Dir = dir
+	options.UpstreamURL = strings.TrimSuffix(options.UpstreamURL, "/")
+	return &Cache{
+		options: options,
+		git:     git,
+		backend: &cgi.Handler{
+			Path: git,
+			Args: []string{"http-backend"},
+			Dir:  dir,
+			Env: []string{
+				"GIT_PROJECT_ROOT=" + dir,
+				"GIT_HTTP_EXPORT_ALL=1",
+				// the pipelines fetch the revisions they build by SHA, which the clients of protocol v0 only request
+				// if the server allows it
+				"GIT_CONFIG_COUNT=1",
+				"GIT_CONFIG_KEY_0=uploadpack.allowReachableSHA1InWant",
+				"GIT_CONFIG_VALUE_0=true",
+			},
+		},
+		logger:    logrus.WithField("component", "gitcache"),
+		now:       time.Now,
+		repoLocks: map[string]*sync.Mutex{},
+		fetched:   map[string]time.Time{},
+	}, nil
+}
+
+// ServeHTTP serves the clones and fetches of the mirrors, cloning or fetching them from the git server first
+func (c *Cache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !c.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="lighthouse-gitcache"`)
+		http.Error(w, "401 Unauthorized: invalid or missing token", http.StatusUnauthorized)
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, "/git-receive-pack") || r.URL.Query().Get("service") == "git-receive-pack" {
+		http.Error(w, "the git cache is read only", http.StatusForbidden)
+		return
+	}
+	idx := strings.Index(r.URL.Path, ".git/")
+	if idx < 0 {
+		http.NotFound(w, r)
+		return
+	}
+	repo := strings.TrimPrefix(r.URL.Path[:idx], "/")
+	if !reRepoPath.MatchString(repo) || strings.Contains(repo, "..") || !c.allowed(repo) {
+		http.NotFound(w, r)
+		return
+	}
+	// clones and fetches start by discovering the refs, which is when the mirror is brought up to date
+	if strings.HasSuffix(r.URL.Path, "/info/refs") {
+		if err := c.Sync(repo); err != nil {
+			c.logger.WithError(err).WithField("repo", repo).Error("Failed to mirror repository")
+			if !c.exists(repo) {
+				http.Error(w, fmt.Sprintf("failed to mirror %s", repo), http.StatusBadGateway)
+				return
+			}
+			// serve the stale mirror rather than failing the pipeline
+		}
+	}
+	// the revisions the client wants are only known once it negotiates the pack, e.g. a commit of a pull request pushed
+	// after the mirror was last fetched
+	if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/git-upload-pack") && c.exists(repo) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "failed to read the request", http.StatusBadRequest)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		if revisions := wants(body, r.Header.Get("Content-Encoding") == "gzip"); c.missing(repo, revisions) {
+			if err := c.Sync(repo, revisions...); err != nil {
+				// serve the stale mirror rather than failing the pipeline
+				c.logger.WithError(err).WithField("repo", repo).Error("Failed to mirror repository")
+			}
+		}
+	}
+	if !c.exists(repo) {
+		http.NotFound(w, r)
+		return
+	}
+	c.backend.ServeHTTP(w, r)
+}
+
+// Sync clones the mirror of the repository if it does not exist yet or fetches it if it was not fetched within the
+// minimum fetch interval or misses any of the revisions, then records that the mirror was used
+func (c *Cache) Sync(repo string, revisions ...string) error {
+	lock := c.repoLock(repo)
+	lock.Lock()
+	defer lock.Unlock()
+
+	dir := c.mirrorDir(repo)
+	if !c.exists(repo) {
+		if err := c.clone(repo); err != nil {
+			requests.WithLabelValues(resultFailed).Inc()
+			return err
+		}
+		requests.WithLabelValues(resultCloned).Inc()
+	} else if c.stale(repo) || c.missing(repo, revisions) {
+		if err := c.fetch(repo); err != nil {
+			requests.WithLabelValues(resultFailed).Inc()
+			return err
+		}
+		requests.WithLabelValues(resultFetched).Inc()
+	} else {
+		requests.WithLabelValues(resultCached).Inc()
+	}
+	return touch(filepath.Join(dir, lastUsedFile), c.now())
+}
+
+// Maintain fetches the mirrors and prunes the ones which were not used within the prune period or which are no longer
+// allowed
+func (c *Cache) Maintain() error {
+	repos, err := c.mirrors()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, repo := range repos {
+		if err := c.maintain(repo); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func (c *Cache) maintain(repo string) error {
+	lock := c.repoLock(repo)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if !c.allowed(repo) {
+		c.logger.WithField("repo", repo).Info("Pruning mirror no longer allowed")
+		return c.prune(repo)
+	}
+	if c.options.PruneAfter > 0 {
+		info, err := os.Stat(filepath.Join(c.mirrorDir(repo), lastUsedFile))
+		if err == nil && c.now().Sub(info.ModTime()) > c.options.PruneAfter {
+			c.logger.WithField("repo", repo).Infof("Pruning mirror unused since %s", info.ModTime().Format(time.RFC3339))
+			return c.prune(repo)
+		}
+	}
+	return c.fetch(repo)
+}
+
+func (c *Cache) prune(repo string) error {
+	if err := os.RemoveAll(c.mirrorDir(repo)); err != nil {
+		return fmt.Errorf("failed to prune mirror of %s: %v", repo, err)
+	}
+	c.lock.Lock()
+	delete(c.fetched, repo)
+	c.lock.Unlock()
+	pruned.Inc()
+	return nil
+}
+
+// authorized returns whether the request carries the token of the cache, either as a bearer token or as the password
+// of basic authentication so that git clients can be given it as credentials
+func (c *Cache) authorized(r *http.Request) bool {
+	token := ""
+	if _, password, ok := r.BasicAuth(); ok {
+		token = password
+	} else if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		token = strings.TrimPrefix(header, "Bearer ")
+	}
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(c.options.AuthToken)) == 1
+}
+
+func (c *Cache) allowed(repo string) bool {
+	return c.options.Allowed == nil || c.options.Allowed(repo)
+}
+
+// mirrors returns the repositories mirrored in the directory of the cache
+func (c *Cache) mirrors() ([]string, error) {
+	var repos []string
+	err := filepath.Walk(c.options.Dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() || path == c.options.Dir || !strings.HasSuffix(path, ".git") {
+			return nil
+		}
+		rel, err := filepath.Rel(c.options.Dir, strings.TrimSuffix(path, ".git"))
+		if err != nil {
+			return err
+		}
+		repos = append(repos, filepath.ToSlash(rel))
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the mirrors in %s: %v", c.options.Dir, err)
+	}
+	return repos, nil
+}
+
+func (c *Cache) clone(repo string) error {
+	dir := c.mirrorDir(repo)
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %v", repo, err)
+	}
+	// clone into a temporary directory so that a failed clone is never served
+	tmp, err := ioutil.TempDir(filepath.Dir(dir), ".clone-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory for %s: %v", repo, err)
+	}
+	defer os.RemoveAll(tmp)
+	c.logger.WithField("repo", repo).Info("Cloning mirror")
+	if err := c.run("", "clone", "--mirror", c.upstreamURL(repo), tmp); err != nil {
+		return fmt.Errorf("failed to clone %s: %v", repo, err)
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return fmt.Errorf("failed to move the mirror of %s: %v", repo, err)
+	}
+	c.fetchedAt(repo)
+	return nil
+}
+
+func (c *Cache) fetch(repo string) error {
+	c.logger.WithField("repo", repo).Debug("Fetching mirror")
+	// the remote URL is given explicitly so that the credentials are never written to the mirror
+	if err := c.run(c.mirrorDir(repo), "fetch", "--prune", c.upstreamURL(repo), "+refs/*:refs/*"); err != nil {
+		return fmt.Errorf("failed to fetch %s: %v", repo, err)
+	}
+	c.fetchedAt(repo)
+	return nil
+}
+
+func (c *Cache) run(dir string, args ...string) error {
+	cmd := exec.Command(c.git, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if c.options.Token != "" {
+		// the credentials are passed through the environment rather than the command line, which any process can read
+		credentials := base64.StdEncoding.EncodeToString([]byte(c.options.User + ":" + c.options.Token))
+		cmd.Env = append(cmd.Env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+credentials,
+		)
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// missing returns whether the mirror misses any of the revisions
+func (c *Cache) missing(repo string, revisions []string) bool {
+	for _, revision := range revisions {
+		if err := c.run(c.mirrorDir(repo), "cat-file", "-e", revision); err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Cache) stale(repo string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	last, ok := c.fetched[repo]
+	return !ok || c.now().Sub(last) >= c.options.MinFetchInterval
+}
+
+func (c *Cache) fetchedAt(repo string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.fetched[repo] = c.now()
+}
+
+func (c *Cache) repoLock(repo string) *sync.Mutex {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	lock, ok := c.repoLocks[repo]
+	if !ok {
+		lock = &sync.Mutex{}
+		c.repoLocks[repo] = lock
+	}
+	return lock
+}
+
+func (c *Cache) exists(repo string) bool {
+	info, err := os.Stat(c.mirrorDir(repo))
+	return err == nil && info.IsDir()
+}
+
+func (c *Cache) mirrorDir(repo string) string {
+	return filepath.Join(c.options.Dir, filepath.FromSlash(repo)+".git")
+}
+
+func (c *Cache) upstreamURL(repo string) string {
+	return fmt.Sprintf("%s/%s.git", c.options.UpstreamURL, repo)
+}
+
+// wants returns the revisions wanted in the pkt-lines of the body of an upload-pack request
+func wants(body []byte, gzipped bool) []string {
+	if gzipped {
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil
+		}
+		if body, err = ioutil.ReadAll(reader); err != nil {
+			return nil
+		}
+	}
+	var answer []string
+	for len(body) >= 4 {
+		size, err := strconv.ParseUint(string(body[:4]), 16, 16)
+		if err != nil {
+			return answer
+		}
+		// flush, delimiter and response end packets have no payload
+		if size < 4 {
+			body = body[4:]
+			continue
+		}
+		if int(size) > len(body) {
+			return answer
+		}
+		if m := reWant.FindSubmatch(body[4:size]); m != nil {
+			answer = append(answer, string(m[1]))
+		}
+		body = body[size:]
+	}
+	return answer
+}
+
+func touch(path string, t time.Time) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+			return err
+		}
+	}
+	return os.Chtimes(path, t, t)
+}
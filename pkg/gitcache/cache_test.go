@@ -0,0 +1,186 @@
+package gitcache
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func git(t *testing.T, dir string, args ...string) string {
+	args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %s: %s", strings.Join(args, " "), string(out))
+	return strings.TrimSpace(string(out))
+}
+
+// upstream creates the bare repository org/repo in a new upstream directory and returns the directory and a working
+// copy pushing to it
+func upstream(t *testing.T, root string) (string, string) {
+	upstreamDir := filepath.Join(root, "upstream")
+	bare := filepath.Join(upstreamDir, "org", "repo.git")
+	require.NoError(t, os.MkdirAll(bare, 0755))
+	git(t, bare, "init", "--bare")
+	work := filepath.Join(root, "work")
+	git(t, root, "clone", bare, work)
+	commit(t, work, "README.md", "hello")
+	return upstreamDir, work
+}
+
+func commit(t *testing.T, work, file, content string) string {
+	require.NoError(t, ioutil.WriteFile(filepath.Join(work, file), []byte(content), 0644))
+	git(t, work, "add", file)
+	git(t, work, "commit", "-m", "update "+file)
+	git(t, work, "push", "origin", "HEAD:refs/heads/master")
+	return git(t, work, "rev-parse", "HEAD")
+}
+
+func TestCacheServesMirrors(t *testing.T) {
+	root, err := ioutil.TempDir("", "gitcache")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+	upstreamDir, work := upstream(t, root)
+
+	cache, err := NewCache(Options{
+		Dir:         filepath.Join(root, "mirrors"),
+		UpstreamURL: "file://" + upstreamDir,
+		AuthToken:   "secret",
+		Allowed: func(repo string) bool {
+			return strings.HasPrefix(repo, "org/")
+		},
+		MinFetchInterval: time.Hour,
+	})
+	require.NoError(t, err)
+	server := httptest.NewServer(cache)
+	defer server.Close()
+	get := func(path, token string) int {
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.SetBasicAuth("pipeline", token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	cloneURL := strings.Replace(server.URL, "http://", "http://pipeline:secret@", 1) + "/org/repo.git"
+
+	// the clients must authenticate
+	assert.Equal(t, http.StatusUnauthorized, get("/org/repo.git/info/refs?service=git-upload-pack", ""))
+	assert.Equal(t, http.StatusUnauthorized, get("/org/repo.git/info/refs?service=git-upload-pack", "wrong"))
+	assert.NoDirExists(t, filepath.Join(root, "mirrors", "org", "repo.git"))
+
+	// the mirror is cloned on the first clone
+	git(t, root, "clone", cloneURL, "clone1")
+	content, err := ioutil.ReadFile(filepath.Join(root, "clone1", "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+	assert.DirExists(t, filepath.Join(root, "mirrors", "org", "repo.git"))
+	assert.FileExists(t, filepath.Join(root, "mirrors", "org", "repo.git", lastUsedFile))
+
+	// and fetched on the next ones once the minimum fetch interval elapsed
+	sha := commit(t, work, "README.md", "updated")
+	cache.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	git(t, root, "clone", cloneURL, "clone2")
+	assert.Equal(t, sha, git(t, filepath.Join(root, "clone2"), "rev-parse", "HEAD"))
+
+	// or as soon as a client wants a revision the mirror misses, e.g. the head of a pull request
+	require.NoError(t, ioutil.WriteFile(filepath.Join(work, "README.md"), []byte("pull request"), 0644))
+	git(t, work, "commit", "-am", "pull request")
+	git(t, work, "push", "origin", "HEAD:refs/pull/1/head")
+	prSHA := git(t, work, "rev-parse", "HEAD")
+	git(t, filepath.Join(root, "clone2"), "fetch", "origin", prSHA)
+	assert.Equal(t, "pull request", git(t, filepath.Join(root, "clone2"), "show", prSHA+":README.md"))
+
+	// the cache is read only
+	assert.Equal(t, http.StatusForbidden, get("/org/repo.git/info/refs?service=git-receive-pack", "secret"))
+
+	for _, path := range []string{"/org/repo", "/../repo.git/info/refs", "/repo.git/info/refs"} {
+		assert.Equal(t, http.StatusNotFound, get(path, "secret"), path)
+	}
+
+	// the repositories which are not allowed are never mirrored
+	assert.Equal(t, http.StatusNotFound, get("/other/repo.git/info/refs?service=git-upload-pack", "secret"))
+	assert.NoDirExists(t, filepath.Join(root, "mirrors", "other", "repo.git"))
+
+	// unknown repositories fail to be mirrored
+	assert.Equal(t, http.StatusBadGateway, get("/org/missing.git/info/refs?service=git-upload-pack", "secret"))
+	assert.NoDirExists(t, filepath.Join(root, "mirrors", "org", "missing.git"))
+}
+
+func TestCacheMaintain(t *testing.T) {
+	tests := []struct {
+		name       string
+		pruneAfter time.Duration
+		lastUsed   time.Duration
+		disallowed bool
+		pruned     bool
+	}{
+		{
+			name:     "no pruning",
+			lastUsed: 30 * 24 * time.Hour,
+		},
+		{
+			name:       "used recently",
+			pruneAfter: 24 * time.Hour,
+			lastUsed:   time.Hour,
+		},
+		{
+			name:       "unused",
+			pruneAfter: 24 * time.Hour,
+			lastUsed:   48 * time.Hour,
+			pruned:     true,
+		},
+		{
+			name:       "no longer allowed",
+			lastUsed:   time.Hour,
+			disallowed: true,
+			pruned:     true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			root, err := ioutil.TempDir("", "gitcache")
+			require.NoError(t, err)
+			defer os.RemoveAll(root)
+			upstreamDir, work := upstream(t, root)
+
+			now := time.Now()
+			allowed := true
+			cache, err := NewCache(Options{
+				Dir:              filepath.Join(root, "mirrors"),
+				UpstreamURL:      "file://" + upstreamDir,
+				AuthToken:        "secret",
+				Allowed:          func(string) bool { return allowed },
+				MinFetchInterval: time.Hour,
+				PruneAfter:       tc.pruneAfter,
+			})
+			require.NoError(t, err)
+			cache.now = func() time.Time { return now.Add(-tc.lastUsed) }
+			require.NoError(t, cache.Sync("org/repo"))
+			cache.now = func() time.Time { return now }
+			allowed = !tc.disallowed
+
+			sha := commit(t, work, "README.md", "updated")
+			require.NoError(t, cache.Maintain())
+
+			mirror := filepath.Join(root, "mirrors", "org", "repo.git")
+			if tc.pruned {
+				assert.NoDirExists(t, mirror)
+				return
+			}
+			assert.Equal(t, sha, git(t, mirror, "rev-parse", "refs/heads/master"))
+		})
+	}
+}
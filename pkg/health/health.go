@@ -0,0 +1,143 @@
+// Package health serves the liveness and readiness endpoints of the Lighthouse components. The endpoints run checks of
+// the dependencies of a component and report why any of them failed, so that a misconfigured deployment fails its
+// probes rather than silently dropping work.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// LivenessPath is the path of the liveness endpoint
+	LivenessPath = "/healthz"
+	// ReadinessPath is the path of the readiness endpoint
+	ReadinessPath = "/readyz"
+
+	// StatusOK is the status of the response when all the checks pass
+	StatusOK = "ok"
+	// StatusFailed is the status of the response when a check fails
+	StatusFailed = "failed"
+
+	// defaultTimeout is how long the checks are waited for by default
+	defaultTimeout = 5 * time.Second
+)
+
+// Check verifies a dependency, returning why it is unavailable
+type Check struct {
+	// Name identifies the dependency, e.g. kubernetes
+	Name string
+	// Run returns an error describing why the dependency is unavailable
+	Run func(ctx context.Context) error
+}
+
+// Result is the result of a check
+type Result struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// Response is the body served by the endpoints
+type Response struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks,omitempty"`
+}
+
+// Handler serves the results of its checks as JSON, with 200 if they all pass or 503 otherwise
+type Handler struct {
+	Checks []Check
+	// Timeout is how long the checks are waited for, after which they fail. Defaults to 5 seconds.
+	Timeout time.Duration
+}
+
+// ServeHTTP runs the checks and serves their results
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	response := Run(ctx, h.Checks)
+	w.Header().Set("Content-Type", "application/json")
+	if response.Status != StatusOK {
+		logrus.WithField("checks", response.Checks).Warnf("%s failed", r.URL.Path)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logrus.WithError(err).Debug("failed to write the health response")
+	}
+}
+
+// Run runs the checks concurrently, failing those which have not finished when the context is done
+func Run(ctx context.Context, checks []Check) Response {
+	results := make([]Result, len(checks))
+	wg := sync.WaitGroup{}
+	for i := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = run(ctx, checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	response := Response{Status: StatusOK, Checks: results}
+	for _, r := range results {
+		if !r.OK {
+			response.Status = StatusFailed
+		}
+	}
+	return response
+}
+
+// run runs a check, giving up when the context is done as not all clients support contexts
+func run(ctx context.Context, check Check) Result {
+	errs := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				errs <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		errs <- check.Run(ctx)
+	}()
+	var err error
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out: %v", ctx.Err())
+	}
+	if err != nil {
+		return Result{Name: check.Name, Error: err.Error()}
+	}
+	return Result{Name: check.Name, OK: true}
+}
+
+// Cached returns a check which remembers the result of the check for the TTL, e.g. so that frequent probes do not use
+// up the rate limit of an API
+func Cached(check Check, ttl time.Duration) Check {
+	var lock sync.Mutex
+	var err error
+	var expires time.Time
+	return Check{
+		Name: check.Name,
+		Run: func(ctx context.Context) error {
+			lock.Lock()
+			defer lock.Unlock()
+			if now := time.Now(); now.After(expires) {
+				err = check.Run(ctx)
+				expires = now.Add(ttl)
+			}
+			return err
+		},
+	}
+}
@@ -0,0 +1,82 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	ok := Check{Name: "ok", Run: func(context.Context) error { return nil }}
+	failing := Check{Name: "failing", Run: func(context.Context) error { return errors.New("unreachable") }}
+	slow := Check{Name: "slow", Run: func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}}
+	panicking := Check{Name: "panicking", Run: func(context.Context) error { panic("boom") }}
+
+	testCases := []struct {
+		name     string
+		checks   []Check
+		code     int
+		expected Response
+	}{
+		{
+			name:     "no checks",
+			code:     http.StatusOK,
+			expected: Response{Status: StatusOK},
+		},
+		{
+			name:   "passing checks",
+			checks: []Check{ok},
+			code:   http.StatusOK,
+			expected: Response{Status: StatusOK, Checks: []Result{
+				{Name: "ok", OK: true},
+			}},
+		},
+		{
+			name:   "failing checks",
+			checks: []Check{ok, failing, slow, panicking},
+			code:   http.StatusServiceUnavailable,
+			expected: Response{Status: StatusFailed, Checks: []Result{
+				{Name: "ok", OK: true},
+				{Name: "failing", Error: "unreachable"},
+				{Name: "slow", Error: "timed out: context deadline exceeded"},
+				{Name: "panicking", Error: "panic: boom"},
+			}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := &Handler{Checks: tc.checks, Timeout: 50 * time.Millisecond}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, ReadinessPath, nil))
+
+			assert.Equal(t, tc.code, w.Code)
+			var response Response
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			assert.Equal(t, tc.expected, response)
+		})
+	}
+}
+
+func TestCached(t *testing.T) {
+	runs := 0
+	check := Cached(Check{Name: "counted", Run: func(context.Context) error {
+		runs++
+		return errors.New("failed")
+	}}, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		assert.EqualError(t, check.Run(context.Background()), "failed")
+	}
+	assert.Equal(t, "counted", check.Name)
+	assert.Equal(t, 1, runs)
+}
@@ -145,6 +145,34 @@ func Run(work func(ctx context.Context)) {
 	go wait(cancel)
 }
 
+// OnInterrupt ensures that work is done when an interrupt is fired and
+// that we wait for the work to be finished before we consider the process
+// cleaned up. This function is not blocking.
+func OnInterrupt(work func()) {
+	single.wg.Add(1)
+	go wait(func() {
+		defer single.wg.Done()
+		work()
+	})
+}
+
+// Drain waits for the work tracked by the wait group to finish, or for the
+// context to be done in which case the error of the context is returned.
+// Callers must stop adding work to the wait group before draining it.
+func Drain(ctx context.Context, wg *sync.WaitGroup) error {
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // ListenAndServe runs the HTTP server and handles shutting it down
 // gracefully on interrupts. This function is not blocking. Callers
 // are expected to exit only after WaitForGracefulShutdown returns to
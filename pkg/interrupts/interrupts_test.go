@@ -257,3 +257,22 @@ func generateCerts(url string) (string, string, error) {
 	}
 	return certOut.Name(), keyOut.Name(), nil
 }
+
+func TestDrain(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	if err := Drain(context.Background(), wg); err != nil {
+		t.Errorf("expected no error draining no work, got %v", err)
+	}
+
+	wg.Add(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := Drain(ctx, wg); err != context.DeadlineExceeded {
+		t.Errorf("expected the deadline to be exceeded draining unfinished work, got %v", err)
+	}
+
+	go wg.Done()
+	if err := Drain(context.Background(), wg); err != nil {
+		t.Errorf("expected no error draining finished work, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+// Package jobadmission implements the admission webhooks which default and validate LighthouseJobs, so that jobs
+// created by hand cannot break the controllers.
+package jobadmission
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// DefaultPath is the path the defaulting webhook is served at
+	DefaultPath = "/mutate-lighthouse-jenkins-io-v1alpha1-lighthousejob"
+	// ValidatePath is the path the validating webhook is served at
+	ValidatePath = "/validate-lighthouse-jenkins-io-v1alpha1-lighthousejob"
+)
+
+// Defaulter is the admission handler which fills in the fields of the spec of new jobs which the controllers expect
+// to be set
+type Defaulter struct {
+	namespace string
+	decoder   *admission.Decoder
+}
+
+// NewDefaulter creates a defaulter, jobs without a namespace default to the given one
+func NewDefaulter(namespace string) *Defaulter {
+	return &Defaulter{namespace: namespace}
+}
+
+// InjectDecoder injects the decoder of admission requests
+func (d *Defaulter) InjectDecoder(decoder *admission.Decoder) error {
+	d.decoder = decoder
+	return nil
+}
+
+// Handle defaults the job of the request
+func (d *Defaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	lj := &v1alpha1.LighthouseJob{}
+	if err := d.decoder.Decode(req, lj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	SetDefaults(&lj.Spec, d.namespace)
+	data, err := json.Marshal(lj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, data)
+}
+
+// SetDefaults defaults the status context of the spec to the name of its job and its namespace to the given one
+func SetDefaults(spec *v1alpha1.LighthouseJobSpec, namespace string) {
+	if spec.Context == "" {
+		spec.Context = spec.Job
+	}
+	if spec.Namespace == "" {
+		spec.Namespace = namespace
+	}
+}
+
+// Validator is the admission handler which rejects jobs the controllers cannot run. It has no side effects: the older
+// jobs superseded by a new job are aborted by the controller reconciling it.
+type Validator struct {
+	decoder *admission.Decoder
+}
+
+// NewValidator creates a validator
+func NewValidator() *Validator {
+	return &Validator{}
+}
+
+// InjectDecoder injects the decoder of admission requests
+func (v *Validator) InjectDecoder(decoder *admission.Decoder) error {
+	v.decoder = decoder
+	return nil
+}
+
+// Handle validates the job of the request
+func (v *Validator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	lj := &v1alpha1.LighthouseJob{}
+	if err := v.decoder.Decode(req, lj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := lj.Spec.Validate(); err != nil {
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
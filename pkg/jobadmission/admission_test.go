@@ -0,0 +1,131 @@
+package jobadmission
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func presubmit(name, context, sha string, completed bool) *v1alpha1.LighthouseJob {
+	lj := &v1alpha1.LighthouseJob{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "LighthouseJob"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "jx"},
+		Spec: v1alpha1.LighthouseJobSpec{
+			Type:    job.PresubmitJob,
+			Job:     context,
+			Context: context,
+			Refs: &v1alpha1.Refs{
+				Org:     "org",
+				Repo:    "repo",
+				BaseSHA: "base",
+				Pulls:   []v1alpha1.Pull{{Number: 1, SHA: sha}},
+			},
+		},
+	}
+	if completed {
+		now := metav1.Now()
+		lj.Status.CompletionTime = &now
+	}
+	return lj
+}
+
+func request(t *testing.T, operation admissionv1beta1.Operation, lj *v1alpha1.LighthouseJob) admission.Request {
+	data, err := json.Marshal(lj)
+	require.NoError(t, err)
+	return admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+		Operation: operation,
+		Namespace: lj.Namespace,
+		Name:      lj.Name,
+		Object:    runtime.RawExtension{Raw: data},
+	}}
+}
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	return scheme
+}
+
+func TestValidator(t *testing.T) {
+	scheme := newScheme(t)
+	decoder, err := admission.NewDecoder(scheme)
+	require.NoError(t, err)
+
+	noRefs := presubmit("no-refs", "unit", "abc", false)
+	noRefs.Spec.Refs = nil
+	unknownType := presubmit("unknown-type", "unit", "abc", false)
+	unknownType.Spec.Type = "nightly"
+	negativeConcurrency := presubmit("negative-concurrency", "unit", "abc", false)
+	negativeConcurrency.Spec.MaxConcurrency = -1
+
+	tests := []struct {
+		name      string
+		operation admissionv1beta1.Operation
+		job       *v1alpha1.LighthouseJob
+		allowed   bool
+	}{
+		{
+			name:      "valid",
+			operation: admissionv1beta1.Create,
+			job:       presubmit("valid", "unit", "def", false),
+			allowed:   true,
+		},
+		{
+			name:      "updating a running job",
+			operation: admissionv1beta1.Update,
+			job:       presubmit("running", "unit", "running", false),
+			allowed:   true,
+		},
+		{
+			name:      "missing refs",
+			operation: admissionv1beta1.Create,
+			job:       noRefs,
+		},
+		{
+			name:      "unknown type",
+			operation: admissionv1beta1.Create,
+			job:       unknownType,
+		},
+		{
+			name:      "negative max concurrency",
+			operation: admissionv1beta1.Create,
+			job:       negativeConcurrency,
+		},
+	}
+
+	validator := NewValidator()
+	require.NoError(t, validator.InjectDecoder(decoder))
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := validator.Handle(context.TODO(), request(t, tc.operation, tc.job))
+			assert.Equal(t, tc.allowed, resp.Allowed, "%v", resp.Result)
+		})
+	}
+}
+
+func TestDefaulter(t *testing.T) {
+	decoder, err := admission.NewDecoder(newScheme(t))
+	require.NoError(t, err)
+	defaulter := NewDefaulter("jx")
+	require.NoError(t, defaulter.InjectDecoder(decoder))
+
+	lj := presubmit("unit", "", "abc", false)
+	lj.Spec.Job = "unit"
+	resp := defaulter.Handle(context.TODO(), request(t, admissionv1beta1.Create, lj))
+	require.True(t, resp.Allowed)
+
+	paths := map[string]interface{}{}
+	for _, p := range resp.Patches {
+		paths[p.Path] = p.Value
+	}
+	assert.Equal(t, map[string]interface{}{"/spec/context": "unit", "/spec/namespace": "jx"}, paths)
+}
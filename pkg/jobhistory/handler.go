@@ -0,0 +1,57 @@
+package jobhistory
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultLimit is the number of records returned when the query does not specify a limit
+const defaultLimit = 100
+
+// Handler serves the records of a store as JSON, filtered by the org, repo, job, branch, state and limit query
+// parameters
+type Handler struct {
+	Store Store
+}
+
+// ServeHTTP serves the records selected by the query parameters of the request
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET requests are supported", http.StatusMethodNotAllowed)
+		return
+	}
+	params := r.URL.Query()
+	query := Query{
+		Org:    params.Get("org"),
+		Repo:   params.Get("repo"),
+		Job:    params.Get("job"),
+		Branch: params.Get("branch"),
+		State:  v1alpha1.PipelineState(params.Get("state")),
+		Limit:  defaultLimit,
+	}
+	if limit := params.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 0 {
+			http.Error(w, "limit must be a non-negative number", http.StatusBadRequest)
+			return
+		}
+		query.Limit = l
+	}
+	records, err := h.Store.Query(query)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to query job history")
+		http.Error(w, "failed to query job history", http.StatusInternalServerError)
+		return
+	}
+	if records == nil {
+		records = []*Record{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(records); err != nil {
+		logrus.WithError(err).Error("Failed to write job history response")
+	}
+}
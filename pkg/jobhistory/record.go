@@ -0,0 +1,90 @@
+// Package jobhistory keeps a record of every completed pipeline execution in a pluggable store and serves the
+// history over HTTP so that it can be queried by repository, job and branch.
+package jobhistory
+
+import (
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+)
+
+// Record describes one execution of a job
+type Record struct {
+	// Name is the name of the LighthouseJob of the execution
+	Name string `json:"name"`
+	// Job is the name of the job
+	Job string `json:"job"`
+	// Type is the type of the job
+	Type job.PipelineKind `json:"type"`
+	// Org is the organisation of the repository the job ran against
+	Org string `json:"org,omitempty"`
+	// Repo is the repository the job ran against
+	Repo string `json:"repo,omitempty"`
+	// Branch is the branch the job ran against
+	Branch string `json:"branch,omitempty"`
+	// Context is the context of the commit status of the job
+	Context string `json:"context,omitempty"`
+	// Refs are the refs the job ran against
+	Refs *v1alpha1.Refs `json:"refs,omitempty"`
+	// State is the final state of the job
+	State v1alpha1.PipelineState `json:"state"`
+	// Description is the description of the final state of the job
+	Description string `json:"description,omitempty"`
+	// StartTime is when the job started
+	StartTime time.Time `json:"start_time"`
+	// CompletionTime is when the job completed
+	CompletionTime time.Time `json:"completion_time"`
+	// DurationSeconds is how long the job took to complete
+	DurationSeconds int64 `json:"duration_seconds"`
+	// URL is the link to the report of the job
+	URL string `json:"url,omitempty"`
+	// Spec is a snapshot of the spec of the job
+	Spec v1alpha1.LighthouseJobSpec `json:"spec"`
+}
+
+// RecordForJob creates the record of a completed job
+func RecordForJob(lj *v1alpha1.LighthouseJob) *Record {
+	r := &Record{
+		Name:        lj.Name,
+		Job:         lj.Spec.Job,
+		Type:        lj.Spec.Type,
+		Context:     lj.Spec.Context,
+		State:       lj.Status.State,
+		Description: lj.Status.Description,
+		StartTime:   lj.Status.StartTime.Time,
+		URL:         lj.Status.ReportURL,
+		Spec:        *lj.Spec.DeepCopy(),
+	}
+	if lj.Spec.Refs != nil {
+		r.Refs = lj.Spec.Refs.DeepCopy()
+		r.Org = lj.Spec.Refs.Org
+		r.Repo = lj.Spec.Refs.Repo
+		r.Branch = lj.Spec.GetBranch()
+	}
+	if lj.Status.CompletionTime != nil {
+		r.CompletionTime = lj.Status.CompletionTime.Time
+		r.DurationSeconds = int64(r.CompletionTime.Sub(r.StartTime).Seconds())
+	}
+	return r
+}
+
+// Query selects records, empty fields match any value
+type Query struct {
+	Org    string
+	Repo   string
+	Job    string
+	Branch string
+	State  v1alpha1.PipelineState
+	// Limit is the maximum number of records returned, 0 means no limit
+	Limit int
+}
+
+// Matches returns true if the record is selected by the query
+func (q *Query) Matches(r *Record) bool {
+	return (q.Org == "" || q.Org == r.Org) &&
+		(q.Repo == "" || q.Repo == r.Repo) &&
+		(q.Job == "" || q.Job == r.Job) &&
+		(q.Branch == "" || q.Branch == r.Branch) &&
+		(q.State == "" || q.State == r.State)
+}
@@ -0,0 +1,169 @@
+package jobhistory
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MemoryStore keeps the most recent records in memory
+	MemoryStore = "memory"
+	// FileStore keeps the records as JSON files in a directory, e.g. a persistent volume or a mounted bucket
+	FileStore = "file"
+
+	// defaultMaxRecords is the number of records kept by the memory store
+	defaultMaxRecords = 10000
+
+	// noRepo is the directory used by the file store for jobs which do not run against a repository
+	noRepo = "_"
+)
+
+// Store persists the records of job executions
+type Store interface {
+	// Put adds or replaces the record of an execution
+	Put(record *Record) error
+	// Query returns the records selected by the query, most recently started first
+	Query(query Query) ([]*Record, error)
+}
+
+// NewStore creates the store of the given kind, the path is the directory used by the file store
+func NewStore(kind, path string) (Store, error) {
+	switch kind {
+	case "", MemoryStore:
+		return NewMemoryStore(defaultMaxRecords), nil
+	case FileStore:
+		if path == "" {
+			return nil, errors.New("the file store requires a directory")
+		}
+		return &fileStore{dir: path}, nil
+	default:
+		return nil, errors.Errorf("unknown job history store %q, must be one of %s or %s", kind, MemoryStore, FileStore)
+	}
+}
+
+// NewMemoryStore creates a store keeping up to maxRecords records in memory, dropping the oldest first
+func NewMemoryStore(maxRecords int) Store {
+	return &memoryStore{maxRecords: maxRecords, records: map[string]*Record{}}
+}
+
+type memoryStore struct {
+	sync.RWMutex
+	maxRecords int
+	records    map[string]*Record
+	order      []string
+}
+
+func (s *memoryStore) Put(record *Record) error {
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.records[record.Name]; !ok {
+		s.order = append(s.order, record.Name)
+	}
+	s.records[record.Name] = record
+	for len(s.order) > s.maxRecords {
+		delete(s.records, s.order[0])
+		s.order = s.order[1:]
+	}
+	return nil
+}
+
+func (s *memoryStore) Query(query Query) ([]*Record, error) {
+	s.RLock()
+	defer s.RUnlock()
+	var records []*Record
+	for _, r := range s.records {
+		if query.Matches(r) {
+			records = append(records, r)
+		}
+	}
+	return sortAndLimit(records, query.Limit), nil
+}
+
+// fileStore keeps each record in <dir>/<org>/<repo>/<name>.json so that queries for a repository only read the
+// records of that repository
+type fileStore struct {
+	dir string
+}
+
+func (s *fileStore) Put(record *Record) error {
+	dir := filepath.Join(s.dir, pathElement(record.Org), pathElement(record.Repo))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrapf(err, "failed to create directory %s", dir)
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal record %s", record.Name)
+	}
+	// write to a temporary file first so that readers never see a partial record
+	path := filepath.Join(dir, record.Name+".json")
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write record %s", tmp)
+	}
+	return errors.Wrapf(os.Rename(tmp, path), "failed to save record %s", path)
+}
+
+func (s *fileStore) Query(query Query) ([]*Record, error) {
+	root := s.dir
+	if query.Org != "" {
+		root = filepath.Join(root, pathElement(query.Org))
+		if query.Repo != "" {
+			root = filepath.Join(root, pathElement(query.Repo))
+		}
+	}
+	var records []*Record
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read record %s", path)
+		}
+		r := &Record{}
+		if err := json.Unmarshal(data, r); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal record %s", path)
+		}
+		if query.Matches(r) {
+			records = append(records, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sortAndLimit(records, query.Limit), nil
+}
+
+func pathElement(s string) string {
+	if s == "" {
+		return noRepo
+	}
+	return s
+}
+
+func sortAndLimit(records []*Record, limit int) []*Record {
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].StartTime.Equal(records[j].StartTime) {
+			return records[i].Name > records[j].Name
+		}
+		return records[i].StartTime.After(records[j].StartTime)
+	})
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+	return records
+}
@@ -0,0 +1,159 @@
+package jobhistory
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var start = time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func record(name, org, repo, jobName, branch string, state v1alpha1.PipelineState, started int) *Record {
+	startTime := metav1.NewTime(start.Add(time.Duration(started) * time.Minute))
+	completionTime := metav1.NewTime(startTime.Add(90 * time.Second))
+	lj := &v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1alpha1.LighthouseJobSpec{
+			Type: job.PostsubmitJob,
+			Job:  jobName,
+		},
+		Status: v1alpha1.LighthouseJobStatus{
+			State:          state,
+			StartTime:      startTime,
+			CompletionTime: &completionTime,
+		},
+	}
+	if org != "" {
+		lj.Spec.Refs = &v1alpha1.Refs{Org: org, Repo: repo, BaseRef: branch}
+	}
+	return RecordForJob(lj)
+}
+
+func names(records []*Record) []string {
+	var result []string
+	for _, r := range records {
+		result = append(result, r.Name)
+	}
+	return result
+}
+
+func TestStores(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jobhistory")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	fileStore, err := NewStore(FileStore, dir)
+	require.NoError(t, err)
+
+	stores := map[string]Store{
+		MemoryStore: NewMemoryStore(10),
+		FileStore:   fileStore,
+	}
+	records := []*Record{
+		record("a-1", "org", "a", "unit", "master", v1alpha1.SuccessState, 1),
+		record("a-2", "org", "a", "unit", "master", v1alpha1.FailureState, 2),
+		record("a-3", "org", "a", "e2e", "release", v1alpha1.SuccessState, 3),
+		record("b-1", "org", "b", "unit", "master", v1alpha1.SuccessState, 4),
+		record("periodic-1", "", "", "nightly", "", v1alpha1.SuccessState, 5),
+	}
+	queries := []struct {
+		name     string
+		query    Query
+		expected []string
+	}{
+		{
+			name:     "all",
+			expected: []string{"periodic-1", "b-1", "a-3", "a-2", "a-1"},
+		},
+		{
+			name:     "by repo",
+			query:    Query{Org: "org", Repo: "a"},
+			expected: []string{"a-3", "a-2", "a-1"},
+		},
+		{
+			name:     "by job and branch",
+			query:    Query{Job: "unit", Branch: "master"},
+			expected: []string{"b-1", "a-2", "a-1"},
+		},
+		{
+			name:     "by state",
+			query:    Query{Org: "org", Repo: "a", State: v1alpha1.FailureState},
+			expected: []string{"a-2"},
+		},
+		{
+			name:     "limited",
+			query:    Query{Org: "org", Limit: 2},
+			expected: []string{"b-1", "a-3"},
+		},
+		{
+			name:  "unknown repo",
+			query: Query{Org: "org", Repo: "c"},
+		},
+	}
+
+	for kind, store := range stores {
+		for _, r := range records {
+			require.NoError(t, store.Put(r), kind)
+		}
+		// replacing a record does not duplicate it
+		require.NoError(t, store.Put(records[0]), kind)
+
+		for _, q := range queries {
+			t.Run(kind+" "+q.name, func(t *testing.T) {
+				results, err := store.Query(q.query)
+				require.NoError(t, err)
+				assert.Equal(t, q.expected, names(results))
+			})
+		}
+	}
+
+	loaded, err := fileStore.Query(Query{Org: "org", Repo: "a", Job: "e2e"})
+	require.NoError(t, err)
+	require.Len(t, loaded, 1)
+	assert.Equal(t, records[2].Branch, loaded[0].Branch)
+	assert.Equal(t, int64(90), loaded[0].DurationSeconds)
+	assert.True(t, records[2].StartTime.Equal(loaded[0].StartTime))
+}
+
+func TestMemoryStoreDropsOldestRecords(t *testing.T) {
+	store := NewMemoryStore(2)
+	require.NoError(t, store.Put(record("a-1", "org", "a", "unit", "master", v1alpha1.SuccessState, 1)))
+	require.NoError(t, store.Put(record("a-2", "org", "a", "unit", "master", v1alpha1.SuccessState, 2)))
+	require.NoError(t, store.Put(record("a-3", "org", "a", "unit", "master", v1alpha1.SuccessState, 3)))
+
+	results, err := store.Query(Query{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a-3", "a-2"}, names(results))
+}
+
+func TestHandler(t *testing.T) {
+	store := NewMemoryStore(10)
+	require.NoError(t, store.Put(record("a-1", "org", "a", "unit", "master", v1alpha1.SuccessState, 1)))
+	require.NoError(t, store.Put(record("b-1", "org", "b", "unit", "master", v1alpha1.SuccessState, 2)))
+	handler := &Handler{Store: store}
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/history?org=org&repo=a", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	var results []*Record
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &results))
+	assert.Equal(t, []string{"a-1"}, names(results))
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/history?repo=c", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "[]\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/history?limit=lots", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
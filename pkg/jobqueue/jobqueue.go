@@ -0,0 +1,141 @@
+// Package jobqueue decides which triggered LighthouseJobs may start their pipelines given the concurrency limits of
+// the jobs and of the Lighthouse config. The jobs with the highest priority start first, e.g. presubmits before
+// periodics, and the capacity is shared fairly between the jobs of the same priority: the waiting jobs of the
+// repositories with the fewest running pipelines start first, then the oldest ones.
+package jobqueue
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+)
+
+// counts are the numbers of pipelines running, or admitted ahead of a job, in each scope of the limits
+type counts struct {
+	total int
+	orgs  map[string]int
+	repos map[string]int
+	jobs  map[string]int
+}
+
+func newCounts() *counts {
+	return &counts{
+		orgs:  map[string]int{},
+		repos: map[string]int{},
+		jobs:  map[string]int{},
+	}
+}
+
+func (c *counts) add(j *v1alpha1.LighthouseJob) {
+	c.total++
+	if org, _ := orgRepo(j); org != "" {
+		c.orgs[org]++
+	}
+	c.repos[repoKey(j)]++
+	c.jobs[j.Spec.Job]++
+}
+
+// blocked returns why the job cannot start without exceeding a limit, or an empty string if it can
+func (c *counts) blocked(limits *lighthouse.Concurrency, j *v1alpha1.LighthouseJob) string {
+	if max := j.Spec.MaxConcurrency; max > 0 && c.jobs[j.Spec.Job] >= max {
+		return fmt.Sprintf("%d pipelines of %s running or queued ahead, the maximum is %d", c.jobs[j.Spec.Job], j.Spec.Job, max)
+	}
+	if org, repo := orgRepo(j); org != "" {
+		fullName := repoKey(j)
+		if max := limits.RepoLimit(org, repo); max > 0 && c.repos[fullName] >= max {
+			return fmt.Sprintf("%d pipelines of %s running or queued ahead, the maximum is %d", c.repos[fullName], fullName, max)
+		}
+		if max := limits.OrgLimit(org); max > 0 && c.orgs[org] >= max {
+			return fmt.Sprintf("%d pipelines of %s running or queued ahead, the maximum is %d", c.orgs[org], org, max)
+		}
+	}
+	if max := limits.MaxPipelines; max > 0 && c.total >= max {
+		return fmt.Sprintf("%d pipelines running or queued ahead, the maximum is %d", c.total, max)
+	}
+	return ""
+}
+
+// CanStart returns whether the triggered job may start its pipeline, or why it has to wait, given the other jobs
+// handled by the same engine. Pending and running jobs count as running, and triggered jobs as waiting in the queue.
+func CanStart(limits lighthouse.Concurrency, job *v1alpha1.LighthouseJob, jobs []v1alpha1.LighthouseJob) (bool, string) {
+	running := newCounts()
+	queue := []*v1alpha1.LighthouseJob{job}
+	for i := range jobs {
+		j := &jobs[i]
+		if j.Name == job.Name {
+			continue
+		}
+		switch j.Status.State {
+		case v1alpha1.PendingState, v1alpha1.RunningState:
+			running.add(j)
+		case v1alpha1.TriggeredState:
+			queue = append(queue, j)
+		}
+	}
+
+	// admit the waiting jobs one at a time in fair order until the job is admitted or blocked
+	for {
+		var admissible []*v1alpha1.LighthouseJob
+		for _, j := range queue {
+			if reason := running.blocked(&limits, j); reason != "" {
+				if j == job {
+					return false, reason
+				}
+				continue
+			}
+			admissible = append(admissible, j)
+		}
+		sort.SliceStable(admissible, func(i, k int) bool {
+			return before(running, admissible[i], admissible[k])
+		})
+		next := admissible[0]
+		if next == job {
+			return true, ""
+		}
+		running.add(next)
+		queue = remove(queue, next)
+	}
+}
+
+// before returns whether the job a is admitted before b: jobs with the highest priority first, then jobs of the
+// repositories with the fewest running pipelines, then the oldest jobs
+func before(running *counts, a, b *v1alpha1.LighthouseJob) bool {
+	if aPriority, bPriority := a.Spec.GetPriority(), b.Spec.GetPriority(); aPriority != bPriority {
+		return aPriority > bPriority
+	}
+	aRunning, bRunning := running.repos[repoKey(a)], running.repos[repoKey(b)]
+	if aRunning != bRunning {
+		return aRunning < bRunning
+	}
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
+
+func remove(queue []*v1alpha1.LighthouseJob, job *v1alpha1.LighthouseJob) []*v1alpha1.LighthouseJob {
+	var answer []*v1alpha1.LighthouseJob
+	for _, j := range queue {
+		if j != job {
+			answer = append(answer, j)
+		}
+	}
+	return answer
+}
+
+func orgRepo(j *v1alpha1.LighthouseJob) (string, string) {
+	if j.Spec.Refs == nil {
+		return "", ""
+	}
+	return j.Spec.Refs.Org, j.Spec.Refs.Repo
+}
+
+// repoKey returns the 'org/repo' of the job, or an empty string for jobs without refs, e.g. periodics
+func repoKey(j *v1alpha1.LighthouseJob) string {
+	if org, repo := orgRepo(j); org != "" {
+		return org + "/" + repo
+	}
+	return ""
+}
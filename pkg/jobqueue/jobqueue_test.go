@@ -0,0 +1,190 @@
+package jobqueue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var created = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func makeJob(name, repo string, state v1alpha1.PipelineState, age int) v1alpha1.LighthouseJob {
+	j := v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created.Add(-time.Duration(age) * time.Minute)),
+		},
+		Spec: v1alpha1.LighthouseJobSpec{
+			Job: "unit",
+		},
+		Status: v1alpha1.LighthouseJobStatus{State: state},
+	}
+	if repo != "" {
+		j.Spec.Refs = &v1alpha1.Refs{Org: "org", Repo: repo}
+	}
+	return j
+}
+
+func withType(j v1alpha1.LighthouseJob, kind job.PipelineKind) v1alpha1.LighthouseJob {
+	j.Spec.Type = kind
+	return j
+}
+
+func withPriority(j v1alpha1.LighthouseJob, priority int) v1alpha1.LighthouseJob {
+	j.Spec.Priority = &priority
+	return j
+}
+
+func TestCanStart(t *testing.T) {
+	testCases := []struct {
+		name           string
+		limits         lighthouse.Concurrency
+		maxConcurrency int
+		job            v1alpha1.LighthouseJob
+		jobs           []v1alpha1.LighthouseJob
+		expected       bool
+		reason         string
+	}{
+		{
+			name: "no limits",
+			job:  makeJob("a", "mono", v1alpha1.TriggeredState, 0),
+			jobs: []v1alpha1.LighthouseJob{
+				makeJob("b", "mono", v1alpha1.PendingState, 1),
+				makeJob("c", "mono", v1alpha1.TriggeredState, 1),
+			},
+			expected: true,
+		},
+		{
+			name:   "repo limit reached",
+			limits: lighthouse.Concurrency{MaxPerRepo: 1},
+			job:    makeJob("a", "mono", v1alpha1.TriggeredState, 0),
+			jobs: []v1alpha1.LighthouseJob{
+				makeJob("b", "mono", v1alpha1.PendingState, 1),
+				makeJob("c", "other", v1alpha1.PendingState, 1),
+			},
+			reason: "1 pipelines of org/mono running or queued ahead, the maximum is 1",
+		},
+		{
+			name:   "repo limit overridden",
+			limits: lighthouse.Concurrency{MaxPerRepo: 1, Limits: map[string]int{"org/mono": 2}},
+			job:    makeJob("a", "mono", v1alpha1.TriggeredState, 0),
+			jobs: []v1alpha1.LighthouseJob{
+				makeJob("b", "mono", v1alpha1.PendingState, 1),
+			},
+			expected: true,
+		},
+		{
+			name:   "org limit reached",
+			limits: lighthouse.Concurrency{Limits: map[string]int{"org": 2}},
+			job:    makeJob("a", "mono", v1alpha1.TriggeredState, 0),
+			jobs: []v1alpha1.LighthouseJob{
+				makeJob("b", "one", v1alpha1.PendingState, 1),
+				makeJob("c", "two", v1alpha1.PendingState, 1),
+			},
+			reason: "2 pipelines of org running or queued ahead, the maximum is 2",
+		},
+		{
+			name:           "job max concurrency reached",
+			maxConcurrency: 1,
+			job:            makeJob("a", "mono", v1alpha1.TriggeredState, 0),
+			jobs: []v1alpha1.LighthouseJob{
+				makeJob("b", "other", v1alpha1.PendingState, 1),
+			},
+			reason: "1 pipelines of unit running or queued ahead, the maximum is 1",
+		},
+		{
+			name:   "running jobs count",
+			limits: lighthouse.Concurrency{MaxPipelines: 2},
+			job:    makeJob("a", "mono", v1alpha1.TriggeredState, 0),
+			jobs: []v1alpha1.LighthouseJob{
+				makeJob("b", "mono", v1alpha1.RunningState, 1),
+				makeJob("c", "other", v1alpha1.RunningState, 1),
+			},
+			reason: "2 pipelines running or queued ahead, the maximum is 2",
+		},
+		{
+			name:   "running and pending jobs count",
+			limits: lighthouse.Concurrency{MaxPerRepo: 2},
+			job:    makeJob("a", "mono", v1alpha1.TriggeredState, 0),
+			jobs: []v1alpha1.LighthouseJob{
+				makeJob("b", "mono", v1alpha1.RunningState, 1),
+				makeJob("c", "mono", v1alpha1.PendingState, 1),
+			},
+			reason: "2 pipelines of org/mono running or queued ahead, the maximum is 2",
+		},
+		{
+			name:   "completed jobs do not count",
+			limits: lighthouse.Concurrency{MaxPipelines: 1},
+			job:    makeJob("a", "mono", v1alpha1.TriggeredState, 0),
+			jobs: []v1alpha1.LighthouseJob{
+				makeJob("b", "mono", v1alpha1.SuccessState, 1),
+				makeJob("c", "", v1alpha1.FailureState, 1),
+			},
+			expected: true,
+		},
+		{
+			name:   "older jobs start first",
+			limits: lighthouse.Concurrency{MaxPipelines: 2},
+			job:    makeJob("a", "mono", v1alpha1.TriggeredState, 0),
+			jobs: []v1alpha1.LighthouseJob{
+				makeJob("b", "mono", v1alpha1.PendingState, 3),
+				makeJob("c", "mono", v1alpha1.TriggeredState, 1),
+			},
+			reason: "2 pipelines running or queued ahead, the maximum is 2",
+		},
+		{
+			name:   "repositories with fewer running pipelines start first",
+			limits: lighthouse.Concurrency{MaxPipelines: 3},
+			job:    makeJob("a", "small", v1alpha1.TriggeredState, 0),
+			jobs: []v1alpha1.LighthouseJob{
+				makeJob("b", "mono", v1alpha1.PendingState, 3),
+				makeJob("c", "mono", v1alpha1.TriggeredState, 2),
+				makeJob("d", "mono", v1alpha1.TriggeredState, 1),
+			},
+			expected: true,
+		},
+		{
+			name:   "queued jobs blocked by their own limits do not hold back others",
+			limits: lighthouse.Concurrency{MaxPipelines: 2, Limits: map[string]int{"org/mono": 1}},
+			job:    makeJob("a", "small", v1alpha1.TriggeredState, 0),
+			jobs: []v1alpha1.LighthouseJob{
+				makeJob("b", "mono", v1alpha1.PendingState, 3),
+				makeJob("c", "mono", v1alpha1.TriggeredState, 2),
+			},
+			expected: true,
+		},
+		{
+			name:   "presubmits start before older periodics",
+			limits: lighthouse.Concurrency{MaxPipelines: 2},
+			job:    withType(makeJob("a", "mono", v1alpha1.TriggeredState, 0), job.PresubmitJob),
+			jobs: []v1alpha1.LighthouseJob{
+				makeJob("b", "mono", v1alpha1.PendingState, 3),
+				withType(makeJob("c", "", v1alpha1.TriggeredState, 2), job.PeriodicJob),
+			},
+			expected: true,
+		},
+		{
+			name:   "higher priority jobs start before jobs of less busy repositories",
+			limits: lighthouse.Concurrency{MaxPipelines: 2},
+			job:    makeJob("a", "small", v1alpha1.TriggeredState, 2),
+			jobs: []v1alpha1.LighthouseJob{
+				makeJob("b", "mono", v1alpha1.PendingState, 3),
+				withPriority(makeJob("c", "mono", v1alpha1.TriggeredState, 0), job.PostsubmitPriority+1),
+			},
+			reason: "2 pipelines running or queued ahead, the maximum is 2",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.job.Spec.MaxConcurrency = tc.maxConcurrency
+			start, reason := CanStart(tc.limits, &tc.job, append(tc.jobs, tc.job))
+			assert.Equal(t, tc.expected, start)
+			assert.Equal(t, tc.reason, reason)
+		})
+	}
+}
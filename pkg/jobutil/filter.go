@@ -31,6 +31,9 @@ var TestAllRe = regexp.MustCompile(`(?m)^/(?:lh-)?test all,?($|\s.*)`)
 // RetestRe provides the regex for `/retest`
 var RetestRe = regexp.MustCompile(`(?m)^/(?:lh-)?retest\s*$`)
 
+// RetestRequiredRe provides the regex for `/retest-required`
+var RetestRequiredRe = regexp.MustCompile(`(?m)^/(?:lh-)?retest-required\s*$`)
+
 // OkToTestRe provies the regex for `/ok-to-test`
 var OkToTestRe = regexp.MustCompile(`(?m)^/(?:lh-)?ok-to-test\s*$`)
 
@@ -73,6 +76,12 @@ func AggregateFilter(filters []Filter) Filter {
 // FilterPresubmits determines which presubmits should run and which should be skipped
 // by evaluating the user-provided filter.
 func FilterPresubmits(filter Filter, changes job.ChangedFilesProvider, branch string, presubmits []job.Presubmit, logger *logrus.Entry) ([]job.Presubmit, []job.Presubmit, error) {
+	return FilterMonorepoPresubmits(filter, changes, branch, presubmits, nil, logger)
+}
+
+// FilterMonorepoPresubmits determines which presubmits should run and which should be skipped like FilterPresubmits,
+// the presubmits of the groups of the monorepo which are not changed being skipped unless they are forced to run.
+func FilterMonorepoPresubmits(filter Filter, changes job.ChangedFilesProvider, branch string, presubmits []job.Presubmit, monorepo *job.Monorepo, logger *logrus.Entry) ([]job.Presubmit, []job.Presubmit, error) {
 
 	var toTrigger []job.Presubmit
 	var namesToTrigger []string
@@ -86,6 +95,13 @@ func FilterPresubmits(filter Filter, changes job.ChangedFilesProvider, branch st
 		if err != nil {
 			return nil, nil, err
 		}
+		if shouldRun && !forced && monorepo.Scopes(presubmit.Name) {
+			changeList, err := changes()
+			if err != nil {
+				return nil, nil, err
+			}
+			shouldRun = monorepo.RunsAgainstChanges(presubmit.Name, changeList)
+		}
 		if shouldRun {
 			toTrigger = append(toTrigger, presubmit)
 			namesToTrigger = append(namesToTrigger, presubmit.Name)
@@ -131,6 +147,14 @@ func RetestFilter(failedContexts, allContexts sets.String) Filter {
 	}
 }
 
+// RetestRequiredFilter builds a filter for `/retest-required`, which only reruns
+// the required presubmits whose contexts failed
+func RetestRequiredFilter(failedContexts sets.String) Filter {
+	return func(p job.Presubmit) (bool, bool, bool) {
+		return p.ContextRequired() && failedContexts.Has(p.Context), false, true
+	}
+}
+
 type contextGetter func() (sets.String, sets.String, error)
 
 // PresubmitFilter creates a filter for presubmits
@@ -151,6 +175,14 @@ func PresubmitFilter(honorOkToTest bool, contextGetter contextGetter, body strin
 		}
 		filters = append(filters, RetestFilter(failedContexts, allContexts))
 	}
+	if RetestRequiredRe.MatchString(body) {
+		logger.Debug("Using retest-required filter.")
+		failedContexts, _, err := contextGetter()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, RetestRequiredFilter(failedContexts))
+	}
 	if (honorOkToTest && OkToTestRe.MatchString(body)) || TestAllRe.MatchString(body) {
 		logger.Debug("Using test-all filter.")
 		filters = append(filters, TestAllFilter())
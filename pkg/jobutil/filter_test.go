@@ -352,6 +352,107 @@ func TestFilterPresubmits(t *testing.T) {
 	}
 }
 
+func TestFilterMonorepoPresubmits(t *testing.T) {
+	monorepo := &job.Monorepo{
+		Groups: []job.MonorepoGroup{{
+			Name:  "foo",
+			Paths: []string{"services/foo/**"},
+			Jobs:  []string{"foo-test", "shared-test"},
+		}, {
+			Name:  "bar",
+			Paths: []string{"services/bar/**", "*.md"},
+			Jobs:  []string{"bar-test", "shared-test"},
+		}},
+	}
+	if err := monorepo.SetRegexes(); err != nil {
+		t.Fatalf("failed to compile monorepo: %v", err)
+	}
+	presubmits := []job.Presubmit{{
+		Base:      job.Base{Name: "foo-test"},
+		Reporter:  job.Reporter{Context: "foo-test"},
+		AlwaysRun: true,
+	}, {
+		Base:      job.Base{Name: "bar-test"},
+		Reporter:  job.Reporter{Context: "bar-test"},
+		AlwaysRun: true,
+	}, {
+		Base:      job.Base{Name: "shared-test"},
+		Reporter:  job.Reporter{Context: "shared-test"},
+		AlwaysRun: true,
+	}, {
+		Base:      job.Base{Name: "lint"},
+		Reporter:  job.Reporter{Context: "lint"},
+		AlwaysRun: true,
+	}}
+	var testCases = []struct {
+		name              string
+		filter            Filter
+		changes           []string
+		expectedToTrigger []string
+		expectedToSkip    []string
+	}{
+		{
+			name:              "only the changed group runs",
+			filter:            TestAllFilter(),
+			changes:           []string{"services/foo/main.go"},
+			expectedToTrigger: []string{"foo-test", "shared-test", "lint"},
+			expectedToSkip:    []string{"bar-test"},
+		},
+		{
+			name:              "globs of the root files do not match nested files",
+			filter:            TestAllFilter(),
+			changes:           []string{"docs/README.md"},
+			expectedToTrigger: []string{"lint"},
+			expectedToSkip:    []string{"foo-test", "bar-test", "shared-test"},
+		},
+		{
+			name:              "several groups run",
+			filter:            TestAllFilter(),
+			changes:           []string{"services/foo/main.go", "README.md"},
+			expectedToTrigger: []string{"foo-test", "bar-test", "shared-test", "lint"},
+		},
+		{
+			name:              "explicitly triggered jobs of unchanged groups run",
+			filter:            CommandFilter("/test bar-test"),
+			changes:           []string{"services/foo/main.go"},
+			expectedToTrigger: []string{"bar-test"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			changes := func() ([]string, error) {
+				return testCase.changes, nil
+			}
+			var ps []job.Presubmit
+			for _, p := range presubmits {
+				p.SetDefaults("default")
+				if err := p.SetRegexes(); err != nil {
+					t.Fatalf("failed to set regexes: %v", err)
+				}
+				ps = append(ps, p)
+			}
+			toTrigger, toSkip, err := FilterMonorepoPresubmits(testCase.filter, changes, "master", ps, monorepo, logrus.WithField("test-case", testCase.name))
+			if err != nil {
+				t.Fatalf("%s: expected no error filtering presubmits, but got one: %v", testCase.name, err)
+			}
+			var triggered, skipped []string
+			for _, p := range toTrigger {
+				triggered = append(triggered, p.Name)
+			}
+			for _, p := range toSkip {
+				skipped = append(skipped, p.Name)
+			}
+			if !reflect.DeepEqual(triggered, testCase.expectedToTrigger) {
+				t.Errorf("%s: incorrect set of presubmits to trigger: %s", testCase.name, diff.ObjectReflectDiff(triggered, testCase.expectedToTrigger))
+			}
+			if !reflect.DeepEqual(skipped, testCase.expectedToSkip) {
+				t.Errorf("%s: incorrect set of presubmits to skip: %s", testCase.name, diff.ObjectReflectDiff(skipped, testCase.expectedToSkip))
+			}
+		})
+	}
+}
+
 func TestDetermineSkippedPresubmits(t *testing.T) {
 	var testCases = []struct {
 		name                      string
@@ -648,6 +749,50 @@ func TestPresubmitFilter(t *testing.T) {
 			},
 			expected: [][]bool{{false, false, false}, {false, false, false}, {true, false, true}, {true, false, true}, {true, false, true}},
 		},
+		{
+			name: "retest-required command selects for errored or failed contexts of required jobs only",
+			body: "/retest-required",
+			org:  "org",
+			repo: "repo",
+			ref:  "ref",
+			presubmits: []job.Presubmit{
+				{
+					Base: job.Base{
+						Name: "successful-job",
+					},
+					Reporter: job.Reporter{
+						Context: "existing-successful",
+					},
+				},
+				{
+					Base: job.Base{
+						Name: "failure-job",
+					},
+					Reporter: job.Reporter{
+						Context: "existing-failure",
+					},
+				},
+				{
+					Base: job.Base{
+						Name: "optional-error-job",
+					},
+					Reporter: job.Reporter{
+						Context: "existing-error",
+					},
+					Optional: true,
+				},
+				{
+					Base: job.Base{
+						Name: "missing-always-runs",
+					},
+					Reporter: job.Reporter{
+						Context: "missing-always-runs",
+					},
+					AlwaysRun: true,
+				},
+			},
+			expected: [][]bool{{false, false, false}, {true, false, true}, {false, false, false}, {false, false, false}},
+		},
 		{
 			name: "explicit test command filters for jobs that match",
 			body: "/test trigger",
@@ -80,6 +80,7 @@ func createRefs(pr *scm.PullRequest, baseSHA string, prRefFmt string) v1alpha1.R
 				Number:     number,
 				Author:     pr.Author.Login,
 				SHA:        pr.Head.Sha,
+				Title:      pr.Title,
 				Link:       pr.Link,
 				AuthorLink: pr.Author.Link,
 				CommitLink: fmt.Sprintf("%s/pull/%d/commits/%s", repoLink, number, pr.Head.Sha),
@@ -111,6 +112,7 @@ func PresubmitSpec(p job.Presubmit, refs v1alpha1.Refs) v1alpha1.LighthouseJobSp
 	pjs := specFromJobBase(p.Base)
 	pjs.Type = job.PresubmitJob
 	pjs.Context = p.Context
+	pjs.Report = p.ReportModes()
 	pjs.RerunCommand = p.RerunCommand
 	pjs.Refs = completePrimaryRefs(refs, p.Base)
 
@@ -128,6 +130,7 @@ func PostsubmitSpec(p job.Postsubmit, refs v1alpha1.Refs) v1alpha1.LighthouseJob
 	pjs := specFromJobBase(p.Base)
 	pjs.Type = job.PostsubmitJob
 	pjs.Context = p.Context
+	pjs.Report = p.ReportModes()
 	pjs.Refs = completePrimaryRefs(refs, p.Base)
 
 	if p.JenkinsSpec != nil {
@@ -139,10 +142,35 @@ func PostsubmitSpec(p job.Postsubmit, refs v1alpha1.Refs) v1alpha1.LighthouseJob
 	return pjs
 }
 
+// ReleaseSpec initializes a PipelineOptionsSpec for a given release job.
+func ReleaseSpec(r job.Release, refs v1alpha1.Refs) v1alpha1.LighthouseJobSpec {
+	pjs := specFromJobBase(r.Base)
+	pjs.Type = job.ReleaseJob
+	pjs.Context = r.Context
+	pjs.Report = r.ReportModes()
+	pjs.Refs = completePrimaryRefs(refs, r.Base)
+
+	return pjs
+}
+
+// DeploymentSpec initializes a PipelineOptionsSpec for a given deployment job and the deployment which triggered it.
+func DeploymentSpec(d job.Deployment, refs v1alpha1.Refs, deployment *v1alpha1.DeploymentSpec) v1alpha1.LighthouseJobSpec {
+	pjs := specFromJobBase(d.Base)
+	pjs.Type = job.DeploymentJob
+	pjs.Context = d.Context
+	pjs.Report = d.ReportModes()
+	pjs.Refs = completePrimaryRefs(refs, d.Base)
+	pjs.Deployment = deployment
+
+	return pjs
+}
+
 // PeriodicSpec initializes a PipelineOptionsSpec for a given periodic job.
 func PeriodicSpec(p job.Periodic) v1alpha1.LighthouseJobSpec {
 	pjs := specFromJobBase(p.Base)
 	pjs.Type = job.PeriodicJob
+	pjs.Report = p.Report
+	pjs.IssueRepo = p.IssueRepo
 
 	return pjs
 }
@@ -152,6 +180,7 @@ func BatchSpec(p job.Presubmit, refs v1alpha1.Refs) v1alpha1.LighthouseJobSpec {
 	pjs := specFromJobBase(p.Base)
 	pjs.Type = job.BatchJob
 	pjs.Context = p.Context
+	pjs.Report = p.ReportModes()
 	pjs.Refs = completePrimaryRefs(refs, p.Base)
 
 	return pjs
@@ -163,12 +192,21 @@ func specFromJobBase(jb job.Base) v1alpha1.LighthouseJobSpec {
 		namespace = *jb.Namespace
 	}
 	return v1alpha1.LighthouseJobSpec{
-		Agent:           jb.Agent,
-		Job:             jb.Name,
-		Namespace:       namespace,
-		MaxConcurrency:  jb.MaxConcurrency,
-		PodSpec:         jb.Spec,
-		PipelineRunSpec: jb.PipelineRunSpec,
+		Agent:             jb.Agent,
+		Job:               jb.Name,
+		Namespace:         namespace,
+		MaxConcurrency:    jb.MaxConcurrency,
+		Priority:          jb.Priority,
+		PodSpec:           jb.Spec,
+		PipelineRunSpec:   jb.PipelineRunSpec,
+		PipelineRunParams: jb.PipelineRunParams,
+		PodTemplate:       jb.PodTemplate,
+		Cluster:           jb.Cluster,
+		Params:            jb.Params,
+		EnvFromSecrets:    jb.EnvFromSecrets,
+		Retry:             jb.Retry,
+		Timeout:           jb.Timeout,
+		GracePeriod:       jb.GracePeriod,
 	}
 }
 
@@ -194,10 +232,12 @@ func LighthouseJobFields(lighthouseJob *v1alpha1.LighthouseJob) logrus.Fields {
 	if len(lighthouseJob.ObjectMeta.Labels[scmprovider.EventGUID]) > 0 {
 		fields[scmprovider.EventGUID] = lighthouseJob.ObjectMeta.Labels[scmprovider.EventGUID]
 	}
-	if lighthouseJob.Spec.Refs != nil && len(lighthouseJob.Spec.Refs.Pulls) == 1 {
-		fields[scmprovider.PrLogField] = lighthouseJob.Spec.Refs.Pulls[0].Number
+	if lighthouseJob.Spec.Refs != nil {
 		fields[scmprovider.RepoLogField] = lighthouseJob.Spec.Refs.Repo
 		fields[scmprovider.OrgLogField] = lighthouseJob.Spec.Refs.Org
+		if len(lighthouseJob.Spec.Refs.Pulls) == 1 {
+			fields[scmprovider.PrLogField] = lighthouseJob.Spec.Refs.Pulls[0].Number
+		}
 	}
 
 	if lighthouseJob.Spec.JenkinsSpec != nil {
@@ -26,7 +26,9 @@ import (
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/util/diff"
 )
@@ -656,3 +658,50 @@ func TestPartitionActive(t *testing.T) {
 		}
 	}
 }
+
+func TestLighthouseJobFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		job      v1alpha1.LighthouseJob
+		expected logrus.Fields
+	}{
+		{
+			name: "periodic",
+			job: v1alpha1.LighthouseJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "nightly-1"},
+				Spec:       v1alpha1.LighthouseJobSpec{Job: "nightly", Type: job.PeriodicJob},
+			},
+			expected: logrus.Fields{"name": "nightly-1", "job": "nightly", "type": job.PeriodicJob},
+		},
+		{
+			name: "postsubmit",
+			job: v1alpha1.LighthouseJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "release-1", Labels: map[string]string{scmprovider.EventGUID: "abc"}},
+				Spec: v1alpha1.LighthouseJobSpec{
+					Job:  "release",
+					Type: job.PostsubmitJob,
+					Refs: &v1alpha1.Refs{Org: "org", Repo: "repo"},
+				},
+			},
+			expected: logrus.Fields{"name": "release-1", "job": "release", "type": job.PostsubmitJob, "event-GUID": "abc", "org": "org", "repo": "repo"},
+		},
+		{
+			name: "presubmit",
+			job: v1alpha1.LighthouseJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "unit-1"},
+				Spec: v1alpha1.LighthouseJobSpec{
+					Job:  "unit",
+					Type: job.PresubmitJob,
+					Refs: &v1alpha1.Refs{Org: "org", Repo: "repo", Pulls: []v1alpha1.Pull{{Number: 5}}},
+				},
+			},
+			expected: logrus.Fields{"name": "unit-1", "job": "unit", "type": job.PresubmitJob, "org": "org", "repo": "repo", "pr": 5},
+		},
+	}
+
+	for _, tc := range tests {
+		if actual := LighthouseJobFields(&tc.job); !reflect.DeepEqual(tc.expected, actual) {
+			t.Errorf("%s: expected fields %v but got %v", tc.name, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package jobutil
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/pkg/errors"
+)
+
+// maxParamValueLength bounds the values rendered from the templates, e.g. from a long list of changed files
+const maxParamValueLength = 64 * 1024
+
+// ParamTemplateContext is the data the value templates of the pipeline run params of a job are rendered with, e.g.
+// {{ .PullRequest.Number }} or {{ .Refs.BaseRef }}. The fields which do not apply to the job are empty, the refs and
+// the deployment being nil so that templates can test them, e.g. {{ if .Deployment }}.
+type ParamTemplateContext struct {
+	// Job is the name of the job
+	Job string
+	// Type is the type of the job, e.g. presubmit
+	Type string
+	// Context is the status context of the job
+	Context string
+	// Refs are the refs the job builds
+	Refs *v1alpha1.Refs
+	// PullRequest is the pull request a presubmit builds, the first one of a batch
+	PullRequest v1alpha1.Pull
+	// Deployment is the deployment which triggered a deployment job
+	Deployment *v1alpha1.DeploymentSpec
+	// ChangedFiles are the files changed by the pull request or push which triggered the job
+	ChangedFiles []string
+}
+
+// SetChangedFiles sets the changed files of the spec of a job, sorted and limited to the first
+// v1alpha1.MaxChangedFiles of them
+func SetChangedFiles(spec *v1alpha1.LighthouseJobSpec, files []string) {
+	files = append([]string{}, files...)
+	sort.Strings(files)
+	if len(files) > v1alpha1.MaxChangedFiles {
+		files = files[:v1alpha1.MaxChangedFiles]
+	}
+	spec.ChangedFiles = files
+}
+
+// NewParamTemplateContext returns the data the value templates of the pipeline run params of the job are rendered with
+func NewParamTemplateContext(spec *v1alpha1.LighthouseJobSpec) *ParamTemplateContext {
+	answer := &ParamTemplateContext{
+		Job:          spec.Job,
+		Type:         string(spec.Type),
+		Context:      spec.Context,
+		Refs:         spec.Refs,
+		Deployment:   spec.Deployment,
+		ChangedFiles: spec.ChangedFiles,
+	}
+	if spec.Refs != nil && len(spec.Refs.Pulls) > 0 {
+		answer.PullRequest = spec.Refs.Pulls[0]
+	}
+	if answer.ChangedFiles == nil {
+		answer.ChangedFiles = []string{}
+	}
+	return answer
+}
+
+// RenderPipelineRunParams renders the value templates of the pipeline run params of the job
+func RenderPipelineRunParams(spec *v1alpha1.LighthouseJobSpec) (map[string]string, error) {
+	data := NewParamTemplateContext(spec)
+	answer := map[string]string{}
+	for i := range spec.PipelineRunParams {
+		param := &spec.PipelineRunParams[i]
+		t, err := param.Parse()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the value template of param %s", param.Name)
+		}
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, data); err != nil {
+			return nil, errors.Wrapf(err, "failed to render the value template of param %s", param.Name)
+		}
+		if buf.Len() > maxParamValueLength {
+			return nil, fmt.Errorf("the value of param %s is longer than %d bytes", param.Name, maxParamValueLength)
+		}
+		answer[param.Name] = buf.String()
+	}
+	return answer, nil
+}
@@ -0,0 +1,156 @@
+package jobutil
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderPipelineRunParams(t *testing.T) {
+	presubmit := v1alpha1.LighthouseJobSpec{
+		Type:    job.PresubmitJob,
+		Job:     "pr-build",
+		Context: "pr-build",
+		Refs: &v1alpha1.Refs{
+			Org:     "org",
+			Repo:    "repo",
+			BaseRef: "release-1.2",
+			BaseSHA: "base-sha",
+			Pulls: []v1alpha1.Pull{
+				{Number: 42, Author: "alice", SHA: "head-sha", Title: "Fix the docs"},
+			},
+		},
+		ChangedFiles: []string{"docs/a.md", "docs/b.md", "pkg/foo/foo.go"},
+	}
+	postsubmit := v1alpha1.LighthouseJobSpec{
+		Type: job.PostsubmitJob,
+		Job:  "release",
+		Refs: &v1alpha1.Refs{Org: "org", Repo: "repo", BaseRef: "master", BaseSHA: "base-sha"},
+	}
+	deployment := v1alpha1.LighthouseJobSpec{
+		Type:       job.DeploymentJob,
+		Job:        "promote",
+		Refs:       &v1alpha1.Refs{Org: "org", Repo: "repo", BaseRef: "master", BaseSHA: "base-sha"},
+		Deployment: &v1alpha1.DeploymentSpec{Environment: "production"},
+	}
+
+	tests := []struct {
+		name     string
+		spec     v1alpha1.LighthouseJobSpec
+		template string
+		expected string
+		err      string
+	}{
+		{
+			name:     "pull request",
+			spec:     presubmit,
+			template: "{{ .PullRequest.Number }}-{{ .PullRequest.Author }}-{{ .PullRequest.SHA }}",
+			expected: "42-alice-head-sha",
+		},
+		{
+			name:     "refs",
+			spec:     presubmit,
+			template: "{{ .Refs.Org }}/{{ .Refs.Repo }}@{{ .Refs.BaseRef | trimPrefix \"release-\" }}",
+			expected: "org/repo@1.2",
+		},
+		{
+			name:     "changed files",
+			spec:     presubmit,
+			template: "{{ .ChangedFiles | filter \"docs/*\" | join \",\" }}",
+			expected: "docs/a.md,docs/b.md",
+		},
+		{
+			name:     "changed directories",
+			spec:     presubmit,
+			template: "{{ .ChangedFiles | dirs | join \" \" }}",
+			expected: "docs pkg/foo",
+		},
+		{
+			name:     "no pull request",
+			spec:     postsubmit,
+			template: "{{ .PullRequest.Number }}:{{ len .ChangedFiles }}:{{ .Job }}",
+			expected: "0:0:release",
+		},
+		{
+			name:     "default",
+			spec:     postsubmit,
+			template: "{{ .Refs.BaseRef | default \"main\" }}-{{ .PullRequest.Title | default \"none\" }}",
+			expected: "master-none",
+		},
+		{
+			name:     "deployment",
+			spec:     deployment,
+			template: "{{ if .Deployment }}{{ .Deployment.Environment }}{{ else }}staging{{ end }}",
+			expected: "production",
+		},
+		{
+			name:     "no deployment",
+			spec:     postsubmit,
+			template: "{{ if .Deployment }}{{ .Deployment.Environment }}{{ else }}staging{{ end }}",
+			expected: "staging",
+		},
+		{
+			name:     "no refs",
+			spec:     v1alpha1.LighthouseJobSpec{Type: job.PeriodicJob, Job: "nightly"},
+			template: "{{ with .Refs }}{{ .BaseRef }}{{ else }}{{ .Job }}{{ end }}",
+			expected: "nightly",
+		},
+		{
+			name:     "unknown field",
+			spec:     presubmit,
+			template: "{{ .PullRequest.Nmber }}",
+			err:      "can't evaluate field Nmber",
+		},
+		{
+			name:     "unknown function",
+			spec:     presubmit,
+			template: "{{ env \"HOME\" }}",
+			err:      "function \"env\" not defined",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := tc.spec
+			spec.PipelineRunParams = []job.PipelineRunParam{{Name: "VALUE", ValueTemplate: tc.template}}
+			params, err := RenderPipelineRunParams(&spec)
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"VALUE": tc.expected}, params)
+		})
+	}
+}
+
+func TestRenderPipelineRunParamsTooLong(t *testing.T) {
+	spec := v1alpha1.LighthouseJobSpec{
+		Type:              job.PostsubmitJob,
+		ChangedFiles:      []string{strings.Repeat("a", maxParamValueLength)},
+		PipelineRunParams: []job.PipelineRunParam{{Name: "FILES", ValueTemplate: "{{ join \" \" .ChangedFiles }}!"}},
+	}
+	_, err := RenderPipelineRunParams(&spec)
+	assert.Error(t, err)
+}
+
+func TestSetChangedFiles(t *testing.T) {
+	files := []string{"b.go", "a.go"}
+	spec := &v1alpha1.LighthouseJobSpec{}
+	SetChangedFiles(spec, files)
+	assert.Equal(t, []string{"a.go", "b.go"}, spec.ChangedFiles)
+	assert.Equal(t, []string{"b.go", "a.go"}, files, "the given files are not modified")
+
+	files = nil
+	for i := 0; i < v1alpha1.MaxChangedFiles+10; i++ {
+		files = append(files, fmt.Sprintf("file-%05d", i))
+	}
+	SetChangedFiles(spec, files)
+	require.Len(t, spec.ChangedFiles, v1alpha1.MaxChangedFiles)
+	assert.Equal(t, files[v1alpha1.MaxChangedFiles-1], spec.ChangedFiles[v1alpha1.MaxChangedFiles-1])
+}
@@ -29,6 +29,10 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// FreezeTitle is the title of the merge freeze issues opened by the freeze plugin, followed by the branch they freeze
+// if any
+const FreezeTitle = "Merge freeze"
+
 var (
 	branchRE = regexp.MustCompile(`(?im)\bbranch:[^\w-]*([\w-./]+)\b`)
 )
@@ -44,6 +48,12 @@ type Blocker struct {
 	// TODO: time blocked? (when blocker label was added)
 }
 
+// IsFreeze returns whether the blocker is a merge freeze issue opened by the freeze plugin, which the merge override
+// label overrides unlike the other blockers
+func (b Blocker) IsFreeze() bool {
+	return b.Title == FreezeTitle || strings.HasPrefix(b.Title, FreezeTitle+" branch:")
+}
+
 // OrgRepo the org + repo
 type OrgRepo struct {
 	Org, Repo string
@@ -98,7 +108,7 @@ func fromIssues(issues []Issue, log *logrus.Entry) Blockers {
 			Title:  strippedTitle,
 			URL:    string(issue.URL),
 		}
-		if branches := parseBranches(string(issue.Title)); len(branches) > 0 {
+		if branches := ParseBranches(string(issue.Title)); len(branches) > 0 {
 			for _, branch := range branches {
 				key := OrgRepoBranch{
 					Org:    string(issue.Repository.Owner.Login),
@@ -130,7 +140,9 @@ func blockerQuery(label, orgRepoTokens string) string {
 	return strings.Join(tokens, " ")
 }
 
-func parseBranches(str string) []string {
+// ParseBranches returns the branches named in the title of a blocker issue.
+// The issue blocks all the branches of its repository if there are none.
+func ParseBranches(str string) []string {
 	var res []string
 	for _, match := range branchRE.FindAllStringSubmatch(str, -1) {
 		res = append(res, match[1])
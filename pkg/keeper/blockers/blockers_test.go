@@ -65,8 +65,8 @@ func TestParseBranches(t *testing.T) {
 	}
 
 	for _, tc := range tcs {
-		if got := parseBranches(tc.text); !reflect.DeepEqual(got, tc.expected) {
-			t.Errorf("Expected parseBranches(%q)==%q, but got %q.", tc.text, tc.expected, got)
+		if got := ParseBranches(tc.text); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("Expected ParseBranches(%q)==%q, but got %q.", tc.text, tc.expected, got)
 		}
 	}
 }
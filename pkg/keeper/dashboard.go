@@ -0,0 +1,209 @@
+package keeper
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/keeper"
+	"github.com/jenkins-x/lighthouse/pkg/keeper/blockers"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DashboardPath is the path of the HTML dashboard of the merge pools
+	DashboardPath = "/dashboard"
+	// DashboardAPIPath is the path of the JSON API of the dashboard
+	DashboardAPIPath = "/api/dashboard"
+)
+
+// PoolStatus describes a merge pool for the dashboard: the open PRs targeting
+// its branch, why those not merging are blocked and the batch currently running.
+type PoolStatus struct {
+	Org    string
+	Repo   string
+	Branch string
+
+	// Action is the last action taken on the pool and Target the PRs it was taken on.
+	Action Action
+	Target []int
+	// Batch lists the PRs of the batch currently running, if any.
+	Batch []int
+	// Train lists the PRs queued in the merge train of the branch, in order.
+	Train    []int
+	Blockers []blockers.Blocker
+	Freeze   *keeper.BranchFreeze
+	Error    string
+
+	PRs []PRStatus
+}
+
+// PauseStatus describes a component paused at runtime for the dashboard
+type PauseStatus struct {
+	Component   string
+	Target      string
+	Description string
+}
+
+// Dashboard serves the status of the merge pools of a controller
+type Dashboard struct {
+	controller Controller
+	config     config.Getter
+	logger     *logrus.Entry
+}
+
+// NewDashboard creates a dashboard of the merge pools of the given controller
+func NewDashboard(c Controller, cfg config.Getter) *Dashboard {
+	return &Dashboard{
+		controller: c,
+		config:     cfg,
+		logger:     logrus.WithField("component", "dashboard"),
+	}
+}
+
+// PoolStatuses returns the statuses of the pools, sorted by org, repo and branch
+func (d *Dashboard) PoolStatuses() []PoolStatus {
+	return poolStatuses(d.controller.GetPools(), d.controller.GetPRStatuses())
+}
+
+// PauseStatuses returns the components paused at runtime, sorted by component and target
+func (d *Dashboard) PauseStatuses() []PauseStatus {
+	var answer []PauseStatus
+	if d.config == nil || d.config() == nil {
+		return answer
+	}
+	for component, targets := range d.config().Pauses {
+		for target, pause := range targets {
+			answer = append(answer, PauseStatus{Component: component, Target: target, Description: pause.Description()})
+		}
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		if answer[i].Component != answer[j].Component {
+			return answer[i].Component < answer[j].Component
+		}
+		return answer[i].Target < answer[j].Target
+	})
+	return answer
+}
+
+// ServeJSON serves the statuses of the pools as JSON
+func (d *Dashboard) ServeJSON(w http.ResponseWriter, r *http.Request) {
+	b, err := json.Marshal(d.PoolStatuses())
+	if err != nil {
+		d.logger.WithError(err).Error("Encoding JSON.")
+		b = []byte("[]")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(b); err != nil {
+		d.logger.WithError(err).Error("Writing JSON response.")
+	}
+}
+
+// ServeHTML serves the statuses of the pools as an HTML page
+func (d *Dashboard) ServeHTML(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	data := struct {
+		Pauses []PauseStatus
+		Pools  []PoolStatus
+	}{
+		Pauses: d.PauseStatuses(),
+		Pools:  d.PoolStatuses(),
+	}
+	if err := dashboardTemplate.Execute(w, data); err != nil {
+		d.logger.WithError(err).Error("Writing HTML response.")
+	}
+}
+
+// poolStatuses groups the statuses of the PRs by the pool of their base branch.
+// PRs whose branch has no pool, e.g. because none of its PRs match a query, are
+// reported in a pool without action.
+func poolStatuses(pools []Pool, prs []PRStatus) []PoolStatus {
+	byKey := map[string]*PoolStatus{}
+	var keys []string
+	get := func(org, repo, branch string) *PoolStatus {
+		key := org + "/" + repo + ":" + branch
+		ps, ok := byKey[key]
+		if !ok {
+			ps = &PoolStatus{Org: org, Repo: repo, Branch: branch, PRs: []PRStatus{}}
+			byKey[key] = ps
+			keys = append(keys, key)
+		}
+		return ps
+	}
+	for _, p := range pools {
+		ps := get(p.Org, p.Repo, p.Branch)
+		ps.Action = p.Action
+		ps.Target = prNumbers(p.Target)
+		ps.Batch = prNumbers(p.BatchPending)
+		ps.Train = prNumbers(p.Train)
+		ps.Blockers = p.Blockers
+		ps.Freeze = p.Freeze
+		ps.Error = p.Error
+	}
+	for _, pr := range prs {
+		ps := get(pr.Org, pr.Repo, pr.Branch)
+		ps.PRs = append(ps.PRs, pr)
+	}
+
+	sort.Strings(keys)
+	answer := make([]PoolStatus, 0, len(keys))
+	for _, key := range keys {
+		ps := byKey[key]
+		sort.Slice(ps.PRs, func(i, j int) bool {
+			return ps.PRs[i].Number < ps.PRs[j].Number
+		})
+		answer = append(answer, *ps)
+	}
+	return answer
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Keeper</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+.success { color: #28a745; }
+.pending { color: #b08800; }
+.error, .failure { color: #cb2431; }
+</style>
+</head>
+<body>
+{{- with .Pauses }}
+<h1>Paused</h1>
+<ul>
+{{- range . }}
+<li class="pending">{{ .Component }} of {{ .Target }}: {{ .Description }}</li>
+{{- end }}
+</ul>
+{{- end }}
+<h1>Merge pools</h1>
+{{- range .Pools }}
+<h2>{{ .Org }}/{{ .Repo }}:{{ .Branch }}</h2>
+<p>
+{{- if .Action }}Action: {{ .Action }}{{ with .Target }} on {{ range $i, $n := . }}{{ if $i }}, {{ end }}#{{ $n }}{{ end }}{{ end }}.{{ end }}
+{{- with .Batch }} Running batch: {{ range $i, $n := . }}{{ if $i }}, {{ end }}#{{ $n }}{{ end }}.{{ end }}
+{{- with .Train }} Merge train: {{ range $i, $n := . }}{{ if $i }}, {{ end }}#{{ $n }}{{ end }}.{{ end }}
+{{- with .Freeze }} {{ .Description }}{{ end }}
+{{- range .Blockers }} Blocked by <a href="{{ .URL }}">#{{ .Number }} {{ .Title }}</a>.{{ end }}
+{{- with .Error }} Error: {{ . }}{{ end }}
+</p>
+<table>
+<tr><th>PR</th><th>Title</th><th>Author</th><th>In pool</th><th>Status</th></tr>
+{{- range .PRs }}
+<tr><td>#{{ .Number }}</td><td>{{ .Title }}</td><td>{{ .Author }}</td><td>{{ if .InPool }}yes{{ else }}no{{ end }}</td><td class="{{ .State }}">{{ .Reason }}</td></tr>
+{{- else }}
+<tr><td colspan="5">No open pull requests.</td></tr>
+{{- end }}
+</table>
+{{- else }}
+<p>There are no merge pools yet.</p>
+{{- end }}
+</body>
+</html>
+`))
@@ -0,0 +1,179 @@
+package keeper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/keeper"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/keeper/blockers"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	githubql "github.com/shurcooL/githubv4"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dashboardPR(number int, labels ...string) PullRequest {
+	var pr PullRequest
+	pr.Number = githubql.Int(number)
+	pr.Title = githubql.String(fmt.Sprintf("PR %d", number))
+	pr.Author.Login = "author"
+	pr.Repository.Owner.Login = "org"
+	pr.Repository.Name = "repo"
+	pr.Repository.NameWithOwner = "org/repo"
+	pr.BaseRef.Name = "master"
+	for _, label := range labels {
+		pr.Labels.Nodes = append(pr.Labels.Nodes, struct{ Name githubql.String }{Name: githubql.String(label)})
+	}
+	return pr
+}
+
+func TestPRStatus(t *testing.T) {
+	queryMap := keeper.Queries{{Orgs: []string{"org"}, Labels: []string{"approved", "lgtm"}}}.QueryMap()
+	failing := []Context{
+		{Context: "unit", State: githubql.StatusStateFailure},
+		{Context: "lint", State: githubql.StatusStatePending},
+		{Context: "e2e", State: githubql.StatusStateSuccess},
+	}
+	testcases := []struct {
+		name     string
+		pr       PullRequest
+		pool     map[string]prWithStatus
+		contexts []Context
+		desc     string
+
+		inPool bool
+		reason string
+	}{
+		{
+			name:   "missing label",
+			pr:     dashboardPR(1, "approved"),
+			desc:   fmt.Sprintf(statusNotInPool, " Needs lgtm label."),
+			reason: fmt.Sprintf(statusNotInPool, " Needs lgtm label."),
+		},
+		{
+			name:   "reason omitted from the status description",
+			pr:     dashboardPR(1, "approved"),
+			desc:   fmt.Sprintf(statusNotInPool, ""),
+			reason: fmt.Sprintf(statusNotInPool, " Needs lgtm label."),
+		},
+		{
+			name:     "in pool waiting for contexts",
+			pr:       dashboardPR(2, "approved", "lgtm"),
+			pool:     map[string]prWithStatus{"org/repo#2": {}},
+			contexts: failing,
+			desc:     statusInPool + ".",
+			inPool:   true,
+			reason:   statusInPool + ", waiting for lint, unit.",
+		},
+		{
+			name:     "in pool waiting for merge",
+			pr:       dashboardPR(3, "approved", "lgtm"),
+			pool:     map[string]prWithStatus{"org/repo#3": {success: true, waitingFor: []int{2}}},
+			contexts: failing[2:],
+			desc:     statusInPool + ", waiting for merge of PR(s) #2.",
+			inPool:   true,
+			reason:   statusInPool + ", waiting for merge of PR(s) #2.",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := tc.pr
+			status := prStatus(queryMap, &pr, tc.pool, &keeper.ContextPolicy{}, blockers.Blockers{}, nil, "", tc.contexts, scmprovider.StatusPending, tc.desc, logrus.WithField("test", tc.name))
+			assert.Equal(t, "org", status.Org)
+			assert.Equal(t, "repo", status.Repo)
+			assert.Equal(t, "master", status.Branch)
+			assert.Equal(t, int(pr.Number), status.Number)
+			assert.Equal(t, tc.inPool, status.InPool)
+			assert.Equal(t, tc.reason, status.Reason)
+		})
+	}
+}
+
+func TestSetStatusesRecordsPRStatuses(t *testing.T) {
+	ca := &config.Agent{}
+	ca.Set(&config.Config{})
+	sc := &statusController{spc: &fgc{}, config: ca.Config, logger: logrus.WithField("component", "keeper")}
+
+	inPool := dashboardPR(1)
+	inPool.Commits.Nodes = []struct{ Commit Commit }{{}}
+	notInPool := dashboardPR(2)
+	notInPool.Commits.Nodes = []struct{ Commit Commit }{{}}
+	sc.setStatuses([]PullRequest{inPool, notInPool}, map[string]prWithStatus{inPool.prKey(): {pr: inPool}}, blockers.Blockers{}, nil)
+
+	statuses := sc.statuses()
+	require.Len(t, statuses, 2)
+	assert.True(t, statuses[0].InPool)
+	assert.Equal(t, scmprovider.StatusSuccess, statuses[0].State)
+	assert.False(t, statuses[1].InPool)
+	assert.Equal(t, scmprovider.StatusPending, statuses[1].State)
+}
+
+func TestDashboard(t *testing.T) {
+	batch := []PullRequest{dashboardPR(1), dashboardPR(2)}
+	c := &DefaultController{
+		pools: []Pool{
+			{
+				Org:          "org",
+				Repo:         "repo",
+				Branch:       "master",
+				SuccessPRs:   batch,
+				BatchPending: batch,
+				Action:       Wait,
+			},
+		},
+		sc: &statusController{
+			prStatuses: []PRStatus{
+				{Org: "org", Repo: "repo", Branch: "master", Number: 3, State: scmprovider.StatusPending, Reason: fmt.Sprintf(statusNotInPool, " Needs lgtm label.")},
+				{Org: "org", Repo: "repo", Branch: "master", Number: 1, InPool: true, State: scmprovider.StatusSuccess, Reason: statusInPool + "."},
+				{Org: "org", Repo: "other", Branch: "master", Number: 7, State: scmprovider.StatusPending, Reason: fmt.Sprintf(statusNotInPool, " Job unit has not succeeded.")},
+			},
+		},
+	}
+	cfg := &config.Config{}
+	cfg.Pauses = lighthouse.Pauses{}
+	cfg.Pauses.Set(lighthouse.PauseMerges, "org/repo", lighthouse.Pause{Reason: "release", By: "alice"})
+	d := NewDashboard(c, func() *config.Config { return cfg })
+
+	expected := []PoolStatus{
+		{
+			Org:    "org",
+			Repo:   "other",
+			Branch: "master",
+			PRs:    []PRStatus{c.sc.prStatuses[2]},
+		},
+		{
+			Org:    "org",
+			Repo:   "repo",
+			Branch: "master",
+			Action: Wait,
+			Batch:  []int{1, 2},
+			PRs:    []PRStatus{c.sc.prStatuses[1], c.sc.prStatuses[0]},
+		},
+	}
+	assert.Equal(t, expected, d.PoolStatuses())
+
+	s := httptest.NewServer(http.HandlerFunc(d.ServeJSON))
+	defer s.Close()
+	resp, err := http.Get(s.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var pools []PoolStatus
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&pools))
+	assert.Equal(t, expected, pools)
+
+	rec := httptest.NewRecorder()
+	d.ServeHTML(rec, httptest.NewRequest(http.MethodGet, DashboardPath, nil))
+	body, err := ioutil.ReadAll(rec.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "<h2>org/repo:master</h2>")
+	assert.Contains(t, string(body), "Running batch: #1, #2.")
+	assert.Contains(t, string(body), "Not mergeable. Needs lgtm label.")
+	assert.Contains(t, string(body), "merges of org/repo: paused by alice: release")
+}
@@ -1,7 +1,6 @@
 package githubapp
 
 import (
-	"github.com/jenkins-x/go-scm/scm/factory"
 	"github.com/jenkins-x/lighthouse/pkg/clients"
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/git"
@@ -20,7 +19,7 @@ func NewKeeperController(configAgent *config.Agent, botName string, gitKind stri
 		return NewGitHubAppKeeperController(githubAppSecretDir, configAgent, botName, gitKind, maxRecordsPerPool, historyURI, statusURI, ns)
 	}
 
-	scmClient, err := factory.NewClient(gitKind, serverURL, "")
+	scmClient, err := scmprovider.NewSCMClient(gitKind, serverURL, "")
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot create SCM client")
 	}
@@ -92,6 +92,16 @@ func (g *gitHubAppKeeperController) GetPools() []keeper.Pool {
 	return pools
 }
 
+func (g *gitHubAppKeeperController) GetPRStatuses() []keeper.PRStatus {
+	g.m.Lock()
+	defer g.m.Unlock()
+	statuses := []keeper.PRStatus{}
+	for _, c := range g.controllers {
+		statuses = append(statuses, c.GetPRStatuses()...)
+	}
+	return statuses
+}
+
 func (g *gitHubAppKeeperController) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	pools := g.GetPools()
 	b, err := json.Marshal(pools)
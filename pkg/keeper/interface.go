@@ -12,6 +12,7 @@ type Controller interface {
 	Sync() error
 	Shutdown()
 	GetPools() []Pool
+	GetPRStatuses() []PRStatus
 	ServeHTTP(w http.ResponseWriter, r *http.Request)
 	GetHistory() *history.History
 }
@@ -41,6 +41,7 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/jobutil"
 	"github.com/jenkins-x/lighthouse/pkg/keeper/blockers"
 	"github.com/jenkins-x/lighthouse/pkg/keeper/history"
+	"github.com/jenkins-x/lighthouse/pkg/notifier"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"github.com/jenkins-x/lighthouse/pkg/triggerconfig/inrepo"
 	"github.com/jenkins-x/lighthouse/pkg/util"
@@ -65,7 +66,9 @@ type scmProviderClient interface {
 	GetCombinedStatus(org, repo, ref string) (*scm.CombinedStatus, error)
 	CreateStatus(org, repo, ref string, s *scm.StatusInput) (*scm.Status, error)
 	GetPullRequestChanges(org, repo string, number int) ([]*scm.Change, error)
+	GetPullRequest(org, repo string, number int) (*scm.PullRequest, error)
 	GetRef(string, string, string) (string, error)
+	ListCommits(string, string, scm.CommitListOptions) ([]*scm.Commit, error)
 	Merge(string, string, int, scmprovider.MergeDetails) error
 	Query(context.Context, interface{}, map[string]interface{}) error
 	SupportsGraphQL() bool
@@ -76,6 +79,10 @@ type scmProviderClient interface {
 	CreateComment(owner, repo string, number int, isPR bool, comment string) error
 	GetFile(string, string, string, string) ([]byte, error)
 	ListFiles(string, string, string, string) ([]*scm.FileEntry, error)
+	ListReviews(org, repo string, number int) ([]*scm.Review, error)
+	HasUnresolvedDiscussions(org, repo string, number int) (bool, error)
+	SupportsCheckRuns() bool
+	ListCheckRuns(owner, repo, ref, name string) ([]*scmprovider.CheckRun, error)
 }
 
 type contextChecker interface {
@@ -105,6 +112,14 @@ type DefaultController struct {
 	// Cache entries expire if they are not used during a sync loop.
 	changedFiles *changedFilesAgent
 
+	// notifier posts the merges to the configured notification routes
+	notifier *notifier.Notifier
+	// notifications tracks the merge notifications being posted so that shutting down waits for them
+	notifications sync.WaitGroup
+
+	// trains remembers the PRs merged from and evicted from the merge trains
+	trains *trainTracker
+
 	History *history.History
 }
 
@@ -119,6 +134,8 @@ const (
 	TriggerBatch        = "TRIGGER_BATCH"
 	Merge               = "MERGE"
 	MergeBatch          = "MERGE_BATCH"
+	TriggerTrain        = "TRIGGER_TRAIN"
+	MergeTrain          = "MERGE_TRAIN"
 	PoolBlocked         = "BLOCKED"
 )
 
@@ -129,6 +146,8 @@ var recordableActions = map[Action]bool{
 	TriggerBatch: true,
 	Merge:        true,
 	MergeBatch:   true,
+	TriggerTrain: true,
+	MergeTrain:   true,
 }
 
 // Pool represents information about a keeper pool. There is one for every
@@ -147,11 +166,14 @@ type Pool struct {
 
 	// Empty if there is no pending batch.
 	BatchPending []PullRequest
+	// Train lists the PRs queued in the merge train of the branch, in order.
+	Train []PullRequest
 
 	// Which action did we last take, and to what target(s), if any.
 	Action   Action
 	Target   []PullRequest
 	Blockers []blockers.Blocker
+	Freeze   *keeper.BranchFreeze
 	Error    string
 }
 
@@ -161,6 +183,8 @@ type prWithStatus struct {
 	waitingFor      []int
 	waitingForBatch []int
 	blocks          []blockers.Blocker
+	// trainPosition is the position of the PR in the merge train of its branch, from 1, or 0 if it is not in a train
+	trainPosition int
 }
 
 // Prometheus Metrics
@@ -254,7 +278,9 @@ func NewController(spcSync, spcStatus *scmprovider.Client, launcherClient launch
 			spc:             spcSync,
 			nextChangeCache: make(map[changeCacheKey][]string),
 		},
-		History: hist,
+		notifier: notifier.New(cfg),
+		trains:   newTrainTracker(),
+		History:  hist,
 	}, nil
 }
 
@@ -268,6 +294,7 @@ func (c *DefaultController) Shutdown() {
 	}
 	c.History.Flush()
 	c.sc.shutdown()
+	c.notifications.Wait()
 }
 
 // GetHistory returns the history
@@ -337,6 +364,13 @@ func (c *DefaultController) Sync() error {
 			prs[p.prKey()] = pr
 		}
 	}
+	for key, pr := range prs {
+		p := pr
+		if err := addCheckRunContexts(c.logger.WithFields(p.logFields()), c.spc, &p); err != nil {
+			c.logger.WithFields(p.logFields()).WithError(err).Warn("Failed to add the check runs to the head contexts.")
+		}
+		prs[key] = p
+	}
 	c.logger.WithField(
 		"duration", time.Since(start).String(),
 	).Debugf("Found %d (unfiltered) pool PRs.", len(prs))
@@ -371,12 +405,19 @@ func (c *DefaultController) Sync() error {
 			}
 		}
 	}
+	c.trains.prune(prs)
 	// Partition PRs into subpools and filter out non-pool PRs.
 	rawPools, err := c.dividePool(prs, lhjs)
 	if err != nil {
 		return err
 	}
 	filteredPools := c.filterSubpools(c.config().Keeper.MaxGoroutines, rawPools)
+	unmetReviews := make(map[string]string)
+	for _, sp := range rawPools {
+		for key, desc := range sp.unmetReviews {
+			unmetReviews[key] = desc
+		}
+	}
 
 	// Sync subpools in parallel.
 	poolChan := make(chan Pool, len(filteredPools))
@@ -403,6 +444,7 @@ func (c *DefaultController) Sync() error {
 	// Notify statusController about the new pool.
 	c.sc.Lock()
 	c.sc.blocks = blocks
+	c.sc.unmetReviews = unmetReviews
 	c.sc.poolPRs = poolsToStatusPRMap(pools)
 	select {
 	case c.sc.newPoolPending <- true:
@@ -442,7 +484,7 @@ func (p *Pool) toPRsWithStatus() map[string]prWithStatus {
 
 	targets := make(map[int]bool)
 
-	if p.Action == Merge || p.Action == MergeBatch {
+	if p.Action == Merge || p.Action == MergeBatch || p.Action == MergeTrain {
 		for _, t := range p.Target {
 			targets[int(t.Number)] = true
 		}
@@ -495,6 +537,13 @@ func (p *Pool) toPRsWithStatus() map[string]prWithStatus {
 		result[s.prKey()] = out
 	}
 
+	for i, t := range p.Train {
+		if out, ok := result[t.prKey()]; ok {
+			out.trainPosition = i + 1
+			result[t.prKey()] = out
+		}
+	}
+
 	return result
 }
 
@@ -522,6 +571,11 @@ func (c *DefaultController) GetPools() []Pool {
 	return answer
 }
 
+// GetPRStatuses returns the statuses of the open PRs
+func (c *DefaultController) GetPRStatuses() []PRStatus {
+	return c.sc.statuses()
+}
+
 func subpoolsInParallel(goroutines int, sps map[string]*subpool, process func(*subpool)) {
 	// Load the subpools into a channel for use as a work queue.
 	queue := make(chan *subpool, len(sps))
@@ -557,6 +611,10 @@ func (c *DefaultController) filterSubpools(goroutines int, raw map[string]*subpo
 		goroutines,
 		raw,
 		func(sp *subpool) {
+			if !c.config().RepoEnabled(sp.org, sp.repo) {
+				sp.log.Debug("ignoring sub-pool as the repository is excluded by the repo_filter")
+				return
+			}
 			if err := c.initSubpoolData(sp); err != nil {
 				sp.log.WithError(err).Error("Error initializing subpool.")
 				return
@@ -586,6 +644,7 @@ func (c *DefaultController) initSubpoolData(sp *subpool) error {
 	if err != nil {
 		return fmt.Errorf("error setting up context checker: %v", err)
 	}
+	sp.reviews = c.config().Keeper.ReviewRequirements(sp.org, sp.repo)
 	return nil
 }
 
@@ -617,6 +676,8 @@ func filterSubpool(spc scmProviderClient, sp *subpool) *subpool {
 //   status is preventing merge. Required PipelineActivity statuses are allowed to be
 //   'pending' because this prevents kicking PRs from the pool when Keeper is
 //   retesting them.)
+// - Do not meet the review requirements of their repo. The unmet requirements are
+//   recorded in the subpool so that the status context can describe them.
 func filterPR(spc scmProviderClient, sp *subpool, pr *PullRequest) bool {
 	log := sp.log.WithFields(pr.logFields())
 	// Skip PRs that are known to be unmergeable.
@@ -650,9 +711,47 @@ func filterPR(spc scmProviderClient, sp *subpool, pr *PullRequest) bool {
 		}
 	}
 
+	unmet, err := unmetReviewRequirements(spc, sp, pr)
+	if err != nil {
+		log.WithError(err).Error("Checking review requirements.")
+		return true
+	}
+	if len(unmet) > 0 {
+		log.WithField("requirements", unmet).Debug("filtering out PR as its review requirements are not met")
+		if sp.unmetReviews == nil {
+			sp.unmetReviews = map[string]string{}
+		}
+		sp.unmetReviews[pr.prKey()] = strings.Join(unmet, " ")
+		return true
+	}
+
 	return false
 }
 
+// unmetReviewRequirements describes the review requirements of the subpool the PR does not meet
+func unmetReviewRequirements(spc scmProviderClient, sp *subpool, pr *PullRequest) ([]string, error) {
+	var approvers, changesRequestedBy []string
+	if sp.reviews.NeedsReviews() {
+		reviews, err := spc.ListReviews(sp.org, sp.repo, int(pr.Number))
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing the reviews of PR %d", pr.Number)
+		}
+		approvers, changesRequestedBy = scmprovider.ReviewersByState(reviews)
+	}
+	unresolved := false
+	if sp.reviews.ResolvedDiscussions {
+		var err error
+		unresolved, err = spc.HasUnresolvedDiscussions(sp.org, sp.repo, int(pr.Number))
+		// providers without resolvable discussions have none left unresolved
+		if err == scm.ErrNotSupported {
+			unresolved = false
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "checking the discussions of PR %d", pr.Number)
+		}
+	}
+	return sp.reviews.Unmet(approvers, changesRequestedBy, unresolved), nil
+}
+
 type simpleState string
 
 const (
@@ -755,7 +854,7 @@ func accumulateBatch(presubmits map[int][]job.Presubmit, prs []PullRequest, pjs
 	}
 	states := make(map[string]*accState)
 	for _, pj := range pjs {
-		if pj.Spec.Type != job.BatchJob {
+		if pj.Spec.Type != job.BatchJob || isTrainCar(&pj) {
 			continue
 		}
 		// First validate the batch job's refs.
@@ -834,7 +933,7 @@ func accumulate(presubmits map[int][]job.Presubmit, prs []PullRequest, pjs []v1a
 	missingTests = map[int][]job.Presubmit{}
 	for _, pr := range prs {
 		// Accumulate the best result for each job (Passing > Pending > Failing/Unknown)
-		// We can ignore the baseSHA here because the subPool only contains PipelineActivitys with the correct baseSHA
+		// We can ignore the baseSHA here because the subPool only contains PipelineActivitys with a recent enough baseSHA
 		psStates := make(map[string]simpleState)
 		for _, pj := range pjs {
 			if pj.Spec.Type != job.PresubmitJob {
@@ -896,6 +995,15 @@ func prNumbers(prs []PullRequest) []int {
 	return nums
 }
 
+func hasLabel(pr PullRequest, label string) bool {
+	for _, l := range pr.Labels.Nodes {
+		if string(l.Name) == label {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *DefaultController) pickBatch(sp subpool, cc contextChecker) ([]PullRequest, error) {
 	batchLimit := c.config().Keeper.BatchSizeLimit(sp.org, sp.repo)
 	if batchLimit < 0 {
@@ -918,23 +1026,11 @@ func (c *DefaultController) pickBatch(sp subpool, cc contextChecker) ([]PullRequ
 	}
 	sp.log.Debugf("of %d possible PRs, %d are passing tests", len(sp.prs), len(candidates))
 
-	r, err := c.gc.Clone(sp.org + "/" + sp.repo)
+	r, err := c.checkoutBase(sp)
 	if err != nil {
 		return nil, err
 	}
 	defer r.Clean()
-	if err := r.Config("user.name", "prow"); err != nil {
-		return nil, err
-	}
-	if err := r.Config("user.email", "prow@localhost"); err != nil {
-		return nil, err
-	}
-	if err := r.Config("commit.gpgsign", "false"); err != nil {
-		sp.log.Warningf("Cannot set gpgsign=false in gitconfig: %v", err)
-	}
-	if err := r.Checkout(sp.sha); err != nil {
-		return nil, err
-	}
 
 	var res []PullRequest
 	for _, pr := range candidates {
@@ -953,6 +1049,40 @@ func (c *DefaultController) pickBatch(sp subpool, cc contextChecker) ([]PullRequ
 	return res, nil
 }
 
+// checkoutBase clones the repository of the subpool and checks out its base to test merging PRs into it.
+// The caller must clean the clone.
+func (c *DefaultController) checkoutBase(sp subpool) (*git.Repo, error) {
+	r, err := c.clone(sp.org, sp.repo, sp.log)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Checkout(sp.sha); err != nil {
+		r.Clean()
+		return nil, err
+	}
+	return r, nil
+}
+
+// clone clones the repo, configured to commit merges
+func (c *DefaultController) clone(org, repo string, log *logrus.Entry) (*git.Repo, error) {
+	r, err := c.gc.Clone(org + "/" + repo)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Config("user.name", "prow"); err != nil {
+		r.Clean()
+		return nil, err
+	}
+	if err := r.Config("user.email", "prow@localhost"); err != nil {
+		r.Clean()
+		return nil, err
+	}
+	if err := r.Config("commit.gpgsign", "false"); err != nil {
+		log.Warningf("Cannot set gpgsign=false in gitconfig: %v", err)
+	}
+	return r, nil
+}
+
 func checkMergeLabels(pr PullRequest, squash, rebase, merge string, method keeper.PullRequestMergeType) (keeper.PullRequestMergeType, error) {
 	labelCount := 0
 	for _, prlabel := range pr.Labels.Nodes {
@@ -1003,6 +1133,34 @@ func (c *DefaultController) prepareMergeDetails(commitTemplates keeper.MergeComm
 	return ghMergeDetails
 }
 
+// notifyMerge posts a merged PR to the notification routes in the background, so that slow routes do not delay the
+// next merges, with the SHA of the commit the PR was merged as
+func (c *DefaultController) notifyMerge(sp subpool, pr PullRequest, log *logrus.Entry) {
+	if !c.notifier.Enabled() {
+		return
+	}
+	c.notifications.Add(1)
+	go func() {
+		defer c.notifications.Done()
+		merge := notifier.Merge{
+			Org:     sp.org,
+			Repo:    sp.repo,
+			Number:  int(pr.Number),
+			Title:   string(pr.Title),
+			Author:  string(pr.Author.Login),
+			BaseRef: sp.branch,
+			SHA:     string(pr.HeadRefOID),
+		}
+		// the head of the PR is only the merged commit when it is fast forwarded
+		if merged, err := c.spc.GetPullRequest(sp.org, sp.repo, int(pr.Number)); err != nil {
+			log.WithError(err).Warn("Failed to get the merge commit of the PR, notifying its head instead.")
+		} else if merged.MergeSha != "" {
+			merge.SHA = merged.MergeSha
+		}
+		c.notifier.NotifyMerge(merge)
+	}()
+}
+
 func (c *DefaultController) mergePRs(sp subpool, prs []PullRequest) error {
 	var merged, failed []int
 	var failedPRs []PullRequest
@@ -1046,6 +1204,7 @@ func (c *DefaultController) mergePRs(sp subpool, prs []PullRequest) error {
 		} else {
 			log.Info("Merged.")
 			merged = append(merged, int(pr.Number))
+			c.notifyMerge(sp, pr, log)
 		}
 		if !keepTrying {
 			break
@@ -1164,7 +1323,8 @@ func tryMerge(mergeFunc func() error) (bool, error) {
 	return true, err
 }
 
-func (c *DefaultController) trigger(sp subpool, presubmits map[int][]job.Presubmit, prs []PullRequest) error {
+// refs returns the refs testing the PRs merged into the base of the subpool
+func (c *DefaultController) refs(sp subpool, prs []PullRequest) v1alpha1.Refs {
 	refs := v1alpha1.Refs{
 		Org:      sp.org,
 		Repo:     sp.repo,
@@ -1183,6 +1343,11 @@ func (c *DefaultController) trigger(sp subpool, presubmits map[int][]job.Presubm
 			},
 		)
 	}
+	return refs
+}
+
+func (c *DefaultController) trigger(sp subpool, presubmits map[int][]job.Presubmit, prs []PullRequest) error {
+	refs := c.refs(sp, prs)
 
 	// If PRs require the same job, we only want to trigger it once.
 	// If multiple required jobs have the same context, we assume the
@@ -1388,6 +1553,28 @@ func (c *DefaultController) commentOnPRsWithFailedMerge(prs []PullRequest, error
 }
 
 func (c *DefaultController) syncSubpool(sp subpool, blocks []blockers.Blocker) (Pool, error) {
+	freeze := c.config().FreezeFor(sp.org, sp.repo, sp.branch, time.Now())
+	// the merge override label overrides the freezes, not the other merge-blocker issues
+	frozen, blocked := freeze != nil, false
+	for _, b := range blocks {
+		if b.IsFreeze() {
+			frozen = true
+		} else {
+			blocked = true
+		}
+	}
+	if frozen && !blocked {
+		// only the PRs with the merge override label can be merged into a frozen branch
+		overrideLabel := c.config().Keeper.MergeOverrideLabel
+		var overrides []PullRequest
+		for _, pr := range sp.prs {
+			if hasLabel(pr, overrideLabel) {
+				overrides = append(overrides, pr)
+			}
+		}
+		sp.log.WithField("merge-override-prs", prNumbers(overrides)).Info("Subpool is frozen.")
+		sp.prs = overrides
+	}
 	sp.log.Infof("Syncing subpool: %d PRs, %d PJs.", len(sp.prs), len(sp.ljs))
 	successes, pendings, missings, missingSerialTests := accumulate(sp.presubmits, sp.prs, sp.ljs, sp.log)
 	batchMerge, batchPending := accumulateBatch(sp.presubmits, sp.prs, sp.ljs, sp.log)
@@ -1400,13 +1587,17 @@ func (c *DefaultController) syncSubpool(sp subpool, blocks []blockers.Blocker) (
 	}).Info("Subpool accumulated.")
 
 	var act Action
-	var targets []PullRequest
+	var targets, train []PullRequest
 	var err error
 	var errorString string
-	if len(blocks) > 0 {
+	if blocked || (frozen && len(sp.prs) == 0) {
 		act = PoolBlocked
 	} else {
-		act, targets, err = c.takeAction(sp, batchPending, successes, pendings, missings, batchMerge, missingSerialTests)
+		if size := c.config().Keeper.MergeTrainSize(sp.org, sp.repo); size > 0 && len(sp.presubmits) > 0 {
+			act, targets, train, err = c.takeTrainAction(sp, successes, size)
+		} else {
+			act, targets, err = c.takeAction(sp, batchPending, successes, pendings, missings, batchMerge, missingSerialTests)
+		}
 		if err != nil {
 			errorString = err.Error()
 		}
@@ -1437,10 +1628,12 @@ func (c *DefaultController) syncSubpool(sp subpool, blocks []blockers.Blocker) (
 			MissingPRs: missings,
 
 			BatchPending: batchPending,
+			Train:        train,
 
 			Action:   act,
 			Target:   targets,
 			Blockers: blocks,
+			Freeze:   freeze,
 			Error:    errorString,
 		},
 		err
@@ -1491,14 +1684,27 @@ type subpool struct {
 	sha string
 
 	// ljs contains all LighthouseJobs of type Presubmit or Batch
-	// that have the same baseSHA as the subpool
+	// that have the same baseSHA as the subpool, plus any Presubmits
+	// whose baseSHA is within the configured max commits behind
 	ljs []v1alpha1.LighthouseJob
+	// recentSHAs contains the most recent commits on the branch, lazily
+	// populated when checking how far behind a presubmit's baseSHA is
+	recentSHAs map[string]bool
 	prs []PullRequest
 
 	cc contextChecker
 	// presubmit contains all required presubmits for each PR
 	// in this subpool
 	presubmits map[int][]job.Presubmit
+
+	reviews keeper.ReviewRequirements
+	// unmetReviews describes the unmet review requirements of the PRs
+	// filtered out of the subpool, by PR key
+	unmetReviews map[string]string
+
+	// merged are the PRs of the merge train merged into the base, if the
+	// base was advanced by the train
+	merged *mergedCars
 }
 
 func poolKey(org, repo, branch string) string {
@@ -1523,6 +1729,8 @@ func (c *DefaultController) dividePool(pool map[string]PullRequest, pjs []v1alph
 		}
 		fn := poolKey(org, repo, branch)
 		if sps[fn] == nil {
+			// keeper does not get the push events so it resolves the heads without caching them: a stale head would
+			// let it merge PRs tested against an older base
 			sha, err := c.spc.GetRef(org, repo, strings.TrimPrefix(branchRef, "refs/"))
 			if err != nil {
 				return nil, err
@@ -1540,6 +1748,13 @@ func (c *DefaultController) dividePool(pool map[string]PullRequest, pjs []v1alph
 				branch:   branch,
 				sha:      sha,
 				cloneURL: cloneURL,
+				merged: c.trains.mergedInto(fn, sha, func(m *mergedCars) bool {
+					ok, err := c.isTrainHead(org, repo, m, sha, c.logger)
+					if err != nil {
+						c.logger.WithError(err).WithField("pool", fn).Warn("Failed to verify the head of the branch after the merge train merges.")
+					}
+					return ok
+				}),
 			}
 		}
 		sps[fn].prs = append(sps[fn].prs, pr)
@@ -1549,7 +1764,12 @@ func (c *DefaultController) dividePool(pool map[string]PullRequest, pjs []v1alph
 			continue
 		}
 		fn := poolKey(pj.Spec.Refs.Org, pj.Spec.Refs.Repo, pj.Spec.Refs.BaseRef)
-		if sps[fn] == nil || pj.Spec.Refs.BaseSHA != sps[fn].sha {
+		if sps[fn] == nil {
+			continue
+		}
+		// Batches are merged as a whole so must always have run against the current base,
+		// or against the previous base for the cars of a train behind the PRs merged since
+		if pj.Spec.Refs.BaseSHA != sps[fn].sha && !sps[fn].merged.carries(&pj) && (pj.Spec.Type != job.PresubmitJob || !c.isRecentBaseSHA(sps[fn], pj.Spec.Refs.BaseSHA)) {
 			continue
 		}
 		sps[fn].ljs = append(sps[fn].ljs, pj)
@@ -1557,6 +1777,44 @@ func (c *DefaultController) dividePool(pool map[string]PullRequest, pjs []v1alph
 	return sps, nil
 }
 
+// commitsPageSize is the largest number of commits the providers return per page, e.g. 100 on GitHub
+const commitsPageSize = 100
+
+// isRecentBaseSHA returns true if the sha is within the configured number of commits
+// behind the current HEAD of the subpool's branch, so that presubmits which ran against
+// it do not need to be retested before merging.
+func (c *DefaultController) isRecentBaseSHA(sp *subpool, sha string) bool {
+	maxBehind := c.config().Keeper.MaxCommitsBehind(sp.org, sp.repo)
+	if maxBehind <= 0 {
+		return false
+	}
+	if sp.recentSHAs == nil {
+		sp.recentSHAs = map[string]bool{}
+		// the providers return a limited number of commits per page so the recent commits are listed page by page
+		size := maxBehind + 1
+		if size > commitsPageSize {
+			size = commitsPageSize
+		}
+		for page := 1; len(sp.recentSHAs) <= maxBehind; page++ {
+			commits, err := c.spc.ListCommits(sp.org, sp.repo, scm.CommitListOptions{Ref: sp.sha, Sha: sp.sha, Page: page, Size: size})
+			if err != nil {
+				sp.log.WithError(err).Warn("Failed to list recent commits, presubmits must be retested against the current base.")
+				break
+			}
+			for _, commit := range commits {
+				if len(sp.recentSHAs) > maxBehind {
+					break
+				}
+				sp.recentSHAs[commit.Sha] = true
+			}
+			if len(commits) < size {
+				break
+			}
+		}
+	}
+	return sp.recentSHAs[sha]
+}
+
 // PullRequest holds graphql data about a PR, including its commits and their contexts.
 type PullRequest struct {
 	Number githubql.Int
@@ -1699,6 +1957,48 @@ func headContexts(log *logrus.Entry, spc scmProviderClient, pr *PullRequest) ([]
 	return contexts, nil
 }
 
+// addCheckRunContexts adds the check runs of the head commit of the PR to its contexts, as the jobs reporting their
+// results as check runs have no commit status. They are added once per search so that the check runs are not listed
+// every time the contexts are needed. A commit status takes precedence over a check run with the same name.
+func addCheckRunContexts(log *logrus.Entry, spc scmProviderClient, pr *PullRequest) error {
+	if !spc.SupportsCheckRuns() {
+		return nil
+	}
+	contexts, err := headContexts(log, spc, pr)
+	if err != nil {
+		return err
+	}
+	runs, err := spc.ListCheckRuns(string(pr.Repository.Owner.Login), string(pr.Repository.Name), string(pr.HeadRefOID), "")
+	if err != nil {
+		return fmt.Errorf("failed to list the check runs: %v", err)
+	}
+	reported := sets.NewString()
+	for _, ctx := range contexts {
+		reported.Insert(string(ctx.Context))
+	}
+	for _, run := range runs {
+		if reported.Has(run.Name) {
+			continue
+		}
+		desc := ""
+		if run.Output != nil {
+			desc = run.Output.Title
+		}
+		contexts = append(contexts, Context{
+			Context:     githubql.String(run.Name),
+			Description: githubql.String(desc),
+			State:       githubql.StatusState(strings.ToUpper(run.State().String())),
+		})
+		reported.Insert(run.Name)
+	}
+	for i := range pr.Commits.Nodes {
+		if pr.Commits.Nodes[i].Commit.OID == pr.HeadRefOID {
+			pr.Commits.Nodes[i].Commit.Status.Contexts = contexts
+		}
+	}
+	return nil
+}
+
 func orgRepoQueryString(orgs, repos []string, orgExceptions map[string]sets.String) string {
 	toks := make([]string, 0, len(orgs))
 	for _, o := range orgs {
@@ -35,10 +35,14 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/fake"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
 	"github.com/jenkins-x/lighthouse/pkg/config/keeper"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/keeper/blockers"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	githubql "github.com/shurcooL/githubv4"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	tektonfake "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
@@ -48,6 +52,7 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/git/localgit"
 	"github.com/jenkins-x/lighthouse/pkg/keeper/history"
 	launcherfake "github.com/jenkins-x/lighthouse/pkg/launcher/fake"
+	"github.com/jenkins-x/lighthouse/pkg/notifier"
 )
 
 func testPullsMatchList(t *testing.T, test string, actual []PullRequest, expected []int) {
@@ -506,11 +511,16 @@ type fgc struct {
 	setStatus        bool
 	mergeErrs        map[int]error
 	mergeErrComments map[int]string
+	mergeSHAs        map[int]string
 
 	expectedSHA    string
 	ignoreExpected bool
 	combinedStatus map[string]map[string]commitStatus
 	fakeClient     *scm.Client
+	commits        map[string][]*scm.Commit
+	reviews        map[int][]*scm.Review
+	unresolved     map[int]bool
+	checkRuns      map[string][]*scmprovider.CheckRun
 }
 
 type commitStatus struct {
@@ -529,6 +539,22 @@ func (f *fgc) SupportsGraphQL() bool {
 	return true
 }
 
+func (f *fgc) ListReviews(org, repo string, number int) ([]*scm.Review, error) {
+	return f.reviews[number], nil
+}
+
+func (f *fgc) HasUnresolvedDiscussions(org, repo string, number int) (bool, error) {
+	return f.unresolved[number], nil
+}
+
+func (f *fgc) SupportsCheckRuns() bool {
+	return true
+}
+
+func (f *fgc) ListCheckRuns(owner, repo, ref, name string) ([]*scmprovider.CheckRun, error) {
+	return f.checkRuns[ref], nil
+}
+
 func (f *fgc) ProviderType() string {
 	return "fake"
 }
@@ -545,6 +571,28 @@ func (f *fgc) ListAllPullRequestsForFullNameRepo(string, scm.PullRequestListOpti
 	return nil, scm.ErrNotSupported
 }
 
+func (f *fgc) ListCommits(o, r string, opts scm.CommitListOptions) ([]*scm.Commit, error) {
+	commits := f.commits[o+"/"+r]
+	// like GitHub, at most 100 commits are returned per page
+	if opts.Size > 100 {
+		opts.Size = 100
+	}
+	if opts.Size > 0 {
+		start := opts.Size * (opts.Page - 1)
+		if opts.Page <= 1 {
+			start = 0
+		}
+		if start > len(commits) {
+			start = len(commits)
+		}
+		commits = commits[start:]
+		if len(commits) > opts.Size {
+			commits = commits[:opts.Size]
+		}
+	}
+	return commits, nil
+}
+
 func (f *fgc) GetRef(o, r, ref string) (string, error) {
 	return f.refs[o+"/"+r+" "+ref], nil
 }
@@ -573,6 +621,10 @@ func (f *fgc) CreateComment(org, repo string, number int, isPR bool, commentBody
 	return nil
 }
 
+func (f *fgc) GetPullRequest(org, repo string, number int) (*scm.PullRequest, error) {
+	return &scm.PullRequest{Number: number, MergeSha: f.mergeSHAs[number]}, nil
+}
+
 func (f *fgc) Merge(org, repo string, number int, details scmprovider.MergeDetails) error {
 	if err, ok := f.mergeErrs[number]; ok {
 		return err
@@ -751,9 +803,12 @@ func TestDividePool(t *testing.T) {
 	fc := &fgc{
 		refs: map[string]string{"k/t-i heads/master": "123"},
 	}
+	ca := &config.Agent{}
+	ca.Set(&config.Config{})
 	c := &DefaultController{
 		spc:    fc,
 		logger: logrus.WithField("component", "keeper"),
+		config: ca.Config,
 	}
 	pulls := make(map[string]PullRequest)
 	for _, p := range testPulls {
@@ -811,6 +866,112 @@ func TestDividePool(t *testing.T) {
 	}
 }
 
+func TestDividePoolMaxCommitsBehind(t *testing.T) {
+	testCases := []struct {
+		name        string
+		maxBehind   int
+		jobType     job.PipelineKind
+		baseSHA     string
+		expectedJob bool
+	}{
+		{
+			name:        "current base",
+			jobType:     job.PresubmitJob,
+			baseSHA:     "head",
+			expectedJob: true,
+		},
+		{
+			name:    "stale base without threshold",
+			jobType: job.PresubmitJob,
+			baseSHA: "head~1",
+		},
+		{
+			name:        "stale base within threshold",
+			maxBehind:   2,
+			jobType:     job.PresubmitJob,
+			baseSHA:     "head~2",
+			expectedJob: true,
+		},
+		{
+			name:      "stale base beyond threshold",
+			maxBehind: 2,
+			jobType:   job.PresubmitJob,
+			baseSHA:   "head~3",
+		},
+		{
+			name:        "stale base within threshold beyond a page of commits",
+			maxBehind:   150,
+			jobType:     job.PresubmitJob,
+			baseSHA:     "head~150",
+			expectedJob: true,
+		},
+		{
+			name:      "stale base beyond threshold beyond a page of commits",
+			maxBehind: 150,
+			jobType:   job.PresubmitJob,
+			baseSHA:   "head~151",
+		},
+		{
+			name:      "stale batch within threshold",
+			maxBehind: 2,
+			jobType:   job.BatchJob,
+			baseSHA:   "head~1",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			commits := []*scm.Commit{{Sha: "head"}}
+			for i := 1; i < 200; i++ {
+				commits = append(commits, &scm.Commit{Sha: fmt.Sprintf("head~%d", i)})
+			}
+			fc := &fgc{
+				refs:    map[string]string{"k/k heads/master": "head"},
+				commits: map[string][]*scm.Commit{"k/k": commits},
+			}
+			ca := &config.Agent{}
+			ca.Set(&config.Config{
+				ProwConfig: config.ProwConfig{
+					Keeper: keeper.Config{
+						MaxCommitsBehindMap: map[string]int{"k/k": tc.maxBehind},
+					},
+				},
+			})
+			c := &DefaultController{
+				spc:    fc,
+				logger: logrus.WithField("component", "keeper"),
+				config: ca.Config,
+			}
+			pr := PullRequest{Number: 1}
+			pr.BaseRef.Name = "master"
+			pr.BaseRef.Prefix = "refs/heads/"
+			pr.Repository.Name = "k"
+			pr.Repository.Owner.Login = "k"
+			pr.Repository.URL = "https://github.com/k/k.git"
+			pjs := []v1alpha1.LighthouseJob{{
+				Spec: v1alpha1.LighthouseJobSpec{
+					Type: tc.jobType,
+					Refs: &v1alpha1.Refs{
+						Org:     "k",
+						Repo:    "k",
+						BaseRef: "master",
+						BaseSHA: tc.baseSHA,
+					},
+				},
+			}}
+
+			sps, err := c.dividePool(map[string]PullRequest{pr.prKey(): pr}, pjs)
+			if err != nil {
+				t.Fatalf("Error dividing pool: %v", err)
+			}
+			sp := sps[poolKey("k", "k", "master")]
+			if sp == nil {
+				t.Fatal("Missing subpool.")
+			}
+			assert.Equal(t, tc.expectedJob, len(sp.ljs) == 1)
+		})
+	}
+}
+
 func TestPickBatch(t *testing.T) {
 	// TODO: Remove once #564 is fixed and batch builds can work again. (APB)
 	t.Skip("Skipping TestPickBatch until #564 is fixed and batch builds can work again")
@@ -1636,10 +1797,14 @@ func TestSync(t *testing.T) {
 	unmergeableA := testPR("org", "repo", "A", 6, githubql.MergeableStateConflicting)
 	unmergeableB := testPR("org", "repo", "B", 7, githubql.MergeableStateConflicting)
 	unknownA := testPR("org", "repo", "A", 8, githubql.MergeableStateUnknown)
+	overrideA := testPR("org", "repo", "A", 9, githubql.MergeableStateMergeable)
+	overrideA.Labels.Nodes = append(overrideA.Labels.Nodes, struct{ Name githubql.String }{Name: githubql.String(labels.MergeOverride)})
+	freezeA := keeper.BranchFreeze{Repos: []string{"org"}, Branches: []string{"A"}, Reason: "release"}
 
 	testcases := []struct {
-		name string
-		prs  []PullRequest
+		name    string
+		prs     []PullRequest
+		freezes []keeper.BranchFreeze
 
 		expectedPools []Pool
 	}{
@@ -1713,6 +1878,32 @@ func TestSync(t *testing.T) {
 				Target:     []PullRequest{mergeableA},
 			}},
 		},
+		{
+			name:    "1 mergeable PR on a frozen branch",
+			prs:     []PullRequest{mergeableA},
+			freezes: []keeper.BranchFreeze{freezeA},
+			expectedPools: []Pool{{
+				Org:    "org",
+				Repo:   "repo",
+				Branch: "A",
+				Action: PoolBlocked,
+				Freeze: &freezeA,
+			}},
+		},
+		{
+			name:    "1 mergeable PR with the merge override label on a frozen branch",
+			prs:     []PullRequest{mergeableA, overrideA},
+			freezes: []keeper.BranchFreeze{freezeA},
+			expectedPools: []Pool{{
+				Org:        "org",
+				Repo:       "repo",
+				Branch:     "A",
+				SuccessPRs: []PullRequest{overrideA},
+				Action:     Merge,
+				Target:     []PullRequest{overrideA},
+				Freeze:     &freezeA,
+			}},
+		},
 	}
 
 	for _, tc := range testcases {
@@ -1728,6 +1919,8 @@ func TestSync(t *testing.T) {
 						Queries:            []keeper.Query{{}},
 						MaxGoroutines:      4,
 						StatusUpdatePeriod: time.Second * 0,
+						Freezes:            tc.freezes,
+						MergeOverrideLabel: labels.MergeOverride,
 					},
 				},
 			})
@@ -1783,6 +1976,140 @@ func TestSync(t *testing.T) {
 	}
 }
 
+func TestSyncSubpoolMergeOverride(t *testing.T) {
+	mergeable := testPR("org", "repo", "A", 5, githubql.MergeableStateMergeable)
+	override := testPR("org", "repo", "A", 9, githubql.MergeableStateMergeable)
+	override.Labels.Nodes = append(override.Labels.Nodes, struct{ Name githubql.String }{Name: githubql.String(labels.MergeOverride)})
+	freezeIssue := blockers.Blocker{Number: 1, Title: "Merge freeze branch:A"}
+	outageIssue := blockers.Blocker{Number: 2, Title: "Outage branch:A"}
+
+	testcases := []struct {
+		name   string
+		blocks []blockers.Blocker
+
+		expectedAction Action
+		expectedTarget []int
+	}{
+		{
+			name:           "the override label overrides freezes",
+			blocks:         []blockers.Blocker{freezeIssue},
+			expectedAction: Merge,
+			expectedTarget: []int{9},
+		},
+		{
+			name:           "the override label does not override the other merge-blocker issues",
+			blocks:         []blockers.Blocker{outageIssue},
+			expectedAction: PoolBlocked,
+		},
+		{
+			name:           "the override label does not override the other merge-blocker issues of frozen branches",
+			blocks:         []blockers.Blocker{freezeIssue, outageIssue},
+			expectedAction: PoolBlocked,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ca := &config.Agent{}
+			ca.Set(&config.Config{
+				ProwConfig: config.ProwConfig{
+					Keeper: keeper.Config{MergeOverrideLabel: labels.MergeOverride},
+				},
+			})
+			hist, err := history.New(100, "")
+			require.NoError(t, err)
+			c := &DefaultController{
+				logger:  logrus.WithField("controller", "keeper"),
+				config:  ca.Config,
+				spc:     &fgc{},
+				History: hist,
+			}
+			sp := subpool{
+				log:    logrus.WithField("component", "keeper"),
+				cc:     &keeper.ContextPolicy{},
+				org:    "org",
+				repo:   "repo",
+				branch: "A",
+				prs:    []PullRequest{mergeable, override},
+			}
+			pool, err := c.syncSubpool(sp, tc.blocks)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedAction, pool.Action)
+			assert.Equal(t, tc.expectedTarget, prNumbers(pool.Target))
+		})
+	}
+}
+
+func TestSyncCheckRuns(t *testing.T) {
+	testcases := []struct {
+		name       string
+		conclusion string
+
+		expectedMerged int
+	}{
+		{
+			name:           "merges a PR whose required context is only reported as a successful check run",
+			conclusion:     scmprovider.CheckRunConclusionSuccess,
+			expectedMerged: 1,
+		},
+		{
+			name:       "does not merge a PR whose required context is only reported as a failed check run",
+			conclusion: scmprovider.CheckRunConclusionFailure,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := testPR("org", "repo", "A", 5, githubql.MergeableStateMergeable)
+			fgc := &fgc{
+				prs: []PullRequest{pr},
+				checkRuns: map[string][]*scmprovider.CheckRun{
+					"SHA": {{Name: "pr-build", HeadSHA: "SHA", Status: scmprovider.CheckRunStatusCompleted, Conclusion: tc.conclusion}},
+				},
+			}
+			ca := &config.Agent{}
+			ca.Set(&config.Config{
+				ProwConfig: config.ProwConfig{
+					Keeper: keeper.Config{
+						Queries:       []keeper.Query{{}},
+						MaxGoroutines: 4,
+						ContextOptions: keeper.ContextPolicyOptions{
+							ContextPolicy: keeper.ContextPolicy{RequiredContexts: []string{"pr-build"}},
+						},
+					},
+				},
+			})
+			hist, err := history.New(100, "")
+			require.NoError(t, err)
+			sc := &statusController{
+				logger:         logrus.WithField("controller", "status-update"),
+				spc:            fgc,
+				config:         ca.Config,
+				newPoolPending: make(chan bool, 1),
+				shutDown:       make(chan bool),
+			}
+			go sc.run()
+			defer sc.shutdown()
+			c := &DefaultController{
+				config:         ca.Config,
+				spc:            fgc,
+				launcherClient: launcherfake.NewLauncher(),
+				tektonClient:   tektonfake.NewSimpleClientset(),
+				lhClient:       fake.NewSimpleClientset(),
+				ns:             "jx",
+				logger:         logrus.WithField("controller", "sync"),
+				sc:             sc,
+				changedFiles: &changedFilesAgent{
+					spc:             fgc,
+					nextChangeCache: make(map[changeCacheKey][]string),
+				},
+				History: hist,
+			}
+
+			require.NoError(t, c.Sync())
+			assert.Equal(t, tc.expectedMerged, fgc.merged)
+		})
+	}
+}
+
 func TestFilterSubpool(t *testing.T) {
 	presubmits := map[int][]job.Presubmit{
 		1: {{Reporter: job.Reporter{Context: "pj-a"}}},
@@ -2110,6 +2437,83 @@ func TestFilterSubpool(t *testing.T) {
 	}
 }
 
+func TestFilterPRReviewRequirements(t *testing.T) {
+	approve := func(login, state string) *scm.Review {
+		return &scm.Review{Author: scm.User{Login: login}, State: state}
+	}
+	tcs := []struct {
+		name       string
+		reqs       keeper.ReviewRequirements
+		reviews    []*scm.Review
+		unresolved bool
+
+		filtered bool
+		unmet    string
+	}{
+		{
+			name:    "no requirements",
+			reviews: []*scm.Review{approve("bob", scm.ReviewStateChangesRequested)},
+		},
+		{
+			name:    "enough approvals",
+			reqs:    keeper.ReviewRequirements{MinApprovals: 2},
+			reviews: []*scm.Review{approve("alice", scm.ReviewStateApproved), approve("bob", scm.ReviewStateApproved)},
+		},
+		{
+			name: "approval dismissed",
+			reqs: keeper.ReviewRequirements{MinApprovals: 2},
+			reviews: []*scm.Review{
+				approve("alice", scm.ReviewStateApproved),
+				approve("bob", scm.ReviewStateApproved),
+				approve("bob", scm.ReviewStateDismissed),
+			},
+			filtered: true,
+			unmet:    "Needs 2 approving reviews, has 1.",
+		},
+		{
+			name: "changes requested",
+			reqs: keeper.ReviewRequirements{MinApprovals: 1, NoChangesRequested: true},
+			reviews: []*scm.Review{
+				approve("alice", scm.ReviewStateApproved),
+				approve("bob", scm.ReviewStateChangesRequested),
+				approve("carol", scm.ReviewStateChangesRequested),
+				approve("carol", scm.ReviewStateCommented),
+			},
+			filtered: true,
+			unmet:    "Changes requested by bob, carol.",
+		},
+		{
+			name:       "unresolved discussions",
+			reqs:       keeper.ReviewRequirements{ResolvedDiscussions: true},
+			unresolved: true,
+			filtered:   true,
+			unmet:      "Has unresolved discussions.",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			sp := &subpool{
+				org:     "org",
+				repo:    "repo",
+				branch:  "branch",
+				cc:      &keeper.ContextPolicy{},
+				reviews: tc.reqs,
+				log:     logrus.WithFields(logrus.Fields{"org": "org", "repo": "repo", "branch": "branch"}),
+			}
+			pr := PullRequest{Number: githubql.Int(1)}
+			pr.Repository.NameWithOwner = "org/repo"
+			pr.Commits.Nodes = []struct{ Commit Commit }{{}}
+			spc := &fgc{
+				reviews:    map[int][]*scm.Review{1: tc.reviews},
+				unresolved: map[int]bool{1: tc.unresolved},
+			}
+
+			assert.Equal(t, tc.filtered, filterPR(spc, sp, &pr))
+			assert.Equal(t, tc.unmet, sp.unmetReviews[pr.prKey()])
+		})
+	}
+}
+
 func TestIsPassing(t *testing.T) {
 	yes := true
 	no := false
@@ -2869,3 +3273,39 @@ func TestReposToQueries(t *testing.T) {
 	assert.Equal(t, 1, len(queryMap["c"]))
 	assert.Equal(t, secondQuery, queryMap["c"][0])
 }
+
+func TestMergePRsNotifiesTheMergeCommit(t *testing.T) {
+	received := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]string{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err == nil {
+			received <- body["text"]
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.Notifications.Routes = []lighthouse.NotificationRoute{
+		{Kind: lighthouse.NotificationKindSlack, WebhookURL: server.URL, Events: []string{"merge"}, Template: "{{ .Number }} {{ .SHA }}"},
+	}
+	require.NoError(t, cfg.Notifications.Parse())
+	cfgGetter := func() *config.Config { return cfg }
+	c := &DefaultController{
+		config:   cfgGetter,
+		spc:      &fgc{mergeSHAs: map[int]string{1: "merge-sha"}},
+		notifier: notifier.New(cfgGetter),
+	}
+	var pr PullRequest
+	pr.Number = 1
+	pr.HeadRefOID = "head-sha"
+	sp := subpool{log: logrus.WithField("test", t.Name()), org: "o", repo: "r", branch: "master"}
+
+	require.NoError(t, c.mergePRs(sp, []PullRequest{pr}))
+	c.notifications.Wait()
+	select {
+	case text := <-received:
+		assert.Equal(t, "1 merge-sha", text)
+	default:
+		t.Fatal("the merge was not notified")
+	}
+}
@@ -0,0 +1,71 @@
+package keeper
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/sirupsen/logrus"
+)
+
+// MaintenanceAPIPath is the path of the JSON API of the maintenance windows
+const MaintenanceAPIPath = "/api/maintenance"
+
+// MaintenanceStatus describes a maintenance window: whether it is in progress and when it ends, or when it starts next
+type MaintenanceStatus struct {
+	Name   string   `json:"name"`
+	Reason string   `json:"reason,omitempty"`
+	Repos  []string `json:"repos,omitempty"`
+	Pause  []string `json:"pause"`
+	Active bool     `json:"active"`
+	// Current is the occurrence in progress, if any
+	Current *lighthouse.MaintenancePeriod `json:"current,omitempty"`
+	// Next is the next occurrence, if any
+	Next *lighthouse.MaintenancePeriod `json:"next,omitempty"`
+}
+
+// MaintenanceStatuses returns the statuses of the maintenance windows of the config at the given time
+func MaintenanceStatuses(cfg *config.Config, now time.Time) []MaintenanceStatus {
+	answer := []MaintenanceStatus{}
+	if cfg == nil {
+		return answer
+	}
+	for i := range cfg.Maintenance.Windows {
+		w := &cfg.Maintenance.Windows[i]
+		status := MaintenanceStatus{
+			Name:   w.Name,
+			Reason: w.Reason,
+			Repos:  w.Repos,
+			Pause:  w.Pause,
+		}
+		if len(status.Pause) == 0 {
+			status.Pause = []string{lighthouse.PausePeriodics, lighthouse.PauseMerges}
+		}
+		if current, ok := w.Current(now); ok {
+			status.Active, status.Current = true, &current
+		}
+		if next, ok := w.Next(now); ok {
+			status.Next = &next
+		}
+		answer = append(answer, status)
+	}
+	return answer
+}
+
+// MaintenanceHandler serves the statuses of the maintenance windows of the config as JSON
+func MaintenanceHandler(cfg config.Getter) http.HandlerFunc {
+	logger := logrus.WithField("component", "maintenance")
+	return func(w http.ResponseWriter, r *http.Request) {
+		b, err := json.Marshal(MaintenanceStatuses(cfg(), time.Now()))
+		if err != nil {
+			logger.WithError(err).Error("Encoding JSON.")
+			b = []byte("[]")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err = w.Write(b); err != nil {
+			logger.WithError(err).Error("Writing JSON response.")
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package keeper
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceStatuses(t *testing.T) {
+	now := time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)
+	cfg := &config.Config{}
+	cfg.Maintenance.Windows = []lighthouse.MaintenanceWindow{
+		{Name: "upgrade", Reason: "cluster upgrade", StartString: "2020-06-01T08:00:00Z", EndString: "2020-06-01T12:00:00Z"},
+		{Name: "nightly", Repos: []string{"org"}, Pause: []string{lighthouse.PauseMerges}, ScheduleString: "TZ=UTC 0 23 * * *", DurationString: "1h"},
+	}
+	require.NoError(t, cfg.Maintenance.Parse())
+
+	statuses := MaintenanceStatuses(cfg, now)
+	require.Len(t, statuses, 2)
+	assert.True(t, statuses[0].Active)
+	assert.Equal(t, []string{lighthouse.PausePeriodics, lighthouse.PauseMerges}, statuses[0].Pause)
+	require.NotNil(t, statuses[0].Current)
+	assert.Equal(t, cfg.Maintenance.Windows[0].End, statuses[0].Current.End)
+	assert.Nil(t, statuses[0].Next)
+	assert.False(t, statuses[1].Active)
+	assert.Nil(t, statuses[1].Current)
+	require.NotNil(t, statuses[1].Next)
+	assert.True(t, time.Date(2020, 6, 1, 23, 0, 0, 0, time.UTC).Equal(statuses[1].Next.Start))
+
+	s := httptest.NewServer(MaintenanceHandler(func() *config.Config { return cfg }))
+	defer s.Close()
+	resp, err := http.Get(s.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var served []MaintenanceStatus
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&served))
+	require.Len(t, served, 2)
+	assert.Equal(t, "upgrade", served[0].Name)
+}
@@ -58,6 +58,23 @@ func GetStatusContextLabel() string {
 	return statusContext
 }
 
+// PRStatus describes whether an open PR is in a merge pool and, if it is not
+// merging yet, the reason why.
+type PRStatus struct {
+	Org    string
+	Repo   string
+	Branch string
+	Number int
+	Title  string
+	Author string
+
+	InPool bool
+	// State and Reason are the state and description of the keeper status
+	// context of the PR.
+	State  string
+	Reason string
+}
+
 type storedState struct {
 	// LatestPR is the update time of the most recent result
 	LatestPR metav1.Time
@@ -84,6 +101,11 @@ type statusController struct {
 	sync.Mutex
 	poolPRs map[string]prWithStatus
 	blocks  blockers.Blockers
+	// unmetReviews describes the unmet review requirements of the PRs filtered
+	// out of the pool by the main Keeper loop, by PR key.
+	unmetReviews map[string]string
+	// prStatuses holds the statuses computed for the open PRs by the last sync.
+	prStatuses []PRStatus
 
 	storedState
 	path string
@@ -234,7 +256,7 @@ func requirementDiff(pr *PullRequest, q *keeper.Query, cc contextChecker) (strin
 // in order to generate a diff for the status description. We choose the query
 // for the repo that the PR is closest to meeting (as determined by the number
 // of unmet/violated requirements).
-func expectedStatus(queryMap *keeper.QueryMap, pr *PullRequest, pool map[string]prWithStatus, cc contextChecker, blocks blockers.Blockers, providerType string, log *logrus.Entry) (string, string) {
+func expectedStatus(queryMap *keeper.QueryMap, pr *PullRequest, pool map[string]prWithStatus, cc contextChecker, blocks blockers.Blockers, freeze *keeper.BranchFreeze, unmetReviews string, providerType string, log *logrus.Entry) (string, string) {
 	if _, ok := pool[pr.prKey()]; !ok {
 		// if the branch is blocked forget checking for a diff
 		blockingIssues := blocks.GetApplicable(string(pr.Repository.Owner.Login), string(pr.Repository.Name), string(pr.BaseRef.Name))
@@ -249,6 +271,9 @@ func expectedStatus(queryMap *keeper.QueryMap, pr *PullRequest, pool map[string]
 			}
 			return scmprovider.StatusError, fmt.Sprintf(statusNotInPool, fmt.Sprintf(" Merging is blocked by issue%s %s.", s, strings.Join(numbers, ", ")))
 		}
+		if freeze != nil {
+			return scmprovider.StatusError, fmt.Sprintf(statusNotInPool, " "+freeze.Description())
+		}
 		minDiffCount := -1
 		var minDiff string
 		for _, q := range queryMap.ForRepo(string(pr.Repository.Owner.Login), string(pr.Repository.Name)) {
@@ -259,6 +284,10 @@ func expectedStatus(queryMap *keeper.QueryMap, pr *PullRequest, pool map[string]
 				minDiff = diff
 			}
 		}
+		// the review requirements are only checked for the PRs meeting the other requirements
+		if minDiff == "" && unmetReviews != "" {
+			minDiff = " " + unmetReviews
+		}
 		// GitLab doesn't like updating status description without a state change.
 		if providerType == "gitlab" {
 			log.Infof("gitlab: expectedStatus failed for repository %s pr#%d with reason: %s", pr.Repository.NameWithOwner, pr.Number, minDiff)
@@ -270,6 +299,9 @@ func expectedStatus(queryMap *keeper.QueryMap, pr *PullRequest, pool map[string]
 }
 
 func statusForPRInPool(pr prWithStatus) string {
+	if pr.trainPosition > 0 {
+		return fmt.Sprintf("%s, position %d in the merge train.", statusInPool, pr.trainPosition)
+	}
 	if pr.success {
 		if len(pr.waitingForBatch) > 0 {
 			return fmt.Sprintf("%s, waiting for batch run and merge of PRs %s.", statusInPool, prList(pr.waitingForBatch))
@@ -312,11 +344,12 @@ func targetURL(c config.Getter, pr *PullRequest, log *logrus.Entry) string {
 	return link
 }
 
-func (sc *statusController) setStatuses(all []PullRequest, pool map[string]prWithStatus, blocks blockers.Blockers) {
+func (sc *statusController) setStatuses(all []PullRequest, pool map[string]prWithStatus, blocks blockers.Blockers, unmetReviews map[string]string) {
 	// queryMap caches which queries match a repo.
 	// Make a new one each sync loop as queries will change.
 	queryMap := sc.config().Keeper.Queries.QueryMap()
 	processed := sets.NewString()
+	var statuses []PRStatus
 
 	process := func(pr *PullRequest) {
 		processed.Insert(pr.prKey())
@@ -335,7 +368,13 @@ func (sc *statusController) setStatuses(all []PullRequest, pool map[string]prWit
 			return
 		}
 
-		wantState, wantDesc := expectedStatus(queryMap, pr, pool, cr, blocks, sc.spc.ProviderType(), log)
+		freeze := sc.config().FreezeFor(
+			string(pr.Repository.Owner.Login),
+			string(pr.Repository.Name),
+			string(pr.BaseRef.Name),
+			time.Now())
+		wantState, wantDesc := expectedStatus(queryMap, pr, pool, cr, blocks, freeze, unmetReviews[pr.prKey()], sc.spc.ProviderType(), log)
+		statuses = append(statuses, prStatus(queryMap, pr, pool, cr, blocks, freeze, unmetReviews[pr.prKey()], contexts, wantState, wantDesc, log))
 		var actualState githubql.StatusState
 		var actualDesc string
 		for _, ctx := range contexts {
@@ -389,6 +428,45 @@ func (sc *statusController) setStatuses(all []PullRequest, pool map[string]prWit
 			process(&p.pr)
 		}
 	}
+
+	sc.Lock()
+	sc.prStatuses = statuses
+	sc.Unlock()
+}
+
+// prStatus describes the status of a PR for the dashboard. Unlike the status
+// context, the reason of a PR waiting in the pool names the contexts it waits
+// for and the reason of a PR not in the pool is given for every provider.
+func prStatus(queryMap *keeper.QueryMap, pr *PullRequest, pool map[string]prWithStatus, cc contextChecker, blocks blockers.Blockers, freeze *keeper.BranchFreeze, unmetReviews string, contexts []Context, state, desc string, log *logrus.Entry) PRStatus {
+	poolPR, inPool := pool[pr.prKey()]
+	reason := desc
+	if inPool && !poolPR.success {
+		if waiting := contextsToStrings(unsuccessfulContexts(contexts, cc, log)); len(waiting) > 0 {
+			sort.Strings(waiting)
+			reason = fmt.Sprintf("%s, waiting for %s.", statusInPool, strings.Join(waiting, ", "))
+		}
+	} else if !inPool {
+		// the description of GitLab statuses omits the reason
+		_, reason = expectedStatus(queryMap, pr, pool, cc, blocks, freeze, unmetReviews, "", log)
+	}
+	return PRStatus{
+		Org:    string(pr.Repository.Owner.Login),
+		Repo:   string(pr.Repository.Name),
+		Branch: string(pr.BaseRef.Name),
+		Number: int(pr.Number),
+		Title:  string(pr.Title),
+		Author: string(pr.Author.Login),
+		InPool: inPool,
+		State:  state,
+		Reason: reason,
+	}
+}
+
+// statuses returns the statuses computed for the open PRs by the last sync.
+func (sc *statusController) statuses() []PRStatus {
+	sc.Lock()
+	defer sc.Unlock()
+	return append([]PRStatus(nil), sc.prStatuses...)
 }
 
 func (sc *statusController) load() {
@@ -432,8 +510,9 @@ func (sc *statusController) waitSync() {
 			sc.Lock()
 			pool := sc.poolPRs
 			blocks := sc.blocks
+			unmetReviews := sc.unmetReviews
 			sc.Unlock()
-			sc.sync(pool, blocks)
+			sc.sync(pool, blocks, unmetReviews)
 			return
 		case more := <-sc.newPoolPending:
 			if !more {
@@ -443,7 +522,7 @@ func (sc *statusController) waitSync() {
 	}
 }
 
-func (sc *statusController) sync(pool map[string]prWithStatus, blocks blockers.Blockers) {
+func (sc *statusController) sync(pool map[string]prWithStatus, blocks blockers.Blockers, unmetReviews map[string]string) {
 	sc.lastSyncStart = time.Now()
 	defer func() {
 		duration := time.Since(sc.lastSyncStart)
@@ -451,7 +530,7 @@ func (sc *statusController) sync(pool map[string]prWithStatus, blocks blockers.B
 		keeperMetrics.statusUpdateDuration.Set(duration.Seconds())
 	}()
 
-	sc.setStatuses(sc.search(), pool, blocks)
+	sc.setStatuses(sc.search(), pool, blocks, unmetReviews)
 }
 
 func (sc *statusController) search() []PullRequest {
@@ -498,6 +577,11 @@ func (sc *statusController) search() []PullRequest {
 		log.WithField("latestPR", sc.LatestPR).Debug("no new results")
 		return nil
 	}
+	for i := range prs {
+		if err := addCheckRunContexts(sc.logger.WithFields(prs[i].logFields()), sc.spc, &prs[i]); err != nil {
+			sc.logger.WithFields(prs[i].logFields()).WithError(err).Warn("Failed to add the check runs to the head contexts.")
+		}
+	}
 
 	latest := prs[len(prs)-1].UpdatedAt
 	if latest.IsZero() {
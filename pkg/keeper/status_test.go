@@ -46,8 +46,11 @@ func TestExpectedStatus(t *testing.T) {
 		contexts          []Context
 		inPool            bool
 		blocks            []int
+		freeze            *keeper.BranchFreeze
+		unmetReviews      string
 		pending           []int
 		batchPending      []int
+		trainPosition     int
 
 		state string
 		desc  string
@@ -208,6 +211,36 @@ func TestExpectedStatus(t *testing.T) {
 			state: scmprovider.StatusError,
 			desc:  fmt.Sprintf(statusNotInPool, " Merging is blocked by issues 1, 2."),
 		},
+		{
+			name:      "check that freezes take precedence over other queries",
+			labels:    []string{"3", "4", "5", "6", "7"},
+			milestone: "v1.0",
+			inPool:    false,
+			freeze:    &keeper.BranchFreeze{Reason: "release 1.0 stabilization"},
+
+			state: scmprovider.StatusError,
+			desc:  fmt.Sprintf(statusNotInPool, " Merging is frozen: release 1.0 stabilization."),
+		},
+		{
+			name:         "unmet review requirements",
+			labels:       neededLabels,
+			milestone:    "v1.0",
+			inPool:       false,
+			unmetReviews: "Needs 2 approving reviews, has 1.",
+
+			state: scmprovider.StatusPending,
+			desc:  fmt.Sprintf(statusNotInPool, " Needs 2 approving reviews, has 1."),
+		},
+		{
+			name:         "other requirements are described before the review requirements",
+			labels:       neededLabels[:2],
+			milestone:    "v1.0",
+			inPool:       false,
+			unmetReviews: "Needs 2 approving reviews, has 1.",
+
+			state: scmprovider.StatusPending,
+			desc:  fmt.Sprintf(statusNotInPool, " Needs need-a-very-super-duper-extra-not-short-at-all-label-name label."),
+		},
 		{
 			name:    "in pool behind pending",
 			inPool:  true,
@@ -224,6 +257,14 @@ func TestExpectedStatus(t *testing.T) {
 			state: scmprovider.StatusSuccess,
 			desc:  fmt.Sprintf("%s, waiting for batch run and merge of PRs #1, #2, #3.", statusInPool),
 		},
+		{
+			name:          "in merge train",
+			inPool:        true,
+			trainPosition: 2,
+
+			state: scmprovider.StatusSuccess,
+			desc:  fmt.Sprintf("%s, position 2 in the merge train.", statusInPool),
+		},
 	}
 
 	for _, tc := range testcases {
@@ -294,6 +335,7 @@ func TestExpectedStatus(t *testing.T) {
 					withStatus.waitingForBatch = append(withStatus.waitingForBatch, tc.batchPending...)
 					withStatus.success = true
 				}
+				withStatus.trainPosition = tc.trainPosition
 				pool = map[string]prWithStatus{"#0": withStatus}
 			}
 			blocks := blockers.Blockers{
@@ -305,7 +347,7 @@ func TestExpectedStatus(t *testing.T) {
 			}
 			blocks.Repo[blockers.OrgRepo{Org: "", Repo: ""}] = items
 
-			state, desc := expectedStatus(queriesByRepo, &pr, pool, &keeper.ContextPolicy{}, blocks, "fake", nil)
+			state, desc := expectedStatus(queriesByRepo, &pr, pool, &keeper.ContextPolicy{}, blocks, tc.freeze, tc.unmetReviews, "fake", nil)
 			if state != tc.state {
 				t.Errorf("Expected status state %q, but got %q.", string(tc.state), string(state))
 			}
@@ -431,7 +473,7 @@ func TestSetStatuses(t *testing.T) {
 			}
 
 			sc := &statusController{spc: fc, config: ca.Config, logger: log}
-			sc.setStatuses([]PullRequest{pr}, pool, blockers.Blockers{})
+			sc.setStatuses([]PullRequest{pr}, pool, blockers.Blockers{}, nil)
 			if str, err := log.String(); err != nil {
 				t.Fatalf("For case %s: failed to get log output: %v", tc.name, err)
 			} else if str != initialLog {
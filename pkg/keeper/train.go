@@ -0,0 +1,552 @@
+package keeper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// A merge train queues the PRs of a branch in order. Each PR is tested by a car, a batch job
+// testing it on top of the PRs ahead of it in the train, so that the PRs are merged as soon as
+// their car passes without being retested against the branch updated by the merges ahead of
+// them. When a car fails its PR is evicted from the train and the cars behind it are tested
+// again without it.
+
+// mergedCars are the PRs at the front of a merge train merged into the branch
+type mergedCars struct {
+	// baseSHA is the base the cars of the train were tested against
+	baseSHA string
+	// headSHA is the head of the branch once the PRs were merged, adopted on the next sync once
+	// verified to be the merge of the PRs on top of the base
+	headSHA string
+	// pulls are the merged PRs, in order
+	pulls []v1alpha1.Pull
+}
+
+// carries returns whether the job is a car of the train tested on top of the merged PRs
+func (m *mergedCars) carries(lhj *v1alpha1.LighthouseJob) bool {
+	if m == nil || !isTrainCar(lhj) || lhj.Spec.Refs.BaseSHA != m.baseSHA {
+		return false
+	}
+	pulls := lhj.Spec.Refs.Pulls
+	if len(pulls) <= len(m.pulls) {
+		return false
+	}
+	for i, pull := range m.pulls {
+		if pulls[i].Number != pull.Number || pulls[i].SHA != pull.SHA {
+			return false
+		}
+	}
+	return true
+}
+
+// trainTracker remembers the PRs keeper merged from the front of the merge train of each pool,
+// so that the cars behind them remain valid once the branch has advanced, the order in which the
+// PRs joined the queue and the PRs evicted from the trains until their head changes. It is kept
+// in memory: the trains are tested again after a restart.
+type trainTracker struct {
+	sync.Mutex
+	// merged by pool key
+	merged map[string]*mergedCars
+	// evicted holds the head SHA of the evicted PRs, by PR key
+	evicted map[string]string
+	// queued holds the position of the PRs in the queue, by PR key
+	queued map[string]int
+	next   int
+}
+
+func newTrainTracker() *trainTracker {
+	return &trainTracker{
+		merged:  map[string]*mergedCars{},
+		evicted: map[string]string{},
+		queued:  map[string]int{},
+	}
+}
+
+// mergedInto returns the PRs merged from the train of the pool if its branch was advanced to the
+// given head by the merges, nil otherwise. The first head seen after the merges is only adopted
+// if verify confirms it is the merge of the PRs on top of the base of the train, the train is
+// dropped otherwise.
+func (t *trainTracker) mergedInto(key, headSHA string, verify func(*mergedCars) bool) *mergedCars {
+	if t == nil {
+		return nil
+	}
+	t.Lock()
+	defer t.Unlock()
+	m := t.merged[key]
+	if m == nil {
+		return nil
+	}
+	if m.headSHA == "" {
+		if verify == nil || !verify(m) {
+			delete(t.merged, key)
+			return nil
+		}
+		m.headSHA = headSHA
+	}
+	if m.headSHA != headSHA {
+		// the branch has moved on since
+		delete(t.merged, key)
+		return nil
+	}
+	return m
+}
+
+// merge remembers the PRs merged from the train of the pool, tested against the given base
+func (t *trainTracker) merge(key, baseSHA string, pulls []v1alpha1.Pull) {
+	if t == nil {
+		return
+	}
+	t.Lock()
+	defer t.Unlock()
+	t.merged[key] = &mergedCars{baseSHA: baseSHA, pulls: pulls}
+}
+
+// forget forgets the PRs merged from the train of the pool
+func (t *trainTracker) forget(key string) {
+	if t == nil {
+		return
+	}
+	t.Lock()
+	defer t.Unlock()
+	delete(t.merged, key)
+}
+
+// evict evicts the PR from its train until its head changes
+func (t *trainTracker) evict(pr *PullRequest) {
+	if t == nil {
+		return
+	}
+	t.Lock()
+	defer t.Unlock()
+	t.evicted[pr.prKey()] = string(pr.HeadRefOID)
+	delete(t.queued, pr.prKey())
+}
+
+// isEvicted returns whether the PR was evicted from its train at its current head
+func (t *trainTracker) isEvicted(pr *PullRequest) bool {
+	if t == nil {
+		return false
+	}
+	t.Lock()
+	defer t.Unlock()
+	sha, ok := t.evicted[pr.prKey()]
+	if ok && sha != string(pr.HeadRefOID) {
+		delete(t.evicted, pr.prKey())
+		return false
+	}
+	return ok
+}
+
+// enqueue queues the PRs not queued yet behind the others, in the given order, and sorts them
+// in queue order
+func (t *trainTracker) enqueue(prs []PullRequest) {
+	if t == nil {
+		return
+	}
+	t.Lock()
+	defer t.Unlock()
+	for i := range prs {
+		key := prs[i].prKey()
+		if _, ok := t.queued[key]; !ok {
+			t.queued[key] = t.next
+			t.next++
+		}
+	}
+	sort.SliceStable(prs, func(i, j int) bool { return t.queued[prs[i].prKey()] < t.queued[prs[j].prKey()] })
+}
+
+// prune forgets the evicted and queued PRs which left the pools
+func (t *trainTracker) prune(prs map[string]PullRequest) {
+	if t == nil {
+		return
+	}
+	t.Lock()
+	defer t.Unlock()
+	for key := range t.evicted {
+		if _, ok := prs[key]; !ok {
+			delete(t.evicted, key)
+		}
+	}
+	for key := range t.queued {
+		if _, ok := prs[key]; !ok {
+			delete(t.queued, key)
+		}
+	}
+}
+
+// isTrainCar returns whether the job is a car of a merge train
+func isTrainCar(lhj *v1alpha1.LighthouseJob) bool {
+	return lhj.Spec.Type == job.BatchJob && lhj.Labels[util.MergeTrainLabel] == "true"
+}
+
+// trainCar is the state of the car testing a PR of a train on top of the PRs ahead of it
+type trainCar struct {
+	// prs are the PRs not merged yet tested by the car, in order
+	prs []PullRequest
+	// baseSHA and pulls are the refs of the car, including the PRs merged since
+	baseSHA string
+	pulls   []v1alpha1.Pull
+	state   simpleState
+	// failures are the URLs of the failed jobs of the car, by context
+	failures map[string]string
+}
+
+// trainKey identifies the car testing the PRs
+func trainKey(prs []PullRequest) string {
+	nums := make([]string, 0, len(prs))
+	for _, pr := range prs {
+		nums = append(nums, fmt.Sprintf("#%d", pr.Number))
+	}
+	return strings.Join(nums, ",")
+}
+
+// accumulateTrain returns the cars of the train of the subpool testing the given PRs, by key. The
+// cars testing other PRs, or other heads of the PRs, are ignored like the cars missing some of
+// the required presubmits, which must be triggered again.
+func accumulateTrain(presubmits map[int][]job.Presubmit, prs []PullRequest, merged *mergedCars, pjs []v1alpha1.LighthouseJob, log *logrus.Entry) map[string]*trainCar {
+	prNums := make(map[int]PullRequest)
+	for _, pr := range prs {
+		prNums[int(pr.Number)] = pr
+	}
+	cars := map[string]*trainCar{}
+	invalid := sets.NewString()
+	jobStates := map[string]map[string]simpleState{}
+	for i := range pjs {
+		pj := &pjs[i]
+		if !isTrainCar(pj) {
+			continue
+		}
+		pulls := pj.Spec.Refs.Pulls
+		if merged.carries(pj) {
+			pulls = pulls[len(merged.pulls):]
+		}
+		var carPRs []PullRequest
+		for _, pull := range pulls {
+			pr, ok := prNums[pull.Number]
+			if !ok || string(pr.HeadRefOID) != pull.SHA {
+				carPRs = nil
+				break
+			}
+			carPRs = append(carPRs, pr)
+		}
+		if len(carPRs) == 0 {
+			invalid.Insert(pj.Spec.Refs.String())
+			continue
+		}
+		key := trainKey(carPRs)
+		car, ok := cars[key]
+		if !ok {
+			car = &trainCar{prs: carPRs, baseSHA: pj.Spec.Refs.BaseSHA, pulls: pj.Spec.Refs.Pulls, failures: map[string]string{}}
+			cars[key] = car
+			jobStates[key] = map[string]simpleState{}
+		}
+		context := pj.Spec.Context
+		state := toSimpleState(pj.Status.State)
+		if s, ok := jobStates[key][context]; !ok || s == failureState || state == successState {
+			jobStates[key][context] = state
+		}
+		if state == failureState {
+			car.failures[context] = pj.Status.ReportURL
+		}
+	}
+	if invalid.Len() > 0 {
+		log.WithField("cars", invalid.List()).Debug("Ignoring the cars of PRs which left the train or changed.")
+	}
+
+	for key, car := range cars {
+		required := sets.NewString()
+		for _, pr := range car.prs {
+			for _, ps := range presubmits[int(pr.Number)] {
+				required.Insert(ps.Context)
+			}
+		}
+		car.state = successState
+		for _, context := range required.List() {
+			s, ok := jobStates[key][context]
+			if !ok {
+				log.WithField("car", key).Debugf("car missing required presubmit %s", context)
+				delete(cars, key)
+				break
+			}
+			if s == failureState {
+				car.state = failureState
+			} else if s == pendingState && car.state == successState {
+				car.state = pendingState
+			}
+		}
+		for context := range car.failures {
+			if jobStates[key][context] != failureState {
+				delete(car.failures, context)
+			}
+		}
+	}
+	return cars
+}
+
+// trainOrder returns the PRs of the train in order, those of its longest car
+func trainOrder(cars map[string]*trainCar) []PullRequest {
+	keys := make([]string, 0, len(cars))
+	for key := range cars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var answer []PullRequest
+	for _, key := range keys {
+		if prs := cars[key].prs; len(prs) > len(answer) {
+			answer = prs
+		}
+	}
+	return append([]PullRequest(nil), answer...)
+}
+
+// takeTrainAction merges the PRs at the front of the merge train of the subpool whose car passed,
+// evicts the first PR whose car failed, then queues the other PRs passing their tests behind them
+// and triggers the missing cars. It returns the action, its targets and the PRs of the train.
+func (c *DefaultController) takeTrainAction(sp subpool, successes []PullRequest, size int) (Action, []PullRequest, []PullRequest, error) {
+	var queue []PullRequest
+	for i := range sp.prs {
+		pr := sp.prs[i]
+		if c.trains.isEvicted(&pr) {
+			sp.log.WithFields(pr.logFields()).Debug("PR was evicted from the merge train.")
+			continue
+		}
+		queue = append(queue, pr)
+	}
+	c.trains.enqueue(queue)
+	var candidates []PullRequest
+	for _, pr := range queue {
+		if isPassingTests(sp.log, c.spc, pr, sp.cc) {
+			candidates = append(candidates, pr)
+		}
+	}
+	cars := accumulateTrain(sp.presubmits, candidates, sp.merged, sp.ljs, sp.log)
+	train := trainOrder(cars)
+	if len(train) > size {
+		train = train[:size]
+	}
+	key := poolKey(sp.org, sp.repo, sp.branch)
+
+	// There is no need to start a train to merge a PR which passed against the current base.
+	if len(cars) == 0 && len(successes) > 0 {
+		if ok, pr := pickSmallestPassingNumber(sp.log, c.spc, successes, sp.cc); ok {
+			return Merge, []PullRequest{pr}, nil, c.mergePRs(sp, []PullRequest{pr})
+		}
+	}
+
+	// The car of a PR tests all the PRs ahead of it so merge them all with the last passing car.
+	for i := len(train) - 1; i >= 0; i-- {
+		car := cars[trainKey(train[:i+1])]
+		if car == nil || car.state != successState {
+			continue
+		}
+		targets := train[:i+1]
+		if err := c.mergePRs(sp, targets); err != nil {
+			c.trains.forget(key)
+			return MergeTrain, targets, train[i+1:], err
+		}
+		c.trains.merge(key, car.baseSHA, car.pulls)
+		return MergeTrain, targets, train[i+1:], nil
+	}
+
+	for i := range train {
+		car := cars[trainKey(train[:i+1])]
+		if car == nil || car.state != failureState {
+			continue
+		}
+		evicted := train[i]
+		c.trains.evict(&evicted)
+		sp.log.WithFields(evicted.logFields()).Info("Evicted PR from the merge train.")
+		if err := c.commentOnEvictedPR(sp, evicted, train[:i], car); err != nil {
+			sp.log.WithFields(evicted.logFields()).WithError(err).Warn("Failed to comment on the PR evicted from the merge train.")
+		}
+		train = append(train[:i:i], train[i+1:]...)
+		break
+	}
+
+	queued := sets.NewInt(prNumbers(train)...)
+	for i := range candidates {
+		pr := candidates[i]
+		if len(train) >= size {
+			break
+		}
+		if !queued.Has(int(pr.Number)) && !c.trains.isEvicted(&pr) {
+			train = append(train, pr)
+		}
+	}
+
+	missing := false
+	for i := range train {
+		if cars[trainKey(train[:i+1])] == nil {
+			missing = true
+			break
+		}
+	}
+	if !missing {
+		return Wait, nil, train, nil
+	}
+
+	train, err := c.mergeableTrain(sp, train)
+	if err != nil {
+		return Wait, nil, train, err
+	}
+	var targets []PullRequest
+	for i := range train {
+		if cars[trainKey(train[:i+1])] != nil {
+			continue
+		}
+		if err := c.triggerCar(sp, train[:i+1]); err != nil {
+			return TriggerTrain, targets, train, err
+		}
+		targets = append(targets, train[i])
+	}
+	return TriggerTrain, targets, train, nil
+}
+
+// mergeableTrain returns the PRs of the train which merge into the base of the subpool on top of the
+// PRs ahead of them without conflicts. The conflicting PRs are evicted from the train.
+func (c *DefaultController) mergeableTrain(sp subpool, train []PullRequest) ([]PullRequest, error) {
+	r, err := c.checkoutBase(sp)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Clean()
+
+	var answer []PullRequest
+	for _, pr := range train {
+		ok, err := r.Merge(string(pr.HeadRefOID))
+		if err != nil {
+			// we failed to abort the merge and our git client is
+			// in a bad state; it must be cleaned before we try again
+			return nil, err
+		}
+		if ok {
+			answer = append(answer, pr)
+			continue
+		}
+		c.trains.evict(&pr)
+		sp.log.WithFields(pr.logFields()).Info("Evicted PR conflicting with the PRs ahead of it from the merge train.")
+		if err := c.commentOnConflictingPR(sp, pr, answer); err != nil {
+			sp.log.WithFields(pr.logFields()).WithError(err).Warn("Failed to comment on the PR evicted from the merge train.")
+		}
+	}
+	return answer, nil
+}
+
+// isTrainHead returns whether the head of the branch is the merge of exactly the PRs merged from
+// the train on top of the base their cars were tested against
+func (c *DefaultController) isTrainHead(org, repo string, m *mergedCars, headSHA string, log *logrus.Entry) (bool, error) {
+	r, err := c.clone(org, repo, log)
+	if err != nil {
+		return false, err
+	}
+	defer r.Clean()
+	if err := r.Checkout(m.baseSHA); err != nil {
+		return false, err
+	}
+	for _, pull := range m.pulls {
+		ok, err := r.Merge(pull.SHA)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	expected, err := r.RevParse("HEAD^{tree}")
+	if err != nil {
+		return false, err
+	}
+	actual, err := r.RevParse(headSHA + "^{tree}")
+	if err != nil {
+		return false, err
+	}
+	return expected == actual, nil
+}
+
+// triggerCar triggers the car testing the last of the PRs on top of the others. The cars are not
+// reported to the PRs, keeper reports their position in the train instead.
+func (c *DefaultController) triggerCar(sp subpool, prs []PullRequest) error {
+	refs := c.refs(sp, prs)
+	triggeredContexts := sets.NewString()
+	for _, pr := range prs {
+		for _, ps := range sp.presubmits[int(pr.Number)] {
+			if triggeredContexts.Has(ps.Context) {
+				continue
+			}
+			triggeredContexts.Insert(ps.Context)
+			spec := jobutil.BatchSpec(ps, refs)
+			spec.Report = []job.ReportMode{job.ReportNone}
+			labels := map[string]string{}
+			for k, v := range ps.Labels {
+				labels[k] = v
+			}
+			labels[util.MergeTrainLabel] = "true"
+			pj := jobutil.NewLighthouseJob(spec, labels, ps.Annotations)
+			if _, err := c.launcherClient.Launch(&pj); err != nil {
+				return fmt.Errorf("failed to create a pipeline for job: %q, merge train car: %v: %v", spec.Job, prNumbers(prs), err)
+			}
+		}
+	}
+	return nil
+}
+
+// commentOnConflictingPR tells the author of the PR it was evicted from the merge train as it
+// conflicts with the PRs ahead of it
+func (c *DefaultController) commentOnConflictingPR(sp subpool, pr PullRequest, ahead []PullRequest) error {
+	var sb strings.Builder
+	sb.WriteString("This PR was removed from the merge train of `")
+	sb.WriteString(sp.branch)
+	sb.WriteString("` as it does not merge cleanly into it")
+	if len(ahead) > 0 {
+		sb.WriteString(" on top of ")
+		for i, n := range prNumbers(ahead) {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(fmt.Sprintf("#%d", n))
+		}
+	}
+	sb.WriteString(".\n\nPush a new commit to queue it again.")
+	return c.spc.CreateComment(sp.org, sp.repo, int(pr.Number), true, sb.String())
+}
+
+// commentOnEvictedPR tells the author of the PR why it was evicted from the merge train
+func (c *DefaultController) commentOnEvictedPR(sp subpool, pr PullRequest, ahead []PullRequest, car *trainCar) error {
+	var sb strings.Builder
+	sb.WriteString("This PR was removed from the merge train of `")
+	sb.WriteString(sp.branch)
+	sb.WriteString("` as ")
+	contexts := make([]string, 0, len(car.failures))
+	for context := range car.failures {
+		contexts = append(contexts, context)
+	}
+	sort.Strings(contexts)
+	for i, context := range contexts {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		if url := car.failures[context]; url != "" {
+			sb.WriteString(fmt.Sprintf("[%s](%s)", context, url))
+		} else {
+			sb.WriteString(context)
+		}
+	}
+	sb.WriteString(" failed")
+	if len(ahead) > 0 {
+		sb.WriteString(" on top of ")
+		for i, n := range prNumbers(ahead) {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(fmt.Sprintf("#%d", n))
+		}
+	}
+	sb.WriteString(".\n\nPush a new commit to queue it again.")
+	return c.spc.CreateComment(sp.org, sp.repo, int(pr.Number), true, sb.String())
+}
@@ -0,0 +1,282 @@
+package keeper
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/keeper"
+	"github.com/jenkins-x/lighthouse/pkg/git/localgit"
+	launcherfake "github.com/jenkins-x/lighthouse/pkg/launcher/fake"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	githubql "github.com/shurcooL/githubv4"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeTrain(t *testing.T) {
+	lg, gc, err := localgit.New()
+	require.NoError(t, err)
+	defer gc.Clean()
+	defer lg.Clean()
+	require.NoError(t, lg.MakeFakeRepo("o", "r"))
+	require.NoError(t, lg.AddCommit("o", "r", map[string][]byte{"foo": []byte("foo")}))
+
+	presubmits := map[int][]job.Presubmit{}
+	var prs []PullRequest
+	for i := 1; i <= 4; i++ {
+		require.NoError(t, lg.CheckoutNewBranch("o", "r", fmt.Sprintf("pr-%d", i)))
+		require.NoError(t, lg.AddCommit("o", "r", map[string][]byte{fmt.Sprintf("%d", i): []byte("WOW")}))
+		require.NoError(t, lg.Checkout("o", "r", "master"))
+		oid := githubql.String(fmt.Sprintf("origin/pr-%d", i))
+		var pr PullRequest
+		pr.Number = githubql.Int(i)
+		pr.HeadRefOID = oid
+		pr.Repository.Owner.Login = "o"
+		pr.Repository.Name = "r"
+		pr.Commits.Nodes = []struct {
+			Commit Commit
+		}{{Commit: Commit{OID: oid}}}
+		prs = append(prs, pr)
+		presubmits[i] = []job.Presubmit{{Reporter: job.Reporter{Context: "unit"}}}
+	}
+	base, err := lg.RevParse("o", "r", "master")
+	require.NoError(t, err)
+
+	ca := &config.Agent{}
+	ca.Set(&config.Config{})
+	spc := &fgc{}
+	fakeLauncher := launcherfake.NewLauncher()
+	c := &DefaultController{
+		logger:         logrus.WithField("controller", "keeper"),
+		gc:             gc,
+		config:         ca.Config,
+		spc:            spc,
+		launcherClient: fakeLauncher,
+		trains:         newTrainTracker(),
+	}
+	sp := subpool{
+		log:        logrus.WithField("component", "keeper"),
+		presubmits: presubmits,
+		cc:         &keeper.ContextPolicy{},
+		org:        "o",
+		repo:       "r",
+		branch:     "master",
+		sha:        base,
+		prs:        prs,
+	}
+	key := poolKey("o", "r", "master")
+
+	// the PRs are queued in order, up to the size of the train, each tested on top of those ahead of it
+	act, targets, train, err := c.takeTrainAction(sp, nil, 3)
+	require.NoError(t, err)
+	assert.Equal(t, Action(TriggerTrain), act)
+	assert.Equal(t, []int{1, 2, 3}, prNumbers(targets))
+	assert.Equal(t, []int{1, 2, 3}, prNumbers(train))
+	require.Len(t, fakeLauncher.Pipelines, 3)
+	for i, car := range fakeLauncher.Pipelines {
+		assert.Equal(t, job.BatchJob, car.Spec.Type)
+		assert.Equal(t, "true", car.Labels[util.MergeTrainLabel])
+		assert.Equal(t, []job.ReportMode{job.ReportNone}, car.Spec.Report)
+		assert.Equal(t, base, car.Spec.Refs.BaseSHA)
+		assert.Len(t, car.Spec.Refs.Pulls, i+1)
+	}
+	cars := func(states ...v1alpha1.PipelineState) []v1alpha1.LighthouseJob {
+		var answer []v1alpha1.LighthouseJob
+		for i, state := range states {
+			car := *fakeLauncher.Pipelines[i]
+			car.Status.State = state
+			car.Status.ReportURL = fmt.Sprintf("https://dashboard/%d", i+1)
+			answer = append(answer, car)
+		}
+		return answer
+	}
+
+	// nothing to do while the cars are running
+	sp.ljs = cars(v1alpha1.PendingState, v1alpha1.PendingState, v1alpha1.PendingState)
+	act, targets, train, err = c.takeTrainAction(sp, nil, 3)
+	require.NoError(t, err)
+	assert.Equal(t, Wait, act)
+	assert.Empty(t, targets)
+	assert.Equal(t, []int{1, 2, 3}, prNumbers(train))
+	assert.Len(t, fakeLauncher.Pipelines, 3)
+
+	// the second car passing tests the first PR as well so both are merged
+	sp.ljs = cars(v1alpha1.PendingState, v1alpha1.SuccessState, v1alpha1.PendingState)
+	act, targets, train, err = c.takeTrainAction(sp, nil, 3)
+	require.NoError(t, err)
+	assert.Equal(t, Action(MergeTrain), act)
+	assert.Equal(t, []int{1, 2}, prNumbers(targets))
+	assert.Equal(t, []int{3}, prNumbers(train))
+	assert.Equal(t, 2, spc.merged)
+
+	verify := func(head string) func(*mergedCars) bool {
+		return func(m *mergedCars) bool {
+			ok, err := c.isTrainHead("o", "r", m, head, c.logger)
+			require.NoError(t, err)
+			return ok
+		}
+	}
+
+	// the car of the third PR remains valid once the branch includes the merged PRs
+	require.NoError(t, lg.AddCommit("o", "r", map[string][]byte{"1": []byte("WOW"), "2": []byte("WOW")}))
+	head, err := lg.RevParse("o", "r", "master")
+	require.NoError(t, err)
+	merged := c.trains.mergedInto(key, head, verify(head))
+	require.NotNil(t, merged)
+	ljs := cars(v1alpha1.PendingState, v1alpha1.SuccessState, v1alpha1.FailureState)
+	assert.False(t, merged.carries(&ljs[0]))
+	assert.False(t, merged.carries(&ljs[1]))
+	assert.True(t, merged.carries(&ljs[2]))
+
+	// its failure evicts it from the train, the PRs behind it are tested again without it
+	sp.sha = head
+	sp.prs = prs[2:]
+	sp.ljs = ljs[2:]
+	sp.merged = merged
+	act, targets, train, err = c.takeTrainAction(sp, nil, 3)
+	require.NoError(t, err)
+	assert.Equal(t, Action(TriggerTrain), act)
+	assert.Equal(t, []int{4}, prNumbers(targets))
+	assert.Equal(t, []int{4}, prNumbers(train))
+	require.Len(t, fakeLauncher.Pipelines, 4)
+	car := fakeLauncher.Pipelines[3]
+	assert.Equal(t, head, car.Spec.Refs.BaseSHA)
+	require.Len(t, car.Spec.Refs.Pulls, 1)
+	assert.Equal(t, 4, car.Spec.Refs.Pulls[0].Number)
+	assert.Contains(t, spc.mergeErrComments[3], "removed from the merge train of `master` as [unit](https://dashboard/3) failed.")
+
+	// until it is updated
+	evicted := prs[2]
+	assert.True(t, c.trains.isEvicted(&evicted))
+	evicted.HeadRefOID = "updated"
+	assert.False(t, c.trains.isEvicted(&evicted))
+
+	// the train is forgotten once the branch moves on
+	assert.Nil(t, c.trains.mergedInto(key, "other", nil))
+	assert.Nil(t, c.trains.mergedInto(key, head, nil))
+
+	// the train is dropped if the branch was not advanced by the merges alone
+	c.trains.merge(key, base, car.Spec.Refs.Pulls)
+	assert.Nil(t, c.trains.mergedInto(key, head, verify(head)))
+}
+
+func TestMergeTrainQueueOrder(t *testing.T) {
+	lg, gc, err := localgit.New()
+	require.NoError(t, err)
+	defer gc.Clean()
+	defer lg.Clean()
+	require.NoError(t, lg.MakeFakeRepo("o", "r"))
+	require.NoError(t, lg.AddCommit("o", "r", map[string][]byte{"foo": []byte("foo")}))
+
+	presubmits := map[int][]job.Presubmit{}
+	prs := map[int]PullRequest{}
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, lg.CheckoutNewBranch("o", "r", fmt.Sprintf("pr-%d", i)))
+		// the first and third PRs conflict
+		file := fmt.Sprintf("%d", i)
+		if i == 3 {
+			file = "1"
+		}
+		require.NoError(t, lg.AddCommit("o", "r", map[string][]byte{file: []byte(fmt.Sprintf("WOW %d", i))}))
+		require.NoError(t, lg.Checkout("o", "r", "master"))
+		oid := githubql.String(fmt.Sprintf("origin/pr-%d", i))
+		var pr PullRequest
+		pr.Number = githubql.Int(i)
+		pr.HeadRefOID = oid
+		pr.Repository.Owner.Login = "o"
+		pr.Repository.Name = "r"
+		pr.Commits.Nodes = []struct {
+			Commit Commit
+		}{{Commit: Commit{OID: oid}}}
+		prs[i] = pr
+		presubmits[i] = []job.Presubmit{{Reporter: job.Reporter{Context: "unit"}}}
+	}
+	base, err := lg.RevParse("o", "r", "master")
+	require.NoError(t, err)
+
+	ca := &config.Agent{}
+	ca.Set(&config.Config{})
+	spc := &fgc{}
+	fakeLauncher := launcherfake.NewLauncher()
+	c := &DefaultController{
+		logger:         logrus.WithField("controller", "keeper"),
+		gc:             gc,
+		config:         ca.Config,
+		spc:            spc,
+		launcherClient: fakeLauncher,
+		trains:         newTrainTracker(),
+	}
+	sp := subpool{
+		log:        logrus.WithField("component", "keeper"),
+		presubmits: presubmits,
+		cc:         &keeper.ContextPolicy{},
+		org:        "o",
+		repo:       "r",
+		branch:     "master",
+		sha:        base,
+	}
+
+	// the second PR joined the queue first
+	c.trains.enqueue([]PullRequest{prs[2]})
+
+	// the PRs are queued in the order they joined the queue, the conflicting PR is evicted
+	sp.prs = []PullRequest{prs[1], prs[2], prs[3]}
+	act, targets, train, err := c.takeTrainAction(sp, nil, 3)
+	require.NoError(t, err)
+	assert.Equal(t, Action(TriggerTrain), act)
+	assert.Equal(t, []int{2, 1}, prNumbers(targets))
+	assert.Equal(t, []int{2, 1}, prNumbers(train))
+	evicted := prs[3]
+	assert.True(t, c.trains.isEvicted(&evicted))
+	assert.Contains(t, spc.mergeErrComments[3], "removed from the merge train of `master` as it does not merge cleanly into it on top of #2, #1.")
+}
+
+func TestAccumulateTrain(t *testing.T) {
+	var prs []PullRequest
+	for i := 1; i <= 2; i++ {
+		var pr PullRequest
+		pr.Number = githubql.Int(i)
+		pr.HeadRefOID = githubql.String(fmt.Sprintf("sha%d", i))
+		prs = append(prs, pr)
+	}
+	presubmits := map[int][]job.Presubmit{
+		1: {{Reporter: job.Reporter{Context: "unit"}}},
+		2: {{Reporter: job.Reporter{Context: "unit"}}, {Reporter: job.Reporter{Context: "e2e"}}},
+	}
+	car := func(context string, state v1alpha1.PipelineState, pulls ...int) v1alpha1.LighthouseJob {
+		lhj := v1alpha1.LighthouseJob{}
+		lhj.Labels = map[string]string{util.MergeTrainLabel: "true"}
+		lhj.Spec.Type = job.BatchJob
+		lhj.Spec.Context = context
+		lhj.Spec.Refs = &v1alpha1.Refs{Org: "o", Repo: "r", BaseSHA: "base"}
+		for _, n := range pulls {
+			lhj.Spec.Refs.Pulls = append(lhj.Spec.Refs.Pulls, v1alpha1.Pull{Number: n, SHA: fmt.Sprintf("sha%d", n)})
+		}
+		lhj.Status.State = state
+		return lhj
+	}
+	batch := car("unit", v1alpha1.SuccessState, 1, 2)
+	batch.Labels = nil
+	stale := car("unit", v1alpha1.SuccessState, 2)
+	stale.Spec.Refs.Pulls[0].SHA = "old"
+	pjs := []v1alpha1.LighthouseJob{
+		car("unit", v1alpha1.FailureState, 1),
+		car("unit", v1alpha1.SuccessState, 1),
+		car("unit", v1alpha1.SuccessState, 1, 2),
+		car("e2e", v1alpha1.PendingState, 1, 2),
+		car("unit", v1alpha1.SuccessState, 2, 1),
+		car("unit", v1alpha1.SuccessState, 3),
+		batch,
+		stale,
+	}
+	cars := accumulateTrain(presubmits, prs, nil, pjs, logrus.WithField("component", "keeper"))
+	require.Len(t, cars, 2)
+	assert.Equal(t, successState, cars["#1"].state)
+	assert.Empty(t, cars["#1"].failures)
+	assert.Equal(t, pendingState, cars["#1,#2"].state)
+	assert.Equal(t, []int{1, 2}, prNumbers(trainOrder(cars)))
+}
@@ -18,26 +18,32 @@ package labels
 
 // labels for github plugins
 const (
-	Approved        = "approved"
-	BlockedPaths    = "do-not-merge/blocked-paths"
-	Bug             = "kind/bug"
-	ClaNo           = "cncf-cla: no"
-	ClaYes          = "cncf-cla: yes"
-	CpApproved      = "cherry-pick-approved"
-	CpUnapproved    = "do-not-merge/cherry-pick-not-approved"
-	GoodFirstIssue  = "good first issue"
-	Help            = "help wanted"
-	Hold            = "do-not-merge/hold"
-	InvalidOwners   = "do-not-merge/invalid-owners-file"
-	LGTM            = "lgtm"
-	LifecycleActive = "lifecycle/active"
-	LifecycleFrozen = "lifecycle/frozen"
-	LifecycleRotten = "lifecycle/rotten"
-	LifecycleStale  = "lifecycle/stale"
-	NeedsOkToTest   = "needs-ok-to-test"
-	NeedsRebase     = "needs-rebase"
-	NeedsSig        = "needs-sig"
-	OkToTest        = "ok-to-test"
-	Shrug           = "¯\\_(ツ)_/¯"
-	WorkInProgress  = "do-not-merge/work-in-progress"
+	Approved             = "approved"
+	BlockedPaths         = "do-not-merge/blocked-paths"
+	Bug                  = "kind/bug"
+	ClaNo                = "cncf-cla: no"
+	ClaYes               = "cncf-cla: yes"
+	CpApproved           = "cherry-pick-approved"
+	CpUnapproved         = "do-not-merge/cherry-pick-not-approved"
+	FirstTimeContributor = "first-time-contributor"
+	GoodFirstIssue       = "good first issue"
+	Help                 = "help wanted"
+	Hold                 = "do-not-merge/hold"
+	InvalidOwners        = "do-not-merge/invalid-owners-file"
+	LGTM                 = "lgtm"
+	LifecycleActive      = "lifecycle/active"
+	LifecycleFrozen      = "lifecycle/frozen"
+	LifecycleRotten      = "lifecycle/rotten"
+	LifecycleStale       = "lifecycle/stale"
+	MergeOverride        = "tide/merge-override"
+	NeedsDCO             = "needs-dco"
+	NeedsOkToTest        = "needs-ok-to-test"
+	NeedsRebase          = "needs-rebase"
+	NeedsSig             = "needs-sig"
+	OkToTest             = "ok-to-test"
+	ReleaseNote          = "release-note"
+	ReleaseNoteNone      = "release-note-none"
+	ReleaseNoteNeeded    = "do-not-merge/release-note-label-needed"
+	Shrug                = "¯\\_(ツ)_/¯"
+	WorkInProgress       = "do-not-merge/work-in-progress"
 )
@@ -1,8 +1,13 @@
 package launcher
 
 import (
+	"fmt"
+
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
 	clientset "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"github.com/pkg/errors"
 )
 
@@ -36,6 +41,30 @@ func (b *launcherImpl) Launch(request *v1alpha1.LighthouseJob) (*v1alpha1.Lighth
 	if err != nil {
 		return nil, errors.Wrapf(err, "unable to set status on LighthouseJob %s", appliedJob.Name)
 	}
-
+	if audit.Enabled() {
+		audit.Record(triggerEntry(fullyCreatedJob))
+	}
 	return fullyCreatedJob, nil
 }
+
+// triggerEntry returns the audit entry of the trigger of a job
+func triggerEntry(j *v1alpha1.LighthouseJob) audit.Entry {
+	entry := audit.Entry{
+		Action:    audit.ActionTrigger,
+		Actor:     j.Annotations[job.AuthorizedByAnnotation],
+		Target:    j.Spec.Job,
+		Details:   fmt.Sprintf("%s job %s", j.Spec.Type, j.Name),
+		Reason:    j.Annotations[job.AuthorizationAnnotation],
+		EventGUID: j.Labels[scmprovider.EventGUID],
+	}
+	if refs := j.Spec.Refs; refs != nil {
+		entry.Org = refs.Org
+		entry.Repo = refs.Repo
+		entry.Ref = refs.BaseSHA
+		if len(refs.Pulls) > 0 {
+			entry.Number = refs.Pulls[0].Number
+			entry.Ref = refs.Pulls[0].SHA
+		}
+	}
+	return entry
+}
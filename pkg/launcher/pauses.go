@@ -0,0 +1,33 @@
+package launcher
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+)
+
+// pausingLauncher does not launch the jobs of the repositories whose triggers are paused
+type pausingLauncher struct {
+	launcher PipelineLauncher
+	config   config.Getter
+}
+
+// NewPausingLauncher wraps the launcher so that it refuses to launch the jobs of the repositories whose triggers are
+// paused through the admin API
+func NewPausingLauncher(launcher PipelineLauncher, cfg config.Getter) PipelineLauncher {
+	return &pausingLauncher{launcher: launcher, config: cfg}
+}
+
+// Launch creates the pipeline unless the triggers of its repository are paused
+func (l *pausingLauncher) Launch(request *v1alpha1.LighthouseJob) (*v1alpha1.LighthouseJob, error) {
+	if refs := request.Spec.Refs; refs != nil {
+		if cfg := l.config(); cfg != nil {
+			if pause := cfg.Pauses.Paused(lighthouse.PauseTriggers, refs.Org, refs.Repo); pause != nil {
+				return nil, fmt.Errorf("not triggering job %s as the triggers of %s/%s are %s", request.Spec.Job, refs.Org, refs.Repo, pause.Description())
+			}
+		}
+	}
+	return l.launcher.Launch(request)
+}
@@ -0,0 +1,35 @@
+package launcher_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/launcher"
+	"github.com/jenkins-x/lighthouse/pkg/launcher/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPausingLauncher(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Pauses = lighthouse.Pauses{}
+	cfg.Pauses.Set(lighthouse.PauseTriggers, "org/paused", lighthouse.Pause{Reason: "migrating"})
+	fakeLauncher := fake.NewLauncher()
+	l := launcher.NewPausingLauncher(fakeLauncher, func() *config.Config { return cfg })
+
+	job := func(repo string) *v1alpha1.LighthouseJob {
+		return &v1alpha1.LighthouseJob{Spec: v1alpha1.LighthouseJobSpec{
+			Job:  "build",
+			Refs: &v1alpha1.Refs{Org: "org", Repo: repo},
+		}}
+	}
+	_, err := l.Launch(job("paused"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the triggers of org/paused are paused: migrating")
+	_, err = l.Launch(job("other"))
+	require.NoError(t, err)
+	require.Len(t, fakeLauncher.Pipelines, 1)
+	assert.Equal(t, "other", fakeLauncher.Pipelines[0].Spec.Refs.Repo)
+}
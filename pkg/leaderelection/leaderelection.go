@@ -0,0 +1,139 @@
+// Package leaderelection lets singleton controllers, such as keeper, run with multiple replicas where only the
+// elected leader does any work, and components whose replicas all serve requests run their periodic work on the
+// leader only.
+package leaderelection
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/interrupts"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+const (
+	leaseDuration = 15 * time.Second
+	renewDeadline = 10 * time.Second
+	retryPeriod   = 2 * time.Second
+)
+
+// Run starts the work once this replica has been elected the leader for the named Lease in the namespace.
+// If leadership is lost the process exits so that it restarts as a follower. This function is not blocking.
+// Callers are expected to exit only after interrupts.WaitForGracefulShutdown returns.
+func Run(kubeClient kubernetes.Interface, namespace, name string, work func()) error {
+	identity, lock, err := newLock(kubeClient, namespace, name)
+	if err != nil {
+		return err
+	}
+	logger := logrus.WithFields(logrus.Fields{"lease": name, "identity": identity})
+
+	interrupts.Run(func(ctx context.Context) {
+		elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+			Lock:            lock,
+			ReleaseOnCancel: true,
+			LeaseDuration:   leaseDuration,
+			RenewDeadline:   renewDeadline,
+			RetryPeriod:     retryPeriod,
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnStartedLeading: func(context.Context) {
+					logger.Info("started leading")
+					work()
+				},
+				OnStoppedLeading: func() {
+					if ctx.Err() != nil {
+						logger.Info("stopped leading as we are shutting down")
+						return
+					}
+					logger.Fatal("lost leadership")
+				},
+				OnNewLeader: func(leader string) {
+					if leader != identity {
+						logger.WithField("leader", leader).Info("another replica is the leader")
+					}
+				},
+			},
+		})
+		if err != nil {
+			logger.WithError(err).Fatal("failed to create leader elector")
+		}
+		elector.Run(ctx)
+	})
+	return nil
+}
+
+// Leadership tells whether this replica is the elected leader for a Lease
+type Leadership struct {
+	leading int32
+}
+
+// IsLeader returns whether this replica currently is the leader
+func (l *Leadership) IsLeader() bool {
+	return l != nil && atomic.LoadInt32(&l.leading) == 1
+}
+
+// Track takes part in the election of the leader for the named Lease in the namespace for the lifetime of the process.
+// Unlike Run, losing the leadership does not exit the process: the replica stands for election again so that the
+// work guarded by the returned Leadership moves to another replica. This function is not blocking.
+func Track(kubeClient kubernetes.Interface, namespace, name string) (*Leadership, error) {
+	identity, lock, err := newLock(kubeClient, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	logger := logrus.WithFields(logrus.Fields{"lease": name, "identity": identity})
+	l := &Leadership{}
+
+	interrupts.Run(func(ctx context.Context) {
+		elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+			Lock:            lock,
+			ReleaseOnCancel: true,
+			LeaseDuration:   leaseDuration,
+			RenewDeadline:   renewDeadline,
+			RetryPeriod:     retryPeriod,
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnStartedLeading: func(context.Context) {
+					logger.Info("started leading")
+					atomic.StoreInt32(&l.leading, 1)
+				},
+				OnStoppedLeading: func() {
+					atomic.StoreInt32(&l.leading, 0)
+					logger.Info("stopped leading")
+				},
+			},
+		})
+		if err != nil {
+			logger.WithError(err).Error("failed to create leader elector")
+			return
+		}
+		// the elector returns once the leadership is lost
+		for ctx.Err() == nil {
+			elector.Run(ctx)
+		}
+	})
+	return l, nil
+}
+
+func newLock(kubeClient kubernetes.Interface, namespace, name string) (string, resourcelock.Interface, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to determine the identity for leader election")
+	}
+	identity := hostname + "_" + string(uuid.NewUUID())
+	return identity, &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Client: kubeClient.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: identity,
+		},
+	}, nil
+}
@@ -0,0 +1,27 @@
+package leaderelection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestTrack(t *testing.T) {
+	var none *Leadership
+	assert.False(t, none.IsLeader())
+
+	kubeClient := fake.NewSimpleClientset()
+	first, err := Track(kubeClient, "jx", "test-lease")
+	require.NoError(t, err)
+	assert.Eventually(t, first.IsLeader, 10*time.Second, 100*time.Millisecond)
+
+	// the lease is held by the first replica
+	second, err := Track(kubeClient, "jx", "test-lease")
+	require.NoError(t, err)
+	time.Sleep(3 * retryPeriod)
+	assert.False(t, second.IsLeader())
+	assert.True(t, first.IsLeader())
+}
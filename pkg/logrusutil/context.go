@@ -0,0 +1,30 @@
+package logrusutil
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+type loggerKey struct{}
+
+// NewContext returns a copy of the context carrying the logger, so that the functions it is passed to log with the
+// same correlation fields, e.g. the event GUID, the repository, the pull request and the job
+func NewContext(ctx context.Context, logger *logrus.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger carried by the context, or a logger without any fields if there is none
+func FromContext(ctx context.Context) *logrus.Entry {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey{}).(*logrus.Entry); ok && logger != nil {
+			return logger
+		}
+	}
+	return logrus.NewEntry(logrus.StandardLogger())
+}
+
+// WithFields returns a copy of the context whose logger has the given fields added to those it already carries
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	return NewContext(ctx, FromContext(ctx).WithFields(fields))
+}
@@ -0,0 +1,89 @@
+package logrusutil
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextLogger(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, FromContext(ctx).Data)
+
+	ctx = NewContext(ctx, logrus.WithField("event-GUID", "abc"))
+	ctx = WithFields(ctx, logrus.Fields{"org": "jenkins-x", "repo": "lighthouse"})
+	assert.Equal(t, logrus.Fields{"event-GUID": "abc", "org": "jenkins-x", "repo": "lighthouse"}, FromContext(ctx).Data)
+}
+
+func TestLevelHandler(t *testing.T) {
+	defer logrus.SetLevel(logrus.GetLevel())
+	logrus.SetLevel(logrus.InfoLevel)
+
+	tests := []struct {
+		name         string
+		method       string
+		query        string
+		expectedCode int
+		expected     logrus.Level
+	}{
+		{
+			name:         "get",
+			method:       http.MethodGet,
+			expectedCode: http.StatusOK,
+			expected:     logrus.InfoLevel,
+		},
+		{
+			name:         "put",
+			method:       http.MethodPut,
+			query:        "?level=debug",
+			expectedCode: http.StatusOK,
+			expected:     logrus.DebugLevel,
+		},
+		{
+			name:         "invalid level",
+			method:       http.MethodPost,
+			query:        "?level=loud",
+			expectedCode: http.StatusBadRequest,
+			expected:     logrus.DebugLevel,
+		},
+		{
+			name:         "invalid method",
+			method:       http.MethodDelete,
+			expectedCode: http.StatusMethodNotAllowed,
+			expected:     logrus.DebugLevel,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			LevelHandler{}.ServeHTTP(rr, httptest.NewRequest(tc.method, LevelPath+tc.query, nil))
+			assert.Equal(t, tc.expectedCode, rr.Code)
+			assert.Equal(t, tc.expected, logrus.GetLevel())
+			if tc.expectedCode == http.StatusOK {
+				assert.Equal(t, tc.expected.String()+"\n", rr.Body.String())
+			}
+		})
+	}
+
+	// only the authorized requests change the level when the handler authorizes them
+	handler := LevelHandler{Authorize: func(r *http.Request) bool { return r.Header.Get("Authorization") == "Bearer secret" }}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, LevelPath+"?level=warn", nil))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Equal(t, logrus.DebugLevel, logrus.GetLevel())
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, LevelPath, nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, LevelPath+"?level=warn", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, logrus.WarnLevel, logrus.GetLevel())
+}
@@ -0,0 +1,44 @@
+package logrusutil
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LevelPath is the path the log level handler is usually served at
+const LevelPath = "/admin/log-level"
+
+// LevelHandler reports the log level on GET requests and changes it on PUT or POST requests with a level query
+// parameter, e.g. `curl -X PUT localhost:8888/admin/log-level?level=debug`. The level stays in effect until it is
+// changed again, either by this handler or by a configuration reload.
+type LevelHandler struct {
+	// Authorize, if set, authorizes the requests changing the level, the others being refused
+	Authorize func(*http.Request) bool
+}
+
+// ServeHTTP reports or changes the log level
+func (h LevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		if h.Authorize != nil && !h.Authorize(r) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		lvl, err := logrus.ParseLevel(r.URL.Query().Get("level"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if lvl != logrus.GetLevel() {
+			logrus.WithFields(logrus.Fields{"from": logrus.GetLevel().String(), "to": lvl.String()}).Info("changing the log level")
+			logrus.SetLevel(lvl)
+		}
+	default:
+		http.Error(w, "only GET, PUT and POST requests are supported", http.StatusMethodNotAllowed)
+		return
+	}
+	fmt.Fprintln(w, logrus.GetLevel().String())
+}
@@ -0,0 +1,300 @@
+// Package notifier posts the outcomes of the pipelines and of the merges of keeper to Slack or Microsoft Teams
+// channels, routed by repository, kind of event, job type and severity as configured in the notifications of the
+// Lighthouse config.
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"text/template"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultPipelineTemplate = `{{if .Recovered}}Recovered: {{end}}{{.Link}} {{.State}}{{if .Repo}} for {{.Org}}/{{.Repo}}{{if .Number}}#{{.Number}}{{else if .BaseRef}} on {{.BaseRef}}{{end}}{{end}}{{if .Duration}} after {{.Duration}}{{end}}`
+	defaultMergeTemplate    = `{{.Link}} merged into {{.BaseRef}} of {{.Org}}/{{.Repo}}{{if .Author}} by {{.Author}}{{end}}`
+)
+
+// Message is the data of the templates of the messages
+type Message struct {
+	// Event is the kind of outcome, pipeline or merge
+	Event string
+	// Severity is the severity of the outcome, info, warning or error
+	Severity string
+	// Org, Repo and Number identify the repository and pull request of the outcome, if any
+	Org    string
+	Repo   string
+	Number int
+	// Title and Author are the title and author of the pull request
+	Title  string
+	Author string
+	// BaseRef is the branch the pipeline ran against or the pull request was merged into
+	BaseRef string
+	// SHA is the commit the pipeline ran against or the merged commit
+	SHA string
+	// Job, JobType and Context describe the job of the pipeline
+	Job     string
+	JobType string
+	Context string
+	// State and Description are the state of the pipeline and its description
+	State       string
+	Description string
+	// Duration is how long the pipeline took
+	Duration string
+	// Recovered is whether the pipeline succeeded after the previous run of the job failed
+	Recovered bool
+	// URL is the URL of the pipeline or of the pull request
+	URL string
+	// Link is the job or the pull request, linking to the URL in the markup of the channel
+	Link string
+}
+
+// Merge is a pull request merged by keeper
+type Merge struct {
+	Org     string
+	Repo    string
+	Number  int
+	Title   string
+	Author  string
+	BaseRef string
+	SHA     string
+	URL     string
+}
+
+// Notifier posts the outcomes to the routes of the config
+type Notifier struct {
+	config config.Getter
+	client *http.Client
+	logger *logrus.Entry
+}
+
+// New creates a notifier posting to the routes of the config. The routes are read for each outcome so that config
+// changes apply immediately.
+func New(cfg config.Getter) *Notifier {
+	return &Notifier{
+		config: cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+		logger: logrus.WithField("component", "notifier"),
+	}
+}
+
+// Enabled returns whether any route is configured, so that callers can skip gathering what only notifications need
+func (n *Notifier) Enabled() bool {
+	return n != nil && len(n.routes()) > 0
+}
+
+func (n *Notifier) routes() []lighthouse.NotificationRoute {
+	cfg := n.config()
+	if cfg == nil {
+		return nil
+	}
+	return cfg.Notifications.Routes
+}
+
+// NotifyPipeline posts the outcome of a completed job to the matching routes. The previous state is the state of the
+// previous run of the same job, used to detect recoveries, or empty if unknown.
+func (n *Notifier) NotifyPipeline(job *v1alpha1.LighthouseJob, previous v1alpha1.PipelineState) {
+	if !n.Enabled() || !job.Complete() {
+		return
+	}
+	msg := Message{
+		Event:       lighthouse.NotificationEventPipeline,
+		Severity:    pipelineSeverity(job.Status.State),
+		Job:         job.Spec.Job,
+		JobType:     string(job.Spec.Type),
+		Context:     job.Spec.Context,
+		State:       string(job.Status.State),
+		Description: job.Status.Description,
+		URL:         job.Status.ReportURL,
+		Recovered:   job.Status.State == v1alpha1.SuccessState && isFailure(previous),
+	}
+	if refs := job.Spec.Refs; refs != nil {
+		msg.Org, msg.Repo, msg.BaseRef, msg.SHA = refs.Org, refs.Repo, refs.BaseRef, refs.BaseSHA
+		if len(refs.Pulls) > 0 {
+			pull := refs.Pulls[0]
+			msg.Number, msg.Title, msg.Author, msg.SHA = pull.Number, pull.Title, pull.Author, pull.SHA
+		}
+	}
+	if !job.Status.StartTime.IsZero() {
+		msg.Duration = job.Status.CompletionTime.Sub(job.Status.StartTime.Time).Round(time.Second).String()
+	}
+	for _, route := range n.routes() {
+		if !route.Matches(msg.Org, msg.Repo) || !acceptsEvent(&route, msg.Event) || !contains(route.JobTypes, msg.JobType) {
+			continue
+		}
+		if !acceptsPipeline(&route, &msg) {
+			continue
+		}
+		n.post(&route, msg, job.Spec.Job)
+	}
+}
+
+// NotifyMerge posts the merge of a pull request to the matching routes
+func (n *Notifier) NotifyMerge(merge Merge) {
+	if !n.Enabled() {
+		return
+	}
+	msg := Message{
+		Event:    lighthouse.NotificationEventMerge,
+		Severity: lighthouse.NotificationSeverityInfo,
+		Org:      merge.Org,
+		Repo:     merge.Repo,
+		Number:   merge.Number,
+		Title:    merge.Title,
+		Author:   merge.Author,
+		BaseRef:  merge.BaseRef,
+		SHA:      merge.SHA,
+		URL:      merge.URL,
+	}
+	for _, route := range n.routes() {
+		if !route.Matches(msg.Org, msg.Repo) || !acceptsEvent(&route, msg.Event) || !acceptsSeverity(&route, msg.Severity) {
+			continue
+		}
+		n.post(&route, msg, fmt.Sprintf("#%d %s", merge.Number, merge.Title))
+	}
+}
+
+// post renders the message for the route and posts it, logging failures as notifications are best effort
+func (n *Notifier) post(route *lighthouse.NotificationRoute, msg Message, linkText string) {
+	logger := n.logger.WithFields(logrus.Fields{"route": route.Name, "event": msg.Event, "org": msg.Org, "repo": msg.Repo})
+	msg.Link = link(route.Kind, msg.URL, linkText)
+	text, err := render(route, msg)
+	if err != nil {
+		logger.WithError(err).Error("failed to render the notification")
+		return
+	}
+	url := route.WebhookURL
+	if url == "" {
+		url = os.Getenv(route.WebhookURLEnv)
+	}
+	if url == "" {
+		logger.Errorf("no webhook URL in $%s", route.WebhookURLEnv)
+		return
+	}
+	body, err := json.Marshal(payload(route, msg.Severity, text))
+	if err != nil {
+		logger.WithError(err).Error("failed to marshal the notification")
+		return
+	}
+	resp, err := n.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		logger.WithError(err).Error("failed to post the notification")
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		logger.Errorf("failed to post the notification: status %s", resp.Status)
+		return
+	}
+	logger.Debug("posted the notification")
+}
+
+func render(route *lighthouse.NotificationRoute, msg Message) (string, error) {
+	text := route.Template
+	if text == "" {
+		text = defaultPipelineTemplate
+		if msg.Event == lighthouse.NotificationEventMerge {
+			text = defaultMergeTemplate
+		}
+	}
+	t, err := template.New(route.Name).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, msg); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// payload returns the body of the request posting the text to the incoming webhook of the route
+func payload(route *lighthouse.NotificationRoute, severity, text string) interface{} {
+	if route.Kind == lighthouse.NotificationKindTeams {
+		return map[string]string{
+			"@type":      "MessageCard",
+			"@context":   "https://schema.org/extensions",
+			"themeColor": themeColors[severity],
+			"summary":    text,
+			"text":       text,
+		}
+	}
+	answer := map[string]string{"text": text}
+	if route.Channel != "" {
+		answer["channel"] = route.Channel
+	}
+	return answer
+}
+
+var themeColors = map[string]string{
+	lighthouse.NotificationSeverityInfo:    "2EB886",
+	lighthouse.NotificationSeverityWarning: "DAA038",
+	lighthouse.NotificationSeverityError:   "A30200",
+}
+
+// link returns the text linking to the URL in the markup of the kind of channel
+func link(kind, url, text string) string {
+	if url == "" {
+		return text
+	}
+	if kind == lighthouse.NotificationKindTeams {
+		return fmt.Sprintf("[%s](%s)", text, url)
+	}
+	return fmt.Sprintf("<%s|%s>", url, text)
+}
+
+func pipelineSeverity(state v1alpha1.PipelineState) string {
+	switch {
+	case isFailure(state):
+		return lighthouse.NotificationSeverityError
+	case state == v1alpha1.AbortedState:
+		return lighthouse.NotificationSeverityWarning
+	}
+	return lighthouse.NotificationSeverityInfo
+}
+
+func isFailure(state v1alpha1.PipelineState) bool {
+	return state == v1alpha1.FailureState || state == v1alpha1.ErrorState
+}
+
+func acceptsEvent(route *lighthouse.NotificationRoute, event string) bool {
+	return contains(route.Events, event)
+}
+
+func acceptsSeverity(route *lighthouse.NotificationRoute, severity string) bool {
+	return lighthouse.SeverityLevel(severity) >= lighthouse.SeverityLevel(route.MinSeverity)
+}
+
+func acceptsPipeline(route *lighthouse.NotificationRoute, msg *Message) bool {
+	if !acceptsSeverity(route, msg.Severity) {
+		return false
+	}
+	switch route.When {
+	case lighthouse.NotifyOnFailure:
+		return msg.Severity == lighthouse.NotificationSeverityError
+	case lighthouse.NotifyOnChange:
+		return msg.Severity == lighthouse.NotificationSeverityError || msg.Recovered
+	}
+	return true
+}
+
+// contains returns whether the value is in the values, an empty list containing every value
+func contains(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
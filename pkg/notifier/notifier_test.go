@@ -0,0 +1,166 @@
+package notifier
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newJob(jobType job.PipelineKind, state v1alpha1.PipelineState, refs *v1alpha1.Refs) *v1alpha1.LighthouseJob {
+	start := metav1.NewTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	completion := metav1.NewTime(start.Add(95 * time.Second))
+	return &v1alpha1.LighthouseJob{
+		Spec: v1alpha1.LighthouseJobSpec{Type: jobType, Job: "unit", Refs: refs},
+		Status: v1alpha1.LighthouseJobStatus{
+			State:          state,
+			ReportURL:      "https://dashboard/unit",
+			StartTime:      start,
+			CompletionTime: &completion,
+		},
+	}
+}
+
+func TestNotifyPipeline(t *testing.T) {
+	pr := &v1alpha1.Refs{Org: "myorg", Repo: "myrepo", BaseRef: "main", Pulls: []v1alpha1.Pull{{Number: 7, Author: "someone"}}}
+	branch := &v1alpha1.Refs{Org: "myorg", Repo: "other", BaseRef: "main"}
+
+	testCases := []struct {
+		name     string
+		route    lighthouse.NotificationRoute
+		job      *v1alpha1.LighthouseJob
+		previous v1alpha1.PipelineState
+		expected map[string]string
+	}{
+		{
+			name:     "slack",
+			route:    lighthouse.NotificationRoute{Kind: lighthouse.NotificationKindSlack, Channel: "#ci"},
+			job:      newJob(job.PresubmitJob, v1alpha1.FailureState, pr),
+			expected: map[string]string{"channel": "#ci", "text": "<https://dashboard/unit|unit> failure for myorg/myrepo#7 after 1m35s"},
+		},
+		{
+			name:  "teams",
+			route: lighthouse.NotificationRoute{Kind: lighthouse.NotificationKindTeams},
+			job:   newJob(job.PostsubmitJob, v1alpha1.SuccessState, branch),
+			expected: map[string]string{
+				"@type":      "MessageCard",
+				"@context":   "https://schema.org/extensions",
+				"themeColor": "2EB886",
+				"summary":    "[unit](https://dashboard/unit) success for myorg/other on main after 1m35s",
+				"text":       "[unit](https://dashboard/unit) success for myorg/other on main after 1m35s",
+			},
+		},
+		{
+			name:  "other repository",
+			route: lighthouse.NotificationRoute{Kind: lighthouse.NotificationKindSlack, Repos: []string{"myorg/other"}},
+			job:   newJob(job.PresubmitJob, v1alpha1.FailureState, pr),
+		},
+		{
+			name:  "other job type",
+			route: lighthouse.NotificationRoute{Kind: lighthouse.NotificationKindSlack, JobTypes: []string{"periodic"}},
+			job:   newJob(job.PresubmitJob, v1alpha1.FailureState, pr),
+		},
+		{
+			name:  "periodic",
+			route: lighthouse.NotificationRoute{Kind: lighthouse.NotificationKindSlack, Repos: []string{"myorg"}, JobTypes: []string{"periodic"}, Template: "{{.Job}} {{.State}}"},
+			job:   newJob(job.PeriodicJob, v1alpha1.AbortedState, nil),
+		},
+		{
+			name:     "periodic without repository",
+			route:    lighthouse.NotificationRoute{Kind: lighthouse.NotificationKindSlack, JobTypes: []string{"periodic"}, Template: "{{.Job}} {{.State}}"},
+			job:      newJob(job.PeriodicJob, v1alpha1.AbortedState, nil),
+			expected: map[string]string{"text": "unit aborted"},
+		},
+		{
+			name:  "below the minimum severity",
+			route: lighthouse.NotificationRoute{Kind: lighthouse.NotificationKindSlack, MinSeverity: "warning"},
+			job:   newJob(job.PostsubmitJob, v1alpha1.SuccessState, branch),
+		},
+		{
+			name:  "merges only",
+			route: lighthouse.NotificationRoute{Kind: lighthouse.NotificationKindSlack, Events: []string{"merge"}},
+			job:   newJob(job.PostsubmitJob, v1alpha1.FailureState, branch),
+		},
+		{
+			name:  "success on failure",
+			route: lighthouse.NotificationRoute{Kind: lighthouse.NotificationKindSlack, When: lighthouse.NotifyOnFailure},
+			job:   newJob(job.PostsubmitJob, v1alpha1.SuccessState, branch),
+		},
+		{
+			name:     "success on change",
+			route:    lighthouse.NotificationRoute{Kind: lighthouse.NotificationKindSlack, When: lighthouse.NotifyOnChange},
+			job:      newJob(job.PostsubmitJob, v1alpha1.SuccessState, branch),
+			previous: v1alpha1.SuccessState,
+		},
+		{
+			name:     "recovery on change",
+			route:    lighthouse.NotificationRoute{Kind: lighthouse.NotificationKindSlack, When: lighthouse.NotifyOnChange},
+			job:      newJob(job.PostsubmitJob, v1alpha1.SuccessState, branch),
+			previous: v1alpha1.FailureState,
+			expected: map[string]string{"text": "Recovered: <https://dashboard/unit|unit> success for myorg/other on main after 1m35s"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var received []map[string]string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body := map[string]string{}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				received = append(received, body)
+			}))
+			defer server.Close()
+
+			route := tc.route
+			route.WebhookURL = server.URL
+			cfg := &config.Config{}
+			cfg.Notifications.Routes = []lighthouse.NotificationRoute{route}
+			require.NoError(t, cfg.Notifications.Parse())
+
+			New(func() *config.Config { return cfg }).NotifyPipeline(tc.job, tc.previous)
+			if tc.expected == nil {
+				assert.Empty(t, received)
+				return
+			}
+			require.Len(t, received, 1)
+			assert.Equal(t, tc.expected, received[0])
+		})
+	}
+}
+
+func TestNotifyMerge(t *testing.T) {
+	var received []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]string{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		received = append(received, body)
+	}))
+	defer server.Close()
+	os.Setenv("NOTIFIER_TEST_URL", server.URL)
+	defer os.Unsetenv("NOTIFIER_TEST_URL")
+
+	cfg := &config.Config{}
+	cfg.Notifications.Routes = []lighthouse.NotificationRoute{
+		{Kind: lighthouse.NotificationKindSlack, WebhookURLEnv: "NOTIFIER_TEST_URL", Events: []string{"merge"}},
+		{Kind: lighthouse.NotificationKindSlack, WebhookURLEnv: "NOTIFIER_TEST_URL", Events: []string{"pipeline"}},
+	}
+	require.NoError(t, cfg.Notifications.Parse())
+
+	n := New(func() *config.Config { return cfg })
+	n.NotifyMerge(Merge{Org: "myorg", Repo: "myrepo", Number: 7, Title: "Add a feature", Author: "someone", BaseRef: "main", URL: "https://github.com/myorg/myrepo/pull/7"})
+	require.Len(t, received, 1)
+	assert.Equal(t, map[string]string{"text": "<https://github.com/myorg/myrepo/pull/7|#7 Add a feature> merged into main of myorg/myrepo by someone"}, received[0])
+
+	var disabled *Notifier
+	assert.False(t, disabled.Enabled())
+	disabled.NotifyMerge(Merge{})
+}
@@ -30,6 +30,7 @@ import (
 	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
 	"github.com/jenkins-x/lighthouse/pkg/labels"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/sirupsen/logrus"
@@ -51,7 +52,7 @@ type scmProviderClient interface {
 }
 
 type pruneClient interface {
-	PruneComments(bool, func(ic *scm.Comment) bool)
+	PruneFunc(isObsolete func(*scm.Comment) bool) error
 }
 
 func init() {
@@ -96,7 +97,7 @@ type client struct {
 }
 
 func handlePullRequest(pc plugins.Agent, pre scm.PullRequestHook) error {
-	cp, err := pc.CommentPruner()
+	cp, err := pc.BotCommentClient()
 	if err != nil {
 		return err
 	}
@@ -167,15 +168,15 @@ func handle(spc scmProviderClient, log *logrus.Entry, config []plugins.Blockade,
 		if err := spc.AddLabel(org, repo, prNumber, labels.BlockedPaths, true); err != nil {
 			return err
 		}
-		msg := plugins.FormatResponse(spc.QuoteAuthorForComment(pre.PullRequest.Author.Login), blockedPathsBody, sum.String())
+		msg := plugins.FormatResponse(spc.QuoteAuthorForComment(pre.PullRequest.Author.Login), blockedPathsBody, sum.String()) + "\n" + botcomment.Marker(pluginName)
 		return spc.CreateComment(org, repo, prNumber, true, msg)
 	} else if !shouldBlock && labelPresent {
 		// Remove the label and delete any comments created by this plugin.
 		if err := spc.RemoveLabel(org, repo, prNumber, labels.BlockedPaths, true); err != nil {
 			return err
 		}
-		cp.PruneComments(true, func(ic *scm.Comment) bool {
-			return strings.Contains(ic.Body, blockedPathsBody)
+		return cp.PruneFunc(func(ic *scm.Comment) bool {
+			return botcomment.HasMarker(ic.Body, pluginName) || strings.Contains(ic.Body, blockedPathsBody)
 		})
 	}
 	return nil
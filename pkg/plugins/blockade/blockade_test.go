@@ -195,7 +195,7 @@ func formatLabel(label string) string {
 
 type fakePruner struct{}
 
-func (f *fakePruner) PruneComments(_ bool, _ func(ic *scm.Comment) bool) {}
+func (f *fakePruner) PruneFunc(_ func(*scm.Comment) bool) error { return nil }
 
 // TestHandle validates that:
 // - The correct labels are added/removed.
@@ -25,6 +25,7 @@ import (
 	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"github.com/sirupsen/logrus"
 
@@ -67,11 +68,11 @@ type scmProviderClient interface {
 }
 
 type commentPruner interface {
-	PruneComments(pr bool, shouldPrune func(*scm.Comment) bool)
+	PruneFunc(isObsolete func(*scm.Comment) bool) error
 }
 
 func handlePullRequest(pc plugins.Agent, pr scm.PullRequestHook) error {
-	cp, err := pc.CommentPruner()
+	cp, err := pc.BotCommentClient()
 	if err != nil {
 		return err
 	}
@@ -119,9 +120,11 @@ func handlePR(spc scmProviderClient, log *logrus.Entry, pr *scm.PullRequestHook,
 				log.WithError(err).Errorf("GitHub failed to remove the following label: %s", labels.CpUnapproved)
 			}
 		}
-		cp.PruneComments(true, func(comment *scm.Comment) bool {
-			return strings.Contains(comment.Body, commentBody)
-		})
+		if err := cp.PruneFunc(func(comment *scm.Comment) bool {
+			return botcomment.HasMarker(comment.Body, pluginName) || strings.Contains(comment.Body, commentBody)
+		}); err != nil {
+			log.WithError(err).Errorf("Failed to prune the %s comments", pluginName)
+		}
 		return nil
 	}
 
@@ -135,7 +138,7 @@ func handlePR(spc scmProviderClient, log *logrus.Entry, pr *scm.PullRequestHook,
 		log.WithError(err).Errorf("GitHub failed to add the following label: %s", labels.CpUnapproved)
 	}
 
-	formattedComment := plugins.FormatSimpleResponse(spc.QuoteAuthorForComment(pr.PullRequest.Author.Login), commentBody)
+	formattedComment := plugins.FormatSimpleResponse(spc.QuoteAuthorForComment(pr.PullRequest.Author.Login), commentBody) + "\n" + botcomment.Marker(pluginName)
 	if err := spc.CreateComment(org, repo, prNumber, true, formattedComment); err != nil {
 		log.WithError(err).Errorf("Failed to comment %q", formattedComment)
 	}
@@ -90,7 +90,7 @@ func (fc *fakeClient) QuoteAuthorForComment(author string) string {
 
 type fakePruner struct{}
 
-func (fp *fakePruner) PruneComments(pr bool, shouldPrune func(*scm.Comment) bool) {}
+func (fp *fakePruner) PruneFunc(isObsolete func(*scm.Comment) bool) error { return nil }
 
 func makeFakePullRequestEvent(action scm.Action, branch string) scm.PullRequestHook {
 	return scm.PullRequestHook{
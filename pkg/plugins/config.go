@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"path"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -43,6 +44,11 @@ type Configuration struct {
 	// https://github.com/kubernetes/test-infra/issues/3476
 	Plugins map[string][]string `json:"plugins,omitempty"`
 
+	// DisabledPlugins is a map of repositories (eg "k/k") to lists of plugin
+	// names which are not run for the repository even though they are enabled
+	// for its org.
+	DisabledPlugins map[string][]string `json:"disabled_plugins,omitempty"`
+
 	// ExternalPlugins is a map of repositories (eg "k/k") to lists of
 	// external plugins.
 	ExternalPlugins map[string][]ExternalPlugin `json:"external_plugins,omitempty"`
@@ -55,15 +61,20 @@ type Configuration struct {
 	Blockades            []Blockade             `json:"blockades,omitempty"`
 	Cat                  Cat                    `json:"cat,omitempty"`
 	CherryPickUnapproved CherryPickUnapproved   `json:"cherry_pick_unapproved,omitempty"`
+	CommentTemplates     CommentTemplates       `json:"comment_templates,omitempty"`
 	ConfigUpdater        ConfigUpdater          `json:"config_updater,omitempty"`
+	Dco                  map[string]*Dco        `json:"dco,omitempty"`
 	Heart                Heart                  `json:"heart,omitempty"`
 	Label                Label                  `json:"label,omitempty"`
 	Lgtm                 []Lgtm                 `json:"lgtm,omitempty"`
+	NeedsRebase          NeedsRebase            `json:"needs_rebase,omitempty"`
+	Policy               map[string]*Policy     `json:"policy,omitempty"`
 	RepoMilestone        map[string]Milestone   `json:"repo_milestone,omitempty"`
 	RequireMatchingLabel []RequireMatchingLabel `json:"require_matching_label,omitempty"`
 	RequireSIG           RequireSIG             `json:"requiresig,omitempty"`
 	SigMention           SigMention             `json:"sigmention,omitempty"`
 	Size                 Size                   `json:"size,omitempty"`
+	StatusReconcile      StatusReconcile        `json:"status_reconcile,omitempty"`
 	Triggers             []Trigger              `json:"triggers,omitempty"`
 	Welcome              []Welcome              `json:"welcome,omitempty"`
 }
@@ -293,15 +304,52 @@ type Trigger struct {
 	JoinOrgURL string `json:"join_org_url,omitempty"`
 	// OnlyOrgMembers requires PRs and/or /ok-to-test comments to come from org members.
 	// By default, trigger also include repo collaborators.
+	// It is equivalent to the org_members trust policy.
 	OnlyOrgMembers bool `json:"only_org_members,omitempty"`
+	// TrustPolicy decides whose PRs are tested automatically and who may trigger
+	// tests with /test and /retest or mark PRs as trusted with /ok-to-test:
+	// collaborators (the default) trusts repo collaborators and org members,
+	// org_members only trusts org members, team only trusts the members of
+	// TrustedTeam and anyone trusts everybody.
+	TrustPolicy string `json:"trust_policy,omitempty"`
+	// TrustedTeam is the name of the team of the org, or of TrustedOrg if set,
+	// whose members are trusted by the team trust policy.
+	TrustedTeam string `json:"trusted_team,omitempty"`
 	// IgnoreOkToTest makes trigger ignore /ok-to-test comments.
 	// This is a security mitigation to only allow testing from trusted users.
 	IgnoreOkToTest bool `json:"ignore_ok_to_test,omitempty"`
+	// RequireOkToTestForNewCommits removes the ok-to-test label when an untrusted
+	// author pushes new commits, so that they are not tested until a trusted user
+	// reviews them and leaves a new /ok-to-test.
+	RequireOkToTestForNewCommits bool `json:"require_ok_to_test_for_new_commits,omitempty"`
 	// ElideSkippedContexts makes trigger not post "Skipped" contexts for jobs
 	// that could run but do not run.
 	ElideSkippedContexts bool `json:"elide_skipped_contexts,omitempty"`
 }
 
+const (
+	// TrustCollaborators trusts repo collaborators and members of the org and trusted org
+	TrustCollaborators = "collaborators"
+	// TrustOrgMembers trusts members of the org and trusted org
+	TrustOrgMembers = "org_members"
+	// TrustTeam trusts members of the trusted team
+	TrustTeam = "team"
+	// TrustAnyone trusts everybody, e.g. for private repositories
+	TrustAnyone = "anyone"
+)
+
+// Policy returns the trust policy of the trigger, defaulting to the org_members
+// policy if OnlyOrgMembers is set or the collaborators policy otherwise.
+func (t *Trigger) Policy() string {
+	if t.TrustPolicy != "" {
+		return t.TrustPolicy
+	}
+	if t.OnlyOrgMembers {
+		return TrustOrgMembers
+	}
+	return TrustCollaborators
+}
+
 // Heart contains the configuration for the heart plugin.
 type Heart struct {
 	// Adorees is a list of GitHub logins for members
@@ -369,6 +417,97 @@ type Welcome struct {
 	// MessageTemplate is the welcome message template to post on new-contributor PRs
 	// For the info struct see prow/plugins/welcome/welcome.go's PRInfo
 	MessageTemplate string `json:"message_template,omitempty"`
+	// ContributingURL is the link to the contribution guide added to the welcome message
+	ContributingURL string `json:"contributing_url,omitempty"`
+	// OrgWide only welcomes contributors on their first PR to any repository of the org, found using the
+	// search API of the SCM provider, rather than on their first PR to each repository
+	OrgWide bool `json:"org_wide,omitempty"`
+}
+
+// Dco is config for the DCO (https://developercertificate.org/) checker plugin.
+type Dco struct {
+	// SkipDCOCheckForMembers is used to skip the DCO check for trusted org members
+	SkipDCOCheckForMembers bool `json:"skip_dco_check_for_members,omitempty"`
+	// TrustedOrg is the org whose members' commits will not be checked for DCO signoff
+	// if the skip DCO option is enabled. The default is the PR's org.
+	TrustedOrg string `json:"trusted_org,omitempty"`
+	// CLAURL is the URL of a CLA service checked instead of the Signed-off-by lines of the commits. It is sent
+	// a GET request with the org, repo and login of each commit author as query parameters, and must reply
+	// with a JSON object with a boolean signed field.
+	CLAURL string `json:"cla_url,omitempty"`
+	// ContributingURL is the link explaining how to sign off commits, added to the comment left on PRs
+	// which are missing a signoff.
+	ContributingURL string `json:"contributing_url,omitempty"`
+}
+
+// Policy is the config for the policy plugin, which checks the titles of the pull requests and the messages of their
+// commits against regular expressions, e.g. to enforce conventional commits or references to JIRA issues.
+type Policy struct {
+	// Context is the commit status context reported by the plugin. Defaults to "policy".
+	Context string `json:"context,omitempty"`
+	// Title are the rules the titles of the pull requests must satisfy.
+	Title []PolicyRule `json:"title,omitempty"`
+	// Commits are the rules the messages of the commits of the pull requests must satisfy. The whole message is
+	// matched, use (?m) for the rules to match its lines.
+	Commits []PolicyRule `json:"commits,omitempty"`
+	// SkipCommitsRegexp is a regular expression matching the messages of the commits which are not checked, e.g.
+	// ^Merge to skip the merge commits. Compiles into SkipCommitsRe during config load.
+	SkipCommitsRegexp string         `json:"skip_commits_regexp,omitempty"`
+	SkipCommitsRe     *regexp.Regexp `json:"-"`
+	// GuidelinesURL is the link explaining the policy, added to the status and to the comment listing the violations.
+	GuidelinesURL string `json:"guidelines_url,omitempty"`
+}
+
+// PolicyRule is a regular expression the title of a pull request or the message of a commit must match.
+type PolicyRule struct {
+	// Name describes the rule in the comment listing the violations, e.g. "conventional commit".
+	Name string `json:"name"`
+	// Regexp must match the title or the message, e.g. ^(feat|fix|chore)(\(.+\))?!?: .+
+	// Compiles into Re during config load.
+	Regexp string         `json:"regexp"`
+	Re     *regexp.Regexp `json:"-"`
+}
+
+func (p *Policy) compile() error {
+	for _, rules := range [][]PolicyRule{p.Title, p.Commits} {
+		for i := range rules {
+			if rules[i].Regexp == "" {
+				return fmt.Errorf("rule %q has no regexp", rules[i].Name)
+			}
+			re, err := regexp.Compile(rules[i].Regexp)
+			if err != nil {
+				return fmt.Errorf("failed to compile the regexp of rule %q: %v", rules[i].Name, err)
+			}
+			rules[i].Re = re
+		}
+	}
+	if p.SkipCommitsRegexp != "" {
+		re, err := regexp.Compile(p.SkipCommitsRegexp)
+		if err != nil {
+			return fmt.Errorf("failed to compile skip_commits_regexp: %v", err)
+		}
+		p.SkipCommitsRe = re
+	}
+	return nil
+}
+
+// NeedsRebase is the config for the needs-rebase plugin.
+type NeedsRebase struct {
+	// SweepInterval is how often all the open pull requests of the repositories the plugin is enabled for are
+	// checked for merge conflicts, e.g. caused by changes to their base branch. Defaults to 1h, 0 disables it.
+	SweepInterval string `json:"sweep_interval,omitempty"`
+	// SweepIntervalDuration is compiled from SweepInterval at load time.
+	SweepIntervalDuration time.Duration `json:"-"`
+}
+
+// StatusReconcile is the config for reconciling the statuses of the open pull requests of the repositories the
+// trigger plugin is enabled for, to recover from webhooks which were not delivered or not processed.
+type StatusReconcile struct {
+	// SweepInterval is how often the statuses of the required contexts of all the open pull requests are checked, the
+	// missing ones being reported again or their jobs triggered. Defaults to 1h, 0 disables it.
+	SweepInterval string `json:"sweep_interval,omitempty"`
+	// SweepIntervalDuration is compiled from SweepInterval at load time.
+	SweepIntervalDuration time.Duration `json:"-"`
 }
 
 // CherryPickUnapproved is the config for the cherrypick-unapproved plugin.
@@ -503,6 +642,28 @@ func (c *Configuration) TriggerFor(org, repo string) *Trigger {
 	return &Trigger{}
 }
 
+// DcoFor finds the Dco for a repo, the config for the repo overrides the one for the owning organization
+func (c *Configuration) DcoFor(org, repo string) *Dco {
+	if dco, ok := c.Dco[fmt.Sprintf("%s/%s", org, repo)]; ok && dco != nil {
+		return dco
+	}
+	if dco, ok := c.Dco[org]; ok && dco != nil {
+		return dco
+	}
+	return &Dco{}
+}
+
+// PolicyFor finds the Policy for a repo, the config for the repo overrides the one for the owning organization
+func (c *Configuration) PolicyFor(org, repo string) *Policy {
+	if policy, ok := c.Policy[fmt.Sprintf("%s/%s", org, repo)]; ok && policy != nil {
+		return policy
+	}
+	if policy, ok := c.Policy[org]; ok && policy != nil {
+		return policy
+	}
+	return &Policy{}
+}
+
 // EnabledReposForPlugin returns the orgs and repos that have enabled the passed plugin.
 func (c *Configuration) EnabledReposForPlugin(plugin string) (orgs, repos []string) {
 	for repo, plugins := range c.Plugins {
@@ -611,6 +772,12 @@ The list of patch release managers for each release can be found [here](https://
 			c.RequireMatchingLabel[i].GracePeriod = "5s"
 		}
 	}
+	if c.NeedsRebase.SweepInterval == "" {
+		c.NeedsRebase.SweepInterval = "1h"
+	}
+	if c.StatusReconcile.SweepInterval == "" {
+		c.StatusReconcile.SweepInterval = "1h"
+	}
 }
 
 // ValidatePluginsArePresent takes a map with plugin names as keys and errors or logs for each configured plugin that can't be found.
@@ -634,6 +801,22 @@ func (c *Configuration) ValidatePluginsArePresent(presentPlugins map[string]inte
 	return nil
 }
 
+// validateDisabledPlugins will return error if
+// plugins are disabled for an org rather than a repository.
+func validateDisabledPlugins(disabled map[string][]string) error {
+	var errList []string
+	for repo := range disabled {
+		if len(strings.Split(repo, "/")) != 2 {
+			errList = append(errList, fmt.Sprintf("plugins can only be disabled for a repository of the form org/repo, not %s", repo))
+		}
+	}
+	if len(errList) > 0 {
+		sort.Strings(errList)
+		return fmt.Errorf("invalid plugin configuration:\n\t%v", strings.Join(errList, "\n\t"))
+	}
+	return nil
+}
+
 // validatePlugins will return error if
 // there are unknown or duplicated plugins.
 func validatePlugins(plugins map[string][]string) error {
@@ -730,6 +913,21 @@ func validateConfigUpdater(updater *ConfigUpdater) error {
 	return nil
 }
 
+func validateTriggers(triggers []Trigger) error {
+	for i, t := range triggers {
+		switch t.Policy() {
+		case TrustCollaborators, TrustOrgMembers, TrustAnyone:
+		case TrustTeam:
+			if t.TrustedTeam == "" {
+				return fmt.Errorf("trigger #%d for %v: trusted_team must be set for the %s trust policy", i, t.Repos, TrustTeam)
+			}
+		default:
+			return fmt.Errorf("trigger #%d for %v: invalid trust_policy %q, must be one of %s, %s, %s or %s", i, t.Repos, t.TrustPolicy, TrustCollaborators, TrustOrgMembers, TrustTeam, TrustAnyone)
+		}
+	}
+	return nil
+}
+
 func validateRequireMatchingLabel(rs []RequireMatchingLabel) error {
 	for i, r := range rs {
 		if err := r.validate(); err != nil {
@@ -773,6 +971,27 @@ func compileRegexpsAndDurations(pc *Configuration) error {
 		}
 		rs[i].GracePeriodDuration = dur
 	}
+
+	for key, policy := range pc.Policy {
+		if policy == nil {
+			continue
+		}
+		if err := policy.compile(); err != nil {
+			return fmt.Errorf("invalid policy for %s: %v", key, err)
+		}
+	}
+
+	sweepInterval, err := time.ParseDuration(pc.NeedsRebase.SweepInterval)
+	if err != nil {
+		return fmt.Errorf("failed to compile needs-rebase sweep interval: %q, error: %v", pc.NeedsRebase.SweepInterval, err)
+	}
+	pc.NeedsRebase.SweepIntervalDuration = sweepInterval
+
+	reconcileInterval, err := time.ParseDuration(pc.StatusReconcile.SweepInterval)
+	if err != nil {
+		return fmt.Errorf("failed to compile status reconcile sweep interval: %q, error: %v", pc.StatusReconcile.SweepInterval, err)
+	}
+	pc.StatusReconcile.SweepIntervalDuration = reconcileInterval
 	return nil
 }
 
@@ -792,18 +1011,27 @@ func (c *Configuration) Validate() error {
 	if err := validatePlugins(c.Plugins); err != nil {
 		return err
 	}
+	if err := validateDisabledPlugins(c.DisabledPlugins); err != nil {
+		return err
+	}
 	if err := validateExternalPlugins(c.ExternalPlugins); err != nil {
 		return err
 	}
 	if err := validateConfigUpdater(&c.ConfigUpdater); err != nil {
 		return err
 	}
+	if err := validateTriggers(c.Triggers); err != nil {
+		return err
+	}
 	if err := validateSizes(c.Size); err != nil {
 		return err
 	}
 	if err := validateRequireMatchingLabel(c.RequireMatchingLabel); err != nil {
 		return err
 	}
+	if err := validateCommentTemplates(c.CommentTemplates); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -24,6 +24,68 @@ import (
 	"k8s.io/utils/diff"
 )
 
+func TestValidateDisabledPlugins(t *testing.T) {
+	tests := []struct {
+		name        string
+		disabled    map[string][]string
+		expectedErr error
+	}{
+		{
+			name:     "valid config",
+			disabled: map[string][]string{"kubernetes/test-infra": {"cat"}},
+		},
+		{
+			name:        "disabled for an org",
+			disabled:    map[string][]string{"kubernetes": {"cat"}, "kubernetes/test-infra": {"dog"}},
+			expectedErr: errors.New("invalid plugin configuration:\n\tplugins can only be disabled for a repository of the form org/repo, not kubernetes"),
+		},
+	}
+
+	for _, test := range tests {
+		err := validateDisabledPlugins(test.disabled)
+		if !reflect.DeepEqual(err, test.expectedErr) {
+			t.Errorf("%s: unexpected error: %v, expected: %v", test.name, err, test.expectedErr)
+		}
+	}
+}
+
+func TestValidateTriggers(t *testing.T) {
+	tests := []struct {
+		name        string
+		triggers    []Trigger
+		expectedErr string
+	}{
+		{
+			name: "valid policies",
+			triggers: []Trigger{
+				{Repos: []string{"org"}},
+				{Repos: []string{"other"}, OnlyOrgMembers: true},
+				{Repos: []string{"team"}, TrustPolicy: TrustTeam, TrustedTeam: "leads"},
+				{Repos: []string{"private"}, TrustPolicy: TrustAnyone},
+			},
+		},
+		{
+			name:        "team policy without team",
+			triggers:    []Trigger{{Repos: []string{"org"}, TrustPolicy: TrustTeam}},
+			expectedErr: "trigger #0 for [org]: trusted_team must be set for the team trust policy",
+		},
+		{
+			name:        "invalid policy",
+			triggers:    []Trigger{{Repos: []string{"org"}}, {Repos: []string{"org/repo"}, TrustPolicy: "members"}},
+			expectedErr: `trigger #1 for [org/repo]: invalid trust_policy "members", must be one of collaborators, org_members, team or anyone`,
+		},
+	}
+
+	for _, test := range tests {
+		err := validateTriggers(test.triggers)
+		if test.expectedErr == "" && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		} else if test.expectedErr != "" && (err == nil || err.Error() != test.expectedErr) {
+			t.Errorf("%s: unexpected error: %v, expected: %v", test.name, err, test.expectedErr)
+		}
+	}
+}
+
 func TestValidateExternalPlugins(t *testing.T) {
 	tests := []struct {
 		name        string
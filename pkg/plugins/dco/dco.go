@@ -0,0 +1,345 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dco implements a plugin which checks that all the commits of a pull request are signed off according to
+// the Developer Certificate of Origin, or that their authors signed a CLA.
+package dco
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	pluginName = "dco"
+	// statusContext is the commit status context reported by the plugin
+	statusContext = "dco"
+
+	defaultContributingURL = "https://developercertificate.org/"
+
+	dcoMsgPruneMatch = "Thanks for your pull request. Before we can look at it, you'll need to"
+	dcoMsg           = `Thanks for your pull request. Before we can look at it, you'll need to {{if .CLA}}sign our CLA{{else}}add a 'DCO signoff' to your commits{{end}}.
+
+{{if .CLA}}The following commit authors have not signed the CLA{{else}}The following commits are missing a ` + "`Signed-off-by`" + ` line{{end}}:
+{{range .Commits}}
+* {{.}}{{end}}
+
+Please see [the contribution guide]({{.ContributingURL}}) for more details. Once this is fixed, comment ` + "`/check-dco`" + ` to check again.`
+)
+
+var (
+	testRe = regexp.MustCompile(`(?mi)^signed-off-by:`)
+
+	// httpClient is used to query the CLA service
+	httpClient = &http.Client{Timeout: 30 * time.Second}
+)
+
+// DcoInfo contains the info provided to the dco comment template
+type DcoInfo struct {
+	Org             string
+	Repo            string
+	Number          int
+	CLA             bool
+	Commits         []string
+	ContributingURL string
+}
+
+var (
+	plugin = plugins.Plugin{
+		Description:        "The dco plugin checks that all the commits of a pull request have a 'Signed-off-by' line, or that their authors signed a CLA, reporting a '" + statusContext + "' status and adding the '" + labels.NeedsDCO + "' label when they are not.",
+		ConfigHelpProvider: configHelp,
+		PullRequestHandler: handlePullRequest,
+		Commands: []plugins.Command{{
+			Name:        "check-dco",
+			Description: "Forces rechecking of the DCO status.",
+			WhoCanUse:   "Anyone can trigger this command on a PR.",
+			Action: plugins.
+				Invoke(func(match plugins.CommandMatch, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
+					return handleComment(pc, e)
+				}).
+				When(plugins.Action(scm.ActionCreate), plugins.IsPR(), plugins.IssueState("open")),
+		}},
+	}
+)
+
+func init() {
+	plugins.RegisterCommentTemplate(pluginName, dcoMsg)
+	plugins.RegisterPlugin(pluginName, plugin)
+}
+
+func configHelp(config *plugins.Configuration, enabledRepos []string) (map[string]string, error) {
+	configInfo := map[string]string{}
+	for _, orgRepo := range enabledRepos {
+		parts := strings.SplitN(orgRepo, "/", 2)
+		repo := ""
+		if len(parts) == 2 {
+			repo = parts[1]
+		}
+		opts := config.DcoFor(parts[0], repo)
+		if opts.CLAURL != "" {
+			configInfo[orgRepo] = fmt.Sprintf("Commit authors are checked against the CLA service %s.", opts.CLAURL)
+		} else if opts.SkipDCOCheckForMembers {
+			configInfo[orgRepo] = "The commits of trusted org members are not checked for a signoff."
+		}
+	}
+	return configInfo, nil
+}
+
+type scmProviderClient interface {
+	AddLabel(owner, repo string, number int, label string, pr bool) error
+	RemoveLabel(owner, repo string, number int, label string, pr bool) error
+	GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error)
+	CreateComment(owner, repo string, number int, pr bool, comment string) error
+	EditComment(owner, repo string, number int, id int, comment string, pr bool) error
+	DeleteComment(owner, repo string, number, id int, pr bool) error
+	ListIssueComments(org, repo string, number int) ([]*scm.Comment, error)
+	ListPullRequestComments(org, repo string, number int) ([]*scm.Comment, error)
+	CreateStatus(owner, repo, ref string, s *scm.StatusInput) (*scm.Status, error)
+	GetPullRequest(org, repo string, number int) (*scm.PullRequest, error)
+	ListPullRequestCommits(owner, repo string, number int) ([]*scm.Commit, error)
+	IsMember(org, user string) (bool, error)
+	BotName() (string, error)
+}
+
+func handlePullRequest(pc plugins.Agent, pre scm.PullRequestHook) error {
+	switch pre.Action {
+	case scm.ActionOpen, scm.ActionReopen, scm.ActionSync:
+	default:
+		return nil
+	}
+	org := pre.Repo.Namespace
+	repo := pre.Repo.Name
+	return handle(pc.Logger, pc.SCMProviderClient, pc.PluginConfig, org, repo, &pre.PullRequest)
+}
+
+func handleComment(pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
+	org := e.Repo.Namespace
+	repo := e.Repo.Name
+	pr, err := pc.SCMProviderClient.GetPullRequest(org, repo, e.Number)
+	if err != nil {
+		return fmt.Errorf("failed to get pull request %s/%s#%d: %v", org, repo, e.Number, err)
+	}
+	return handle(pc.Logger, pc.SCMProviderClient, pc.PluginConfig, org, repo, pr)
+}
+
+func handle(log *logrus.Entry, spc scmProviderClient, config *plugins.Configuration, org, repo string, pr *scm.PullRequest) error {
+	opts := config.DcoFor(org, repo)
+	commits, err := spc.ListPullRequestCommits(org, repo, pr.Number)
+	if err != nil {
+		return fmt.Errorf("failed to list the commits of %s/%s#%d: %v", org, repo, pr.Number, err)
+	}
+
+	commits, err = filterTrustedCommits(spc, opts, org, commits)
+	if err != nil {
+		return err
+	}
+
+	var failing []string
+	if opts.CLAURL != "" {
+		failing, err = unsignedCLAAuthors(opts.CLAURL, org, repo, commits)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, commit := range commits {
+			if !testRe.MatchString(commit.Message) {
+				failing = append(failing, fmt.Sprintf("%s %s", shortSHA(commit.Sha), firstLine(commit.Message)))
+			}
+		}
+	}
+
+	contributingURL := opts.ContributingURL
+	if contributingURL == "" {
+		contributingURL = defaultContributingURL
+	}
+	info := DcoInfo{
+		Org:             org,
+		Repo:            repo,
+		Number:          pr.Number,
+		CLA:             opts.CLAURL != "",
+		Commits:         failing,
+		ContributingURL: contributingURL,
+	}
+	return takeAction(log, spc, config, pr, info)
+}
+
+func takeAction(log *logrus.Entry, spc scmProviderClient, config *plugins.Configuration, pr *scm.PullRequest, info DcoInfo) error {
+	org, repo, number := info.Org, info.Repo, info.Number
+	passing := len(info.Commits) == 0
+
+	status := &scm.StatusInput{
+		Label:  statusContext,
+		State:  scm.StateSuccess,
+		Desc:   "All commits are signed off",
+		Target: info.ContributingURL,
+	}
+	if info.CLA {
+		status.Desc = "All commit authors signed the CLA"
+	}
+	if !passing {
+		status.State = scm.StateFailure
+		status.Desc = "Commits in PR missing Signed-off-by"
+		if info.CLA {
+			status.Desc = "Commit authors have not signed the CLA"
+		}
+	}
+	if _, err := spc.CreateStatus(org, repo, pr.Head.Sha, status); err != nil {
+		return fmt.Errorf("failed to report the %s status: %v", statusContext, err)
+	}
+
+	issueLabels, err := spc.GetIssueLabels(org, repo, number, true)
+	if err != nil {
+		return fmt.Errorf("failed to get the labels of %s/%s#%d: %v", org, repo, number, err)
+	}
+	hasLabel := scmprovider.HasLabel(labels.NeedsDCO, issueLabels)
+
+	comments := botcomment.NewClient(spc, org, repo, number, true)
+	if passing {
+		if hasLabel {
+			log.Infof("Removing %q label", labels.NeedsDCO)
+			if err := spc.RemoveLabel(org, repo, number, labels.NeedsDCO, true); err != nil {
+				return err
+			}
+		}
+		return comments.PruneFunc(func(c *scm.Comment) bool {
+			return botcomment.HasMarker(c.Body, pluginName) || isLegacyComment(c)
+		})
+	}
+
+	if !hasLabel {
+		log.Infof("Adding %q label", labels.NeedsDCO)
+		if err := spc.AddLabel(org, repo, number, labels.NeedsDCO, true); err != nil {
+			return err
+		}
+	}
+	if err := comments.PruneFunc(isLegacyComment); err != nil {
+		return err
+	}
+	msg, err := config.RenderComment(pluginName, org, repo, info)
+	if err != nil {
+		return err
+	}
+	// update any previous comment as the failing commits may have changed
+	return comments.Upsert(pluginName, msg)
+}
+
+// filterTrustedCommits removes the commits authored by members of the trusted org if they are not checked
+func filterTrustedCommits(spc scmProviderClient, opts *plugins.Dco, org string, commits []*scm.Commit) ([]*scm.Commit, error) {
+	if !opts.SkipDCOCheckForMembers {
+		return commits, nil
+	}
+	trustedOrg := opts.TrustedOrg
+	if trustedOrg == "" {
+		trustedOrg = org
+	}
+	members := map[string]bool{}
+	var answer []*scm.Commit
+	for _, commit := range commits {
+		login := commit.Author.Login
+		if login != "" {
+			member, ok := members[login]
+			if !ok {
+				var err error
+				member, err = spc.IsMember(trustedOrg, login)
+				if err != nil {
+					return nil, fmt.Errorf("failed to check if %s is a member of %s: %v", login, trustedOrg, err)
+				}
+				members[login] = member
+			}
+			if member {
+				continue
+			}
+		}
+		answer = append(answer, commit)
+	}
+	return answer, nil
+}
+
+type claResponse struct {
+	Signed bool `json:"signed"`
+}
+
+// unsignedCLAAuthors queries the CLA service for each commit author, returning the ones who have not signed it
+func unsignedCLAAuthors(claURL, org, repo string, commits []*scm.Commit) ([]string, error) {
+	var answer []string
+	checked := map[string]bool{}
+	for _, commit := range commits {
+		login := commit.Author.Login
+		if login == "" {
+			// the author's email is not linked to an account on the git provider
+			login = commit.Author.Email
+		}
+		if checked[login] {
+			continue
+		}
+		checked[login] = true
+
+		u, err := url.Parse(claURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CLA service URL %s: %v", claURL, err)
+		}
+		q := u.Query()
+		q.Set("org", org)
+		q.Set("repo", repo)
+		q.Set("login", login)
+		u.RawQuery = q.Encode()
+		resp, err := httpClient.Get(u.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to query the CLA service: %v", err)
+		}
+		var cla claResponse
+		err = json.NewDecoder(resp.Body).Decode(&cla)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("the CLA service returned status %d for %s", resp.StatusCode, login)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the CLA service response for %s: %v", login, err)
+		}
+		if !cla.Signed {
+			answer = append(answer, login)
+		}
+	}
+	return answer, nil
+}
+
+// isLegacyComment finds the comments left by this plugin before they carried a marker.
+func isLegacyComment(comment *scm.Comment) bool {
+	return !botcomment.HasMarker(comment.Body, pluginName) && strings.Contains(comment.Body, dcoMsgPruneMatch)
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func firstLine(message string) string {
+	return strings.SplitN(message, "\n", 2)[0]
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dco
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const headSHA = "abcdef1234567890"
+
+func commit(sha, login, message string) *scm.Commit {
+	return &scm.Commit{
+		Sha:     sha,
+		Message: message,
+		Author:  scm.Signature{Login: login, Email: login + "@example.com"},
+	}
+}
+
+func TestHandle(t *testing.T) {
+	signed := commit("1111111111", "alice", "Fix a bug\n\nSigned-off-by: Alice <alice@example.com>")
+	unsigned := commit("2222222222", "bob", "Add a feature")
+	member := commit("3333333333", "carol", "Tweak the docs")
+
+	testCases := []struct {
+		name            string
+		commits         []*scm.Commit
+		dco             *plugins.Dco
+		hasLabel        bool
+		existingComment bool
+		markedComment   bool
+		expectState     scm.State
+		expectAdded     bool
+		expectRemoved   bool
+		expectComment   bool
+		expectEdited    bool
+		expectPruned    bool
+	}{
+		{
+			name:        "all commits signed off",
+			commits:     []*scm.Commit{signed},
+			expectState: scm.StateSuccess,
+		},
+		{
+			name:          "commit missing signoff",
+			commits:       []*scm.Commit{signed, unsigned},
+			expectState:   scm.StateFailure,
+			expectAdded:   true,
+			expectComment: true,
+		},
+		{
+			name:            "commit still missing signoff replaces the comment",
+			commits:         []*scm.Commit{unsigned},
+			hasLabel:        true,
+			existingComment: true,
+			expectState:     scm.StateFailure,
+			expectComment:   true,
+			expectPruned:    true,
+		},
+		{
+			name:            "commit still missing signoff updates the marked comment",
+			commits:         []*scm.Commit{unsigned},
+			hasLabel:        true,
+			existingComment: true,
+			markedComment:   true,
+			expectState:     scm.StateFailure,
+			expectEdited:    true,
+		},
+		{
+			name:            "signoff fixed",
+			commits:         []*scm.Commit{signed},
+			hasLabel:        true,
+			existingComment: true,
+			expectState:     scm.StateSuccess,
+			expectRemoved:   true,
+			expectPruned:    true,
+		},
+		{
+			name:        "trusted org members are skipped",
+			commits:     []*scm.Commit{signed, member},
+			dco:         &plugins.Dco{SkipDCOCheckForMembers: true, TrustedOrg: "trusted"},
+			expectState: scm.StateSuccess,
+		},
+		{
+			name:          "only trusted org members are skipped",
+			commits:       []*scm.Commit{unsigned, member},
+			dco:           &plugins.Dco{SkipDCOCheckForMembers: true, TrustedOrg: "trusted"},
+			expectState:   scm.StateFailure,
+			expectAdded:   true,
+			expectComment: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := &fake.SCMClient{
+				PullRequestCommits:  map[int][]*scm.Commit{1: tc.commits},
+				PullRequestComments: map[int][]*scm.Comment{},
+				IssueComments:       map[int][]*scm.Comment{},
+				OrgMembers:          map[string][]string{"trusted": {"carol"}},
+			}
+			if tc.hasLabel {
+				fc.PullRequestLabelsExisting = []string{"org/repo#1:" + labels.NeedsDCO}
+			}
+			botName, err := fc.BotName()
+			require.NoError(t, err)
+			if tc.existingComment {
+				body := dcoMsgPruneMatch + " add a 'DCO signoff'"
+				if tc.markedComment {
+					body += "\n" + botcomment.Marker(pluginName)
+				}
+				fc.PullRequestComments[1] = []*scm.Comment{{ID: 1, Body: body, Author: scm.User{Login: botName}}}
+			}
+			config := &plugins.Configuration{}
+			if tc.dco != nil {
+				config.Dco = map[string]*plugins.Dco{"org": tc.dco}
+			}
+			pr := &scm.PullRequest{Number: 1, Head: scm.PullRequestBranch{Sha: headSHA}}
+
+			err = handle(logrus.WithField("plugin", pluginName), fc, config, "org", "repo", pr)
+			require.NoError(t, err)
+
+			require.Len(t, fc.CreatedStatuses[headSHA], 1)
+			status := fc.CreatedStatuses[headSHA][0]
+			assert.Equal(t, statusContext, status.Label)
+			assert.Equal(t, tc.expectState, status.State)
+			assert.Equal(t, tc.expectAdded, len(fc.PullRequestLabelsAdded) == 1, "label added")
+			assert.Equal(t, tc.expectRemoved, len(fc.PullRequestLabelsRemoved) == 1, "label removed")
+			assert.Equal(t, tc.expectComment, len(fc.PullRequestCommentsAdded) == 1, "comment added")
+			assert.Equal(t, tc.expectEdited, len(fc.PullRequestCommentsEdited) == 1, "comment edited")
+			assert.Equal(t, tc.expectPruned, len(fc.PullRequestCommentsDeleted) == 1, "comment pruned")
+			if tc.expectComment {
+				assert.Contains(t, fc.PullRequestCommentsAdded[0], "* 2222222 Add a feature")
+			}
+		})
+	}
+}
+
+func TestHandleCLA(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "org", r.URL.Query().Get("org"))
+		assert.Equal(t, "repo", r.URL.Query().Get("repo"))
+		fmt.Fprintf(w, `{"signed": %t}`, r.URL.Query().Get("login") == "alice")
+	}))
+	defer server.Close()
+
+	fc := &fake.SCMClient{
+		PullRequestCommits: map[int][]*scm.Commit{1: {
+			commit("1111111111", "alice", "Fix a bug"),
+			commit("2222222222", "bob", "Add a feature"),
+			commit("3333333333", "bob", "Add another feature"),
+		}},
+		PullRequestComments: map[int][]*scm.Comment{},
+		IssueComments:       map[int][]*scm.Comment{},
+	}
+	config := &plugins.Configuration{
+		Dco: map[string]*plugins.Dco{"org/repo": {CLAURL: server.URL + "/check"}},
+	}
+	pr := &scm.PullRequest{Number: 1, Head: scm.PullRequestBranch{Sha: headSHA}}
+
+	err := handle(logrus.WithField("plugin", pluginName), fc, config, "org", "repo", pr)
+	require.NoError(t, err)
+
+	require.Len(t, fc.CreatedStatuses[headSHA], 1)
+	assert.Equal(t, scm.StateFailure, fc.CreatedStatuses[headSHA][0].State)
+	assert.Equal(t, []string{"org/repo#1:" + labels.NeedsDCO}, fc.PullRequestLabelsAdded)
+	require.Len(t, fc.PullRequestCommentsAdded, 1)
+	assert.Contains(t, fc.PullRequestCommentsAdded[0], "sign our CLA")
+	assert.Contains(t, fc.PullRequestCommentsAdded[0], "* bob\n")
+	assert.NotContains(t, fc.PullRequestCommentsAdded[0], "alice")
+}
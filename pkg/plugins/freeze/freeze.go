@@ -0,0 +1,160 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package freeze contains a plugin which lets repo administrators freeze merges into a branch, e.g. during a
+// release stabilization period. The freeze is an issue with the keeper blocker label, which Keeper honours by
+// only merging the PRs with the merge override label.
+package freeze
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/config/keeper"
+	"github.com/jenkins-x/lighthouse/pkg/keeper/blockers"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	pluginName = "freeze"
+)
+
+var (
+	plugin = plugins.Plugin{
+		Description:        "The freeze plugin lets repo administrators freeze merges into a branch, or all the branches of a repository, by opening an issue with the keeper blocker label. Keeper only merges the PRs with the merge override label until the freeze is lifted.",
+		ConfigHelpProvider: configHelp,
+		Commands: []plugins.Command{{
+			Prefix: "un",
+			Name:   "freeze",
+			Arg: &plugins.CommandArg{
+				Usage:    "branch",
+				Pattern:  `[\w-./]+`,
+				Optional: true,
+			},
+			Description: "Freezes merges into the given branch, or all the branches of the repository if none is given. `/unfreeze` lifts the freeze.",
+			WhoCanUse:   "Repo administrators",
+			Action: plugins.
+				Invoke(func(match plugins.CommandMatch, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
+					return handle(pc.SCMProviderClient, pc.Logger, pc.Config.Keeper, match.Prefix != "un", match.Arg, &e)
+				}).
+				When(plugins.Action(scm.ActionCreate)),
+		}},
+	}
+)
+
+func init() {
+	plugins.RegisterPlugin(pluginName, plugin)
+}
+
+func configHelp(config *plugins.Configuration, enabledRepos []string) (map[string]string, error) {
+	return map[string]string{
+		"": "The freeze issues are labelled with the `blocker_label` of the keeper config, which must be set.",
+	}, nil
+}
+
+type scmProviderClient interface {
+	CreateComment(owner, repo string, number int, pr bool, comment string) error
+	CreateIssue(owner, repo, title, body string) (*scm.Issue, error)
+	CloseIssue(owner, repo string, number int) error
+	AddLabel(owner, repo string, number int, label string, pr bool) error
+	Search(opts scm.SearchOptions) ([]*scm.SearchIssue, *scmprovider.RateLimits, error)
+	HasPermission(org, repo, user string, roles ...string) (bool, error)
+	QuoteAuthorForComment(string) string
+}
+
+// title returns the title of the freeze issue of a branch, using the branch syntax Keeper parses from blocker issues
+func title(branch string) string {
+	if branch == "" {
+		return blockers.FreezeTitle
+	}
+	return fmt.Sprintf("%s branch:%s", blockers.FreezeTitle, branch)
+}
+
+func handle(spc scmProviderClient, log *logrus.Entry, config keeper.Config, freeze bool, branch string, e *scmprovider.GenericCommentEvent) error {
+	org := e.Repo.Namespace
+	repo := e.Repo.Name
+	user := e.Author.Login
+	target := "all branches"
+	if branch != "" {
+		target = fmt.Sprintf("branch `%s`", branch)
+	}
+	respond := func(msg string) error {
+		return spc.CreateComment(org, repo, e.Number, e.IsPR, plugins.FormatResponseRaw(e.Body, e.Link, spc.QuoteAuthorForComment(user), msg))
+	}
+
+	ok, err := spc.HasPermission(org, repo, user, scmprovider.RoleAdmin)
+	if err != nil {
+		return fmt.Errorf("failed to check whether %s is an admin of %s/%s: %v", user, org, repo, err)
+	}
+	if !ok {
+		return respond("Only repo administrators can freeze or unfreeze merges.")
+	}
+	label := config.BlockerLabel
+	if label == "" {
+		return respond("Merges cannot be frozen as no `blocker_label` is configured for keeper.")
+	}
+
+	results, _, err := spc.Search(scm.SearchOptions{
+		Query: fmt.Sprintf("is:issue is:open repo:%s/%s label:\"%s\"", org, repo, label),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to search the blocker issues of %s/%s: %v", org, repo, err)
+	}
+	var existing []int
+	for _, r := range results {
+		if !r.PullRequest && !r.Closed && r.Title == title(branch) {
+			existing = append(existing, r.Number)
+		}
+	}
+
+	if !freeze {
+		if len(existing) == 0 {
+			return respond(fmt.Sprintf("Merges into %s are not frozen.", target))
+		}
+		for _, number := range existing {
+			log.Infof("Closing freeze issue #%d", number)
+			if err := spc.CloseIssue(org, repo, number); err != nil {
+				return fmt.Errorf("failed to close the freeze issue %s/%s#%d: %v", org, repo, number, err)
+			}
+		}
+		return respond(fmt.Sprintf("Merges into %s are no longer frozen.", target))
+	}
+
+	if len(existing) > 0 {
+		return respond(fmt.Sprintf("Merges into %s are already frozen by #%d.", target, existing[0]))
+	}
+	body := fmt.Sprintf("Merges into %s of this repository are frozen by %s (see #%d).\n\n"+
+		"Only the pull requests with the `%s` label will be merged until this issue is closed or `/unfreeze%s` is commented.",
+		target, spc.QuoteAuthorForComment(user), e.Number, config.MergeOverrideLabel, branchArg(branch))
+	issue, err := spc.CreateIssue(org, repo, title(branch), body)
+	if err != nil {
+		return fmt.Errorf("failed to create the freeze issue in %s/%s: %v", org, repo, err)
+	}
+	log.Infof("Created freeze issue #%d", issue.Number)
+	if err := spc.AddLabel(org, repo, issue.Number, label, false); err != nil {
+		return fmt.Errorf("failed to label the freeze issue %s/%s#%d: %v", org, repo, issue.Number, err)
+	}
+	return respond(fmt.Sprintf("Merges into %s are now frozen by #%d. Pull requests with the `%s` label are still merged.", target, issue.Number, config.MergeOverrideLabel))
+}
+
+func branchArg(branch string) string {
+	if branch == "" {
+		return ""
+	}
+	return " " + branch
+}
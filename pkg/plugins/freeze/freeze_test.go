@@ -0,0 +1,177 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package freeze
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/config/keeper"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	*fake.SCMClient
+	admins []string
+}
+
+func (f *fakeClient) HasPermission(org, repo, user string, roles ...string) (bool, error) {
+	for _, admin := range f.admins {
+		if admin == user {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeClient) Search(opts scm.SearchOptions) ([]*scm.SearchIssue, *scmprovider.RateLimits, error) {
+	var results []*scm.SearchIssue
+	for _, issues := range f.Issues {
+		for _, issue := range issues {
+			if !issue.Closed {
+				results = append(results, &scm.SearchIssue{Issue: *issue})
+			}
+		}
+	}
+	return results, &scmprovider.RateLimits{}, nil
+}
+
+func TestHandle(t *testing.T) {
+	testCases := []struct {
+		name           string
+		user           string
+		freeze         bool
+		branch         string
+		blockerLabel   string
+		existingIssues map[int][]*scm.Issue
+
+		expectedIssues  map[int]string
+		expectedClosed  []int
+		expectedLabels  []string
+		expectedComment string
+	}{
+		{
+			name:            "non admin cannot freeze",
+			user:            "bob",
+			freeze:          true,
+			branch:          "release-1.0",
+			blockerLabel:    "merge-blocker",
+			expectedComment: "Only repo administrators can freeze or unfreeze merges.",
+		},
+		{
+			name:            "no blocker label configured",
+			user:            "admin",
+			freeze:          true,
+			expectedComment: "no `blocker_label` is configured",
+		},
+		{
+			name:            "freeze a branch",
+			user:            "admin",
+			freeze:          true,
+			branch:          "release-1.0",
+			blockerLabel:    "merge-blocker",
+			expectedIssues:  map[int]string{1: "Merge freeze branch:release-1.0"},
+			expectedLabels:  []string{"org/repo#1:merge-blocker"},
+			expectedComment: "Merges into branch `release-1.0` are now frozen by #1.",
+		},
+		{
+			name:            "freeze all branches",
+			user:            "admin",
+			freeze:          true,
+			blockerLabel:    "merge-blocker",
+			expectedIssues:  map[int]string{1: "Merge freeze"},
+			expectedLabels:  []string{"org/repo#1:merge-blocker"},
+			expectedComment: "Merges into all branches are now frozen by #1.",
+		},
+		{
+			name:         "branch already frozen",
+			user:         "admin",
+			freeze:       true,
+			branch:       "release-1.0",
+			blockerLabel: "merge-blocker",
+			existingIssues: map[int][]*scm.Issue{
+				1: {{Number: 1, Title: "Merge freeze branch:release-1.0"}},
+			},
+			expectedIssues:  map[int]string{1: "Merge freeze branch:release-1.0"},
+			expectedComment: "Merges into branch `release-1.0` are already frozen by #1.",
+		},
+		{
+			name:         "unfreeze a branch",
+			user:         "admin",
+			branch:       "release-1.0",
+			blockerLabel: "merge-blocker",
+			existingIssues: map[int][]*scm.Issue{
+				1: {{Number: 1, Title: "Merge freeze branch:release-1.0"}},
+				2: {{Number: 2, Title: "Merge freeze branch:release-2.0"}},
+			},
+			expectedIssues:  map[int]string{1: "Merge freeze branch:release-1.0", 2: "Merge freeze branch:release-2.0"},
+			expectedClosed:  []int{1},
+			expectedComment: "Merges into branch `release-1.0` are no longer frozen.",
+		},
+		{
+			name:            "unfreeze a branch which is not frozen",
+			user:            "admin",
+			branch:          "release-1.0",
+			blockerLabel:    "merge-blocker",
+			expectedComment: "Merges into branch `release-1.0` are not frozen.",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := &fakeClient{
+				SCMClient: &fake.SCMClient{
+					Issues:        tc.existingIssues,
+					IssueComments: map[int][]*scm.Comment{},
+				},
+				admins: []string{"admin"},
+			}
+			e := &scmprovider.GenericCommentEvent{
+				Repo:   scm.Repository{Namespace: "org", Name: "repo"},
+				Number: 10,
+				Author: scm.User{Login: tc.user},
+				Body:   "/freeze",
+			}
+			config := keeper.Config{BlockerLabel: tc.blockerLabel, MergeOverrideLabel: labels.MergeOverride}
+
+			err := handle(fc, logrus.WithField("plugin", pluginName), config, tc.freeze, tc.branch, e)
+			require.NoError(t, err)
+
+			issues := map[int]string{}
+			var closed []int
+			for number, list := range fc.Issues {
+				issues[number] = list[0].Title
+				if list[0].Closed {
+					closed = append(closed, number)
+				}
+			}
+			if tc.expectedIssues == nil {
+				tc.expectedIssues = map[int]string{}
+			}
+			assert.Equal(t, tc.expectedIssues, issues)
+			assert.Equal(t, tc.expectedClosed, closed)
+			assert.Equal(t, tc.expectedLabels, fc.IssueLabelsAdded)
+			require.Len(t, fc.IssueCommentsAdded, 1)
+			assert.Contains(t, fc.IssueCommentsAdded[0], tc.expectedComment)
+		})
+	}
+}
@@ -20,13 +20,17 @@ import (
 	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
 	"github.com/jenkins-x/lighthouse/pkg/labels"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"github.com/sirupsen/logrus"
 )
 
-const pluginName = "help"
+const (
+	pluginName                 = "help"
+	goodFirstIssueTemplateName = "good-first-issue"
+)
 
 var (
 	helpGuidelinesURL = "https://git.k8s.io/community/contributors/guide/help-wanted.md"
@@ -34,7 +38,7 @@ var (
 	helpMsg           = `
 This request has been marked as needing help from a contributor.
 
-Please ensure the request meets the requirements listed [here](` + helpGuidelinesURL + `).
+Please ensure the request meets the requirements listed [here]({{.GuidelinesURL}}).
 
 If this request no longer meets these requirements, the label can be removed
 by commenting with the ` + "`/remove-help`" + ` command.
@@ -43,13 +47,21 @@ by commenting with the ` + "`/remove-help`" + ` command.
 	goodFirstIssueMsg           = `
 This request has been marked as suitable for new contributors.
 
-Please ensure the request meets the requirements listed [here](` + helpGuidelinesURL + "#good-first-issue" + `).
+Please ensure the request meets the requirements listed [here]({{.GuidelinesURL}}#good-first-issue).
 
 If this request no longer meets these requirements, the label can be removed
 by commenting with the ` + "`/remove-good-first-issue`" + ` command.
 `
 )
 
+// IssueInfo contains the info provided to the help and good-first-issue comment templates
+type IssueInfo struct {
+	Org           string
+	Repo          string
+	Number        int
+	GuidelinesURL string
+}
+
 var (
 	plugin = plugins.Plugin{
 		Description: "The help plugin provides commands that add or remove the '" + labels.Help + "' and the '" + labels.GoodFirstIssue + "' labels from issues.",
@@ -57,14 +69,14 @@ var (
 			Prefix:      "remove-",
 			Name:        "help|good-first-issue",
 			Description: "Applies or removes the '" + labels.Help + "' and '" + labels.GoodFirstIssue + "' labels to an issue.",
-			WhoCanUse:   "Anyone can trigger this command on a PR.",
+			WhoCanUse:   "Anyone can trigger this command on an issue.",
 			Action: plugins.
 				Invoke(func(match plugins.CommandMatch, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
-					cp, err := pc.CommentPruner()
+					cp, err := pc.BotCommentClient()
 					if err != nil {
 						return err
 					}
-					return handle(match.Prefix != "", match.Name, pc.SCMProviderClient, pc.Logger, cp, &e)
+					return handle(match.Prefix != "", match.Name, pc.SCMProviderClient, pc.Logger, cp, pc.PluginConfig, &e)
 				}).
 				When(plugins.Action(scm.ActionCreate), plugins.IsNotPR(), plugins.IssueState("open")),
 		}},
@@ -72,11 +84,12 @@ var (
 )
 
 func init() {
+	plugins.RegisterCommentTemplate(pluginName, helpMsg)
+	plugins.RegisterCommentTemplate(goodFirstIssueTemplateName, goodFirstIssueMsg)
 	plugins.RegisterPlugin(pluginName, plugin)
 }
 
 type scmProviderClient interface {
-	BotName() (string, error)
 	CreateComment(owner, repo string, number int, pr bool, comment string) error
 	AddLabel(owner, repo string, number int, label string, pr bool) error
 	RemoveLabel(owner, repo string, number int, label string, pr bool) error
@@ -85,13 +98,19 @@ type scmProviderClient interface {
 }
 
 type commentPruner interface {
-	PruneComments(pr bool, shouldPrune func(*scm.Comment) bool)
+	PruneFunc(isObsolete func(*scm.Comment) bool) error
 }
 
-func handle(remove bool, command string, spc scmProviderClient, log *logrus.Entry, cp commentPruner, e *scmprovider.GenericCommentEvent) error {
+func handle(remove bool, command string, spc scmProviderClient, log *logrus.Entry, cp commentPruner, config *plugins.Configuration, e *scmprovider.GenericCommentEvent) error {
 	org := e.Repo.Namespace
 	repo := e.Repo.Name
 	commentAuthor := e.Author.Login
+	info := IssueInfo{
+		Org:           org,
+		Repo:          repo,
+		Number:        e.Number,
+		GuidelinesURL: helpGuidelinesURL,
+	}
 
 	// Determine if the issue has the help and the good-first-issue label
 	issueLabels, err := spc.GetIssueLabels(org, repo, e.Number, e.IsPR)
@@ -106,19 +125,18 @@ func handle(remove bool, command string, spc scmProviderClient, log *logrus.Entr
 		if err := spc.RemoveLabel(org, repo, e.Number, labels.Help, e.IsPR); err != nil {
 			log.WithError(err).Errorf("GitHub failed to remove the following label: %s", labels.Help)
 		}
-
-		botName, err := spc.BotName()
-		if err != nil {
-			log.WithError(err).Errorf("Failed to get bot name.")
+		if err := cp.PruneFunc(isObsolete(pluginName, pruneMatches(config, pluginName, info, helpMsgPruneMatch)...)); err != nil {
+			log.WithError(err).Errorf("Failed to prune the %s comments.", pluginName)
 		}
-		cp.PruneComments(e.IsPR, shouldPrune(log, botName, helpMsgPruneMatch))
 
 		// if it has the good-first-issue label, remove it too
 		if hasGoodFirstIssue {
 			if err := spc.RemoveLabel(org, repo, e.Number, labels.GoodFirstIssue, e.IsPR); err != nil {
 				log.WithError(err).Errorf("GitHub failed to remove the following label: %s", labels.GoodFirstIssue)
 			}
-			cp.PruneComments(e.IsPR, shouldPrune(log, botName, goodFirstIssueMsgPruneMatch))
+			if err := cp.PruneFunc(isObsolete(goodFirstIssueTemplateName, pruneMatches(config, goodFirstIssueTemplateName, info, goodFirstIssueMsgPruneMatch)...)); err != nil {
+				log.WithError(err).Errorf("Failed to prune the %s comments.", goodFirstIssueTemplateName)
+			}
 		}
 
 		return nil
@@ -127,8 +145,12 @@ func handle(remove bool, command string, spc scmProviderClient, log *logrus.Entr
 	// If PR does not have the good-first-issue label and we are asking for it to be added,
 	// add both the good-first-issue and help labels
 	if !hasGoodFirstIssue && command == "good-first-issue" && !remove {
-		if err := spc.CreateComment(org, repo, e.Number, e.IsPR, plugins.FormatResponseRaw(e.Body, e.IssueLink, spc.QuoteAuthorForComment(commentAuthor), goodFirstIssueMsg)); err != nil {
-			log.WithError(err).Errorf("Failed to create comment \"%s\".", goodFirstIssueMsg)
+		msg, err := config.RenderComment(goodFirstIssueTemplateName, org, repo, info)
+		if err != nil {
+			return err
+		}
+		if err := spc.CreateComment(org, repo, e.Number, e.IsPR, plugins.FormatResponseRaw(e.Body, e.IssueLink, spc.QuoteAuthorForComment(commentAuthor), msg)+"\n"+botcomment.Marker(goodFirstIssueTemplateName)); err != nil {
+			log.WithError(err).Errorf("Failed to create comment \"%s\".", msg)
 		}
 
 		if err := spc.AddLabel(org, repo, e.Number, labels.GoodFirstIssue, e.IsPR); err != nil {
@@ -147,8 +169,12 @@ func handle(remove bool, command string, spc scmProviderClient, log *logrus.Entr
 	// If PR does not have the help label and we're asking it to be added,
 	// add the label
 	if !hasHelp && command == "help" && !remove {
-		if err := spc.CreateComment(org, repo, e.Number, e.IsPR, plugins.FormatResponseRaw(e.Body, e.IssueLink, spc.QuoteAuthorForComment(commentAuthor), helpMsg)); err != nil {
-			log.WithError(err).Errorf("Failed to create comment \"%s\".", helpMsg)
+		msg, err := config.RenderComment(pluginName, org, repo, info)
+		if err != nil {
+			return err
+		}
+		if err := spc.CreateComment(org, repo, e.Number, e.IsPR, plugins.FormatResponseRaw(e.Body, e.IssueLink, spc.QuoteAuthorForComment(commentAuthor), msg)+"\n"+botcomment.Marker(pluginName)); err != nil {
+			log.WithError(err).Errorf("Failed to create comment \"%s\".", msg)
 		}
 		if err := spc.AddLabel(org, repo, e.Number, labels.Help, e.IsPR); err != nil {
 			log.WithError(err).Errorf("GitHub failed to add the following label: %s", labels.Help)
@@ -163,12 +189,9 @@ func handle(remove bool, command string, spc scmProviderClient, log *logrus.Entr
 		if err := spc.RemoveLabel(org, repo, e.Number, labels.GoodFirstIssue, e.IsPR); err != nil {
 			log.WithError(err).Errorf("GitHub failed to remove the following label: %s", labels.GoodFirstIssue)
 		}
-
-		botName, err := spc.BotName()
-		if err != nil {
-			log.WithError(err).Errorf("Failed to get bot name.")
+		if err := cp.PruneFunc(isObsolete(goodFirstIssueTemplateName, pruneMatches(config, goodFirstIssueTemplateName, info, goodFirstIssueMsgPruneMatch)...)); err != nil {
+			log.WithError(err).Errorf("Failed to prune the %s comments.", goodFirstIssueTemplateName)
 		}
-		cp.PruneComments(e.IsPR, shouldPrune(log, botName, goodFirstIssueMsgPruneMatch))
 
 		return nil
 	}
@@ -176,12 +199,27 @@ func handle(remove bool, command string, spc scmProviderClient, log *logrus.Entr
 	return nil
 }
 
-// shouldPrune finds comments left by this plugin.
-func shouldPrune(log *logrus.Entry, botName, msgPruneMatch string) func(*scm.Comment) bool {
+// pruneMatches returns the text identifying the comments left for the template before they carried a marker, including
+// when it is customized.
+func pruneMatches(config *plugins.Configuration, name string, info IssueInfo, defaultMatch string) []string {
+	matches := []string{defaultMatch}
+	if msg, err := config.RenderComment(name, info.Org, info.Repo, info); err == nil && strings.TrimSpace(msg) != "" {
+		matches = append(matches, strings.TrimSpace(msg))
+	}
+	return matches
+}
+
+// isObsolete finds the bot comments left by this plugin for the template.
+func isObsolete(name string, msgPruneMatches ...string) func(*scm.Comment) bool {
 	return func(comment *scm.Comment) bool {
-		if comment.Author.Login != botName {
-			return false
+		if botcomment.HasMarker(comment.Body, name) {
+			return true
+		}
+		for _, match := range msgPruneMatches {
+			if strings.Contains(comment.Body, match) {
+				return true
+			}
 		}
-		return strings.Contains(comment.Body, msgPruneMatch)
+		return false
 	}
 }
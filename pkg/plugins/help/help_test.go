@@ -24,14 +24,16 @@ import (
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
 	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"github.com/sirupsen/logrus"
 )
 
 type fakePruner struct{}
 
-func (fp *fakePruner) PruneComments(pr bool, shouldPrune func(*scm.Comment) bool) {}
+func (fp *fakePruner) PruneFunc(isObsolete func(*scm.Comment) bool) error { return nil }
 
 func formatLabels(labels ...string) []string {
 	r := []string{}
@@ -221,7 +223,7 @@ func TestLabel(t *testing.T) {
 				t.Fatalf("(%s): Unexpected error from handle: %v.", tc.name, err)
 			}
 			for _, m := range matches {
-				if err := handle(m.Prefix != "", m.Name, &fakeSCMProviderClient.Client, logrus.WithField("plugin", pluginName), &fakePruner{}, e); err != nil {
+				if err := handle(m.Prefix != "", m.Name, &fakeSCMProviderClient.Client, logrus.WithField("plugin", pluginName), &fakePruner{}, &plugins.Configuration{}, e); err != nil {
 					t.Fatalf("For case %s, didn't expect error from label test: %v", tc.name, err)
 				}
 			}
@@ -245,3 +247,36 @@ func TestLabel(t *testing.T) {
 		})
 	}
 }
+
+func TestIsObsolete(t *testing.T) {
+	matches := pruneMatches(&plugins.Configuration{}, pluginName, IssueInfo{Org: "org", Repo: "repo", Number: 1}, helpMsgPruneMatch)
+	testcases := []struct {
+		name     string
+		body     string
+		expected bool
+	}{
+		{
+			name:     "marked comment",
+			body:     "customized comment\n" + botcomment.Marker(pluginName),
+			expected: true,
+		},
+		{
+			name:     "comment posted before the markers",
+			body:     "This request has been marked as needing help from a contributor.",
+			expected: true,
+		},
+		{
+			name: "comment of the other template",
+			body: "customized comment\n" + botcomment.Marker(goodFirstIssueTemplateName),
+		},
+		{
+			name: "unrelated comment",
+			body: "LGTM",
+		},
+	}
+	for _, tc := range testcases {
+		if actual := isObsolete(pluginName, matches...)(&scm.Comment{Body: tc.body}); actual != tc.expected {
+			t.Errorf("%s: expected %t but got %t", tc.name, tc.expected, actual)
+		}
+	}
+}
@@ -52,7 +52,7 @@ func configInfoStickyLgtmTeam(team string) string {
 }
 
 type commentPruner interface {
-	PruneComments(pr bool, shouldPrune func(*scm.Comment) bool)
+	PruneFunc(isObsolete func(*scm.Comment) bool) error
 }
 
 var (
@@ -73,7 +73,7 @@ var (
 			WhoCanUse:   "Collaborators on the repository. '/lgtm cancel' can be used additionally by the PR author.",
 			Action: plugins.
 				Invoke(func(m plugins.CommandMatch, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
-					cp, err := pc.CommentPruner()
+					cp, err := pc.BotCommentClient()
 					if err != nil {
 						return err
 					}
@@ -185,7 +185,7 @@ func handlePullRequestReviewEvent(pc plugins.Agent, e scm.ReviewHook) error {
 	if !opts.ReviewActsAsLgtm {
 		return nil
 	}
-	cp, err := pc.CommentPruner()
+	cp, err := pc.BotCommentClient()
 	if err != nil {
 		return err
 	}
@@ -342,9 +342,11 @@ func handle(wantLGTM bool, config *plugins.Configuration, ownersClient repoowner
 			return err
 		}
 		if opts.StoreTreeHash {
-			cp.PruneComments(true, func(comment *scm.Comment) bool {
+			if err := cp.PruneFunc(func(comment *scm.Comment) bool {
 				return addLGTMLabelNotificationRe.MatchString(comment.Body)
-			})
+			}); err != nil {
+				log.WithError(err).Error("Failed to prune the tree-hash comments.")
+			}
 		}
 	} else if !hasLGTM && wantLGTM {
 		log.Info("Adding LGTM label.")
@@ -371,9 +373,11 @@ func handle(wantLGTM bool, config *plugins.Configuration, ownersClient repoowner
 				}
 			}
 			// Delete the LGTM removed noti after the LGTM label is added.
-			cp.PruneComments(true, func(comment *scm.Comment) bool {
+			if err := cp.PruneFunc(func(comment *scm.Comment) bool {
 				return strings.Contains(comment.Body, removeLGTMLabelNoti)
-			})
+			}); err != nil {
+				log.WithError(err).Error("Failed to prune the LGTM removed comments.")
+			}
 		}
 	}
 
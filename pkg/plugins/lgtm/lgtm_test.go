@@ -58,12 +58,13 @@ type fakePruner struct {
 	PullRequestComments []*scm.Comment
 }
 
-func (fp *fakePruner) PruneComments(pr bool, shouldPrune func(*scm.Comment) bool) {
+func (fp *fakePruner) PruneFunc(isObsolete func(*scm.Comment) bool) error {
 	for _, comment := range fp.PullRequestComments {
-		if shouldPrune(comment) {
+		if isObsolete(comment) {
 			fp.SCMProviderClient.PullRequestCommentsDeleted = append(fp.SCMProviderClient.PullRequestCommentsDeleted, comment.Body)
 		}
 	}
+	return nil
 }
 
 var _ repoowners.RepoOwner = &fakeRepoOwners{}
@@ -0,0 +1,219 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package needsrebase contains a plugin which labels pull requests that have merge conflicts with their base branch,
+// so that they are not merged until they are rebased.
+package needsrebase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// PluginName is the name of the needs-rebase plugin
+	PluginName = "needs-rebase"
+
+	needsRebaseMsgPruneMatch = "PR needs rebase."
+	needsRebaseMsg           = "@{{.AuthorLogin}}: PR needs rebase.\n\n" +
+		"This pull request has merge conflicts with the `{{.BaseRef}}` branch. Once it is rebased the `" + labels.NeedsRebase + "` label will be removed automatically."
+)
+
+// PRInfo contains the info provided to the needs-rebase comment template
+type PRInfo struct {
+	Org         string
+	Repo        string
+	Number      int
+	AuthorLogin string
+	BaseRef     string
+}
+
+func init() {
+	plugins.RegisterCommentTemplate(PluginName, needsRebaseMsg)
+	plugins.RegisterPlugin(
+		PluginName,
+		plugins.Plugin{
+			Description:        "The needs-rebase plugin adds the '" + labels.NeedsRebase + "' label to pull requests which have merge conflicts with their base branch and removes it once the conflicts are resolved.",
+			ConfigHelpProvider: configHelp,
+			PullRequestHandler: handlePullRequest,
+		},
+	)
+}
+
+func configHelp(config *plugins.Configuration, enabledRepos []string) (map[string]string, error) {
+	return map[string]string{
+		"": fmt.Sprintf("Open pull requests are also checked for merge conflicts every %s.", config.NeedsRebase.SweepInterval),
+	}, nil
+}
+
+type scmProviderClient interface {
+	AddLabel(owner, repo string, number int, label string, pr bool) error
+	RemoveLabel(owner, repo string, number int, label string, pr bool) error
+	GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error)
+	CreateComment(owner, repo string, number int, pr bool, comment string) error
+	EditComment(owner, repo string, number int, id int, comment string, pr bool) error
+	DeleteComment(owner, repo string, number, id int, pr bool) error
+	ListIssueComments(org, repo string, number int) ([]*scm.Comment, error)
+	ListPullRequestComments(org, repo string, number int) ([]*scm.Comment, error)
+	GetPullRequest(org, repo string, number int) (*scm.PullRequest, error)
+	ListAllPullRequestsForFullNameRepo(fullName string, opts scm.PullRequestListOptions) ([]*scm.PullRequest, error)
+	Search(opts scm.SearchOptions) ([]*scm.SearchIssue, *scmprovider.RateLimits, error)
+	QuoteAuthorForComment(author string) string
+	BotName() (string, error)
+}
+
+func handlePullRequest(pc plugins.Agent, pre scm.PullRequestHook) error {
+	switch pre.Action {
+	case scm.ActionOpen, scm.ActionReopen, scm.ActionSync:
+	default:
+		return nil
+	}
+	return handle(pc.Logger, pc.SCMProviderClient, pc.PluginConfig, pre.Repo.Namespace, pre.Repo.Name, pre.PullRequest.Number)
+}
+
+// handle checks the current mergeability of the pull request as the one in the webhook payload is usually stale
+func handle(log *logrus.Entry, spc scmProviderClient, config *plugins.Configuration, org, repo string, number int) error {
+	pr, err := spc.GetPullRequest(org, repo, number)
+	if err != nil {
+		return fmt.Errorf("failed to get pull request %s/%s#%d: %v", org, repo, number, err)
+	}
+	return takeAction(log.WithField("pr", fmt.Sprintf("%s/%s#%d", org, repo, number)), spc, config, org, repo, pr)
+}
+
+// HandleAll checks all the open pull requests of the given orgs and org/repos for merge conflicts. It is run
+// periodically because a pull request can conflict with changes merged into its base branch without it changing.
+func HandleAll(log *logrus.Entry, spc scmProviderClient, config *plugins.Configuration, orgs, repos []string) error {
+	seen := sets.NewString()
+	var errs []string
+	check := func(org, repo string, number int) {
+		key := fmt.Sprintf("%s/%s#%d", org, repo, number)
+		if seen.Has(key) {
+			return
+		}
+		seen.Insert(key)
+		if err := handle(log, spc, config, org, repo, number); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	for _, fullName := range repos {
+		prs, err := spc.ListAllPullRequestsForFullNameRepo(fullName, scm.PullRequestListOptions{Open: true, Size: 100})
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to list open pull requests of %s: %v", fullName, err))
+			continue
+		}
+		for _, pr := range prs {
+			parts := strings.SplitN(fullName, "/", 2)
+			check(parts[0], parts[1], pr.Number)
+		}
+	}
+	for _, org := range orgs {
+		results, _, err := spc.Search(scm.SearchOptions{Query: fmt.Sprintf("is:pr is:open org:%s", org)})
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to search open pull requests of %s: %v", org, err))
+			continue
+		}
+		for _, r := range results {
+			check(org, r.Repository.Name, r.Number)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to check %d pull requests for merge conflicts: %s", len(errs), strings.Join(errs, "; "))
+	}
+	log.WithField("pullRequests", seen.Len()).Info("checked open pull requests for merge conflicts")
+	return nil
+}
+
+func takeAction(log *logrus.Entry, spc scmProviderClient, config *plugins.Configuration, org, repo string, pr *scm.PullRequest) error {
+	var conflicting bool
+	switch pr.MergeableState {
+	case scm.MergeableStateConflicting:
+		conflicting = true
+	case scm.MergeableStateMergeable:
+		conflicting = false
+	default:
+		// the provider is still calculating the mergeability, the next sweep will check the pull request again
+		log.Debug("mergeability of the pull request is unknown")
+		return nil
+	}
+
+	issueLabels, err := spc.GetIssueLabels(org, repo, pr.Number, true)
+	if err != nil {
+		return fmt.Errorf("failed to get the labels of %s/%s#%d: %v", org, repo, pr.Number, err)
+	}
+	hasLabel := scmprovider.HasLabel(labels.NeedsRebase, issueLabels)
+	info := PRInfo{
+		Org:         org,
+		Repo:        repo,
+		Number:      pr.Number,
+		AuthorLogin: spc.QuoteAuthorForComment(pr.Author.Login),
+		BaseRef:     pr.Base.Ref,
+	}
+
+	switch {
+	case conflicting && !hasLabel:
+		log.Infof("Adding %q label", labels.NeedsRebase)
+		if err := spc.AddLabel(org, repo, pr.Number, labels.NeedsRebase, true); err != nil {
+			return err
+		}
+		msg, err := config.RenderComment(PluginName, org, repo, info)
+		if err != nil {
+			return err
+		}
+		return botcomment.NewClient(spc, org, repo, pr.Number, true).Upsert(PluginName, msg)
+	case !conflicting && hasLabel:
+		log.Infof("Removing %q label", labels.NeedsRebase)
+		if err := spc.RemoveLabel(org, repo, pr.Number, labels.NeedsRebase, true); err != nil {
+			return err
+		}
+		return botcomment.NewClient(spc, org, repo, pr.Number, true).PruneFunc(isObsolete(pruneMatches(config, info)...))
+	}
+	return nil
+}
+
+// pruneMatches returns the text identifying the comments left by this plugin before they carried a marker, including
+// when the template is customized.
+func pruneMatches(config *plugins.Configuration, info PRInfo) []string {
+	matches := []string{needsRebaseMsgPruneMatch}
+	if msg, err := config.RenderComment(PluginName, info.Org, info.Repo, info); err == nil && strings.TrimSpace(msg) != "" {
+		matches = append(matches, strings.TrimSpace(msg))
+	}
+	return matches
+}
+
+// isObsolete finds the bot comments left by this plugin.
+func isObsolete(msgPruneMatches ...string) func(*scm.Comment) bool {
+	return func(comment *scm.Comment) bool {
+		if botcomment.HasMarker(comment.Body, PluginName) {
+			return true
+		}
+		for _, match := range msgPruneMatches {
+			if strings.Contains(comment.Body, match) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package needsrebase
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	*fake.SCMClient
+	searches []string
+}
+
+func (f *fakeClient) ListAllPullRequestsForFullNameRepo(fullName string, opts scm.PullRequestListOptions) ([]*scm.PullRequest, error) {
+	var prs []*scm.PullRequest
+	for _, pr := range f.PullRequests {
+		if pr.Base.Repo.FullName == fullName {
+			prs = append(prs, pr)
+		}
+	}
+	return prs, nil
+}
+
+func (f *fakeClient) Search(opts scm.SearchOptions) ([]*scm.SearchIssue, *scmprovider.RateLimits, error) {
+	f.searches = append(f.searches, opts.Query)
+	var results []*scm.SearchIssue
+	for _, pr := range f.PullRequests {
+		results = append(results, &scm.SearchIssue{
+			Issue:      scm.Issue{Number: pr.Number, PullRequest: true},
+			Repository: pr.Base.Repo,
+		})
+	}
+	return results, &scmprovider.RateLimits{}, nil
+}
+
+func newPR(number int, state scm.MergeableState) *scm.PullRequest {
+	return &scm.PullRequest{
+		Number:         number,
+		MergeableState: state,
+		Author:         scm.User{Login: "author"},
+		Base: scm.PullRequestBranch{
+			Ref:  "master",
+			Repo: scm.Repository{Namespace: "org", Name: "repo", FullName: "org/repo"},
+		},
+	}
+}
+
+func TestHandle(t *testing.T) {
+	testCases := []struct {
+		name            string
+		state           scm.MergeableState
+		hasLabel        bool
+		expectAdded     bool
+		expectRemoved   bool
+		expectComment   bool
+		existingComment bool
+		markedComment   bool
+		expectPruned    bool
+	}{
+		{
+			name:          "conflicting without label",
+			state:         scm.MergeableStateConflicting,
+			expectAdded:   true,
+			expectComment: true,
+		},
+		{
+			name:     "conflicting with label",
+			state:    scm.MergeableStateConflicting,
+			hasLabel: true,
+		},
+		{
+			name:            "mergeable with label",
+			state:           scm.MergeableStateMergeable,
+			hasLabel:        true,
+			existingComment: true,
+			expectRemoved:   true,
+			expectPruned:    true,
+		},
+		{
+			name:            "mergeable with label and marked comment",
+			state:           scm.MergeableStateMergeable,
+			hasLabel:        true,
+			existingComment: true,
+			markedComment:   true,
+			expectRemoved:   true,
+			expectPruned:    true,
+		},
+		{
+			name:  "mergeable without label",
+			state: scm.MergeableStateMergeable,
+		},
+		{
+			name:     "unknown mergeability",
+			state:    scm.MergeableStateUnknown,
+			hasLabel: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := &fake.SCMClient{
+				PullRequests:        map[int]*scm.PullRequest{1: newPR(1, tc.state)},
+				PullRequestComments: map[int][]*scm.Comment{},
+				IssueComments:       map[int][]*scm.Comment{},
+			}
+			if tc.hasLabel {
+				fc.PullRequestLabelsExisting = []string{"org/repo#1:" + labels.NeedsRebase}
+			}
+			if tc.existingComment {
+				body := "@author: PR needs rebase."
+				if tc.markedComment {
+					body = "@author: customized comment\n" + botcomment.Marker(PluginName)
+				}
+				fc.PullRequestComments[1] = []*scm.Comment{{ID: 1, Body: body, Author: scm.User{Login: "k8s-ci-robot"}}}
+			}
+
+			err := handle(logrus.WithField("plugin", PluginName), &fakeClient{SCMClient: fc}, &plugins.Configuration{}, "org", "repo", 1)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectAdded, len(fc.PullRequestLabelsAdded) == 1, "label added")
+			assert.Equal(t, tc.expectRemoved, len(fc.PullRequestLabelsRemoved) == 1, "label removed")
+			assert.Equal(t, tc.expectComment, len(fc.PullRequestCommentsAdded) == 1, "comment added")
+			assert.Equal(t, tc.expectPruned, len(fc.PullRequestCommentsDeleted) == 1, "comment pruned")
+			if tc.expectComment {
+				assert.Contains(t, fc.PullRequestCommentsAdded[0], "This pull request has merge conflicts with the `master` branch")
+			}
+		})
+	}
+}
+
+func TestHandleAll(t *testing.T) {
+	fc := &fakeClient{
+		SCMClient: &fake.SCMClient{
+			PullRequests: map[int]*scm.PullRequest{
+				1: newPR(1, scm.MergeableStateConflicting),
+				2: newPR(2, scm.MergeableStateMergeable),
+			},
+			PullRequestComments: map[int][]*scm.Comment{},
+			IssueComments:       map[int][]*scm.Comment{},
+		},
+	}
+
+	err := HandleAll(logrus.WithField("plugin", PluginName), fc, &plugins.Configuration{}, []string{"org"}, []string{"org/repo"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"org/repo#1:" + labels.NeedsRebase}, fc.PullRequestLabelsAdded)
+	assert.Len(t, fc.PullRequestCommentsAdded, 1, "pull requests found by both the org search and the repo listing should be checked once")
+	assert.Equal(t, []string{"is:pr is:open org:org"}, fc.searches)
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
 	lighthouseclient "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/typed/lighthouse/v1alpha1"
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
@@ -231,6 +232,18 @@ Only the following contexts were expected:
 			log.WithError(err).Warn(resp)
 			return spc.CreateComment(org, repo, number, e.IsPR, plugins.FormatResponseRaw(e.Body, e.Link, spc.QuoteAuthorForComment(user), resp))
 		}
+		audit.Record(audit.Entry{
+			Action:    audit.ActionOverride,
+			Actor:     user,
+			Org:       org,
+			Repo:      repo,
+			Number:    number,
+			Ref:       sha,
+			Target:    status.Label,
+			Details:   fmt.Sprintf("%s: %s", status.State, status.Desc),
+			Reason:    e.Body,
+			EventGUID: e.GUID,
+		})
 		done.Insert(status.Label)
 	}
 	return nil
@@ -8,16 +8,17 @@ import (
 
 // Plugin defines a plugin and its handlers
 type Plugin struct {
-	Description           string
-	ExcludedProviders     sets.String
-	ConfigHelpProvider    ConfigHelpProvider
-	IssueHandler          IssueHandler
-	PullRequestHandler    PullRequestHandler
-	PushEventHandler      PushEventHandler
-	ReviewEventHandler    ReviewEventHandler
-	StatusEventHandler    StatusEventHandler
-	GenericCommentHandler GenericCommentHandler
-	Commands              []Command
+	Description            string
+	ExcludedProviders      sets.String
+	ConfigHelpProvider     ConfigHelpProvider
+	IssueHandler           IssueHandler
+	PullRequestHandler     PullRequestHandler
+	PushEventHandler       PushEventHandler
+	DeploymentEventHandler DeploymentEventHandler
+	ReviewEventHandler     ReviewEventHandler
+	StatusEventHandler     StatusEventHandler
+	GenericCommentHandler  GenericCommentHandler
+	Commands               []Command
 }
 
 // InvokeCommandHandler calls InvokeHandler on all commands
@@ -63,6 +64,9 @@ func (plugin Plugin) getEvents() []string {
 	if plugin.PushEventHandler != nil {
 		events = append(events, "push")
 	}
+	if plugin.DeploymentEventHandler != nil {
+		events = append(events, "deployment")
+	}
 	if plugin.ReviewEventHandler != nil {
 		events = append(events, "pull_request_review")
 	}
@@ -25,8 +25,9 @@ import (
 	"sync"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/basesha"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
 	lighthouseclient "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/typed/lighthouse/v1alpha1"
-	"github.com/jenkins-x/lighthouse/pkg/commentpruner"
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/git"
 	"github.com/jenkins-x/lighthouse/pkg/launcher"
@@ -34,6 +35,7 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/repoowners"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/yaml"
 )
@@ -42,8 +44,15 @@ var (
 	plugins = map[string]Plugin{}
 )
 
-// RegisterPlugin registers a plugin.
+// RegisterPlugin registers a plugin. It panics if the name is empty or already
+// registered as plugins are registered from the init functions of their packages.
 func RegisterPlugin(name string, plugin Plugin) {
+	if name == "" {
+		panic("cannot register a plugin without a name")
+	}
+	if _, ok := plugins[name]; ok {
+		panic(fmt.Sprintf("plugin %s is already registered", name))
+	}
 	plugins[name] = plugin
 }
 
@@ -66,6 +75,9 @@ type StatusEventHandler func(Agent, scm.Status) error
 // PushEventHandler defines the function contract for a scm.PushHook handler.
 type PushEventHandler func(Agent, scm.PushHook) error
 
+// DeploymentEventHandler defines the function contract for a scm.DeployHook handler.
+type DeploymentEventHandler func(Agent, scm.DeployHook) error
+
 // ReviewEventHandler defines the function contract for a ReviewHook handler.
 type ReviewEventHandler func(Agent, scm.ReviewHook) error
 
@@ -79,6 +91,7 @@ type CommandEventHandler func(CommandMatch, Agent, scmprovider.GenericCommentEve
 func HelpProviders() map[string]HelpProvider {
 	pluginHelp := make(map[string]HelpProvider)
 	for k, v := range plugins {
+		v := v
 		pluginHelp[k] = func(config *Configuration, enabledRepos []string) (*pluginhelp.PluginHelp, error) {
 			return v.GetHelp(config, enabledRepos)
 		}
@@ -94,6 +107,8 @@ type Agent struct {
 	KubernetesClient  kubernetes.Interface
 	LighthouseClient  lighthouseclient.LighthouseJobInterface
 	ServerURL         *url.URL
+	// BaseSHAs resolves the heads of the base branches the jobs test against, shared by the events
+	BaseSHAs *basesha.Resolver
 	/*
 		SlackClient      *slack.Client
 	*/
@@ -109,7 +124,7 @@ type Agent struct {
 	Logger *logrus.Entry
 
 	// may be nil if not initialized
-	Commentpruner *commentpruner.EventClient
+	BotComments *botcomment.Client
 }
 
 // NewAgent bootstraps a new Agent struct from the passed dependencies.
@@ -117,6 +132,9 @@ func NewAgent(configAgent *config.Agent, pluginConfigAgent *ConfigAgent, clientA
 	prowConfig := configAgent.Config()
 	pluginConfig := pluginConfigAgent.Config()
 	scmClient := scmprovider.ToClient(clientAgent.SCMProviderClient, clientAgent.BotName)
+	eventGUID, _ := logger.Data[scmprovider.EventGUID].(string)
+	plugin, _ := logger.Data["plugin"].(string)
+	scmClient.SetAuditContext(eventGUID, plugin)
 	return Agent{
 		SCMProviderClient: scmClient,
 		GitClient:         clientAgent.GitClient,
@@ -124,6 +142,7 @@ func NewAgent(configAgent *config.Agent, pluginConfigAgent *ConfigAgent, clientA
 		LauncherClient:    clientAgent.LauncherClient,
 		LighthouseClient:  clientAgent.LighthouseClient,
 		ServerURL:         serverURL,
+		BaseSHAs:          clientAgent.BaseSHAs,
 
 		/*
 			SlackClient:   clientAgent.SlackClient,
@@ -139,22 +158,19 @@ func NewAgent(configAgent *config.Agent, pluginConfigAgent *ConfigAgent, clientA
 	}
 }
 
-// InitializeCommentPruner attaches a commentpruner.EventClient to the agent to handle
-// pruning comments.
-func (a *Agent) InitializeCommentPruner(org, repo string, pr int) {
-	a.Commentpruner = commentpruner.NewEventClient(
-		a.SCMProviderClient, a.Logger.WithField("client", "commentpruner"),
-		org, repo, pr,
-	)
+// InitializeBotComments attaches a botcomment.Client to the agent to handle
+// updating and pruning the bot comments of the issue or pull request of the event.
+func (a *Agent) InitializeBotComments(org, repo string, number int, pr bool) {
+	a.BotComments = botcomment.NewClient(a.SCMProviderClient, org, repo, number, pr)
 }
 
-// CommentPruner will return the commentpruner.EventClient attached to the agent or an error
+// BotCommentClient will return the botcomment.Client attached to the agent or an error
 // if one is not attached.
-func (a *Agent) CommentPruner() (*commentpruner.EventClient, error) {
-	if a.Commentpruner == nil {
-		return nil, errors.New("comment pruner client never initialized")
+func (a *Agent) BotCommentClient() (*botcomment.Client, error) {
+	if a.BotComments == nil {
+		return nil, errors.New("bot comment client never initialized")
 	}
-	return a.Commentpruner, nil
+	return a.BotComments, nil
 }
 
 // ClientAgent contains the various clients that are attached to the Agent.
@@ -166,6 +182,7 @@ type ClientAgent struct {
 	GitClient        git.Client
 	LauncherClient   launcher.PipelineLauncher
 	LighthouseClient lighthouseclient.LighthouseJobInterface
+	BaseSHAs         *basesha.Resolver
 
 	/*	SlackClient      *slack.Client
 	 */
@@ -252,10 +269,21 @@ func (pa *ConfigAgent) getPlugins(owner, repo string) []string {
 	if lowerOwner != owner {
 		owners = append(owners, lowerOwner)
 	}
+	disabled := sets.NewString()
 	for _, o := range owners {
 		fullName := fmt.Sprintf("%s/%s", o, repo)
 		plugins = append(plugins, pa.configuration.Plugins[o]...)
 		plugins = append(plugins, pa.configuration.Plugins[fullName]...)
+		disabled.Insert(pa.configuration.DisabledPlugins[fullName]...)
+	}
+	if disabled.Len() > 0 {
+		var enabled []string
+		for _, p := range plugins {
+			if !disabled.Has(p) {
+				enabled = append(enabled, p)
+			}
+		}
+		plugins = enabled
 	}
 	logrus.Infof("found plugins %s\n", strings.Join(plugins, ", "))
 	return plugins
@@ -94,6 +94,7 @@ func TestGetPlugins(t *testing.T) {
 	var testcases = []struct {
 		name            string
 		pluginMap       map[string][]string // this is read from the plugins.yaml file typically.
+		disabledMap     map[string][]string
 		owner           string
 		repo            string
 		expectedPlugins []string
@@ -137,9 +138,23 @@ func TestGetPlugins(t *testing.T) {
 			repo:            "repo",
 			expectedPlugins: []string{"plugin3"},
 		},
+		{
+			name: "Plugins disabled for org1/repo should not be returned for org1/repo query",
+			pluginMap: map[string][]string{
+				"org1":      {"plugin1", "plugin2"},
+				"org1/repo": {"plugin3"},
+			},
+			disabledMap: map[string][]string{
+				"org1/repo":  {"plugin1"},
+				"org1/other": {"plugin2"},
+			},
+			owner:           "org1",
+			repo:            "repo",
+			expectedPlugins: []string{"plugin2", "plugin3"},
+		},
 	}
 	for _, tc := range testcases {
-		pa := ConfigAgent{configuration: &Configuration{Plugins: tc.pluginMap}}
+		pa := ConfigAgent{configuration: &Configuration{Plugins: tc.pluginMap, DisabledPlugins: tc.disabledMap}}
 
 		plugins := pa.getPlugins(tc.owner, tc.repo)
 		if len(plugins) != len(tc.expectedPlugins) {
@@ -153,3 +168,37 @@ func TestGetPlugins(t *testing.T) {
 		}
 	}
 }
+
+func TestHelpProviders(t *testing.T) {
+	names := []string{"help-plugin-1", "help-plugin-2", "help-plugin-3"}
+	for _, name := range names {
+		RegisterPlugin(name, Plugin{Description: name})
+		defer delete(plugins, name)
+	}
+	providers := HelpProviders()
+	for _, name := range names {
+		help, err := providers[name](&Configuration{}, nil)
+		if err != nil {
+			t.Fatalf("unexpected error getting the help of %s: %v", name, err)
+		}
+		if help.Description != name {
+			t.Errorf("expected the help of %s, got the help of %s", name, help.Description)
+		}
+	}
+}
+
+func TestRegisterPluginPanics(t *testing.T) {
+	RegisterPlugin("registered-plugin", Plugin{})
+	defer delete(plugins, "registered-plugin")
+
+	for _, name := range []string{"", "registered-plugin"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected registering plugin %q to panic", name)
+				}
+			}()
+			RegisterPlugin(name, Plugin{})
+		}()
+	}
+}
@@ -0,0 +1,208 @@
+// Package policy implements a plugin which checks the titles of the pull requests and the messages of their commits
+// against the regular expressions configured for the repository, e.g. to enforce conventional commits or references
+// to JIRA issues.
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	pluginName = "policy"
+	// defaultStatusContext is the commit status context reported by the plugin unless configured
+	defaultStatusContext = "policy"
+
+	policyMsg = `This pull request does not follow the policy of the repository:
+{{range .Violations}}
+* {{.}}{{end}}
+
+{{if .GuidelinesURL}}Please see [the guidelines]({{.GuidelinesURL}}) for more details. {{end}}The policy is checked again when the pull request is updated, or comment ` + "`/check-policy`" + ` to check it again.`
+)
+
+// PolicyInfo contains the info provided to the policy comment template
+type PolicyInfo struct {
+	Org           string
+	Repo          string
+	Number        int
+	Violations    []string
+	GuidelinesURL string
+}
+
+var (
+	plugin = plugins.Plugin{
+		Description:        "The policy plugin checks the title of the pull requests and the messages of their commits against the configured regular expressions, reporting a status and commenting with the violations.",
+		ConfigHelpProvider: configHelp,
+		PullRequestHandler: handlePullRequest,
+		Commands: []plugins.Command{{
+			Name:        "check-policy",
+			Description: "Forces rechecking of the policy status.",
+			WhoCanUse:   "Anyone can trigger this command on a PR.",
+			Action: plugins.
+				Invoke(func(match plugins.CommandMatch, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
+					return handleComment(pc, e)
+				}).
+				When(plugins.Action(scm.ActionCreate), plugins.IsPR(), plugins.IssueState("open")),
+		}},
+	}
+)
+
+func init() {
+	plugins.RegisterCommentTemplate(pluginName, policyMsg)
+	plugins.RegisterPlugin(pluginName, plugin)
+}
+
+func configHelp(config *plugins.Configuration, enabledRepos []string) (map[string]string, error) {
+	configInfo := map[string]string{}
+	for _, orgRepo := range enabledRepos {
+		parts := strings.SplitN(orgRepo, "/", 2)
+		repo := ""
+		if len(parts) == 2 {
+			repo = parts[1]
+		}
+		opts := config.PolicyFor(parts[0], repo)
+		var rules []string
+		for _, rule := range opts.Title {
+			rules = append(rules, fmt.Sprintf("the title must match %s (%s)", rule.Regexp, rule.Name))
+		}
+		for _, rule := range opts.Commits {
+			rules = append(rules, fmt.Sprintf("the commit messages must match %s (%s)", rule.Regexp, rule.Name))
+		}
+		if len(rules) > 0 {
+			configInfo[orgRepo] = fmt.Sprintf("The pull requests are checked that %s.", strings.Join(rules, ", "))
+		}
+	}
+	return configInfo, nil
+}
+
+type scmProviderClient interface {
+	CreateComment(owner, repo string, number int, pr bool, comment string) error
+	EditComment(owner, repo string, number int, id int, comment string, pr bool) error
+	DeleteComment(owner, repo string, number, id int, pr bool) error
+	ListIssueComments(org, repo string, number int) ([]*scm.Comment, error)
+	ListPullRequestComments(org, repo string, number int) ([]*scm.Comment, error)
+	CreateStatus(owner, repo, ref string, s *scm.StatusInput) (*scm.Status, error)
+	GetPullRequest(org, repo string, number int) (*scm.PullRequest, error)
+	ListPullRequestCommits(owner, repo string, number int) ([]*scm.Commit, error)
+	BotName() (string, error)
+}
+
+func handlePullRequest(pc plugins.Agent, pre scm.PullRequestHook) error {
+	switch pre.Action {
+	case scm.ActionOpen, scm.ActionReopen, scm.ActionSync, scm.ActionEdited, scm.ActionUpdate:
+	default:
+		return nil
+	}
+	org := pre.Repo.Namespace
+	repo := pre.Repo.Name
+	return handle(pc.Logger, pc.SCMProviderClient, pc.PluginConfig, org, repo, &pre.PullRequest)
+}
+
+func handleComment(pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
+	org := e.Repo.Namespace
+	repo := e.Repo.Name
+	pr, err := pc.SCMProviderClient.GetPullRequest(org, repo, e.Number)
+	if err != nil {
+		return fmt.Errorf("failed to get pull request %s/%s#%d: %v", org, repo, e.Number, err)
+	}
+	return handle(pc.Logger, pc.SCMProviderClient, pc.PluginConfig, org, repo, pr)
+}
+
+func handle(log *logrus.Entry, spc scmProviderClient, config *plugins.Configuration, org, repo string, pr *scm.PullRequest) error {
+	opts := config.PolicyFor(org, repo)
+	if len(opts.Title) == 0 && len(opts.Commits) == 0 {
+		log.Debugf("No policy configured for %s/%s", org, repo)
+		return nil
+	}
+
+	var violations []string
+	for _, rule := range opts.Title {
+		if !matches(rule, pr.Title) {
+			violations = append(violations, fmt.Sprintf("the title `%s` is not a %s: it must match `%s`", pr.Title, rule.Name, rule.Regexp))
+		}
+	}
+	if len(opts.Commits) > 0 {
+		commits, err := spc.ListPullRequestCommits(org, repo, pr.Number)
+		if err != nil {
+			return fmt.Errorf("failed to list the commits of %s/%s#%d: %v", org, repo, pr.Number, err)
+		}
+		for _, commit := range commits {
+			if opts.SkipCommitsRe != nil && opts.SkipCommitsRe.MatchString(commit.Message) {
+				continue
+			}
+			for _, rule := range opts.Commits {
+				if !matches(rule, commit.Message) {
+					violations = append(violations, fmt.Sprintf("the message of commit %s `%s` is not a %s: it must match `%s`", shortSHA(commit.Sha), firstLine(commit.Message), rule.Name, rule.Regexp))
+				}
+			}
+		}
+	}
+
+	info := PolicyInfo{
+		Org:           org,
+		Repo:          repo,
+		Number:        pr.Number,
+		Violations:    violations,
+		GuidelinesURL: opts.GuidelinesURL,
+	}
+	return takeAction(log, spc, config, opts, pr, info)
+}
+
+func takeAction(log *logrus.Entry, spc scmProviderClient, config *plugins.Configuration, opts *plugins.Policy, pr *scm.PullRequest, info PolicyInfo) error {
+	org, repo, number := info.Org, info.Repo, info.Number
+	passing := len(info.Violations) == 0
+
+	statusContext := opts.Context
+	if statusContext == "" {
+		statusContext = defaultStatusContext
+	}
+	status := &scm.StatusInput{
+		Label:  statusContext,
+		State:  scm.StateSuccess,
+		Desc:   "The pull request follows the policy",
+		Target: info.GuidelinesURL,
+	}
+	if !passing {
+		status.State = scm.StateFailure
+		status.Desc = fmt.Sprintf("The pull request has %d policy violation(s)", len(info.Violations))
+	}
+	if _, err := spc.CreateStatus(org, repo, pr.Head.Sha, status); err != nil {
+		return fmt.Errorf("failed to report the %s status: %v", statusContext, err)
+	}
+
+	comments := botcomment.NewClient(spc, org, repo, number, true)
+	if passing {
+		return comments.Prune(pluginName)
+	}
+	log.Infof("Pull request %s/%s#%d has %d policy violation(s)", org, repo, number, len(info.Violations))
+	msg, err := config.RenderComment(pluginName, org, repo, info)
+	if err != nil {
+		return err
+	}
+	// update any previous comment as the violations may have changed
+	return comments.Upsert(pluginName, msg)
+}
+
+func matches(rule plugins.PolicyRule, text string) bool {
+	if rule.Re == nil {
+		return true
+	}
+	return rule.Re.MatchString(text)
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func firstLine(message string) string {
+	return strings.SplitN(message, "\n", 2)[0]
+}
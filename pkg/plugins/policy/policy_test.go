@@ -0,0 +1,152 @@
+package policy
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const headSHA = "abcdef1234567890"
+
+func rule(name, expr string) plugins.PolicyRule {
+	return plugins.PolicyRule{Name: name, Regexp: expr, Re: regexp.MustCompile(expr)}
+}
+
+func TestHandle(t *testing.T) {
+	policy := &plugins.Policy{
+		Title:         []plugins.PolicyRule{rule("conventional commit", `^(feat|fix|chore|docs)(\(.+\))?!?: .+`)},
+		Commits:       []plugins.PolicyRule{rule("JIRA reference", `(?m)^Refs: [A-Z][A-Z0-9]+-\d+$`)},
+		SkipCommitsRe: regexp.MustCompile(`^Merge `),
+		GuidelinesURL: "https://example.com/contributing",
+	}
+	referenced := &scm.Commit{Sha: "1111111111", Message: "fix the widget\n\nRefs: WID-12"}
+	unreferenced := &scm.Commit{Sha: "2222222222", Message: "tweak the widget"}
+	merge := &scm.Commit{Sha: "3333333333", Message: "Merge branch 'master' into widget"}
+
+	testCases := []struct {
+		name               string
+		policy             *plugins.Policy
+		title              string
+		commits            []*scm.Commit
+		existingComment    bool
+		expectState        scm.State
+		expectViolations   []string
+		expectNoViolations []string
+		expectEdited       bool
+		expectPruned       bool
+	}{
+		{
+			name:    "no policy",
+			title:   "whatever",
+			commits: []*scm.Commit{unreferenced},
+		},
+		{
+			name:        "passing",
+			policy:      policy,
+			title:       "fix(widget): stop spinning",
+			commits:     []*scm.Commit{referenced, merge},
+			expectState: scm.StateSuccess,
+		},
+		{
+			name:            "passing prunes the comment",
+			policy:          policy,
+			title:           "fix(widget): stop spinning",
+			commits:         []*scm.Commit{referenced},
+			expectState:     scm.StateSuccess,
+			existingComment: true,
+			expectPruned:    true,
+		},
+		{
+			name:        "invalid title",
+			policy:      policy,
+			title:       "Stop spinning",
+			commits:     []*scm.Commit{referenced},
+			expectState: scm.StateFailure,
+			expectViolations: []string{
+				"* the title `Stop spinning` is not a conventional commit",
+			},
+			expectNoViolations: []string{"1111111"},
+		},
+		{
+			name:        "invalid commit",
+			policy:      policy,
+			title:       "fix(widget): stop spinning",
+			commits:     []*scm.Commit{referenced, unreferenced, merge},
+			expectState: scm.StateFailure,
+			expectViolations: []string{
+				"* the message of commit 2222222 `tweak the widget` is not a JIRA reference",
+				"[the guidelines](https://example.com/contributing)",
+			},
+			expectNoViolations: []string{"1111111", "3333333", "the title"},
+		},
+		{
+			name:             "violations update the comment",
+			policy:           policy,
+			title:            "fix(widget): stop spinning",
+			commits:          []*scm.Commit{unreferenced},
+			existingComment:  true,
+			expectState:      scm.StateFailure,
+			expectViolations: []string{"2222222"},
+			expectEdited:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := &fake.SCMClient{
+				PullRequestCommits:  map[int][]*scm.Commit{1: tc.commits},
+				PullRequestComments: map[int][]*scm.Comment{},
+				IssueComments:       map[int][]*scm.Comment{},
+			}
+			botName, err := fc.BotName()
+			require.NoError(t, err)
+			if tc.existingComment {
+				body := "This pull request does not follow the policy\n" + botcomment.Marker(pluginName)
+				fc.PullRequestComments[1] = []*scm.Comment{{ID: 1, Body: body, Author: scm.User{Login: botName}}}
+			}
+			config := &plugins.Configuration{}
+			if tc.policy != nil {
+				config.Policy = map[string]*plugins.Policy{"org/repo": tc.policy}
+			}
+			pr := &scm.PullRequest{Number: 1, Title: tc.title, Head: scm.PullRequestBranch{Sha: headSHA}}
+
+			err = handle(logrus.WithField("plugin", pluginName), fc, config, "org", "repo", pr)
+			require.NoError(t, err)
+
+			if tc.policy == nil {
+				assert.Empty(t, fc.CreatedStatuses[headSHA])
+				return
+			}
+			require.Len(t, fc.CreatedStatuses[headSHA], 1)
+			status := fc.CreatedStatuses[headSHA][0]
+			assert.Equal(t, defaultStatusContext, status.Label)
+			assert.Equal(t, tc.expectState, status.State)
+			assert.Equal(t, tc.expectPruned, len(fc.PullRequestCommentsDeleted) == 1, "comment pruned")
+
+			var comment string
+			switch {
+			case tc.expectEdited:
+				require.Len(t, fc.PullRequestCommentsEdited, 1)
+				comment = fc.PullRequestCommentsEdited[0]
+			case len(tc.expectViolations) > 0:
+				require.Len(t, fc.PullRequestCommentsAdded, 1)
+				comment = fc.PullRequestCommentsAdded[0]
+			default:
+				assert.Empty(t, fc.PullRequestCommentsAdded)
+			}
+			for _, violation := range tc.expectViolations {
+				assert.Contains(t, comment, violation)
+			}
+			for _, violation := range tc.expectNoViolations {
+				assert.NotContains(t, comment, violation)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package releasenote
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+const (
+	commitsPageSize = 100
+	// maxCommitPages bounds the number of commits listed when looking for the start of the range
+	maxCommitPages = 10
+	// maxPullRequests bounds the number of pull requests fetched for the notes of a range
+	maxPullRequests = 200
+)
+
+var (
+	// mergedPRRes match the pull request numbers in the subjects of the commits merging them on GitHub, e.g. "Merge
+	// pull request #12 from ..." or "Fix the widget (#12)"
+	mergedPRRes = []*regexp.Regexp{
+		regexp.MustCompile(`^Merge pull request #(\d+)`),
+		regexp.MustCompile(`\(#(\d+)\)\s*$`),
+	}
+	// mergedMRRe matches the merge request number in the messages of the commits merging them on GitLab
+	mergedMRRe = regexp.MustCompile(`(?m)^See merge request \S*!(\d+)\s*$`)
+	// fixedIssueRe matches the references to the issues closed by a pull request, e.g. "Fixes #12"
+	fixedIssueRe = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?)\s*:?\s+#(\d+)\b`)
+)
+
+// Note is the release note of a merged pull request
+type Note struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	URL    string `json:"url,omitempty"`
+	Author string `json:"author,omitempty"`
+	// Note is the content of the release-note block of the pull request, or its title when it has no block
+	Note string `json:"note"`
+	// Issues are the numbers of the issues the pull request closes
+	Issues []int `json:"issues,omitempty"`
+}
+
+type notesClient interface {
+	GetSingleCommit(owner, repo, SHA string) (*scm.Commit, error)
+	ListCommits(owner, repo string, opts scm.CommitListOptions) ([]*scm.Commit, error)
+	GetPullRequest(org, repo string, number int) (*scm.PullRequest, error)
+}
+
+// Notes returns the release notes of the pull requests merged after the from ref, typically the previous tag, up to
+// the to ref, ordered by pull request number. The pull requests are found from the messages of the commits merging
+// them and the ones with a release note of NONE are left out.
+func Notes(spc notesClient, org, repo, from, to string) ([]Note, error) {
+	fromCommit, err := spc.GetSingleCommit(org, repo, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find %s in %s/%s: %v", from, org, repo, err)
+	}
+	if fromCommit == nil {
+		return nil, fmt.Errorf("failed to find %s in %s/%s", from, org, repo)
+	}
+	toCommit, err := spc.GetSingleCommit(org, repo, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find %s in %s/%s: %v", to, org, repo, err)
+	}
+	if toCommit == nil {
+		return nil, fmt.Errorf("failed to find %s in %s/%s", to, org, repo)
+	}
+
+	numbers, err := mergedPullRequests(spc, org, repo, fromCommit.Sha, toCommit.Sha)
+	if err != nil {
+		return nil, err
+	}
+	var answer []Note
+	for _, number := range numbers {
+		pr, err := spc.GetPullRequest(org, repo, number)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pull request %s/%s#%d: %v", org, repo, number, err)
+		}
+		note, found := ExtractReleaseNote(pr.Body)
+		if found && note == "" {
+			continue
+		}
+		if !found {
+			note = pr.Title
+		}
+		answer = append(answer, Note{
+			Number: pr.Number,
+			Title:  pr.Title,
+			URL:    pr.Link,
+			Author: pr.Author.Login,
+			Note:   note,
+			Issues: fixedIssues(pr.Body),
+		})
+	}
+	return answer, nil
+}
+
+// mergedPullRequests walks the history back from the to commit until the from commit, collecting the numbers of the
+// pull requests merged by the commits in between
+func mergedPullRequests(spc notesClient, org, repo, fromSHA, toSHA string) ([]int, error) {
+	seen := map[int]bool{}
+	var numbers []int
+	for page := 1; page <= maxCommitPages; page++ {
+		commits, err := spc.ListCommits(org, repo, scm.CommitListOptions{Sha: toSHA, Page: page, Size: commitsPageSize})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the commits of %s/%s: %v", org, repo, err)
+		}
+		for _, commit := range commits {
+			if commit.Sha == fromSHA {
+				sort.Ints(numbers)
+				return numbers, nil
+			}
+			if number := mergedPullRequest(commit.Message); number > 0 && !seen[number] {
+				if len(numbers) == maxPullRequests {
+					return nil, fmt.Errorf("more than %d pull requests were merged between %s and %s in %s/%s", maxPullRequests, fromSHA, toSHA, org, repo)
+				}
+				seen[number] = true
+				numbers = append(numbers, number)
+			}
+		}
+		if len(commits) < commitsPageSize {
+			break
+		}
+	}
+	return nil, fmt.Errorf("%s is not an ancestor of %s in %s/%s within %d commits", fromSHA, toSHA, org, repo, maxCommitPages*commitsPageSize)
+}
+
+func mergedPullRequest(message string) int {
+	subject := strings.SplitN(message, "\n", 2)[0]
+	for _, re := range mergedPRRes {
+		if match := re.FindStringSubmatch(subject); match != nil {
+			number, _ := strconv.Atoi(match[1])
+			return number
+		}
+	}
+	if match := mergedMRRe.FindStringSubmatch(message); match != nil {
+		number, _ := strconv.Atoi(match[1])
+		return number
+	}
+	return 0
+}
+
+func fixedIssues(body string) []int {
+	var answer []int
+	seen := map[int]bool{}
+	for _, match := range fixedIssueRe.FindAllStringSubmatch(body, -1) {
+		number, err := strconv.Atoi(match[1])
+		if err == nil && !seen[number] {
+			seen[number] = true
+			answer = append(answer, number)
+		}
+	}
+	return answer
+}
+
+// Markdown renders the release notes as a changelog, linking each note to its pull request and the issues it closes
+func Markdown(notes []Note) string {
+	var b strings.Builder
+	for _, n := range notes {
+		lines := strings.Split(n.Note, "\n")
+		b.WriteString("* " + strings.TrimSpace(lines[0]))
+		for _, line := range lines[1:] {
+			b.WriteString("\n  " + strings.TrimRight(line, " \t\r"))
+		}
+		refs := []string{fmt.Sprintf("#%d", n.Number)}
+		if n.URL != "" {
+			refs[0] = fmt.Sprintf("[#%d](%s)", n.Number, n.URL)
+		}
+		if n.Author != "" {
+			refs = append(refs, "@"+n.Author)
+		}
+		for _, issue := range n.Issues {
+			refs = append(refs, fmt.Sprintf("fixes #%d", issue))
+		}
+		b.WriteString(" (" + strings.Join(refs, ", ") + ")\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package releasenote
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotes(t *testing.T) {
+	fromCommit := &scm.Commit{Sha: "1111111111", Message: "Merge pull request #1 from alice/first"}
+	commits := []*scm.Commit{
+		{Sha: "5555555555", Message: "Merge pull request #4 from bob/spinner\n\nAdd a spinner"},
+		{Sha: "4444444444", Message: "Document the widget (#3)"},
+		{Sha: "3333333333", Message: "Tweak the widget"},
+		{Sha: "2222222222", Message: "Fix the widget\n\nSee merge request org/repo!2"},
+		fromCommit,
+		{Sha: "0000000000", Message: "Merge pull request #0 from alice/initial"},
+	}
+	fc := &fake.SCMClient{
+		Commits: map[string]*scm.Commit{
+			"v1.0.0": fromCommit,
+			"v1.1.0": commits[0],
+		},
+		RepoCommits: map[string][]*scm.Commit{"org/repo": commits},
+		PullRequests: map[int]*scm.PullRequest{
+			2: {
+				Number: 2,
+				Title:  "Fix the widget",
+				Link:   "https://example.com/org/repo/pull/2",
+				Author: scm.User{Login: "alice"},
+				Body:   "Fixes #10, closes #11\n\n```release-note\nFixed the widget spinning forever.\n```",
+			},
+			3: {
+				Number: 3,
+				Title:  "Document the widget",
+				Author: scm.User{Login: "alice"},
+				Body:   "```release-note\nNONE\n```",
+			},
+			4: {
+				Number: 4,
+				Title:  "Add a spinner",
+				Author: scm.User{Login: "bob"},
+			},
+		},
+	}
+
+	notes, err := Notes(fc, "org", "repo", "v1.0.0", "v1.1.0")
+	require.NoError(t, err)
+	expected := []Note{
+		{
+			Number: 2,
+			Title:  "Fix the widget",
+			URL:    "https://example.com/org/repo/pull/2",
+			Author: "alice",
+			Note:   "Fixed the widget spinning forever.",
+			Issues: []int{10, 11},
+		},
+		{
+			Number: 4,
+			Title:  "Add a spinner",
+			Author: "bob",
+			Note:   "Add a spinner",
+		},
+	}
+	assert.Equal(t, expected, notes)
+
+	assert.Equal(t, "* Fixed the widget spinning forever. ([#2](https://example.com/org/repo/pull/2), @alice, fixes #10, fixes #11)\n"+
+		"* Add a spinner (#4, @bob)\n", Markdown(notes))
+
+	_, err = Notes(fc, "org", "repo", "v0.9.0", "v1.1.0")
+	assert.Error(t, err, "unknown from ref")
+
+	fc.Commits["v2.0.0"] = &scm.Commit{Sha: "9999999999"}
+	_, err = Notes(fc, "org", "repo", "v2.0.0", "v1.1.0")
+	assert.Error(t, err, "from ref not an ancestor")
+}
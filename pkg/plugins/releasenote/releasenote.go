@@ -0,0 +1,130 @@
+// Package releasenote implements a plugin which labels the pull requests according to the release-note block of
+// their description, and extracts the release notes of the pull requests merged between two tags.
+package releasenote
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// PluginName is the name of the release-note plugin
+	PluginName = "release-note"
+
+	releaseNoteMsg = "This pull request has no release note. Please add a `release-note` block to its description, e.g.\n\n" +
+		"````\n```release-note\nFixed the widget spinning forever when the cache is empty.\n```\n````\n\n" +
+		"or `NONE` in the block if the change is not visible to the users."
+)
+
+var (
+	// noteRe matches the release-note block of a pull request description
+	noteRe = regexp.MustCompile("(?s)```release-note[ \\t]*\\r?\\n(.*?)```")
+	// noneRe matches the release notes of the changes not visible to the users
+	noneRe = regexp.MustCompile(`(?i)^\W*(none|n/?a)\W*$`)
+
+	allLabels = []string{labels.ReleaseNote, labels.ReleaseNoteNone, labels.ReleaseNoteNeeded}
+)
+
+// ReleaseNoteInfo contains the info provided to the release-note comment template
+type ReleaseNoteInfo struct {
+	Org    string
+	Repo   string
+	Number int
+}
+
+var (
+	plugin = plugins.Plugin{
+		Description: fmt.Sprintf("The release-note plugin labels the pull requests with '%s' or '%s' according to the release-note block of their description, or with '%s' when they have none.",
+			labels.ReleaseNote, labels.ReleaseNoteNone, labels.ReleaseNoteNeeded),
+		PullRequestHandler: handlePullRequest,
+	}
+)
+
+func init() {
+	plugins.RegisterCommentTemplate(PluginName, releaseNoteMsg)
+	plugins.RegisterPlugin(PluginName, plugin)
+}
+
+type scmProviderClient interface {
+	AddLabel(owner, repo string, number int, label string, pr bool) error
+	RemoveLabel(owner, repo string, number int, label string, pr bool) error
+	GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error)
+	CreateComment(owner, repo string, number int, pr bool, comment string) error
+	EditComment(owner, repo string, number int, id int, comment string, pr bool) error
+	DeleteComment(owner, repo string, number, id int, pr bool) error
+	ListIssueComments(org, repo string, number int) ([]*scm.Comment, error)
+	ListPullRequestComments(org, repo string, number int) ([]*scm.Comment, error)
+	BotName() (string, error)
+}
+
+func handlePullRequest(pc plugins.Agent, pre scm.PullRequestHook) error {
+	switch pre.Action {
+	case scm.ActionOpen, scm.ActionReopen, scm.ActionEdited, scm.ActionUpdate:
+	default:
+		return nil
+	}
+	return handle(pc.Logger, pc.SCMProviderClient, pc.PluginConfig, pre.Repo.Namespace, pre.Repo.Name, &pre.PullRequest)
+}
+
+func handle(log *logrus.Entry, spc scmProviderClient, config *plugins.Configuration, org, repo string, pr *scm.PullRequest) error {
+	number := pr.Number
+	note, found := ExtractReleaseNote(pr.Body)
+	label := labels.ReleaseNoteNeeded
+	if found {
+		label = labels.ReleaseNote
+		if note == "" {
+			label = labels.ReleaseNoteNone
+		}
+	}
+
+	issueLabels, err := spc.GetIssueLabels(org, repo, number, true)
+	if err != nil {
+		return fmt.Errorf("failed to get the labels of %s/%s#%d: %v", org, repo, number, err)
+	}
+	for _, l := range allLabels {
+		has := scmprovider.HasLabel(l, issueLabels)
+		if l == label && !has {
+			log.Infof("Adding %q label", l)
+			if err := spc.AddLabel(org, repo, number, l, true); err != nil {
+				return err
+			}
+		} else if l != label && has {
+			log.Infof("Removing %q label", l)
+			if err := spc.RemoveLabel(org, repo, number, l, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	comments := botcomment.NewClient(spc, org, repo, number, true)
+	if found {
+		return comments.Prune(PluginName)
+	}
+	msg, err := config.RenderComment(PluginName, org, repo, ReleaseNoteInfo{Org: org, Repo: repo, Number: number})
+	if err != nil {
+		return err
+	}
+	return comments.Upsert(PluginName, msg)
+}
+
+// ExtractReleaseNote returns the content of the release-note block of a pull request description, and whether there is
+// one. The content is empty when the change is not visible to the users, e.g. when the block says NONE or N/A.
+func ExtractReleaseNote(body string) (string, bool) {
+	match := noteRe.FindStringSubmatch(body)
+	if match == nil {
+		return "", false
+	}
+	note := strings.TrimSpace(match[1])
+	if noneRe.MatchString(note) {
+		note = ""
+	}
+	return note, true
+}
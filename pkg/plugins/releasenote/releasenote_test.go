@@ -0,0 +1,150 @@
+package releasenote
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractReleaseNote(t *testing.T) {
+	testCases := []struct {
+		name        string
+		body        string
+		expectNote  string
+		expectFound bool
+	}{
+		{
+			name: "no block",
+			body: "Fixes the widget",
+		},
+		{
+			name:        "note",
+			body:        "Fixes #12\n\n```release-note\nFixed the widget spinning forever.\n```\n",
+			expectNote:  "Fixed the widget spinning forever.",
+			expectFound: true,
+		},
+		{
+			name:        "multi line note",
+			body:        "```release-note\r\nFixed the widget.\r\nAdded a spinner.\r\n```",
+			expectNote:  "Fixed the widget.\r\nAdded a spinner.",
+			expectFound: true,
+		},
+		{
+			name:        "none",
+			body:        "```release-note\nNONE\n```",
+			expectFound: true,
+		},
+		{
+			name:        "n/a",
+			body:        "```release-note\n  n/a.\n```",
+			expectFound: true,
+		},
+		{
+			name: "other block",
+			body: "```go\nfmt.Println(\"release-note\")\n```",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			note, found := ExtractReleaseNote(tc.body)
+			assert.Equal(t, tc.expectFound, found)
+			assert.Equal(t, tc.expectNote, note)
+		})
+	}
+}
+
+func TestHandle(t *testing.T) {
+	testCases := []struct {
+		name            string
+		body            string
+		existingLabels  []string
+		existingComment bool
+		expectAdded     []string
+		expectRemoved   []string
+		expectComment   bool
+		expectPruned    bool
+	}{
+		{
+			name:          "no release note",
+			body:          "Fixes the widget",
+			expectAdded:   []string{labels.ReleaseNoteNeeded},
+			expectComment: true,
+		},
+		{
+			name:        "release note",
+			body:        "```release-note\nFixed the widget.\n```",
+			expectAdded: []string{labels.ReleaseNote},
+		},
+		{
+			name:        "none",
+			body:        "```release-note\nNONE\n```",
+			expectAdded: []string{labels.ReleaseNoteNone},
+		},
+		{
+			name:            "release note added",
+			body:            "```release-note\nFixed the widget.\n```",
+			existingLabels:  []string{labels.ReleaseNoteNeeded},
+			existingComment: true,
+			expectAdded:     []string{labels.ReleaseNote},
+			expectRemoved:   []string{labels.ReleaseNoteNeeded},
+			expectPruned:    true,
+		},
+		{
+			name:           "release note changed to none",
+			body:           "```release-note\nnone\n```",
+			existingLabels: []string{labels.ReleaseNote},
+			expectAdded:    []string{labels.ReleaseNoteNone},
+			expectRemoved:  []string{labels.ReleaseNote},
+		},
+		{
+			name:           "already labelled",
+			body:           "```release-note\nFixed the widget.\n```",
+			existingLabels: []string{labels.ReleaseNote},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := &fake.SCMClient{
+				PullRequestComments: map[int][]*scm.Comment{},
+				IssueComments:       map[int][]*scm.Comment{},
+			}
+			for _, l := range tc.existingLabels {
+				fc.PullRequestLabelsExisting = append(fc.PullRequestLabelsExisting, "org/repo#1:"+l)
+			}
+			botName, err := fc.BotName()
+			require.NoError(t, err)
+			if tc.existingComment {
+				body := "This pull request has no release note\n" + botcomment.Marker(PluginName)
+				fc.PullRequestComments[1] = []*scm.Comment{{ID: 1, Body: body, Author: scm.User{Login: botName}}}
+			}
+			pr := &scm.PullRequest{Number: 1, Body: tc.body}
+
+			err = handle(logrus.WithField("plugin", PluginName), fc, &plugins.Configuration{}, "org", "repo", pr)
+			require.NoError(t, err)
+
+			var expectAdded, expectRemoved []string
+			for _, l := range tc.expectAdded {
+				expectAdded = append(expectAdded, "org/repo#1:"+l)
+			}
+			for _, l := range tc.expectRemoved {
+				expectRemoved = append(expectRemoved, "org/repo#1:"+l)
+			}
+			assert.ElementsMatch(t, expectAdded, fc.PullRequestLabelsAdded)
+			assert.ElementsMatch(t, expectRemoved, fc.PullRequestLabelsRemoved)
+			assert.Equal(t, tc.expectPruned, len(fc.PullRequestCommentsDeleted) == 1, "comment pruned")
+			if tc.expectComment {
+				require.Len(t, fc.PullRequestCommentsAdded, 1)
+				assert.Contains(t, fc.PullRequestCommentsAdded[0], "```release-note")
+			} else {
+				assert.Empty(t, fc.PullRequestCommentsAdded)
+			}
+		})
+	}
+}
@@ -85,7 +85,7 @@ func handle(spc scmProviderClient, log *logrus.Entry, e *scmprovider.GenericComm
 	}
 	statuses := combinedStatus.Statuses
 
-	filteredPresubmits, _, err := trigger.FilterPresubmits(honorOkToTest, spc, e.Body, pr, presubmits, log)
+	filteredPresubmits, _, err := trigger.FilterPresubmits(honorOkToTest, spc, e.Body, pr, presubmits, nil, log)
 	if err != nil {
 		resp := fmt.Sprintf("Cannot get combined status for PR #%d in %s/%s: %v", number, org, repo, err)
 		log.Warn(resp)
@@ -0,0 +1,106 @@
+package plugins
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"text/template"
+
+	"github.com/jenkins-x/lighthouse/pkg/templates"
+)
+
+var (
+	commentTemplatesLock sync.RWMutex
+	commentTemplates     = map[string]string{}
+)
+
+// CommentTemplates holds overrides of the Go text/templates used by plugins to render the comments they post.
+// Templates are referred to by name, e.g. "welcome" or "help", see RegisteredCommentTemplates.
+type CommentTemplates struct {
+	// Templates overrides the default comment templates of all repositories.
+	Templates map[string]string `json:"templates,omitempty"`
+	// Repos overrides comment templates for an org or org/repo, taking precedence over Templates.
+	// The org/repo overrides take precedence over the org ones.
+	Repos map[string]map[string]string `json:"repos,omitempty"`
+}
+
+// RegisterCommentTemplate registers the default template of a comment posted by a plugin so that it can be
+// overridden in the plugin configuration.
+func RegisterCommentTemplate(name, defaultTemplate string) {
+	commentTemplatesLock.Lock()
+	defer commentTemplatesLock.Unlock()
+	commentTemplates[name] = defaultTemplate
+}
+
+// RegisteredCommentTemplates returns the names of all the registered comment templates.
+func RegisteredCommentTemplates() []string {
+	commentTemplatesLock.RLock()
+	defer commentTemplatesLock.RUnlock()
+	var names []string
+	for name := range commentTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CommentTemplate returns the template used to render the named comment in the given repository.
+func (c *Configuration) CommentTemplate(name, org, repo string) string {
+	if c != nil {
+		for _, key := range []string{fmt.Sprintf("%s/%s", org, repo), org} {
+			if text, ok := c.CommentTemplates.Repos[key][name]; ok {
+				return text
+			}
+		}
+		if text, ok := c.CommentTemplates.Templates[name]; ok {
+			return text
+		}
+	}
+	commentTemplatesLock.RLock()
+	defer commentTemplatesLock.RUnlock()
+	return commentTemplates[name]
+}
+
+// RenderComment renders the named comment template of the given repository with the data.
+func (c *Configuration) RenderComment(name, org, repo string, data interface{}) (string, error) {
+	return RenderCommentTemplate(name, c.CommentTemplate(name, org, repo), data)
+}
+
+// RenderCommentTemplate renders the comment template text with the data, making the sprig-style helper functions
+// of the templates package available to it.
+func RenderCommentTemplate(name, text string, data interface{}) (string, error) {
+	tmpl, err := parseCommentTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s comment template: %v", name, err)
+	}
+	return buf.String(), nil
+}
+
+func parseCommentTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templates.FuncMap()).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s comment template: %v", name, err)
+	}
+	return tmpl, nil
+}
+
+func validateCommentTemplates(c CommentTemplates) error {
+	for name, text := range c.Templates {
+		if _, err := parseCommentTemplate(name, text); err != nil {
+			return err
+		}
+	}
+	for key, overrides := range c.Repos {
+		for name, text := range overrides {
+			if _, err := parseCommentTemplate(name, text); err != nil {
+				return fmt.Errorf("invalid comment templates for %s: %v", key, err)
+			}
+		}
+	}
+	return nil
+}
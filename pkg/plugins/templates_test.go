@@ -0,0 +1,93 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderComment(t *testing.T) {
+	RegisterCommentTemplate("test-greeting", "Hello {{.Name}}")
+
+	config := &Configuration{
+		CommentTemplates: CommentTemplates{
+			Templates: map[string]string{
+				"test-greeting": "Hi {{.Name | upper}}",
+			},
+			Repos: map[string]map[string]string{
+				"org": {
+					"test-greeting": "Hey {{.Name}} from the org",
+				},
+				"org/repo": {
+					"test-greeting": "Yo {{.Name | default \"stranger\"}}",
+				},
+			},
+		},
+	}
+	data := struct{ Name string }{Name: "bob"}
+
+	testCases := []struct {
+		name     string
+		config   *Configuration
+		org      string
+		repo     string
+		expected string
+	}{
+		{
+			name:     "default template",
+			config:   &Configuration{},
+			org:      "org",
+			repo:     "repo",
+			expected: "Hello bob",
+		},
+		{
+			name:     "nil config",
+			org:      "org",
+			repo:     "repo",
+			expected: "Hello bob",
+		},
+		{
+			name:     "global override",
+			config:   config,
+			org:      "other",
+			repo:     "repo",
+			expected: "Hi BOB",
+		},
+		{
+			name:     "org override",
+			config:   config,
+			org:      "org",
+			repo:     "other",
+			expected: "Hey bob from the org",
+		},
+		{
+			name:     "repo override",
+			config:   config,
+			org:      "org",
+			repo:     "repo",
+			expected: "Yo bob",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			msg, err := tc.config.RenderComment("test-greeting", tc.org, tc.repo, data)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, msg)
+		})
+	}
+	assert.Contains(t, RegisteredCommentTemplates(), "test-greeting")
+}
+
+func TestValidateCommentTemplates(t *testing.T) {
+	assert.NoError(t, validateCommentTemplates(CommentTemplates{
+		Templates: map[string]string{"help": "{{.Org}}"},
+	}))
+	assert.Error(t, validateCommentTemplates(CommentTemplates{
+		Templates: map[string]string{"help": "{{.Org"},
+	}))
+	assert.Error(t, validateCommentTemplates(CommentTemplates{
+		Repos: map[string]map[string]string{"org": {"help": "{{unknownFunc .Org}}"}},
+	}))
+}
@@ -0,0 +1,52 @@
+package trigger
+
+import (
+	"encoding/json"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/pkg/errors"
+)
+
+// handleDE triggers the deployment jobs matching the environment of a deployment event
+func handleDE(c Client, dh scm.DeployHook) error {
+	var payload string
+	if dh.Data != nil {
+		data, err := json.Marshal(dh.Data)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal the payload of the deployment to %s", dh.Target)
+		}
+		payload = string(data)
+	}
+	for _, j := range c.Config.GetDeployments(dh.Repo) {
+		if !j.ShouldRun(dh.Target) {
+			continue
+		}
+		refs := v1alpha1.Refs{
+			Org:      dh.Repo.Namespace,
+			Repo:     dh.Repo.Name,
+			BaseRef:  dh.Ref.Name,
+			BaseSHA:  dh.Ref.Sha,
+			BaseLink: dh.Repo.Link,
+			CloneURI: dh.Repo.Clone,
+		}
+		deployment := &v1alpha1.DeploymentSpec{
+			Environment:    dh.Target,
+			EnvironmentURL: dh.TargetURL,
+			Task:           dh.Task,
+			Description:    dh.Desc,
+			Payload:        payload,
+		}
+		labels := make(map[string]string)
+		for k, v := range j.Labels {
+			labels[k] = v
+		}
+		pj := jobutil.NewLighthouseJob(jobutil.DeploymentSpec(j, refs, deployment), labels, j.Annotations)
+		c.Logger.WithFields(jobutil.LighthouseJobFields(&pj)).WithField("environment", dh.Target).Info("Creating a new LighthouseJob for deployment.")
+		if _, err := c.LauncherClient.Launch(&pj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
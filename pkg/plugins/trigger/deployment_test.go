@@ -0,0 +1,87 @@
+package trigger
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/launcher/fake"
+	fake2 "github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleDeployment(t *testing.T) {
+	testCases := []struct {
+		name         string
+		environment  string
+		expectedJobs []string
+	}{
+		{
+			name:         "staging",
+			environment:  "staging",
+			expectedJobs: []string{"promote"},
+		},
+		{
+			name:         "production",
+			environment:  "production",
+			expectedJobs: []string{"promote", "promote-production"},
+		},
+		{
+			name:        "preview environments are skipped",
+			environment: "preview-pr-123",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeLauncher := fake.NewLauncher()
+			c := Client{
+				SCMProviderClient: &fake2.SCMClient{},
+				LauncherClient:    fakeLauncher,
+				Config:            &config.Config{ProwConfig: config.ProwConfig{LighthouseJobNamespace: "lighthouseJobs"}},
+				Logger:            logrus.WithField("plugin", PluginName),
+			}
+			deployments := map[string][]job.Deployment{
+				"org/environments": {
+					{
+						Base:             job.Base{Name: "promote"},
+						SkipEnvironments: []string{"^preview-"},
+					},
+					{
+						Base:         job.Base{Name: "promote-production"},
+						Environments: []string{"^production$"},
+					},
+				},
+			}
+			require.NoError(t, c.Config.SetDeployments(deployments))
+
+			dh := scm.DeployHook{
+				Data:   map[string]interface{}{"version": "1.2.3"},
+				Ref:    scm.Reference{Name: "master", Sha: "abc123"},
+				Target: tc.environment,
+				Task:   "deploy",
+				Repo: scm.Repository{
+					Namespace: "org",
+					Name:      "environments",
+					FullName:  "org/environments",
+				},
+			}
+			require.NoError(t, handleDE(c, dh))
+
+			var started []string
+			for _, j := range fakeLauncher.Pipelines {
+				started = append(started, j.Spec.Job)
+				assert.Equal(t, job.DeploymentJob, j.Spec.Type)
+				assert.Equal(t, "abc123", j.Spec.Refs.BaseSHA)
+				env := j.Spec.GetEnvVars()
+				assert.Equal(t, tc.environment, env[v1alpha1.DeployEnvironmentEnv])
+				assert.Equal(t, "deploy", env[v1alpha1.DeployTaskEnv])
+				assert.Equal(t, `{"version":"1.2.3"}`, env[v1alpha1.DeployPayloadEnv])
+			}
+			assert.ElementsMatch(t, tc.expectedJobs, started)
+		})
+	}
+}
@@ -57,13 +57,14 @@ func handleGenericComment(c Client, trigger *plugins.Trigger, gc scmprovider.Gen
 		return fmt.Errorf("error checking trust of %s: %v", commentAuthor, err)
 	}
 	var l []*scm.Label
+	auth := &Authorization{User: commentAuthor, Reason: AuthorizedByTrustedCommenter}
 	if !trusted {
 		// Skip untrusted PRs.
-		l, trusted, err = TrustedPullRequest(c.SCMProviderClient, trigger, gc.IssueAuthor.Login, org, repo, number, nil)
+		l, auth, err = authorizePullRequest(c.SCMProviderClient, trigger, gc.IssueAuthor.Login, org, repo, number, nil)
 		if err != nil {
 			return err
 		}
-		if !trusted {
+		if auth == nil {
 			resp := fmt.Sprintf("Cannot trigger testing until a trusted user reviews the PR and leaves an `/ok-to-test` message.")
 			c.Logger.Infof("Commenting \"%s\".", resp)
 			return c.SCMProviderClient.CreateComment(org, repo, number, true, plugins.FormatResponseRaw(gc.Body, gc.Link, c.SCMProviderClient.QuoteAuthorForComment(gc.Author.Login), resp))
@@ -71,7 +72,7 @@ func handleGenericComment(c Client, trigger *plugins.Trigger, gc scmprovider.Gen
 	}
 
 	// At this point we can trust the PR, so we eventually update labels.
-	// Ensure we have labels before test, because TrustedPullRequest() won't be called
+	// Ensure we have labels before test, because authorizePullRequest() won't be called
 	// when commentAuthor is trusted.
 	if l == nil {
 		l, err = c.SCMProviderClient.GetIssueLabels(org, repo, number, gc.IsPR)
@@ -79,7 +80,11 @@ func handleGenericComment(c Client, trigger *plugins.Trigger, gc scmprovider.Gen
 			return err
 		}
 	}
-	isOkToTest := HonorOkToTest(trigger) && jobutil.OkToTestRe.MatchString(gc.Body)
+	// Only trusted users may mark a PR as trusted, others can only run the tests of PRs already marked as trusted.
+	isOkToTest := HonorOkToTest(trigger) && trusted && jobutil.OkToTestRe.MatchString(gc.Body)
+	if isOkToTest {
+		auth.Reason = AuthorizedByOkToTest
+	}
 	if isOkToTest && !scmprovider.HasLabel(labels.OkToTest, l) {
 		if err := c.SCMProviderClient.AddLabel(org, repo, number, labels.OkToTest, gc.IsPR); err != nil {
 			return err
@@ -91,11 +96,19 @@ func handleGenericComment(c Client, trigger *plugins.Trigger, gc scmprovider.Gen
 		}
 	}
 
-	toTest, toSkip, err := FilterPresubmits(HonorOkToTest(trigger), c.SCMProviderClient, gc.Body, pr, c.Config.GetPresubmits(gc.Repo), c.Logger)
+	monorepo := c.Config.GetMonorepo(gc.Repo)
+	toTest, toSkip, err := FilterPresubmits(HonorOkToTest(trigger), c.SCMProviderClient, gc.Body, pr, c.Config.GetPresubmits(gc.Repo), monorepo, c.Logger)
 	if err != nil {
 		return err
 	}
-	return RunAndSkipJobs(c, pr, toTest, toSkip, gc.GUID, trigger.ElideSkippedContexts)
+	// the summary of the groups is required to merge, so report it whatever the command triggers, e.g. for the PRs
+	// opened before the repository became a monorepo
+	if monorepo != nil {
+		if err := reportMonorepo(c, pr, monorepo, job.NewGitHubDeferredChangedFilesProvider(c.SCMProviderClient, org, repo, number)); err != nil {
+			return err
+		}
+	}
+	return RunAndSkipJobs(c, pr, toTest, toSkip, gc.GUID, *auth, trigger.ElideSkippedContexts)
 }
 
 // HonorOkToTest checks if shoudn't ignore the ok test
@@ -124,7 +137,9 @@ type SCMProviderClient interface {
 // If a comment that we get matches more than one of the above patterns, we
 // consider the set of matching presubmits the union of the results from the
 // matching cases.
-func FilterPresubmits(honorOkToTest bool, scmClient SCMProviderClient, body string, pr *scm.PullRequest, presubmits []job.Presubmit, logger *logrus.Entry) ([]job.Presubmit, []job.Presubmit, error) {
+// The presubmits of the groups of the monorepo, if any, which are not changed by the PR are skipped unless the
+// comment explicitly triggers them.
+func FilterPresubmits(honorOkToTest bool, scmClient SCMProviderClient, body string, pr *scm.PullRequest, presubmits []job.Presubmit, monorepo *job.Monorepo, logger *logrus.Entry) ([]job.Presubmit, []job.Presubmit, error) {
 	org, repo, sha := pr.Base.Repo.Namespace, pr.Base.Repo.Name, pr.Head.Sha
 
 	contextGetter := func() (sets.String, sets.String, error) {
@@ -143,7 +158,7 @@ func FilterPresubmits(honorOkToTest bool, scmClient SCMProviderClient, body stri
 
 	number, branch := pr.Number, pr.Base.Ref
 	changes := job.NewGitHubDeferredChangedFilesProvider(scmClient, org, repo, number)
-	return jobutil.FilterPresubmits(filter, changes, branch, presubmits, logger)
+	return jobutil.FilterMonorepoPresubmits(filter, changes, branch, presubmits, monorepo, logger)
 }
 
 func getContexts(combinedStatus *scm.CombinedStatus) (sets.String, sets.String) {
@@ -61,6 +61,7 @@ type testcase struct {
 	IssueLabels          []string
 	IgnoreOkToTest       bool
 	ElideSkippedContexts bool
+	TrustPolicy          string
 }
 
 func TestHandleGenericComment(t *testing.T) {
@@ -187,6 +188,38 @@ func TestHandleGenericComment(t *testing.T) {
 			ShouldBuild: true,
 			AddedLabels: issueLabels(labels.OkToTest),
 		},
+		{
+			name: "Non-trusted member's ok to test does not mark a trusted author's PR as trusted",
+
+			Author:      "untrusted-member",
+			PRAuthor:    "trusted-member",
+			Body:        "/ok-to-test",
+			State:       "open",
+			IsPR:        true,
+			ShouldBuild: true,
+		},
+		{
+			name: "Anyone's ok to test with the anyone trust policy",
+
+			Author:      "untrusted-member",
+			Body:        "/ok-to-test",
+			State:       "open",
+			IsPR:        true,
+			ShouldBuild: true,
+			AddedLabels: issueLabels(labels.OkToTest),
+			TrustPolicy: plugins.TrustAnyone,
+		},
+		{
+			name: "Org member's /test rejected by the team trust policy",
+
+			Author:      "trusted-member",
+			PRAuthor:    "untrusted-member",
+			Body:        "/test all",
+			State:       "open",
+			IsPR:        true,
+			ShouldBuild: false,
+			TrustPolicy: plugins.TrustTeam,
+		},
 		{
 			name: "Trusted member's ok to test with prefix",
 
@@ -878,7 +911,7 @@ func TestHandleGenericComment(t *testing.T) {
 				SCMProviderClient: g,
 				LauncherClient:    fakeLauncher,
 				Config:            fakeConfig,
-				Logger:            logrus.WithField("plugin", pluginName),
+				Logger:            logrus.WithField("plugin", PluginName),
 			}
 			presubmits := tc.Presubmits
 			if presubmits == nil {
@@ -931,6 +964,10 @@ func TestHandleGenericComment(t *testing.T) {
 			trigger := &plugins.Trigger{
 				IgnoreOkToTest:       tc.IgnoreOkToTest,
 				ElideSkippedContexts: tc.ElideSkippedContexts,
+				TrustPolicy:          tc.TrustPolicy,
+			}
+			if tc.TrustPolicy == plugins.TrustTeam {
+				trigger.TrustedTeam = "leads"
 			}
 
 			log.Printf("running case %s", tc.name)
@@ -1074,3 +1111,70 @@ func TestRetestFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleGenericCommentReportsMonorepo(t *testing.T) {
+	for _, body := range []string{"/ok-to-test", "/test all", "/retest", "/test docs"} {
+		g := &fake2.SCMClient{
+			CreatedStatuses: map[string][]*scm.StatusInput{},
+			OrgMembers:      map[string][]string{"org": {"trusted-member"}},
+			PullRequests: map[int]*scm.PullRequest{
+				0: {
+					Author: scm.User{Login: "outsider"},
+					Head:   scm.PullRequestBranch{Ref: "feature", Sha: "cafe"},
+					Base: scm.PullRequestBranch{
+						Ref:  "master",
+						Repo: scm.Repository{Namespace: "org", Name: "repo"},
+					},
+				},
+			},
+			PullRequestChanges: map[int][]*scm.Change{0: {{Path: "services/api/main.go"}}},
+		}
+		c := Client{
+			SCMProviderClient: g,
+			LauncherClient:    fake.NewLauncher(),
+			Config:            &config.Config{},
+			Logger:            logrus.WithField("plugin", PluginName),
+		}
+		presubmits := map[string][]job.Presubmit{
+			"org/repo": {
+				{Base: job.Base{Name: "api"}, Reporter: job.Reporter{Context: "api"}, AlwaysRun: true, RerunCommand: "/test api", Trigger: `(?m)^/test (?:.*? )?api(?: .*?)?$`},
+				{Base: job.Base{Name: "docs"}, Reporter: job.Reporter{Context: "docs"}, AlwaysRun: true, RerunCommand: "/test docs", Trigger: `(?m)^/test (?:.*? )?docs(?: .*?)?$`},
+			},
+		}
+		if err := c.Config.SetPresubmits(presubmits); err != nil {
+			t.Fatalf("failed to set presubmits: %v", err)
+		}
+		m := job.Monorepo{Groups: []job.MonorepoGroup{
+			{Name: "api", Paths: []string{"services/api/**"}, Jobs: []string{"api"}},
+			{Name: "docs", Paths: []string{"docs/**"}, Jobs: []string{"docs"}},
+		}}
+		if err := m.SetRegexes(); err != nil {
+			t.Fatalf("invalid monorepo: %v", err)
+		}
+		c.Config.Monorepos = map[string]job.Monorepo{"org/repo": m}
+
+		event := scmprovider.GenericCommentEvent{
+			Action:      scm.ActionCreate,
+			Repo:        scm.Repository{Namespace: "org", Name: "repo", FullName: "org/repo"},
+			Body:        body,
+			Author:      scm.User{Login: "trusted-member"},
+			IssueAuthor: scm.User{Login: "outsider"},
+			IssueState:  "open",
+			IsPR:        true,
+		}
+		if err := handleGenericComment(c, &plugins.Trigger{}, event); err != nil {
+			t.Fatalf("%s: didn't expect error: %v", body, err)
+		}
+		var summary *scm.StatusInput
+		for _, s := range g.CreatedStatuses["cafe"] {
+			if s.Label == job.DefaultMonorepoContext {
+				summary = s
+			}
+		}
+		if summary == nil {
+			t.Errorf("%s: expected the monorepo summary to be reported, got %v", body, g.CreatedStatuses)
+		} else if summary.Desc != "Testing api. Skipped docs." {
+			t.Errorf("%s: unexpected monorepo summary %q", body, summary.Desc)
+		}
+	}
+}
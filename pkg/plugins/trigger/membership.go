@@ -0,0 +1,110 @@
+package trigger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+)
+
+const (
+	// defaultMembershipTTL is how long the memberships looked up to trust users are remembered
+	defaultMembershipTTL = 5 * time.Minute
+	// maxMemberships is the number of remembered memberships above which the expired ones are evicted
+	maxMemberships = 10000
+)
+
+// memberships remembers whether users are org members, team members or repo collaborators so that
+// busy PRs do not query the SCM provider for every comment and push
+var memberships = newMembershipCache(defaultMembershipTTL)
+
+type membership struct {
+	member  bool
+	expires time.Time
+}
+
+// membershipCache caches the result of membership lookups for a TTL. Failed lookups are not cached.
+type membershipCache struct {
+	lock    sync.Mutex
+	ttl     time.Duration
+	entries map[string]membership
+	now     func() time.Time
+}
+
+func newMembershipCache(ttl time.Duration) *membershipCache {
+	return &membershipCache{
+		ttl:     ttl,
+		entries: map[string]membership{},
+		now:     time.Now,
+	}
+}
+
+// lookup returns the cached membership for the key, or calls lookup and caches its result
+func (c *membershipCache) lookup(key string, lookup func() (bool, error)) (bool, error) {
+	if c.ttl <= 0 {
+		return lookup()
+	}
+	c.lock.Lock()
+	m, ok := c.entries[key]
+	c.lock.Unlock()
+	if ok && c.now().Before(m.expires) {
+		return m.member, nil
+	}
+
+	member, err := lookup()
+	if err != nil {
+		return false, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := c.now()
+	if len(c.entries) >= maxMemberships {
+		for k, m := range c.entries {
+			if !now.Before(m.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	c.entries[key] = membership{member: member, expires: now.Add(c.ttl)}
+	return member, nil
+}
+
+func (c *membershipCache) isCollaborator(spc trustedUserClient, org, repo, user string) (bool, error) {
+	return c.lookup(fmt.Sprintf("collaborator:%s/%s:%s", org, repo, user), func() (bool, error) {
+		return spc.IsCollaborator(org, repo, user)
+	})
+}
+
+func (c *membershipCache) isMember(spc trustedUserClient, org, user string) (bool, error) {
+	return c.lookup(fmt.Sprintf("member:%s:%s", org, user), func() (bool, error) {
+		return spc.IsMember(org, user)
+	})
+}
+
+func (c *membershipCache) isTeamMember(spc trustedUserClient, org, team, user string) (bool, error) {
+	return c.lookup(fmt.Sprintf("team:%s/%s:%s", org, team, user), func() (bool, error) {
+		teams, err := spc.ListTeams(org)
+		if err != nil {
+			return false, fmt.Errorf("failed to list the teams of %s: %v", org, err)
+		}
+		for _, t := range teams {
+			if !strings.EqualFold(t.Name, team) && !strings.EqualFold(t.Slug, team) {
+				continue
+			}
+			members, err := spc.ListTeamMembers(t.ID, scmprovider.RoleAll)
+			if err != nil {
+				return false, fmt.Errorf("failed to list the members of team %s/%s: %v", org, team, err)
+			}
+			for _, m := range members {
+				if scmprovider.NormLogin(m.Login) == scmprovider.NormLogin(user) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+		return false, fmt.Errorf("team %s/%s not found", org, team)
+	})
+}
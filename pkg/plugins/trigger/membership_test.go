@@ -0,0 +1,147 @@
+package trigger
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	// the tests use fake clients with different memberships for the same users
+	memberships = newMembershipCache(0)
+	os.Exit(m.Run())
+}
+
+func TestTrustedUser(t *testing.T) {
+	testCases := []struct {
+		name    string
+		trigger plugins.Trigger
+		user    string
+
+		expected bool
+		err      bool
+	}{
+		{
+			name:     "collaborator trusted by default",
+			user:     "collab",
+			expected: true,
+		},
+		{
+			name:     "org member trusted by default",
+			user:     "member",
+			expected: true,
+		},
+		{
+			name:     "trusted org member",
+			trigger:  plugins.Trigger{TrustedOrg: "trusted"},
+			user:     "trusted-member",
+			expected: true,
+		},
+		{
+			name: "stranger not trusted by default",
+			user: "stranger",
+		},
+		{
+			name:    "collaborator not trusted by only_org_members",
+			trigger: plugins.Trigger{OnlyOrgMembers: true},
+			user:    "collab",
+		},
+		{
+			name:    "collaborator not trusted by org_members policy",
+			trigger: plugins.Trigger{TrustPolicy: plugins.TrustOrgMembers},
+			user:    "collab",
+		},
+		{
+			name:     "org member trusted by org_members policy",
+			trigger:  plugins.Trigger{TrustPolicy: plugins.TrustOrgMembers},
+			user:     "member",
+			expected: true,
+		},
+		{
+			name:     "team member trusted by team policy",
+			trigger:  plugins.Trigger{TrustPolicy: plugins.TrustTeam, TrustedTeam: "leads"},
+			user:     "sig-lead",
+			expected: true,
+		},
+		{
+			name:    "org member not trusted by team policy",
+			trigger: plugins.Trigger{TrustPolicy: plugins.TrustTeam, TrustedTeam: "leads"},
+			user:    "member",
+		},
+		{
+			name:    "missing team",
+			trigger: plugins.Trigger{TrustPolicy: plugins.TrustTeam, TrustedTeam: "missing"},
+			user:    "sig-lead",
+			err:     true,
+		},
+		{
+			name:     "stranger trusted by anyone policy",
+			trigger:  plugins.Trigger{TrustPolicy: plugins.TrustAnyone},
+			user:     "stranger",
+			expected: true,
+		},
+		{
+			name:     "bot always trusted",
+			trigger:  plugins.Trigger{TrustPolicy: plugins.TrustTeam, TrustedTeam: "leads"},
+			user:     "k8s-ci-robot",
+			expected: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := &fake.SCMClient{
+				Collaborators: []string{"collab"},
+				OrgMembers: map[string][]string{
+					"org":     {"member"},
+					"trusted": {"trusted-member"},
+				},
+			}
+			trusted, err := TrustedUser(fc, &tc.trigger, tc.user, "org", "repo")
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, trusted)
+		})
+	}
+}
+
+func TestMembershipCache(t *testing.T) {
+	now := time.Now()
+	c := newMembershipCache(time.Minute)
+	c.now = func() time.Time { return now }
+
+	lookups := 0
+	member := true
+	var lookupErr error
+	lookup := func() (bool, error) {
+		lookups++
+		return member, lookupErr
+	}
+
+	lookupErr = errors.New("rate limited")
+	_, err := c.lookup("member:org:alice", lookup)
+	require.Error(t, err)
+
+	lookupErr = nil
+	for i := 0; i < 3; i++ {
+		ok, err := c.lookup("member:org:alice", lookup)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+	assert.Equal(t, 2, lookups, "failed lookups are not cached, successful ones are")
+
+	member = false
+	now = now.Add(time.Minute)
+	ok, err := c.lookup("member:org:alice", lookup)
+	require.NoError(t, err)
+	assert.False(t, ok, "expired memberships are looked up again")
+	assert.Equal(t, 3, lookups)
+}
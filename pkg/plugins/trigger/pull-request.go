@@ -27,6 +27,7 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/labels"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
 )
 
 func handlePR(c Client, trigger *plugins.Trigger, pr scm.PullRequestHook) error {
@@ -48,7 +49,7 @@ func handlePR(c Client, trigger *plugins.Trigger, pr scm.PullRequestHook) error
 		}
 		if member {
 			c.Logger.Infof("Author %q is a member, Starting all jobs for new PR.", author)
-			return buildAll(c, &pr.PullRequest, pr.GUID, trigger.ElideSkippedContexts)
+			return buildAll(c, &pr.PullRequest, pr.GUID, Authorization{User: author, Reason: AuthorizedByTrustedAuthor}, trigger.ElideSkippedContexts)
 		}
 		c.Logger.Infof("Author is not a member, Welcome message to PR author %q.", author)
 		if err := welcomeMsg(c.SCMProviderClient, trigger, pr.PullRequest); err != nil {
@@ -57,10 +58,10 @@ func handlePR(c Client, trigger *plugins.Trigger, pr scm.PullRequestHook) error
 	case scm.ActionReopen:
 		// When a PR is reopened, check that the user is in the org or that an org
 		// member had said "/ok-to-test" before building, resulting in label ok-to-test.
-		l, trusted, err := TrustedPullRequest(c.SCMProviderClient, trigger, author, org, repo, num, nil)
+		l, auth, err := authorizePullRequest(c.SCMProviderClient, trigger, author, org, repo, num, nil)
 		if err != nil {
 			return fmt.Errorf("could not validate PR: %s", err)
-		} else if trusted {
+		} else if auth != nil {
 			// Eventually remove need-ok-to-test
 			// Does not work for TrustedUser() == true since labels are not fetched in this case
 			if scmprovider.HasLabel(labels.NeedsOkToTest, l) {
@@ -69,7 +70,7 @@ func handlePR(c Client, trigger *plugins.Trigger, pr scm.PullRequestHook) error
 				}
 			}
 			c.Logger.Info("Starting all jobs for updated PR.")
-			return buildAll(c, &pr.PullRequest, pr.GUID, trigger.ElideSkippedContexts)
+			return buildAll(c, &pr.PullRequest, pr.GUID, *auth, trigger.ElideSkippedContexts)
 		}
 	case scm.ActionEdited, scm.ActionUpdate:
 		// if someone changes the base of their PR, we will get this
@@ -90,7 +91,7 @@ func handlePR(c Client, trigger *plugins.Trigger, pr scm.PullRequestHook) error
 				return fmt.Errorf("could not validate PR: %s", err)
 			} else if !trusted {
 				c.Logger.Info("Starting all jobs for untrusted PR with LGTM.")
-				return buildAll(c, &pr.PullRequest, pr.GUID, trigger.ElideSkippedContexts)
+				return buildAll(c, &pr.PullRequest, pr.GUID, Authorization{User: pr.Sender.Login, Reason: AuthorizedByLGTM}, trigger.ElideSkippedContexts)
 			}
 		}
 	default:
@@ -115,10 +116,13 @@ func buildAllIfTrusted(c Client, trigger *plugins.Trigger, pr scm.PullRequestHoo
 	org, repo, a := orgRepoAuthor(pr.PullRequest)
 	author := string(a)
 	num := pr.PullRequest.Number
-	l, trusted, err := TrustedPullRequest(c.SCMProviderClient, trigger, author, org, repo, num, nil)
+	l, auth, err := authorizePullRequest(c.SCMProviderClient, trigger, author, org, repo, num, nil)
 	if err != nil {
 		return fmt.Errorf("could not validate PR: %s", err)
-	} else if trusted {
+	} else if auth != nil {
+		if pr.Action == scm.ActionSync && auth.Reason == AuthorizedByOkToTest && trigger.RequireOkToTestForNewCommits {
+			return requireOkToTest(c, pr.PullRequest)
+		}
 		// Eventually remove needs-ok-to-test
 		// Will not work for org members since labels are not fetched in this case
 		if scmprovider.HasLabel(labels.NeedsOkToTest, l) {
@@ -127,19 +131,42 @@ func buildAllIfTrusted(c Client, trigger *plugins.Trigger, pr scm.PullRequestHoo
 			}
 		}
 		c.Logger.Info("Starting all jobs for updated PR.")
-		return buildAll(c, &pr.PullRequest, pr.GUID, trigger.ElideSkippedContexts)
+		return buildAll(c, &pr.PullRequest, pr.GUID, *auth, trigger.ElideSkippedContexts)
 	}
 	return nil
 }
 
+// requireOkToTest marks the PR of an untrusted author as untrusted again after new commits were pushed,
+// so that they are not tested until a trusted user reviews them
+func requireOkToTest(c Client, pr scm.PullRequest) error {
+	org, repo, a := orgRepoAuthor(pr)
+	c.Logger.Infof("New commits pushed by untrusted author %q, removing the %s label.", a, labels.OkToTest)
+	if err := c.SCMProviderClient.RemoveLabel(org, repo, pr.Number, labels.OkToTest, true); err != nil {
+		return err
+	}
+	if err := c.SCMProviderClient.AddLabel(org, repo, pr.Number, labels.NeedsOkToTest, true); err != nil {
+		return err
+	}
+	comment := fmt.Sprintf("New commits were pushed to this PR by @%s, who is not trusted. A trusted user needs to review them and reply with `/ok-to-test` on its own line before I test them again.", a)
+	return c.SCMProviderClient.CreateComment(org, repo, pr.Number, true, comment)
+}
+
 func welcomeMsg(spc scmProviderClient, trigger *plugins.Trigger, pr scm.PullRequest) error {
 	var errors []error
 	org, repo, a := orgRepoAuthor(pr)
 	author := string(a)
 	encodedRepoFullName := url.QueryEscape(pr.Base.Repo.FullName)
-	var more string
+	who := fmt.Sprintf("[%s](https://github.com/orgs/%s/people) ", org, org)
 	if trigger.TrustedOrg != "" && trigger.TrustedOrg != org {
-		more = fmt.Sprintf("or [%s](https://github.com/orgs/%s/people) ", trigger.TrustedOrg, trigger.TrustedOrg)
+		who += fmt.Sprintf("or [%s](https://github.com/orgs/%s/people) ", trigger.TrustedOrg, trigger.TrustedOrg)
+	}
+	who += "member"
+	if trigger.Policy() == plugins.TrustTeam {
+		teamOrg := org
+		if trigger.TrustedOrg != "" {
+			teamOrg = trigger.TrustedOrg
+		}
+		who = fmt.Sprintf("member of the %s/%s team", teamOrg, trigger.TrustedTeam)
 	}
 
 	var joinOrgURL string
@@ -165,7 +192,7 @@ I understand the commands that are listed [here](https://go.k8s.io/bot-commands?
 	} else {
 		comment = fmt.Sprintf(`Hi @%s. Thanks for your PR.
 
-I'm waiting for a [%s](https://github.com/orgs/%s/people) %smember to verify that this patch is reasonable to test. If it is, they should reply with `+"`/ok-to-test`"+` on its own line. Until that is done, I will not automatically test new commits in this PR, but the usual testing commands by org members will still work. Regular contributors should [join the org](%s) to skip this step.
+I'm waiting for a %s to verify that this patch is reasonable to test. If it is, they should reply with `+"`/ok-to-test`"+` on its own line. Until that is done, I will not automatically test new commits in this PR, but the usual testing commands by org members will still work. Regular contributors should [join the org](%s) to skip this step.
 
 Once the patch is verified, the new status will be reflected by the `+"`%s`"+` label.
 
@@ -175,7 +202,7 @@ I understand the commands that are listed [here](https://go.k8s.io/bot-commands?
 
 %s
 </details>
-`, author, org, org, more, joinOrgURL, labels.OkToTest, encodedRepoFullName, plugins.AboutThisBotWithoutCommands)
+`, author, who, joinOrgURL, labels.OkToTest, encodedRepoFullName, plugins.AboutThisBotWithoutCommands)
 		if err := spc.AddLabel(org, repo, pr.Number, labels.NeedsOkToTest, true); err != nil {
 			errors = append(errors, err)
 		}
@@ -194,30 +221,87 @@ I understand the commands that are listed [here](https://go.k8s.io/bot-commands?
 // TrustedPullRequest returns whether or not the given PR should be tested.
 // It first checks if the author is in the org, then looks for "ok-to-test" label.
 func TrustedPullRequest(spc scmProviderClient, trigger *plugins.Trigger, author, org, repo string, num int, l []*scm.Label) ([]*scm.Label, bool, error) {
+	l, auth, err := authorizePullRequest(spc, trigger, author, org, repo, num, l)
+	return l, auth != nil, err
+}
+
+// authorizePullRequest returns the authorization to test the given PR, or nil if it should not be tested.
+func authorizePullRequest(spc scmProviderClient, trigger *plugins.Trigger, author, org, repo string, num int, l []*scm.Label) ([]*scm.Label, *Authorization, error) {
 	// First check if the author is a member of the org.
 	if orgMember, err := TrustedUser(spc, trigger, author, org, repo); err != nil {
-		return l, false, fmt.Errorf("error checking %s for trust: %v", author, err)
+		return l, nil, fmt.Errorf("error checking %s for trust: %v", author, err)
 	} else if orgMember {
-		return l, true, nil
+		return l, &Authorization{User: author, Reason: AuthorizedByTrustedAuthor}, nil
 	}
 	// Then check if PR has ok-to-test label
 	if l == nil {
 		var err error
 		l, err = spc.GetIssueLabels(org, repo, num, true)
 		if err != nil {
-			return l, false, err
+			return l, nil, err
+		}
+	}
+	if !scmprovider.HasLabel(labels.OkToTest, l) {
+		return l, nil, nil
+	}
+	issuer, err := okToTestIssuer(spc, trigger, org, repo, num)
+	if err != nil {
+		return l, nil, err
+	}
+	return l, &Authorization{User: issuer, Reason: AuthorizedByOkToTest}, nil
+}
+
+// okToTestIssuer returns the trusted user who issued the latest /ok-to-test of the PR, or an empty string if the
+// ok-to-test label was added by hand.
+func okToTestIssuer(spc scmProviderClient, trigger *plugins.Trigger, org, repo string, num int) (string, error) {
+	comments, err := spc.ListIssueComments(org, repo, num)
+	if err != nil {
+		return "", fmt.Errorf("error listing the comments of %s/%s#%d: %v", org, repo, num, err)
+	}
+	var latest *scm.Comment
+	for _, comment := range comments {
+		if !jobutil.OkToTestRe.MatchString(comment.Body) || (latest != nil && comment.Created.Before(latest.Created)) {
+			continue
+		}
+		trusted, err := TrustedUser(spc, trigger, comment.Author.Login, org, repo)
+		if err != nil {
+			return "", fmt.Errorf("error checking %s for trust: %v", comment.Author.Login, err)
+		}
+		if trusted {
+			latest = comment
 		}
 	}
-	return l, scmprovider.HasLabel(labels.OkToTest, l), nil
+	if latest == nil {
+		return "", nil
+	}
+	return latest.Author.Login, nil
 }
 
 // buildAll ensures that all builds that should run and will be required are built
-func buildAll(c Client, pr *scm.PullRequest, eventGUID string, elideSkippedContexts bool) error {
+func buildAll(c Client, pr *scm.PullRequest, eventGUID string, auth Authorization, elideSkippedContexts bool) error {
 	org, repo, number, branch := pr.Base.Repo.Namespace, pr.Base.Repo.Name, pr.Number, pr.Base.Ref
 	changes := job.NewGitHubDeferredChangedFilesProvider(c.SCMProviderClient, org, repo, number)
-	toTest, toSkip, err := jobutil.FilterPresubmits(jobutil.TestAllFilter(), changes, branch, c.Config.GetPresubmits(pr.Base.Repo), c.Logger)
+	monorepo := c.Config.GetMonorepo(pr.Base.Repo)
+	toTest, toSkip, err := jobutil.FilterMonorepoPresubmits(jobutil.TestAllFilter(), changes, branch, c.Config.GetPresubmits(pr.Base.Repo), monorepo, c.Logger)
+	if err != nil {
+		return err
+	}
+	if monorepo != nil {
+		if err := reportMonorepo(c, pr, monorepo, changes); err != nil {
+			return err
+		}
+	}
+	return RunAndSkipJobs(c, pr, toTest, toSkip, eventGUID, auth, elideSkippedContexts)
+}
+
+// reportMonorepo posts the status summarizing the groups of the monorepo tested and skipped by the PR
+func reportMonorepo(c Client, pr *scm.PullRequest, monorepo *job.Monorepo, changes job.ChangedFilesProvider) error {
+	changeList, err := changes()
 	if err != nil {
 		return err
 	}
-	return RunAndSkipJobs(c, pr, toTest, toSkip, eventGUID, elideSkippedContexts)
+	changed, unchanged := monorepo.ChangedGroups(changeList)
+	c.Logger.WithFields(logrus.Fields{"changed": changed, "unchanged": unchanged}).Info("Scoping monorepo presubmits.")
+	_, err = c.SCMProviderClient.CreateStatus(pr.Base.Repo.Namespace, pr.Base.Repo.Name, pr.Head.Sha, monorepoStatusFor(monorepo, changed, unchanged))
+	return err
 }
@@ -17,7 +17,9 @@ limitations under the License.
 package trigger
 
 import (
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/lighthouse/pkg/config"
@@ -27,6 +29,8 @@ import (
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	fake2 "github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
 	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTrusted(t *testing.T) {
@@ -107,6 +111,34 @@ func TestTrusted(t *testing.T) {
 	}
 }
 
+func TestAuthorizePullRequestRecordsOkToTestIssuer(t *testing.T) {
+	const rando = "random-person"
+	const member = "org-member"
+	const other = "other-member"
+
+	now := time.Now()
+	g := &fake2.SCMClient{
+		OrgMembers: map[string][]string{"kubernetes-incubator": {member, other}},
+		IssueComments: map[int][]*scm.Comment{1: {
+			{Body: "/ok-to-test", Author: scm.User{Login: other}, Created: now.Add(-time.Hour)},
+			{Body: "/ok-to-test", Author: scm.User{Login: member}, Created: now.Add(-time.Minute)},
+			{Body: "/ok-to-test", Author: scm.User{Login: rando}, Created: now},
+			{Body: "/lgtm", Author: scm.User{Login: other}, Created: now},
+		}},
+	}
+	okToTest := []*scm.Label{{Name: labels.OkToTest}}
+	_, auth, err := authorizePullRequest(g, &plugins.Trigger{}, rando, "kubernetes-incubator", "random-repo", 1, okToTest)
+	require.NoError(t, err)
+	require.NotNil(t, auth)
+	assert.Equal(t, Authorization{User: member, Reason: AuthorizedByOkToTest}, *auth, "the latest trusted issuer of /ok-to-test authorized the run, not the author")
+
+	// the label was added by hand
+	_, auth, err = authorizePullRequest(g, &plugins.Trigger{}, rando, "kubernetes-incubator", "random-repo", 2, okToTest)
+	require.NoError(t, err)
+	require.NotNil(t, auth)
+	assert.Equal(t, Authorization{Reason: AuthorizedByOkToTest}, *auth)
+}
+
 func TestHandlePullRequest(t *testing.T) {
 	var testcases = []struct {
 		name string
@@ -118,6 +150,9 @@ func TestHandlePullRequest(t *testing.T) {
 		prLabel       string
 		prChanges     bool
 		prAction      scm.Action
+
+		requireOkToTestForNewCommits bool
+		expectedLabelsRemoved        []string
 	}{
 		{
 			name: "Trusted user open PR should build",
@@ -225,6 +260,25 @@ func TestHandlePullRequest(t *testing.T) {
 			HasOkToTest: true,
 			prAction:    scm.ActionSync,
 		},
+		{
+			name: "Untrusted user sync PR with ok-to-test should not build when new commits require ok-to-test",
+
+			Author:                       "u",
+			ShouldBuild:                  false,
+			ShouldComment:                true,
+			HasOkToTest:                  true,
+			prAction:                     scm.ActionSync,
+			requireOkToTestForNewCommits: true,
+			expectedLabelsRemoved:        issueLabels(labels.OkToTest),
+		},
+		{
+			name: "Trusted user sync PR should build when new commits require ok-to-test",
+
+			Author:                       "t",
+			ShouldBuild:                  true,
+			prAction:                     scm.ActionSync,
+			requireOkToTestForNewCommits: true,
+		},
 		{
 			name: "Trusted user labeled PR with lgtm should not build",
 
@@ -282,7 +336,7 @@ func TestHandlePullRequest(t *testing.T) {
 			SCMProviderClient: g,
 			LauncherClient:    fakeLauncher,
 			Config:            &config.Config{},
-			Logger:            logrus.WithField("plugin", pluginName),
+			Logger:            logrus.WithField("plugin", PluginName),
 		}
 
 		presubmits := map[string][]job.Presubmit{
@@ -331,8 +385,9 @@ func TestHandlePullRequest(t *testing.T) {
 			}
 		}
 		trigger := &plugins.Trigger{
-			TrustedOrg:     "org",
-			OnlyOrgMembers: true,
+			TrustedOrg:                   "org",
+			OnlyOrgMembers:               true,
+			RequireOkToTestForNewCommits: tc.requireOkToTestForNewCommits,
 		}
 		if err := handlePR(c, trigger, pr); err != nil {
 			t.Fatalf("Didn't expect error: %s", err)
@@ -352,5 +407,8 @@ func TestHandlePullRequest(t *testing.T) {
 		} else if !tc.ShouldComment && len(g.PullRequestCommentsAdded) > 0 {
 			t.Errorf("Expected no comments to github, but got %d", len(g.CreatedStatuses))
 		}
+		if !reflect.DeepEqual(g.PullRequestLabelsRemoved, tc.expectedLabelsRemoved) {
+			t.Errorf("Expected labels %v to be removed, got %v", tc.expectedLabelsRemoved, g.PullRequestLabelsRemoved)
+		}
 	}
 }
@@ -17,6 +17,8 @@ limitations under the License.
 package trigger
 
 import (
+	"strings"
+
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
@@ -76,11 +78,45 @@ func handlePE(c Client, pe scm.PushHook) error {
 			labels[k] = v
 		}
 		labels[scmprovider.EventGUID] = pe.GUID
-		pj := jobutil.NewLighthouseJob(jobutil.PostsubmitSpec(j, refs), labels, j.Annotations)
+		spec := jobutil.PostsubmitSpec(j, refs)
+		if j.UsesChangedFiles() {
+			changedFiles, err := listPushEventChanges(pe)()
+			if err != nil {
+				return err
+			}
+			jobutil.SetChangedFiles(&spec, changedFiles)
+		}
+		pj := jobutil.NewLighthouseJob(spec, labels, j.Annotations)
 		c.Logger.WithFields(jobutil.LighthouseJobFields(&pj)).Info("Creating a new LighthouseJob.")
 		if _, err := c.LauncherClient.Launch(&pj); err != nil {
 			return err
 		}
 	}
+	if strings.HasPrefix(pe.Ref, "refs/tags/") {
+		return handleTagPush(c, pe)
+	}
+	return nil
+}
+
+// handleTagPush triggers the release jobs matching a pushed git tag. Publishing a release on the git provider
+// creates its tag, so this also drives release pipelines from published releases.
+func handleTagPush(c Client, pe scm.PushHook) error {
+	tag := strings.TrimPrefix(pe.Ref, "refs/tags/")
+	for _, j := range c.Config.GetReleases(pe.Repo) {
+		if !j.ShouldRun(tag) {
+			continue
+		}
+		refs := createRefs(&pe)
+		labels := make(map[string]string)
+		for k, v := range j.Labels {
+			labels[k] = v
+		}
+		labels[scmprovider.EventGUID] = pe.GUID
+		pj := jobutil.NewLighthouseJob(jobutil.ReleaseSpec(j, refs), labels, j.Annotations)
+		c.Logger.WithFields(jobutil.LighthouseJobFields(&pj)).WithField("tag", tag).Info("Creating a new LighthouseJob for release.")
+		if _, err := c.LauncherClient.Launch(&pj); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package trigger
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/jenkins-x/go-scm/scm"
@@ -138,7 +139,7 @@ func TestHandlePE(t *testing.T) {
 			SCMProviderClient: g,
 			LauncherClient:    fakeLauncher,
 			Config:            &config.Config{ProwConfig: config.ProwConfig{LighthouseJobNamespace: "lighthouseJobs"}},
-			Logger:            logrus.WithField("plugin", pluginName),
+			Logger:            logrus.WithField("plugin", PluginName),
 		}
 		postsubmits := map[string][]job.Postsubmit{
 			"org/repo": {
@@ -186,3 +187,83 @@ func TestHandlePE(t *testing.T) {
 		}
 	}
 }
+
+func TestHandleTagPush(t *testing.T) {
+	testCases := []struct {
+		name         string
+		ref          string
+		expectedJobs []string
+	}{
+		{
+			name: "branch push",
+			ref:  "refs/heads/master",
+		},
+		{
+			name:         "release tag",
+			ref:          "refs/tags/v1.2.3",
+			expectedJobs: []string{"release"},
+		},
+		{
+			name:         "pre-release tag",
+			ref:          "refs/tags/v1.2.3-rc.1",
+			expectedJobs: []string{"release-candidate"},
+		},
+		{
+			name: "unmatched tag",
+			ref:  "refs/tags/nightly",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeLauncher := fake.NewLauncher()
+			c := Client{
+				SCMProviderClient: &fake2.SCMClient{},
+				LauncherClient:    fakeLauncher,
+				Config:            &config.Config{ProwConfig: config.ProwConfig{LighthouseJobNamespace: "lighthouseJobs"}},
+				Logger:            logrus.WithField("plugin", PluginName),
+			}
+			releases := map[string][]job.Release{
+				"org/repo": {
+					{
+						Base:     job.Base{Name: "release"},
+						Tags:     []string{`^v\d+\.\d+\.\d+`},
+						SkipTags: []string{`-rc\.\d+$`},
+					},
+					{
+						Base: job.Base{Name: "release-candidate"},
+						Tags: []string{`-rc\.\d+$`},
+					},
+				},
+			}
+			if err := c.Config.SetReleases(releases); err != nil {
+				t.Fatalf("failed to set releases: %v", err)
+			}
+			pe := scm.PushHook{
+				Ref:   tc.ref,
+				After: "abc123",
+				Repo: scm.Repository{
+					Namespace: "org",
+					Name:      "repo",
+					FullName:  "org/repo",
+				},
+			}
+			if err := handlePE(c, pe); err != nil {
+				t.Fatalf("handlePE returned unexpected error %v", err)
+			}
+			var started []string
+			for _, j := range fakeLauncher.Pipelines {
+				started = append(started, j.Spec.Job)
+				if j.Spec.Type != job.ReleaseJob {
+					t.Errorf("expected job type %s, got %s", job.ReleaseJob, j.Spec.Type)
+				}
+				env := j.Spec.GetEnvVars()
+				if env[v1alpha1.TagNameEnv] != strings.TrimPrefix(tc.ref, "refs/tags/") {
+					t.Errorf("expected %s to be the tag name, got %q", v1alpha1.TagNameEnv, env[v1alpha1.TagNameEnv])
+				}
+			}
+			if !equality.Semantic.DeepEqual(started, tc.expectedJobs) {
+				t.Errorf("expected jobs %v, got %v", tc.expectedJobs, started)
+			}
+		})
+	}
+}
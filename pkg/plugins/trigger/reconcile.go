@@ -0,0 +1,263 @@
+package trigger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/errorutil"
+	"github.com/jenkins-x/lighthouse/pkg/jobutil"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider/reporter"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// reconcileGracePeriod is how long the pull requests are left alone after they were updated, as the webhooks of
+	// the update may still be processed
+	reconcileGracePeriod = 10 * time.Minute
+
+	// reconcileEventGUID is the event GUID of the jobs triggered when reconciling the statuses of a pull request
+	reconcileEventGUID = "status-reconcile"
+)
+
+type pullRequestLister interface {
+	ListAllPullRequestsForFullNameRepo(string, scm.PullRequestListOptions) ([]*scm.PullRequest, error)
+	Search(scm.SearchOptions) ([]*scm.SearchIssue, *scmprovider.RateLimits, error)
+	ProviderType() string
+}
+
+type jobLister interface {
+	List(opts metav1.ListOptions) (*v1alpha1.LighthouseJobList, error)
+}
+
+// ReconcileAll reconciles the statuses of the open pull requests of the orgs and repos, recovering from the webhooks
+// which were not delivered or not processed, e.g. while Lighthouse was down. The open pull requests of the orgs are
+// found by searching them, which only GitHub supports, so the repos have to be listed on the other providers.
+func ReconcileAll(c Client, prs pullRequestLister, jobs jobLister, pluginConfig *plugins.Configuration, orgs, repos []string) error {
+	seen := sets.NewString()
+	var errs []string
+	now := time.Now()
+	check := func(org, repo string, pr *scm.PullRequest) {
+		key := fmt.Sprintf("%s/%s#%d", org, repo, pr.Number)
+		if seen.Has(key) {
+			return
+		}
+		seen.Insert(key)
+		if err := reconcile(c, jobs, pluginConfig.TriggerFor(org, repo), pr, now); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", key, err))
+		}
+	}
+
+	for _, fullName := range repos {
+		results, err := prs.ListAllPullRequestsForFullNameRepo(fullName, scm.PullRequestListOptions{Open: true, Size: 100})
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to list open pull requests of %s: %v", fullName, err))
+			continue
+		}
+		parts := strings.SplitN(fullName, "/", 2)
+		for _, pr := range results {
+			check(parts[0], parts[1], pr)
+		}
+	}
+	if len(orgs) > 0 && prs.ProviderType() != "github" {
+		c.Logger.WithField("orgs", orgs).Warnf("cannot reconcile the statuses of the pull requests of orgs on %s, enable the trigger plugin for their repos instead", prs.ProviderType())
+		orgs = nil
+	}
+	for _, org := range orgs {
+		results, _, err := prs.Search(scm.SearchOptions{Query: fmt.Sprintf("is:pr is:open org:%s", org)})
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to search open pull requests of %s: %v", org, err))
+			continue
+		}
+		for _, r := range results {
+			pr, err := c.SCMProviderClient.GetPullRequest(org, r.Repository.Name, r.Number)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("failed to get %s/%s#%d: %v", org, r.Repository.Name, r.Number, err))
+				continue
+			}
+			check(org, r.Repository.Name, pr)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to reconcile the statuses of %d pull requests: %s", len(errs), strings.Join(errs, "; "))
+	}
+	c.Logger.WithField("pullRequests", seen.Len()).Info("reconciled the statuses of open pull requests")
+	return nil
+}
+
+// reconcile looks for the required contexts of the pull request without any status. The status of a context whose job
+// already reported is reported again, the jobs which never ran are triggered if the pull request is trusted and the
+// skipped statuses are reported again.
+func reconcile(c Client, jobs jobLister, trigger *plugins.Trigger, pr *scm.PullRequest, now time.Time) error {
+	if now.Sub(pr.Updated) < reconcileGracePeriod {
+		return nil
+	}
+	presubmits := c.Config.GetPresubmits(pr.Base.Repo)
+	if len(presubmits) == 0 {
+		return nil
+	}
+	org, repo, a := orgRepoAuthor(*pr)
+	combined, err := c.SCMProviderClient.GetCombinedStatus(org, repo, pr.Head.Sha)
+	if err != nil {
+		return fmt.Errorf("failed to get the statuses of %s: %v", pr.Head.Sha, err)
+	}
+	reported := sets.NewString()
+	if combined != nil {
+		for _, s := range combined.Statuses {
+			reported.Insert(s.Label)
+		}
+	}
+	checked := sets.NewString()
+	if c.SCMProviderClient.SupportsCheckRuns() {
+		runs, err := c.SCMProviderClient.ListCheckRuns(org, repo, pr.Head.Sha, "")
+		if err != nil {
+			return fmt.Errorf("failed to list the check runs of %s: %v", pr.Head.Sha, err)
+		}
+		for _, run := range runs {
+			checked.Insert(run.Name)
+		}
+	}
+	// the contexts of the jobs reporting through check runs are looked for in the check runs, the others in the statuses
+	reportedContext := func(context string, modes []job.ReportMode) bool {
+		if reportsCheckRun(c, org, repo, modes) {
+			return checked.Has(context)
+		}
+		return reported.Has(context)
+	}
+
+	changes := job.NewGitHubDeferredChangedFilesProvider(c.SCMProviderClient, org, repo, pr.Number)
+	monorepo := c.Config.GetMonorepo(pr.Base.Repo)
+	toTest, toSkip, err := jobutil.FilterMonorepoPresubmits(jobutil.TestAllFilter(), changes, pr.Base.Ref, presubmits, monorepo, c.Logger)
+	if err != nil {
+		return err
+	}
+	missingTests := missingContexts(toTest, func(p job.Presubmit) bool {
+		return reportedContext(p.Context, p.ReportModes())
+	})
+	var missingSkips []job.Presubmit
+	if !trigger.ElideSkippedContexts {
+		// the skipped contexts are always reported as statuses
+		missingSkips = missingContexts(toSkip, func(p job.Presubmit) bool {
+			return reported.Has(p.Context)
+		})
+	}
+	// the summary of the groups of a monorepo is required, e.g. for the PRs opened before the repo became a monorepo
+	missingSummary := monorepo != nil && !reported.Has(monorepoContext(monorepo))
+	if len(missingTests) == 0 && len(missingSkips) == 0 && !missingSummary {
+		return nil
+	}
+
+	logger := c.Logger.WithFields(logrus.Fields{
+		scmprovider.OrgLogField:  org,
+		scmprovider.RepoLogField: repo,
+		scmprovider.PrLogField:   pr.Number,
+		"sha":                    pr.Head.Sha,
+	})
+	c.Logger = logger
+	var errs []error
+	var toRun []job.Presubmit
+	if len(missingTests) > 0 {
+		latest, err := latestJobs(jobs, org, repo, pr)
+		if err != nil {
+			return err
+		}
+		for _, p := range missingTests {
+			j := latest[p.Context]
+			switch {
+			case j == nil:
+				toRun = append(toRun, p)
+			case j.Status.LastReportState != "":
+				logger.Infof("Reporting the missing status of context %s again from LighthouseJob %s.", p.Context, j.Name)
+				errs = append(errs, reportAgain(c, org, repo, pr.Head.Sha, j))
+			}
+			// otherwise the job is yet to report its status
+		}
+	}
+	if len(toRun) > 0 || len(missingSkips) > 0 || missingSummary {
+		// the statuses of untrusted pull requests are only reported once they are trusted
+		_, auth, err := authorizePullRequest(c.SCMProviderClient, trigger, string(a), org, repo, pr.Number, nil)
+		if err != nil {
+			return errorutil.NewAggregate(append(errs, fmt.Errorf("could not validate PR: %s", err))...)
+		}
+		if auth != nil {
+			if missingSummary {
+				logger.Info("Reporting the missing summary of the monorepo groups.")
+				errs = append(errs, reportMonorepo(c, pr, monorepo, changes))
+			}
+			if len(toRun) > 0 {
+				logger.Infof("Starting %d jobs whose status is missing.", len(toRun))
+				errs = append(errs, runRequested(c, pr, toRun, reconcileEventGUID, *auth))
+			}
+			errs = append(errs, skipRequested(c, pr, missingSkips))
+		}
+	}
+	return errorutil.NewAggregate(errs...)
+}
+
+// missingContexts returns the presubmits whose contexts are required but not reported
+func missingContexts(presubmits []job.Presubmit, reported func(job.Presubmit) bool) []job.Presubmit {
+	var answer []job.Presubmit
+	for _, p := range presubmits {
+		if p.ContextRequired() && !reported(p) {
+			answer = append(answer, p)
+		}
+	}
+	return answer
+}
+
+// latestJobs returns the latest presubmit of each context which built the head of the pull request
+func latestJobs(jobs jobLister, org, repo string, pr *scm.PullRequest) (map[string]*v1alpha1.LighthouseJob, error) {
+	selector := fmt.Sprintf("%s=%s,%s=%s,%s=%s,%s=%s,%s=%s", job.LighthouseJobTypeLabel, job.PresubmitJob,
+		util.OrgLabel, strings.ToLower(org), util.RepoLabel, repo, util.PullLabel, strconv.Itoa(pr.Number),
+		util.LastCommitSHALabel, pr.Head.Sha)
+	list, err := jobs.List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the LighthouseJobs of %s/%s#%d: %v", org, repo, pr.Number, err)
+	}
+	answer := map[string]*v1alpha1.LighthouseJob{}
+	for i := range list.Items {
+		j := &list.Items[i]
+		if latest := answer[j.Spec.Context]; latest == nil || latest.CreationTimestamp.Before(&j.CreationTimestamp) {
+			answer[j.Spec.Context] = j
+		}
+	}
+	return answer, nil
+}
+
+// reportsCheckRun returns whether a job with the report modes reports its context as a check run, like foghorn does:
+// explicit check or status modes take precedence over the check runs enabled for the repository
+func reportsCheckRun(c Client, org, repo string, modes []job.ReportMode) bool {
+	if !c.SCMProviderClient.SupportsCheckRuns() {
+		return false
+	}
+	return job.Reports(modes, job.ReportCheck) || len(modes) == 0 && c.Config.CheckRunsEnabled(org+"/"+repo)
+}
+
+// reportAgain reports the result last reported by the job again, on the channel the job reports to
+func reportAgain(c Client, org, repo, sha string, j *v1alpha1.LighthouseJob) error {
+	if reportsCheckRun(c, org, repo, j.Spec.Report) {
+		return reporter.ReportCheckRun(c.SCMProviderClient, j, nil, sha, nil)
+	}
+	_, err := c.SCMProviderClient.CreateStatus(org, repo, sha, reportedStatusFor(j))
+	return err
+}
+
+// reportedStatusFor returns the status last reported by the job
+func reportedStatusFor(j *v1alpha1.LighthouseJob) *scm.StatusInput {
+	return &scm.StatusInput{
+		State:  scm.ToState(j.Status.LastReportState),
+		Label:  j.Spec.Context,
+		Desc:   j.Status.Description,
+		Target: j.Status.ReportURL,
+	}
+}
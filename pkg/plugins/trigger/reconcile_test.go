@@ -0,0 +1,285 @@
+package trigger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/labels"
+	"github.com/jenkins-x/lighthouse/pkg/launcher/fake"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	fake2 "github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeJobLister struct {
+	jobs     []v1alpha1.LighthouseJob
+	selector string
+}
+
+func (f *fakeJobLister) List(opts metav1.ListOptions) (*v1alpha1.LighthouseJobList, error) {
+	f.selector = opts.LabelSelector
+	return &v1alpha1.LighthouseJobList{Items: f.jobs}, nil
+}
+
+func TestReconcile(t *testing.T) {
+	now := time.Now()
+	reportedJob := v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-1"},
+		Spec:       v1alpha1.LighthouseJobSpec{Context: "build"},
+		Status: v1alpha1.LighthouseJobStatus{
+			LastReportState: "failure",
+			Description:     "Pipeline failed",
+			ReportURL:       "https://dashboard/build-1",
+		},
+	}
+	reportedCheckJob := *reportedJob.DeepCopy()
+	reportedCheckJob.Spec.Report = []job.ReportMode{job.ReportCheck}
+	reportedCheckJob.Status.State = v1alpha1.FailureState
+	reportedCheckJob.Spec.Refs = &v1alpha1.Refs{Org: "org", Repo: "repo"}
+	runningJob := v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-2"},
+		Spec:       v1alpha1.LighthouseJobSpec{Context: "build"},
+	}
+
+	testcases := []struct {
+		name              string
+		author            string
+		okToTest          bool
+		updated           time.Time
+		statuses          []string
+		checkRuns         []string
+		checks            bool
+		jobs              []v1alpha1.LighthouseJob
+		elide             bool
+		monorepo          bool
+		expectedJobs      []string
+		expectedStatuses  map[string]scm.State
+		expectedCheckRuns map[string]string
+	}{
+		{
+			name:             "missing statuses of a trusted PR",
+			author:           "t",
+			updated:          now.Add(-time.Hour),
+			expectedJobs:     []string{"build"},
+			expectedStatuses: map[string]scm.State{"docs": scm.StateSuccess},
+		},
+		{
+			name:     "all statuses reported",
+			author:   "t",
+			updated:  now.Add(-time.Hour),
+			statuses: []string{"build", "docs"},
+		},
+		{
+			name:             "missing monorepo summary",
+			author:           "t",
+			updated:          now.Add(-time.Hour),
+			statuses:         []string{"build", "docs"},
+			monorepo:         true,
+			expectedStatuses: map[string]scm.State{job.DefaultMonorepoContext: scm.StateSuccess},
+		},
+		{
+			name:     "missing monorepo summary of an untrusted PR",
+			author:   "u",
+			updated:  now.Add(-time.Hour),
+			statuses: []string{"build", "docs"},
+			monorepo: true,
+		},
+		{
+			name:     "optional and elided skipped contexts are not reconciled",
+			author:   "t",
+			updated:  now.Add(-time.Hour),
+			statuses: []string{"build"},
+			elide:    true,
+		},
+		{
+			name:    "recently updated PR",
+			author:  "t",
+			updated: now.Add(-time.Minute),
+		},
+		{
+			name:    "untrusted PR",
+			author:  "u",
+			updated: now.Add(-time.Hour),
+		},
+		{
+			name:             "untrusted PR with ok-to-test",
+			author:           "u",
+			okToTest:         true,
+			updated:          now.Add(-time.Hour),
+			expectedJobs:     []string{"build"},
+			expectedStatuses: map[string]scm.State{"docs": scm.StateSuccess},
+		},
+		{
+			name:             "status of a job reported again",
+			author:           "u",
+			updated:          now.Add(-time.Hour),
+			statuses:         []string{"docs"},
+			jobs:             []v1alpha1.LighthouseJob{reportedJob},
+			expectedStatuses: map[string]scm.State{"build": scm.StateFailure},
+		},
+		{
+			name:      "context of a job reporting check runs found in the check runs",
+			author:    "t",
+			updated:   now.Add(-time.Hour),
+			statuses:  []string{"docs"},
+			checkRuns: []string{"build"},
+			checks:    true,
+		},
+		{
+			name:              "check run of a job reported again",
+			author:            "t",
+			updated:           now.Add(-time.Hour),
+			statuses:          []string{"docs", "build"},
+			checks:            true,
+			jobs:              []v1alpha1.LighthouseJob{reportedCheckJob},
+			expectedCheckRuns: map[string]string{"build": scmprovider.CheckRunConclusionFailure},
+		},
+		{
+			name:     "job yet to report",
+			author:   "t",
+			updated:  now.Add(-time.Hour),
+			statuses: []string{"docs"},
+			jobs:     []v1alpha1.LighthouseJob{runningJob},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			combined := &scm.CombinedStatus{}
+			for _, s := range tc.statuses {
+				combined.Statuses = append(combined.Statuses, &scm.Status{Label: s, State: scm.StateSuccess})
+			}
+			g := &fake2.SCMClient{
+				OrgMembers:       map[string][]string{"org": {"t"}},
+				CombinedStatuses: map[string]*scm.CombinedStatus{"head-sha": combined},
+				CheckRuns:        map[string][]*scmprovider.CheckRun{},
+			}
+			for _, name := range tc.checkRuns {
+				g.CheckRuns["head-sha"] = append(g.CheckRuns["head-sha"], &scmprovider.CheckRun{Name: name, HeadSHA: "head-sha"})
+			}
+			if tc.okToTest {
+				g.PullRequestLabelsExisting = []string{"org/repo#1:" + labels.OkToTest}
+			}
+			fakeLauncher := fake.NewLauncher()
+			c := Client{
+				SCMProviderClient: g,
+				LauncherClient:    fakeLauncher,
+				Config:            &config.Config{},
+				Logger:            logrus.WithField("plugin", PluginName),
+			}
+			build := job.Reporter{Context: "build"}
+			if tc.checks {
+				build.Report = []job.ReportMode{job.ReportCheck}
+			}
+			presubmits := map[string][]job.Presubmit{
+				"org/repo": {
+					{Base: job.Base{Name: "build"}, Reporter: build, AlwaysRun: true},
+					{Base: job.Base{Name: "docs"}, Reporter: job.Reporter{Context: "docs"}, RegexpChangeMatcher: job.RegexpChangeMatcher{RunIfChanged: "^docs/"}},
+					{Base: job.Base{Name: "lint"}, Reporter: job.Reporter{Context: "lint"}, AlwaysRun: true, Optional: true},
+				},
+			}
+			require.NoError(t, c.Config.SetPresubmits(presubmits))
+			if tc.monorepo {
+				m := job.Monorepo{Groups: []job.MonorepoGroup{{Name: "docs", Paths: []string{"docs/**"}, Jobs: []string{"docs"}}}}
+				require.NoError(t, m.SetRegexes())
+				c.Config.Monorepos = map[string]job.Monorepo{"org/repo": m}
+			}
+			jobs := &fakeJobLister{jobs: tc.jobs}
+			pr := &scm.PullRequest{
+				Number:  1,
+				Author:  scm.User{Login: tc.author},
+				Updated: tc.updated,
+				Base: scm.PullRequestBranch{
+					Ref:  "master",
+					Repo: scm.Repository{Namespace: "org", Name: "repo", FullName: "org/repo"},
+				},
+				Head: scm.PullRequestBranch{Ref: "feature", Sha: "head-sha"},
+			}
+
+			err := reconcile(c, jobs, &plugins.Trigger{ElideSkippedContexts: tc.elide}, pr, now)
+			require.NoError(t, err)
+
+			var started []string
+			for _, j := range fakeLauncher.Pipelines {
+				started = append(started, j.Spec.Context)
+				assert.Equal(t, reconcileEventGUID, j.Labels[scmprovider.EventGUID])
+			}
+			assert.Equal(t, tc.expectedJobs, started)
+			if tc.jobs != nil {
+				assert.Contains(t, jobs.selector, util.LastCommitSHALabel+"=head-sha")
+			}
+
+			statuses := map[string]scm.State{}
+			for _, refStatuses := range g.CreatedStatuses {
+				for _, s := range refStatuses {
+					statuses[s.Label] = s.State
+				}
+			}
+			if tc.expectedStatuses == nil {
+				tc.expectedStatuses = map[string]scm.State{}
+			}
+			assert.Equal(t, tc.expectedStatuses, statuses)
+			if _, ok := tc.expectedStatuses[job.DefaultMonorepoContext]; ok {
+				var headStatuses []string
+				for _, s := range g.CreatedStatuses["head-sha"] {
+					headStatuses = append(headStatuses, s.Label)
+				}
+				assert.Contains(t, headStatuses, job.DefaultMonorepoContext, "the summary is reported on the head commit")
+			}
+
+			checkRuns := map[string]string{}
+			for _, run := range g.CheckRuns["head-sha"] {
+				if run.Conclusion != "" {
+					checkRuns[run.Name] = run.Conclusion
+				}
+			}
+			if tc.expectedCheckRuns == nil {
+				tc.expectedCheckRuns = map[string]string{}
+			}
+			assert.Equal(t, tc.expectedCheckRuns, checkRuns)
+		})
+	}
+}
+
+type fakePullRequestLister struct {
+	provider string
+	searches []string
+}
+
+func (f *fakePullRequestLister) ListAllPullRequestsForFullNameRepo(string, scm.PullRequestListOptions) ([]*scm.PullRequest, error) {
+	return nil, nil
+}
+
+func (f *fakePullRequestLister) Search(opts scm.SearchOptions) ([]*scm.SearchIssue, *scmprovider.RateLimits, error) {
+	f.searches = append(f.searches, opts.Query)
+	return nil, nil, nil
+}
+
+func (f *fakePullRequestLister) ProviderType() string {
+	return f.provider
+}
+
+func TestReconcileAllSearchesOrgsOnGitHubOnly(t *testing.T) {
+	for provider, expected := range map[string][]string{
+		"github": {"is:pr is:open org:org"},
+		"gitlab": nil,
+	} {
+		prs := &fakePullRequestLister{provider: provider}
+		c := Client{
+			SCMProviderClient: &fake2.SCMClient{},
+			Config:            &config.Config{},
+			Logger:            logrus.WithField("plugin", PluginName),
+		}
+		err := ReconcileAll(c, prs, &fakeJobLister{}, &plugins.Configuration{}, []string{"org"}, nil)
+		require.NoError(t, err, provider)
+		assert.Equal(t, expected, prs.searches, provider)
+	}
+}
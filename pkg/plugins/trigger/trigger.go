@@ -22,6 +22,7 @@ import (
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/basesha"
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
 	"github.com/jenkins-x/lighthouse/pkg/errorutil"
@@ -33,22 +34,26 @@ import (
 )
 
 const (
-	// pluginName is the name of the trigger plugin
-	pluginName = "trigger"
+	// PluginName is the name of the trigger plugin
+	PluginName = "trigger"
+
+	// maxStatusDescriptionLength is the maximum length of the descriptions of the statuses accepted by GitHub
+	maxStatusDescriptionLength = 140
 )
 
 var (
 	plugin = plugins.Plugin{
 		Description: `The trigger plugin starts tests in reaction to commands and pull request events. It is responsible for ensuring that test jobs are only run on trusted PRs. A PR is considered trusted if the author is a member of the 'trusted organization' for the repository or if such a member has left an '/ok-to-test' command on the PR.
 <br>Trigger starts jobs automatically when a new trusted PR is created or when an untrusted PR becomes trusted, but it can also be used to start jobs manually via the '/test' command.
-<br>The '/retest' command can be used to rerun jobs that have reported failure.`,
-		ConfigHelpProvider: configHelp,
-		PullRequestHandler: handlePullRequest,
-		PushEventHandler:   handlePush,
+<br>The '/retest' command can be used to rerun jobs that have reported failure, and the '/retest-required' command to only rerun the failed jobs which are required to merge.`,
+		ConfigHelpProvider:     configHelp,
+		PullRequestHandler:     handlePullRequest,
+		PushEventHandler:       handlePush,
+		DeploymentEventHandler: handleDeployment,
 		Commands: []plugins.Command{{
 			Name:        "ok-to-test",
 			Description: "Marks a PR as 'trusted' and starts tests.",
-			WhoCanUse:   "Members of the trusted organization for the repo.",
+			WhoCanUse:   "Users trusted by the trust policy of the repo, by default its collaborators and the members of the trusted organization.",
 			Action: plugins.
 				Invoke(handleGenericCommentEvent).
 				When(plugins.Action(scm.ActionCreate), plugins.IsPR(), plugins.IssueState("open")),
@@ -69,12 +74,18 @@ var (
 			Action: plugins.
 				Invoke(handleGenericCommentEvent).
 				When(plugins.Action(scm.ActionCreate), plugins.IsPR(), plugins.IssueState("open")),
+		}, {
+			Name:        "retest-required",
+			Description: "Rerun the failed test jobs which are required to merge, leaving the optional ones alone.",
+			Action: plugins.
+				Invoke(handleGenericCommentEvent).
+				When(plugins.Action(scm.ActionCreate), plugins.IsPR(), plugins.IssueState("open")),
 		}},
 	}
 )
 
 func init() {
-	plugins.RegisterPlugin(pluginName, plugin)
+	plugins.RegisterPlugin(PluginName, plugin)
 }
 
 func configHelp(config *plugins.Configuration, enabledRepos []string) (map[string]string, error) {
@@ -94,7 +105,16 @@ func configHelp(config *plugins.Configuration, enabledRepos []string) (map[strin
 		if trigger.TrustedOrg != "" {
 			org = trigger.TrustedOrg
 		}
-		configInfo[orgRepo] = fmt.Sprintf("The trusted GitHub organization for this repository is %q.", org)
+		switch trigger.Policy() {
+		case plugins.TrustAnyone:
+			configInfo[orgRepo] = "Anyone is trusted to test pull requests on this repository."
+		case plugins.TrustTeam:
+			configInfo[orgRepo] = fmt.Sprintf("The trusted team for this repository is %q of the organization %q.", trigger.TrustedTeam, org)
+		case plugins.TrustOrgMembers:
+			configInfo[orgRepo] = fmt.Sprintf("The trusted GitHub organization for this repository is %q.", org)
+		default:
+			configInfo[orgRepo] = fmt.Sprintf("The trusted GitHub organization for this repository is %q. Collaborators of the repository are trusted too.", org)
+		}
 	}
 	return configInfo, nil
 }
@@ -104,15 +124,20 @@ type scmProviderClient interface {
 	BotName() (string, error)
 	IsCollaborator(org, repo, user string) (bool, error)
 	IsMember(org, user string) (bool, error)
+	ListTeams(org string) ([]*scm.Team, error)
+	ListTeamMembers(id int, role string) ([]*scm.TeamMember, error)
 	GetPullRequest(org, repo string, number int) (*scm.PullRequest, error)
 	GetRef(org, repo, ref string) (string, error)
 	CreateComment(owner, repo string, number int, pr bool, comment string) error
 	ListIssueComments(owner, repo string, issue int) ([]*scm.Comment, error)
 	CreateStatus(org, repo, ref string, s *scm.StatusInput) (*scm.Status, error)
 	GetCombinedStatus(org, repo, ref string) (*scm.CombinedStatus, error)
+	SupportsCheckRuns() bool
+	ListCheckRuns(owner, repo, ref, name string) ([]*scmprovider.CheckRun, error)
+	CreateCheckRun(owner, repo string, run *scmprovider.CheckRun) (*scmprovider.CheckRun, error)
+	UpdateCheckRun(owner, repo string, run *scmprovider.CheckRun) (*scmprovider.CheckRun, error)
 	GetPullRequestChanges(org, repo string, number int) ([]*scm.Change, error)
 	RemoveLabel(org, repo string, number int, label string, pr bool) error
-	DeleteStaleComments(org, repo string, number int, comments []*scm.Comment, pr bool, isStale func(*scm.Comment) bool) error
 	GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error)
 	QuoteAuthorForComment(string) string
 	PRRefFmt() string
@@ -130,11 +155,14 @@ type Client struct {
 	LauncherClient    launcher
 	Config            *config.Config
 	Logger            *logrus.Entry
+	BaseSHAs          *basesha.Resolver
 }
 
 type trustedUserClient interface {
 	IsCollaborator(org, repo, user string) (bool, error)
 	IsMember(org, user string) (bool, error)
+	ListTeams(org string) ([]*scm.Team, error)
+	ListTeamMembers(id int, role string) ([]*scm.TeamMember, error)
 	BotName() (string, error)
 }
 
@@ -144,6 +172,7 @@ func getClient(pc plugins.Agent) Client {
 		Config:            pc.Config,
 		LauncherClient:    pc.LauncherClient,
 		Logger:            pc.Logger,
+		BaseSHAs:          pc.BaseSHAs,
 	}
 }
 
@@ -160,19 +189,42 @@ func handlePush(pc plugins.Agent, pe scm.PushHook) error {
 	return handlePE(getClient(pc), pe)
 }
 
+func handleDeployment(pc plugins.Agent, dh scm.DeployHook) error {
+	return handleDE(getClient(pc), dh)
+}
+
 // TrustedUser returns true if user is trusted in repo.
 //
-// Trusted users are either repo collaborators, org members or trusted org members.
-// Whether repo collaborators and/or a second org is trusted is configured by trigger.
+// Which users are trusted depends on the trust policy of the trigger: repo collaborators and
+// org or trusted org members, only org or trusted org members, only trusted team members, or anyone.
+// Memberships are cached for a few minutes to avoid querying the SCM provider for every event.
 func TrustedUser(spc trustedUserClient, trigger *plugins.Trigger, user, org, repo string) (bool, error) {
 	botUser, err := spc.BotName()
 	if err == nil && user == botUser {
 		logrus.Infof("User %q is the bot user", user)
 		return true, nil
 	}
+
+	policy := trigger.Policy()
+	switch policy {
+	case plugins.TrustAnyone:
+		return true, nil
+	case plugins.TrustTeam:
+		teamOrg := org
+		if trigger.TrustedOrg != "" {
+			teamOrg = trigger.TrustedOrg
+		}
+		member, err := memberships.isTeamMember(spc, teamOrg, trigger.TrustedTeam, user)
+		if err != nil {
+			return false, fmt.Errorf("error in IsTeamMember(%s/%s): %v", teamOrg, trigger.TrustedTeam, err)
+		}
+		logrus.Infof("User %q is a member of the trusted team %s/%s - %t", user, teamOrg, trigger.TrustedTeam, member)
+		return member, nil
+	}
+
 	// First check if user is a collaborator, assuming this is allowed
-	if !trigger.OnlyOrgMembers {
-		if ok, err := spc.IsCollaborator(org, repo, user); err != nil {
+	if policy == plugins.TrustCollaborators {
+		if ok, err := memberships.isCollaborator(spc, org, repo, user); err != nil {
 			return false, fmt.Errorf("error in IsCollaborator: %v", err)
 		} else if ok {
 			logrus.Infof("User %q is a collaborator of org %q", user, org)
@@ -183,7 +235,7 @@ func TrustedUser(spc trustedUserClient, trigger *plugins.Trigger, user, org, rep
 	// TODO(fejta): consider dropping support for org checks in the future.
 
 	// Next see if the user is an org member
-	if member, err := spc.IsMember(org, user); err != nil {
+	if member, err := memberships.isMember(spc, org, user); err != nil {
 		return false, fmt.Errorf("error in IsMember(%s): %v", org, err)
 	} else if member {
 		logrus.Infof("User %q is a member of org %q", user, org)
@@ -196,7 +248,7 @@ func TrustedUser(spc trustedUserClient, trigger *plugins.Trigger, user, org, rep
 	}
 
 	// Check the second trusted org.
-	member, err := spc.IsMember(trigger.TrustedOrg, user)
+	member, err := memberships.isMember(spc, trigger.TrustedOrg, user)
 	if err != nil {
 		return false, fmt.Errorf("error in IsMember(%s): %v", trigger.TrustedOrg, err)
 	}
@@ -204,6 +256,26 @@ func TrustedUser(spc trustedUserClient, trigger *plugins.Trigger, user, org, rep
 	return member, nil
 }
 
+const (
+	// AuthorizedByTrustedAuthor is the reason of the runs of the PRs of trusted authors
+	AuthorizedByTrustedAuthor = "trusted-author"
+	// AuthorizedByOkToTest is the reason of the runs of PRs marked as trusted with /ok-to-test
+	AuthorizedByOkToTest = "ok-to-test"
+	// AuthorizedByTrustedCommenter is the reason of the runs requested with /test or /retest by trusted users
+	AuthorizedByTrustedCommenter = "trusted-commenter"
+	// AuthorizedByLGTM is the reason of the single run of untrusted PRs when they get the lgtm label
+	AuthorizedByLGTM = "lgtm"
+)
+
+// Authorization records who authorized running the presubmits of a PR and why.
+// It is added to the annotations of the LighthouseJobs and to the security audit log.
+type Authorization struct {
+	// User is the login of the user who authorized the run
+	User string
+	// Reason is why the user could authorize the run, e.g. AuthorizedByOkToTest
+	Reason string
+}
+
 func skippedStatusFor(context string) *scm.StatusInput {
 	return &scm.StatusInput{
 		State: scm.StateSuccess,
@@ -212,6 +284,34 @@ func skippedStatusFor(context string) *scm.StatusInput {
 	}
 }
 
+// monorepoStatusFor summarizes the groups of a monorepo tested and skipped by a PR
+func monorepoStatusFor(monorepo *job.Monorepo, changed, unchanged []string) *scm.StatusInput {
+	var parts []string
+	if len(changed) > 0 {
+		parts = append(parts, fmt.Sprintf("Testing %s.", strings.Join(changed, ", ")))
+	}
+	if len(unchanged) > 0 {
+		parts = append(parts, fmt.Sprintf("Skipped %s.", strings.Join(unchanged, ", ")))
+	}
+	desc := strings.Join(parts, " ")
+	if len(desc) > maxStatusDescriptionLength {
+		desc = fmt.Sprintf("Testing %d groups, skipped %d groups.", len(changed), len(unchanged))
+	}
+	return &scm.StatusInput{
+		State: scm.StateSuccess,
+		Label: monorepoContext(monorepo),
+		Desc:  desc,
+	}
+}
+
+// monorepoContext returns the context of the status summarizing the groups of a monorepo
+func monorepoContext(monorepo *job.Monorepo) string {
+	if monorepo.Context == "" {
+		return job.DefaultMonorepoContext
+	}
+	return monorepo.Context
+}
+
 func failedStatusForMetapipelineCreation(context string, err error) *scm.StatusInput {
 	return &scm.StatusInput{
 		State: scm.StateError,
@@ -222,12 +322,12 @@ func failedStatusForMetapipelineCreation(context string, err error) *scm.StatusI
 
 // RunAndSkipJobs executes the config.Presubmits that are requested and posts skipped statuses
 // for the reporting jobs that are skipped
-func RunAndSkipJobs(c Client, pr *scm.PullRequest, requestedJobs []job.Presubmit, skippedJobs []job.Presubmit, eventGUID string, elideSkippedContexts bool) error {
+func RunAndSkipJobs(c Client, pr *scm.PullRequest, requestedJobs []job.Presubmit, skippedJobs []job.Presubmit, eventGUID string, auth Authorization, elideSkippedContexts bool) error {
 	if err := validateContextOverlap(requestedJobs, skippedJobs); err != nil {
 		c.Logger.WithError(err).Warn("Could not run or skip requested jobs, overlapping contexts.")
 		return err
 	}
-	runErr := runRequested(c, pr, requestedJobs, eventGUID)
+	runErr := runRequested(c, pr, requestedJobs, eventGUID, auth)
 	var skipErr error
 	if !elideSkippedContexts {
 		skipErr = skipRequested(c, pr, skippedJobs)
@@ -254,16 +354,43 @@ func validateContextOverlap(toRun, toSkip []job.Presubmit) error {
 }
 
 // runRequested executes the config.Presubmits that are requested
-func runRequested(c Client, pr *scm.PullRequest, requestedJobs []job.Presubmit, eventGUID string) error {
-	baseSHA, err := c.SCMProviderClient.GetRef(pr.Base.Repo.Namespace, pr.Base.Repo.Name, "heads/"+pr.Base.Ref)
+func runRequested(c Client, pr *scm.PullRequest, requestedJobs []job.Presubmit, eventGUID string, auth Authorization) error {
+	baseSHA, err := c.BaseSHAs.Resolve(c.SCMProviderClient, pr.Base.Repo.Namespace, pr.Base.Repo.Name, pr.Base.Ref)
 	if err != nil {
 		return err
 	}
 
+	authAnnotations := map[string]string{
+		job.AuthorizedByAnnotation:  auth.User,
+		job.AuthorizationAnnotation: auth.Reason,
+	}
+	changes := job.NewGitHubDeferredChangedFilesProvider(c.SCMProviderClient, pr.Base.Repo.Namespace, pr.Base.Repo.Name, pr.Number)
 	var errors []error
 	for _, job := range requestedJobs {
 		c.Logger.Infof("Starting %s build.", job.Name)
 		pj := jobutil.NewPresubmit(pr, baseSHA, job, eventGUID, c.SCMProviderClient.PRRefFmt())
+		if job.UsesChangedFiles() {
+			changedFiles, err := changes()
+			if err != nil {
+				c.Logger.WithError(err).Errorf("Failed to list the changes of the pull request for %s.", job.Name)
+				errors = append(errors, err)
+				continue
+			}
+			jobutil.SetChangedFiles(&pj.Spec, changedFiles)
+		}
+		for k, v := range authAnnotations {
+			pj.Annotations[k] = v
+		}
+		c.Logger.WithFields(logrus.Fields{
+			"audit":         "presubmit-authorization",
+			"job":           job.Name,
+			"pr":            fmt.Sprintf("%s#%d", pr.Base.Repo.FullName, pr.Number),
+			"author":        pr.Author.Login,
+			"fork":          pr.Fork != "" && pr.Fork != pr.Base.Repo.FullName,
+			"sha":           pr.Head.Sha,
+			"authorized_by": auth.User,
+			"authorization": auth.Reason,
+		}).Info("Presubmit authorized.")
 		c.Logger.WithFields(jobutil.LighthouseJobFields(&pj)).Info("Creating a new LighthouseJob.")
 		if _, err := c.LauncherClient.Launch(&pj); err != nil {
 			c.Logger.WithError(err).Error("Failed to create LighthouseJob.")
@@ -280,7 +407,7 @@ func runRequested(c Client, pr *scm.PullRequest, requestedJobs []job.Presubmit,
 func skipRequested(c Client, pr *scm.PullRequest, skippedJobs []job.Presubmit) error {
 	var errors []error
 	for _, job := range skippedJobs {
-		if job.SkipReport {
+		if !job.ReportsContext() {
 			continue
 		}
 		c.Logger.Infof("Skipping %s build.", job.Name)
@@ -17,6 +17,7 @@ limitations under the License.
 package trigger
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
@@ -338,7 +339,7 @@ func TestRunAndSkipJobs(t *testing.T) {
 				Logger:            logrus.WithField("testcase", testCase.name),
 			}
 
-			err := RunAndSkipJobs(client, pr, testCase.requestedJobs, testCase.skippedJobs, "event-guid", testCase.elideSkippedContexts)
+			err := RunAndSkipJobs(client, pr, testCase.requestedJobs, testCase.skippedJobs, "event-guid", Authorization{User: "alice", Reason: AuthorizedByTrustedAuthor}, testCase.elideSkippedContexts)
 			if err == nil && testCase.expectedErr {
 				t.Errorf("%s: expected an error but got none", testCase.name)
 			}
@@ -352,8 +353,11 @@ func TestRunAndSkipJobs(t *testing.T) {
 
 			observedCreatedLighthouseJobs := sets.NewString()
 			existingLighthouseJobs := fakeLauncher.Pipelines
-			for _, job := range existingLighthouseJobs {
-				observedCreatedLighthouseJobs.Insert(job.Spec.Job)
+			for _, lhjob := range existingLighthouseJobs {
+				observedCreatedLighthouseJobs.Insert(lhjob.Spec.Job)
+				if lhjob.Annotations[job.AuthorizedByAnnotation] != "alice" || lhjob.Annotations[job.AuthorizationAnnotation] != AuthorizedByTrustedAuthor {
+					t.Errorf("%s: expected LighthouseJob %s to be annotated with its authorization, got %v", testCase.name, lhjob.Spec.Job, lhjob.Annotations)
+				}
 			}
 
 			if missing := testCase.expectedJobs.Difference(observedCreatedLighthouseJobs); missing.Len() > 0 {
@@ -372,6 +376,7 @@ func TestRunRequested(t *testing.T) {
 
 		requestedJobs   []job.Presubmit
 		jobCreationErrs sets.String // job names which fail creation
+		changesErr      bool        // whether listing the changes of the pull request fails
 
 		expectedJobs sets.String // by name
 		expectedErr  bool
@@ -411,6 +416,24 @@ func TestRunRequested(t *testing.T) {
 			expectedJobs:    sets.NewString("second"),
 			expectedErr:     true,
 		},
+		{
+			name: "failure to list the changed files bubbles up but doesn't stop the other jobs from starting",
+			requestedJobs: []job.Presubmit{{
+				Base: job.Base{
+					Name:              "first",
+					PipelineRunParams: []job.PipelineRunParam{{Name: "FILES", ValueTemplate: "{{ join \" \" .ChangedFiles }}"}},
+				},
+				Reporter: job.Reporter{Context: "first-context"},
+			}, {
+				Base: job.Base{
+					Name: "second",
+				},
+				Reporter: job.Reporter{Context: "second-context"},
+			}},
+			changesErr:   true,
+			expectedJobs: sets.NewString("second"),
+			expectedErr:  true,
+		},
 	}
 
 	pr := &scm.PullRequest{
@@ -437,8 +460,11 @@ func TestRunRequested(t *testing.T) {
 				LauncherClient:    fakeLauncher,
 				Logger:            logrus.WithField("testcase", testCase.name),
 			}
+			if testCase.changesErr {
+				client.SCMProviderClient = &failingChangesClient{SCMClient: &fakeSCMClient}
+			}
 
-			err := runRequested(client, pr, testCase.requestedJobs, "event-guid")
+			err := runRequested(client, pr, testCase.requestedJobs, "event-guid", Authorization{User: "alice", Reason: AuthorizedByTrustedAuthor})
 			if err == nil && testCase.expectedErr {
 				t.Errorf("%s: expected an error but got none", testCase.name)
 			}
@@ -462,6 +488,14 @@ func TestRunRequested(t *testing.T) {
 	}
 }
 
+type failingChangesClient struct {
+	*fake2.SCMClient
+}
+
+func (c *failingChangesClient) GetPullRequestChanges(org, repo string, number int) ([]*scm.Change, error) {
+	return nil, errors.New("injected error")
+}
+
 func TestValidateContextOverlap(t *testing.T) {
 	var testCases = []struct {
 		name          string
@@ -515,3 +549,55 @@ func TestValidateContextOverlap(t *testing.T) {
 		})
 	}
 }
+
+func TestMonorepoStatusFor(t *testing.T) {
+	var longNames []string
+	for i := 0; i < 20; i++ {
+		longNames = append(longNames, "some-rather-long-service-name")
+	}
+	var testCases = []struct {
+		name         string
+		monorepo     job.Monorepo
+		changed      []string
+		unchanged    []string
+		expectedDesc string
+	}{
+		{
+			name:         "changed and skipped groups",
+			monorepo:     job.Monorepo{Context: "groups"},
+			changed:      []string{"foo"},
+			unchanged:    []string{"bar", "baz"},
+			expectedDesc: "Testing foo. Skipped bar, baz.",
+		},
+		{
+			name:         "no group changed",
+			unchanged:    []string{"bar"},
+			expectedDesc: "Skipped bar.",
+		},
+		{
+			name:         "too many groups to list",
+			changed:      longNames[:10],
+			unchanged:    longNames[10:],
+			expectedDesc: "Testing 10 groups, skipped 10 groups.",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			status := monorepoStatusFor(&testCase.monorepo, testCase.changed, testCase.unchanged)
+			expectedContext := testCase.monorepo.Context
+			if expectedContext == "" {
+				expectedContext = job.DefaultMonorepoContext
+			}
+			if status.Label != expectedContext {
+				t.Errorf("%s: expected context %s but got %s", testCase.name, expectedContext, status.Label)
+			}
+			if status.State != scm.StateSuccess {
+				t.Errorf("%s: expected a success status but got %s", testCase.name, status.State)
+			}
+			if status.Desc != testCase.expectedDesc {
+				t.Errorf("%s: expected description %q but got %q", testCase.name, testCase.expectedDesc, status.Desc)
+			}
+		})
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
 	config2 "github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/jenkins-x/lighthouse/pkg/util"
@@ -84,11 +85,11 @@ type scmProviderClient interface {
 }
 
 type commentPruner interface {
-	PruneComments(pr bool, shouldPrune func(*scm.Comment) bool)
+	PruneFunc(isObsolete func(*scm.Comment) bool) error
 }
 
 func handlePullRequest(pc plugins.Agent, pre scm.PullRequestHook) error {
-	cp, err := pc.CommentPruner()
+	cp, err := pc.BotCommentClient()
 	if err != nil {
 		return err
 	}
@@ -397,9 +398,11 @@ func handle(spc scmProviderClient, kc corev1.ConfigMapsGetter, cp commentPruner,
 		}
 	}
 
-	cp.PruneComments(true, func(comment *scm.Comment) bool {
-		return strings.Contains(comment.Body, configUpdaterMsgPruneMatch)
-	})
+	if err := cp.PruneFunc(func(comment *scm.Comment) bool {
+		return botcomment.HasMarker(comment.Body, pluginName) || strings.Contains(comment.Body, configUpdaterMsgPruneMatch)
+	}); err != nil {
+		log.WithError(err).Warn("Cannot prune the previous validation comments")
+	}
 
 	var statusInput *scm.StatusInput
 	message := ""
@@ -410,7 +413,7 @@ func handle(spc scmProviderClient, kc corev1.ConfigMapsGetter, cp commentPruner,
 			Label: configUpdaterContextName,
 			Desc:  configUpdaterContextMsgFailed,
 		}
-		message = fmt.Sprintf("%s\n\n%s", configUpdaterMsgPruneMatch, strings.Join(validationErrors, "\n\n---\n\n"))
+		message = fmt.Sprintf("%s\n\n%s\n%s", configUpdaterMsgPruneMatch, strings.Join(validationErrors, "\n\n---\n\n"), botcomment.Marker(pluginName))
 	} else {
 		statusInput = &scm.StatusInput{
 			State: scm.StateSuccess,
@@ -25,7 +25,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jenkins-x/go-scm/scm"
-	"github.com/jenkins-x/lighthouse/pkg/commentpruner"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	fake2 "github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
 	"github.com/sirupsen/logrus"
@@ -1286,7 +1286,7 @@ func TestUpdateConfig(t *testing.T) {
 				}
 			}
 			m.SetDefaults()
-			cp := commentpruner.NewEventClient(fspc, logrus.WithField("client", "commentpruner"), basicPR.Repository().Namespace, basicPR.Repository().Name, basicPR.Number)
+			cp := botcomment.NewClient(fspc, basicPR.Repository().Namespace, basicPR.Repository().Name, basicPR.Number, true)
 
 			if err := handle(fspc, fkc.CoreV1(), cp, defaultNamespace, log, event, *m); err != nil {
 				t.Fatalf("%s: unexpected error handling: %s", tc.name, err)
@@ -18,36 +18,39 @@ limitations under the License.
 package welcome
 
 import (
-	"bytes"
 	"fmt"
-	"html/template"
 	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/sirupsen/logrus"
 
+	"github.com/jenkins-x/lighthouse/pkg/labels"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 const (
 	pluginName            = "welcome"
-	defaultWelcomeMessage = "Welcome @{{.AuthorLogin}}! It looks like this is your first PR to {{.Org}}/{{.Repo}} 🎉"
+	defaultWelcomeMessage = "Welcome @{{.AuthorLogin}}! It looks like this is your first PR to {{.Org}}/{{.Repo}} 🎉" +
+		"{{if .ContributingURL}} Please take a look at our [contribution guide]({{.ContributingURL}}).{{end}}"
 )
 
 // PRInfo contains info used provided to the welcome message template
 type PRInfo struct {
-	Org         string
-	Repo        string
-	AuthorLogin string
-	AuthorName  string
+	Org             string
+	Repo            string
+	AuthorLogin     string
+	AuthorName      string
+	ContributingURL string
 }
 
 func init() {
+	plugins.RegisterCommentTemplate(pluginName, defaultWelcomeMessage)
 	plugins.RegisterPlugin(
 		pluginName,
 		plugins.Plugin{
-			Description:        "The welcome plugin posts a welcoming message when it detects a user's first contribution to a repo.",
+			Description:        "The welcome plugin posts a welcoming message and adds the '" + labels.FirstTimeContributor + "' label when it detects a user's first contribution to a repo or org.",
 			ConfigHelpProvider: configHelp,
 			PullRequestHandler: handlePullRequest,
 		},
@@ -75,8 +78,10 @@ func configHelp(config *plugins.Configuration, enabledRepos []string) (map[strin
 }
 
 type scmProviderClient interface {
+	AddLabel(owner, repo string, number int, label string, pr bool) error
 	CreateComment(owner, repo string, number int, pr bool, comment string) error
 	FindPullRequestsByAuthor(owner, repo string, author string) ([]*scm.PullRequest, error)
+	Search(opts scm.SearchOptions) ([]*scm.SearchIssue, *scmprovider.RateLimits, error)
 }
 
 type client struct {
@@ -92,47 +97,80 @@ func getClient(pc plugins.Agent) client {
 }
 
 func handlePullRequest(pc plugins.Agent, pre scm.PullRequestHook) error {
-	return handlePR(getClient(pc), pre, welcomeMessageForRepo(pc.PluginConfig, pre.Repo.Namespace, pre.Repo.Name))
+	org := pre.Repo.Namespace
+	repo := pre.Repo.Name
+	return handlePR(getClient(pc), pre, optionsForRepo(pc.PluginConfig, org, repo), welcomeMessageForRepo(pc.PluginConfig, org, repo))
 }
 
-func handlePR(c client, pre scm.PullRequestHook, welcomeTemplate string) error {
+func handlePR(c client, pre scm.PullRequestHook, opts *plugins.Welcome, welcomeTemplate string) error {
 	// Only consider newly opened PRs
 	if pre.Action != scm.ActionOpen {
 		return nil
 	}
 
-	// search for PRs from the author in this repo
 	org := pre.PullRequest.Base.Repo.Namespace
 	repo := pre.PullRequest.Base.Repo.Name
 	user := pre.PullRequest.Author.Login
-	issues, err := c.SCMProviderClient.FindPullRequestsByAuthor(org, repo, user)
+	first, err := isFirstPR(c, pre, opts.OrgWide)
 	if err != nil {
 		return err
 	}
+	if !first {
+		return nil
+	}
 
-	// if there are no results, this is the first! post the welcome comment
-	if len(issues) == 0 || len(issues) == 1 && issues[0].Number == pre.PullRequest.Number {
-		// load the template, and run it over the PR info
-		parsedTemplate, err := template.New("welcome").Parse(welcomeTemplate)
-		if err != nil {
-			return err
-		}
-		var msgBuffer bytes.Buffer
-		err = parsedTemplate.Execute(&msgBuffer, PRInfo{
-			Org:         org,
-			Repo:        repo,
-			AuthorLogin: user,
-			AuthorName:  pre.PullRequest.Author.Name,
+	// render the template over the PR info
+	msg, err := plugins.RenderCommentTemplate(pluginName, welcomeTemplate, PRInfo{
+		Org:             org,
+		Repo:            repo,
+		AuthorLogin:     user,
+		AuthorName:      pre.PullRequest.Author.Name,
+		ContributingURL: opts.ContributingURL,
+	})
+	if err != nil {
+		return err
+	}
+
+	// actually post the comment
+	if err := c.SCMProviderClient.CreateComment(org, repo, pre.PullRequest.Number, true, msg); err != nil {
+		return err
+	}
+	if err := c.SCMProviderClient.AddLabel(org, repo, pre.PullRequest.Number, labels.FirstTimeContributor, true); err != nil {
+		c.Logger.WithError(err).Errorf("Failed to add the %s label", labels.FirstTimeContributor)
+	}
+	return nil
+}
+
+// isFirstPR returns true if the pull request is the first one of its author to the repository, or to any repository
+// of the org if orgWide is enabled
+func isFirstPR(c client, pre scm.PullRequestHook, orgWide bool) (bool, error) {
+	org := pre.PullRequest.Base.Repo.Namespace
+	repo := pre.PullRequest.Base.Repo.Name
+	user := pre.PullRequest.Author.Login
+
+	if orgWide {
+		// search for PRs from the author in the whole org
+		results, _, err := c.SCMProviderClient.Search(scm.SearchOptions{
+			Query: fmt.Sprintf("is:pr org:%s author:%s", org, user),
 		})
 		if err != nil {
-			return err
+			return false, err
 		}
-
-		// actually post the comment
-		return c.SCMProviderClient.CreateComment(org, repo, pre.PullRequest.Number, true, msgBuffer.String())
+		for _, r := range results {
+			if r.Number != pre.PullRequest.Number || r.Repository.Name != repo {
+				return false, nil
+			}
+		}
+		return true, nil
 	}
 
-	return nil
+	// search for PRs from the author in this repo
+	issues, err := c.SCMProviderClient.FindPullRequestsByAuthor(org, repo, user)
+	if err != nil {
+		return false, err
+	}
+	// if there are no results, this is the first!
+	return len(issues) == 0 || len(issues) == 1 && issues[0].Number == pre.PullRequest.Number, nil
 }
 
 func welcomeMessageForRepo(config *plugins.Configuration, org, repo string) string {
@@ -140,7 +178,7 @@ func welcomeMessageForRepo(config *plugins.Configuration, org, repo string) stri
 	if opts.MessageTemplate != "" {
 		return opts.MessageTemplate
 	}
-	return defaultWelcomeMessage
+	return config.CommentTemplate(pluginName, org, repo)
 }
 
 // optionsForRepo gets the plugins.Welcome struct that is applicable to the indicated repo.
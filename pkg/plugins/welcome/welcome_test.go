@@ -27,7 +27,9 @@ import (
 
 	"sigs.k8s.io/yaml"
 
+	"github.com/jenkins-x/lighthouse/pkg/labels"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -37,25 +39,34 @@ const (
 
 type fakeClient struct {
 	commentsAdded map[int][]string
+	labelsAdded   map[int][]string
 	prs           map[string]sets.Int
 }
 
 func newFakeClient() *fakeClient {
 	return &fakeClient{
 		commentsAdded: make(map[int][]string),
+		labelsAdded:   make(map[int][]string),
 		prs:           make(map[string]sets.Int),
 	}
 }
 
+// AddLabel adds and tracks a label in the client
+func (fc *fakeClient) AddLabel(owner, repo string, number int, label string, pr bool) error {
+	fc.labelsAdded[number] = append(fc.labelsAdded[number], label)
+	return nil
+}
+
 // CreateComment adds and tracks a comment in the client
 func (fc *fakeClient) CreateComment(owner, repo string, number int, pr bool, comment string) error {
 	fc.commentsAdded[number] = append(fc.commentsAdded[number], comment)
 	return nil
 }
 
-// ClearComments removes all comments in the client
+// ClearComments removes all comments and labels in the client
 func (fc *fakeClient) ClearComments() {
 	fc.commentsAdded = map[int][]string{}
+	fc.labelsAdded = map[int][]string{}
 }
 
 // NumComments counts the number of tracked comments
@@ -96,6 +107,34 @@ func (fc *fakeClient) FindPullRequestsByAuthor(owner, repo string, author string
 	return issues, nil
 }
 
+// Search looks up the PRs of the author in all the repositories of the org
+func (fc *fakeClient) Search(opts scm.SearchOptions) ([]*scm.SearchIssue, *scmprovider.RateLimits, error) {
+	var org, author string
+	for _, term := range strings.Fields(opts.Query) {
+		if strings.HasPrefix(term, "org:") {
+			org = strings.TrimPrefix(term, "org:")
+		}
+		if strings.HasPrefix(term, "author:") {
+			author = strings.TrimPrefix(term, "author:")
+		}
+	}
+
+	var results []*scm.SearchIssue
+	for key, numbers := range fc.prs {
+		parts := strings.Split(key, ",")
+		if parts[0] != org || parts[2] != author {
+			continue
+		}
+		for _, number := range numbers.List() {
+			results = append(results, &scm.SearchIssue{
+				Issue:      scm.Issue{Number: number, PullRequest: true},
+				Repository: scm.Repository{Namespace: parts[0], Name: parts[1]},
+			})
+		}
+	}
+	return results, &scmprovider.RateLimits{}, nil
+}
+
 func makeFakePullRequestEvent(owner, repo, author string, number int, action scm.Action) scm.PullRequestHook {
 	return scm.PullRequestHook{
 		Action: action,
@@ -130,6 +169,7 @@ func TestHandlePR(t *testing.T) {
 		prNumber      int
 		prAction      scm.Action
 		addPR         bool
+		orgWide       bool
 		expectComment bool
 	}{
 		{
@@ -178,6 +218,36 @@ func TestHandlePR(t *testing.T) {
 			prNumber:      50,
 			expectComment: false,
 		},
+		{
+			name:          "existing contributorA, first PR to another repo",
+			repoOwner:     "kubernetes",
+			repoName:      "kubernetes",
+			author:        "contributorA",
+			prAction:      scm.ActionOpen,
+			prNumber:      60,
+			expectComment: true,
+		},
+		{
+			name:          "existing contributorA, first PR to another repo of the org",
+			repoOwner:     "kubernetes",
+			repoName:      "kubernetes",
+			author:        "contributorA",
+			prAction:      scm.ActionOpen,
+			prNumber:      60,
+			orgWide:       true,
+			expectComment: false,
+		},
+		{
+			name:          "new contributor to the org",
+			repoOwner:     "kubernetes",
+			repoName:      "kubernetes",
+			author:        "newOrgContributor",
+			prAction:      scm.ActionOpen,
+			prNumber:      70,
+			addPR:         true,
+			orgWide:       true,
+			expectComment: true,
+		},
 	}
 
 	c := client{
@@ -195,7 +265,7 @@ func TestHandlePR(t *testing.T) {
 		}
 
 		// try handling it
-		if err := handlePR(c, event, testWelcomeTemplate); err != nil {
+		if err := handlePR(c, event, &plugins.Welcome{OrgWide: tc.orgWide}, testWelcomeTemplate); err != nil {
 			t.Fatalf("did not expect error handling PR for case '%s': %v", tc.name, err)
 		}
 
@@ -209,6 +279,9 @@ func TestHandlePR(t *testing.T) {
 		} else if numComments > 0 && !tc.expectComment {
 			t.Fatalf("did not expect comments for case '%s' and got %d comments", tc.name, numComments)
 		}
+		if tc.expectComment && !sets.NewString(fc.labelsAdded[tc.prNumber]...).Has(labels.FirstTimeContributor) {
+			t.Fatalf("expected the %s label to be added for case '%s'", labels.FirstTimeContributor, tc.name)
+		}
 	}
 }
 
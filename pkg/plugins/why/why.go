@@ -0,0 +1,272 @@
+// Package why contains a plugin which explains why Keeper does not merge a pull request yet, by listing the merge
+// requirements of the keeper queries and contexts the pull request does not meet.
+package why
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/keeper"
+	lighthousekeeper "github.com/jenkins-x/lighthouse/pkg/keeper"
+	"github.com/jenkins-x/lighthouse/pkg/keeper/blockers"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	pluginName = "why"
+
+	whyHeader = "**Merge requirements**"
+)
+
+var (
+	plugin = plugins.Plugin{
+		Description: "The why plugin explains why a pull request is not merged yet by listing the merge requirements of keeper it does not meet.",
+		Commands: []plugins.Command{{
+			Name:        "why",
+			Description: "Lists the merge requirements the pull request does not meet: labels, approvals, failing or missing contexts, review requirements, merge conflicts, freezes and blocking issues. Commenting it again refreshes the list.",
+			Action: plugins.
+				Invoke(func(match plugins.CommandMatch, pc plugins.Agent, e scmprovider.GenericCommentEvent) error {
+					return handle(pc.SCMProviderClient, pc.Logger, pc.Config, &e)
+				}).
+				When(plugins.Action(scm.ActionCreate), plugins.IsPR()),
+		}},
+	}
+)
+
+func init() {
+	plugins.RegisterPlugin(pluginName, plugin)
+}
+
+type scmProviderClient interface {
+	CreateComment(owner, repo string, number int, pr bool, comment string) error
+	EditComment(owner, repo string, number int, id int, comment string, pr bool) error
+	DeleteComment(owner, repo string, number, id int, pr bool) error
+	ListIssueComments(org, repo string, number int) ([]*scm.Comment, error)
+	ListPullRequestComments(org, repo string, number int) ([]*scm.Comment, error)
+	GetPullRequest(org, repo string, number int) (*scm.PullRequest, error)
+	GetIssueLabels(org, repo string, number int, pr bool) ([]*scm.Label, error)
+	GetCombinedStatus(org, repo, ref string) (*scm.CombinedStatus, error)
+	ListReviews(org, repo string, number int) ([]*scm.Review, error)
+	HasUnresolvedDiscussions(org, repo string, number int) (bool, error)
+	Search(opts scm.SearchOptions) ([]*scm.SearchIssue, *scmprovider.RateLimits, error)
+	QuoteAuthorForComment(string) string
+	BotName() (string, error)
+}
+
+func handle(spc scmProviderClient, log *logrus.Entry, cfg *config.Config, e *scmprovider.GenericCommentEvent) error {
+	org := e.Repo.Namespace
+	repo := e.Repo.Name
+
+	pr, err := spc.GetPullRequest(org, repo, e.Number)
+	if err != nil {
+		return fmt.Errorf("failed to get pull request %s/%s#%d: %v", org, repo, e.Number, err)
+	}
+	reasons, err := blockingReasons(spc, cfg, org, repo, pr)
+	if err != nil {
+		return err
+	}
+
+	log.WithField("reasons", len(reasons)).Info("Explaining the merge requirements of the pull request")
+	// the previous explanation is refreshed rather than adding another one
+	return botcomment.NewClient(spc, org, repo, e.Number, true).Upsert(pluginName, formatComment(spc.QuoteAuthorForComment(e.Author.Login), reasons))
+}
+
+// blockingReasons lists the merge requirements the pull request does not meet, comparing it to the keeper query
+// of its repository it is the closest to meet
+func blockingReasons(spc scmProviderClient, cfg *config.Config, org, repo string, pr *scm.PullRequest) ([]string, error) {
+	branch := pr.Base.Ref
+	queries := cfg.Keeper.Queries.QueryMap().ForRepo(org, repo)
+	if len(queries) == 0 {
+		return []string{fmt.Sprintf("Keeper does not merge the pull requests of %s/%s as no keeper query matches the repository.", org, repo)}, nil
+	}
+
+	issueLabels, err := spc.GetIssueLabels(org, repo, pr.Number, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the labels of %s/%s#%d: %v", org, repo, pr.Number, err)
+	}
+	labels := sets.NewString()
+	for _, l := range issueLabels {
+		labels.Insert(l.Name)
+	}
+	approved := false
+	for _, q := range queries {
+		if q.ReviewApprovedRequired {
+			reviews, err := spc.ListReviews(org, repo, pr.Number)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list the reviews of %s/%s#%d: %v", org, repo, pr.Number, err)
+			}
+			approvers, changesRequestedBy := scmprovider.ReviewersByState(reviews)
+			// like the review:approved search qualifier, a request for changes outweighs the approvals
+			approved = len(approvers) > 0 && len(changesRequestedBy) == 0
+			break
+		}
+	}
+
+	var reasons []string
+	var best []string
+	bestBranch := false
+	for i, q := range queries {
+		branchAllowed, missing := queryRequirements(&q, branch, pr.Milestone.Title, labels, approved)
+		// prefer the queries for the branch of the pull request, then those it is the closest to meet
+		if i == 0 || (branchAllowed && !bestBranch) || (branchAllowed == bestBranch && len(missing) < len(best)) {
+			best = missing
+			bestBranch = branchAllowed
+		}
+	}
+	if !bestBranch {
+		return []string{fmt.Sprintf("Merging to branch `%s` is forbidden.", branch)}, nil
+	}
+	reasons = append(reasons, best...)
+
+	cc, err := cfg.GetKeeperContextPolicy(org, repo, branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the context policy of %s/%s on branch %s: %v", org, repo, branch, err)
+	}
+	status, err := spc.GetCombinedStatus(org, repo, pr.Head.Sha)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the statuses of %s/%s@%s: %v", org, repo, pr.Head.Sha, err)
+	}
+	reasons = append(reasons, contextRequirements(cc, status)...)
+
+	unmetReviews, err := reviewRequirements(spc, cfg.Keeper.ReviewRequirements(org, repo), org, repo, pr.Number)
+	if err != nil {
+		return nil, err
+	}
+	reasons = append(reasons, unmetReviews...)
+
+	if pr.MergeableState == scm.MergeableStateConflicting {
+		reasons = append(reasons, fmt.Sprintf("Has merge conflicts with the `%s` branch and needs a rebase.", branch))
+	}
+	if freeze := cfg.FreezeFor(org, repo, branch, time.Now()); freeze != nil {
+		reasons = append(reasons, freeze.Description())
+	}
+	if label := cfg.Keeper.BlockerLabel; label != "" {
+		blocking, err := blockingIssues(spc, org, repo, branch, label)
+		if err != nil {
+			return nil, err
+		}
+		reasons = append(reasons, blocking...)
+	}
+	return reasons, nil
+}
+
+// queryRequirements returns whether the query allows the branch, along with the label, milestone and review
+// requirements of the query the pull request does not meet
+func queryRequirements(q *keeper.Query, branch, milestone string, labels sets.String, approved bool) (bool, []string) {
+	branchAllowed := len(q.IncludedBranches) == 0 || sets.NewString(q.IncludedBranches...).Has(branch)
+	if sets.NewString(q.ExcludedBranches...).Has(branch) {
+		branchAllowed = false
+	}
+
+	var missing []string
+	for _, l := range sets.NewString(q.Labels...).Difference(labels).List() {
+		missing = append(missing, fmt.Sprintf("Needs the `%s` label.", l))
+	}
+	for _, l := range sets.NewString(q.MissingLabels...).Intersection(labels).List() {
+		missing = append(missing, fmt.Sprintf("Must not have the `%s` label.", l))
+	}
+	if q.Milestone != "" && milestone != q.Milestone {
+		missing = append(missing, fmt.Sprintf("Must be in the `%s` milestone.", q.Milestone))
+	}
+	if q.ReviewApprovedRequired && !approved {
+		missing = append(missing, "Needs an approving review.")
+	}
+	return branchAllowed, missing
+}
+
+// contextRequirements describes the required contexts which have not succeeded or not reported a status yet
+func contextRequirements(cc *keeper.ContextPolicy, status *scm.CombinedStatus) []string {
+	ignored := sets.NewString("keeper", "tide", lighthousekeeper.GetStatusContextLabel())
+
+	var reasons []string
+	var contexts []string
+	if status != nil {
+		statuses := append([]*scm.Status{}, status.Statuses...)
+		sort.Slice(statuses, func(i, j int) bool {
+			return statuses[i].Label < statuses[j].Label
+		})
+		for _, s := range statuses {
+			contexts = append(contexts, s.Label)
+			if ignored.Has(s.Label) || cc.IsOptional(s.Label) || s.State == scm.StateSuccess {
+				continue
+			}
+			reasons = append(reasons, fmt.Sprintf("Context `%s` has not succeeded, it is %s.", s.Label, s.State.String()))
+		}
+	}
+	missing := cc.MissingRequiredContexts(contexts)
+	sort.Strings(missing)
+	for _, c := range missing {
+		reasons = append(reasons, fmt.Sprintf("Context `%s` is required but has not reported a status.", c))
+	}
+	return reasons
+}
+
+// reviewRequirements describes the review requirements of the repository the pull request does not meet
+func reviewRequirements(spc scmProviderClient, reqs keeper.ReviewRequirements, org, repo string, number int) ([]string, error) {
+	var approvers, changesRequestedBy []string
+	if reqs.NeedsReviews() {
+		reviews, err := spc.ListReviews(org, repo, number)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the reviews of %s/%s#%d: %v", org, repo, number, err)
+		}
+		approvers, changesRequestedBy = scmprovider.ReviewersByState(reviews)
+	}
+	unresolved := false
+	if reqs.ResolvedDiscussions {
+		var err error
+		unresolved, err = spc.HasUnresolvedDiscussions(org, repo, number)
+		if err == scm.ErrNotSupported {
+			unresolved = false
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to check the discussions of %s/%s#%d: %v", org, repo, number, err)
+		}
+	}
+	return reqs.Unmet(approvers, changesRequestedBy, unresolved), nil
+}
+
+// blockingIssues describes the open issues with the blocker label which block merges into the branch
+func blockingIssues(spc scmProviderClient, org, repo, branch, label string) ([]string, error) {
+	results, _, err := spc.Search(scm.SearchOptions{
+		Query: fmt.Sprintf("is:issue is:open repo:%s/%s label:\"%s\"", org, repo, label),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search the blocker issues of %s/%s: %v", org, repo, err)
+	}
+	var reasons []string
+	for _, r := range results {
+		if r.PullRequest || r.Closed {
+			continue
+		}
+		branches := blockers.ParseBranches(r.Title)
+		if len(branches) == 0 || sets.NewString(branches...).Has(branch) {
+			reasons = append(reasons, fmt.Sprintf("Merging is blocked by issue #%d.", r.Number))
+		}
+	}
+	return reasons, nil
+}
+
+func formatComment(author string, reasons []string) string {
+	var sb strings.Builder
+	sb.WriteString(whyHeader)
+	sb.WriteString("\n\n")
+	if len(reasons) == 0 {
+		sb.WriteString(fmt.Sprintf("@%s: this pull request meets all the merge requirements, keeper will merge it from the merge pool.\n", author))
+	} else {
+		sb.WriteString(fmt.Sprintf("@%s: this pull request cannot be merged yet:\n\n", author))
+		for _, r := range reasons {
+			sb.WriteString("- ")
+			sb.WriteString(r)
+			sb.WriteString("\n")
+		}
+	}
+	sb.WriteString("\nComment `/why` again to refresh this list.\n")
+	return sb.String()
+}
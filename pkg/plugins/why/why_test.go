@@ -0,0 +1,198 @@
+package why
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/botcomment"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/keeper"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	*fake.SCMClient
+}
+
+func (f *fakeClient) Search(opts scm.SearchOptions) ([]*scm.SearchIssue, *scmprovider.RateLimits, error) {
+	var results []*scm.SearchIssue
+	for _, issues := range f.Issues {
+		for _, issue := range issues {
+			results = append(results, &scm.SearchIssue{Issue: *issue})
+		}
+	}
+	return results, &scmprovider.RateLimits{}, nil
+}
+
+func TestHandle(t *testing.T) {
+	testCases := []struct {
+		name     string
+		branch   string
+		labels   []string
+		statuses []*scm.Status
+		reviews  []*scm.Review
+		state    scm.MergeableState
+		issues   []*scm.Issue
+		queries  keeper.Queries
+		reqs     *keeper.ReviewRequirements
+		threads  bool
+
+		expected []string
+	}{
+		{
+			name:     "all requirements met",
+			labels:   []string{"approved", "lgtm"},
+			statuses: []*scm.Status{{Label: "unit", State: scm.StateSuccess}, {Label: "keeper", State: scm.StatePending}},
+			expected: []string{"this pull request meets all the merge requirements"},
+		},
+		{
+			name:   "missing and forbidden labels",
+			labels: []string{"approved", "do-not-merge/hold"},
+			statuses: []*scm.Status{
+				{Label: "unit", State: scm.StateSuccess},
+			},
+			expected: []string{
+				"- Needs the `lgtm` label.\n",
+				"- Must not have the `do-not-merge/hold` label.\n",
+			},
+		},
+		{
+			name:   "failing and missing contexts",
+			labels: []string{"approved", "lgtm"},
+			statuses: []*scm.Status{
+				{Label: "lint", State: scm.StateFailure},
+				{Label: "optional", State: scm.StateFailure},
+			},
+			expected: []string{
+				"- Context `lint` has not succeeded, it is failure.\n",
+				"- Context `unit` is required but has not reported a status.\n",
+			},
+		},
+		{
+			name:     "merge conflicts",
+			labels:   []string{"approved", "lgtm"},
+			statuses: []*scm.Status{{Label: "unit", State: scm.StateSuccess}},
+			state:    scm.MergeableStateConflicting,
+			expected: []string{"- Has merge conflicts with the `master` branch and needs a rebase.\n"},
+		},
+		{
+			name:     "review approval",
+			labels:   []string{"approved", "lgtm"},
+			statuses: []*scm.Status{{Label: "unit", State: scm.StateSuccess}},
+			reviews: []*scm.Review{
+				{Author: scm.User{Login: "alice"}, State: scm.ReviewStateApproved},
+				{Author: scm.User{Login: "bob"}, State: scm.ReviewStateChangesRequested},
+			},
+			queries:  keeper.Queries{{Repos: []string{"org/repo"}, Labels: []string{"lgtm"}, ReviewApprovedRequired: true}},
+			expected: []string{"- Needs an approving review.\n"},
+		},
+		{
+			name:     "review requirements",
+			labels:   []string{"approved", "lgtm"},
+			statuses: []*scm.Status{{Label: "unit", State: scm.StateSuccess}},
+			reviews: []*scm.Review{
+				{Author: scm.User{Login: "alice"}, State: scm.ReviewStateApproved},
+				{Author: scm.User{Login: "bob"}, State: scm.ReviewStateChangesRequested},
+			},
+			reqs:    &keeper.ReviewRequirements{MinApprovals: 2, NoChangesRequested: true, ResolvedDiscussions: true},
+			threads: true,
+			expected: []string{
+				"- Needs 2 approving reviews, has 1.\n",
+				"- Changes requested by bob.\n",
+				"- Has unresolved discussions.\n",
+			},
+		},
+		{
+			name:     "blocking issues",
+			labels:   []string{"approved", "lgtm"},
+			statuses: []*scm.Status{{Label: "unit", State: scm.StateSuccess}},
+			issues: []*scm.Issue{
+				{Number: 5, Title: "Broken build"},
+				{Number: 6, Title: "Release branch:release-1.0"},
+			},
+			expected: []string{"- Merging is blocked by issue #5.\n"},
+		},
+		{
+			name:     "forbidden branch",
+			branch:   "gh-pages",
+			labels:   []string{"approved", "lgtm"},
+			expected: []string{"- Merging to branch `gh-pages` is forbidden.\n"},
+		},
+		{
+			name:     "no query for the repository",
+			queries:  keeper.Queries{{Repos: []string{"org/other"}}},
+			expected: []string{"- Keeper does not merge the pull requests of org/repo as no keeper query matches the repository.\n"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			branch := tc.branch
+			if branch == "" {
+				branch = "master"
+			}
+			state := tc.state
+			if state == "" {
+				state = scm.MergeableStateMergeable
+			}
+			fc := &fake.SCMClient{
+				PullRequests: map[int]*scm.PullRequest{1: {
+					Number:         1,
+					MergeableState: state,
+					Base:           scm.PullRequestBranch{Ref: branch},
+					Head:           scm.PullRequestBranch{Sha: "sha"},
+				}},
+				PullRequestComments: map[int][]*scm.Comment{
+					1: {{ID: 1, Body: whyHeader + "\n\nold\n" + botcomment.Marker(pluginName), Author: scm.User{Login: "k8s-ci-robot"}}},
+				},
+				CombinedStatuses:      map[string]*scm.CombinedStatus{"sha": {Statuses: tc.statuses}},
+				Reviews:               map[int][]*scm.Review{1: tc.reviews},
+				UnresolvedDiscussions: map[int]bool{1: tc.threads},
+				Issues:                map[int][]*scm.Issue{0: tc.issues},
+			}
+			for _, l := range tc.labels {
+				fc.PullRequestLabelsExisting = append(fc.PullRequestLabelsExisting, "org/repo#1:"+l)
+			}
+			queries := tc.queries
+			if queries == nil {
+				queries = keeper.Queries{{
+					Repos:            []string{"org/repo"},
+					ExcludedBranches: []string{"gh-pages"},
+					Labels:           []string{"approved", "lgtm"},
+					MissingLabels:    []string{"do-not-merge/hold"},
+				}}
+			}
+			cfg := &config.Config{}
+			cfg.Keeper.Queries = queries
+			cfg.Keeper.BlockerLabel = "merge-blocker"
+			cfg.Keeper.ContextOptions.RequiredContexts = []string{"unit"}
+			cfg.Keeper.ContextOptions.OptionalContexts = []string{"optional"}
+			if tc.reqs != nil {
+				cfg.Keeper.ReviewRequirementsMap = map[string]keeper.ReviewRequirements{"org": *tc.reqs}
+			}
+
+			e := &scmprovider.GenericCommentEvent{
+				Repo:   scm.Repository{Namespace: "org", Name: "repo"},
+				Number: 1,
+				IsPR:   true,
+				Author: scm.User{Login: "author"},
+				Body:   "/why",
+			}
+			require.NoError(t, handle(&fakeClient{SCMClient: fc}, logrus.WithField("plugin", pluginName), cfg, e))
+
+			assert.Empty(t, fc.PullRequestCommentsAdded, "the previous comment is updated")
+			require.Len(t, fc.PullRequestCommentsEdited, 1)
+			comment := strings.TrimPrefix(fc.PullRequestCommentsEdited[0], "org/repo#1:")
+			assert.True(t, strings.HasPrefix(comment, whyHeader), comment)
+			for _, expected := range tc.expected {
+				assert.Contains(t, comment, expected)
+			}
+			assert.Equal(t, len(tc.expected), strings.Count(comment, "\n- ")+strings.Count(comment, "meets all"), comment)
+		})
+	}
+}
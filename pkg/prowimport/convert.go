@@ -0,0 +1,310 @@
+// Package prowimport converts the config.yaml and plugins.yaml of Prow to the configuration of Lighthouse, reporting
+// the settings Lighthouse does not support.
+//
+// Jobs running pods, i.e. using the kubernetes agent of Prow, are converted to jobs running an inline Tekton pipeline
+// whose single task runs the containers of the pod as its steps.
+package prowimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/pkg/errors"
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// kubernetesAgent is the Prow agent running the pod spec of a job
+	kubernetesAgent = "kubernetes"
+
+	// taskName is the name of the task running the containers of a converted pod spec
+	taskName = "run"
+)
+
+// ConvertConfig converts a Prow config.yaml, including its jobs, returning the Lighthouse config.yaml along with
+// warnings describing the settings which were dropped
+func ConvertConfig(data []byte) ([]byte, []string, error) {
+	root := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse the Prow config")
+	}
+	var warnings []string
+	presets, err := parsePresets(root["presets"])
+	if err != nil {
+		return nil, nil, err
+	}
+	if presubmits, ok := root["presubmits"].(map[string]interface{}); ok {
+		for repo, jobs := range presubmits {
+			presubmits[repo] = convertJobs(jobs, fmt.Sprintf("presubmits[%s]", repo), presets, &warnings)
+		}
+	}
+	if postsubmits, ok := root["postsubmits"].(map[string]interface{}); ok {
+		for repo, jobs := range postsubmits {
+			postsubmits[repo] = convertJobs(jobs, fmt.Sprintf("postsubmits[%s]", repo), presets, &warnings)
+		}
+	}
+	if periodics, ok := root["periodics"]; ok {
+		root["periodics"] = convertJobs(periodics, "periodics", presets, &warnings)
+	}
+	if len(presets) > 0 {
+		// presets only apply to pod specs so they were merged into the converted jobs
+		delete(root, "presets")
+	}
+
+	prune(root, reflect.TypeOf(config.Config{}), "", &warnings)
+	removeEmpty(root)
+	out, err := yaml.Marshal(root)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to marshal the Lighthouse config")
+	}
+	if _, err := config.LoadYAMLConfig(out); err != nil {
+		return out, sorted(warnings), errors.Wrap(err, "the converted config is invalid")
+	}
+	return out, sorted(warnings), nil
+}
+
+// ConvertPlugins converts a Prow plugins.yaml, returning the Lighthouse plugins.yaml along with warnings describing the
+// settings which were dropped. Plugins whose names are not in knownPlugins are removed.
+func ConvertPlugins(data []byte, knownPlugins sets.String) ([]byte, []string, error) {
+	root := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse the Prow plugins")
+	}
+	var warnings []string
+	if enabled, ok := root["plugins"].(map[string]interface{}); ok {
+		disabled := map[string]interface{}{}
+		for repo, value := range enabled {
+			var excludedRepos []string
+			enabled[repo], excludedRepos = convertEnabledPlugins(value, repo, knownPlugins, &warnings)
+			for _, excluded := range excludedRepos {
+				disabled[excluded] = enabled[repo]
+			}
+		}
+		if len(disabled) > 0 {
+			root["disabled_plugins"] = disabled
+		}
+	}
+
+	prune(root, reflect.TypeOf(plugins.Configuration{}), "", &warnings)
+	removeEmpty(root)
+	out, err := yaml.Marshal(root)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to marshal the Lighthouse plugins")
+	}
+	c := &plugins.Configuration{}
+	if err := yaml.Unmarshal(out, c); err != nil {
+		return out, sorted(warnings), errors.Wrap(err, "the converted plugins are invalid")
+	}
+	if err := c.Validate(); err != nil {
+		return out, sorted(warnings), errors.Wrap(err, "the converted plugins are invalid")
+	}
+	return out, sorted(warnings), nil
+}
+
+// convertEnabledPlugins converts the plugins enabled for an org or repository, which recent Prow versions configure
+// as an object listing the plugins and the excluded repositories. The excluded repositories are returned as org/repo
+// so that the plugins are disabled for them.
+func convertEnabledPlugins(value interface{}, repo string, knownPlugins sets.String, warnings *[]string) ([]interface{}, []string) {
+	var excludedRepos []string
+	if m, ok := value.(map[string]interface{}); ok {
+		excluded, _ := m["excluded_repos"].([]interface{})
+		for _, e := range excluded {
+			name, ok := e.(string)
+			if !ok {
+				continue
+			}
+			if !strings.Contains(name, "/") {
+				name = repo + "/" + name
+			}
+			excludedRepos = append(excludedRepos, name)
+		}
+		value = m["plugins"]
+	}
+	names, _ := value.([]interface{})
+	result := []interface{}{}
+	for _, name := range names {
+		if s, ok := name.(string); ok && !knownPlugins.Has(s) {
+			*warnings = append(*warnings, fmt.Sprintf("plugins[%s]: plugin %s is not supported", repo, s))
+			continue
+		}
+		result = append(result, name)
+	}
+	return result, excludedRepos
+}
+
+// convertJobs converts the jobs of a list, dropping those whose agent is not supported
+func convertJobs(value interface{}, path string, presets []job.Preset, warnings *[]string) []interface{} {
+	items, _ := value.([]interface{})
+	result := []interface{}{}
+	for i, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if convertJob(m, itemPath(path, i, m), presets, warnings) {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// convertJob converts a job in place, returning false if the job cannot be converted
+func convertJob(j map[string]interface{}, path string, presets []job.Preset, warnings *[]string) bool {
+	if _, ok := j["decorate"]; ok {
+		*warnings = append(*warnings, fmt.Sprintf("%s.decorate is not supported, the pipeline of the job must clone the repository itself", path))
+		delete(j, "decorate")
+	}
+	agent, _ := j["agent"].(string)
+	switch agent {
+	case "", kubernetesAgent:
+		spec, ok := j["spec"]
+		if !ok {
+			*warnings = append(*warnings, fmt.Sprintf("%s has no spec so it is dropped", path))
+			return false
+		}
+		labels := map[string]string{}
+		if l, ok := j["labels"].(map[string]interface{}); ok {
+			for k, v := range l {
+				labels[k], _ = v.(string)
+			}
+		}
+		runSpec, podTemplate, err := pipelineRunSpecForPod(spec, labels, presets, path, warnings)
+		if err != nil {
+			*warnings = append(*warnings, fmt.Sprintf("%s could not be converted so it is dropped: %v", path, err))
+			return false
+		}
+		delete(j, "spec")
+		j["agent"] = job.TektonPipelineAgent
+		j["pipeline_run_spec"] = runSpec
+		if podTemplate != nil {
+			j["pod_template"] = podTemplate
+		}
+	case job.TektonPipelineAgent, job.JenkinsAgent:
+	default:
+		*warnings = append(*warnings, fmt.Sprintf("%s uses the %s agent which is not supported so it is dropped", path, agent))
+		return false
+	}
+	return true
+}
+
+// pipelineRunSpecForPod converts the pod spec of a job to the spec of a pipeline run, along with the pod template
+// holding the scheduling of the pod
+func pipelineRunSpecForPod(value interface{}, labels map[string]string, presets []job.Preset, path string, warnings *[]string) (interface{}, interface{}, error) {
+	spec := &v1.PodSpec{}
+	if err := convert(value, spec); err != nil {
+		return nil, nil, err
+	}
+	for _, preset := range presets {
+		if err := job.MergePreset(preset, labels, spec); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(spec.Containers) == 0 {
+		return nil, nil, errors.New("the spec has no containers")
+	}
+
+	taskSpec := &tektonv1beta1.TaskSpec{Volumes: spec.Volumes}
+	for _, c := range spec.Containers {
+		taskSpec.Steps = append(taskSpec.Steps, tektonv1beta1.Step{Container: c})
+	}
+	runSpec := &tektonv1beta1.PipelineRunSpec{
+		ServiceAccountName: spec.ServiceAccountName,
+		PipelineSpec: &tektonv1beta1.PipelineSpec{
+			Tasks: []tektonv1beta1.PipelineTask{{Name: taskName, TaskSpec: taskSpec}},
+		},
+	}
+	var podTemplate *job.PodTemplate
+	if len(spec.NodeSelector) > 0 || len(spec.Tolerations) > 0 || spec.PriorityClassName != "" {
+		podTemplate = &job.PodTemplate{NodeSelector: spec.NodeSelector, Tolerations: spec.Tolerations, PriorityClassName: spec.PriorityClassName}
+	}
+
+	// report the settings of the pod which were not converted
+	spec.Containers = nil
+	spec.Volumes = nil
+	spec.ServiceAccountName = ""
+	spec.NodeSelector = nil
+	spec.Tolerations = nil
+	spec.PriorityClassName = ""
+	remaining := map[string]interface{}{}
+	if err := convert(spec, &remaining); err != nil {
+		return nil, nil, err
+	}
+	for key := range remaining {
+		if key != "containers" {
+			*warnings = append(*warnings, fmt.Sprintf("%s.spec.%s is not supported", path, key))
+		}
+	}
+
+	var runSpecValue, podTemplateValue interface{}
+	if err := convert(runSpec, &runSpecValue); err != nil {
+		return nil, nil, err
+	}
+	if podTemplate != nil {
+		if err := convert(podTemplate, &podTemplateValue); err != nil {
+			return nil, nil, err
+		}
+	}
+	return runSpecValue, podTemplateValue, nil
+}
+
+func parsePresets(value interface{}) ([]job.Preset, error) {
+	if value == nil {
+		return nil, nil
+	}
+	var presets []job.Preset
+	if err := convert(value, &presets); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the presets")
+	}
+	return presets, nil
+}
+
+// removeEmpty removes the empty strings and objects of a generic YAML value, e.g. those left by pruning or by
+// marshalling the containers of pod specs
+func removeEmpty(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			removeEmpty(child)
+			if isEmpty(child) {
+				delete(v, key)
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			removeEmpty(child)
+		}
+	}
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return v == ""
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// convert converts a value to another type through its JSON representation
+func convert(from, to interface{}) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, to)
+}
+
+func sorted(warnings []string) []string {
+	sort.Strings(warnings)
+	return warnings
+}
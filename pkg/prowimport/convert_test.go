@@ -0,0 +1,66 @@
+package prowimport
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestConvertConfig(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("test_data", "prow-config.yaml"))
+	require.NoError(t, err)
+
+	out, warnings, err := ConvertConfig(data)
+	require.NoError(t, err)
+
+	expected, err := ioutil.ReadFile(filepath.Join("test_data", "lighthouse-config.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(out))
+	assert.Equal(t, []string{
+		"deck is not supported",
+		"periodics[nightly].extra_refs is not supported",
+		"plank.job_url_template is not supported",
+		"presubmits[org/repo][lint] uses the knative-build agent which is not supported so it is dropped",
+		"presubmits[org/repo][unit].decorate is not supported, the pipeline of the job must clone the repository itself",
+		"presubmits[org/repo][unit].spec.hostNetwork is not supported",
+		"tide.priority is not supported",
+	}, warnings)
+
+	c, err := config.LoadYAMLConfig(out)
+	require.NoError(t, err)
+	presubmits := c.Presubmits["org/repo"]
+	require.Len(t, presubmits, 1)
+	unit := presubmits[0]
+	assert.Equal(t, job.TektonPipelineAgent, unit.Agent)
+	require.NotNil(t, unit.PipelineRunSpec)
+	assert.Equal(t, "tester", unit.PipelineRunSpec.ServiceAccountName)
+	steps := unit.PipelineRunSpec.PipelineSpec.Tasks[0].TaskSpec.Steps
+	require.Len(t, steps, 1)
+	assert.Equal(t, []string{"make", "test"}, steps[0].Command)
+	// the preset matching the labels of the job is merged into its steps
+	assert.Equal(t, "DOCKER_HOST", steps[0].Env[0].Name)
+	assert.Equal(t, map[string]string{"pool": "ci"}, unit.PodTemplate.NodeSelector)
+	assert.Equal(t, []string{"lgtm", "approved"}, c.Keeper.Queries[0].Labels)
+}
+
+func TestConvertPlugins(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("test_data", "prow-plugins.yaml"))
+	require.NoError(t, err)
+
+	out, warnings, err := ConvertPlugins(data, sets.NewString("approve", "hold", "lgtm"))
+	require.NoError(t, err)
+
+	expected, err := ioutil.ReadFile(filepath.Join("test_data", "lighthouse-plugins.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(out))
+	assert.Equal(t, []string{
+		"plugins[org]: plugin slackevents is not supported",
+		"slack is not supported",
+	}, warnings)
+}
@@ -0,0 +1,107 @@
+package prowimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// prune removes the keys of the generic YAML value which have no field in the given type, recording a warning for
+// each of them, and returns the pruned value
+func prune(value interface{}, t reflect.Type, path string, warnings *[]string) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	// types with their own unmarshalling, e.g. durations and quantities, are kept as they are
+	if t.Implements(unmarshalerType) || reflect.PtrTo(t).Implements(unmarshalerType) {
+		return value
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		fields := jsonFields(t)
+		for key, v := range m {
+			fieldType, ok := fields[key]
+			if !ok {
+				*warnings = append(*warnings, fmt.Sprintf("%s is not supported", childPath(path, key)))
+				delete(m, key)
+				continue
+			}
+			m[key] = prune(v, fieldType, childPath(path, key), warnings)
+		}
+	case reflect.Map:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		for key, v := range m {
+			m[key] = prune(v, t.Elem(), fmt.Sprintf("%s[%s]", path, key), warnings)
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return value
+		}
+		for i, v := range items {
+			items[i] = prune(v, t.Elem(), itemPath(path, i, v), warnings)
+		}
+	}
+	return value
+}
+
+// jsonFields returns the types of the fields of a struct by their JSON names, including those of inlined structs
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && (name == "" || strings.Contains(tag, ",inline")) {
+			embedded := f.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for k, v := range jsonFields(embedded) {
+					fields[k] = v
+				}
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+func childPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// itemPath identifies the items of lists by their name if they have one, e.g. jobs, and their index otherwise
+func itemPath(path string, index int, item interface{}) string {
+	if m, ok := item.(map[string]interface{}); ok {
+		if name, ok := m["name"].(string); ok && name != "" {
+			return fmt.Sprintf("%s[%s]", path, name)
+		}
+	}
+	return fmt.Sprintf("%s[%d]", path, index)
+}
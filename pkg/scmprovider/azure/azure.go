@@ -0,0 +1,161 @@
+// Package azure implements a go-scm driver for Azure Repos, the git repositories of Azure DevOps, which go-scm does
+// not support. The server URL is the one of the organization, e.g. https://dev.azure.com/myorg, and the full name of
+// a repository is its project and name, e.g. myproject/myrepo.
+//
+// Azure Repos has no issues, so the issue services act on the pull requests. The comments of the pull requests live in
+// threads: a new comment starts a new thread and the ID of a comment encodes both the ID of its thread and its own.
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/transport"
+)
+
+const (
+	// Name is the git kind of Azure Repos
+	Name = "azure"
+
+	// Driver identifies the clients of Azure Repos, go-scm does not define a driver for it
+	Driver scm.Driver = 100
+
+	apiVersion = "6.0"
+)
+
+// NewClient returns a client of the Azure Repos organization at the server URL, authenticated with the personal
+// access token if it is not empty
+func NewClient(serverURL, token string) (*scm.Client, error) {
+	if serverURL == "" {
+		return nil, fmt.Errorf("the server URL of the Azure DevOps organization is required, e.g. https://dev.azure.com/myorg")
+	}
+	base, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path = base.Path + "/"
+	}
+	client := &wrapper{Client: new(scm.Client)}
+	client.BaseURL = base
+	client.Driver = Driver
+	client.Contents = &contentService{client}
+	client.Git = &gitService{client}
+	client.Issues = &issueService{client}
+	client.Organizations = &organizationService{client}
+	client.PullRequests = &pullService{client}
+	client.Repositories = &repositoryService{client}
+	client.Reviews = &reviewService{client}
+	client.Users = &userService{client}
+	client.Webhooks = &webhookService{client}
+	if token != "" {
+		client.Client.Client = &http.Client{
+			Transport: Transport(token),
+		}
+	}
+	return client.Client, nil
+}
+
+// Transport returns the transport authenticating the requests with the personal access token
+func Transport(token string) http.RoundTripper {
+	return &transport.BasicAuth{
+		Password: token,
+	}
+}
+
+type wrapper struct {
+	*scm.Client
+}
+
+// repoPath returns the path of the API of the repository, e.g. myproject/_apis/git/repositories/myrepo/pullrequests
+func repoPath(repo string, elements ...string) string {
+	project, name := scm.Split(repo)
+	path := fmt.Sprintf("%s/_apis/git/repositories/%s", url.PathEscape(project), url.PathEscape(name))
+	if len(elements) == 0 {
+		return path
+	}
+	return path + "/" + strings.Join(elements, "/")
+}
+
+// withQuery appends the API version and the parameters to the path
+func withQuery(path string, params url.Values) string {
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("api-version", apiVersion)
+	return path + "?" + params.Encode()
+}
+
+// do wraps the Client.Do function by creating the Request and
+// unmarshalling the response.
+func (c *wrapper) do(ctx context.Context, method, path string, in, out interface{}) (*scm.Response, error) {
+	req := &scm.Request{
+		Method: method,
+		Path:   path,
+	}
+	if in != nil {
+		buf := new(bytes.Buffer)
+		if err := json.NewEncoder(buf).Encode(in); err != nil {
+			return nil, err
+		}
+		req.Header = map[string][]string{
+			"Content-Type": {"application/json"},
+		}
+		req.Body = buf
+	}
+	res, err := c.Client.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.Status == http.StatusNotFound {
+		return res, scm.ErrNotFound
+	}
+	if res.Status > 300 {
+		e := new(Error)
+		_ = json.NewDecoder(res.Body).Decode(e)
+		if e.Message == "" {
+			e.Message = fmt.Sprintf("%s %s: status %d", method, path, res.Status)
+		}
+		return res, e
+	}
+	if out == nil {
+		return res, nil
+	}
+	return res, json.NewDecoder(res.Body).Decode(out)
+}
+
+// Error represents an Azure DevOps error.
+type Error struct {
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// list is the envelope of the collections returned by the API
+type list struct {
+	Count int             `json:"count"`
+	Value json.RawMessage `json:"value"`
+}
+
+// doList requests a collection and unmarshals its values
+func (c *wrapper) doList(ctx context.Context, path string, out interface{}) (*scm.Response, error) {
+	l := list{}
+	res, err := c.do(ctx, http.MethodGet, path, nil, &l)
+	if err != nil {
+		return res, err
+	}
+	if len(l.Value) == 0 {
+		return res, nil
+	}
+	return res, json.Unmarshal(l.Value, out)
+}
@@ -0,0 +1,113 @@
+package azure
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+type contentService struct {
+	client *wrapper
+}
+
+type item struct {
+	ObjectID      string `json:"objectId"`
+	GitObjectType string `json:"gitObjectType"`
+	CommitID      string `json:"commitId"`
+	Path          string `json:"path"`
+	IsFolder      bool   `json:"isFolder"`
+	Content       string `json:"content"`
+	URL           string `json:"url"`
+}
+
+func (s *contentService) Find(ctx context.Context, repo, filepath, ref string) (*scm.Content, *scm.Response, error) {
+	params := versionParams(ref)
+	params.Set("path", "/"+filepath)
+	params.Set("includeContent", "true")
+	out := new(item)
+	res, err := s.client.do(ctx, http.MethodGet, withQuery(repoPath(repo, "items"), params), nil, out)
+	if err != nil {
+		return nil, res, err
+	}
+	return &scm.Content{
+		Path: filepath,
+		Data: []byte(out.Content),
+		Sha:  out.ObjectID,
+	}, res, nil
+}
+
+// List returns the entries of the folder, the first item returned by the API being the folder itself
+func (s *contentService) List(ctx context.Context, repo, filepath, ref string) ([]*scm.FileEntry, *scm.Response, error) {
+	params := versionParams(ref)
+	params.Set("scopePath", "/"+filepath)
+	params.Set("recursionLevel", "OneLevel")
+	var out []*item
+	res, err := s.client.doList(ctx, withQuery(repoPath(repo, "items"), params), &out)
+	if err != nil {
+		return nil, res, err
+	}
+	var answer []*scm.FileEntry
+	for _, i := range out {
+		p := strings.TrimPrefix(i.Path, "/")
+		if p == strings.Trim(filepath, "/") {
+			continue
+		}
+		entry := &scm.FileEntry{
+			Name: path.Base(p),
+			Path: p,
+			Type: "file",
+			Sha:  i.ObjectID,
+			Link: i.URL,
+		}
+		if i.IsFolder {
+			entry.Type = "dir"
+		}
+		answer = append(answer, entry)
+	}
+	return answer, res, nil
+}
+
+func (s *contentService) Create(context.Context, string, string, *scm.ContentParams) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (s *contentService) Update(context.Context, string, string, *scm.ContentParams) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (s *contentService) Delete(context.Context, string, string, string) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+// versionParams returns the parameters selecting the version of the items, a SHA being a commit and anything else a
+// branch
+func versionParams(ref string) url.Values {
+	params := url.Values{}
+	if ref == "" {
+		return params
+	}
+	if isSHA(ref) {
+		params.Set("versionDescriptor.versionType", "commit")
+	} else {
+		params.Set("versionDescriptor.versionType", "branch")
+		ref = scm.TrimRef(ref)
+	}
+	params.Set("versionDescriptor.version", ref)
+	return params
+}
+
+func isSHA(ref string) bool {
+	if len(ref) != 40 {
+		return false
+	}
+	for _, r := range ref {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package azure
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+type gitService struct {
+	client *wrapper
+}
+
+type ref struct {
+	Name     string `json:"name"`
+	ObjectID string `json:"objectId"`
+}
+
+type commit struct {
+	CommitID  string          `json:"commitId"`
+	Comment   string          `json:"comment"`
+	Author    commitSignature `json:"author"`
+	Committer commitSignature `json:"committer"`
+	RemoteURL string          `json:"remoteUrl"`
+}
+
+type commitSignature struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	Date  time.Time `json:"date"`
+}
+
+// FindRef returns the SHA of a branch or a tag, e.g. heads/master or tags/v1.0.0
+func (s *gitService) FindRef(ctx context.Context, repo, name string) (string, *scm.Response, error) {
+	name = strings.TrimPrefix(name, "refs/")
+	if !strings.HasPrefix(name, "heads/") && !strings.HasPrefix(name, "tags/") {
+		name = "heads/" + name
+	}
+	var out []*ref
+	res, err := s.client.doList(ctx, withQuery(repoPath(repo, "refs"), url.Values{"filter": []string{name}}), &out)
+	if err != nil {
+		return "", res, err
+	}
+	for _, r := range out {
+		if r.Name == "refs/"+name {
+			return r.ObjectID, res, nil
+		}
+	}
+	return "", res, scm.ErrNotFound
+}
+
+func (s *gitService) FindBranch(ctx context.Context, repo, name string) (*scm.Reference, *scm.Response, error) {
+	sha, res, err := s.FindRef(ctx, repo, "heads/"+scm.TrimRef(name))
+	if err != nil {
+		return nil, res, err
+	}
+	return &scm.Reference{
+		Name: scm.TrimRef(name),
+		Path: scm.ExpandRef(name, "refs/heads"),
+		Sha:  sha,
+	}, res, nil
+}
+
+func (s *gitService) FindCommit(ctx context.Context, repo, sha string) (*scm.Commit, *scm.Response, error) {
+	out := new(commit)
+	res, err := s.client.do(ctx, http.MethodGet, withQuery(repoPath(repo, "commits", sha), nil), nil, out)
+	if err != nil {
+		return nil, res, err
+	}
+	return &scm.Commit{
+		Sha:     out.CommitID,
+		Message: out.Comment,
+		Author: scm.Signature{
+			Name:  out.Author.Name,
+			Email: out.Author.Email,
+			Date:  out.Author.Date,
+		},
+		Committer: scm.Signature{
+			Name:  out.Committer.Name,
+			Email: out.Committer.Email,
+			Date:  out.Committer.Date,
+		},
+		Link: out.RemoteURL,
+	}, res, nil
+}
+
+func (s *gitService) FindTag(context.Context, string, string) (*scm.Reference, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *gitService) ListBranches(context.Context, string, scm.ListOptions) ([]*scm.Reference, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *gitService) ListCommits(context.Context, string, scm.CommitListOptions) ([]*scm.Commit, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *gitService) ListChanges(context.Context, string, string, scm.ListOptions) ([]*scm.Change, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *gitService) ListTags(context.Context, string, scm.ListOptions) ([]*scm.Reference, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *gitService) DeleteRef(context.Context, string, string) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (s *gitService) CreateRef(context.Context, string, string, string) (*scm.Reference, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
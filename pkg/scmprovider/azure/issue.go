@@ -0,0 +1,120 @@
+package azure
+
+import (
+	"context"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// issueService acts on the pull requests as Azure Repos has no issues, the work items of Azure Boards not being
+// linked to the repositories
+type issueService struct {
+	client *wrapper
+}
+
+func (s *issueService) pulls() *pullService {
+	return &pullService{s.client}
+}
+
+func (s *issueService) Find(ctx context.Context, repo string, number int) (*scm.Issue, *scm.Response, error) {
+	pr, res, err := s.pulls().Find(ctx, repo, number)
+	if err != nil {
+		return nil, res, err
+	}
+	issue := &scm.Issue{
+		Number:      pr.Number,
+		Title:       pr.Title,
+		Body:        pr.Body,
+		Link:        pr.Link,
+		State:       pr.State,
+		Closed:      pr.Closed,
+		Author:      pr.Author,
+		PullRequest: true,
+		Created:     pr.Created,
+		Updated:     pr.Updated,
+	}
+	for _, l := range pr.Labels {
+		issue.Labels = append(issue.Labels, l.Name)
+	}
+	return issue, res, nil
+}
+
+func (s *issueService) FindComment(ctx context.Context, repo string, number, id int) (*scm.Comment, *scm.Response, error) {
+	return s.pulls().FindComment(ctx, repo, number, id)
+}
+
+func (s *issueService) ListComments(ctx context.Context, repo string, number int, opts scm.ListOptions) ([]*scm.Comment, *scm.Response, error) {
+	return s.pulls().ListComments(ctx, repo, number, opts)
+}
+
+func (s *issueService) ListLabels(ctx context.Context, repo string, number int, opts scm.ListOptions) ([]*scm.Label, *scm.Response, error) {
+	return s.pulls().ListLabels(ctx, repo, number, opts)
+}
+
+func (s *issueService) CreateComment(ctx context.Context, repo string, number int, input *scm.CommentInput) (*scm.Comment, *scm.Response, error) {
+	return s.pulls().CreateComment(ctx, repo, number, input)
+}
+
+func (s *issueService) DeleteComment(ctx context.Context, repo string, number, id int) (*scm.Response, error) {
+	return s.pulls().DeleteComment(ctx, repo, number, id)
+}
+
+func (s *issueService) EditComment(ctx context.Context, repo string, number, id int, input *scm.CommentInput) (*scm.Comment, *scm.Response, error) {
+	return s.pulls().EditComment(ctx, repo, number, id, input)
+}
+
+func (s *issueService) Close(ctx context.Context, repo string, number int) (*scm.Response, error) {
+	return s.pulls().Close(ctx, repo, number)
+}
+
+func (s *issueService) Reopen(ctx context.Context, repo string, number int) (*scm.Response, error) {
+	return s.pulls().Reopen(ctx, repo, number)
+}
+
+func (s *issueService) AddLabel(ctx context.Context, repo string, number int, label string) (*scm.Response, error) {
+	return s.pulls().AddLabel(ctx, repo, number, label)
+}
+
+func (s *issueService) DeleteLabel(ctx context.Context, repo string, number int, label string) (*scm.Response, error) {
+	return s.pulls().DeleteLabel(ctx, repo, number, label)
+}
+
+func (s *issueService) List(context.Context, string, scm.IssueListOptions) ([]*scm.Issue, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *issueService) Search(context.Context, scm.SearchOptions) ([]*scm.SearchIssue, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *issueService) ListEvents(context.Context, string, int, scm.ListOptions) ([]*scm.ListedIssueEvent, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *issueService) Create(context.Context, string, *scm.IssueInput) (*scm.Issue, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *issueService) Lock(context.Context, string, int) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (s *issueService) Unlock(context.Context, string, int) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (s *issueService) AssignIssue(context.Context, string, int, []string) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (s *issueService) UnassignIssue(context.Context, string, int, []string) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (s *issueService) SetMilestone(context.Context, string, int, int) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (s *issueService) ClearMilestone(context.Context, string, int) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
@@ -0,0 +1,60 @@
+package azure
+
+import (
+	"context"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+type organizationService struct {
+	client *wrapper
+}
+
+// IsMember returns true as only the members of an organization can access its repositories
+func (s *organizationService) IsMember(context.Context, string, string) (bool, *scm.Response, error) {
+	return true, nil, nil
+}
+
+func (s *organizationService) IsAdmin(context.Context, string, string) (bool, *scm.Response, error) {
+	return false, nil, scm.ErrNotSupported
+}
+
+func (s *organizationService) Find(context.Context, string) (*scm.Organization, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *organizationService) Create(context.Context, *scm.OrganizationInput) (*scm.Organization, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *organizationService) Delete(context.Context, string) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (s *organizationService) List(context.Context, scm.ListOptions) ([]*scm.Organization, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *organizationService) ListTeams(context.Context, string, scm.ListOptions) ([]*scm.Team, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *organizationService) ListTeamMembers(context.Context, int, string, scm.ListOptions) ([]*scm.TeamMember, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *organizationService) ListOrgMembers(context.Context, string, scm.ListOptions) ([]*scm.TeamMember, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *organizationService) ListPendingInvitations(context.Context, string, scm.ListOptions) ([]*scm.OrganizationPendingInvite, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *organizationService) AcceptOrganizationInvitation(context.Context, string) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (s *organizationService) ListMemberships(context.Context, scm.ListOptions) ([]*scm.Membership, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
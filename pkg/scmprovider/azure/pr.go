@@ -0,0 +1,470 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+type pullService struct {
+	client *wrapper
+}
+
+type identity struct {
+	ID          string `json:"id,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+	UniqueName  string `json:"uniqueName,omitempty"`
+	ImageURL    string `json:"imageUrl,omitempty"`
+}
+
+type reviewer struct {
+	identity
+	Vote int `json:"vote"`
+}
+
+type commitRef struct {
+	CommitID string `json:"commitId"`
+}
+
+type label struct {
+	ID     string `json:"id,omitempty"`
+	Name   string `json:"name"`
+	Active bool   `json:"active,omitempty"`
+}
+
+type pullRequest struct {
+	PullRequestID         int         `json:"pullRequestId"`
+	Repository            repository  `json:"repository"`
+	Status                string      `json:"status"`
+	CreatedBy             identity    `json:"createdBy"`
+	CreationDate          time.Time   `json:"creationDate"`
+	ClosedDate            *time.Time  `json:"closedDate,omitempty"`
+	Title                 string      `json:"title"`
+	Description           string      `json:"description"`
+	SourceRefName         string      `json:"sourceRefName"`
+	TargetRefName         string      `json:"targetRefName"`
+	MergeStatus           string      `json:"mergeStatus"`
+	IsDraft               bool        `json:"isDraft"`
+	LastMergeSourceCommit *commitRef  `json:"lastMergeSourceCommit,omitempty"`
+	LastMergeTargetCommit *commitRef  `json:"lastMergeTargetCommit,omitempty"`
+	LastMergeCommit       *commitRef  `json:"lastMergeCommit,omitempty"`
+	Reviewers             []reviewer  `json:"reviewers"`
+	Labels                []label     `json:"labels"`
+	URL                   string      `json:"url"`
+	CompletionOptions     *completion `json:"completionOptions,omitempty"`
+}
+
+type completion struct {
+	MergeStrategy      string `json:"mergeStrategy,omitempty"`
+	DeleteSourceBranch bool   `json:"deleteSourceBranch,omitempty"`
+	MergeCommitMessage string `json:"mergeCommitMessage,omitempty"`
+}
+
+type pullRequestUpdate struct {
+	Status                string      `json:"status,omitempty"`
+	LastMergeSourceCommit *commitRef  `json:"lastMergeSourceCommit,omitempty"`
+	CompletionOptions     *completion `json:"completionOptions,omitempty"`
+}
+
+type iteration struct {
+	ID              int        `json:"id"`
+	SourceRefCommit *commitRef `json:"sourceRefCommit,omitempty"`
+}
+
+type change struct {
+	ChangeType   string `json:"changeType"`
+	OriginalPath string `json:"originalPath"`
+	Item         struct {
+		Path          string `json:"path"`
+		IsFolder      bool   `json:"isFolder"`
+		GitObjectType string `json:"gitObjectType"`
+	} `json:"item"`
+}
+
+type thread struct {
+	ID        int       `json:"id,omitempty"`
+	Status    string    `json:"status,omitempty"`
+	Comments  []comment `json:"comments"`
+	IsDeleted bool      `json:"isDeleted,omitempty"`
+}
+
+type comment struct {
+	ID              int        `json:"id,omitempty"`
+	ParentCommentID int        `json:"parentCommentId"`
+	Author          *identity  `json:"author,omitempty"`
+	Content         string     `json:"content"`
+	PublishedDate   time.Time  `json:"publishedDate,omitempty"`
+	LastUpdatedDate time.Time  `json:"lastUpdatedDate,omitempty"`
+	CommentType     string     `json:"commentType,omitempty"`
+	IsDeleted       bool       `json:"isDeleted,omitempty"`
+	Links           *linkTable `json:"_links,omitempty"`
+}
+
+type linkTable struct {
+	Self    link `json:"self"`
+	Threads link `json:"threads"`
+}
+
+type link struct {
+	Href string `json:"href"`
+}
+
+// botThreadStatus is the status of the threads of the comments created by Lighthouse, which are closed so that they
+// never block the completion of a pull request when the resolution of the comments is required
+const botThreadStatus = "closed"
+
+func (s *pullService) Find(ctx context.Context, repo string, number int) (*scm.PullRequest, *scm.Response, error) {
+	out := new(pullRequest)
+	res, err := s.client.do(ctx, http.MethodGet, withQuery(repoPath(repo, "pullrequests", strconv.Itoa(number)), nil), nil, out)
+	if err != nil {
+		return nil, res, err
+	}
+	return convertPullRequest(out), res, nil
+}
+
+func (s *pullService) List(ctx context.Context, repo string, opts scm.PullRequestListOptions) ([]*scm.PullRequest, *scm.Response, error) {
+	params := url.Values{}
+	switch {
+	case opts.Open && opts.Closed:
+		params.Set("searchCriteria.status", "all")
+	case opts.Closed:
+		params.Set("searchCriteria.status", "completed")
+	default:
+		params.Set("searchCriteria.status", "active")
+	}
+	if opts.Size > 0 {
+		params.Set("$top", strconv.Itoa(opts.Size))
+		if opts.Page > 1 {
+			params.Set("$skip", strconv.Itoa((opts.Page-1)*opts.Size))
+		}
+	}
+	var out []*pullRequest
+	res, err := s.client.doList(ctx, withQuery(repoPath(repo, "pullrequests"), params), &out)
+	if err != nil {
+		return nil, res, err
+	}
+	var answer []*scm.PullRequest
+	for _, pr := range out {
+		converted := convertPullRequest(pr)
+		if len(opts.Labels) > 0 && !hasLabels(converted, opts.Labels) {
+			continue
+		}
+		answer = append(answer, converted)
+	}
+	return answer, res, nil
+}
+
+// ListChanges returns the changes of the latest iteration of the pull request, i.e. of its latest push, compared to
+// its target branch
+func (s *pullService) ListChanges(ctx context.Context, repo string, number int, _ scm.ListOptions) ([]*scm.Change, *scm.Response, error) {
+	it, res, err := s.latestIteration(ctx, repo, number)
+	if err != nil || it == nil {
+		return nil, res, err
+	}
+	out := struct {
+		ChangeEntries []change `json:"changeEntries"`
+	}{}
+	params := url.Values{"$compareTo": []string{"0"}}
+	res, err = s.client.do(ctx, http.MethodGet, withQuery(repoPath(repo, "pullRequests", strconv.Itoa(number), "iterations", strconv.Itoa(it.ID), "changes"), params), nil, &out)
+	if err != nil {
+		return nil, res, err
+	}
+	var answer []*scm.Change
+	for _, c := range out.ChangeEntries {
+		if c.Item.IsFolder || c.Item.GitObjectType == "tree" {
+			continue
+		}
+		answer = append(answer, &scm.Change{
+			Path:         strings.TrimPrefix(c.Item.Path, "/"),
+			PreviousPath: strings.TrimPrefix(c.OriginalPath, "/"),
+			Added:        strings.Contains(c.ChangeType, "add"),
+			Renamed:      strings.Contains(c.ChangeType, "rename"),
+			Deleted:      strings.Contains(c.ChangeType, "delete"),
+		})
+	}
+	return answer, res, nil
+}
+
+// latestIteration returns the latest iteration of the pull request, each push to its source branch creating one
+func (s *pullService) latestIteration(ctx context.Context, repo string, number int) (*iteration, *scm.Response, error) {
+	var out []*iteration
+	res, err := s.client.doList(ctx, withQuery(repoPath(repo, "pullRequests", strconv.Itoa(number), "iterations"), nil), &out)
+	if err != nil {
+		return nil, res, err
+	}
+	var latest *iteration
+	for _, it := range out {
+		if latest == nil || it.ID > latest.ID {
+			latest = it
+		}
+	}
+	return latest, res, nil
+}
+
+// ListComments returns the comments of the threads of the pull request, the ones generated by the system excepted
+func (s *pullService) ListComments(ctx context.Context, repo string, number int, _ scm.ListOptions) ([]*scm.Comment, *scm.Response, error) {
+	var threads []*thread
+	res, err := s.client.doList(ctx, withQuery(repoPath(repo, "pullRequests", strconv.Itoa(number), "threads"), nil), &threads)
+	if err != nil {
+		return nil, res, err
+	}
+	var answer []*scm.Comment
+	for _, t := range threads {
+		if t.IsDeleted {
+			continue
+		}
+		for i := range t.Comments {
+			c := &t.Comments[i]
+			if c.IsDeleted || c.CommentType == "system" {
+				continue
+			}
+			answer = append(answer, convertComment(t.ID, c))
+		}
+	}
+	return answer, res, nil
+}
+
+func (s *pullService) FindComment(ctx context.Context, repo string, number, id int) (*scm.Comment, *scm.Response, error) {
+	threadID, commentID := SplitCommentID(id)
+	out := new(comment)
+	res, err := s.client.do(ctx, http.MethodGet, withQuery(repoPath(repo, "pullRequests", strconv.Itoa(number), "threads", strconv.Itoa(threadID), "comments", strconv.Itoa(commentID)), nil), nil, out)
+	if err != nil {
+		return nil, res, err
+	}
+	return convertComment(threadID, out), res, nil
+}
+
+// CreateComment starts a new thread with the comment
+func (s *pullService) CreateComment(ctx context.Context, repo string, number int, input *scm.CommentInput) (*scm.Comment, *scm.Response, error) {
+	in := &thread{
+		Status:   botThreadStatus,
+		Comments: []comment{{Content: input.Body, CommentType: "text"}},
+	}
+	out := new(thread)
+	res, err := s.client.do(ctx, http.MethodPost, withQuery(repoPath(repo, "pullRequests", strconv.Itoa(number), "threads"), nil), in, out)
+	if err != nil {
+		return nil, res, err
+	}
+	if len(out.Comments) == 0 {
+		return nil, res, fmt.Errorf("no comment in the thread %d created on pull request %d of %s", out.ID, number, repo)
+	}
+	return convertComment(out.ID, &out.Comments[0]), res, nil
+}
+
+func (s *pullService) EditComment(ctx context.Context, repo string, number, id int, input *scm.CommentInput) (*scm.Comment, *scm.Response, error) {
+	threadID, commentID := SplitCommentID(id)
+	in := &comment{Content: input.Body}
+	out := new(comment)
+	res, err := s.client.do(ctx, http.MethodPatch, withQuery(repoPath(repo, "pullRequests", strconv.Itoa(number), "threads", strconv.Itoa(threadID), "comments", strconv.Itoa(commentID)), nil), in, out)
+	if err != nil {
+		return nil, res, err
+	}
+	return convertComment(threadID, out), res, nil
+}
+
+func (s *pullService) DeleteComment(ctx context.Context, repo string, number, id int) (*scm.Response, error) {
+	threadID, commentID := SplitCommentID(id)
+	return s.client.do(ctx, http.MethodDelete, withQuery(repoPath(repo, "pullRequests", strconv.Itoa(number), "threads", strconv.Itoa(threadID), "comments", strconv.Itoa(commentID)), nil), nil, nil)
+}
+
+func (s *pullService) ListLabels(ctx context.Context, repo string, number int, _ scm.ListOptions) ([]*scm.Label, *scm.Response, error) {
+	var out []*label
+	res, err := s.client.doList(ctx, withQuery(repoPath(repo, "pullRequests", strconv.Itoa(number), "labels"), nil), &out)
+	if err != nil {
+		return nil, res, err
+	}
+	var answer []*scm.Label
+	for _, l := range out {
+		answer = append(answer, &scm.Label{Name: l.Name})
+	}
+	return answer, res, nil
+}
+
+func (s *pullService) AddLabel(ctx context.Context, repo string, number int, name string) (*scm.Response, error) {
+	return s.client.do(ctx, http.MethodPost, withQuery(repoPath(repo, "pullRequests", strconv.Itoa(number), "labels"), nil), &label{Name: name}, nil)
+}
+
+func (s *pullService) DeleteLabel(ctx context.Context, repo string, number int, name string) (*scm.Response, error) {
+	return s.client.do(ctx, http.MethodDelete, withQuery(repoPath(repo, "pullRequests", strconv.Itoa(number), "labels", url.PathEscape(name)), nil), nil, nil)
+}
+
+// Merge completes the pull request at the SHA of the options, so that it is not merged if it has been updated since
+func (s *pullService) Merge(ctx context.Context, repo string, number int, options *scm.PullRequestMergeOptions) (*scm.Response, error) {
+	in := &pullRequestUpdate{
+		Status:            "completed",
+		CompletionOptions: &completion{MergeStrategy: "noFastForward"},
+	}
+	if options != nil {
+		if options.SHA != "" {
+			in.LastMergeSourceCommit = &commitRef{CommitID: options.SHA}
+		}
+		in.CompletionOptions.MergeCommitMessage = options.CommitTitle
+		in.CompletionOptions.DeleteSourceBranch = options.DeleteSourceBranch
+		switch options.MergeMethod {
+		case "squash":
+			in.CompletionOptions.MergeStrategy = "squash"
+		case "rebase":
+			in.CompletionOptions.MergeStrategy = "rebase"
+		}
+	}
+	return s.update(ctx, repo, number, in)
+}
+
+// Close abandons the pull request
+func (s *pullService) Close(ctx context.Context, repo string, number int) (*scm.Response, error) {
+	return s.update(ctx, repo, number, &pullRequestUpdate{Status: "abandoned"})
+}
+
+// Reopen reactivates an abandoned pull request
+func (s *pullService) Reopen(ctx context.Context, repo string, number int) (*scm.Response, error) {
+	return s.update(ctx, repo, number, &pullRequestUpdate{Status: "active"})
+}
+
+func (s *pullService) update(ctx context.Context, repo string, number int, in *pullRequestUpdate) (*scm.Response, error) {
+	return s.client.do(ctx, http.MethodPatch, withQuery(repoPath(repo, "pullrequests", strconv.Itoa(number)), nil), in, nil)
+}
+
+func (s *pullService) Update(context.Context, string, int, *scm.PullRequestInput) (*scm.PullRequest, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *pullService) ListEvents(context.Context, string, int, scm.ListOptions) ([]*scm.ListedIssueEvent, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *pullService) AssignIssue(context.Context, string, int, []string) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (s *pullService) UnassignIssue(context.Context, string, int, []string) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (s *pullService) Create(context.Context, string, *scm.PullRequestInput) (*scm.PullRequest, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *pullService) RequestReview(context.Context, string, int, []string) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (s *pullService) UnrequestReview(context.Context, string, int, []string) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (s *pullService) SetMilestone(context.Context, string, int, int) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (s *pullService) ClearMilestone(context.Context, string, int) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+// CommentID returns the ID of a comment of a thread, the thread in the high bits and the comment in the low ones
+func CommentID(threadID, commentID int) int {
+	return threadID<<20 | commentID
+}
+
+// SplitCommentID returns the IDs of the thread and the comment encoded in the ID of a comment
+func SplitCommentID(id int) (int, int) {
+	return id >> 20, id & (1<<20 - 1)
+}
+
+func convertComment(threadID int, from *comment) *scm.Comment {
+	c := &scm.Comment{
+		ID:      CommentID(threadID, from.ID),
+		Body:    from.Content,
+		Created: from.PublishedDate,
+		Updated: from.LastUpdatedDate,
+	}
+	if from.Author != nil {
+		c.Author = *convertIdentity(from.Author)
+	}
+	return c
+}
+
+func convertIdentity(from *identity) *scm.User {
+	return &scm.User{
+		Login:  from.UniqueName,
+		Name:   from.DisplayName,
+		Avatar: from.ImageURL,
+	}
+}
+
+func convertPullRequest(from *pullRequest) *scm.PullRequest {
+	repo := convertRepository(&from.Repository)
+	source := scm.TrimRef(from.SourceRefName)
+	target := scm.TrimRef(from.TargetRefName)
+	pr := &scm.PullRequest{
+		Number: from.PullRequestID,
+		Title:  from.Title,
+		Body:   from.Description,
+		Ref:    fmt.Sprintf("refs/pull/%d/merge", from.PullRequestID),
+		Source: source,
+		Target: target,
+		Fork:   repo.FullName,
+		Base: scm.PullRequestBranch{
+			Ref:  target,
+			Repo: *repo,
+		},
+		Head: scm.PullRequestBranch{
+			Ref:  source,
+			Repo: *repo,
+		},
+		Link:      fmt.Sprintf("%s/pullrequest/%d", repo.Link, from.PullRequestID),
+		Closed:    from.Status != "active",
+		Merged:    from.Status == "completed",
+		Mergeable: from.MergeStatus == "succeeded",
+		Draft:     from.IsDraft,
+		Author:    *convertIdentity(&from.CreatedBy),
+		Created:   from.CreationDate,
+		Updated:   from.CreationDate,
+	}
+	switch {
+	case pr.Merged:
+		pr.State = "merged"
+	case pr.Closed:
+		pr.State = "closed"
+	default:
+		pr.State = "open"
+	}
+	if from.LastMergeSourceCommit != nil {
+		pr.Sha = from.LastMergeSourceCommit.CommitID
+		pr.Head.Sha = pr.Sha
+	}
+	if from.LastMergeTargetCommit != nil {
+		pr.Base.Sha = from.LastMergeTargetCommit.CommitID
+	}
+	if from.LastMergeCommit != nil && pr.Merged {
+		pr.MergeSha = from.LastMergeCommit.CommitID
+	}
+	if from.ClosedDate != nil {
+		pr.Updated = *from.ClosedDate
+	}
+	for _, l := range from.Labels {
+		pr.Labels = append(pr.Labels, &scm.Label{Name: l.Name})
+	}
+	return pr
+}
+
+func hasLabels(pr *scm.PullRequest, names []string) bool {
+	for _, name := range names {
+		found := false
+		for _, l := range pr.Labels {
+			if l.Name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
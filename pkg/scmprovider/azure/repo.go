@@ -0,0 +1,298 @@
+package azure
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// statusGenre is the genre of the statuses reported by Lighthouse, their context name being the one of the job
+const statusGenre = "lighthouse"
+
+type repositoryService struct {
+	client *wrapper
+}
+
+type repository struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Project       project `json:"project"`
+	DefaultBranch string  `json:"defaultBranch"`
+	RemoteURL     string  `json:"remoteUrl"`
+	WebURL        string  `json:"webUrl"`
+	URL           string  `json:"url"`
+}
+
+type project struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Visibility string `json:"visibility"`
+}
+
+type status struct {
+	ID           int           `json:"id,omitempty"`
+	State        string        `json:"state"`
+	Description  string        `json:"description"`
+	Context      statusContext `json:"context"`
+	TargetURL    string        `json:"targetUrl,omitempty"`
+	IterationID  int           `json:"iterationId,omitempty"`
+	CreationDate time.Time     `json:"creationDate,omitempty"`
+}
+
+type statusContext struct {
+	Name  string `json:"name"`
+	Genre string `json:"genre,omitempty"`
+}
+
+func (s *repositoryService) Find(ctx context.Context, repo string) (*scm.Repository, *scm.Response, error) {
+	out := new(repository)
+	res, err := s.client.do(ctx, http.MethodGet, withQuery(repoPath(repo), nil), nil, out)
+	if err != nil {
+		return nil, res, err
+	}
+	return convertRepository(out), res, nil
+}
+
+// CreateStatus reports the status on the active pull request whose source branch is at the ref, for the latest
+// iteration so that a new push invalidates it, otherwise on the commit
+func (s *repositoryService) CreateStatus(ctx context.Context, repo, ref string, input *scm.StatusInput) (*scm.Status, *scm.Response, error) {
+	in := &status{
+		State:       convertState(input.State),
+		Description: input.Desc,
+		Context:     statusContext{Name: input.Label, Genre: statusGenre},
+		TargetURL:   input.Target,
+	}
+	number, it, res, err := s.findIteration(ctx, repo, ref)
+	if err != nil {
+		return nil, res, err
+	}
+	path := repoPath(repo, "commits", ref, "statuses")
+	if it != nil {
+		in.IterationID = it.ID
+		path = repoPath(repo, "pullRequests", strconv.Itoa(number), "statuses")
+	}
+	out := new(status)
+	res, err = s.client.do(ctx, http.MethodPost, withQuery(path, nil), in, out)
+	if err != nil {
+		return nil, res, err
+	}
+	return convertStatus(out), res, nil
+}
+
+// ListStatus returns the statuses of the ref, the most recent first, the ones of the active pull request whose source
+// branch is at the ref if any
+func (s *repositoryService) ListStatus(ctx context.Context, repo, ref string, _ scm.ListOptions) ([]*scm.Status, *scm.Response, error) {
+	number, it, res, err := s.findIteration(ctx, repo, ref)
+	if err != nil {
+		return nil, res, err
+	}
+	var out []*status
+	if it == nil {
+		res, err = s.client.doList(ctx, withQuery(repoPath(repo, "commits", ref, "statuses"), nil), &out)
+	} else {
+		res, err = s.client.doList(ctx, withQuery(repoPath(repo, "pullRequests", strconv.Itoa(number), "statuses"), nil), &out)
+	}
+	if err != nil {
+		return nil, res, err
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].CreationDate.After(out[j].CreationDate)
+	})
+	var answer []*scm.Status
+	for _, st := range out {
+		if it != nil && st.IterationID != 0 && st.IterationID != it.ID {
+			continue
+		}
+		answer = append(answer, convertStatus(st))
+	}
+	return answer, res, nil
+}
+
+// FindCombinedStatus combines the latest status of each context of the ref
+func (s *repositoryService) FindCombinedStatus(ctx context.Context, repo, ref string) (*scm.CombinedStatus, *scm.Response, error) {
+	statuses, res, err := s.ListStatus(ctx, repo, ref, scm.ListOptions{})
+	if err != nil {
+		return nil, res, err
+	}
+	combined := &scm.CombinedStatus{
+		Sha:   ref,
+		State: scm.StateSuccess,
+	}
+	seen := map[string]bool{}
+	for _, st := range statuses {
+		if seen[st.Label] {
+			continue
+		}
+		seen[st.Label] = true
+		combined.Statuses = append(combined.Statuses, st)
+		switch st.State {
+		case scm.StateFailure, scm.StateError, scm.StateCanceled:
+			combined.State = scm.StateFailure
+		case scm.StatePending, scm.StateRunning:
+			if combined.State == scm.StateSuccess {
+				combined.State = scm.StatePending
+			}
+		}
+	}
+	if len(combined.Statuses) == 0 {
+		combined.State = scm.StatePending
+	}
+	return combined, res, nil
+}
+
+// findIteration returns the number and the latest iteration of the active pull request whose source branch is at
+// the SHA, or a nil iteration if there is none
+func (s *repositoryService) findIteration(ctx context.Context, repo, sha string) (int, *iteration, *scm.Response, error) {
+	pulls := &pullService{s.client}
+	prs, res, err := pulls.List(ctx, repo, scm.PullRequestListOptions{Open: true})
+	if err != nil {
+		return 0, nil, res, err
+	}
+	for _, pr := range prs {
+		if pr.Sha != sha {
+			continue
+		}
+		it, res, err := pulls.latestIteration(ctx, repo, pr.Number)
+		return pr.Number, it, res, err
+	}
+	return 0, nil, res, nil
+}
+
+// IsCollaborator returns true as only the members of the project of a repository can access it
+func (s *repositoryService) IsCollaborator(context.Context, string, string) (bool, *scm.Response, error) {
+	return true, nil, nil
+}
+
+// FindUserPermission returns the write permission as only the contributors of a project can comment its pull requests
+func (s *repositoryService) FindUserPermission(context.Context, string, string) (string, *scm.Response, error) {
+	return scm.WritePermission, nil, nil
+}
+
+// ListLabels returns no labels as the labels of the pull requests are created on demand
+func (s *repositoryService) ListLabels(context.Context, string, scm.ListOptions) ([]*scm.Label, *scm.Response, error) {
+	return nil, nil, nil
+}
+
+func (s *repositoryService) FindHook(context.Context, string, string) (*scm.Hook, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) FindPerms(context.Context, string) (*scm.Perm, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) List(context.Context, scm.ListOptions) ([]*scm.Repository, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) ListOrganisation(context.Context, string, scm.ListOptions) ([]*scm.Repository, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) ListUser(context.Context, string, scm.ListOptions) ([]*scm.Repository, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) ListHooks(context.Context, string, scm.ListOptions) ([]*scm.Hook, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) Create(context.Context, *scm.RepositoryInput) (*scm.Repository, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) Fork(context.Context, *scm.RepositoryInput, string) (*scm.Repository, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) CreateHook(context.Context, string, *scm.HookInput) (*scm.Hook, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) DeleteHook(context.Context, string, string) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) AddCollaborator(context.Context, string, string, string) (bool, bool, *scm.Response, error) {
+	return false, false, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) ListCollaborators(context.Context, string, scm.ListOptions) ([]scm.User, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) Delete(context.Context, string) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func convertRepository(from *repository) *scm.Repository {
+	link := from.WebURL
+	if link == "" && from.RemoteURL != "" {
+		// the repositories of the service hooks have no web URL, their remote URL being the same with a user
+		if u, err := url.Parse(from.RemoteURL); err == nil {
+			u.User = nil
+			link = u.String()
+		}
+	}
+	return &scm.Repository{
+		ID:        from.ID,
+		Namespace: from.Project.Name,
+		Name:      from.Name,
+		FullName:  from.Project.Name + "/" + from.Name,
+		Branch:    scm.TrimRef(from.DefaultBranch),
+		Private:   from.Project.Visibility != "public",
+		Clone:     from.RemoteURL,
+		Link:      link,
+	}
+}
+
+func convertStatus(from *status) *scm.Status {
+	label := from.Context.Name
+	if from.Context.Genre != "" && from.Context.Genre != statusGenre {
+		label = from.Context.Genre + "/" + label
+	}
+	return &scm.Status{
+		State:  convertStatusState(from.State),
+		Label:  label,
+		Desc:   from.Description,
+		Target: from.TargetURL,
+		Link:   from.TargetURL,
+	}
+}
+
+func convertState(from scm.State) string {
+	switch from {
+	case scm.StatePending, scm.StateRunning:
+		return "pending"
+	case scm.StateSuccess:
+		return "succeeded"
+	case scm.StateFailure, scm.StateCanceled:
+		return "failed"
+	case scm.StateError:
+		return "error"
+	default:
+		return "notSet"
+	}
+}
+
+func convertStatusState(from string) scm.State {
+	switch from {
+	case "pending":
+		return scm.StatePending
+	case "succeeded":
+		return scm.StateSuccess
+	case "failed":
+		return scm.StateFailure
+	case "error":
+		return scm.StateError
+	case "notApplicable":
+		return scm.StateCanceled
+	default:
+		return scm.StateUnknown
+	}
+}
@@ -0,0 +1,54 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateStatusOnPullRequestIteration(t *testing.T) {
+	var posted map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /myorg/project/_apis/git/repositories/repo/pullrequests":
+			assert.Equal(t, "active", r.URL.Query().Get("searchCriteria.status"))
+			writeList(w, []map[string]interface{}{
+				{"pullRequestId": 6, "status": "active", "lastMergeSourceCommit": map[string]string{"commitId": "def"}},
+				{"pullRequestId": 7, "status": "active", "lastMergeSourceCommit": map[string]string{"commitId": "abc"}},
+			})
+		case "GET /myorg/project/_apis/git/repositories/repo/pullRequests/7/iterations":
+			writeList(w, []map[string]interface{}{{"id": 1}, {"id": 3}, {"id": 2}})
+		case "POST /myorg/project/_apis/git/repositories/repo/pullRequests/7/statuses":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&posted))
+			_ = json.NewEncoder(w).Encode(posted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL+"/myorg", "token")
+	require.NoError(t, err)
+	status, _, err := client.Repositories.CreateStatus(context.Background(), "project/repo", "abc", &scm.StatusInput{
+		State:  scm.StateFailure,
+		Label:  "pr-build",
+		Desc:   "Build failed",
+		Target: "https://example.com/build/1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, float64(3), posted["iterationId"], "the status is reported for the latest iteration")
+	assert.Equal(t, "failed", posted["state"])
+	assert.Equal(t, map[string]interface{}{"name": "pr-build", "genre": statusGenre}, posted["context"])
+	assert.Equal(t, scm.StateFailure, status.State)
+	assert.Equal(t, "pr-build", status.Label)
+}
+
+func writeList(w http.ResponseWriter, value interface{}) {
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"value": value})
+}
@@ -0,0 +1,86 @@
+package azure
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// the votes of the reviewers of a pull request
+const (
+	voteApproved               = 10
+	voteApprovedWithSuggestion = 5
+	voteWaitingForAuthor       = -5
+	voteRejected               = -10
+)
+
+// reviewService maps the votes of the reviewers of a pull request onto reviews, Azure Repos having no reviews
+type reviewService struct {
+	client *wrapper
+}
+
+// List returns a review per reviewer who voted, so that approving or rejecting a pull request acts as /approve and
+// /approve cancel respectively
+func (s *reviewService) List(ctx context.Context, repo string, number int, _ scm.ListOptions) ([]*scm.Review, *scm.Response, error) {
+	out := new(pullRequest)
+	res, err := s.client.do(ctx, http.MethodGet, withQuery(repoPath(repo, "pullrequests", strconv.Itoa(number)), nil), nil, out)
+	if err != nil {
+		return nil, res, err
+	}
+	pr := convertPullRequest(out)
+	var answer []*scm.Review
+	for i := range out.Reviewers {
+		if r := convertReview(pr, &out.Reviewers[i]); r.State != "" {
+			answer = append(answer, r)
+		}
+	}
+	return answer, res, nil
+}
+
+func (s *reviewService) Find(context.Context, string, int, int) (*scm.Review, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *reviewService) Create(context.Context, string, int, *scm.ReviewInput) (*scm.Review, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *reviewService) Delete(context.Context, string, int, int) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (s *reviewService) ListComments(context.Context, string, int, int, scm.ListOptions) ([]*scm.ReviewComment, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *reviewService) Update(context.Context, string, int, int, string) (*scm.Review, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *reviewService) Submit(context.Context, string, int, int, *scm.ReviewSubmitInput) (*scm.Review, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *reviewService) Dismiss(context.Context, string, int, int, string) (*scm.Review, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+// convertReview returns the review of the vote of the reviewer, with an empty state if the reviewer has not voted
+func convertReview(pr *scm.PullRequest, from *reviewer) *scm.Review {
+	r := &scm.Review{
+		Sha:    pr.Sha,
+		Link:   pr.Link,
+		Author: *convertIdentity(&from.identity),
+	}
+	switch from.Vote {
+	case voteApproved, voteApprovedWithSuggestion:
+		r.State = scm.ReviewStateApproved
+	case voteRejected:
+		r.State = scm.ReviewStateChangesRequested
+	case voteWaitingForAuthor:
+		r.State = scm.ReviewStateCommented
+	}
+	return r
+}
@@ -0,0 +1,61 @@
+package azure
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+type userService struct {
+	client *wrapper
+}
+
+type connectionData struct {
+	AuthenticatedUser struct {
+		ID                  string `json:"id"`
+		ProviderDisplayName string `json:"providerDisplayName"`
+		Properties          struct {
+			Account struct {
+				Value string `json:"$value"`
+			} `json:"Account"`
+		} `json:"properties"`
+	} `json:"authenticatedUser"`
+}
+
+// Find returns the user authenticated by the token, from the connection data of the organization
+func (s *userService) Find(ctx context.Context) (*scm.User, *scm.Response, error) {
+	out := new(connectionData)
+	res, err := s.client.do(ctx, http.MethodGet, "_apis/connectionData", nil, out)
+	if err != nil {
+		return nil, res, err
+	}
+	return &scm.User{
+		Login: out.AuthenticatedUser.Properties.Account.Value,
+		Name:  out.AuthenticatedUser.ProviderDisplayName,
+	}, res, nil
+}
+
+func (s *userService) CreateToken(context.Context, string, string) (*scm.UserToken, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *userService) DeleteToken(context.Context, int64) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (s *userService) FindEmail(context.Context) (string, *scm.Response, error) {
+	return "", nil, scm.ErrNotSupported
+}
+
+func (s *userService) FindLogin(context.Context, string) (*scm.User, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *userService) ListInvitations(context.Context) ([]*scm.Invitation, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *userService) AcceptInvitation(context.Context, int64) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
@@ -0,0 +1,225 @@
+package azure
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// the event types of the service hooks handled by Lighthouse
+const (
+	eventPullRequestCreated = "git.pullrequest.created"
+	eventPullRequestUpdated = "git.pullrequest.updated"
+	eventPullRequestComment = "ms.vss-code.git-pullrequest-comment-event"
+	eventPush               = "git.push"
+)
+
+type webhookService struct {
+	client *wrapper
+}
+
+type event struct {
+	ID        string          `json:"id"`
+	EventType string          `json:"eventType"`
+	Message   eventMessage    `json:"message"`
+	Resource  json.RawMessage `json:"resource"`
+}
+
+type eventMessage struct {
+	Text string `json:"text"`
+}
+
+type pushResource struct {
+	RefUpdates []refUpdate `json:"refUpdates"`
+	Repository repository  `json:"repository"`
+	PushedBy   identity    `json:"pushedBy"`
+	Commits    []commit    `json:"commits"`
+}
+
+type refUpdate struct {
+	Name        string `json:"name"`
+	OldObjectID string `json:"oldObjectId"`
+	NewObjectID string `json:"newObjectId"`
+}
+
+type commentResource struct {
+	Comment     comment     `json:"comment"`
+	PullRequest pullRequest `json:"pullRequest"`
+}
+
+// Parse parses the payload of a service hook, the secret being the password of its basic authentication
+func (s *webhookService) Parse(req *http.Request, fn scm.SecretFunc) (scm.Webhook, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(req.Body, 10000000))
+	if err != nil {
+		return nil, err
+	}
+	e := new(event)
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil, err
+	}
+
+	var hook scm.Webhook
+	switch e.EventType {
+	case eventPush:
+		hook, err = parsePushHook(e)
+	case eventPullRequestCreated, eventPullRequestUpdated:
+		hook, err = parsePullRequestHook(e)
+	case eventPullRequestComment:
+		hook, err = parseCommentHook(e)
+	default:
+		return nil, scm.UnknownWebhook{Event: e.EventType}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := fn(hook)
+	if err != nil {
+		return hook, err
+	} else if key == "" {
+		return hook, nil
+	}
+	_, password, ok := req.BasicAuth()
+	if !ok || subtle.ConstantTimeCompare([]byte(password), []byte(key)) != 1 {
+		return hook, scm.ErrSignatureInvalid
+	}
+	return hook, nil
+}
+
+func parsePushHook(e *event) (scm.Webhook, error) {
+	from := new(pushResource)
+	if err := json.Unmarshal(e.Resource, from); err != nil {
+		return nil, err
+	}
+	if len(from.RefUpdates) == 0 {
+		return nil, scm.UnknownWebhook{Event: e.EventType}
+	}
+	update := from.RefUpdates[0]
+	hook := &scm.PushHook{
+		Ref:     update.Name,
+		Repo:    *convertRepository(&from.Repository),
+		Before:  update.OldObjectID,
+		After:   update.NewObjectID,
+		Created: isZeroSHA(update.OldObjectID),
+		Deleted: isZeroSHA(update.NewObjectID),
+		Commit:  scm.Commit{Sha: update.NewObjectID},
+		Sender:  *convertIdentity(&from.PushedBy),
+		GUID:    e.ID,
+	}
+	for _, c := range from.Commits {
+		if c.CommitID == update.NewObjectID {
+			hook.Commit.Message = c.Comment
+		}
+		hook.Commits = append(hook.Commits, scm.PushCommit{
+			ID:      c.CommitID,
+			Message: c.Comment,
+		})
+	}
+	return hook, nil
+}
+
+// parsePullRequestHook returns a pull request hook, or a review hook when a reviewer voted
+func parsePullRequestHook(e *event) (scm.Webhook, error) {
+	from := new(pullRequest)
+	if err := json.Unmarshal(e.Resource, from); err != nil {
+		return nil, err
+	}
+	pr := convertPullRequest(from)
+	hook := &scm.PullRequestHook{
+		Action:      scm.ActionOpen,
+		Repo:        pr.Base.Repo,
+		PullRequest: *pr,
+		Sender:      pr.Author,
+		GUID:        e.ID,
+	}
+	if e.EventType == eventPullRequestCreated {
+		return hook, nil
+	}
+
+	// the updates are told apart by the message of the event, which starts with the name of the user
+	text := e.Message.Text
+	switch {
+	case strings.Contains(text, "updated the source branch"):
+		hook.Action = scm.ActionSync
+	case pr.Closed:
+		hook.Action = scm.ActionClose
+	default:
+		if r := findVoter(from, text); r != nil {
+			review := convertReview(pr, r)
+			action := scm.ActionSubmitted
+			if review.State == "" {
+				review.State = scm.ReviewStateDismissed
+				action = scm.ActionDismissed
+			}
+			return &scm.ReviewHook{
+				Action:      action,
+				PullRequest: *pr,
+				Repo:        pr.Base.Repo,
+				Review:      *review,
+				GUID:        e.ID,
+			}, nil
+		}
+		hook.Action = scm.ActionUpdate
+	}
+	return hook, nil
+}
+
+// findVoter returns the reviewer of the pull request who voted according to the message of the event
+func findVoter(from *pullRequest, text string) *reviewer {
+	if !strings.Contains(text, " voted ") && !strings.Contains(text, " approved ") && !strings.Contains(text, " rejected ") &&
+		!strings.Contains(text, " waiting for the author") && !strings.Contains(text, " reset ") {
+		return nil
+	}
+	for i := range from.Reviewers {
+		r := &from.Reviewers[i]
+		if r.DisplayName != "" && strings.HasPrefix(text, r.DisplayName+" ") {
+			return r
+		}
+	}
+	return nil
+}
+
+func parseCommentHook(e *event) (scm.Webhook, error) {
+	from := new(commentResource)
+	if err := json.Unmarshal(e.Resource, from); err != nil {
+		return nil, err
+	}
+	threadID := 0
+	if from.Comment.Links != nil {
+		threadID = threadIDFromLink(from.Comment.Links.Threads.Href)
+		if threadID == 0 {
+			threadID = threadIDFromLink(from.Comment.Links.Self.Href)
+		}
+	}
+	pr := convertPullRequest(&from.PullRequest)
+	c := convertComment(threadID, &from.Comment)
+	return &scm.PullRequestCommentHook{
+		Action:      scm.ActionCreate,
+		Repo:        pr.Base.Repo,
+		PullRequest: *pr,
+		Comment:     *c,
+		Sender:      c.Author,
+	}, nil
+}
+
+// threadIDFromLink returns the ID of the thread of a link such as .../pullRequests/1/threads/5/comments/1
+func threadIDFromLink(href string) int {
+	elements := strings.Split(strings.TrimSuffix(href, "/"), "/")
+	for i := 0; i < len(elements)-1; i++ {
+		if elements[i] == "threads" {
+			id, _ := strconv.Atoi(elements[i+1])
+			return id
+		}
+	}
+	return 0
+}
+
+func isZeroSHA(sha string) bool {
+	return strings.Trim(sha, "0") == ""
+}
@@ -0,0 +1,152 @@
+package azure
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePullRequestUpdated(t *testing.T) {
+	resource := map[string]interface{}{
+		"pullRequestId": 7,
+		"status":        "active",
+		"repository": map[string]interface{}{
+			"name":      "repo",
+			"project":   map[string]string{"name": "project"},
+			"remoteUrl": "https://myorg@dev.azure.com/myorg/project/_git/repo",
+		},
+		"sourceRefName":         "refs/heads/feature",
+		"targetRefName":         "refs/heads/master",
+		"lastMergeSourceCommit": map[string]string{"commitId": "abc"},
+		"reviewers": []map[string]interface{}{
+			{"displayName": "Bob Smith", "uniqueName": "bob@example.com", "vote": 0},
+			{"displayName": "Jane Doe", "uniqueName": "jane@example.com", "vote": 10},
+		},
+	}
+	tests := []struct {
+		name   string
+		text   string
+		status string
+		check  func(t *testing.T, hook scm.Webhook)
+	}{
+		{
+			name: "source branch updated",
+			text: "Jane Doe updated the source branch of pull request 7 (Add a feature)",
+			check: func(t *testing.T, hook scm.Webhook) {
+				pr := hook.(*scm.PullRequestHook)
+				assert.Equal(t, scm.ActionSync, pr.Action)
+				assert.Equal(t, "abc", pr.PullRequest.Sha)
+				assert.Equal(t, "project/repo", pr.Repo.FullName)
+				assert.Equal(t, "https://dev.azure.com/myorg/project/_git/repo/pullrequest/7", pr.PullRequest.Link)
+			},
+		},
+		{
+			name: "approved",
+			text: "Jane Doe approved pull request 7 (Add a feature)",
+			check: func(t *testing.T, hook scm.Webhook) {
+				review := hook.(*scm.ReviewHook)
+				assert.Equal(t, scm.ActionSubmitted, review.Action)
+				assert.Equal(t, scm.ReviewStateApproved, review.Review.State)
+				assert.Equal(t, "jane@example.com", review.Review.Author.Login)
+				assert.Equal(t, 7, review.PullRequest.Number)
+			},
+		},
+		{
+			name: "vote reset",
+			text: "Bob Smith reset their vote on pull request 7 (Add a feature)",
+			check: func(t *testing.T, hook scm.Webhook) {
+				review := hook.(*scm.ReviewHook)
+				assert.Equal(t, scm.ActionDismissed, review.Action)
+				assert.Equal(t, "bob@example.com", review.Review.Author.Login)
+			},
+		},
+		{
+			name:   "completed",
+			text:   "Jane Doe completed pull request 7 (Add a feature)",
+			status: "completed",
+			check: func(t *testing.T, hook scm.Webhook) {
+				pr := hook.(*scm.PullRequestHook)
+				assert.Equal(t, scm.ActionClose, pr.Action)
+				assert.True(t, pr.PullRequest.Merged)
+			},
+		},
+		{
+			name: "title edited",
+			text: "Jane Doe updated pull request 7 (Add a feature)",
+			check: func(t *testing.T, hook scm.Webhook) {
+				pr := hook.(*scm.PullRequestHook)
+				assert.Equal(t, scm.ActionUpdate, pr.Action)
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resource["status"] = "active"
+			if tc.status != "" {
+				resource["status"] = tc.status
+			}
+			hook, err := parse(t, map[string]interface{}{
+				"eventType": eventPullRequestUpdated,
+				"message":   map[string]string{"text": tc.text},
+				"resource":  resource,
+			}, "secret")
+			require.NoError(t, err)
+			tc.check(t, hook)
+		})
+	}
+}
+
+func TestParseComment(t *testing.T) {
+	hook, err := parse(t, map[string]interface{}{
+		"eventType": eventPullRequestComment,
+		"resource": map[string]interface{}{
+			"comment": map[string]interface{}{
+				"id":      2,
+				"content": "/retest",
+				"author":  map[string]string{"uniqueName": "jane@example.com"},
+				"_links": map[string]interface{}{
+					"self": map[string]string{"href": "https://dev.azure.com/myorg/_apis/git/repositories/1/pullRequests/7/threads/5/comments/2"},
+				},
+			},
+			"pullRequest": map[string]interface{}{
+				"pullRequestId": 7,
+				"status":        "active",
+				"repository":    map[string]interface{}{"name": "repo", "project": map[string]string{"name": "project"}},
+			},
+		},
+	}, "secret")
+	require.NoError(t, err)
+	comment := hook.(*scm.PullRequestCommentHook)
+	assert.Equal(t, "/retest", comment.Comment.Body)
+	assert.Equal(t, "jane@example.com", comment.Sender.Login)
+	assert.Equal(t, 7, comment.PullRequest.Number)
+	threadID, commentID := SplitCommentID(comment.Comment.ID)
+	assert.Equal(t, 5, threadID)
+	assert.Equal(t, 2, commentID)
+}
+
+func TestParseInvalidSecret(t *testing.T) {
+	_, err := parse(t, map[string]interface{}{
+		"eventType": eventPush,
+		"resource": map[string]interface{}{
+			"refUpdates": []map[string]string{{"name": "refs/heads/master", "newObjectId": "abc"}},
+		},
+	}, "wrong")
+	assert.Equal(t, scm.ErrSignatureInvalid, err)
+}
+
+func parse(t *testing.T, payload interface{}, password string) (scm.Webhook, error) {
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, "http://lighthouse/hook", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.SetBasicAuth("", password)
+	return (&webhookService{}).Parse(req, func(scm.Webhook) (string, error) {
+		return "secret", nil
+	})
+}
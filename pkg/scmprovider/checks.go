@@ -0,0 +1,187 @@
+package scmprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/pkg/errors"
+)
+
+const (
+	// CheckRunStatusQueued is the status of a check run which has not started yet
+	CheckRunStatusQueued = "queued"
+	// CheckRunStatusInProgress is the status of a running check run
+	CheckRunStatusInProgress = "in_progress"
+	// CheckRunStatusCompleted is the status of a finished check run, which will have a conclusion
+	CheckRunStatusCompleted = "completed"
+
+	// CheckRunConclusionSuccess is the conclusion of a successful check run
+	CheckRunConclusionSuccess = "success"
+	// CheckRunConclusionFailure is the conclusion of a failed check run
+	CheckRunConclusionFailure = "failure"
+	// CheckRunConclusionCancelled is the conclusion of an aborted check run
+	CheckRunConclusionCancelled = "cancelled"
+	// CheckRunConclusionNeutral is the conclusion of a check run which neither passed nor failed
+	CheckRunConclusionNeutral = "neutral"
+	// CheckRunConclusionSkipped is the conclusion of a check run which did not need to run
+	CheckRunConclusionSkipped = "skipped"
+
+	// AnnotationLevelNotice is the level of an informational annotation
+	AnnotationLevelNotice = "notice"
+	// AnnotationLevelWarning is the level of a warning annotation
+	AnnotationLevelWarning = "warning"
+	// AnnotationLevelFailure is the level of an annotation for a failure
+	AnnotationLevelFailure = "failure"
+
+	// MaxCheckRunAnnotations is the maximum number of annotations GitHub accepts in a single request
+	MaxCheckRunAnnotations = 50
+
+	checkRunsMediaType = "application/vnd.github.antiope-preview+json"
+)
+
+// CheckRun represents a GitHub check run
+type CheckRun struct {
+	ID          int64           `json:"id,omitempty"`
+	Name        string          `json:"name,omitempty"`
+	HeadSHA     string          `json:"head_sha,omitempty"`
+	DetailsURL  string          `json:"details_url,omitempty"`
+	ExternalID  string          `json:"external_id,omitempty"`
+	Status      string          `json:"status,omitempty"`
+	Conclusion  string          `json:"conclusion,omitempty"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+	Output      *CheckRunOutput `json:"output,omitempty"`
+}
+
+// CheckRunOutput is the rich output of a check run, rendered on the checks tab of a pull request
+type CheckRunOutput struct {
+	Title       string               `json:"title,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Text        string               `json:"text,omitempty"`
+	Annotations []CheckRunAnnotation `json:"annotations,omitempty"`
+}
+
+// CheckRunAnnotation attaches a message to a line range of a file in the commit being checked
+type CheckRunAnnotation struct {
+	Path            string `json:"path"`
+	StartLine       int    `json:"start_line"`
+	EndLine         int    `json:"end_line"`
+	AnnotationLevel string `json:"annotation_level"`
+	Message         string `json:"message"`
+	Title           string `json:"title,omitempty"`
+	RawDetails      string `json:"raw_details,omitempty"`
+}
+
+type checkRunList struct {
+	TotalCount int         `json:"total_count"`
+	CheckRuns  []*CheckRun `json:"check_runs"`
+}
+
+// SupportsCheckRuns returns true if the underlying provider supports check runs.
+// Currently, that means it has to be GitHub.
+func (c *Client) SupportsCheckRuns() bool {
+	return c.client.Driver == scm.DriverGithub
+}
+
+// ListCheckRuns lists the check runs with the given name for a ref, or all of them if the name is empty
+func (c *Client) ListCheckRuns(owner, repo, ref, name string) ([]*CheckRun, error) {
+	query := url.Values{"per_page": []string{"100"}}
+	if name != "" {
+		query.Set("check_name", name)
+	}
+	var answer []*CheckRun
+	for page := 1; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+		path := fmt.Sprintf("repos/%s/commits/%s/check-runs?%s", c.repositoryName(owner, repo), ref, query.Encode())
+		out := &checkRunList{}
+		if err := c.doCheckRunRequest(http.MethodGet, path, nil, out); err != nil {
+			return nil, err
+		}
+		answer = append(answer, out.CheckRuns...)
+		if len(out.CheckRuns) == 0 || len(answer) >= out.TotalCount {
+			return answer, nil
+		}
+	}
+}
+
+// State returns the commit status state equivalent to the status and conclusion of the check run, as reported for
+// the same job as a commit status
+func (r *CheckRun) State() scm.State {
+	if r.Status != CheckRunStatusCompleted {
+		return scm.StatePending
+	}
+	switch r.Conclusion {
+	case CheckRunConclusionSuccess, CheckRunConclusionNeutral, CheckRunConclusionSkipped:
+		return scm.StateSuccess
+	case CheckRunConclusionCancelled:
+		return scm.StateError
+	default:
+		return scm.StateFailure
+	}
+}
+
+// CreateCheckRun creates a new check run
+func (c *Client) CreateCheckRun(owner, repo string, run *CheckRun) (*CheckRun, error) {
+	path := fmt.Sprintf("repos/%s/check-runs", c.repositoryName(owner, repo))
+	out := &CheckRun{}
+	if err := c.doCheckRunRequest(http.MethodPost, path, run, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateCheckRun updates an existing check run, identified by its ID
+func (c *Client) UpdateCheckRun(owner, repo string, run *CheckRun) (*CheckRun, error) {
+	path := fmt.Sprintf("repos/%s/check-runs/%d", c.repositoryName(owner, repo), run.ID)
+	out := &CheckRun{}
+	if err := c.doCheckRunRequest(http.MethodPatch, path, run, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// doCheckRunRequest performs a raw request against the checks API, since go-scm does not expose it
+func (c *Client) doCheckRunRequest(method, path string, in, out interface{}) error {
+	if !c.SupportsCheckRuns() {
+		return errors.Errorf("check runs are not supported by provider %s", c.ProviderType())
+	}
+	return c.doRawRequest(method, path, checkRunsMediaType, in, out)
+}
+
+// doRawRequest performs a raw request encoding and decoding the JSON bodies, for the APIs go-scm does not expose
+func (c *Client) doRawRequest(method, path, mediaType string, in, out interface{}) error {
+	req := &scm.Request{
+		Method: method,
+		Path:   path,
+		Header: http.Header{"Accept": []string{mediaType}},
+	}
+	if in != nil {
+		buf := new(bytes.Buffer)
+		if err := json.NewEncoder(buf).Encode(in); err != nil {
+			return errors.Wrapf(err, "failed to encode the body of %s %s", method, path)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Body = buf
+	}
+	res, err := c.client.Do(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.Status >= http.StatusMultipleChoices {
+		body, _ := ioutil.ReadAll(res.Body)
+		return errors.Errorf("%s %s returned status %d: %s", method, path, res.Status, string(body))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
@@ -7,6 +7,9 @@ import (
 	"os"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider/azure"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -15,6 +18,23 @@ func ToClient(client *scm.Client, botName string) *Client {
 	return &Client{client: client, botName: botName}
 }
 
+// NewSCMClient creates the go-scm client of the git kind, falling back to the drivers of Lighthouse for the kinds
+// go-scm does not support
+func NewSCMClient(kind, serverURL, token string) (*scm.Client, error) {
+	if kind == azure.Name {
+		return azure.NewClient(serverURL, token)
+	}
+	return factory.NewClient(kind, serverURL, token)
+}
+
+// DriverName returns the git kind of the driver of a go-scm client
+func DriverName(driver scm.Driver) string {
+	if driver == azure.Driver {
+		return azure.Name
+	}
+	return driver.String()
+}
+
 // SCMClient is an interface providing all functions on the Client struct.
 type SCMClient interface {
 	// Functions implemented in client.go
@@ -27,6 +47,12 @@ type SCMClient interface {
 	ServerURL() *url.URL
 	QuoteAuthorForComment(string) string
 
+	// Functions implemented in checks.go
+	SupportsCheckRuns() bool
+	ListCheckRuns(string, string, string, string) ([]*CheckRun, error)
+	CreateCheckRun(string, string, *CheckRun) (*CheckRun, error)
+	UpdateCheckRun(string, string, *CheckRun) (*CheckRun, error)
+
 	// Functions implemented in content.go
 	GetFile(string, string, string, string) ([]byte, error)
 	ListFiles(string, string, string, string) ([]*scm.FileEntry, error)
@@ -35,6 +61,7 @@ type SCMClient interface {
 	GetRef(string, string, string) (string, error)
 	DeleteRef(string, string, string) error
 	GetSingleCommit(string, string, string) (*scm.Commit, error)
+	ListCommits(string, string, scm.CommitListOptions) ([]*scm.Commit, error)
 
 	// Functions implemented in issues.go
 	Query(context.Context, interface{}, map[string]interface{}) error
@@ -45,10 +72,10 @@ type SCMClient interface {
 	AddLabel(string, string, int, string, bool) error
 	RemoveLabel(string, string, int, string, bool) error
 	DeleteComment(string, string, int, int, bool) error
-	DeleteStaleComments(string, string, int, []*scm.Comment, bool, func(*scm.Comment) bool) error
 	ListIssueComments(string, string, int) ([]*scm.Comment, error)
 	GetIssueLabels(string, string, int, bool) ([]*scm.Label, error)
 	CreateComment(string, string, int, bool, string) error
+	CreateIssue(string, string, string, string) (*scm.Issue, error)
 	ReopenIssue(string, string, int) error
 	FindIssues(string, string, bool) ([]scm.Issue, error)
 	CloseIssue(string, string, int) error
@@ -69,6 +96,7 @@ type SCMClient interface {
 	ClosePR(string, string, int) error
 	ListAllPullRequestsForFullNameRepo(string, scm.PullRequestListOptions) ([]*scm.PullRequest, error)
 	FindPullRequestsByAuthor(string, string, string) ([]*scm.PullRequest, error)
+	ListPullRequestCommits(string, string, int) ([]*scm.Commit, error)
 
 	// Functions implemented in repositories.go
 	GetRepoLabels(string, string) ([]*scm.Label, error)
@@ -87,6 +115,9 @@ type SCMClient interface {
 	ListReviews(string, string, int) ([]*scm.Review, error)
 	RequestReview(string, string, int, []string) error
 	UnrequestReview(string, string, int, []string) error
+	SupportsReviewComments() bool
+	ListReviewComments(string, string, int) ([]*ReviewComment, error)
+	CreateReviewComments(string, string, int, string, string, []*ReviewComment) error
 
 	// Functions implemented in milestones.go
 	ClearMilestone(string, string, int, bool) error
@@ -98,6 +129,30 @@ type SCMClient interface {
 type Client struct {
 	client  *scm.Client
 	botName string
+
+	// auditEventGUID and auditReason are recorded in the audit log of the changes made by the client
+	auditEventGUID string
+	auditReason    string
+}
+
+// SetAuditContext sets the webhook event and the reason, e.g. the plugin handling the event, recorded in the audit
+// log of the changes made by the client
+func (c *Client) SetAuditContext(eventGUID, reason string) {
+	c.auditEventGUID = eventGUID
+	c.auditReason = reason
+}
+
+// audit records a change made by the bot in the audit log
+func (c *Client) audit(entry audit.Entry) {
+	if !audit.Enabled() {
+		return
+	}
+	entry.Actor, _ = c.BotName()
+	entry.EventGUID = c.auditEventGUID
+	if entry.Reason == "" {
+		entry.Reason = c.auditReason
+	}
+	audit.Record(entry)
 }
 
 // ToScmClient gets the underlying SCM client
@@ -149,7 +204,7 @@ func (c *Client) SupportsGraphQL() bool {
 
 // ProviderType returns the type of the underlying SCM provider
 func (c *Client) ProviderType() string {
-	return c.client.Driver.String()
+	return DriverName(c.client.Driver)
 }
 
 // PRRefFmt returns the "refs/(something)/%d/(something)" sprintf format used for constructing PR refs for this provider
@@ -159,6 +214,8 @@ func (c *Client) PRRefFmt() string {
 		return "refs/pull-requests/%d/from"
 	case scm.DriverGitlab:
 		return "refs/merge-requests/%d/head"
+	case azure.Driver:
+		return "refs/pull/%d/merge"
 	default:
 		return "refs/pull/%d/head"
 	}
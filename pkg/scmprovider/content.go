@@ -2,6 +2,11 @@ package scmprovider
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
 )
@@ -27,5 +32,47 @@ func (c *Client) ListFiles(owner, repo, filepath, commit string) ([]*scm.FileEnt
 	ctx := context.Background()
 	fullName := c.repositoryName(owner, repo)
 	answer, _, err := c.client.Contents.List(ctx, fullName, filepath, commit)
+	if err == scm.ErrNotSupported && c.client.Driver == scm.DriverGitea {
+		return c.listGiteaFiles(ctx, fullName, filepath, commit)
+	}
 	return answer, err
 }
+
+// listGiteaFiles lists the files with the contents API of Gitea as the go-scm driver does not support it
+func (c *Client) listGiteaFiles(ctx context.Context, fullName, filepath, commit string) ([]*scm.FileEntry, error) {
+	req := &scm.Request{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("api/v1/repos/%s/contents/%s?ref=%s", fullName, strings.Trim(filepath, "/"), url.QueryEscape(commit)),
+	}
+	res, err := c.client.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.Status > 299 {
+		return nil, fmt.Errorf("failed to list the files in %s of %s at %s: status %d", filepath, fullName, commit, res.Status)
+	}
+	var entries []struct {
+		Name    string `json:"name"`
+		Path    string `json:"path"`
+		Type    string `json:"type"`
+		Size    int    `json:"size"`
+		Sha     string `json:"sha"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&entries); err != nil {
+		return nil, err
+	}
+	var answer []*scm.FileEntry
+	for _, e := range entries {
+		answer = append(answer, &scm.FileEntry{
+			Name: e.Name,
+			Path: e.Path,
+			Type: e.Type,
+			Size: e.Size,
+			Sha:  e.Sha,
+			Link: e.HTMLURL,
+		})
+	}
+	return answer, nil
+}
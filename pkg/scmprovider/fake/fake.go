@@ -48,10 +48,21 @@ type SCMClient struct {
 	PullRequestComments map[int][]*scm.Comment
 	ReviewID            int
 	Reviews             map[int][]*scm.Review
-	CombinedStatuses    map[string]*scm.CombinedStatus
-	CreatedStatuses     map[string][]*scm.StatusInput
-	IssueEvents         map[int][]*scm.ListedIssueEvent
-	Commits             map[string]*scm.Commit
+	// ReviewComments maps pull request numbers to their inline review comments
+	ReviewComments map[int][]*scmprovider.ReviewComment
+	// UnresolvedDiscussions maps pull request numbers to whether they have unresolved discussions
+	UnresolvedDiscussions map[int]bool
+	CombinedStatuses      map[string]*scm.CombinedStatus
+	CreatedStatuses       map[string][]*scm.StatusInput
+	IssueEvents           map[int][]*scm.ListedIssueEvent
+	Commits               map[string]*scm.Commit
+	// RepoCommits maps "org/repo" to the commits on its default branch, newest first
+	RepoCommits map[string][]*scm.Commit
+	// PullRequestCommits maps pull request numbers to their commits, oldest first
+	PullRequestCommits map[int][]*scm.Commit
+	// CheckRuns are keyed by head SHA
+	CheckRuns  map[string][]*scmprovider.CheckRun
+	CheckRunID int64
 
 	//All Labels That Exist In The Repo
 	RepoLabelsExisting []string
@@ -69,6 +80,9 @@ type SCMClient struct {
 	// org/repo#issuecommentid
 	IssueCommentsDeleted       []string
 	PullRequestCommentsDeleted []string
+	// org/repo#issuecommentid:body
+	IssueCommentsEdited       []string
+	PullRequestCommentsEdited []string
 
 	// org/repo#issuecommentid:reaction
 	IssueReactionsAdded   []string
@@ -143,6 +157,11 @@ func (f *SCMClient) ListReviews(owner, repo string, number int) ([]*scm.Review,
 	return append([]*scm.Review{}, f.Reviews[number]...), nil
 }
 
+// HasUnresolvedDiscussions returns whether the pull request has unresolved discussions
+func (f *SCMClient) HasUnresolvedDiscussions(owner, repo string, number int) (bool, error) {
+	return f.UnresolvedDiscussions[number], nil
+}
+
 // ListIssueEvents returns issue events
 func (f *SCMClient) ListIssueEvents(owner, repo string, number int) ([]*scm.ListedIssueEvent, error) {
 	return append([]*scm.ListedIssueEvent{}, f.IssueEvents[number]...), nil
@@ -180,6 +199,39 @@ func (f *SCMClient) CreateReview(org, repo string, number int, r scmprovider.Dra
 	return nil
 }
 
+// SupportsReviewComments returns whether the provider supports inline review comments
+func (f *SCMClient) SupportsReviewComments() bool {
+	return true
+}
+
+// ListReviewComments returns the inline review comments of a PR
+func (f *SCMClient) ListReviewComments(org, repo string, number int) ([]*scmprovider.ReviewComment, error) {
+	return append([]*scmprovider.ReviewComment{}, f.ReviewComments[number]...), nil
+}
+
+// CreateReviewComments adds a review with inline comments to a PR
+func (f *SCMClient) CreateReviewComments(org, repo string, number int, sha, body string, comments []*scmprovider.ReviewComment) error {
+	if f.Reviews == nil {
+		f.Reviews = make(map[int][]*scm.Review)
+	}
+	if f.ReviewComments == nil {
+		f.ReviewComments = make(map[int][]*scmprovider.ReviewComment)
+	}
+	f.Reviews[number] = append(f.Reviews[number], &scm.Review{
+		ID:     f.ReviewID,
+		Author: scm.User{Login: botName},
+		Body:   body,
+		Sha:    sha,
+	})
+	f.ReviewID++
+	for _, comment := range comments {
+		created := *comment
+		created.User = &scmprovider.ReviewCommentUser{Login: botName}
+		f.ReviewComments[number] = append(f.ReviewComments[number], &created)
+	}
+	return nil
+}
+
 // CreateCommentReaction adds emoji to a comment.
 func (f *SCMClient) CreateCommentReaction(org, repo string, ID int, reaction string) error {
 	f.CommentReactionsAdded = append(f.CommentReactionsAdded, fmt.Sprintf("%s/%s#%d:%s", org, repo, ID, reaction))
@@ -219,23 +271,22 @@ func (f *SCMClient) DeleteComment(owner, repo string, number, ID int, pr bool) e
 	return fmt.Errorf("could not find issue comment %d", ID)
 }
 
-// DeleteStaleComments deletes comments flagged by isStale.
-func (f *SCMClient) DeleteStaleComments(org, repo string, number int, comments []*scm.Comment, pr bool, isStale func(*scm.Comment) bool) error {
-	if comments == nil {
-		if pr {
-			comments, _ = f.ListPullRequestComments(org, repo, number)
-		} else {
-			comments, _ = f.ListIssueComments(org, repo, number)
-		}
+// EditComment edits a comment.
+func (f *SCMClient) EditComment(owner, repo string, number, ID int, comment string, pr bool) error {
+	comments := f.IssueComments
+	if pr {
+		f.PullRequestCommentsEdited = append(f.PullRequestCommentsEdited, fmt.Sprintf("%s/%s#%d:%s", owner, repo, ID, comment))
+		comments = f.PullRequestComments
+	} else {
+		f.IssueCommentsEdited = append(f.IssueCommentsEdited, fmt.Sprintf("%s/%s#%d:%s", owner, repo, ID, comment))
 	}
-	for _, comment := range comments {
-		if isStale(comment) {
-			if err := f.DeleteComment(org, repo, number, comment.ID, pr); err != nil {
-				return fmt.Errorf("failed to delete stale comment with ID '%d'", comment.ID)
-			}
+	for _, ic := range comments[number] {
+		if ic.ID == ID {
+			ic.Body = comment
+			return nil
 		}
 	}
-	return nil
+	return fmt.Errorf("could not find comment %d", ID)
 }
 
 // GetPullRequest returns details about the PR.
@@ -268,6 +319,20 @@ func (f *SCMClient) GetSingleCommit(org, repo, SHA string) (*scm.Commit, error)
 	return f.Commits[SHA], nil
 }
 
+// ListCommits returns the commits of the repository, newest first, limited to the page size in the options.
+func (f *SCMClient) ListCommits(org, repo string, opts scm.CommitListOptions) ([]*scm.Commit, error) {
+	commits := f.RepoCommits[org+"/"+repo]
+	if opts.Size > 0 && len(commits) > opts.Size {
+		commits = commits[:opts.Size]
+	}
+	return commits, nil
+}
+
+// ListPullRequestCommits returns the commits of a pull request.
+func (f *SCMClient) ListPullRequestCommits(org, repo string, number int) ([]*scm.Commit, error) {
+	return f.PullRequestCommits[number], nil
+}
+
 // CreateStatus adds a status context to a commit.
 func (f *SCMClient) CreateStatus(owner, repo, SHA string, s *scm.StatusInput) (*scm.Status, error) {
 	if f.CreatedStatuses == nil {
@@ -288,6 +353,69 @@ func (f *SCMClient) CreateStatus(owner, repo, SHA string, s *scm.StatusInput) (*
 	return nil, nil
 }
 
+// SupportsCheckRuns returns whether the provider supports check runs
+func (f *SCMClient) SupportsCheckRuns() bool {
+	return true
+}
+
+// ListCheckRuns returns the check runs with the given name on a commit, or all of them if the name is empty.
+func (f *SCMClient) ListCheckRuns(org, repo, ref, name string) ([]*scmprovider.CheckRun, error) {
+	var answer []*scmprovider.CheckRun
+	for _, run := range f.CheckRuns[ref] {
+		if name == "" || run.Name == name {
+			answer = append(answer, run)
+		}
+	}
+	return answer, nil
+}
+
+// CreateCheckRun adds a check run to a commit.
+func (f *SCMClient) CreateCheckRun(org, repo string, run *scmprovider.CheckRun) (*scmprovider.CheckRun, error) {
+	if f.CheckRuns == nil {
+		f.CheckRuns = make(map[string][]*scmprovider.CheckRun)
+	}
+	f.CheckRunID++
+	created := *run
+	created.ID = f.CheckRunID
+	f.CheckRuns[run.HeadSHA] = append(f.CheckRuns[run.HeadSHA], &created)
+	return &created, nil
+}
+
+// UpdateCheckRun updates the non-empty fields of an existing check run, appending any annotations.
+func (f *SCMClient) UpdateCheckRun(org, repo string, run *scmprovider.CheckRun) (*scmprovider.CheckRun, error) {
+	for _, runs := range f.CheckRuns {
+		for _, existing := range runs {
+			if existing.ID != run.ID {
+				continue
+			}
+			if run.Name != "" {
+				existing.Name = run.Name
+			}
+			if run.Status != "" {
+				existing.Status = run.Status
+			}
+			if run.Conclusion != "" {
+				existing.Conclusion = run.Conclusion
+			}
+			if run.DetailsURL != "" {
+				existing.DetailsURL = run.DetailsURL
+			}
+			if run.CompletedAt != nil {
+				existing.CompletedAt = run.CompletedAt
+			}
+			if run.Output != nil {
+				output := *run.Output
+				if existing.Output != nil {
+					output.Annotations = append(existing.Output.Annotations, output.Annotations...)
+				}
+				existing.Output = &output
+			}
+			return existing, nil
+		}
+	}
+	return nil, fmt.Errorf("check run %d not found", run.ID)
+}
+
 // ListStatuses returns individual status contexts on a commit.
 func (f *SCMClient) ListStatuses(org, repo, ref string) ([]*scm.Status, error) {
 	return scm.ConvertStatusInputsToStatuses(f.CreatedStatuses[ref]), nil
@@ -382,6 +510,33 @@ func (f *SCMClient) RemoveLabel(owner, repo string, number int, label string, pr
 	return fmt.Errorf("cannot remove %v from %s/%s/#%d", label, owner, repo, number)
 }
 
+// CreateIssue creates an issue numbered after the existing ones.
+func (f *SCMClient) CreateIssue(owner, repo, title, body string) (*scm.Issue, error) {
+	if f.Issues == nil {
+		f.Issues = make(map[int][]*scm.Issue)
+	}
+	issue := &scm.Issue{
+		Number: len(f.Issues) + 1,
+		Title:  title,
+		Body:   body,
+		Author: scm.User{Login: botName},
+	}
+	f.Issues[issue.Number] = []*scm.Issue{issue}
+	return issue, nil
+}
+
+// CloseIssue closes an issue.
+func (f *SCMClient) CloseIssue(owner, repo string, number int) error {
+	issues, ok := f.Issues[number]
+	if !ok {
+		return fmt.Errorf("issue %s/%s#%d not found", owner, repo, number)
+	}
+	for _, issue := range issues {
+		issue.Closed = true
+	}
+	return nil
+}
+
 // FindIssues returns f.Issues
 func (f *SCMClient) FindIssues(query, sort string, asc bool) ([]scm.Issue, error) {
 	var issues []scm.Issue
@@ -29,3 +29,11 @@ func (c *Client) GetSingleCommit(owner, repo, SHA string) (*scm.Commit, error) {
 	commit, _, err := c.client.Git.FindCommit(ctx, fullName, SHA)
 	return commit, err
 }
+
+// ListCommits returns the commits of the repository, newest first, starting from the ref or SHA in the options
+func (c *Client) ListCommits(owner, repo string, opts scm.CommitListOptions) ([]*scm.Commit, error) {
+	ctx := context.Background()
+	fullName := c.repositoryName(owner, repo)
+	commits, _, err := c.client.Git.ListCommits(ctx, fullName, opts)
+	return commits, err
+}
@@ -3,11 +3,11 @@ package scmprovider
 import (
 	"bytes"
 	"context"
-	"fmt"
 	"io"
 	"strconv"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
@@ -105,14 +105,19 @@ func (c *Client) UnassignIssue(owner, repo string, number int, logins []string)
 func (c *Client) AddLabel(owner, repo string, number int, label string, pr bool) error {
 	ctx := context.Background()
 	fullName := c.repositoryName(owner, repo)
+	var err error
 	if pr {
 		if !c.SupportsPRLabels() {
-			return AddLabelToComment(c, owner, repo, number, label)
+			err = AddLabelToComment(c, owner, repo, number, label)
+		} else {
+			_, err = c.client.PullRequests.AddLabel(ctx, fullName, number, label)
 		}
-		_, err := c.client.PullRequests.AddLabel(ctx, fullName, number, label)
-		return err
+	} else {
+		_, err = c.client.Issues.AddLabel(ctx, fullName, number, label)
+	}
+	if err == nil {
+		c.audit(audit.Entry{Action: audit.ActionAddLabel, Org: owner, Repo: repo, Number: number, Target: label})
 	}
-	_, err := c.client.Issues.AddLabel(ctx, fullName, number, label)
 	return err
 }
 
@@ -120,14 +125,19 @@ func (c *Client) AddLabel(owner, repo string, number int, label string, pr bool)
 func (c *Client) RemoveLabel(owner, repo string, number int, label string, pr bool) error {
 	ctx := context.Background()
 	fullName := c.repositoryName(owner, repo)
+	var err error
 	if pr {
 		if !c.SupportsPRLabels() {
-			return DeleteLabelFromComment(c, owner, repo, number, label)
+			err = DeleteLabelFromComment(c, owner, repo, number, label)
+		} else {
+			_, err = c.client.PullRequests.DeleteLabel(ctx, fullName, number, label)
 		}
-		_, err := c.client.PullRequests.DeleteLabel(ctx, fullName, number, label)
-		return err
+	} else {
+		_, err = c.client.Issues.DeleteLabel(ctx, fullName, number, label)
+	}
+	if err == nil {
+		c.audit(audit.Entry{Action: audit.ActionRemoveLabel, Org: owner, Repo: repo, Number: number, Target: label})
 	}
-	_, err := c.client.Issues.DeleteLabel(ctx, fullName, number, label)
 	return err
 }
 
@@ -143,30 +153,6 @@ func (c *Client) DeleteComment(org, repo string, number, ID int, pr bool) error
 	return err
 }
 
-// DeleteStaleComments iterates over comments on an issue/PR, deleting those which the 'isStale'
-// function identifies as stale. If 'comments' is nil, the comments will be fetched from GitHub.
-func (c *Client) DeleteStaleComments(org, repo string, number int, comments []*scm.Comment, pr bool, isStale func(*scm.Comment) bool) error {
-	var err error
-	if comments == nil {
-		if pr {
-			comments, err = c.ListPullRequestComments(org, repo, number)
-		} else {
-			comments, err = c.ListIssueComments(org, repo, number)
-		}
-		if err != nil {
-			return fmt.Errorf("failed to list comments while deleting stale comments. err: %v", err)
-		}
-	}
-	for _, comment := range comments {
-		if isStale(comment) {
-			if err := c.DeleteComment(org, repo, number, comment.ID, pr); err != nil {
-				return fmt.Errorf("failed to delete stale comment with ID '%d'", comment.ID)
-			}
-		}
-	}
-	return nil
-}
-
 // ListIssueComments list comments associated with an issue
 func (c *Client) ListIssueComments(org, repo string, number int) ([]*scm.Comment, error) {
 	ctx := context.Background()
@@ -258,6 +244,7 @@ func (c *Client) CreateComment(owner, repo string, number int, pr bool, comment
 			return errors.Wrapf(err, "response: %s", b.String())
 		}
 	}
+	c.audit(audit.Entry{Action: audit.ActionComment, Org: owner, Repo: repo, Number: number, Details: comment})
 	return nil
 }
 
@@ -290,9 +277,18 @@ func (c *Client) EditComment(owner, repo string, number int, id int, comment str
 			return errors.Wrapf(err, "response: %s", b.String())
 		}
 	}
+	c.audit(audit.Entry{Action: audit.ActionComment, Org: owner, Repo: repo, Number: number, Details: comment})
 	return nil
 }
 
+// CreateIssue creates an issue
+func (c *Client) CreateIssue(owner, repo, title, body string) (*scm.Issue, error) {
+	ctx := context.Background()
+	fullName := c.repositoryName(owner, repo)
+	issue, _, err := c.client.Issues.Create(ctx, fullName, &scm.IssueInput{Title: title, Body: body})
+	return issue, err
+}
+
 // ReopenIssue reopen an issue
 func (c *Client) ReopenIssue(owner, repo string, number int) error {
 	ctx := context.Background()
@@ -2,9 +2,14 @@ package scmprovider
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
 	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
 	"github.com/pkg/errors"
 )
 
@@ -146,6 +151,9 @@ func (c *Client) Merge(owner, repo string, number int, details MergeDetails) err
 		MergeMethod: details.MergeMethod,
 	}
 	_, err := c.client.PullRequests.Merge(ctx, fullName, number, mergeOptions)
+	if err == nil {
+		c.audit(audit.Entry{Action: audit.ActionMerge, Org: owner, Repo: repo, Number: number, Ref: details.SHA, Details: fmt.Sprintf("%s merge: %s", details.MergeMethod, details.CommitTitle)})
+	}
 	return err
 }
 
@@ -233,3 +241,62 @@ func (c *Client) UnassignPR(owner, repo string, number int, logins []string) err
 	_, err := c.client.PullRequests.UnassignIssue(ctx, fullName, number, logins)
 	return err
 }
+
+// githubPullRequestCommit is a commit as returned by the GitHub pull request commits API
+type githubPullRequestCommit struct {
+	Sha    string `json:"sha"`
+	Commit struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+		} `json:"author"`
+	} `json:"commit"`
+	Author *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+// ListPullRequestCommits lists the commits of a pull request, oldest first. It performs raw requests as go-scm does
+// not expose this API, so it is only supported for GitHub.
+func (c *Client) ListPullRequestCommits(owner, repo string, number int) ([]*scm.Commit, error) {
+	if c.client.Driver != scm.DriverGithub {
+		return nil, scm.ErrNotSupported
+	}
+	var answer []*scm.Commit
+	for page := 1; ; page++ {
+		path := fmt.Sprintf("repos/%s/pulls/%d/commits?per_page=100&page=%d", c.repositoryName(owner, repo), number, page)
+		res, err := c.client.Do(context.Background(), &scm.Request{Method: http.MethodGet, Path: path})
+		if err != nil {
+			return nil, err
+		}
+		var commits []githubPullRequestCommit
+		if res.Status >= http.StatusMultipleChoices {
+			body, _ := ioutil.ReadAll(res.Body)
+			res.Body.Close()
+			return nil, errors.Errorf("GET %s returned status %d: %s", path, res.Status, string(body))
+		}
+		err = json.NewDecoder(res.Body).Decode(&commits)
+		res.Body.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode the commits of pull request %d", number)
+		}
+		for _, commit := range commits {
+			converted := &scm.Commit{
+				Sha:     commit.Sha,
+				Message: commit.Commit.Message,
+				Author: scm.Signature{
+					Name:  commit.Commit.Author.Name,
+					Email: commit.Commit.Author.Email,
+				},
+			}
+			if commit.Author != nil {
+				converted.Author.Login = commit.Author.Login
+			}
+			answer = append(answer, converted)
+		}
+		if len(commits) < 100 {
+			return answer, nil
+		}
+	}
+}
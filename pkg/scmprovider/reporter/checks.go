@@ -0,0 +1,215 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/testreport"
+)
+
+const maxSummaryFailures = 20
+
+// CheckRunClient provides a client interface to report job results as check runs.
+type CheckRunClient interface {
+	ListCheckRuns(string, string, string, string) ([]*scmprovider.CheckRun, error)
+	CreateCheckRun(string, string, *scmprovider.CheckRun) (*scmprovider.CheckRun, error)
+	UpdateCheckRun(string, string, *scmprovider.CheckRun) (*scmprovider.CheckRun, error)
+}
+
+// ReportCheckRun creates or updates the check run for the provided LighthouseJob on the given commit, adding a summary
+// and per-file annotations for any failed tests in the (optional) test results.
+func ReportCheckRun(c CheckRunClient, lhj *v1alpha1.LighthouseJob, activity *v1alpha1.ActivityRecord, sha string, results *testreport.Summary) error {
+	refs := lhj.Spec.Refs
+	if refs == nil {
+		return fmt.Errorf("cannot report check run for job %s without refs", lhj.Name)
+	}
+	run := toCheckRun(lhj, activity, sha, results)
+
+	existing, err := c.ListCheckRuns(refs.Org, refs.Repo, sha, run.Name)
+	if err != nil {
+		return fmt.Errorf("error listing check runs: %v", err)
+	}
+
+	var annotations []scmprovider.CheckRunAnnotation
+	if run.Output != nil {
+		annotations = run.Output.Annotations
+		run.Output.Annotations = firstAnnotations(annotations)
+	}
+	if len(existing) == 0 {
+		run, err = c.CreateCheckRun(refs.Org, refs.Repo, run)
+		if err != nil {
+			return fmt.Errorf("error creating check run: %v", err)
+		}
+	} else {
+		run.ID = existing[0].ID
+		run.HeadSHA = ""
+		run, err = c.UpdateCheckRun(refs.Org, refs.Repo, run)
+		if err != nil {
+			return fmt.Errorf("error updating check run: %v", err)
+		}
+	}
+
+	// GitHub limits the number of annotations per request, so append any remaining ones with further updates
+	for i := scmprovider.MaxCheckRunAnnotations; i < len(annotations); i += scmprovider.MaxCheckRunAnnotations {
+		update := &scmprovider.CheckRun{
+			ID: run.ID,
+			Output: &scmprovider.CheckRunOutput{
+				Title:       run.Output.Title,
+				Summary:     run.Output.Summary,
+				Annotations: firstAnnotations(annotations[i:]),
+			},
+		}
+		if _, err := c.UpdateCheckRun(refs.Org, refs.Repo, update); err != nil {
+			return fmt.Errorf("error adding annotations to check run: %v", err)
+		}
+	}
+	return nil
+}
+
+func firstAnnotations(annotations []scmprovider.CheckRunAnnotation) []scmprovider.CheckRunAnnotation {
+	if len(annotations) > scmprovider.MaxCheckRunAnnotations {
+		return annotations[:scmprovider.MaxCheckRunAnnotations]
+	}
+	return annotations
+}
+
+func toCheckRun(lhj *v1alpha1.LighthouseJob, activity *v1alpha1.ActivityRecord, sha string, results *testreport.Summary) *scmprovider.CheckRun {
+	run := &scmprovider.CheckRun{
+		Name:       checkRunName(lhj, activity),
+		HeadSHA:    sha,
+		DetailsURL: lhj.Status.ReportURL,
+		ExternalID: lhj.Name,
+	}
+	if activity != nil && activity.StartTime != nil {
+		started := activity.StartTime.Time
+		run.StartedAt = &started
+	}
+
+	var title string
+	switch lhj.Status.State {
+	case v1alpha1.TriggeredState, v1alpha1.PendingState:
+		run.Status = scmprovider.CheckRunStatusQueued
+		title = "Pipeline pending"
+	case v1alpha1.RunningState:
+		run.Status = scmprovider.CheckRunStatusInProgress
+		title = "Pipeline running"
+	default:
+		run.Status = scmprovider.CheckRunStatusCompleted
+		completed := time.Now()
+		if activity != nil && activity.CompletionTime != nil {
+			completed = activity.CompletionTime.Time
+		}
+		run.CompletedAt = &completed
+		switch lhj.Status.State {
+		case v1alpha1.SuccessState:
+			run.Conclusion = scmprovider.CheckRunConclusionSuccess
+			title = "Pipeline successful"
+		case v1alpha1.AbortedState:
+			run.Conclusion = scmprovider.CheckRunConclusionCancelled
+			title = "Pipeline aborted"
+		case v1alpha1.FailureState, v1alpha1.ErrorState:
+			run.Conclusion = scmprovider.CheckRunConclusionFailure
+			title = "Pipeline failed"
+		default:
+			run.Conclusion = scmprovider.CheckRunConclusionNeutral
+			title = "Pipeline in unknown state"
+		}
+	}
+	if results != nil && results.Total > 0 {
+		title = fmt.Sprintf("%s: %d/%d tests passed", title, results.Passed, results.Total)
+	}
+
+	run.Output = &scmprovider.CheckRunOutput{
+		Title:       title,
+		Summary:     checkRunSummary(lhj, activity, results),
+		Annotations: checkRunAnnotations(results),
+	}
+	return run
+}
+
+// checkRunSummary renders the markdown body of a check run
+func checkRunSummary(lhj *v1alpha1.LighthouseJob, activity *v1alpha1.ActivityRecord, results *testreport.Summary) string {
+	var lines []string
+	if activity != nil && len(activity.Stages) > 0 {
+		lines = append(lines, "Stage | Status", "--- | ---")
+		for _, stage := range activity.Stages {
+			lines = append(lines, fmt.Sprintf("%s | %s", stage.Name, stage.Status))
+		}
+		lines = append(lines, "")
+	}
+	if results != nil && results.Total > 0 {
+		lines = append(lines,
+			"Total | Passed | Failed | Skipped",
+			"--- | --- | --- | ---",
+			fmt.Sprintf("%d | %d | %d | %d", results.Total, results.Passed, results.Failed, results.Skipped),
+			"",
+		)
+	}
+	// the findings of analysis reports are failures without tests
+	if results != nil && len(results.Failures) > 0 {
+		lines = append(lines, "**Failures:**", "")
+		for i, f := range results.Failures {
+			if i == maxSummaryFailures {
+				lines = append(lines, fmt.Sprintf("* ...and %d more", len(results.Failures)-maxSummaryFailures))
+				break
+			}
+			lines = append(lines, fmt.Sprintf("* `%s`: %s", testName(f), f.Message))
+		}
+		lines = append(lines, "")
+	}
+	if lhj.Status.ReportURL != "" {
+		lines = append(lines, fmt.Sprintf("[Pipeline details](%s)", lhj.Status.ReportURL))
+	}
+	if lhj.Spec.RerunCommand != "" {
+		lines = append(lines, "", fmt.Sprintf("Rerun command: `%s`", lhj.Spec.RerunCommand))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// checkRunAnnotations creates an annotation for each failed test or finding with a known source file
+func checkRunAnnotations(results *testreport.Summary) []scmprovider.CheckRunAnnotation {
+	if results == nil {
+		return nil
+	}
+	var answer []scmprovider.CheckRunAnnotation
+	for _, f := range results.Failures {
+		if f.File == "" {
+			continue
+		}
+		line := f.Line
+		if line <= 0 {
+			line = 1
+		}
+		message := f.Message
+		if message == "" {
+			message = "test failed"
+		}
+		answer = append(answer, scmprovider.CheckRunAnnotation{
+			Path:            f.File,
+			StartLine:       line,
+			EndLine:         line,
+			AnnotationLevel: scmprovider.AnnotationLevelFailure,
+			Title:           testName(f),
+			Message:         message,
+			RawDetails:      f.Details,
+		})
+	}
+	return answer
+}
+
+func checkRunName(lhj *v1alpha1.LighthouseJob, activity *v1alpha1.ActivityRecord) string {
+	if activity != nil && activity.Context != "" {
+		return activity.Context
+	}
+	return lhj.Spec.Context
+}
+
+func testName(f testreport.Failure) string {
+	if f.Suite == "" {
+		return f.Name
+	}
+	return f.Suite + "." + f.Name
+}
@@ -0,0 +1,70 @@
+package reporter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/jenkins-x/lighthouse/pkg/testreport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportCheckRun(t *testing.T) {
+	sha := "abc123"
+	lhj := &v1alpha1.LighthouseJob{
+		Spec: v1alpha1.LighthouseJobSpec{
+			Context:      "unit",
+			RerunCommand: "/test unit",
+			Refs: &v1alpha1.Refs{
+				Org:   "org",
+				Repo:  "repo",
+				Pulls: []v1alpha1.Pull{{Number: 1, SHA: sha}},
+			},
+		},
+		Status: v1alpha1.LighthouseJobStatus{
+			State:     v1alpha1.RunningState,
+			ReportURL: "https://example.com/pipeline",
+		},
+	}
+	fakeClient := &fake.SCMClient{}
+
+	require.NoError(t, ReportCheckRun(fakeClient, lhj, nil, sha, nil))
+	require.Len(t, fakeClient.CheckRuns[sha], 1)
+	run := fakeClient.CheckRuns[sha][0]
+	assert.Equal(t, "unit", run.Name)
+	assert.Equal(t, scmprovider.CheckRunStatusInProgress, run.Status)
+	assert.Equal(t, "", run.Conclusion)
+
+	results := &testreport.Summary{Total: 60, Passed: 5, Failed: 55}
+	for i := 0; i < 55; i++ {
+		results.Failures = append(results.Failures, testreport.Failure{
+			Suite:   "pkg/foo",
+			Name:    fmt.Sprintf("TestFoo%d", i),
+			File:    "pkg/foo/foo_test.go",
+			Line:    i + 1,
+			Message: "boom",
+		})
+	}
+	lhj.Status.State = v1alpha1.FailureState
+
+	require.NoError(t, ReportCheckRun(fakeClient, lhj, nil, sha, results))
+	require.Len(t, fakeClient.CheckRuns[sha], 1, "the existing check run should be updated")
+	run = fakeClient.CheckRuns[sha][0]
+	assert.Equal(t, scmprovider.CheckRunStatusCompleted, run.Status)
+	assert.Equal(t, scmprovider.CheckRunConclusionFailure, run.Conclusion)
+	assert.Equal(t, "Pipeline failed: 5/60 tests passed", run.Output.Title)
+	assert.Contains(t, run.Output.Summary, "60 | 5 | 55 | 0")
+	assert.Contains(t, run.Output.Summary, "...and 35 more")
+	require.Len(t, run.Output.Annotations, 55)
+	assert.Equal(t, scmprovider.CheckRunAnnotation{
+		Path:            "pkg/foo/foo_test.go",
+		StartLine:       55,
+		EndLine:         55,
+		AnnotationLevel: scmprovider.AnnotationLevelFailure,
+		Title:           "pkg/foo.TestFoo54",
+		Message:         "boom",
+	}, run.Output.Annotations[54])
+}
@@ -0,0 +1,177 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/jobhistory"
+)
+
+// maxSuspectCommits bounds the number of commits listed as suspects in a tracking issue
+const maxSuspectCommits = 50
+
+// FailureIssueClient provides a client interface to track the failing postsubmits in issues.
+type FailureIssueClient interface {
+	IssueClient
+	ListCommits(string, string, scm.CommitListOptions) ([]*scm.Commit, error)
+	AssignIssue(string, string, int, []string) error
+	CloseIssue(string, string, int) error
+}
+
+// TrackFailures opens the tracking issue of a postsubmit once it failed threshold times in a row, assigned to the
+// authors of the commits since its last successful run, comments on the issue each time it fails again and closes the
+// issue once it succeeds. The runs are the previous runs of the postsubmit on the same branch, most recent first.
+func TrackFailures(c FailureIssueClient, lhj *v1alpha1.LighthouseJob, runs []*jobhistory.Record, threshold int) error {
+	if !lhj.Complete() || lhj.Spec.Refs == nil {
+		return nil
+	}
+	current := jobhistory.RecordForJob(lhj)
+	streak := []*jobhistory.Record{current}
+	var lastSuccess *jobhistory.Record
+	for _, r := range runs {
+		if r.Name == current.Name {
+			continue
+		}
+		if r.StartTime.After(current.StartTime) {
+			if r.State == v1alpha1.SuccessState || failed(r.State, r.Description) {
+				// a more recent run already reported the state of the branch
+				return nil
+			}
+			continue
+		}
+		if r.State == v1alpha1.SuccessState {
+			lastSuccess = r
+			break
+		}
+		if failed(r.State, r.Description) {
+			streak = append(streak, r)
+		}
+	}
+
+	switch {
+	case lhj.Status.State == v1alpha1.SuccessState:
+		return closeFailureIssue(c, lhj)
+	case !failed(lhj.Status.State, lhj.Status.Description) || len(streak) < threshold:
+		return nil
+	}
+	org, repo := IssueRepo(lhj)
+	number, err := FindIssue(c, lhj)
+	if err != nil {
+		return err
+	}
+	if number != 0 {
+		if err := c.CreateComment(org, repo, number, false, issueEntry(current)); err != nil {
+			return fmt.Errorf("error commenting on issue %s/%s#%d: %v", org, repo, number, err)
+		}
+		return nil
+	}
+
+	// the commits which broke the postsubmit are between the last successful run and the first failed one
+	suspects, err := suspectCommits(c, lhj, streak[len(streak)-1], lastSuccess)
+	if err != nil {
+		return err
+	}
+	issue, err := c.CreateIssue(org, repo, IssueTitle(lhj), failureIssueBody(lhj, streak, lastSuccess, suspects))
+	if err != nil {
+		return fmt.Errorf("error creating an issue in %s/%s: %v", org, repo, err)
+	}
+	if authors := commitAuthors(suspects); len(authors) > 0 {
+		if err := c.AssignIssue(org, repo, issue.Number, authors); err != nil {
+			return fmt.Errorf("error assigning issue %s/%s#%d to %s: %v", org, repo, issue.Number, strings.Join(authors, ", "), err)
+		}
+	}
+	return nil
+}
+
+// closeFailureIssue closes the tracking issue of a postsubmit which succeeded, if any
+func closeFailureIssue(c FailureIssueClient, lhj *v1alpha1.LighthouseJob) error {
+	org, repo := IssueRepo(lhj)
+	number, err := FindIssue(c, lhj)
+	if err != nil || number == 0 {
+		return err
+	}
+	comment := fmt.Sprintf("Run %s succeeded on commit %s, closing this issue.", runLink(jobhistory.RecordForJob(lhj)), lhj.Spec.Refs.BaseSHA)
+	if err := c.CreateComment(org, repo, number, false, comment); err != nil {
+		return fmt.Errorf("error commenting on issue %s/%s#%d: %v", org, repo, number, err)
+	}
+	if err := c.CloseIssue(org, repo, number); err != nil {
+		return fmt.Errorf("error closing issue %s/%s#%d: %v", org, repo, number, err)
+	}
+	return nil
+}
+
+// suspectCommits returns the commits from the one the first failed run built, newest first, back to the one the last
+// successful run built excluded. Only the commit of the first failed run is a suspect if the last successful run is
+// not known.
+func suspectCommits(c FailureIssueClient, lhj *v1alpha1.LighthouseJob, firstFailure, lastSuccess *jobhistory.Record) ([]*scm.Commit, error) {
+	if firstFailure.Refs == nil || firstFailure.Refs.BaseSHA == "" {
+		return nil, nil
+	}
+	lastGood := ""
+	if lastSuccess != nil && lastSuccess.Refs != nil {
+		lastGood = lastSuccess.Refs.BaseSHA
+	}
+	size := maxSuspectCommits
+	if lastGood == "" {
+		size = 1
+	}
+	org, repo := lhj.Spec.Refs.Org, lhj.Spec.Refs.Repo
+	commits, err := c.ListCommits(org, repo, scm.CommitListOptions{Sha: firstFailure.Refs.BaseSHA, Size: size})
+	if err != nil {
+		return nil, fmt.Errorf("error listing the commits of %s/%s from %s: %v", org, repo, firstFailure.Refs.BaseSHA, err)
+	}
+	if len(commits) > size {
+		commits = commits[:size]
+	}
+	for i, commit := range commits {
+		if commit.Sha == lastGood {
+			return commits[:i], nil
+		}
+	}
+	return commits, nil
+}
+
+// commitAuthors returns the logins of the authors of the commits
+func commitAuthors(commits []*scm.Commit) []string {
+	var authors []string
+	seen := map[string]bool{}
+	for _, commit := range commits {
+		if login := commit.Author.Login; login != "" && !seen[login] {
+			seen[login] = true
+			authors = append(authors, login)
+		}
+	}
+	return authors
+}
+
+// failureIssueBody describes the failed runs of a postsubmit and the commits which may have broken it
+func failureIssueBody(lhj *v1alpha1.LighthouseJob, streak []*jobhistory.Record, lastSuccess *jobhistory.Record, suspects []*scm.Commit) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The postsubmit `%s` failed %d times in a row on `%s`.\n\n", lhj.Spec.Job, len(streak), lhj.Spec.GetBranch())
+	b.WriteString("Failed runs:\n\n")
+	for _, r := range streak {
+		fmt.Fprintf(&b, "- %s %s", runLink(r), r.State)
+		if r.Refs != nil && r.Refs.BaseSHA != "" {
+			fmt.Fprintf(&b, " on commit %s", r.Refs.BaseSHA)
+		}
+		b.WriteString("\n")
+	}
+	if len(suspects) > 0 {
+		if lastSuccess != nil {
+			fmt.Fprintf(&b, "\nSuspect commits since the last successful run %s:\n\n", runLink(lastSuccess))
+		} else {
+			b.WriteString("\nSuspect commits:\n\n")
+		}
+		for _, commit := range suspects {
+			fmt.Fprintf(&b, "- %s %s", commit.Sha, strings.SplitN(commit.Message, "\n", 2)[0])
+			if commit.Author.Login != "" {
+				fmt.Fprintf(&b, " (@%s)", commit.Author.Login)
+			}
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\nThis issue is updated each time the postsubmit fails and closed once it succeeds again.")
+	return b.String()
+}
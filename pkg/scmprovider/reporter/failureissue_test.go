@@ -0,0 +1,109 @@
+package reporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/jobhistory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTrackFailures(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	now := metav1.Now()
+	postsubmit := func(state v1alpha1.PipelineState) *v1alpha1.LighthouseJob {
+		return &v1alpha1.LighthouseJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "release-4"},
+			Spec: v1alpha1.LighthouseJobSpec{
+				Type: job.PostsubmitJob,
+				Job:  "release",
+				Refs: &v1alpha1.Refs{Org: "org", Repo: "repo", BaseRef: "master", BaseSHA: "c4"},
+			},
+			Status: v1alpha1.LighthouseJobStatus{
+				State:          state,
+				StartTime:      metav1.NewTime(start),
+				CompletionTime: &now,
+				ReportURL:      "https://dashboard/release-4",
+			},
+		}
+	}
+	run := func(name, sha string, state v1alpha1.PipelineState, startedBefore time.Duration) *jobhistory.Record {
+		return &jobhistory.Record{
+			Name:      name,
+			Refs:      &v1alpha1.Refs{BaseSHA: sha},
+			State:     state,
+			StartTime: start.Add(-startedBefore),
+			URL:       "https://dashboard/" + name,
+		}
+	}
+	commits := []*scm.Commit{
+		{Sha: "c4", Message: "Fix the docs", Author: scm.Signature{Login: "bob"}},
+		{Sha: "c3", Message: "Refactor the release\n\nDetails", Author: scm.Signature{Login: "alice"}},
+		{Sha: "c2", Message: "Bump the dependencies", Author: scm.Signature{Login: "dave"}},
+		{Sha: "c1", Message: "Add the release", Author: scm.Signature{Login: "carol"}},
+	}
+	brokenRuns := []*jobhistory.Record{
+		run("release-3", "c3", v1alpha1.FailureState, time.Minute),
+		run("release-2", "c1", v1alpha1.SuccessState, 2*time.Minute),
+		run("release-1", "c1", v1alpha1.FailureState, 3*time.Minute),
+	}
+	title := "Job release is failing on master"
+
+	t.Run("opens the issue once the threshold is reached", func(t *testing.T) {
+		c := &fakeIssueClient{commits: commits}
+		require.NoError(t, TrackFailures(c, postsubmit(v1alpha1.FailureState), brokenRuns, 2))
+		require.Len(t, c.created, 1)
+		issue := c.created[0]
+		assert.Equal(t, title, issue.Title)
+		assert.Contains(t, issue.Body, "failed 2 times in a row on `master`")
+		assert.Contains(t, issue.Body, "- [release-4](https://dashboard/release-4) failure on commit c4")
+		assert.Contains(t, issue.Body, "- [release-3](https://dashboard/release-3) failure on commit c3")
+		assert.Contains(t, issue.Body, "since the last successful run [release-2](https://dashboard/release-2)")
+		assert.Contains(t, issue.Body, "- c3 Refactor the release (@alice)")
+		assert.Contains(t, issue.Body, "- c2 Bump the dependencies (@dave)")
+		assert.NotContains(t, issue.Body, "c1 Add the release")
+		assert.Equal(t, []string{"alice", "dave"}, c.assigned[issue.Number])
+	})
+
+	t.Run("waits for the threshold", func(t *testing.T) {
+		c := &fakeIssueClient{commits: commits}
+		require.NoError(t, TrackFailures(c, postsubmit(v1alpha1.FailureState), brokenRuns, 3))
+		assert.Empty(t, c.created)
+	})
+
+	t.Run("suspects the first failed commit without successful run", func(t *testing.T) {
+		c := &fakeIssueClient{commits: commits}
+		runs := []*jobhistory.Record{run("release-3", "c3", v1alpha1.FailureState, time.Minute)}
+		require.NoError(t, TrackFailures(c, postsubmit(v1alpha1.FailureState), runs, 2))
+		require.Len(t, c.created, 1)
+		assert.Equal(t, []string{"alice"}, c.assigned[c.created[0].Number])
+	})
+
+	t.Run("comments on the open issue", func(t *testing.T) {
+		c := &fakeIssueClient{commits: commits, issues: []*scm.SearchIssue{{Issue: scm.Issue{Number: 8, Title: title}}}}
+		require.NoError(t, TrackFailures(c, postsubmit(v1alpha1.FailureState), brokenRuns, 2))
+		assert.Empty(t, c.created)
+		require.Len(t, c.comments[8], 1)
+		assert.Contains(t, c.comments[8][0], "[release-4](https://dashboard/release-4) failure on commit c4")
+	})
+
+	t.Run("closes the issue once the postsubmit succeeds", func(t *testing.T) {
+		c := &fakeIssueClient{issues: []*scm.SearchIssue{{Issue: scm.Issue{Number: 8, Title: title}}}}
+		require.NoError(t, TrackFailures(c, postsubmit(v1alpha1.SuccessState), brokenRuns, 2))
+		assert.Equal(t, []int{8}, c.closed)
+		require.Len(t, c.comments[8], 1)
+		assert.Contains(t, c.comments[8][0], "succeeded on commit c4")
+	})
+
+	t.Run("ignores the runs superseded by a more recent one", func(t *testing.T) {
+		c := &fakeIssueClient{commits: commits}
+		runs := append([]*jobhistory.Record{run("release-5", "c5", v1alpha1.SuccessState, -time.Minute)}, brokenRuns...)
+		require.NoError(t, TrackFailures(c, postsubmit(v1alpha1.FailureState), runs, 2))
+		assert.Empty(t, c.created)
+	})
+}
@@ -0,0 +1,114 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/jobhistory"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+)
+
+// IssueClient provides a client interface to report the failures of jobs in issues.
+type IssueClient interface {
+	Search(scm.SearchOptions) ([]*scm.SearchIssue, *scmprovider.RateLimits, error)
+	CreateIssue(string, string, string, string) (*scm.Issue, error)
+	CreateComment(string, string, int, bool, string) error
+}
+
+// IssueRepo returns the org and repo the issues reporting the failures of the job are opened in
+func IssueRepo(lhj *v1alpha1.LighthouseJob) (string, string) {
+	if parts := strings.SplitN(lhj.Spec.IssueRepo, "/", 2); len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	if lhj.Spec.Refs != nil {
+		return lhj.Spec.Refs.Org, lhj.Spec.Refs.Repo
+	}
+	return "", ""
+}
+
+// IssueTitle returns the title of the issue reporting the failures of the job, which identifies the issue of the job
+// open in the repository
+func IssueTitle(lhj *v1alpha1.LighthouseJob) string {
+	if lhj.Spec.Type == job.PeriodicJob || lhj.Spec.Refs == nil {
+		return fmt.Sprintf("Periodic job %s is failing", lhj.Spec.Job)
+	}
+	return fmt.Sprintf("Job %s is failing on %s", lhj.Spec.Job, lhj.Spec.GetBranch())
+}
+
+// FindIssue returns the number of the open issue reporting the failures of the job, or 0 if there is none
+func FindIssue(c IssueClient, lhj *v1alpha1.LighthouseJob) (int, error) {
+	org, repo := IssueRepo(lhj)
+	title := IssueTitle(lhj)
+	results, _, err := c.Search(scm.SearchOptions{
+		Query: fmt.Sprintf("is:issue is:open repo:%s/%s in:title \"%s\"", org, repo, title),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error searching the issues of %s/%s: %v", org, repo, err)
+	}
+	for _, r := range results {
+		if !r.PullRequest && !r.Closed && r.Title == title {
+			return r.Number, nil
+		}
+	}
+	return 0, nil
+}
+
+// ReportIssue opens an issue when the job failed, or comments on the issue already open for it, if the job has the
+// issue report mode.
+func ReportIssue(c IssueClient, lhj *v1alpha1.LighthouseJob) error {
+	if !job.Reports(lhj.Spec.Report, job.ReportIssue) || !lhj.Complete() || !failed(lhj.Status.State, lhj.Status.Description) {
+		return nil
+	}
+	org, repo := IssueRepo(lhj)
+	if org == "" || repo == "" {
+		return fmt.Errorf("cannot report job %s in an issue without a repository", lhj.Name)
+	}
+	number, err := FindIssue(c, lhj)
+	if err != nil {
+		return err
+	}
+	body := issueEntry(jobhistory.RecordForJob(lhj))
+	if number != 0 {
+		if err := c.CreateComment(org, repo, number, false, body); err != nil {
+			return fmt.Errorf("error commenting on issue %s/%s#%d: %v", org, repo, number, err)
+		}
+		return nil
+	}
+	body = fmt.Sprintf("The job `%s` is failing.\n\n%s\n\nThis issue is updated each time the job fails.", lhj.Spec.Job, body)
+	if _, err := c.CreateIssue(org, repo, IssueTitle(lhj), body); err != nil {
+		return fmt.Errorf("error creating an issue in %s/%s: %v", org, repo, err)
+	}
+	return nil
+}
+
+// failed returns whether the state and its description are the final state of a failed job, the jobs aborted as
+// they were skipped not having failed
+func failed(state v1alpha1.PipelineState, description string) bool {
+	if state == v1alpha1.AbortedState {
+		return !strings.HasPrefix(description, v1alpha1.SkippedDescriptionPrefix)
+	}
+	return state == v1alpha1.FailureState
+}
+
+// issueEntry describes a failed run of a job
+func issueEntry(r *jobhistory.Record) string {
+	lines := []string{fmt.Sprintf("Run %s %s", runLink(r), r.State)}
+	if r.Refs != nil && r.Refs.BaseSHA != "" {
+		lines[0] += fmt.Sprintf(" on commit %s", r.Refs.BaseSHA)
+	}
+	if r.Description != "" {
+		lines = append(lines, fmt.Sprintf("> %s", r.Description))
+	}
+	return strings.Join(lines, "\n\n")
+}
+
+// runLink links to the report of a run of a job, if any
+func runLink(r *jobhistory.Record) string {
+	if r.URL == "" {
+		return r.Name
+	}
+	return fmt.Sprintf("[%s](%s)", r.Name, r.URL)
+}
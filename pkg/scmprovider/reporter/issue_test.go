@@ -0,0 +1,150 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeIssueClient struct {
+	issues   []*scm.SearchIssue
+	created  []*scm.Issue
+	comments map[int][]string
+	commits  []*scm.Commit
+	assigned map[int][]string
+	closed   []int
+}
+
+func (f *fakeIssueClient) Search(scm.SearchOptions) ([]*scm.SearchIssue, *scmprovider.RateLimits, error) {
+	return f.issues, nil, nil
+}
+
+func (f *fakeIssueClient) CreateIssue(org, repo, title, body string) (*scm.Issue, error) {
+	issue := &scm.Issue{Number: 100 + len(f.created), Title: title, Body: body}
+	f.created = append(f.created, issue)
+	return issue, nil
+}
+
+func (f *fakeIssueClient) CreateComment(org, repo string, number int, pr bool, comment string) error {
+	if f.comments == nil {
+		f.comments = map[int][]string{}
+	}
+	f.comments[number] = append(f.comments[number], comment)
+	return nil
+}
+
+func (f *fakeIssueClient) ListCommits(org, repo string, opts scm.CommitListOptions) ([]*scm.Commit, error) {
+	for i, c := range f.commits {
+		if c.Sha == opts.Sha {
+			return f.commits[i:], nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeIssueClient) AssignIssue(org, repo string, number int, logins []string) error {
+	if f.assigned == nil {
+		f.assigned = map[int][]string{}
+	}
+	f.assigned[number] = append(f.assigned[number], logins...)
+	return nil
+}
+
+func (f *fakeIssueClient) CloseIssue(org, repo string, number int) error {
+	f.closed = append(f.closed, number)
+	return nil
+}
+
+func TestReportIssue(t *testing.T) {
+	now := metav1.Now()
+	postsubmit := &v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "release-abc"},
+		Spec: v1alpha1.LighthouseJobSpec{
+			Type:   job.PostsubmitJob,
+			Job:    "release",
+			Report: []job.ReportMode{job.ReportStatus, job.ReportIssue},
+			Refs:   &v1alpha1.Refs{Org: "org", Repo: "repo", BaseRef: "master", BaseSHA: "sha1"},
+		},
+		Status: v1alpha1.LighthouseJobStatus{
+			State:          v1alpha1.FailureState,
+			CompletionTime: &now,
+			ReportURL:      "https://dashboard/release-abc",
+		},
+	}
+	title := "Job release is failing on master"
+
+	t.Run("opens an issue", func(t *testing.T) {
+		c := &fakeIssueClient{}
+		require.NoError(t, ReportIssue(c, postsubmit))
+		require.Len(t, c.created, 1)
+		assert.Equal(t, title, c.created[0].Title)
+		assert.Contains(t, c.created[0].Body, "[release-abc](https://dashboard/release-abc) failure on commit sha1")
+	})
+
+	t.Run("comments on the open issue", func(t *testing.T) {
+		c := &fakeIssueClient{issues: []*scm.SearchIssue{
+			{Issue: scm.Issue{Number: 7, Title: "Job release is failing on master", Closed: true}},
+			{Issue: scm.Issue{Number: 8, Title: title}},
+		}}
+		require.NoError(t, ReportIssue(c, postsubmit))
+		assert.Empty(t, c.created)
+		assert.Len(t, c.comments[8], 1)
+	})
+
+	t.Run("ignores the jobs which succeeded", func(t *testing.T) {
+		c := &fakeIssueClient{}
+		succeeded := postsubmit.DeepCopy()
+		succeeded.Status.State = v1alpha1.SuccessState
+		require.NoError(t, ReportIssue(c, succeeded))
+		assert.Empty(t, c.created)
+	})
+
+	t.Run("ignores the jobs skipped during maintenance windows", func(t *testing.T) {
+		c := &fakeIssueClient{}
+		skipped := postsubmit.DeepCopy()
+		skipped.Status.State = v1alpha1.AbortedState
+		skipped.Status.Description = v1alpha1.SkippedDescriptionPrefix + "cluster upgrade"
+		require.NoError(t, ReportIssue(c, skipped))
+		assert.Empty(t, c.created)
+
+		aborted := postsubmit.DeepCopy()
+		aborted.Status.State = v1alpha1.AbortedState
+		aborted.Status.Description = "Timed out"
+		require.NoError(t, ReportIssue(c, aborted))
+		assert.Len(t, c.created, 1)
+	})
+
+	t.Run("ignores the jobs without the issue mode", func(t *testing.T) {
+		c := &fakeIssueClient{}
+		defaults := postsubmit.DeepCopy()
+		defaults.Spec.Report = nil
+		require.NoError(t, ReportIssue(c, defaults))
+		assert.Empty(t, c.created)
+	})
+
+	t.Run("opens the issue of a periodic in its issue repo", func(t *testing.T) {
+		c := &fakeIssueClient{}
+		periodic := &v1alpha1.LighthouseJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "nightly-abc"},
+			Spec: v1alpha1.LighthouseJobSpec{
+				Type:      job.PeriodicJob,
+				Job:       "nightly",
+				Report:    []job.ReportMode{job.ReportIssue},
+				IssueRepo: "org/infra",
+			},
+			Status: v1alpha1.LighthouseJobStatus{State: v1alpha1.AbortedState, CompletionTime: &now},
+		}
+		org, repo := IssueRepo(periodic)
+		assert.Equal(t, "org", org)
+		assert.Equal(t, "infra", repo)
+		require.NoError(t, ReportIssue(c, periodic))
+		require.Len(t, c.created, 1)
+		assert.Equal(t, "Periodic job nightly is failing", c.created[0].Title)
+	})
+}
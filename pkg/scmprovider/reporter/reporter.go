@@ -31,8 +31,42 @@ import (
 
 const (
 	commentTag = "!-- test report --"
+
+	// FailureReportTemplateName is the name of the comment template of the report of the failed tests of a pull request
+	FailureReportTemplateName = "failure-report"
+
+	failureReportMsg = `@{{ .Author }}: The following test{{ if gt (len .Entries) 1 }}s{{ end }} **failed**, say ` + "`/retest`" + ` to rerun them all:
+
+Test name | Commit | Details | Rerun command
+--- | --- | --- | ---
+{{ range .Entries }}{{ . }}
+{{ end }}{{ if .Report }}
+{{ .Report }}
+{{ end }}
+<details>
+
+{{ .AboutThisBot }}
+</details>`
 )
 
+func init() {
+	plugins.RegisterCommentTemplate(FailureReportTemplateName, failureReportMsg)
+}
+
+// FailureReport is the data the failure report comment template is rendered with
+type FailureReport struct {
+	// Author is the author of the pull request, quoted for the SCM provider
+	Author string
+	// Entries are the rows of the table of the failed tests
+	Entries []string
+	// Report is the output of the report template of the plank configuration, if any
+	Report string
+	// AboutThisBot describes the bot
+	AboutThisBot string
+	// Job is the LighthouseJob being reported
+	Job *v1alpha1.LighthouseJob
+}
+
 // SCMProviderClient provides a client interface to report job status updates
 // through GitHub comments.
 type SCMProviderClient interface {
@@ -61,8 +95,9 @@ func ShouldReport(lhj *v1alpha1.LighthouseJob, validTypes []job.PipelineKind) bo
 }
 
 // Report is creating/updating/removing report comments in the SCM provider based on the state of
-// the provided LighthouseJob.
-func Report(spc SCMProviderClient, reportTemplate *template.Template, lhj *v1alpha1.LighthouseJob, validTypes []job.PipelineKind) error {
+// the provided LighthouseJob. The comment is rendered with the failure report comment template of the plugin
+// configuration, which may be nil to use the default one.
+func Report(spc SCMProviderClient, pluginConfig *plugins.Configuration, reportTemplate *template.Template, lhj *v1alpha1.LighthouseJob, validTypes []job.PipelineKind) error {
 	if spc == nil {
 		return fmt.Errorf("trying to report lhj %s, but found empty SCM provider client", lhj.ObjectMeta.Name)
 	}
@@ -100,7 +135,7 @@ func Report(spc SCMProviderClient, reportTemplate *template.Template, lhj *v1alp
 		}
 	}
 	if len(entries) > 0 {
-		comment, err := createComment(reportTemplate, lhj, spc.QuoteAuthorForComment(lhj.Spec.Refs.Pulls[0].Author), entries)
+		comment, err := createComment(pluginConfig, reportTemplate, lhj, spc.QuoteAuthorForComment(lhj.Spec.Refs.Pulls[0].Author), entries)
 		if err != nil {
 			return fmt.Errorf("generating comment: %v", err)
 		}
@@ -203,34 +238,24 @@ func createEntry(lhj *v1alpha1.LighthouseJob) string {
 // createComment take a LighthouseJob and a list of entries generated with
 // createEntry and returns a nicely formatted comment. It may fail if template
 // execution fails.
-func createComment(reportTemplate *template.Template, lhj *v1alpha1.LighthouseJob, author string, entries []string) (string, error) {
-	plural := ""
-	if len(entries) > 1 {
-		plural = "s"
-	}
+func createComment(pluginConfig *plugins.Configuration, reportTemplate *template.Template, lhj *v1alpha1.LighthouseJob, author string, entries []string) (string, error) {
 	var b bytes.Buffer
 	if reportTemplate != nil {
 		if err := reportTemplate.Execute(&b, &lhj); err != nil {
 			return "", err
 		}
 	}
-	lines := []string{
-		fmt.Sprintf("@%s: The following test%s **failed**, say `/retest` to rerun them all:", author, plural),
-		"",
-		"Test name | Commit | Details | Rerun command",
-		"--- | --- | --- | ---",
+	refs := lhj.Spec.Refs
+	comment, err := pluginConfig.RenderComment(FailureReportTemplateName, refs.Org, refs.Repo, FailureReport{
+		Author:       author,
+		Entries:      entries,
+		Report:       b.String(),
+		AboutThisBot: plugins.AboutThisBot,
+		Job:          lhj,
+	})
+	if err != nil {
+		return "", err
 	}
-	lines = append(lines, entries...)
-	if reportTemplate != nil {
-		lines = append(lines, "", b.String())
-	}
-	lines = append(lines, []string{
-		"",
-		"<details>",
-		"",
-		plugins.AboutThisBot,
-		"</details>",
-		"<" + commentTag + ">",
-	}...)
-	return strings.Join(lines, "\n"), nil
+	// the tag is not part of the template as it identifies the comment to update
+	return comment + "\n<" + commentTag + ">", nil
 }
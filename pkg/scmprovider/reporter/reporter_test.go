@@ -23,6 +23,11 @@ import (
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
 	"github.com/jenkins-x/lighthouse/pkg/config/job"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestParsePRComment(t *testing.T) {
@@ -270,3 +275,46 @@ func TestShouldReport(t *testing.T) {
 		}
 	}
 }
+
+func TestCreateComment(t *testing.T) {
+	lhj := &v1alpha1.LighthouseJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "unit-1"},
+		Spec: v1alpha1.LighthouseJobSpec{
+			Context: "unit",
+			Refs:    &v1alpha1.Refs{Org: "org", Repo: "repo"},
+		},
+	}
+	entries := []string{"unit | abc | [link](url) | `/test unit`", "lint | abc | [link](url) | `/test lint`"}
+	plank := &lighthouse.Plank{ReportTemplateString: "Ask in #ci about {{ .Spec.Context }}."}
+	require.NoError(t, plank.Parse())
+
+	comment, err := createComment(nil, plank.ReportTemplate, lhj, "author", entries)
+	require.NoError(t, err)
+	expected := strings.Join([]string{
+		"@author: The following tests **failed**, say `/retest` to rerun them all:",
+		"",
+		"Test name | Commit | Details | Rerun command",
+		"--- | --- | --- | ---",
+		entries[0],
+		entries[1],
+		"",
+		"Ask in #ci about unit.",
+		"",
+		"<details>",
+		"",
+		plugins.AboutThisBot,
+		"</details>",
+		"<" + commentTag + ">",
+	}, "\n")
+	assert.Equal(t, expected, comment)
+
+	pluginConfig := &plugins.Configuration{CommentTemplates: plugins.CommentTemplates{
+		Repos: map[string]map[string]string{
+			"org/repo": {FailureReportTemplateName: "{{ len .Entries }} {{ .Job.Spec.Context }} failures for {{ .Author }}"},
+		},
+	}}
+	comment, err = createComment(pluginConfig, nil, lhj, "author", entries)
+	require.NoError(t, err)
+	assert.Equal(t, "2 unit failures for author\n<"+commentTag+">", comment)
+	assert.Contains(t, plugins.RegisteredCommentTemplates(), FailureReportTemplateName)
+}
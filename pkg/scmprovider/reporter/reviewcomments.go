@@ -0,0 +1,185 @@
+package reporter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/testreport"
+)
+
+// maxCommentDetailsLines bounds the number of lines of the details of a failure quoted in its inline comment
+const maxCommentDetailsLines = 20
+
+var (
+	// hunkHeader matches the header of a hunk of a patch, capturing the first line of the hunk in the new file
+	hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+	// failureCommentHeader matches the start of the inline comment of a failure, capturing its context and test name
+	failureCommentHeader = regexp.MustCompile("^\\*\\*(.+?)\\*\\*: `(.+?)` failed")
+)
+
+// ReviewCommentClient provides a client interface to report failures as inline review comments.
+type ReviewCommentClient interface {
+	GetPullRequestChanges(string, string, int) ([]*scm.Change, error)
+	ListReviewComments(string, string, int) ([]*scmprovider.ReviewComment, error)
+	CreateReviewComments(string, string, int, string, string, []*scmprovider.ReviewComment) error
+	BotName() (string, error)
+}
+
+// ReportReviewComments reviews the pull request of a failed presubmit, commenting inline on the failures of the
+// results at lines changed by the pull request, up to max comments. The other failures are listed in the body of the
+// review. The inline comments of the job left by the previous runs count towards the cap, and the failures they
+// comment on, at the same line and for the same test, are not commented again even if their message changed.
+func ReportReviewComments(c ReviewCommentClient, lhj *v1alpha1.LighthouseJob, sha string, results *testreport.Summary, max int) error {
+	refs := lhj.Spec.Refs
+	if refs == nil || len(refs.Pulls) != 1 || results == nil || len(results.Failures) == 0 {
+		return nil
+	}
+	number := refs.Pulls[0].Number
+	changes, err := c.GetPullRequestChanges(refs.Org, refs.Repo, number)
+	if err != nil {
+		return fmt.Errorf("error listing the changes of %s/%s#%d: %v", refs.Org, refs.Repo, number, err)
+	}
+	existing, err := c.ListReviewComments(refs.Org, refs.Repo, number)
+	if err != nil {
+		return fmt.Errorf("error listing the review comments of %s/%s#%d: %v", refs.Org, refs.Repo, number, err)
+	}
+	botName, err := c.BotName()
+	if err != nil {
+		return fmt.Errorf("error getting bot name: %v", err)
+	}
+	commented := map[string]bool{}
+	for _, comment := range existing {
+		if comment.User == nil || comment.User.Login != botName {
+			continue
+		}
+		if m := failureCommentHeader.FindStringSubmatch(comment.Body); m != nil && m[1] == lhj.Spec.Context {
+			commented[reviewCommentKey(comment.Path, comment.Line, m[2])] = true
+		}
+	}
+
+	lines := changedLines(changes)
+	var comments []*scmprovider.ReviewComment
+	var others []testreport.Failure
+	// the comments of the previous runs count towards the cap so that reruns do not add more comments
+	inline := len(commented)
+	for _, f := range results.Failures {
+		path := changedPath(lines, f.File)
+		if path == "" || !lines[path][f.Line] {
+			others = append(others, f)
+			continue
+		}
+		key := reviewCommentKey(path, f.Line, testName(f))
+		if commented[key] {
+			continue
+		}
+		if inline >= max {
+			others = append(others, f)
+			continue
+		}
+		inline++
+		commented[key] = true
+		comments = append(comments, &scmprovider.ReviewComment{
+			Path: path,
+			Line: f.Line,
+			Side: scmprovider.ReviewCommentSideRight,
+			Body: reviewCommentBody(lhj, f),
+		})
+	}
+	if len(comments) == 0 {
+		return nil
+	}
+	if err := c.CreateReviewComments(refs.Org, refs.Repo, number, sha, reviewBody(lhj, comments, others), comments); err != nil {
+		return fmt.Errorf("error reviewing %s/%s#%d: %v", refs.Org, refs.Repo, number, err)
+	}
+	return nil
+}
+
+// changedLines returns the lines of the new version of the changed files which are part of the hunks of the pull
+// request, as only those can be commented inline
+func changedLines(changes []*scm.Change) map[string]map[int]bool {
+	answer := map[string]map[int]bool{}
+	for _, change := range changes {
+		if change.Deleted {
+			continue
+		}
+		lines := map[int]bool{}
+		line := 0
+		for _, text := range strings.Split(change.Patch, "\n") {
+			if m := hunkHeader.FindStringSubmatch(text); m != nil {
+				line, _ = strconv.Atoi(m[1])
+				continue
+			}
+			if line == 0 || strings.HasPrefix(text, "-") || strings.HasPrefix(text, "\\") {
+				continue
+			}
+			lines[line] = true
+			line++
+		}
+		answer[change.Path] = lines
+	}
+	return answer
+}
+
+// changedPath returns the changed file of the file of a failure, which may be absolute or relative to the root of
+// the repository
+func changedPath(lines map[string]map[int]bool, file string) string {
+	file = strings.TrimPrefix(file, "./")
+	if file == "" {
+		return ""
+	}
+	if _, ok := lines[file]; ok {
+		return file
+	}
+	for path := range lines {
+		if strings.HasSuffix(file, "/"+path) {
+			return path
+		}
+	}
+	return ""
+}
+
+func reviewCommentKey(path string, line int, test string) string {
+	return fmt.Sprintf("%s:%d:%s", path, line, test)
+}
+
+func reviewCommentBody(lhj *v1alpha1.LighthouseJob, f testreport.Failure) string {
+	body := fmt.Sprintf("**%s**: `%s` failed", lhj.Spec.Context, testName(f))
+	if f.Message != "" {
+		body += ": " + f.Message
+	}
+	if f.Details != "" {
+		details := strings.Split(f.Details, "\n")
+		if len(details) > maxCommentDetailsLines {
+			details = append(details[:maxCommentDetailsLines], "...")
+		}
+		body += fmt.Sprintf("\n\n```\n%s\n```", strings.Join(details, "\n"))
+	}
+	return body
+}
+
+func reviewBody(lhj *v1alpha1.LighthouseJob, comments []*scmprovider.ReviewComment, others []testreport.Failure) string {
+	context := lhj.Spec.Context
+	if lhj.Status.ReportURL != "" {
+		context = fmt.Sprintf("[%s](%s)", context, lhj.Status.ReportURL)
+	}
+	lines := []string{fmt.Sprintf("%s failed, %d of its failures are commented inline.", context, len(comments))}
+	if len(others) > 0 {
+		lines = append(lines, "", "**Other failures:**", "")
+		for i, f := range others {
+			if i == maxSummaryFailures {
+				lines = append(lines, fmt.Sprintf("* ...and %d more", len(others)-maxSummaryFailures))
+				break
+			}
+			lines = append(lines, fmt.Sprintf("* `%s`: %s", testName(f), f.Message))
+		}
+	}
+	if lhj.Spec.RerunCommand != "" {
+		lines = append(lines, "", fmt.Sprintf("Rerun command: `%s`", lhj.Spec.RerunCommand))
+	}
+	return strings.Join(lines, "\n")
+}
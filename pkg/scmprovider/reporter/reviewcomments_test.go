@@ -0,0 +1,124 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider/fake"
+	"github.com/jenkins-x/lighthouse/pkg/testreport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportReviewComments(t *testing.T) {
+	sha := "abc123"
+	lhj := &v1alpha1.LighthouseJob{
+		Spec: v1alpha1.LighthouseJobSpec{
+			Context:      "unit",
+			RerunCommand: "/test unit",
+			Refs: &v1alpha1.Refs{
+				Org:   "org",
+				Repo:  "repo",
+				Pulls: []v1alpha1.Pull{{Number: 1, SHA: sha}},
+			},
+		},
+		Status: v1alpha1.LighthouseJobStatus{
+			State:     v1alpha1.FailureState,
+			ReportURL: "https://example.com/pipeline",
+		},
+	}
+	fakeClient := &fake.SCMClient{
+		PullRequestChanges: map[int][]*scm.Change{
+			1: {
+				{
+					Path:  "pkg/foo/foo.go",
+					Patch: "@@ -10,3 +10,4 @@ func foo() {\n \ta := 1\n-\tb := 2\n+\tb := 3\n+\tc := 4\n \treturn\n",
+				},
+				{
+					Path:    "pkg/old/old.go",
+					Deleted: true,
+					Patch:   "@@ -1,1 +0,0 @@\n-package old\n",
+				},
+			},
+		},
+	}
+	results := &testreport.Summary{
+		Failures: []testreport.Failure{
+			{Suite: "gosec", Name: "G101", File: "pkg/foo/foo.go", Line: 11, Message: "Potential hardcoded credentials"},
+			{Suite: "pkg/foo", Name: "TestFoo", File: "/workspace/source/pkg/foo/foo.go", Line: 12, Message: "boom", Details: "foo.go:12: boom\ngoroutine 1"},
+			{Suite: "pkg/foo", Name: "TestBar", File: "pkg/foo/foo.go", Line: 13, Message: "bang"},
+			{Suite: "pkg/foo", Name: "TestUnchanged", File: "pkg/foo/foo.go", Line: 42, Message: "unchanged"},
+			{Suite: "pkg/old", Name: "TestOld", File: "pkg/old/old.go", Line: 1, Message: "deleted"},
+		},
+	}
+
+	require.NoError(t, ReportReviewComments(fakeClient, lhj, sha, results, 2))
+	require.Len(t, fakeClient.Reviews[1], 1)
+	assert.Equal(t, sha, fakeClient.Reviews[1][0].Sha)
+	body := fakeClient.Reviews[1][0].Body
+	assert.Contains(t, body, "[unit](https://example.com/pipeline) failed, 2 of its failures are commented inline.")
+	assert.Contains(t, body, "* `pkg/foo.TestBar`: bang")
+	assert.Contains(t, body, "* `pkg/foo.TestUnchanged`: unchanged")
+	assert.Contains(t, body, "* `pkg/old.TestOld`: deleted")
+	assert.Equal(t, []*scmprovider.ReviewComment{
+		{
+			Path: "pkg/foo/foo.go",
+			Line: 11,
+			Side: scmprovider.ReviewCommentSideRight,
+			Body: "**unit**: `gosec.G101` failed: Potential hardcoded credentials",
+			User: &scmprovider.ReviewCommentUser{Login: "k8s-ci-robot"},
+		},
+		{
+			Path: "pkg/foo/foo.go",
+			Line: 12,
+			Side: scmprovider.ReviewCommentSideRight,
+			Body: "**unit**: `pkg/foo.TestFoo` failed: boom\n\n```\nfoo.go:12: boom\ngoroutine 1\n```",
+			User: &scmprovider.ReviewCommentUser{Login: "k8s-ci-robot"},
+		},
+	}, fakeClient.ReviewComments[1])
+
+	// the failures already commented on by a previous run are not commented again, even with another message
+	results.Failures[1].Message = "kaboom"
+	require.NoError(t, ReportReviewComments(fakeClient, lhj, sha, results, 2))
+	assert.Len(t, fakeClient.Reviews[1], 1)
+
+	// and the previous comments count towards the cap
+	require.NoError(t, ReportReviewComments(fakeClient, lhj, sha, results, 3))
+	require.Len(t, fakeClient.Reviews[1], 2)
+	assert.Contains(t, fakeClient.Reviews[1][1].Body, "1 of its failures are commented inline.")
+	assert.Contains(t, fakeClient.Reviews[1][1].Body, "* `pkg/foo.TestUnchanged`: unchanged")
+	require.Len(t, fakeClient.ReviewComments[1], 3)
+	assert.Equal(t, 13, fakeClient.ReviewComments[1][2].Line)
+
+	// the comments of other users and jobs do not count
+	fakeClient.ReviewComments[3] = []*scmprovider.ReviewComment{
+		{Path: "pkg/foo/foo.go", Line: 11, Body: "**unit**: `gosec.G101` failed: Potential hardcoded credentials", User: &scmprovider.ReviewCommentUser{Login: "someone"}},
+		{Path: "pkg/foo/foo.go", Line: 12, Body: "**lint**: `pkg/foo.TestFoo` failed", User: &scmprovider.ReviewCommentUser{Login: "k8s-ci-robot"}},
+	}
+	lhj.Spec.Refs.Pulls[0].Number = 3
+	fakeClient.PullRequestChanges[3] = fakeClient.PullRequestChanges[1]
+	require.NoError(t, ReportReviewComments(fakeClient, lhj, sha, results, 2))
+	require.Len(t, fakeClient.ReviewComments[3], 4)
+	assert.Equal(t, 11, fakeClient.ReviewComments[3][2].Line)
+	assert.Equal(t, 12, fakeClient.ReviewComments[3][3].Line)
+
+	// nor are the failures outside of the changes
+	lhj.Spec.Refs.Pulls[0].Number = 2
+	fakeClient.PullRequestChanges[2] = fakeClient.PullRequestChanges[1][1:]
+	require.NoError(t, ReportReviewComments(fakeClient, lhj, sha, results, 2))
+	assert.Empty(t, fakeClient.Reviews[2])
+}
+
+func TestChangedLines(t *testing.T) {
+	changes := []*scm.Change{
+		{
+			Path:  "foo.go",
+			Patch: "@@ -1,2 +1,2 @@\n-a\n+b\n c\n@@ -20 +20,2 @@\n+d\n e\n\\ No newline at end of file",
+		},
+	}
+	assert.Equal(t, map[string]map[int]bool{
+		"foo.go": {1: true, 2: true, 20: true, 21: true},
+	}, changedLines(changes))
+}
@@ -2,8 +2,10 @@ package scmprovider
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
 )
 
 // GetRepositoryByFullName returns the repository details
@@ -39,6 +41,10 @@ func (c *Client) GetRepoLabels(owner, repo string) ([]*scm.Label, error) {
 
 // IsCollaborator check if a user is collaborator to a repository
 func (c *Client) IsCollaborator(owner, repo, login string) (bool, error) {
+	// Gitea does not list the owner of a user repository as one of its collaborators
+	if c.client.Driver == scm.DriverGitea && owner == login {
+		return true, nil
+	}
 	ctx := context.Background()
 	fullName := c.repositoryName(owner, repo)
 	flag, _, err := c.client.Repositories.IsCollaborator(ctx, fullName, login)
@@ -74,6 +80,9 @@ func (c *Client) CreateStatus(owner, repo, ref string, s *scm.StatusInput) (*scm
 	ctx := context.Background()
 	fullName := c.repositoryName(owner, repo)
 	status, _, err := c.client.Repositories.CreateStatus(ctx, fullName, ref, s)
+	if err == nil {
+		c.audit(audit.Entry{Action: audit.ActionStatus, Org: owner, Repo: repo, Ref: ref, Target: s.Label, Details: fmt.Sprintf("%s: %s", s.State, s.Desc)})
+	}
 	return status, err
 }
 
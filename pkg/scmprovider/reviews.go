@@ -2,8 +2,15 @@ package scmprovider
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/audit"
 	"github.com/pkg/errors"
 )
 
@@ -31,6 +38,55 @@ func (c *Client) ListReviews(owner, repo string, number int) ([]*scm.Review, err
 	return allReviews, nil
 }
 
+// ReviewersByState returns the logins of the reviewers whose latest review approves the pull request and of those
+// whose latest review requests changes. Comments do not change the state of the review of a reviewer.
+func ReviewersByState(reviews []*scm.Review) ([]string, []string) {
+	latest := map[string]string{}
+	for _, r := range reviews {
+		switch r.State {
+		case scm.ReviewStateApproved, scm.ReviewStateChangesRequested, scm.ReviewStateDismissed:
+			latest[r.Author.Login] = r.State
+		}
+	}
+	var approvers, changesRequestedBy []string
+	for login, state := range latest {
+		switch state {
+		case scm.ReviewStateApproved:
+			approvers = append(approvers, login)
+		case scm.ReviewStateChangesRequested:
+			changesRequestedBy = append(changesRequestedBy, login)
+		}
+	}
+	sort.Strings(approvers)
+	sort.Strings(changesRequestedBy)
+	return approvers, changesRequestedBy
+}
+
+// HasUnresolvedDiscussions returns whether a merge request has discussions which must be resolved before merging it.
+// It performs raw requests as go-scm does not expose this API, so it is only supported for GitLab.
+func (c *Client) HasUnresolvedDiscussions(owner, repo string, number int) (bool, error) {
+	if c.client.Driver != scm.DriverGitlab {
+		return false, scm.ErrNotSupported
+	}
+	path := fmt.Sprintf("api/v4/projects/%s/merge_requests/%d", strings.Replace(c.repositoryName(owner, repo), "/", "%2F", -1), number)
+	res, err := c.client.Do(context.Background(), &scm.Request{Method: http.MethodGet, Path: path})
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	if res.Status >= http.StatusMultipleChoices {
+		body, _ := ioutil.ReadAll(res.Body)
+		return false, errors.Errorf("GET %s returned status %d: %s", path, res.Status, string(body))
+	}
+	mr := struct {
+		BlockingDiscussionsResolved bool `json:"blocking_discussions_resolved"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&mr); err != nil {
+		return false, errors.Wrapf(err, "failed to decode merge request %d", number)
+	}
+	return !mr.BlockingDiscussionsResolved, nil
+}
+
 // RequestReview requests a review
 func (c *Client) RequestReview(org, repo string, number int, logins []string) error {
 	ctx := context.Background()
@@ -46,3 +102,76 @@ func (c *Client) UnrequestReview(org, repo string, number int, logins []string)
 	_, err := c.client.PullRequests.UnrequestReview(ctx, fullName, number, logins)
 	return errors.Wrapf(err, "unrequesting review from %s", logins)
 }
+
+const (
+	// ReviewCommentSideRight is the side of the diff of the lines of the new version of a file
+	ReviewCommentSideRight = "RIGHT"
+
+	reviewCommentsMediaType = "application/vnd.github.v3+json"
+	reviewCommentsPerPage   = 100
+)
+
+// ReviewComment is an inline comment of a pull request review, at a line of a file
+type ReviewComment struct {
+	ID   int64  `json:"id,omitempty"`
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Side string `json:"side,omitempty"`
+	Body string `json:"body"`
+	// User is the author of the comment, it is only set for the listed comments
+	User *ReviewCommentUser `json:"user,omitempty"`
+}
+
+// ReviewCommentUser is the author of an inline comment
+type ReviewCommentUser struct {
+	Login string `json:"login"`
+}
+
+// SupportsReviewComments returns true if the underlying provider supports inline review comments at the lines of
+// files. They are created with raw requests as go-scm only supports positions in the diff, so this is only GitHub.
+func (c *Client) SupportsReviewComments() bool {
+	return c.client.Driver == scm.DriverGithub
+}
+
+// ListReviewComments lists the inline review comments of a pull request
+func (c *Client) ListReviewComments(owner, repo string, number int) ([]*ReviewComment, error) {
+	if !c.SupportsReviewComments() {
+		return nil, scm.ErrNotSupported
+	}
+	var answer []*ReviewComment
+	for page := 1; ; page++ {
+		path := fmt.Sprintf("repos/%s/pulls/%d/comments?per_page=%d&page=%d", c.repositoryName(owner, repo), number, reviewCommentsPerPage, page)
+		var comments []*ReviewComment
+		if err := c.doRawRequest(http.MethodGet, path, reviewCommentsMediaType, nil, &comments); err != nil {
+			return nil, err
+		}
+		answer = append(answer, comments...)
+		if len(comments) < reviewCommentsPerPage {
+			return answer, nil
+		}
+	}
+}
+
+// CreateReviewComments creates a review of the commit of a pull request with the body and inline comments
+func (c *Client) CreateReviewComments(owner, repo string, number int, sha, body string, comments []*ReviewComment) error {
+	if !c.SupportsReviewComments() {
+		return scm.ErrNotSupported
+	}
+	path := fmt.Sprintf("repos/%s/pulls/%d/reviews", c.repositoryName(owner, repo), number)
+	review := struct {
+		CommitID string           `json:"commit_id,omitempty"`
+		Body     string           `json:"body,omitempty"`
+		Event    ReviewAction     `json:"event"`
+		Comments []*ReviewComment `json:"comments,omitempty"`
+	}{
+		CommitID: sha,
+		Body:     body,
+		Event:    Comment,
+		Comments: comments,
+	}
+	if err := c.doRawRequest(http.MethodPost, path, reviewCommentsMediaType, &review, nil); err != nil {
+		return err
+	}
+	c.audit(audit.Entry{Action: audit.ActionComment, Org: owner, Repo: repo, Number: number, Details: body})
+	return nil
+}
@@ -0,0 +1,354 @@
+package scmtest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider/azure"
+)
+
+// Azure emulates Azure Repos, the projects of the organization being the orgs of the repositories
+type Azure struct{}
+
+type azureIdentity struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	UniqueName  string `json:"uniqueName"`
+}
+
+type azureRepo struct {
+	ID            string       `json:"id"`
+	Name          string       `json:"name"`
+	Project       azureProject `json:"project"`
+	DefaultBranch string       `json:"defaultBranch"`
+	RemoteURL     string       `json:"remoteUrl"`
+	WebURL        string       `json:"webUrl"`
+}
+
+type azureProject struct {
+	Name string `json:"name"`
+}
+
+type azureStatus struct {
+	State       string             `json:"state"`
+	Description string             `json:"description"`
+	Context     azureStatusContext `json:"context"`
+	TargetURL   string             `json:"targetUrl"`
+}
+
+type azureStatusContext struct {
+	Name  string `json:"name"`
+	Genre string `json:"genre"`
+}
+
+type azureThread struct {
+	ID       int            `json:"id"`
+	Status   string         `json:"status,omitempty"`
+	Comments []azureComment `json:"comments"`
+}
+
+type azureComment struct {
+	ID              int           `json:"id"`
+	Author          azureIdentity `json:"author"`
+	Content         string        `json:"content"`
+	CommentType     string        `json:"commentType"`
+	PublishedDate   time.Time     `json:"publishedDate"`
+	LastUpdatedDate time.Time     `json:"lastUpdatedDate"`
+}
+
+type azureLabel struct {
+	Name string `json:"name"`
+}
+
+type azureItem struct {
+	Path     string `json:"path"`
+	IsFolder bool   `json:"isFolder"`
+	Content  string `json:"content,omitempty"`
+}
+
+// Driver returns azure
+func (Azure) Driver() string {
+	return azure.Name
+}
+
+// Routes returns the routes of the Azure DevOps 6.0 REST API, the comments of a pull request each being in its own
+// thread
+func (p Azure) Routes(s *State) []Route {
+	repo := `^/([^/]+)/_apis/git/repositories/([^/]+)`
+	fullName := func(params []string) string {
+		return params[0] + "/" + params[1]
+	}
+	list := func(w http.ResponseWriter, value interface{}, count int) {
+		WriteJSON(w, http.StatusOK, map[string]interface{}{"count": count, "value": value})
+	}
+	return []Route{
+		{Method: http.MethodGet, Path: regexp.MustCompile(repo + `/pullrequests$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			list(w, []interface{}{}, 0)
+		}},
+		{Method: http.MethodPost, Path: regexp.MustCompile(repo + `/commits/([^/]+)/statuses$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			in := azureStatus{}
+			if err := ReadJSON(r, &in); err != nil {
+				WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			status := &scm.Status{
+				State:  azureState(in.State),
+				Label:  in.Context.Name,
+				Desc:   in.Description,
+				Target: in.TargetURL,
+			}
+			s.Update(fullName(params), func(repo *Repository) {
+				repo.Statuses[params[2]] = append(repo.Statuses[params[2]], status)
+			})
+			WriteJSON(w, http.StatusCreated, in)
+		}},
+		{Method: http.MethodGet, Path: regexp.MustCompile(repo + `/commits/([^/]+)/statuses$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			out := []azureStatus{}
+			s.Update(fullName(params), func(repo *Repository) {
+				for _, status := range repo.Statuses[params[2]] {
+					out = append(out, azureFromStatus(status))
+				}
+			})
+			list(w, out, len(out))
+		}},
+		{Method: http.MethodGet, Path: regexp.MustCompile(repo + `/pullRequests/(\d+)/threads$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			number, _ := strconv.Atoi(params[2])
+			out := []azureThread{}
+			s.Update(fullName(params), func(repo *Repository) {
+				for _, c := range repo.Comments[number] {
+					out = append(out, azureThread{ID: c.ID, Comments: []azureComment{azureFromComment(c)}})
+				}
+			})
+			list(w, out, len(out))
+		}},
+		{Method: http.MethodPost, Path: regexp.MustCompile(repo + `/pullRequests/(\d+)/threads$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			number, _ := strconv.Atoi(params[2])
+			in := azureThread{}
+			if err := ReadJSON(r, &in); err != nil || len(in.Comments) != 1 {
+				WriteError(w, http.StatusBadRequest, "a thread is created with a comment")
+				return
+			}
+			var out azureThread
+			s.Update(fullName(params), func(repo *Repository) {
+				c := s.addComment(repo, number, in.Comments[0].Content)
+				out = azureThread{ID: c.ID, Status: in.Status, Comments: []azureComment{azureFromComment(c)}}
+			})
+			WriteJSON(w, http.StatusOK, out)
+		}},
+		{Method: http.MethodPatch, Path: regexp.MustCompile(repo + `/pullRequests/\d+/threads/(\d+)/comments/1$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			id, _ := strconv.Atoi(params[2])
+			in := azureComment{}
+			if err := ReadJSON(r, &in); err != nil {
+				WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			var out *azureComment
+			s.Update(fullName(params), func(repo *Repository) {
+				if comment := repo.EditComment(id, in.Content); comment != nil {
+					c := azureFromComment(comment)
+					out = &c
+				}
+			})
+			if out == nil {
+				WriteError(w, http.StatusNotFound, "comment does not exist")
+				return
+			}
+			WriteJSON(w, http.StatusOK, out)
+		}},
+		{Method: http.MethodDelete, Path: regexp.MustCompile(repo + `/pullRequests/\d+/threads/(\d+)/comments/1$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			id, _ := strconv.Atoi(params[2])
+			found := false
+			s.Update(fullName(params), func(repo *Repository) {
+				found = repo.DeleteComment(id)
+			})
+			if !found {
+				WriteError(w, http.StatusNotFound, "comment does not exist")
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}},
+		{Method: http.MethodGet, Path: regexp.MustCompile(repo + `/pullRequests/(\d+)/labels$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			number, _ := strconv.Atoi(params[2])
+			out := []azureLabel{}
+			s.Update(fullName(params), func(repo *Repository) {
+				for _, l := range repo.Labels[number] {
+					out = append(out, azureLabel{Name: l})
+				}
+			})
+			list(w, out, len(out))
+		}},
+		{Method: http.MethodPost, Path: regexp.MustCompile(repo + `/pullRequests/(\d+)/labels$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			number, _ := strconv.Atoi(params[2])
+			in := azureLabel{}
+			if err := ReadJSON(r, &in); err != nil {
+				WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			s.Update(fullName(params), func(repo *Repository) {
+				repo.AddLabel(number, in.Name)
+			})
+			WriteJSON(w, http.StatusOK, in)
+		}},
+		{Method: http.MethodDelete, Path: regexp.MustCompile(repo + `/pullRequests/(\d+)/labels/([^/]+)$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			number, _ := strconv.Atoi(params[2])
+			found := false
+			s.Update(fullName(params), func(repo *Repository) {
+				found = repo.RemoveLabel(number, params[3])
+			})
+			if !found {
+				WriteError(w, http.StatusNotFound, "label does not exist")
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}},
+		{Method: http.MethodGet, Path: regexp.MustCompile(repo + `/items$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			query := r.URL.Query()
+			ref := query.Get("versionDescriptor.version")
+			if scope := query.Get("scopePath"); scope != "" {
+				dir := strings.Trim(scope, "/")
+				items := []azureItem{{Path: "/" + dir, IsFolder: true}}
+				s.Update(fullName(params), func(repo *Repository) {
+					paths, dirs := repo.ListDir(ref, dir)
+					for _, path := range paths {
+						items = append(items, azureItem{Path: "/" + path, IsFolder: dirs[path]})
+					}
+				})
+				if len(items) == 1 {
+					WriteError(w, http.StatusNotFound, "folder does not exist")
+					return
+				}
+				list(w, items, len(items))
+				return
+			}
+			path := strings.TrimPrefix(query.Get("path"), "/")
+			var content string
+			var found bool
+			s.Update(fullName(params), func(repo *Repository) {
+				content, found = repo.Files[ref][path]
+			})
+			if !found {
+				WriteError(w, http.StatusNotFound, "item does not exist")
+				return
+			}
+			WriteJSON(w, http.StatusOK, azureItem{Path: "/" + path, Content: content})
+		}},
+	}
+}
+
+// Webhook returns the request delivering the event as a service hook of Azure DevOps would, authenticated with the
+// secret as the password of its basic authentication
+func (p Azure) Webhook(serverURL string, event *Event, secret string) (*http.Request, error) {
+	repo := &Repository{FullName: event.Repo, DefaultBranch: "master"}
+	repository := azureRepo{
+		ID:            repo.FullName,
+		Name:          repo.Name(),
+		Project:       azureProject{Name: repo.Namespace()},
+		DefaultBranch: "refs/heads/" + repo.DefaultBranch,
+		RemoteURL:     fmt.Sprintf("%s/%s/_git/%s", serverURL, repo.Namespace(), repo.Name()),
+		WebURL:        fmt.Sprintf("%s/%s/_git/%s", serverURL, repo.Namespace(), repo.Name()),
+	}
+	sender := azureIdentity{ID: event.Sender, DisplayName: event.Sender, UniqueName: event.Sender}
+	pullRequest := func() map[string]interface{} {
+		return map[string]interface{}{
+			"pullRequestId":         event.Number,
+			"repository":            repository,
+			"status":                "active",
+			"createdBy":             sender,
+			"creationDate":          time.Now(),
+			"title":                 event.Title,
+			"description":           event.Body,
+			"sourceRefName":         "refs/heads/" + event.HeadRef,
+			"targetRefName":         "refs/heads/" + event.BaseRef,
+			"mergeStatus":           "succeeded",
+			"lastMergeSourceCommit": map[string]string{"commitId": event.HeadSHA},
+		}
+	}
+	var payload map[string]interface{}
+	switch event.Kind {
+	case PushEvent:
+		payload = map[string]interface{}{
+			"eventType": "git.push",
+			"resource": map[string]interface{}{
+				"refUpdates": []map[string]string{{"name": event.Ref, "oldObjectId": event.Before, "newObjectId": event.After}},
+				"repository": repository,
+				"pushedBy":   sender,
+			},
+		}
+	case PullRequestOpenedEvent:
+		payload = map[string]interface{}{
+			"eventType": "git.pullrequest.created",
+			"message":   map[string]string{"text": fmt.Sprintf("%s created pull request %d", event.Sender, event.Number)},
+			"resource":  pullRequest(),
+		}
+	case PullRequestCommentEvent:
+		threads := fmt.Sprintf("%s/%s/_apis/git/repositories/%s/pullRequests/%d/threads/%d", serverURL, repo.Namespace(), repo.Name(), event.Number, event.CommentID)
+		payload = map[string]interface{}{
+			"eventType": "ms.vss-code.git-pullrequest-comment-event",
+			"resource": map[string]interface{}{
+				"comment": map[string]interface{}{
+					"id":          1,
+					"author":      sender,
+					"content":     event.Comment,
+					"commentType": "text",
+					"_links": map[string]interface{}{
+						"self":    map[string]string{"href": threads + "/comments/1"},
+						"threads": map[string]string{"href": threads},
+					},
+				},
+				"pullRequest": pullRequest(),
+			},
+		}
+	default:
+		return nil, fmt.Errorf("unsupported event %s", event.Kind)
+	}
+	return signedWebhook(serverURL, payload, nil, func([]byte) map[string]string {
+		return map[string]string{"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(":"+secret))}
+	})
+}
+
+func azureFromStatus(status *scm.Status) azureStatus {
+	states := map[scm.State]string{
+		scm.StatePending: "pending",
+		scm.StateSuccess: "succeeded",
+		scm.StateFailure: "failed",
+		scm.StateError:   "error",
+	}
+	return azureStatus{
+		State:       states[status.State],
+		Description: status.Desc,
+		Context:     azureStatusContext{Name: status.Label, Genre: "lighthouse"},
+		TargetURL:   status.Target,
+	}
+}
+
+func azureState(state string) scm.State {
+	switch state {
+	case "pending":
+		return scm.StatePending
+	case "succeeded":
+		return scm.StateSuccess
+	case "failed":
+		return scm.StateFailure
+	case "error":
+		return scm.StateError
+	default:
+		return scm.StateUnknown
+	}
+}
+
+func azureFromComment(c *scm.Comment) azureComment {
+	return azureComment{
+		ID:              1,
+		Author:          azureIdentity{DisplayName: c.Author.Login, UniqueName: c.Author.Login},
+		Content:         c.Body,
+		CommentType:     "text",
+		PublishedDate:   c.Created,
+		LastUpdatedDate: c.Updated,
+	}
+}
@@ -0,0 +1,240 @@
+package scmtest
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	conformanceOrg    = "org"
+	conformanceRepo   = "repo"
+	conformanceSecret = "webhook-secret"
+)
+
+// RunConformance checks that the provider behaves as Lighthouse expects: its webhooks are parsed into the expected
+// events and the statuses, comments, labels and files used by the plugins round trip through the scmprovider client.
+func RunConformance(t *testing.T, provider Provider) {
+	s := NewServer(provider)
+	defer s.Close()
+	client, err := s.Client()
+	require.NoError(t, err)
+
+	t.Run("webhooks", func(t *testing.T) { testWebhooks(t, s) })
+	t.Run("statuses", func(t *testing.T) { testStatuses(t, client) })
+	t.Run("pull request comments", func(t *testing.T) { testComments(t, client, 1, true) })
+	t.Run("issue comments", func(t *testing.T) { testComments(t, client, 2, false) })
+	t.Run("labels", func(t *testing.T) { testLabels(t, client) })
+	t.Run("files", func(t *testing.T) { testFiles(t, s, client) })
+}
+
+func testWebhooks(t *testing.T, s *Server) {
+	fullName := conformanceOrg + "/" + conformanceRepo
+	parseHook := func(t *testing.T, event *Event, secret string) (scm.Webhook, error) {
+		req, err := s.Webhook(event, secret)
+		require.NoError(t, err)
+		client, err := s.Client()
+		require.NoError(t, err)
+		return client.ToScmClient().Webhooks.Parse(req, func(scm.Webhook) (string, error) {
+			return conformanceSecret, nil
+		})
+	}
+
+	t.Run("push", func(t *testing.T) {
+		hook, err := parseHook(t, &Event{
+			Kind:   PushEvent,
+			Repo:   fullName,
+			Sender: "alice",
+			Ref:    "refs/heads/master",
+			Before: "1111111111111111111111111111111111111111",
+			After:  "2222222222222222222222222222222222222222",
+		}, conformanceSecret)
+		require.NoError(t, err)
+		push, ok := hook.(*scm.PushHook)
+		require.True(t, ok, "expected a push hook but got %T", hook)
+		assert.Equal(t, fullName, push.Repo.FullName)
+		assert.Equal(t, "refs/heads/master", push.Ref)
+		// not all the drivers populate After, the SHA of the pushed commit is the one of the head commit
+		assert.Equal(t, "2222222222222222222222222222222222222222", push.Commit.Sha)
+		assert.Equal(t, "alice", push.Sender.Login)
+	})
+
+	t.Run("pull request opened", func(t *testing.T) {
+		hook, err := parseHook(t, &Event{
+			Kind:    PullRequestOpenedEvent,
+			Repo:    fullName,
+			Sender:  "alice",
+			Number:  3,
+			Title:   "Add a feature",
+			Body:    "Adds a feature",
+			HeadRef: "feature",
+			BaseRef: "master",
+			HeadSHA: "3333333333333333333333333333333333333333",
+		}, conformanceSecret)
+		require.NoError(t, err)
+		pr, ok := hook.(*scm.PullRequestHook)
+		require.True(t, ok, "expected a pull request hook but got %T", hook)
+		assert.Equal(t, scm.ActionOpen, pr.Action)
+		assert.Equal(t, fullName, pr.Repo.FullName)
+		assert.Equal(t, 3, pr.PullRequest.Number)
+		assert.Equal(t, "Add a feature", pr.PullRequest.Title)
+		assert.Equal(t, "3333333333333333333333333333333333333333", pr.PullRequest.Sha)
+		assert.Equal(t, "feature", pr.PullRequest.Source)
+		assert.Equal(t, "master", pr.PullRequest.Target)
+		assert.Equal(t, "alice", pr.Sender.Login)
+	})
+
+	t.Run("pull request comment", func(t *testing.T) {
+		hook, err := parseHook(t, &Event{
+			Kind:      PullRequestCommentEvent,
+			Repo:      fullName,
+			Sender:    "alice",
+			Number:    3,
+			Title:     "Add a feature",
+			HeadRef:   "feature",
+			BaseRef:   "master",
+			HeadSHA:   "3333333333333333333333333333333333333333",
+			CommentID: 42,
+			Comment:   "/lgtm",
+		}, conformanceSecret)
+		require.NoError(t, err)
+		// providers report comments on pull requests either as issue or pull request comments
+		switch comment := hook.(type) {
+		case *scm.IssueCommentHook:
+			assert.True(t, comment.Issue.PullRequest, "the issue is a pull request")
+			assert.Equal(t, fullName, comment.Repo.FullName)
+			assert.Equal(t, 3, comment.Issue.Number)
+			assert.Equal(t, "/lgtm", comment.Comment.Body)
+			assert.Equal(t, "alice", comment.Sender.Login)
+		case *scm.PullRequestCommentHook:
+			assert.Equal(t, fullName, comment.Repo.FullName)
+			assert.Equal(t, 3, comment.PullRequest.Number)
+			assert.Equal(t, "/lgtm", comment.Comment.Body)
+			assert.Equal(t, "alice", comment.Sender.Login)
+		default:
+			require.Fail(t, "expected a comment hook", "got %T", hook)
+		}
+	})
+
+	t.Run("invalid secret", func(t *testing.T) {
+		_, err := parseHook(t, &Event{
+			Kind:   PushEvent,
+			Repo:   fullName,
+			Sender: "alice",
+			Ref:    "refs/heads/master",
+			After:  "2222222222222222222222222222222222222222",
+		}, "wrong-secret")
+		assert.Equal(t, scm.ErrSignatureInvalid, err)
+	})
+}
+
+func testStatuses(t *testing.T, client *scmprovider.Client) {
+	sha := "4444444444444444444444444444444444444444"
+	_, err := client.CreateStatus(conformanceOrg, conformanceRepo, sha, &scm.StatusInput{
+		State:  scm.StatePending,
+		Label:  "pr-build",
+		Desc:   "Build started",
+		Target: "https://example.com/build/1",
+	})
+	require.NoError(t, err)
+	_, err = client.CreateStatus(conformanceOrg, conformanceRepo, sha, &scm.StatusInput{
+		State: scm.StateSuccess,
+		Label: "lint",
+		Desc:  "Lint passed",
+	})
+	require.NoError(t, err)
+
+	statuses, err := client.ListStatuses(conformanceOrg, conformanceRepo, sha)
+	require.NoError(t, err)
+	byLabel := map[string]*scm.Status{}
+	for _, status := range statuses {
+		byLabel[status.Label] = status
+	}
+	require.Contains(t, byLabel, "pr-build")
+	require.Contains(t, byLabel, "lint")
+	assert.Equal(t, scm.StatePending, byLabel["pr-build"].State)
+	assert.Equal(t, "Build started", byLabel["pr-build"].Desc)
+	assert.Equal(t, "https://example.com/build/1", byLabel["pr-build"].Target)
+	assert.Equal(t, scm.StateSuccess, byLabel["lint"].State)
+}
+
+func testComments(t *testing.T, client *scmprovider.Client, number int, pr bool) {
+	list := func() []*scm.Comment {
+		var comments []*scm.Comment
+		var err error
+		if pr {
+			comments, err = client.ListPullRequestComments(conformanceOrg, conformanceRepo, number)
+		} else {
+			comments, err = client.ListIssueComments(conformanceOrg, conformanceRepo, number)
+		}
+		require.NoError(t, err)
+		return comments
+	}
+
+	require.NoError(t, client.CreateComment(conformanceOrg, conformanceRepo, number, pr, "first"))
+	require.NoError(t, client.CreateComment(conformanceOrg, conformanceRepo, number, pr, "second"))
+	comments := list()
+	require.Len(t, comments, 2)
+	assert.Equal(t, "first", comments[0].Body)
+	assert.Equal(t, "second", comments[1].Body)
+	assert.Equal(t, BotName, comments[0].Author.Login, "the comments are authored by the bot")
+
+	require.NoError(t, client.EditComment(conformanceOrg, conformanceRepo, number, comments[0].ID, "edited", pr))
+	require.NoError(t, client.DeleteComment(conformanceOrg, conformanceRepo, number, comments[1].ID, pr))
+	comments = list()
+	require.Len(t, comments, 1)
+	assert.Equal(t, "edited", comments[0].Body)
+}
+
+func testLabels(t *testing.T, client *scmprovider.Client) {
+	number := 3
+	names := func() []string {
+		labels, err := client.GetIssueLabels(conformanceOrg, conformanceRepo, number, true)
+		require.NoError(t, err)
+		var answer []string
+		for _, l := range labels {
+			answer = append(answer, l.Name)
+		}
+		sort.Strings(answer)
+		return answer
+	}
+
+	require.NoError(t, client.AddLabel(conformanceOrg, conformanceRepo, number, "lgtm", true))
+	require.NoError(t, client.AddLabel(conformanceOrg, conformanceRepo, number, "approved", true))
+	assert.Equal(t, []string{"approved", "lgtm"}, names())
+
+	require.NoError(t, client.RemoveLabel(conformanceOrg, conformanceRepo, number, "lgtm", true))
+	assert.Equal(t, []string{"approved"}, names())
+}
+
+func testFiles(t *testing.T, s *Server, client *scmprovider.Client) {
+	fullName := conformanceOrg + "/" + conformanceRepo
+	s.AddFile(fullName, "master", "OWNERS", "approvers:\n- alice\n")
+	s.AddFile(fullName, "master", ".lighthouse/jenkins-x/triggers.yaml", "presubmits: []\n")
+	s.AddFile(fullName, "master", ".lighthouse/jenkins-x/pullrequest.yaml", "kind: PipelineRun\n")
+
+	data, err := client.GetFile(conformanceOrg, conformanceRepo, "OWNERS", "master")
+	require.NoError(t, err)
+	assert.Equal(t, "approvers:\n- alice\n", string(data))
+
+	data, err = client.GetFile(conformanceOrg, conformanceRepo, ".lighthouse/jenkins-x/triggers.yaml", "master")
+	require.NoError(t, err)
+	assert.Equal(t, "presubmits: []\n", string(data))
+
+	data, err = client.GetFile(conformanceOrg, conformanceRepo, "missing.yaml", "master")
+	require.NoError(t, err, "missing files are not an error")
+	assert.Nil(t, data)
+
+	entries, err := client.ListFiles(conformanceOrg, conformanceRepo, ".lighthouse/jenkins-x", "master")
+	require.NoError(t, err)
+	var paths []string
+	for _, e := range entries {
+		paths = append(paths, e.Path)
+	}
+	sort.Strings(paths)
+	assert.Equal(t, []string{".lighthouse/jenkins-x/pullrequest.yaml", ".lighthouse/jenkins-x/triggers.yaml"}, paths)
+}
@@ -0,0 +1,19 @@
+package scmtest
+
+import "testing"
+
+func TestGitHubConformance(t *testing.T) {
+	RunConformance(t, GitHub{})
+}
+
+func TestGitLabConformance(t *testing.T) {
+	RunConformance(t, GitLab{})
+}
+
+func TestGiteaConformance(t *testing.T) {
+	RunConformance(t, Gitea{})
+}
+
+func TestAzureConformance(t *testing.T) {
+	RunConformance(t, Azure{})
+}
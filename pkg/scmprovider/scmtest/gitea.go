@@ -0,0 +1,372 @@
+package scmtest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// Gitea emulates Gitea
+type Gitea struct{}
+
+type giteaUser struct {
+	ID       int    `json:"id"`
+	Login    string `json:"login"`
+	Username string `json:"username"`
+}
+
+type giteaRepo struct {
+	ID            int       `json:"id"`
+	Owner         giteaUser `json:"owner"`
+	Name          string    `json:"name"`
+	FullName      string    `json:"full_name"`
+	DefaultBranch string    `json:"default_branch"`
+	HTMLURL       string    `json:"html_url"`
+	CloneURL      string    `json:"clone_url"`
+}
+
+type giteaStatus struct {
+	// State is the state of a status being created, Status the one of a status being listed
+	State       string `json:"state,omitempty"`
+	Status      string `json:"status,omitempty"`
+	Context     string `json:"context"`
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url"`
+}
+
+type giteaComment struct {
+	ID        int       `json:"id"`
+	User      giteaUser `json:"user"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type giteaLabel struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type giteaEntry struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Type string `json:"type"`
+	Size int    `json:"size"`
+}
+
+// Driver returns gitea
+func (Gitea) Driver() string {
+	return "gitea"
+}
+
+// Routes returns the routes of the Gitea v1 REST API
+func (p Gitea) Routes(s *State) []Route {
+	repo := `^/api/v1/repos/([^/]+/[^/]+)`
+	return []Route{
+		{Method: http.MethodGet, Path: regexp.MustCompile(`^/api/v1/version$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			WriteJSON(w, http.StatusOK, map[string]string{"version": "1.13.0"})
+		}},
+		{Method: http.MethodPost, Path: regexp.MustCompile(repo + `/statuses/([^/]+)$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			in := giteaStatus{}
+			if err := ReadJSON(r, &in); err != nil {
+				WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			status := &scm.Status{
+				State:  giteaState(in.State),
+				Label:  in.Context,
+				Desc:   in.Description,
+				Target: in.TargetURL,
+			}
+			s.Update(params[0], func(repo *Repository) {
+				repo.Statuses[params[1]] = append(repo.Statuses[params[1]], status)
+			})
+			WriteJSON(w, http.StatusCreated, giteaFromStatus(status))
+		}},
+		{Method: http.MethodGet, Path: regexp.MustCompile(repo + `/commits/([^/]+)/statuses$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			out := []giteaStatus{}
+			s.Update(params[0], func(repo *Repository) {
+				for _, status := range repo.Statuses[params[1]] {
+					out = append(out, giteaFromStatus(status))
+				}
+			})
+			WriteJSON(w, http.StatusOK, out)
+		}},
+		{Method: http.MethodGet, Path: regexp.MustCompile(repo + `/issues/(\d+)/comments$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			number, _ := strconv.Atoi(params[1])
+			out := []giteaComment{}
+			s.Update(params[0], func(repo *Repository) {
+				for _, c := range repo.Comments[number] {
+					out = append(out, giteaFromComment(c))
+				}
+			})
+			WriteJSON(w, http.StatusOK, out)
+		}},
+		{Method: http.MethodPost, Path: regexp.MustCompile(repo + `/issues/(\d+)/comments$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			number, _ := strconv.Atoi(params[1])
+			in := giteaComment{}
+			if err := ReadJSON(r, &in); err != nil {
+				WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			var out giteaComment
+			s.Update(params[0], func(repo *Repository) {
+				out = giteaFromComment(s.addComment(repo, number, in.Body))
+			})
+			WriteJSON(w, http.StatusCreated, out)
+		}},
+		{Method: http.MethodPatch, Path: regexp.MustCompile(repo + `/issues/comments/(\d+)$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			id, _ := strconv.Atoi(params[1])
+			in := giteaComment{}
+			if err := ReadJSON(r, &in); err != nil {
+				WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			var out *giteaComment
+			s.Update(params[0], func(repo *Repository) {
+				if comment := repo.EditComment(id, in.Body); comment != nil {
+					c := giteaFromComment(comment)
+					out = &c
+				}
+			})
+			if out == nil {
+				WriteError(w, http.StatusNotFound, "comment does not exist")
+				return
+			}
+			WriteJSON(w, http.StatusOK, out)
+		}},
+		{Method: http.MethodDelete, Path: regexp.MustCompile(repo + `/issues/comments/(\d+)$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			id, _ := strconv.Atoi(params[1])
+			deleted := false
+			s.Update(params[0], func(repo *Repository) {
+				deleted = repo.DeleteComment(id)
+			})
+			if !deleted {
+				WriteError(w, http.StatusNotFound, "comment does not exist")
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}},
+		{Method: http.MethodGet, Path: regexp.MustCompile(repo + `/labels$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			out := []giteaLabel{}
+			s.Update(params[0], func(repo *Repository) {
+				for name, id := range repo.LabelIDs {
+					out = append(out, giteaLabel{ID: id, Name: name})
+				}
+			})
+			sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+			WriteJSON(w, http.StatusOK, out)
+		}},
+		{Method: http.MethodPost, Path: regexp.MustCompile(repo + `/labels$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			in := giteaLabel{}
+			if err := ReadJSON(r, &in); err != nil {
+				WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			s.Update(params[0], func(repo *Repository) {
+				in.ID = s.labelID(repo, in.Name)
+			})
+			WriteJSON(w, http.StatusCreated, in)
+		}},
+		{Method: http.MethodGet, Path: regexp.MustCompile(repo + `/issues/(\d+)/labels$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			number, _ := strconv.Atoi(params[1])
+			out := []giteaLabel{}
+			s.Update(params[0], func(repo *Repository) {
+				for _, l := range repo.Labels[number] {
+					out = append(out, giteaLabel{ID: s.labelID(repo, l), Name: l})
+				}
+			})
+			WriteJSON(w, http.StatusOK, out)
+		}},
+		{Method: http.MethodPost, Path: regexp.MustCompile(repo + `/issues/(\d+)/labels$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			number, _ := strconv.Atoi(params[1])
+			in := struct {
+				Labels []int `json:"labels"`
+			}{}
+			if err := ReadJSON(r, &in); err != nil {
+				WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			out := []giteaLabel{}
+			s.Update(params[0], func(repo *Repository) {
+				for name, id := range repo.LabelIDs {
+					for _, l := range in.Labels {
+						if l == id {
+							repo.AddLabel(number, name)
+						}
+					}
+				}
+				for _, l := range repo.Labels[number] {
+					out = append(out, giteaLabel{ID: s.labelID(repo, l), Name: l})
+				}
+			})
+			WriteJSON(w, http.StatusOK, out)
+		}},
+		{Method: http.MethodDelete, Path: regexp.MustCompile(repo + `/issues/(\d+)/labels/(\d+)$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			number, _ := strconv.Atoi(params[1])
+			id, _ := strconv.Atoi(params[2])
+			removed := false
+			s.Update(params[0], func(repo *Repository) {
+				for name, labelID := range repo.LabelIDs {
+					if labelID == id {
+						removed = repo.RemoveLabel(number, name)
+					}
+				}
+			})
+			if !removed {
+				WriteError(w, http.StatusNotFound, "label does not exist")
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}},
+		{Method: http.MethodGet, Path: regexp.MustCompile(repo + `/raw/([^/]+)/(.*)$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			var content *string
+			s.Update(params[0], func(repo *Repository) {
+				if c, ok := repo.Files[params[1]][params[2]]; ok {
+					content = &c
+				}
+			})
+			if content == nil {
+				WriteError(w, http.StatusNotFound, "file does not exist")
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(*content))
+		}},
+		{Method: http.MethodGet, Path: regexp.MustCompile(repo + `/contents/(.*)$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			ref := r.URL.Query().Get("ref")
+			entries := []giteaEntry{}
+			s.Update(params[0], func(repo *Repository) {
+				paths, dirs := repo.ListDir(ref, params[1])
+				for _, p := range paths {
+					entry := giteaEntry{Name: path.Base(p), Path: p, Type: "file", Size: len(repo.Files[ref][p])}
+					if dirs[p] {
+						entry.Type = "dir"
+						entry.Size = 0
+					}
+					entries = append(entries, entry)
+				}
+			})
+			if len(entries) == 0 {
+				WriteError(w, http.StatusNotFound, "directory does not exist")
+				return
+			}
+			WriteJSON(w, http.StatusOK, entries)
+		}},
+	}
+}
+
+// Webhook returns the request delivering the event as Gitea would, signed with a SHA-256 HMAC of the payload
+func (p Gitea) Webhook(serverURL string, event *Event, secret string) (*http.Request, error) {
+	repo := &Repository{FullName: event.Repo, DefaultBranch: "master"}
+	owner := giteaUser{Login: repo.Namespace(), Username: repo.Namespace()}
+	repository := giteaRepo{
+		Owner:         owner,
+		Name:          repo.Name(),
+		FullName:      repo.FullName,
+		DefaultBranch: repo.DefaultBranch,
+		HTMLURL:       serverURL + "/" + repo.FullName,
+		CloneURL:      serverURL + "/" + repo.FullName + ".git",
+	}
+	sender := giteaUser{Login: event.Sender, Username: event.Sender}
+	now := time.Now()
+	var kind string
+	var payload interface{}
+	switch event.Kind {
+	case PushEvent:
+		kind = "push"
+		payload = map[string]interface{}{
+			"ref":        event.Ref,
+			"before":     event.Before,
+			"after":      event.After,
+			"commits":    []interface{}{},
+			"repository": repository,
+			"pusher":     sender,
+			"sender":     sender,
+		}
+	case PullRequestOpenedEvent:
+		kind = "pull_request"
+		branch := func(ref, sha string) map[string]interface{} {
+			return map[string]interface{}{"label": ref, "ref": ref, "sha": sha, "repo": repository}
+		}
+		payload = map[string]interface{}{
+			"action": "opened",
+			"number": event.Number,
+			"pull_request": map[string]interface{}{
+				"number":     event.Number,
+				"title":      event.Title,
+				"body":       event.Body,
+				"state":      "open",
+				"html_url":   fmt.Sprintf("%s/%s/pulls/%d", serverURL, event.Repo, event.Number),
+				"user":       sender,
+				"head":       branch(event.HeadRef, event.HeadSHA),
+				"base":       branch(event.BaseRef, ""),
+				"created_at": now,
+				"updated_at": now,
+			},
+			"repository": repository,
+			"sender":     sender,
+		}
+	case PullRequestCommentEvent:
+		kind = "issue_comment"
+		payload = map[string]interface{}{
+			"action": "created",
+			"issue": map[string]interface{}{
+				"number":       event.Number,
+				"title":        event.Title,
+				"body":         event.Body,
+				"state":        "open",
+				"user":         sender,
+				"url":          fmt.Sprintf("%s/%s/pulls/%d", serverURL, event.Repo, event.Number),
+				"pull_request": map[string]interface{}{"merged": false},
+			},
+			"comment":    giteaComment{ID: event.CommentID, User: sender, Body: event.Comment},
+			"repository": repository,
+			"sender":     sender,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported event %s", event.Kind)
+	}
+	return signedWebhook(serverURL, payload, map[string]string{
+		"X-Gitea-Event":    kind,
+		"X-Gitea-Delivery": strconv.FormatInt(time.Now().UnixNano(), 10),
+	}, func(body []byte) map[string]string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		_, _ = mac.Write(body)
+		return map[string]string{"X-Gitea-Signature": hex.EncodeToString(mac.Sum(nil))}
+	})
+}
+
+func giteaFromComment(c *scm.Comment) giteaComment {
+	return giteaComment{
+		ID:        c.ID,
+		User:      giteaUser{Login: c.Author.Login, Username: c.Author.Login},
+		Body:      c.Body,
+		CreatedAt: c.Created,
+		UpdatedAt: c.Updated,
+	}
+}
+
+func giteaFromStatus(status *scm.Status) giteaStatus {
+	return giteaStatus{
+		Status:      githubFromState(status.State),
+		Context:     status.Label,
+		Description: status.Desc,
+		TargetURL:   status.Target,
+	}
+}
+
+// giteaState converts the state of a status, which Gitea names as GitHub does
+func giteaState(state string) scm.State {
+	return githubState(state)
+}
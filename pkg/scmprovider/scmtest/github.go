@@ -0,0 +1,337 @@
+package scmtest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// GitHub emulates GitHub and GitHub Enterprise
+type GitHub struct{}
+
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+type githubRepo struct {
+	ID            int        `json:"id"`
+	Owner         githubUser `json:"owner"`
+	Name          string     `json:"name"`
+	FullName      string     `json:"full_name"`
+	DefaultBranch string     `json:"default_branch"`
+	HTMLURL       string     `json:"html_url"`
+	CloneURL      string     `json:"clone_url"`
+}
+
+type githubStatus struct {
+	State       string `json:"state"`
+	Context     string `json:"context"`
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url"`
+}
+
+type githubComment struct {
+	ID        int        `json:"id"`
+	User      githubUser `json:"user"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type githubEntry struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Type    string `json:"type"`
+	Size    int    `json:"size"`
+	Content string `json:"content,omitempty"`
+}
+
+// Driver returns github
+func (GitHub) Driver() string {
+	return "github"
+}
+
+// Routes returns the routes of the GitHub REST API, which the clients of GitHub Enterprise prefix with /api/v3
+func (p GitHub) Routes(s *State) []Route {
+	repo := `^/api/v3/repos/([^/]+/[^/]+)`
+	return []Route{
+		{Method: http.MethodPost, Path: regexp.MustCompile(repo + `/statuses/([^/]+)$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			in := githubStatus{}
+			if err := ReadJSON(r, &in); err != nil {
+				WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			s.Update(params[0], func(repo *Repository) {
+				repo.Statuses[params[1]] = append(repo.Statuses[params[1]], &scm.Status{
+					State:  githubState(in.State),
+					Label:  in.Context,
+					Desc:   in.Description,
+					Target: in.TargetURL,
+				})
+			})
+			WriteJSON(w, http.StatusCreated, in)
+		}},
+		{Method: http.MethodGet, Path: regexp.MustCompile(repo + `/statuses/([^/]+)$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			out := []githubStatus{}
+			s.Update(params[0], func(repo *Repository) {
+				// newest first
+				statuses := repo.Statuses[params[1]]
+				for i := len(statuses) - 1; i >= 0; i-- {
+					out = append(out, githubStatus{
+						State:       githubFromState(statuses[i].State),
+						Context:     statuses[i].Label,
+						Description: statuses[i].Desc,
+						TargetURL:   statuses[i].Target,
+					})
+				}
+			})
+			WriteJSON(w, http.StatusOK, out)
+		}},
+		{Method: http.MethodGet, Path: regexp.MustCompile(repo + `/issues/(\d+)/comments$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			number, _ := strconv.Atoi(params[1])
+			out := []githubComment{}
+			s.Update(params[0], func(repo *Repository) {
+				for _, c := range repo.Comments[number] {
+					out = append(out, githubFromComment(c))
+				}
+			})
+			WriteJSON(w, http.StatusOK, out)
+		}},
+		{Method: http.MethodPost, Path: regexp.MustCompile(repo + `/issues/(\d+)/comments$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			number, _ := strconv.Atoi(params[1])
+			in := githubComment{}
+			if err := ReadJSON(r, &in); err != nil {
+				WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			var out githubComment
+			s.Update(params[0], func(repo *Repository) {
+				out = githubFromComment(s.addComment(repo, number, in.Body))
+			})
+			WriteJSON(w, http.StatusCreated, out)
+		}},
+		{Method: http.MethodPatch, Path: regexp.MustCompile(repo + `/issues/comments/(\d+)$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			id, _ := strconv.Atoi(params[1])
+			in := githubComment{}
+			if err := ReadJSON(r, &in); err != nil {
+				WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			var out *githubComment
+			s.Update(params[0], func(repo *Repository) {
+				if comment := repo.EditComment(id, in.Body); comment != nil {
+					c := githubFromComment(comment)
+					out = &c
+				}
+			})
+			if out == nil {
+				WriteError(w, http.StatusNotFound, "Not Found")
+				return
+			}
+			WriteJSON(w, http.StatusOK, out)
+		}},
+		{Method: http.MethodDelete, Path: regexp.MustCompile(repo + `/issues/comments/(\d+)$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			id, _ := strconv.Atoi(params[1])
+			deleted := false
+			s.Update(params[0], func(repo *Repository) {
+				deleted = repo.DeleteComment(id)
+			})
+			if !deleted {
+				WriteError(w, http.StatusNotFound, "Not Found")
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}},
+		{Method: http.MethodGet, Path: regexp.MustCompile(repo + `/issues/(\d+)/labels$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			number, _ := strconv.Atoi(params[1])
+			out := []map[string]string{}
+			s.Update(params[0], func(repo *Repository) {
+				for _, l := range repo.Labels[number] {
+					out = append(out, map[string]string{"name": l})
+				}
+			})
+			WriteJSON(w, http.StatusOK, out)
+		}},
+		{Method: http.MethodPost, Path: regexp.MustCompile(repo + `/issues/(\d+)/labels$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			number, _ := strconv.Atoi(params[1])
+			var in []string
+			if err := ReadJSON(r, &in); err != nil {
+				WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			s.Update(params[0], func(repo *Repository) {
+				for _, l := range in {
+					repo.AddLabel(number, l)
+				}
+			})
+			WriteJSON(w, http.StatusOK, []interface{}{})
+		}},
+		{Method: http.MethodDelete, Path: regexp.MustCompile(repo + `/issues/(\d+)/labels/([^/]+)$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			number, _ := strconv.Atoi(params[1])
+			removed := false
+			s.Update(params[0], func(repo *Repository) {
+				removed = repo.RemoveLabel(number, params[2])
+			})
+			if !removed {
+				WriteError(w, http.StatusNotFound, "Label does not exist")
+				return
+			}
+			WriteJSON(w, http.StatusOK, []interface{}{})
+		}},
+		{Method: http.MethodGet, Path: regexp.MustCompile(repo + `/contents/(.*)$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			ref := r.URL.Query().Get("ref")
+			var file *githubEntry
+			entries := []githubEntry{}
+			s.Update(params[0], func(repo *Repository) {
+				if content, ok := repo.Files[ref][params[1]]; ok {
+					file = &githubEntry{
+						Name:    path.Base(params[1]),
+						Path:    params[1],
+						Type:    "file",
+						Size:    len(content),
+						Content: base64.StdEncoding.EncodeToString([]byte(content)),
+					}
+					return
+				}
+				paths, dirs := repo.ListDir(ref, params[1])
+				for _, p := range paths {
+					entry := githubEntry{Name: path.Base(p), Path: p, Type: "file", Size: len(repo.Files[ref][p])}
+					if dirs[p] {
+						entry.Type = "dir"
+						entry.Size = 0
+					}
+					entries = append(entries, entry)
+				}
+			})
+			switch {
+			case file != nil:
+				WriteJSON(w, http.StatusOK, file)
+			case len(entries) > 0:
+				WriteJSON(w, http.StatusOK, entries)
+			default:
+				WriteError(w, http.StatusNotFound, "Not Found")
+			}
+		}},
+	}
+}
+
+// Webhook returns the request delivering the event as GitHub would, signed with a SHA-256 HMAC of the payload
+func (p GitHub) Webhook(serverURL string, event *Event, secret string) (*http.Request, error) {
+	repo := &Repository{FullName: event.Repo, DefaultBranch: "master"}
+	repository := githubRepo{
+		Owner:         githubUser{Login: repo.Namespace()},
+		Name:          repo.Name(),
+		FullName:      repo.FullName,
+		DefaultBranch: repo.DefaultBranch,
+		HTMLURL:       serverURL + "/" + repo.FullName,
+		CloneURL:      serverURL + "/" + repo.FullName + ".git",
+	}
+	sender := githubUser{Login: event.Sender}
+	var kind string
+	var payload interface{}
+	switch event.Kind {
+	case PushEvent:
+		kind = "push"
+		payload = map[string]interface{}{
+			"ref":         event.Ref,
+			"before":      event.Before,
+			"after":       event.After,
+			"head_commit": map[string]interface{}{"id": event.After},
+			"repository":  repository,
+			"sender":      sender,
+		}
+	case PullRequestOpenedEvent:
+		kind = "pull_request"
+		payload = map[string]interface{}{
+			"action":       "opened",
+			"number":       event.Number,
+			"pull_request": githubPullRequest(serverURL, event, repository),
+			"repository":   repository,
+			"sender":       sender,
+		}
+	case PullRequestCommentEvent:
+		kind = "issue_comment"
+		payload = map[string]interface{}{
+			"action": "created",
+			"issue": map[string]interface{}{
+				"number":       event.Number,
+				"title":        event.Title,
+				"body":         event.Body,
+				"state":        "open",
+				"html_url":     fmt.Sprintf("%s/%s/pull/%d", serverURL, event.Repo, event.Number),
+				"pull_request": map[string]interface{}{},
+			},
+			"comment":    githubComment{ID: event.CommentID, User: sender, Body: event.Comment},
+			"repository": repository,
+			"sender":     sender,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported event %s", event.Kind)
+	}
+	return signedWebhook(serverURL, payload, map[string]string{
+		"X-GitHub-Event":    kind,
+		"X-GitHub-Delivery": strconv.FormatInt(time.Now().UnixNano(), 10),
+	}, func(body []byte) map[string]string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		_, _ = mac.Write(body)
+		return map[string]string{"X-Hub-Signature": "sha256=" + hex.EncodeToString(mac.Sum(nil))}
+	})
+}
+
+func githubPullRequest(serverURL string, event *Event, repository githubRepo) map[string]interface{} {
+	return map[string]interface{}{
+		"number":   event.Number,
+		"state":    "open",
+		"title":    event.Title,
+		"body":     event.Body,
+		"html_url": fmt.Sprintf("%s/%s/pull/%d", serverURL, event.Repo, event.Number),
+		"user":     githubUser{Login: event.Sender},
+		"head":     map[string]interface{}{"ref": event.HeadRef, "sha": event.HeadSHA, "repo": repository},
+		"base":     map[string]interface{}{"ref": event.BaseRef, "repo": repository},
+	}
+}
+
+func githubFromComment(c *scm.Comment) githubComment {
+	return githubComment{
+		ID:        c.ID,
+		User:      githubUser{Login: c.Author.Login},
+		Body:      c.Body,
+		CreatedAt: c.Created,
+		UpdatedAt: c.Updated,
+	}
+}
+
+func githubState(state string) scm.State {
+	switch state {
+	case "pending":
+		return scm.StatePending
+	case "success":
+		return scm.StateSuccess
+	case "failure":
+		return scm.StateFailure
+	default:
+		return scm.StateError
+	}
+}
+
+func githubFromState(state scm.State) string {
+	switch state {
+	case scm.StatePending, scm.StateRunning:
+		return "pending"
+	case scm.StateSuccess:
+		return "success"
+	case scm.StateFailure:
+		return "failure"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,394 @@
+package scmtest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// GitLab emulates GitLab, whose issues and merge requests share the same numbers in the fake server
+type GitLab struct{}
+
+type gitlabUser struct {
+	Username string `json:"username"`
+	Name     string `json:"name,omitempty"`
+}
+
+type gitlabNamespace struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+type gitlabProject struct {
+	ID                int             `json:"id"`
+	Name              string          `json:"name"`
+	Path              string          `json:"path"`
+	PathWithNamespace string          `json:"path_with_namespace"`
+	Namespace         gitlabNamespace `json:"namespace"`
+	DefaultBranch     string          `json:"default_branch"`
+	Visibility        string          `json:"visibility"`
+	WebURL            string          `json:"web_url"`
+	HTTPURL           string          `json:"http_url_to_repo"`
+}
+
+// gitlabHookProject is the project in the webhook payloads, whose namespace is a string
+type gitlabHookProject struct {
+	ID                int    `json:"id"`
+	Name              string `json:"name"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	Namespace         string `json:"namespace"`
+	DefaultBranch     string `json:"default_branch"`
+	WebURL            string `json:"web_url"`
+	GitHTTPURL        string `json:"git_http_url"`
+}
+
+type gitlabNote struct {
+	ID        int        `json:"id"`
+	Number    int        `json:"noteable_iid"`
+	Author    gitlabUser `json:"author"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type gitlabStatus struct {
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url"`
+	Sha         string `json:"sha"`
+}
+
+// Driver returns gitlab
+func (GitLab) Driver() string {
+	return "gitlab"
+}
+
+// Routes returns the routes of the GitLab v4 REST API, the projects being identified by their ID or escaped full name
+func (p GitLab) Routes(s *State) []Route {
+	project := `^/api/v4/projects/([^/]+)`
+	return []Route{
+		{Method: http.MethodGet, Path: regexp.MustCompile(project + `$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			var out *gitlabProject
+			gitlabUpdate(s, params[0], func(repo *Repository) {
+				p := gitlabFromRepository(repo, "http://"+r.Host)
+				out = &p
+			})
+			if out == nil {
+				WriteError(w, http.StatusNotFound, "404 Project Not Found")
+				return
+			}
+			WriteJSON(w, http.StatusOK, out)
+		}},
+		{Method: http.MethodGet, Path: regexp.MustCompile(project + `/(issues|merge_requests)/(\d+)$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			number, _ := strconv.Atoi(params[2])
+			var out map[string]interface{}
+			gitlabUpdate(s, params[0], func(repo *Repository) {
+				out = gitlabIssue(repo, number)
+			})
+			if out == nil {
+				WriteError(w, http.StatusNotFound, "404 Project Not Found")
+				return
+			}
+			WriteJSON(w, http.StatusOK, out)
+		}},
+		{Method: http.MethodPut, Path: regexp.MustCompile(project + `/(issues|merge_requests)/(\d+)$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			number, _ := strconv.Atoi(params[2])
+			query := r.URL.Query()
+			var out map[string]interface{}
+			gitlabUpdate(s, params[0], func(repo *Repository) {
+				if _, ok := query["labels"]; ok {
+					repo.Labels[number] = nil
+					for _, l := range strings.Split(query.Get("labels"), ",") {
+						if l != "" {
+							repo.AddLabel(number, l)
+						}
+					}
+				}
+				out = gitlabIssue(repo, number)
+			})
+			if out == nil {
+				WriteError(w, http.StatusNotFound, "404 Project Not Found")
+				return
+			}
+			WriteJSON(w, http.StatusOK, out)
+		}},
+		{Method: http.MethodGet, Path: regexp.MustCompile(project + `/(?:issues|merge_requests)/(\d+)/notes$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			number, _ := strconv.Atoi(params[1])
+			out := []gitlabNote{}
+			gitlabUpdate(s, params[0], func(repo *Repository) {
+				for _, c := range repo.Comments[number] {
+					out = append(out, gitlabFromComment(c, number))
+				}
+			})
+			WriteJSON(w, http.StatusOK, out)
+		}},
+		{Method: http.MethodPost, Path: regexp.MustCompile(project + `/(?:issues|merge_requests)/(\d+)/notes$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			number, _ := strconv.Atoi(params[1])
+			var out *gitlabNote
+			gitlabUpdate(s, params[0], func(repo *Repository) {
+				n := gitlabFromComment(s.addComment(repo, number, r.URL.Query().Get("body")), number)
+				out = &n
+			})
+			if out == nil {
+				WriteError(w, http.StatusNotFound, "404 Project Not Found")
+				return
+			}
+			WriteJSON(w, http.StatusCreated, out)
+		}},
+		{Method: http.MethodPut, Path: regexp.MustCompile(project + `/(?:issues|merge_requests)/(\d+)/notes/(\d+)$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			number, _ := strconv.Atoi(params[1])
+			id, _ := strconv.Atoi(params[2])
+			in := gitlabNote{}
+			if err := ReadJSON(r, &in); err != nil {
+				WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			var out *gitlabNote
+			gitlabUpdate(s, params[0], func(repo *Repository) {
+				if comment := repo.EditComment(id, in.Body); comment != nil {
+					n := gitlabFromComment(comment, number)
+					out = &n
+				}
+			})
+			if out == nil {
+				WriteError(w, http.StatusNotFound, "404 Not found")
+				return
+			}
+			WriteJSON(w, http.StatusOK, out)
+		}},
+		{Method: http.MethodDelete, Path: regexp.MustCompile(project + `/(?:issues|merge_requests)/(\d+)/notes/(\d+)$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			id, _ := strconv.Atoi(params[2])
+			deleted := false
+			gitlabUpdate(s, params[0], func(repo *Repository) {
+				deleted = repo.DeleteComment(id)
+			})
+			if !deleted {
+				WriteError(w, http.StatusNotFound, "404 Not found")
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}},
+		{Method: http.MethodPost, Path: regexp.MustCompile(project + `/statuses/([^/]+)$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			query := r.URL.Query()
+			status := &scm.Status{
+				State:  gitlabState(query.Get("state")),
+				Label:  query.Get("name"),
+				Desc:   query.Get("description"),
+				Target: query.Get("target_url"),
+			}
+			gitlabUpdate(s, params[0], func(repo *Repository) {
+				repo.Statuses[params[1]] = append(repo.Statuses[params[1]], status)
+			})
+			WriteJSON(w, http.StatusCreated, gitlabFromStatus(status, params[1]))
+		}},
+		{Method: http.MethodGet, Path: regexp.MustCompile(project + `/repository/commits/([^/]+)/statuses$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			out := []gitlabStatus{}
+			gitlabUpdate(s, params[0], func(repo *Repository) {
+				for _, status := range repo.Statuses[params[1]] {
+					out = append(out, gitlabFromStatus(status, params[1]))
+				}
+			})
+			WriteJSON(w, http.StatusOK, out)
+		}},
+		{Method: http.MethodGet, Path: regexp.MustCompile(project + `/repository/files/([^/]+)$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			ref := r.URL.Query().Get("ref")
+			var out map[string]interface{}
+			gitlabUpdate(s, params[0], func(repo *Repository) {
+				if content, ok := repo.Files[ref][params[1]]; ok {
+					out = map[string]interface{}{
+						"file_name": path.Base(params[1]),
+						"file_path": params[1],
+						"size":      len(content),
+						"encoding":  "base64",
+						"content":   base64.StdEncoding.EncodeToString([]byte(content)),
+						"ref":       ref,
+					}
+				}
+			})
+			if out == nil {
+				WriteError(w, http.StatusNotFound, "404 File Not Found")
+				return
+			}
+			WriteJSON(w, http.StatusOK, out)
+		}},
+		{Method: http.MethodGet, Path: regexp.MustCompile(project + `/repository/tree$`), Handler: func(w http.ResponseWriter, r *http.Request, params []string) {
+			query := r.URL.Query()
+			out := []map[string]string{}
+			gitlabUpdate(s, params[0], func(repo *Repository) {
+				paths, dirs := repo.ListDir(query.Get("ref"), query.Get("path"))
+				for _, p := range paths {
+					t := "blob"
+					if dirs[p] {
+						t = "tree"
+					}
+					out = append(out, map[string]string{"id": p, "name": path.Base(p), "type": t, "path": p, "mode": "100644"})
+				}
+			})
+			WriteJSON(w, http.StatusOK, out)
+		}},
+	}
+}
+
+// Webhook returns the request delivering the event as GitLab would, authenticated by the secret token
+func (p GitLab) Webhook(serverURL string, event *Event, secret string) (*http.Request, error) {
+	repo := &Repository{FullName: event.Repo, DefaultBranch: "master"}
+	project := gitlabHookProject{
+		Name:              repo.Name(),
+		PathWithNamespace: repo.FullName,
+		Namespace:         repo.Namespace(),
+		DefaultBranch:     repo.DefaultBranch,
+		WebURL:            serverURL + "/" + repo.FullName,
+		GitHTTPURL:        serverURL + "/" + repo.FullName + ".git",
+	}
+	user := gitlabUser{Username: event.Sender, Name: event.Sender}
+	mergeRequest := map[string]interface{}{
+		"iid":           event.Number,
+		"title":         event.Title,
+		"description":   event.Body,
+		"state":         "opened",
+		"source_branch": event.HeadRef,
+		"target_branch": event.BaseRef,
+		"url":           fmt.Sprintf("%s/%s/-/merge_requests/%d", serverURL, event.Repo, event.Number),
+		"last_commit":   map[string]interface{}{"id": event.HeadSHA},
+		"source":        project,
+		"target":        project,
+	}
+	var kind string
+	var payload interface{}
+	switch event.Kind {
+	case PushEvent:
+		kind = "Push Hook"
+		payload = map[string]interface{}{
+			"object_kind":   "push",
+			"ref":           event.Ref,
+			"before":        event.Before,
+			"after":         event.After,
+			"checkout_sha":  event.After,
+			"user_username": event.Sender,
+			"user_name":     event.Sender,
+			"project":       project,
+		}
+	case PullRequestOpenedEvent:
+		kind = "Merge Request Hook"
+		mergeRequest["action"] = "open"
+		payload = map[string]interface{}{
+			"object_kind":       "merge_request",
+			"user":              user,
+			"project":           project,
+			"object_attributes": mergeRequest,
+		}
+	case PullRequestCommentEvent:
+		kind = "Note Hook"
+		payload = map[string]interface{}{
+			"object_kind": "note",
+			"user":        user,
+			"project":     project,
+			"object_attributes": map[string]interface{}{
+				"id":            event.CommentID,
+				"note":          event.Comment,
+				"noteable_type": "MergeRequest",
+			},
+			"merge_request": mergeRequest,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported event %s", event.Kind)
+	}
+	return signedWebhook(serverURL, payload, map[string]string{"X-Gitlab-Event": kind}, func([]byte) map[string]string {
+		return map[string]string{"X-Gitlab-Token": secret}
+	})
+}
+
+// gitlabUpdate runs the function on the project identified by its ID or full name, which is created if needed
+func gitlabUpdate(s *State, project string, fn func(r *Repository)) {
+	if id, err := strconv.Atoi(project); err == nil {
+		s.UpdateByID(id, fn)
+		return
+	}
+	s.Update(project, fn)
+}
+
+func gitlabFromRepository(r *Repository, serverURL string) gitlabProject {
+	return gitlabProject{
+		ID:                r.ID,
+		Name:              r.Name(),
+		Path:              r.Name(),
+		PathWithNamespace: r.FullName,
+		Namespace:         gitlabNamespace{Name: r.Namespace(), Path: r.Namespace()},
+		DefaultBranch:     r.DefaultBranch,
+		Visibility:        "private",
+		WebURL:            serverURL + "/" + r.FullName,
+		HTTPURL:           serverURL + "/" + r.FullName + ".git",
+	}
+}
+
+// gitlabIssue returns an issue or merge request with its labels, the ones Lighthouse reads from it
+func gitlabIssue(r *Repository, number int) map[string]interface{} {
+	labels := []string{}
+	labels = append(labels, r.Labels[number]...)
+	return map[string]interface{}{
+		"iid":               number,
+		"state":             "opened",
+		"labels":            labels,
+		"source_project_id": r.ID,
+		"target_project_id": r.ID,
+	}
+}
+
+func gitlabFromComment(c *scm.Comment, number int) gitlabNote {
+	return gitlabNote{
+		ID:        c.ID,
+		Number:    number,
+		Author:    gitlabUser{Username: c.Author.Login},
+		Body:      c.Body,
+		CreatedAt: c.Created,
+		UpdatedAt: c.Updated,
+	}
+}
+
+func gitlabFromStatus(status *scm.Status, sha string) gitlabStatus {
+	return gitlabStatus{
+		Name:        status.Label,
+		Status:      gitlabFromState(status.State),
+		Description: status.Desc,
+		TargetURL:   status.Target,
+		Sha:         sha,
+	}
+}
+
+func gitlabState(state string) scm.State {
+	switch state {
+	case "pending":
+		return scm.StatePending
+	case "running":
+		return scm.StateRunning
+	case "success":
+		return scm.StateSuccess
+	case "canceled":
+		return scm.StateCanceled
+	default:
+		return scm.StateFailure
+	}
+}
+
+func gitlabFromState(state scm.State) string {
+	switch state {
+	case scm.StatePending:
+		return "pending"
+	case scm.StateRunning:
+		return "running"
+	case scm.StateSuccess:
+		return "success"
+	case scm.StateCanceled:
+		return "canceled"
+	default:
+		return "failed"
+	}
+}
@@ -0,0 +1,393 @@
+// Package scmtest provides a fake SCM server emulating the REST API and webhooks of the SCM providers, and a
+// conformance suite checking that a provider behaves as Lighthouse expects through the scmprovider client, so that
+// the drivers can be validated uniformly without hitting real APIs.
+package scmtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+)
+
+const (
+	// BotName is the login of the user the clients of the server are authenticated as
+	BotName = "lighthouse-bot"
+	// Token is the token the clients of the server are authenticated with
+	Token = "fake-token"
+)
+
+// Provider emulates an SCM provider on top of the state of the fake server
+type Provider interface {
+	// Driver is the name of the go-scm driver of the provider, e.g. github
+	Driver() string
+	// Routes returns the routes of the REST API of the provider used by Lighthouse
+	Routes(s *State) []Route
+	// Webhook returns the request delivering the event as the provider would, signed with the secret
+	Webhook(serverURL string, event *Event, secret string) (*http.Request, error)
+}
+
+// Route serves the requests whose method and escaped path match
+type Route struct {
+	Method string
+	// Path matches the escaped path of the request, its submatches are passed to the handler unescaped
+	Path    *regexp.Regexp
+	Handler func(w http.ResponseWriter, r *http.Request, params []string)
+}
+
+// EventKind is the kind of a webhook event
+type EventKind string
+
+const (
+	// PushEvent is a push of commits to a branch
+	PushEvent EventKind = "push"
+	// PullRequestOpenedEvent is a pull request being opened
+	PullRequestOpenedEvent EventKind = "pull_request_opened"
+	// PullRequestCommentEvent is a comment on a pull request
+	PullRequestCommentEvent EventKind = "pull_request_comment"
+)
+
+// Event describes a webhook event independently of the providers
+type Event struct {
+	Kind EventKind
+	// Repo is the full name of the repository, e.g. org/repo
+	Repo   string
+	Sender string
+
+	// Ref, Before and After describe a push
+	Ref    string
+	Before string
+	After  string
+
+	// Number, Title, Body, HeadRef, BaseRef and HeadSHA describe a pull request
+	Number  int
+	Title   string
+	Body    string
+	HeadRef string
+	BaseRef string
+	HeadSHA string
+
+	// CommentID and Comment describe a comment
+	CommentID int
+	Comment   string
+}
+
+// Server is a fake SCM server serving the REST API of a provider from an in-memory state
+type Server struct {
+	*State
+	Provider Provider
+
+	server *httptest.Server
+}
+
+// NewServer starts a fake server for the provider, it must be closed after use
+func NewServer(provider Provider) *Server {
+	s := &Server{
+		State:    NewState(),
+		Provider: provider,
+	}
+	routes := provider.Routes(s.State)
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.EscapedPath()
+		for _, route := range routes {
+			if route.Method != r.Method {
+				continue
+			}
+			match := route.Path.FindStringSubmatch(path)
+			if match == nil {
+				continue
+			}
+			params := make([]string, 0, len(match)-1)
+			for _, p := range match[1:] {
+				params = append(params, unescape(p))
+			}
+			route.Handler(w, r, params)
+			return
+		}
+		WriteError(w, http.StatusNotFound, "no route for "+r.Method+" "+path)
+	}))
+	return s
+}
+
+// URL returns the URL of the server
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Close shuts the server down
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns a client of the server authenticated as the bot
+func (s *Server) Client() (*scmprovider.Client, error) {
+	client, err := scmprovider.NewSCMClient(s.Provider.Driver(), s.URL(), Token)
+	if err != nil {
+		return nil, err
+	}
+	return scmprovider.ToClient(client, BotName), nil
+}
+
+// Webhook returns the request delivering the event to Lighthouse, signed with the secret
+func (s *Server) Webhook(event *Event, secret string) (*http.Request, error) {
+	return s.Provider.Webhook(s.URL(), event, secret)
+}
+
+// State is the content of the repositories of a fake server
+type State struct {
+	lock   sync.Mutex
+	repos  map[string]*Repository
+	nextID int
+}
+
+// Repository is the content of a repository of a fake server
+type Repository struct {
+	ID            int
+	FullName      string
+	DefaultBranch string
+	// Statuses are keyed by commit SHA, in creation order
+	Statuses map[string][]*scm.Status
+	// Comments are keyed by issue or pull request number, in creation order
+	Comments map[int][]*scm.Comment
+	// Labels are keyed by issue or pull request number
+	Labels map[int][]string
+	// LabelIDs are the IDs of the labels of the repository keyed by name, for the providers identifying them by ID
+	LabelIDs map[string]int
+	// Files maps refs to the paths of the files and their content
+	Files map[string]map[string]string
+}
+
+// NewState creates an empty state
+func NewState() *State {
+	return &State{repos: map[string]*Repository{}}
+}
+
+// Update runs the function on the repository, creating it if needed, with the state locked
+func (s *State) Update(fullName string, fn func(r *Repository)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	fn(s.repo(fullName))
+}
+
+// UpdateByID runs the function on the repository with the ID with the state locked, and returns false if there is none
+func (s *State) UpdateByID(id int, fn func(r *Repository)) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, r := range s.repos {
+		if r.ID == id {
+			fn(r)
+			return true
+		}
+	}
+	return false
+}
+
+// newID returns a new ID, e.g. for a comment, the state must be locked
+func (s *State) newID() int {
+	s.nextID++
+	return s.nextID
+}
+
+// AddFile adds a file to a repository at a ref
+func (s *State) AddFile(fullName, ref, path, content string) {
+	s.Update(fullName, func(r *Repository) {
+		if r.Files[ref] == nil {
+			r.Files[ref] = map[string]string{}
+		}
+		r.Files[ref][path] = content
+	})
+}
+
+func (s *State) repo(fullName string) *Repository {
+	r := s.repos[fullName]
+	if r == nil {
+		r = &Repository{
+			ID:            s.newID(),
+			FullName:      fullName,
+			DefaultBranch: "master",
+			Statuses:      map[string][]*scm.Status{},
+			Comments:      map[int][]*scm.Comment{},
+			Labels:        map[int][]string{},
+			LabelIDs:      map[string]int{},
+			Files:         map[string]map[string]string{},
+		}
+		s.repos[fullName] = r
+	}
+	return r
+}
+
+// Namespace returns the org of the repository
+func (r *Repository) Namespace() string {
+	return strings.SplitN(r.FullName, "/", 2)[0]
+}
+
+// Name returns the name of the repository without its org
+func (r *Repository) Name() string {
+	parts := strings.SplitN(r.FullName, "/", 2)
+	return parts[len(parts)-1]
+}
+
+// FindComment returns the comment with the ID and the number of its issue or pull request
+func (r *Repository) FindComment(id int) (*scm.Comment, int) {
+	for number, comments := range r.Comments {
+		for _, c := range comments {
+			if c.ID == id {
+				return c, number
+			}
+		}
+	}
+	return nil, 0
+}
+
+// EditComment replaces the body of the comment with the ID, and returns nil if there is none
+func (r *Repository) EditComment(id int, body string) *scm.Comment {
+	c, _ := r.FindComment(id)
+	if c != nil {
+		c.Body = body
+		c.Updated = time.Now()
+	}
+	return c
+}
+
+// DeleteComment deletes the comment with the ID, and returns false if there is none
+func (r *Repository) DeleteComment(id int) bool {
+	_, number := r.FindComment(id)
+	if number == 0 {
+		return false
+	}
+	comments := r.Comments[number]
+	for i, c := range comments {
+		if c.ID == id {
+			r.Comments[number] = append(comments[:i:i], comments[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// AddLabel adds a label to an issue or pull request unless it already has it
+func (r *Repository) AddLabel(number int, label string) {
+	for _, l := range r.Labels[number] {
+		if l == label {
+			return
+		}
+	}
+	r.Labels[number] = append(r.Labels[number], label)
+}
+
+// RemoveLabel removes a label from an issue or pull request, and returns false if it does not have it
+func (r *Repository) RemoveLabel(number int, label string) bool {
+	labels := r.Labels[number]
+	for i, l := range labels {
+		if l == label {
+			r.Labels[number] = append(labels[:i:i], labels[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ListDir returns the paths of the files and directories directly in the directory at the ref, sorted, and whether
+// each is a directory
+func (r *Repository) ListDir(ref, dir string) ([]string, map[string]bool) {
+	dir = strings.Trim(dir, "/")
+	prefix := ""
+	if dir != "" {
+		prefix = dir + "/"
+	}
+	dirs := map[string]bool{}
+	for path := range r.Files[ref] {
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(path, prefix)
+		if i := strings.Index(rest, "/"); i >= 0 {
+			dirs[prefix+rest[:i]] = true
+		} else {
+			dirs[path] = false
+		}
+	}
+	var paths []string
+	for path := range dirs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, dirs
+}
+
+// addComment adds a comment by the bot to an issue or pull request, the state must be locked
+func (s *State) addComment(r *Repository, number int, body string) *scm.Comment {
+	now := time.Now()
+	c := &scm.Comment{
+		ID:      s.newID(),
+		Body:    body,
+		Author:  scm.User{Login: BotName},
+		Created: now,
+		Updated: now,
+	}
+	r.Comments[number] = append(r.Comments[number], c)
+	return c
+}
+
+// labelID returns the ID of the label of the repository, creating it if needed, the state must be locked
+func (s *State) labelID(r *Repository, name string) int {
+	id, ok := r.LabelIDs[name]
+	if !ok {
+		id = s.newID()
+		r.LabelIDs[name] = id
+	}
+	return id
+}
+
+// signedWebhook returns a webhook request with the JSON payload, the headers and the signature headers of the body
+func signedWebhook(serverURL string, payload interface{}, headers map[string]string, sign func(body []byte) map[string]string) (*http.Request, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, serverURL+"/hook", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range sign(body) {
+		req.Header.Set(k, v)
+	}
+	return req, nil
+}
+
+// WriteJSON writes the value as a JSON response
+func WriteJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// WriteError writes an error response with a message
+func WriteError(w http.ResponseWriter, status int, message string) {
+	WriteJSON(w, status, map[string]string{"message": message})
+}
+
+// ReadJSON decodes the JSON body of the request into the value
+func ReadJSON(r *http.Request, value interface{}) error {
+	return json.NewDecoder(r.Body).Decode(value)
+}
+
+func unescape(s string) string {
+	if u, err := url.PathUnescape(s); err == nil {
+		return u
+	}
+	return s
+}
@@ -0,0 +1,112 @@
+// Package templates provides the helper functions available to the Go templates used to render the comments posted
+// by the bot, so that organizations can customize them without rebuilding lighthouse.
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// FuncMap returns a set of sprig-style helper functions for use in comment templates. As in sprig, the value being
+// operated on is the last argument so that the functions can be used in pipelines, e.g. {{ .Repo | upper }}.
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"lower":      strings.ToLower,
+		"upper":      strings.ToUpper,
+		"title":      strings.Title,
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old, new, s string) string { return strings.Replace(s, old, new, -1) },
+		"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"splitList":  func(sep, s string) []string { return strings.Split(s, sep) },
+		"join":       join,
+		"quote":      quote,
+		"indent":     indent,
+		"nindent":    func(spaces int, s string) string { return "\n" + indent(spaces, s) },
+		"plural":     plural,
+		"default":    defaultValue,
+		"empty":      empty,
+		"now":        time.Now,
+		"date":       func(layout string, t time.Time) string { return t.Format(layout) },
+		"toJson":     toJSON,
+	}
+}
+
+func join(sep string, v interface{}) string {
+	switch values := v.(type) {
+	case []string:
+		return strings.Join(values, sep)
+	case nil:
+		return ""
+	}
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return fmt.Sprint(v)
+	}
+	var parts []string
+	for i := 0; i < value.Len(); i++ {
+		parts = append(parts, fmt.Sprint(value.Index(i).Interface()))
+	}
+	return strings.Join(parts, sep)
+}
+
+func quote(values ...interface{}) string {
+	var parts []string
+	for _, v := range values {
+		if v != nil {
+			parts = append(parts, fmt.Sprintf("%q", fmt.Sprint(v)))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	return pad + strings.Replace(s, "\n", "\n"+pad, -1)
+}
+
+func plural(one, many string, count int) string {
+	if count == 1 {
+		return one
+	}
+	return many
+}
+
+// defaultValue returns the default unless the value is set, e.g. {{ .Description | default "no description" }}
+func defaultValue(d interface{}, v ...interface{}) interface{} {
+	if len(v) == 0 || empty(v[0]) {
+		return d
+	}
+	return v[0]
+}
+
+// empty returns true if the value is nil or the zero value of its type, or an empty collection
+func empty(v interface{}) bool {
+	value := reflect.ValueOf(v)
+	if !value.IsValid() {
+		return true
+	}
+	switch value.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return value.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	default:
+		return value.IsZero()
+	}
+}
+
+func toJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,48 @@
+package templates
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuncMap(t *testing.T) {
+	data := map[string]interface{}{
+		"Repo":   "my-repo",
+		"Labels": []string{"bug", "help wanted"},
+		"Count":  2,
+		"Empty":  "",
+		"Body":   "line1\nline2",
+	}
+	testCases := []struct {
+		template string
+		expected string
+	}{
+		{template: `{{ .Repo | upper }}`, expected: "MY-REPO"},
+		{template: `{{ .Repo | replace "-" "_" }}`, expected: "my_repo"},
+		{template: `{{ .Repo | trimPrefix "my-" }}`, expected: "repo"},
+		{template: `{{ if .Repo | hasPrefix "my" }}yes{{ end }}`, expected: "yes"},
+		{template: `{{ .Labels | join ", " }}`, expected: "bug, help wanted"},
+		{template: `{{ .Empty | default "none" }}`, expected: "none"},
+		{template: `{{ .Repo | default "none" }}`, expected: "my-repo"},
+		{template: `{{ .Missing | default "none" }}`, expected: "none"},
+		{template: `{{ plural "test" "tests" .Count }}`, expected: "tests"},
+		{template: `{{ .Repo | quote }}`, expected: `"my-repo"`},
+		{template: `{{ .Body | indent 2 }}`, expected: "  line1\n  line2"},
+		{template: `{{ .Labels | toJson }}`, expected: `["bug","help wanted"]`},
+		{template: `{{ if empty .Empty }}empty{{ end }}`, expected: "empty"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.template, func(t *testing.T) {
+			tmpl, err := template.New("test").Funcs(FuncMap()).Parse(tc.template)
+			require.NoError(t, err)
+			var buf bytes.Buffer
+			require.NoError(t, tmpl.Execute(&buf, data))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
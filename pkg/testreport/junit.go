@@ -0,0 +1,152 @@
+// Package testreport parses structured test output produced by pipelines so it can be reported back to the SCM provider.
+package testreport
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// JUnitSuites is the root element of a JUnit XML report
+type JUnitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []JUnitSuite `xml:"testsuite"`
+}
+
+// JUnitSuite is a single test suite in a JUnit XML report
+type JUnitSuite struct {
+	Name   string       `xml:"name,attr"`
+	File   string       `xml:"file,attr"`
+	Cases  []JUnitCase  `xml:"testcase"`
+	Suites []JUnitSuite `xml:"testsuite"`
+}
+
+// JUnitCase is a single test case in a JUnit XML report
+type JUnitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr"`
+	Line      int           `xml:"line,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *JUnitFailure `xml:"failure"`
+	Error     *JUnitFailure `xml:"error"`
+	Skipped   *JUnitSkipped `xml:"skipped"`
+}
+
+// JUnitFailure describes why a test case failed or errored
+type JUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Value   string `xml:",chardata"`
+}
+
+// JUnitSkipped marks a test case as skipped
+type JUnitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// Summary is an aggregated view of a test report
+type Summary struct {
+	Total    int
+	Passed   int
+	Failed   int
+	Skipped  int
+	Failures []Failure
+}
+
+// Failure describes a single failed test along with its source location, if known
+type Failure struct {
+	Suite   string
+	Name    string
+	File    string
+	Line    int
+	Message string
+	Details string
+}
+
+// Add adds the results of another report to the summary
+func (s *Summary) Add(other *Summary) {
+	if other == nil {
+		return
+	}
+	s.Total += other.Total
+	s.Passed += other.Passed
+	s.Failed += other.Failed
+	s.Skipped += other.Skipped
+	s.Failures = append(s.Failures, other.Failures...)
+}
+
+// ParseJUnit parses a JUnit XML report, accepting either a <testsuites> or a single <testsuite> root element
+func ParseJUnit(data []byte) (*JUnitSuites, error) {
+	data = bytes.TrimSpace(data)
+	suites := &JUnitSuites{}
+	if err := xml.Unmarshal(data, suites); err == nil {
+		return suites, nil
+	}
+	suite := JUnitSuite{}
+	if err := xml.Unmarshal(data, &suite); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse JUnit XML")
+	}
+	suites.Suites = []JUnitSuite{suite}
+	return suites, nil
+}
+
+// Summarize aggregates the results of all the test cases in the report
+func (r *JUnitSuites) Summarize() *Summary {
+	s := &Summary{}
+	for i := range r.Suites {
+		s.addSuite(&r.Suites[i], "")
+	}
+	return s
+}
+
+func (s *Summary) addSuite(suite *JUnitSuite, file string) {
+	if suite.File != "" {
+		file = suite.File
+	}
+	for i := range suite.Suites {
+		s.addSuite(&suite.Suites[i], file)
+	}
+	for _, c := range suite.Cases {
+		s.Total++
+		failure := c.Failure
+		if failure == nil {
+			failure = c.Error
+		}
+		switch {
+		case failure != nil:
+			s.Failed++
+			f := Failure{
+				Suite:   suite.Name,
+				Name:    c.Name,
+				File:    c.File,
+				Line:    c.Line,
+				Message: failure.Message,
+				Details: strings.TrimSpace(failure.Value),
+			}
+			if f.Suite == "" {
+				f.Suite = c.ClassName
+			}
+			if f.File == "" {
+				f.File = file
+			}
+			if f.Message == "" {
+				f.Message = firstLine(f.Details)
+			}
+			s.Failures = append(s.Failures, f)
+		case c.Skipped != nil:
+			s.Skipped++
+		default:
+			s.Passed++
+		}
+	}
+}
+
+func firstLine(text string) string {
+	if i := strings.Index(text, "\n"); i >= 0 {
+		return text[:i]
+	}
+	return text
+}
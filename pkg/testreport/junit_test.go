@@ -0,0 +1,80 @@
+package testreport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJUnit(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		expected *Summary
+	}{
+		{
+			name: "testsuites root",
+			data: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="pkg/foo" file="pkg/foo/foo_test.go">
+    <testcase name="TestPasses" classname="pkg/foo" time="0.01"></testcase>
+    <testcase name="TestFails" classname="pkg/foo" line="42">
+      <failure message="expected 1 got 2">foo_test.go:42: expected 1 got 2</failure>
+    </testcase>
+    <testcase name="TestSkipped" classname="pkg/foo"><skipped/></testcase>
+  </testsuite>
+  <testsuite name="pkg/bar">
+    <testcase name="TestErrors" classname="pkg/bar" file="pkg/bar/bar_test.go">
+      <error>panic: boom
+goroutine 1</error>
+    </testcase>
+  </testsuite>
+</testsuites>`,
+			expected: &Summary{
+				Total:   4,
+				Passed:  1,
+				Failed:  2,
+				Skipped: 1,
+				Failures: []Failure{
+					{
+						Suite:   "pkg/foo",
+						Name:    "TestFails",
+						File:    "pkg/foo/foo_test.go",
+						Line:    42,
+						Message: "expected 1 got 2",
+						Details: "foo_test.go:42: expected 1 got 2",
+					},
+					{
+						Suite:   "pkg/bar",
+						Name:    "TestErrors",
+						File:    "pkg/bar/bar_test.go",
+						Message: "panic: boom",
+						Details: "panic: boom\ngoroutine 1",
+					},
+				},
+			},
+		},
+		{
+			name: "single testsuite root",
+			data: `<testsuite name="unit"><testcase name="a"/><testcase name="b"/></testsuite>`,
+			expected: &Summary{
+				Total:  2,
+				Passed: 2,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report, err := ParseJUnit([]byte(tc.data))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, report.Summarize())
+		})
+	}
+}
+
+func TestParseJUnitInvalid(t *testing.T) {
+	_, err := ParseJUnit([]byte("not xml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,89 @@
+package testreport
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SARIFLog is the root object of a SARIF report, as produced by static analysis tools
+type SARIFLog struct {
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun is a single run of an analysis tool in a SARIF report
+type SARIFRun struct {
+	Tool    SARIFTool     `json:"tool"`
+	Results []SARIFResult `json:"results"`
+}
+
+// SARIFTool describes the analysis tool of a run
+type SARIFTool struct {
+	Driver struct {
+		Name string `json:"name"`
+	} `json:"driver"`
+}
+
+// SARIFResult is a single finding of an analysis tool
+type SARIFResult struct {
+	RuleID  string `json:"ruleId"`
+	Level   string `json:"level"`
+	Message struct {
+		Text string `json:"text"`
+	} `json:"message"`
+	Locations []SARIFLocation `json:"locations"`
+}
+
+// SARIFLocation is the location of a finding
+type SARIFLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region struct {
+			StartLine int `json:"startLine"`
+		} `json:"region"`
+	} `json:"physicalLocation"`
+}
+
+// ParseSARIF parses a SARIF report
+func ParseSARIF(data []byte) (*SARIFLog, error) {
+	log := &SARIFLog{}
+	if err := json.Unmarshal(data, log); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse SARIF")
+	}
+	return log, nil
+}
+
+// Summarize reports the errors and warnings of the report as failures, notes being ignored. The findings are not
+// tests, so only the number of failures is counted.
+func (l *SARIFLog) Summarize() *Summary {
+	s := &Summary{}
+	for _, run := range l.Runs {
+		for _, r := range run.Results {
+			// the level defaults to warning when it is not set
+			if r.Level == "note" || r.Level == "none" {
+				continue
+			}
+			s.Failed++
+			f := Failure{
+				Suite:   run.Tool.Driver.Name,
+				Name:    r.RuleID,
+				Message: firstLine(r.Message.Text),
+				Details: strings.TrimSpace(r.Message.Text),
+			}
+			if len(r.Locations) > 0 {
+				location := r.Locations[0].PhysicalLocation
+				f.File = strings.TrimPrefix(strings.TrimPrefix(location.ArtifactLocation.URI, "file://"), "./")
+				f.Line = location.Region.StartLine
+			}
+			if f.Details == f.Message {
+				f.Details = ""
+			}
+			s.Failures = append(s.Failures, f)
+		}
+	}
+	return s
+}
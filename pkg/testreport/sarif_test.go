@@ -0,0 +1,69 @@
+package testreport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSARIF(t *testing.T) {
+	data := `{
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {"driver": {"name": "gosec"}},
+      "results": [
+        {
+          "ruleId": "G101",
+          "level": "error",
+          "message": {"text": "Potential hardcoded credentials"},
+          "locations": [{"physicalLocation": {"artifactLocation": {"uri": "file://pkg/foo/foo.go"}, "region": {"startLine": 12}}}]
+        },
+        {
+          "ruleId": "G104",
+          "message": {"text": "Errors unhandled.\nCheck the returned error."},
+          "locations": [{"physicalLocation": {"artifactLocation": {"uri": "./pkg/bar/bar.go"}, "region": {"startLine": 3}}}]
+        },
+        {
+          "ruleId": "G304",
+          "level": "note",
+          "message": {"text": "File path provided as taint input"}
+        }
+      ]
+    }
+  ]
+}`
+	report, err := ParseSARIF([]byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, &Summary{
+		Failed: 2,
+		Failures: []Failure{
+			{
+				Suite:   "gosec",
+				Name:    "G101",
+				File:    "pkg/foo/foo.go",
+				Line:    12,
+				Message: "Potential hardcoded credentials",
+			},
+			{
+				Suite:   "gosec",
+				Name:    "G104",
+				File:    "pkg/bar/bar.go",
+				Line:    3,
+				Message: "Errors unhandled.",
+				Details: "Errors unhandled.\nCheck the returned error.",
+			},
+		},
+	}, report.Summarize())
+
+	_, err = ParseSARIF([]byte("<not json>"))
+	assert.Error(t, err)
+}
+
+func TestSummaryAdd(t *testing.T) {
+	s := &Summary{Total: 2, Passed: 1, Failed: 1, Failures: []Failure{{Name: "TestFoo"}}}
+	s.Add(&Summary{Failed: 1, Failures: []Failure{{Name: "G101"}}})
+	s.Add(nil)
+	assert.Equal(t, &Summary{Total: 2, Passed: 1, Failed: 2, Failures: []Failure{{Name: "TestFoo"}, {Name: "G101"}}}, s)
+}
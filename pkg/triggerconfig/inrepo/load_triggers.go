@@ -70,6 +70,8 @@ func mergeConfigs(m map[string]*triggerconfig.Config) (*triggerconfig.Config, er
 	// lets check for duplicates
 	presubmitNames := map[string]string{}
 	postsubmitNames := map[string]string{}
+	releaseNames := map[string]string{}
+	deploymentNames := map[string]string{}
 	for file, cfg := range m {
 		for _, ps := range cfg.Spec.Presubmits {
 			name := ps.Name
@@ -89,6 +91,24 @@ func mergeConfigs(m map[string]*triggerconfig.Config) (*triggerconfig.Config, er
 				return nil, errors.Errorf("duplicate postsubmit %s in file %s and %s", name, otherFile, file)
 			}
 		}
+		for _, r := range cfg.Spec.Releases {
+			name := r.Name
+			otherFile := releaseNames[name]
+			if otherFile == "" {
+				releaseNames[name] = file
+			} else {
+				return nil, errors.Errorf("duplicate release %s in file %s and %s", name, otherFile, file)
+			}
+		}
+		for _, d := range cfg.Spec.Deployments {
+			name := d.Name
+			otherFile := deploymentNames[name]
+			if otherFile == "" {
+				deploymentNames[name] = file
+			} else {
+				return nil, errors.Errorf("duplicate deployment %s in file %s and %s", name, otherFile, file)
+			}
+		}
 		answer = merge.CombineConfigs(answer, cfg)
 	}
 	return answer, nil
@@ -136,6 +156,45 @@ func loadConfigFile(client scmProviderClient, ownerName, repoName, path, sha str
 		if r.Agent == "" && r.PipelineRunSpec != nil {
 			r.Agent = job.TektonPipelineAgent
 		}
+		if err := r.SetRegexes(); err != nil {
+			return nil, errors.Wrapf(err, "invalid Postsubmit %d in file %s in repo %s/%s", i, path, ownerName, repoName)
+		}
+	}
+	for i := range repoConfig.Spec.Releases {
+		r := &repoConfig.Spec.Releases[i]
+		if r.SourcePath != "" {
+			err = loadJobBaseFromSourcePath(client, &r.Base, ownerName, repoName, filepath.Join(dir, r.SourcePath), sha)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to load Source for Release %d", i)
+			}
+		}
+		if r.Agent == "" && r.PipelineRunSpec != nil {
+			r.Agent = job.TektonPipelineAgent
+		}
+		if r.Context == "" {
+			r.Context = r.Name
+		}
+		if err := r.SetRegexes(); err != nil {
+			return nil, errors.Wrapf(err, "invalid Release %d in file %s in repo %s/%s", i, path, ownerName, repoName)
+		}
+	}
+	for i := range repoConfig.Spec.Deployments {
+		r := &repoConfig.Spec.Deployments[i]
+		if r.SourcePath != "" {
+			err = loadJobBaseFromSourcePath(client, &r.Base, ownerName, repoName, filepath.Join(dir, r.SourcePath), sha)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to load Source for Deployment %d", i)
+			}
+		}
+		if r.Agent == "" && r.PipelineRunSpec != nil {
+			r.Agent = job.TektonPipelineAgent
+		}
+		if r.Context == "" {
+			r.Context = r.Name
+		}
+		if err := r.SetRegexes(); err != nil {
+			return nil, errors.Wrapf(err, "invalid Deployment %d in file %s in repo %s/%s", i, path, ownerName, repoName)
+		}
 	}
 	return repoConfig, nil
 }
@@ -48,7 +48,7 @@ func TestInvalidConfigs(t *testing.T) {
 	scmClient, _ := fake.NewDefault()
 	scmProvider := scmprovider.ToClient(scmClient, "my-bot")
 
-	invalidRepos := []string{"duplicate-presubmit", "duplicate-postsubmit"}
+	invalidRepos := []string{"duplicate-presubmit", "duplicate-postsubmit", "invalid-branch-regex"}
 	for _, repo := range invalidRepos {
 		owner := "myorg"
 		ref := "master"
@@ -58,3 +58,41 @@ func TestInvalidConfigs(t *testing.T) {
 		t.Logf("got expected error loading invalid configuration on repo %s of: %s", repo, err.Error())
 	}
 }
+
+func TestPostsubmitBranches(t *testing.T) {
+	scmClient, _ := fake.NewDefault()
+	scmProvider := scmprovider.ToClient(scmClient, "my-bot")
+
+	cfg, err := inrepo.LoadTriggerConfig(scmProvider, "myorg", "release-branches", "master")
+	require.NoError(t, err, "failed to load triggers")
+	require.Len(t, cfg.Spec.Postsubmits, 2)
+
+	testCases := []struct {
+		branch   string
+		expected []string
+	}{
+		{
+			branch:   "main",
+			expected: []string{"release"},
+		},
+		{
+			branch:   "release-1.2",
+			expected: []string{"patch-release"},
+		},
+		{
+			branch: "release-0.9",
+		},
+		{
+			branch: "feature",
+		},
+	}
+	for _, tc := range testCases {
+		var names []string
+		for _, ps := range cfg.Spec.Postsubmits {
+			if ps.CouldRun(tc.branch) {
+				names = append(names, ps.Name)
+			}
+		}
+		assert.Equal(t, tc.expected, names, "postsubmits for branch %s", tc.branch)
+	}
+}
@@ -1,6 +1,7 @@
 package merge
 
 import (
+	"github.com/jenkins-x/lighthouse/pkg/config/job"
 	"github.com/jenkins-x/lighthouse/pkg/triggerconfig"
 )
 
@@ -18,5 +19,17 @@ func CombineConfigs(a, b *triggerconfig.Config) *triggerconfig.Config {
 	for _, r := range b.Spec.Postsubmits {
 		a.Spec.Postsubmits = append(a.Spec.Postsubmits, r)
 	}
+	for _, r := range b.Spec.Releases {
+		a.Spec.Releases = append(a.Spec.Releases, r)
+	}
+	for _, r := range b.Spec.Deployments {
+		a.Spec.Deployments = append(a.Spec.Deployments, r)
+	}
+	if b.Spec.Monorepo != nil {
+		if a.Spec.Monorepo == nil {
+			a.Spec.Monorepo = &job.Monorepo{}
+		}
+		a.Spec.Monorepo.Merge(b.Spec.Monorepo)
+	}
 	return a
 }
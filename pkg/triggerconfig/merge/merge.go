@@ -64,6 +64,76 @@ func ConfigMerge(cfg *config.Config, pluginsCfg *plugins.Configuration, repoConf
 		}
 		cfg.Postsubmits[repoKey] = ps
 	}
+	if len(repoConfig.Spec.Releases) > 0 {
+		// lets make a new map to avoid concurrent modifications
+		m := map[string][]job.Release{}
+		if cfg.Releases != nil {
+			for k, v := range cfg.Releases {
+				m[k] = append([]job.Release{}, v...)
+			}
+		}
+		cfg.Releases = m
+
+		rs := cfg.Releases[repoKey]
+		for _, r := range repoConfig.Spec.Releases {
+			found := false
+			for i := range rs {
+				if rs[i].Name == r.Name {
+					rs[i] = r
+					found = true
+				}
+			}
+			if !found {
+				rs = append(rs, r)
+			}
+		}
+		cfg.Releases[repoKey] = rs
+	}
+	if len(repoConfig.Spec.Deployments) > 0 {
+		// lets make a new map to avoid concurrent modifications
+		m := map[string][]job.Deployment{}
+		if cfg.Deployments != nil {
+			for k, v := range cfg.Deployments {
+				m[k] = append([]job.Deployment{}, v...)
+			}
+		}
+		cfg.Deployments = m
+
+		ds := cfg.Deployments[repoKey]
+		for _, d := range repoConfig.Spec.Deployments {
+			found := false
+			for i := range ds {
+				if ds[i].Name == d.Name {
+					ds[i] = d
+					found = true
+				}
+			}
+			if !found {
+				ds = append(ds, d)
+			}
+		}
+		cfg.Deployments[repoKey] = ds
+	}
+
+	if repoConfig.Spec.Monorepo != nil {
+		// lets make a new map to avoid concurrent modifications
+		m := map[string]job.Monorepo{}
+		for k, v := range cfg.Monorepos {
+			m[k] = v
+		}
+		monorepo := job.Monorepo{}
+		if existing, ok := m[repoKey]; ok {
+			monorepo.Context = existing.Context
+			monorepo.Groups = append([]job.MonorepoGroup{}, existing.Groups...)
+		}
+		monorepo.Merge(repoConfig.Spec.Monorepo)
+		monorepo.SetDefaults()
+		if err := monorepo.SetRegexes(); err != nil {
+			return errors.Wrapf(err, "invalid monorepo config")
+		}
+		m[repoKey] = monorepo
+		cfg.Monorepos = m
+	}
 
 	// lets make sure we've got a trigger added
 	idx := len(pluginsCfg.Triggers) - 1
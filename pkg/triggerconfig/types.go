@@ -16,13 +16,22 @@ type Config struct {
 	Spec ConfigSpec `json:"spec"`
 }
 
-// ConfigSpec specifies the optional presubmit/postsubmit/trigger configurations
+// ConfigSpec specifies the optional presubmit/postsubmit/release/deployment/trigger configurations
 type ConfigSpec struct {
 	// Presubmit zero or more presubmits
 	Presubmits []job.Presubmit `json:"presubmits,omitempty"`
 
 	// Postsubmit zero or more postsubmits
 	Postsubmits []job.Postsubmit `json:"postsubmits,omitempty"`
+
+	// Releases zero or more jobs triggered by pushing a git tag
+	Releases []job.Release `json:"releases,omitempty"`
+
+	// Deployments zero or more jobs triggered by deployment events
+	Deployments []job.Deployment `json:"deployments,omitempty"`
+
+	// Monorepo the groups of presubmits scoped to the paths of the repository
+	Monorepo *job.Monorepo `json:"monorepo,omitempty"`
 }
 
 // ConfigList contains a list of Config
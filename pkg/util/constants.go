@@ -56,6 +56,9 @@ const (
 	// BaseSHALabel is added in resources created by Lighthouse and contains the base SHA (for PRs) to be merged against..
 	BaseSHALabel = "lighthouse.jenkins-x.io/baseSHA"
 
+	// MergeTrainLabel is added to the batch jobs keeper triggers for the cars of its merge trains.
+	MergeTrainLabel = "lighthouse.jenkins-x.io/mergeTrain"
+
 	// CloneURIAnnotation is added in resources created by Lighthouse and contains the clone URI for the git repo.
 	CloneURIAnnotation = "lighthouse.jenkins-x.io/cloneURI"
 
@@ -70,6 +73,10 @@ const (
 	ProwConfigFilename = "config.yaml"
 	// ProwPluginsFilename plugins file name
 	ProwPluginsFilename = "plugins.yaml"
+	// PausesConfigMapName name of the ConfigMap holding the components paused through the admin API
+	PausesConfigMapName = "lighthouse-pauses"
+	// PausesFilename pauses file name
+	PausesFilename = "pauses.yaml"
 
 	// LighthouseCommandPrefix is an optional prefix for commands to deal with things like GitLab hijacking /approve
 	LighthouseCommandPrefix = "lh-"
@@ -9,10 +9,10 @@ import (
 	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
-	"github.com/jenkins-x/go-scm/scm/factory"
 	"github.com/jenkins-x/go-scm/scm/transport"
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider/azure"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/oauth2"
@@ -33,13 +33,25 @@ func AddAuthToSCMClient(client *scm.Client, token string, isGitHubApp bool) {
 		client.Client.Transport = tr
 		return
 	}
-	if client.Driver.String() == "gitlab" || client.Driver.String() == "bitbucketcloud" {
+	switch scmprovider.DriverName(client.Driver) {
+	case azure.Name:
+		client.Client = &http.Client{
+			Transport: azure.Transport(token),
+		}
+	case "gitea":
+		client.Client = &http.Client{
+			Transport: &transport.Authorization{
+				Scheme:      "token",
+				Credentials: token,
+			},
+		}
+	case "gitlab", "bitbucketcloud":
 		client.Client = &http.Client{
 			Transport: &transport.PrivateToken{
 				Token: token,
 			},
 		}
-	} else {
+	default:
 		ts := oauth2.StaticTokenSource(
 			&oauth2.Token{AccessToken: token},
 		)
@@ -92,7 +104,7 @@ func GetSCMClient(owner string, cfg config.Getter) (scmprovider.SCMClient, *scm.
 		}
 	}
 
-	client, err := factory.NewClient(kind, serverURL, token)
+	client, err := scmprovider.NewSCMClient(kind, serverURL, token)
 	scmClient := scmprovider.ToClient(client, GetBotName(cfg))
 	return scmClient, client, serverURL, token, err
 }
@@ -145,6 +157,11 @@ func HMACToken() string {
 	return os.Getenv("HMAC_TOKEN")
 }
 
+// AdminToken gets the token authenticating the requests to the admin API from the environment
+func AdminToken() string {
+	return os.Getenv("ADMIN_TOKEN")
+}
+
 // BlobURLForProvider gets the link to the blob for an individual file in a commit or branch
 func BlobURLForProvider(providerType string, baseURL *url.URL, owner, repo, branch string, fullPath string) string {
 	switch providerType {
@@ -156,6 +173,10 @@ func BlobURLForProvider(providerType string, baseURL *url.URL, owner, repo, bran
 		return u
 	case "gitlab":
 		return fmt.Sprintf("%s/%s/%s/-/blob/%s/%v", strings.TrimSuffix(baseURL.String(), "/"), owner, repo, branch, fullPath)
+	case "gitea":
+		return fmt.Sprintf("%s/%s/%s/src/%s/%v", strings.TrimSuffix(baseURL.String(), "/"), owner, repo, branch, fullPath)
+	case azure.Name:
+		return fmt.Sprintf("%s/%s/_git/%s?path=/%v&version=GB%s", strings.TrimSuffix(baseURL.String(), "/"), owner, repo, fullPath, url.QueryEscape(branch))
 	default:
 		return fmt.Sprintf("%s/%s/%s/blob/%s/%v", strings.TrimSuffix(baseURL.String(), "/"), owner, repo, branch, fullPath)
 	}
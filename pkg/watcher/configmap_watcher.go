@@ -2,9 +2,13 @@ package watcher
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/jenkins-x/lighthouse/pkg/clients"
 	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
 	"github.com/jenkins-x/lighthouse/pkg/util"
 	"github.com/pkg/errors"
@@ -16,6 +20,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	watchtools "k8s.io/client-go/tools/watch"
+	"sigs.k8s.io/yaml"
 )
 
 // ConfigMapWatcher callbacks for changes to a config map
@@ -26,6 +31,23 @@ type ConfigMapWatcher struct {
 	watch      watch.Interface
 	stopped    bool
 	stopCh     <-chan struct{}
+	loadErrors *loadErrors
+}
+
+// loadErrors records the errors loading the configurations of the ConfigMaps, keyed by file name
+type loadErrors struct {
+	lock sync.RWMutex
+	errs map[string]error
+}
+
+func (l *loadErrors) set(file string, err error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if err == nil {
+		delete(l.errs, file)
+		return
+	}
+	l.errs[file] = err
 }
 
 // ConfigMapCallback represents a callback
@@ -44,11 +66,13 @@ type ConfigMapEntryCallback struct {
 // SetupConfigMapWatchers takes a config agent and plugin agent, each potentially nil, and sets up the appropriate watchers for them.
 func SetupConfigMapWatchers(ns string, configAgent *config.Agent, pluginAgent *plugins.ConfigAgent) (*ConfigMapWatcher, error) {
 	var callbacks []ConfigMapCallback
+	loadErrs := &loadErrors{errs: map[string]error{}}
 
 	if configAgent != nil {
 		onConfigYamlChange := func(text string) {
 			if text != "" {
 				loadedConfig, err := config.LoadYAMLConfig([]byte(text))
+				loadErrs.set(util.ProwConfigFilename, err)
 				if err != nil {
 					logrus.WithError(err).Error("Error processing the Lighthouse Config YAML")
 				} else {
@@ -62,12 +86,28 @@ func SetupConfigMapWatchers(ns string, configAgent *config.Agent, pluginAgent *p
 			Key:      util.ProwConfigFilename,
 			Callback: onConfigYamlChange,
 		})
+
+		onPausesYamlChange := func(text string) {
+			pauses := lighthouse.Pauses{}
+			if err := yaml.Unmarshal([]byte(text), &pauses); err != nil {
+				logrus.WithError(err).Error("Error processing the Lighthouse Pauses YAML")
+				return
+			}
+			logrus.Info("updating the paused Lighthouse components")
+			configAgent.SetPauses(pauses)
+		}
+		callbacks = append(callbacks, &ConfigMapEntryCallback{
+			Name:     util.PausesConfigMapName,
+			Key:      util.PausesFilename,
+			Callback: onPausesYamlChange,
+		})
 	}
 
 	if pluginAgent != nil {
 		onPluginsYamlChange := func(text string) {
 			if text != "" {
 				loadedConfig, err := pluginAgent.LoadYAMLConfig([]byte(text))
+				loadErrs.set(util.ProwPluginsFilename, err)
 				if err != nil {
 					logrus.WithError(err).Error("Error processing the Lighthouse Plugins YAML")
 				} else {
@@ -92,7 +132,11 @@ func SetupConfigMapWatchers(ns string, configAgent *config.Agent, pluginAgent *p
 		return nil, errors.Wrapf(err, "failed to create Kube client")
 	}
 
-	return NewConfigMapWatcher(kubeClient, ns, callbacks, util.Stopper())
+	w, err := NewConfigMapWatcher(kubeClient, ns, callbacks, util.Stopper())
+	if w != nil {
+		w.loadErrors = loadErrs
+	}
+	return w, err
 }
 
 // OnChange invokes the callback function if the value is not empty and changes
@@ -144,6 +188,25 @@ func NewConfigMapWatcher(kubeClient kubernetes.Interface, ns string, callbacks [
 	return w, nil
 }
 
+// LoadError returns why the latest configurations of the ConfigMaps failed to load, or nil if they all loaded. The
+// previously loaded configurations are kept when the latest ones fail to load.
+func (w *ConfigMapWatcher) LoadError() error {
+	if w.loadErrors == nil {
+		return nil
+	}
+	w.loadErrors.lock.RLock()
+	defer w.loadErrors.lock.RUnlock()
+	var msgs []string
+	for file, err := range w.loadErrors.errs {
+		msgs = append(msgs, fmt.Sprintf("failed to load %s: %v", file, err))
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	sort.Strings(msgs)
+	return errors.New(strings.Join(msgs, "; "))
+}
+
 // IsStopped checks if configmap watcher is stopped
 func (w *ConfigMapWatcher) IsStopped() bool {
 	return w.stopped
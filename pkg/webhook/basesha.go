@@ -0,0 +1,29 @@
+package webhook
+
+import (
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// trackBaseSHA keeps the cached heads of the branches up to date with the hook: a push records the new head of its
+// branch and a merge invalidates the head of the base branch, in case its push is delivered later.
+func (o *WebhooksController) trackBaseSHA(webhook scm.Webhook) {
+	switch hook := webhook.(type) {
+	case *scm.PushHook:
+		if !strings.HasPrefix(hook.Ref, "refs/heads/") {
+			return
+		}
+		repo := hook.Repository()
+		if hook.Deleted || hook.After == "" || strings.Trim(hook.After, "0") == "" {
+			o.baseSHAs.Invalidate(repo.Namespace, repo.Name, hook.Ref)
+			return
+		}
+		o.baseSHAs.Update(repo.Namespace, repo.Name, hook.Ref, hook.After)
+	case *scm.PullRequestHook:
+		if hook.PullRequest.Merged {
+			repo := hook.Repository()
+			o.baseSHAs.Invalidate(repo.Namespace, repo.Name, hook.PullRequest.Base.Ref)
+		}
+	}
+}
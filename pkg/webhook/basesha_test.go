@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/basesha"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type refGetter map[string]string
+
+func (r refGetter) GetRef(org, repo, ref string) (string, error) {
+	return r[org+"/"+repo+":"+ref], nil
+}
+
+func TestTrackBaseSHA(t *testing.T) {
+	repo := scm.Repository{Namespace: "org", Name: "repo", FullName: "org/repo"}
+	refs := refGetter{"org/repo:heads/master": "resolved"}
+	o := &WebhooksController{baseSHAs: basesha.NewResolver(time.Hour)}
+	resolve := func() string {
+		sha, err := o.baseSHAs.Resolve(refs, "org", "repo", "master")
+		require.NoError(t, err)
+		return sha
+	}
+
+	o.trackBaseSHA(&scm.PushHook{Ref: "refs/heads/master", Repo: repo, After: "pushed"})
+	assert.Equal(t, "pushed", resolve())
+
+	o.trackBaseSHA(&scm.PushHook{Ref: "refs/tags/v1.0.0", Repo: repo, After: "tagged"})
+	assert.Equal(t, "pushed", resolve())
+
+	o.trackBaseSHA(&scm.PullRequestHook{Action: scm.ActionClose, Repo: repo, PullRequest: scm.PullRequest{Merged: true, Base: scm.PullRequestBranch{Ref: "master"}}})
+	assert.Equal(t, "resolved", resolve())
+
+	o.trackBaseSHA(&scm.PushHook{Ref: "refs/heads/master", Repo: repo, After: "pushed"})
+	o.trackBaseSHA(&scm.PushHook{Ref: "refs/heads/master", Repo: repo, After: "0000000000000000000000000000000000000000", Deleted: true})
+	assert.Equal(t, "resolved", resolve())
+}
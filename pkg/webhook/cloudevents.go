@@ -0,0 +1,378 @@
+package webhook
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// CloudEventsPath is the URL path CloudEvents are received on
+	CloudEventsPath = "/cloudevents"
+
+	// cloudEventsContentType is the content type of the CloudEvents sent in structured content mode
+	cloudEventsContentType = "application/cloudevents+json"
+	// cloudEventsBatchContentType is the content type of the batches of CloudEvents, which are not supported
+	cloudEventsBatchContentType = "application/cloudevents-batch+json"
+
+	// CDEvents types of the source change events, without their version suffix
+	changeCreatedEventType   = "dev.cdevents.change.created"
+	changeUpdatedEventType   = "dev.cdevents.change.updated"
+	changeMergedEventType    = "dev.cdevents.change.merged"
+	changeAbandonedEventType = "dev.cdevents.change.abandoned"
+	branchCreatedEventType   = "dev.cdevents.branch.created"
+)
+
+// CloudEvent is a CloudEvent received in binary or structured content mode
+type CloudEvent struct {
+	SpecVersion string          `json:"specversion"`
+	ID          string          `json:"id"`
+	Source      string          `json:"source"`
+	Type        string          `json:"type"`
+	Subject     string          `json:"subject,omitempty"`
+	Data        json.RawMessage `json:"data,omitempty"`
+}
+
+// CDEvent is the data of a CDEvent, only the subject of which is used
+type CDEvent struct {
+	Subject CDEventSubject `json:"subject"`
+}
+
+// CDEventSubject is the subject of a CDEvent
+type CDEventSubject struct {
+	ID      string       `json:"id"`
+	Source  string       `json:"source,omitempty"`
+	Type    string       `json:"type,omitempty"`
+	Content SourceChange `json:"content"`
+}
+
+// SourceChange is the content of the subject of the source change events. It extends the content of the change and
+// branch subjects of CDEvents with the details needed to trigger pipelines as for the webhooks of an SCM provider.
+type SourceChange struct {
+	// Repository is the repository of the change, either 'owner/name' or an object identifying it
+	Repository ChangeRepository `json:"repository"`
+	// Number is the number of the pull request. Defaults to the ID of the subject if it is a number.
+	Number int `json:"number,omitempty"`
+	// Title is the title of the pull request
+	Title string `json:"title,omitempty"`
+	// Author is the login of the author of the change
+	Author string `json:"author,omitempty"`
+	// URL is the URL of the pull request
+	URL string `json:"url,omitempty"`
+	// Branch is the source branch of the change, or the created branch
+	Branch string `json:"branch,omitempty"`
+	// SHA is the head commit of the change, or of the created branch
+	SHA string `json:"sha,omitempty"`
+	// BaseBranch is the branch the change targets
+	BaseBranch string `json:"baseBranch,omitempty"`
+	// BaseSHA is the commit of the base branch the change is based on
+	BaseSHA string `json:"baseSHA,omitempty"`
+	// MergeSHA is the commit a merged change was merged as. Defaults to the head commit of the change.
+	MergeSHA string `json:"mergeSHA,omitempty"`
+	// ChangedFiles are the files changed by a merged change, used to decide which postsubmits run
+	ChangedFiles []string `json:"changedFiles,omitempty"`
+}
+
+// ChangeRepository identifies the repository of a change
+type ChangeRepository struct {
+	// ID is the 'owner/name' of the repository
+	ID string `json:"id,omitempty"`
+	// Owner is the owner of the repository, if not part of the ID
+	Owner string `json:"owner,omitempty"`
+	// Name is the name of the repository, if not part of the ID
+	Name string `json:"name,omitempty"`
+	// URL is the URL of the repository. Defaults to its URL on the git server.
+	URL string `json:"url,omitempty"`
+}
+
+// UnmarshalJSON accepts either the 'owner/name' of the repository or an object
+func (r *ChangeRepository) UnmarshalJSON(data []byte) error {
+	var id string
+	if err := json.Unmarshal(data, &id); err == nil {
+		*r = ChangeRepository{ID: id}
+		return nil
+	}
+	type repository ChangeRepository
+	return json.Unmarshal(data, (*repository)(r))
+}
+
+// FullName returns the 'owner/name' of the repository
+func (r *ChangeRepository) FullName() string {
+	if r.Owner != "" && r.Name != "" {
+		return r.Owner + "/" + r.Name
+	}
+	return strings.Trim(r.ID, "/")
+}
+
+// HandleCloudEvents handles the source change events sent as CloudEvents, e.g. by a CDEvents broker, by mapping them
+// to the webhooks of the SCM provider, which then trigger pipelines as if the SCM provider had sent them. The events
+// are authenticated with the HMAC token as a bearer token. Events of other types are acknowledged and ignored.
+func (o *WebhooksController) HandleCloudEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		// the abuse protection handshake of the CloudEvents webhook specification
+		if origin := r.Header.Get("WebHook-Request-Origin"); origin != "" {
+			w.Header().Set("WebHook-Allowed-Origin", origin)
+		}
+		w.Header().Set("Allow", "POST")
+		return
+	}
+	if r.Method != http.MethodPost {
+		responseHTTPError(w, http.StatusMethodNotAllowed, fmt.Sprintf("405 Method Not Allowed: %s", r.Method))
+		return
+	}
+	if !o.startEvent(w) {
+		return
+	}
+	defer o.finishEvent()
+
+	if !authorizedCloudEvent(r, util.HMACToken()) {
+		responseHTTPError(w, http.StatusUnauthorized, "401 Unauthorized: invalid or missing bearer token")
+		return
+	}
+	event, err := ParseCloudEvent(r)
+	if err != nil {
+		responseHTTPError(w, http.StatusBadRequest, fmt.Sprintf("400 Bad Request: %s", err.Error()))
+		return
+	}
+	l := logrus.WithFields(logrus.Fields{"CloudEventType": event.Type, "CloudEventSource": event.Source, "CloudEventID": event.ID})
+
+	cfg := o.server.ConfigAgent.Config
+	_, scmClient, serverURL, _, err := util.GetSCMClient("", cfg)
+	if err != nil {
+		l.Errorf("failed to create SCM scmClient: %s", err.Error())
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: %s", err.Error()))
+		return
+	}
+	webhook, err := event.ToWebhook(serverURL)
+	if err != nil {
+		responseHTTPError(w, http.StatusBadRequest, fmt.Sprintf("400 Bad Request: %s", err.Error()))
+		return
+	}
+	if webhook == nil {
+		l.Debug("ignoring unsupported CloudEvent")
+		_, err = w.Write([]byte(fmt.Sprintf("ignored CloudEvent of type %s", event.Type)))
+		if err != nil {
+			l.Debugf("failed to write the response: %v", err)
+		}
+		return
+	}
+	o.handleWebhook(w, cfg, scmClient, serverURL, webhook, event.DeliveryID())
+}
+
+// authorizedCloudEvent returns whether the request carries the token as a bearer token
+func authorizedCloudEvent(r *http.Request, token string) bool {
+	if token == "" {
+		return false
+	}
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(header, "Bearer ")), []byte(token)) == 1
+}
+
+// ParseCloudEvent parses a CloudEvent sent over HTTP in binary or structured content mode
+func ParseCloudEvent(r *http.Request) (*CloudEvent, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the body")
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	event := &CloudEvent{}
+	switch {
+	case mediaType == cloudEventsBatchContentType:
+		return nil, errors.New("batches of CloudEvents are not supported")
+	case mediaType == cloudEventsContentType:
+		if err := json.Unmarshal(body, event); err != nil {
+			return nil, errors.Wrap(err, "failed to parse the CloudEvent")
+		}
+	default:
+		event.SpecVersion = r.Header.Get("Ce-Specversion")
+		event.ID = r.Header.Get("Ce-Id")
+		event.Source = r.Header.Get("Ce-Source")
+		event.Type = r.Header.Get("Ce-Type")
+		event.Subject = r.Header.Get("Ce-Subject")
+		event.Data = body
+	}
+
+	var missing []string
+	for _, attribute := range []struct{ name, value string }{
+		{"id", event.ID},
+		{"source", event.Source},
+		{"specversion", event.SpecVersion},
+		{"type", event.Type},
+	} {
+		if attribute.value == "" {
+			missing = append(missing, attribute.name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("not a CloudEvent, missing the attributes %s", strings.Join(missing, ", "))
+	}
+	return event, nil
+}
+
+// DeliveryID returns the ID identifying the deliveries of the event, which is unique per source
+func (e *CloudEvent) DeliveryID() string {
+	return e.Source + "/" + e.ID
+}
+
+// ToWebhook maps a source change event to the webhook the SCM provider would have sent, or returns nil if the event
+// is not a source change event triggering pipelines
+func (e *CloudEvent) ToWebhook(serverURL string) (scm.Webhook, error) {
+	eventType := cdEventType(e.Type)
+	switch eventType {
+	case changeCreatedEventType, changeUpdatedEventType, changeMergedEventType, changeAbandonedEventType, branchCreatedEventType:
+	default:
+		return nil, nil
+	}
+
+	data := CDEvent{}
+	if err := json.Unmarshal(e.Data, &data); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the data of the %s event", e.Type)
+	}
+	subject := data.Subject
+	change := subject.Content
+	repo, err := change.repository(serverURL)
+	if err != nil {
+		return nil, err
+	}
+	sender := scm.User{Login: change.Author}
+	if sender.Login == "" {
+		sender.Login = e.Source
+	}
+
+	if eventType == branchCreatedEventType {
+		branch := change.Branch
+		if branch == "" {
+			branch = subject.ID
+		}
+		if branch == "" || change.SHA == "" {
+			return nil, fmt.Errorf("the %s event needs the branch and sha of its subject", e.Type)
+		}
+		return &scm.PushHook{
+			Ref:     "refs/heads/" + branch,
+			Repo:    *repo,
+			After:   change.SHA,
+			Created: true,
+			Commit:  scm.Commit{Sha: change.SHA},
+			Sender:  sender,
+			GUID:    e.DeliveryID(),
+		}, nil
+	}
+
+	if change.Number == 0 {
+		change.Number, _ = strconv.Atoi(subject.ID)
+	}
+	if change.Number <= 0 {
+		return nil, fmt.Errorf("the %s event needs the number of its change", e.Type)
+	}
+	if change.SHA == "" || change.BaseBranch == "" {
+		return nil, fmt.Errorf("the %s event needs the sha and baseBranch of its change", e.Type)
+	}
+
+	if eventType == changeMergedEventType {
+		// the merge is a push to the base branch, which triggers the postsubmits
+		after := change.MergeSHA
+		if after == "" {
+			after = change.SHA
+		}
+		hook := &scm.PushHook{
+			Ref:    "refs/heads/" + change.BaseBranch,
+			Repo:   *repo,
+			Before: change.BaseSHA,
+			After:  after,
+			Commit: scm.Commit{Sha: after},
+			Sender: sender,
+			GUID:   e.DeliveryID(),
+		}
+		if len(change.ChangedFiles) > 0 {
+			hook.Commits = []scm.PushCommit{{ID: after, Modified: change.ChangedFiles}}
+		}
+		return hook, nil
+	}
+
+	action := scm.ActionOpen
+	switch eventType {
+	case changeUpdatedEventType:
+		action = scm.ActionSync
+	case changeAbandonedEventType:
+		action = scm.ActionClose
+	}
+	link := change.URL
+	if link == "" {
+		link = subject.Source
+	}
+	return &scm.PullRequestHook{
+		Action: action,
+		Repo:   *repo,
+		PullRequest: scm.PullRequest{
+			Number: change.Number,
+			Title:  change.Title,
+			Sha:    change.SHA,
+			Ref:    fmt.Sprintf("refs/pull/%d/head", change.Number),
+			Source: change.Branch,
+			Target: change.BaseBranch,
+			Base: scm.PullRequestBranch{
+				Ref:  change.BaseBranch,
+				Sha:  change.BaseSHA,
+				Repo: *repo,
+			},
+			Head: scm.PullRequestBranch{
+				Ref:  change.Branch,
+				Sha:  change.SHA,
+				Repo: *repo,
+			},
+			Author: sender,
+			Link:   link,
+			Closed: action == scm.ActionClose,
+		},
+		Sender: sender,
+		GUID:   e.DeliveryID(),
+	}, nil
+}
+
+// repository returns the repository of the change on the git server
+func (c *SourceChange) repository(serverURL string) (*scm.Repository, error) {
+	fullName := c.Repository.FullName()
+	i := strings.LastIndex(fullName, "/")
+	if i <= 0 || i == len(fullName)-1 {
+		return nil, fmt.Errorf("invalid repository %q, it must be of the form owner/name", fullName)
+	}
+	link := c.Repository.URL
+	if link == "" {
+		link = strings.TrimSuffix(serverURL, "/") + "/" + fullName
+	}
+	return &scm.Repository{
+		ID:        fullName,
+		Namespace: fullName[:i],
+		Name:      fullName[i+1:],
+		FullName:  fullName,
+		Branch:    c.BaseBranch,
+		Clone:     strings.TrimSuffix(link, ".git") + ".git",
+		Link:      strings.TrimSuffix(link, ".git"),
+	}, nil
+}
+
+// cdEventType returns the type of a CDEvent without its version suffix, e.g. dev.cdevents.change.merged for
+// dev.cdevents.change.merged.0.1.0
+func cdEventType(eventType string) string {
+	parts := strings.Split(eventType, ".")
+	for i, part := range parts {
+		if _, err := strconv.Atoi(part); err == nil && i > 0 {
+			return strings.Join(parts[:i], ".")
+		}
+	}
+	return eventType
+}
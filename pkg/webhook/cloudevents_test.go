@@ -0,0 +1,229 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const changeData = `{
+  "context": {"version": "0.1.0", "id": "271069a8", "source": "/scm", "type": "%s"},
+  "subject": {
+    "id": "42",
+    "source": "https://example.com/myorg/myrepo/pull/42",
+    "type": "change",
+    "content": {
+      "repository": {"id": "myorg/myrepo", "source": "/scm"},
+      "title": "Add a feature",
+      "author": "someone",
+      "branch": "feature",
+      "sha": "headsha",
+      "baseBranch": "main",
+      "baseSHA": "basesha",
+      "changedFiles": ["README.md"]
+    }
+  }
+}`
+
+func TestParseCloudEvent(t *testing.T) {
+	testCases := []struct {
+		name        string
+		contentType string
+		headers     map[string]string
+		body        string
+		expected    *CloudEvent
+		expectedErr string
+	}{
+		{
+			name:        "binary mode",
+			contentType: "application/json",
+			headers: map[string]string{
+				"Ce-Specversion": "1.0",
+				"Ce-Id":          "1",
+				"Ce-Source":      "/scm",
+				"Ce-Type":        "dev.cdevents.change.created.0.1.0",
+			},
+			body: `{"subject": {}}`,
+			expected: &CloudEvent{
+				SpecVersion: "1.0",
+				ID:          "1",
+				Source:      "/scm",
+				Type:        "dev.cdevents.change.created.0.1.0",
+				Data:        []byte(`{"subject": {}}`),
+			},
+		},
+		{
+			name:        "structured mode",
+			contentType: "application/cloudevents+json; charset=utf-8",
+			body:        `{"specversion": "1.0", "id": "1", "source": "/scm", "type": "dev.cdevents.change.merged.0.1.0", "data": {"subject": {}}}`,
+			expected: &CloudEvent{
+				SpecVersion: "1.0",
+				ID:          "1",
+				Source:      "/scm",
+				Type:        "dev.cdevents.change.merged.0.1.0",
+				Data:        []byte(`{"subject": {}}`),
+			},
+		},
+		{
+			name:        "batch mode",
+			contentType: "application/cloudevents-batch+json",
+			body:        `[]`,
+			expectedErr: "batches of CloudEvents are not supported",
+		},
+		{
+			name:        "missing attributes",
+			contentType: "application/json",
+			headers:     map[string]string{"Ce-Id": "1"},
+			body:        `{}`,
+			expectedErr: "not a CloudEvent, missing the attributes source, specversion, type",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, CloudEventsPath, strings.NewReader(tc.body))
+			r.Header.Set("Content-Type", tc.contentType)
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+			event, err := ParseCloudEvent(r)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, event)
+		})
+	}
+}
+
+func TestCloudEventToWebhook(t *testing.T) {
+	repo := scm.Repository{
+		ID:        "myorg/myrepo",
+		Namespace: "myorg",
+		Name:      "myrepo",
+		FullName:  "myorg/myrepo",
+		Branch:    "main",
+		Clone:     "https://github.com/myorg/myrepo.git",
+		Link:      "https://github.com/myorg/myrepo",
+	}
+	pullRequest := func(action scm.Action) *scm.PullRequestHook {
+		return &scm.PullRequestHook{
+			Action: action,
+			Repo:   repo,
+			PullRequest: scm.PullRequest{
+				Number: 42,
+				Title:  "Add a feature",
+				Sha:    "headsha",
+				Ref:    "refs/pull/42/head",
+				Source: "feature",
+				Target: "main",
+				Base:   scm.PullRequestBranch{Ref: "main", Sha: "basesha", Repo: repo},
+				Head:   scm.PullRequestBranch{Ref: "feature", Sha: "headsha", Repo: repo},
+				Author: scm.User{Login: "someone"},
+				Link:   "https://example.com/myorg/myrepo/pull/42",
+				Closed: action == scm.ActionClose,
+			},
+			Sender: scm.User{Login: "someone"},
+			GUID:   "/scm/1",
+		}
+	}
+
+	testCases := []struct {
+		eventType   string
+		data        string
+		expected    scm.Webhook
+		expectedErr string
+	}{
+		{
+			eventType: "dev.cdevents.change.created.0.1.0",
+			expected:  pullRequest(scm.ActionOpen),
+		},
+		{
+			eventType: "dev.cdevents.change.updated.0.1.0",
+			expected:  pullRequest(scm.ActionSync),
+		},
+		{
+			eventType: "dev.cdevents.change.abandoned.0.1.0",
+			expected:  pullRequest(scm.ActionClose),
+		},
+		{
+			eventType: "dev.cdevents.change.merged.0.1.0",
+			expected: &scm.PushHook{
+				Ref:     "refs/heads/main",
+				Repo:    repo,
+				Before:  "basesha",
+				After:   "headsha",
+				Commits: []scm.PushCommit{{ID: "headsha", Modified: []string{"README.md"}}},
+				Commit:  scm.Commit{Sha: "headsha"},
+				Sender:  scm.User{Login: "someone"},
+				GUID:    "/scm/1",
+			},
+		},
+		{
+			eventType: "dev.cdevents.branch.created.0.1.0",
+			data:      `{"subject": {"id": "feature", "content": {"repository": "myorg/myrepo", "sha": "headsha"}}}`,
+			expected: &scm.PushHook{
+				Ref:     "refs/heads/feature",
+				Repo:    scm.Repository{ID: "myorg/myrepo", Namespace: "myorg", Name: "myrepo", FullName: "myorg/myrepo", Clone: repo.Clone, Link: repo.Link},
+				After:   "headsha",
+				Created: true,
+				Commit:  scm.Commit{Sha: "headsha"},
+				Sender:  scm.User{Login: "/scm"},
+				GUID:    "/scm/1",
+			},
+		},
+		{
+			eventType: "dev.cdevents.pipelinerun.finished.0.1.0",
+		},
+		{
+			eventType:   "dev.cdevents.change.created.0.1.0",
+			data:        `{"subject": {"id": "42", "content": {"repository": "myrepo", "sha": "headsha", "baseBranch": "main"}}}`,
+			expectedErr: `invalid repository "myrepo", it must be of the form owner/name`,
+		},
+		{
+			eventType:   "dev.cdevents.change.created.0.1.0",
+			data:        `{"subject": {"id": "change", "content": {"repository": "myorg/myrepo", "sha": "headsha", "baseBranch": "main"}}}`,
+			expectedErr: "the dev.cdevents.change.created.0.1.0 event needs the number of its change",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.eventType, func(t *testing.T) {
+			data := tc.data
+			if data == "" {
+				data = strings.Replace(changeData, "%s", tc.eventType, 1)
+			}
+			event := &CloudEvent{SpecVersion: "1.0", ID: "1", Source: "/scm", Type: tc.eventType, Data: []byte(data)}
+			webhook, err := event.ToWebhook("https://github.com")
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, webhook)
+		})
+	}
+}
+
+func TestAuthorizedCloudEvent(t *testing.T) {
+	testCases := []struct {
+		header   string
+		token    string
+		expected bool
+	}{
+		{header: "Bearer secret", token: "secret", expected: true},
+		{header: "Bearer wrong", token: "secret"},
+		{header: "secret", token: "secret"},
+		{header: "", token: "secret"},
+		{header: "Bearer ", token: ""},
+	}
+	for _, tc := range testCases {
+		r := httptest.NewRequest(http.MethodPost, CloudEventsPath, nil)
+		r.Header.Set("Authorization", tc.header)
+		assert.Equal(t, tc.expected, authorizedCloudEvent(r, tc.token), "header %q", tc.header)
+	}
+}
@@ -0,0 +1,288 @@
+package webhook
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/config/lighthouse"
+	"github.com/jenkins-x/lighthouse/pkg/interrupts"
+	"github.com/sirupsen/logrus"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coordinationclient "k8s.io/client-go/kubernetes/typed/coordination/v1"
+)
+
+const (
+	// defaultDeliveryTTL is how long we remember processed webhook deliveries for
+	defaultDeliveryTTL = time.Hour
+	// defaultMaxDeliveries is the maximum number of processed webhook deliveries remembered in memory
+	defaultMaxDeliveries = 10000
+	// deliveryLabel is the label added to the Leases used to share processed deliveries between replicas
+	deliveryLabel = "lighthouse.jenkins-x.io/webhook-delivery"
+)
+
+// deliveryIDHeaders are the headers the various git providers use to uniquely identify a webhook delivery. Retried
+// deliveries reuse the same ID.
+var deliveryIDHeaders = []string{
+	"X-GitHub-Delivery",
+	"X-Gitea-Delivery",
+	"X-Gogs-Delivery",
+	"X-Gitlab-Event-UUID",
+	"X-Request-UUID",
+	"X-Request-Id",
+}
+
+// deliveryID returns the unique ID of the webhook delivery, or an empty string if the provider did not send one
+func deliveryID(r *http.Request) string {
+	for _, h := range deliveryIDHeaders {
+		if id := r.Header.Get(h); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+type delivery struct {
+	id        string
+	processed time.Time
+}
+
+// deliveryCache is an LRU of the IDs of recently processed webhook deliveries so that redelivered webhooks are not
+// processed twice, which would otherwise create duplicate pipelines
+type deliveryCache struct {
+	lock       sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    *list.List
+	index      map[string]*list.Element
+}
+
+func newDeliveryCache(ttl time.Duration, maxEntries int) *deliveryCache {
+	return &deliveryCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    list.New(),
+		index:      map[string]*list.Element{},
+	}
+}
+
+// configure updates the TTL and size of the cache, evicting the oldest deliveries if it shrinks
+func (c *deliveryCache) configure(ttl time.Duration, maxEntries int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.ttl = ttl
+	c.maxEntries = maxEntries
+	c.evict()
+}
+
+// markProcessed records the delivery as processed, returning false if it was already processed within the TTL
+func (c *deliveryCache) markProcessed(id string, now time.Time) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if e, ok := c.index[id]; ok {
+		d := e.Value.(*delivery)
+		if now.Sub(d.processed) <= c.ttl {
+			return false
+		}
+		d.processed = now
+		c.entries.MoveToFront(e)
+		return true
+	}
+	c.index[id] = c.entries.PushFront(&delivery{id: id, processed: now})
+	c.evict()
+	return true
+}
+
+// forget removes the delivery so that a redelivery is processed again, e.g. if processing it failed
+func (c *deliveryCache) forget(id string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if e, ok := c.index[id]; ok {
+		c.entries.Remove(e)
+		delete(c.index, id)
+	}
+}
+
+func (c *deliveryCache) evict() {
+	for c.maxEntries > 0 && c.entries.Len() > c.maxEntries {
+		e := c.entries.Back()
+		c.entries.Remove(e)
+		delete(c.index, e.Value.(*delivery).id)
+	}
+}
+
+// deliveryStore records processed deliveries as Leases so that they are shared by all webhook replicas. The Leases
+// are deleted by a periodic sweep once expired, or when there are more than the maximum number of deliveries.
+type deliveryStore struct {
+	leases coordinationclient.LeaseInterface
+}
+
+func newDeliveryStore(leases coordinationclient.LeaseInterface) *deliveryStore {
+	return &deliveryStore{leases: leases}
+}
+
+func deliveryLeaseName(id string) string {
+	return fmt.Sprintf("lighthouse-delivery-%x", sha256.Sum256([]byte(id)))[:63]
+}
+
+// markProcessed records the delivery as processed, returning false if another replica already processed it within
+// the TTL
+func (s *deliveryStore) markProcessed(id string, now time.Time, ttl time.Duration) (bool, error) {
+	acquired := metav1.NewMicroTime(now)
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   deliveryLeaseName(id),
+			Labels: map[string]string{deliveryLabel: "true"},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity: &id,
+			AcquireTime:    &acquired,
+		},
+	}
+	_, err := s.leases.Create(lease)
+	if err == nil {
+		return true, nil
+	}
+	if !kerrors.IsAlreadyExists(err) {
+		return false, err
+	}
+
+	existing, err := s.leases.Get(lease.Name, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	if existing.Spec.AcquireTime != nil && now.Sub(existing.Spec.AcquireTime.Time) <= ttl {
+		return false, nil
+	}
+	existing.Spec.AcquireTime = &acquired
+	_, err = s.leases.Update(existing)
+	if kerrors.IsConflict(err) {
+		// another replica has just taken over the expired delivery
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// forget removes the delivery so that a redelivery is processed again
+func (s *deliveryStore) forget(id string) error {
+	err := s.leases.Delete(deliveryLeaseName(id), &metav1.DeleteOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// prune deletes the expired deliveries, then the least recently processed ones above the maximum number of deliveries
+func (s *deliveryStore) prune(now time.Time, ttl time.Duration, maxEntries int) error {
+	leases, err := s.leases.List(metav1.ListOptions{LabelSelector: deliveryLabel})
+	if err != nil {
+		return fmt.Errorf("failed to list webhook delivery leases: %v", err)
+	}
+	var remaining []*coordinationv1.Lease
+	var obsolete []*coordinationv1.Lease
+	for i := range leases.Items {
+		lease := &leases.Items[i]
+		if lease.Spec.AcquireTime != nil && now.Sub(lease.Spec.AcquireTime.Time) <= ttl {
+			remaining = append(remaining, lease)
+		} else {
+			obsolete = append(obsolete, lease)
+		}
+	}
+	if maxEntries > 0 && len(remaining) > maxEntries {
+		sort.Slice(remaining, func(i, j int) bool {
+			return remaining[i].Spec.AcquireTime.After(remaining[j].Spec.AcquireTime.Time)
+		})
+		obsolete = append(obsolete, remaining[maxEntries:]...)
+	}
+	for _, lease := range obsolete {
+		if err := s.leases.Delete(lease.Name, &metav1.DeleteOptions{}); err != nil && !kerrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete webhook delivery lease %s: %v", lease.Name, err)
+		}
+	}
+	return nil
+}
+
+// isDuplicateDelivery returns true if the delivery has already been processed by this or, when the shared store is
+// enabled, any other replica within the configured TTL
+func (o *WebhooksController) isDuplicateDelivery(id string, cfg lighthouse.WebhookDedupe) bool {
+	if id == "" || o.deliveries == nil {
+		return false
+	}
+	ttl, maxEntries := dedupeLimits(cfg)
+	now := time.Now()
+	o.deliveries.configure(ttl, maxEntries)
+	if !o.deliveries.markProcessed(id, now) {
+		duplicateDeliveryCounter.WithLabelValues("memory").Inc()
+		return true
+	}
+	if !cfg.SharedStore || o.sharedStore == nil {
+		return false
+	}
+	first, err := o.sharedStore.markProcessed(id, now, ttl)
+	if err != nil {
+		// rather process a duplicate than drop a webhook
+		logrus.WithError(err).WithField("DeliveryID", id).Warn("failed to record webhook delivery in the shared store")
+		return false
+	}
+	if !first {
+		duplicateDeliveryCounter.WithLabelValues("shared").Inc()
+		return true
+	}
+	return false
+}
+
+// forgetDelivery removes the delivery so that a redelivery is processed again, e.g. if processing it failed
+func (o *WebhooksController) forgetDelivery(id string) {
+	if id == "" || o.deliveries == nil {
+		return
+	}
+	o.deliveries.forget(id)
+	if o.sharedStore != nil && o.server.ConfigAgent.Config().WebhookDedupe.SharedStore {
+		if err := o.sharedStore.forget(id); err != nil {
+			logrus.WithError(err).WithField("DeliveryID", id).Warn("failed to remove webhook delivery from the shared store")
+		}
+	}
+}
+
+// startDeliverySweep periodically deletes the expired deliveries from the shared store, and the least recently
+// processed ones above the maximum number of deliveries, so that there is not a Lease left behind per delivery. Only
+// the elected replica prunes them.
+func (o *WebhooksController) startDeliverySweep() {
+	interrupts.Tick(o.pruneDeliveries, func() time.Duration {
+		ttl, _ := dedupeLimits(o.server.ConfigAgent.Config().WebhookDedupe)
+		return ttl
+	})
+}
+
+func (o *WebhooksController) pruneDeliveries() {
+	cfg := o.server.ConfigAgent.Config().WebhookDedupe
+	if !cfg.SharedStore || o.sharedStore == nil || !o.sweepLeader.IsLeader() {
+		return
+	}
+	ttl, maxEntries := dedupeLimits(cfg)
+	if err := o.sharedStore.prune(time.Now(), ttl, maxEntries); err != nil {
+		logrus.WithError(err).Warn("failed to prune the webhook deliveries of the shared store")
+	}
+}
+
+// dedupeLimits returns how long and how many processed deliveries are remembered for
+func dedupeLimits(cfg lighthouse.WebhookDedupe) (time.Duration, int) {
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = defaultDeliveryTTL
+	}
+	maxEntries := cfg.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxDeliveries
+	}
+	return ttl, maxEntries
+}
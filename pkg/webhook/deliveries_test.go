@@ -0,0 +1,97 @@
+package webhook
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDeliveryCache(t *testing.T) {
+	c := newDeliveryCache(time.Minute, 10)
+	now := time.Now()
+
+	assert.True(t, c.markProcessed("a", now), "first delivery should be processed")
+	assert.False(t, c.markProcessed("a", now.Add(30*time.Second)), "redelivery within the TTL should be skipped")
+	assert.True(t, c.markProcessed("b", now.Add(30*time.Second)), "a different delivery should be processed")
+	assert.True(t, c.markProcessed("a", now.Add(2*time.Minute)), "redelivery after the TTL should be processed")
+
+	c.forget("b")
+	assert.True(t, c.markProcessed("b", now.Add(2*time.Minute)), "a forgotten delivery should be processed again")
+	assert.Len(t, c.index, 2)
+}
+
+func TestDeliveryCacheEvictsLeastRecentlyProcessed(t *testing.T) {
+	c := newDeliveryCache(time.Hour, 2)
+	now := time.Now()
+
+	assert.True(t, c.markProcessed("a", now))
+	assert.True(t, c.markProcessed("b", now))
+	assert.True(t, c.markProcessed("c", now))
+	assert.Equal(t, 2, c.entries.Len())
+	assert.True(t, c.markProcessed("a", now), "the oldest delivery should have been evicted")
+	assert.False(t, c.markProcessed("c", now))
+
+	c.configure(time.Hour, 1)
+	assert.Equal(t, 1, c.entries.Len())
+	assert.False(t, c.markProcessed("a", now), "the most recently processed delivery should be kept when shrinking")
+}
+
+func TestDeliveryStore(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	leases := kubeClient.CoordinationV1().Leases("jx")
+	replica1 := newDeliveryStore(leases)
+	replica2 := newDeliveryStore(leases)
+	now := time.Now()
+
+	first, err := replica1.markProcessed("a", now, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, first, "first delivery should be processed")
+
+	first, err = replica2.markProcessed("a", now.Add(30*time.Second), time.Minute)
+	require.NoError(t, err)
+	assert.False(t, first, "redelivery to another replica within the TTL should be skipped")
+
+	first, err = replica2.markProcessed("a", now.Add(2*time.Minute), time.Minute)
+	require.NoError(t, err)
+	assert.True(t, first, "redelivery after the TTL should be processed")
+
+	require.NoError(t, replica2.forget("a"))
+	_, err = leases.Get(deliveryLeaseName("a"), metav1.GetOptions{})
+	assert.Error(t, err, "the lease should be deleted when a delivery is forgotten")
+	assert.NoError(t, replica2.forget("a"), "forgetting an unknown delivery should not fail")
+}
+
+func TestDeliveryStorePrune(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	leases := kubeClient.CoordinationV1().Leases("jx")
+	store := newDeliveryStore(leases)
+	now := time.Now()
+
+	for i, id := range []string{"expired", "a", "b", "c"} {
+		_, err := store.markProcessed(id, now.Add(time.Duration(i-3)*time.Minute), 150*time.Second)
+		require.NoError(t, err)
+	}
+	require.NoError(t, store.prune(now, 150*time.Second, 2))
+
+	list, err := leases.List(metav1.ListOptions{LabelSelector: deliveryLabel})
+	require.NoError(t, err)
+	var names []string
+	for _, lease := range list.Items {
+		names = append(names, lease.Name)
+	}
+	assert.ElementsMatch(t, []string{deliveryLeaseName("b"), deliveryLeaseName("c")}, names, "the expired and least recently processed deliveries should be deleted")
+}
+
+func TestDeliveryID(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "/hook", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "", deliveryID(r))
+
+	r.Header.Set("X-GitHub-Delivery", "72d3162e-cc78-11e3-81ab-4c9367dc0958")
+	assert.Equal(t, "72d3162e-cc78-11e3-81ab-4c9367dc0958", deliveryID(r))
+}
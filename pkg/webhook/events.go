@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"runtime/debug"
 	"strconv"
 	"sync"
 
@@ -41,12 +42,47 @@ type Server struct {
 
 	// Tracks running handlers for graceful shutdown
 	wg sync.WaitGroup
+	// pool runs the plugin handlers on a bounded number of workers, each handler runs in its own goroutine when nil
+	pool *workerPool
 }
 
 const failedCommentCoerceFmt = "Could not coerce %s event to a GenericCommentEvent. Unknown 'action': %q."
 
 func (s *Server) getPlugins(org, repo string) map[string]plugins.Plugin {
-	return s.Plugins.GetPlugins(org, repo, s.ClientAgent.SCMProviderClient.Driver.String())
+	return s.Plugins.GetPlugins(org, repo, scmprovider.DriverName(s.ClientAgent.SCMProviderClient.Driver))
+}
+
+// runPlugin runs the handler of a plugin for an event concurrently with the other plugins, on the worker pool if any.
+// A panic of the handler is recovered so that it does not affect the other plugins, and the result is recorded in the
+// plugin metrics.
+func (s *Server) runPlugin(l *logrus.Entry, plugin, eventType, org, repo, ref string, handle func(plugins.Agent) error) {
+	s.wg.Add(1)
+	run := func() {
+		defer s.wg.Done()
+		result := pluginResultSuccess
+		defer func() {
+			if r := recover(); r != nil {
+				result = pluginResultPanic
+				l.WithField("plugin", plugin).Errorf("Panic handling %s: %v\n%s", eventType, r, debug.Stack())
+			}
+			pluginHandlerCounter.WithLabelValues(plugin, eventType, result).Inc()
+		}()
+		agent, err := s.CreateAgent(l, plugin, org, repo, ref)
+		if err != nil {
+			result = pluginResultError
+			agent.Logger.WithError(err).Errorf("Error creating agent for %s.", eventType)
+			return
+		}
+		if err := handle(agent); err != nil {
+			result = pluginResultError
+			agent.Logger.WithError(err).Errorf("Error handling %s.", eventType)
+		}
+	}
+	if s.pool != nil {
+		s.pool.submit(org+"/"+repo, run)
+		return
+	}
+	go run()
 }
 
 // handleIssueCommentEvent handle comment events
@@ -123,39 +159,22 @@ func (s *Server) handlePullRequestCommentEvent(l *logrus.Entry, pc scm.PullReque
 
 func (s *Server) handleGenericComment(l *logrus.Entry, ce *scmprovider.GenericCommentEvent) {
 	for p, h := range s.getPlugins(ce.Repo.Namespace, ce.Repo.Name) {
-		if h.GenericCommentHandler != nil {
-			s.wg.Add(1)
-			go func(p string, h plugins.GenericCommentHandler) {
-				defer s.wg.Done()
-				agent, err := s.CreateAgent(l, p, ce.Repo.Namespace, ce.Repo.Name, ce.HeadSha)
-				if err != nil {
-					agent.Logger.WithError(err).Error("Error creating agent for GenericCommentEvent.")
-					return
-				}
-				if err := h(agent, *ce); err != nil {
-					agent.Logger.WithError(err).Error("Error handling GenericCommentEvent.")
-				}
-			}(p, h.GenericCommentHandler)
+		if h := h.GenericCommentHandler; h != nil {
+			s.runPlugin(l, p, "GenericCommentEvent", ce.Repo.Namespace, ce.Repo.Name, ce.HeadSha, func(agent plugins.Agent) error {
+				return h(agent, *ce)
+			})
 		}
 		for _, cmd := range h.Commands {
 			err := cmd.InvokeCommandHandler(ce, func(handler plugins.CommandEventHandler, e *scmprovider.GenericCommentEvent, match plugins.CommandMatch) error {
-				s.wg.Add(1)
-				go func(p string, h plugins.CommandEventHandler, m plugins.CommandMatch) {
-					defer s.wg.Done()
-					agent, err := s.CreateAgent(l, p, ce.Repo.Namespace, ce.Repo.Name, ce.HeadSha)
-					if err != nil {
-						agent.Logger.WithError(err).Error("Error creating agent for GenericCommentEvent.")
-						return
-					}
-					agent.InitializeCommentPruner(
+				s.runPlugin(l, p, "GenericCommentEvent", ce.Repo.Namespace, ce.Repo.Name, ce.HeadSha, func(agent plugins.Agent) error {
+					agent.InitializeBotComments(
 						ce.Repo.Namespace,
 						ce.Repo.Name,
 						ce.Number,
+						ce.IsPR,
 					)
-					if err := h(m, agent, *ce); err != nil {
-						agent.Logger.WithError(err).Error("Error handling GenericCommentEvent.")
-					}
-				}(p, handler, match)
+					return handler(match, agent, *ce)
+				})
 				return nil
 			})
 			if err != nil {
@@ -177,25 +196,43 @@ func (s *Server) handlePushEvent(l *logrus.Entry, pe *scm.PushHook) {
 	l.Info("Push event.")
 	c := 0
 	for p, h := range s.getPlugins(pe.Repo.Namespace, pe.Repo.Name) {
-		if h.PushEventHandler != nil {
-			s.wg.Add(1)
+		if h := h.PushEventHandler; h != nil {
 			c++
-			go func(p string, h plugins.PushEventHandler) {
-				defer s.wg.Done()
-				agent, err := s.CreateAgent(l, p, repo.Namespace, repo.Name, pe.Ref)
-				if err != nil {
-					agent.Logger.WithError(err).Error("Error creating agent for PushEvent.")
-					return
-				}
-				if err := h(agent, *pe); err != nil {
-					agent.Logger.WithError(err).Error("Error handling PushEvent.")
-				}
-			}(p, h.PushEventHandler)
+			s.runPlugin(l, p, "PushEvent", repo.Namespace, repo.Name, pe.Ref, func(agent plugins.Agent) error {
+				return h(agent, *pe)
+			})
 		}
 	}
 	l.WithField("count", strconv.Itoa(c)).Info("number of push handlers")
 }
 
+// handleDeploymentEvent handles a deployment event
+func (s *Server) handleDeploymentEvent(l *logrus.Entry, dh *scm.DeployHook) {
+	repo := dh.Repository()
+	l = l.WithFields(logrus.Fields{
+		scmprovider.OrgLogField:  repo.Namespace,
+		scmprovider.RepoLogField: repo.Name,
+		"environment":            dh.Target,
+		"ref":                    dh.Ref.Name,
+		"sha":                    dh.Ref.Sha,
+	})
+	l.Info("Deployment event.")
+	ref := dh.Ref.Sha
+	if ref == "" {
+		ref = dh.Ref.Name
+	}
+	c := 0
+	for p, h := range s.getPlugins(repo.Namespace, repo.Name) {
+		if h := h.DeploymentEventHandler; h != nil {
+			c++
+			s.runPlugin(l, p, "DeploymentEvent", repo.Namespace, repo.Name, ref, func(agent plugins.Agent) error {
+				return h(agent, *dh)
+			})
+		}
+	}
+	l.WithField("count", strconv.Itoa(c)).Info("number of deployment handlers")
+}
+
 func (s *Server) handlePullRequestEvent(l *logrus.Entry, pr *scm.PullRequestHook) {
 	l = l.WithFields(logrus.Fields{
 		scmprovider.OrgLogField:  pr.Repo.Namespace,
@@ -212,25 +249,17 @@ func (s *Server) handlePullRequestEvent(l *logrus.Entry, pr *scm.PullRequestHook
 		repo = pr.Repo
 	}
 	for p, h := range s.getPlugins(repo.Namespace, repo.Name) {
-		if h.PullRequestHandler != nil {
-			s.wg.Add(1)
+		if h := h.PullRequestHandler; h != nil {
 			c++
-			go func(p string, h plugins.PullRequestHandler) {
-				defer s.wg.Done()
-				agent, err := s.CreateAgent(l, p, repo.Namespace, repo.Name, pr.PullRequest.Sha)
-				if err != nil {
-					agent.Logger.WithError(err).Error("Error creating agent for PullRequestEvent.")
-					return
-				}
-				agent.InitializeCommentPruner(
+			s.runPlugin(l, p, "PullRequestEvent", repo.Namespace, repo.Name, pr.PullRequest.Sha, func(agent plugins.Agent) error {
+				agent.InitializeBotComments(
 					pr.Repo.Namespace,
 					pr.Repo.Name,
 					pr.PullRequest.Number,
+					true,
 				)
-				if err := h(agent, *pr); err != nil {
-					agent.Logger.WithError(err).Error("Error handling PullRequestEvent.")
-				}
-			}(p, h.PullRequestHandler)
+				return h(agent, *pr)
+			})
 		}
 	}
 	l.WithField("count", strconv.Itoa(c)).Info("number of PR handlers")
@@ -277,24 +306,16 @@ func (s *Server) handleReviewEvent(l *logrus.Entry, re scm.ReviewHook) {
 	l.Infof("Review %s.", re.Action)
 	for p, h := range s.getPlugins(re.PullRequest.Base.Repo.Namespace, re.PullRequest.Base.Repo.Name) {
 		repo := re.PullRequest.Base.Repo
-		if h.ReviewEventHandler != nil {
-			s.wg.Add(1)
-			go func(p string, h plugins.ReviewEventHandler) {
-				defer s.wg.Done()
-				agent, err := s.CreateAgent(l, p, repo.Namespace, repo.Name, re.PullRequest.Sha)
-				if err != nil {
-					agent.Logger.WithError(err).Error("Error creating agent for ReviewEvent.")
-					return
-				}
-				agent.InitializeCommentPruner(
+		if h := h.ReviewEventHandler; h != nil {
+			s.runPlugin(l, p, "ReviewEvent", repo.Namespace, repo.Name, re.PullRequest.Sha, func(agent plugins.Agent) error {
+				agent.InitializeBotComments(
 					re.Repo.Namespace,
 					re.Repo.Name,
 					re.PullRequest.Number,
+					true,
 				)
-				if err := h(agent, re); err != nil {
-					agent.Logger.WithError(err).Error("Error handling ReviewEvent.")
-				}
-			}(p, h.ReviewEventHandler)
+				return h(agent, re)
+			})
 		}
 	}
 
@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+
+	fakescm "github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunPluginIsolatesPanics(t *testing.T) {
+	configAgent := &config.Agent{}
+	configAgent.Set(&config.Config{})
+	pluginAgent := &plugins.ConfigAgent{}
+	pluginAgent.Set(&plugins.Configuration{})
+	scmClient, _ := fakescm.NewDefault()
+	s := &Server{
+		ConfigAgent: configAgent,
+		Plugins:     pluginAgent,
+		ClientAgent: &plugins.ClientAgent{SCMProviderClient: scmClient},
+	}
+	l := logrus.WithField("test", t.Name())
+
+	count := func(plugin, result string) float64 {
+		return testutil.ToFloat64(pluginHandlerCounter.WithLabelValues(plugin, "PushEvent", result))
+	}
+	handled := false
+	s.runPlugin(l, "test-panic", "PushEvent", "org", "repo", "master", func(plugins.Agent) error {
+		panic("boom")
+	})
+	s.runPlugin(l, "test-error", "PushEvent", "org", "repo", "master", func(plugins.Agent) error {
+		return errors.New("failed")
+	})
+	s.runPlugin(l, "test-success", "PushEvent", "org", "repo", "master", func(plugins.Agent) error {
+		handled = true
+		return nil
+	})
+	s.wg.Wait()
+
+	assert.True(t, handled)
+	assert.Equal(t, float64(1), count("test-panic", pluginResultPanic))
+	assert.Equal(t, float64(1), count("test-error", pluginResultError))
+	assert.Equal(t, float64(1), count("test-success", pluginResultSuccess))
+	assert.Equal(t, float64(0), count("test-success", pluginResultPanic))
+}
@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/apis/lighthouse/v1alpha1"
+	"github.com/jenkins-x/lighthouse/pkg/health"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/pkg/errors"
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// scmCheckTTL is how long the result of the check of the SCM credentials is remembered, so that the readiness probes
+// do not use up the rate limit of the SCM provider
+const scmCheckTTL = time.Minute
+
+// LivenessChecks returns the checks of the liveness endpoint. There are none as serving the endpoint is enough to be
+// alive, and restarting the webhooks does not fix their dependencies.
+func (o *WebhooksController) LivenessChecks() []health.Check {
+	return nil
+}
+
+// ReadinessChecks returns the checks of the readiness endpoint: that the webhooks are not shutting down, that the
+// configuration is loaded, and that the Kubernetes API, the LighthouseJob CRD and the SCM provider API are reachable
+// with the configured credentials. The Tekton CRDs are checked too if checkTekton is set.
+func (o *WebhooksController) ReadinessChecks(checkTekton bool) []health.Check {
+	checks := []health.Check{
+		{Name: "shutdown", Run: o.checkNotShuttingDown},
+		{Name: "config", Run: o.checkConfig},
+		{Name: "kubernetes", Run: o.checkKubernetes},
+		health.Cached(health.Check{Name: "scm", Run: o.checkSCM}, scmCheckTTL),
+	}
+	if checkTekton {
+		checks = append(checks, health.Check{Name: "tekton", Run: o.checkTekton})
+	}
+	return checks
+}
+
+func (o *WebhooksController) checkNotShuttingDown(context.Context) error {
+	if o.isShuttingDown() {
+		return errors.New("shutting down")
+	}
+	return nil
+}
+
+func (o *WebhooksController) checkConfig(context.Context) error {
+	if o.ConfigMapWatcher != nil {
+		if err := o.ConfigMapWatcher.LoadError(); err != nil {
+			return err
+		}
+	}
+	if o.server.ConfigAgent.Config() == nil {
+		return errors.New("the config has not been loaded")
+	}
+	if o.server.Plugins.Config() == nil {
+		return errors.New("the plugins config has not been loaded")
+	}
+	return nil
+}
+
+func (o *WebhooksController) checkKubernetes(context.Context) error {
+	if o.kubeClient == nil {
+		return errors.New("no Kubernetes client")
+	}
+	if _, err := o.kubeClient.Discovery().ServerVersion(); err != nil {
+		return errors.Wrap(err, "failed to reach the Kubernetes API")
+	}
+	return o.checkResource(v1alpha1.SchemeGroupVersion, "lighthousejobs")
+}
+
+func (o *WebhooksController) checkTekton(context.Context) error {
+	if o.kubeClient == nil {
+		return errors.New("no Kubernetes client")
+	}
+	return o.checkResource(tektonv1beta1.SchemeGroupVersion, "pipelineruns")
+}
+
+// checkResource checks that the Kubernetes API serves the resource, i.e. that its CRD is installed
+func (o *WebhooksController) checkResource(gv schema.GroupVersion, resource string) error {
+	resources, err := o.kubeClient.Discovery().ServerResourcesForGroupVersion(gv.String())
+	if err != nil {
+		return errors.Wrapf(err, "failed to find the %s resources, is their CRD installed?", gv)
+	}
+	for _, r := range resources.APIResources {
+		if r.Name == resource {
+			return nil
+		}
+	}
+	return fmt.Errorf("the %s resource of %s is not served, is its CRD installed?", resource, gv)
+}
+
+func (o *WebhooksController) checkSCM(ctx context.Context) error {
+	if util.GetGitHubAppSecretDir() != "" {
+		// the tokens are per owner so only the app user is checked
+		if _, err := util.GetGitHubAppAPIUser(); err != nil {
+			return errors.Wrap(err, "failed to read the GitHub App user")
+		}
+		return nil
+	}
+	_, scmClient, serverURL, _, err := util.GetSCMClient("", o.server.ConfigAgent.Config)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the SCM client")
+	}
+	if _, _, err := scmClient.Users.Find(ctx); err != nil {
+		return errors.Wrapf(err, "failed to find the user of the SCM credentials on %s", serverURL)
+	}
+	return nil
+}
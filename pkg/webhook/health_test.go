@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/health"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestReadinessChecks(t *testing.T) {
+	for k, v := range map[string]string{"GIT_KIND": "fake", "GIT_TOKEN": "abc"} {
+		old, ok := os.LookupEnv(k)
+		os.Setenv(k, v)
+		if ok {
+			defer os.Setenv(k, old)
+		} else {
+			defer os.Unsetenv(k)
+		}
+	}
+
+	lighthouseResources := &metav1.APIResourceList{
+		GroupVersion: "lighthouse.jenkins.io/v1alpha1",
+		APIResources: []metav1.APIResource{{Name: "lighthousejobs"}},
+	}
+	tektonResources := &metav1.APIResourceList{
+		GroupVersion: "tekton.dev/v1beta1",
+		APIResources: []metav1.APIResource{{Name: "pipelineruns"}},
+	}
+
+	testCases := []struct {
+		name         string
+		noConfig     bool
+		resources    []*metav1.APIResourceList
+		shuttingDown bool
+		expected     map[string]string
+	}{
+		{
+			name:      "ready",
+			resources: []*metav1.APIResourceList{lighthouseResources, tektonResources},
+			expected:  map[string]string{},
+		},
+		{
+			name:     "not ready",
+			noConfig: true,
+			expected: map[string]string{
+				"config":     "the config has not been loaded",
+				"kubernetes": "failed to find the lighthouse.jenkins.io/v1alpha1 resources, is their CRD installed?: GroupVersion \"lighthouse.jenkins.io/v1alpha1\" not found",
+				"tekton":     "failed to find the tekton.dev/v1beta1 resources, is their CRD installed?: GroupVersion \"tekton.dev/v1beta1\" not found",
+			},
+		},
+		{
+			name:      "missing resource",
+			resources: []*metav1.APIResourceList{{GroupVersion: "lighthouse.jenkins.io/v1alpha1"}, tektonResources},
+			expected: map[string]string{
+				"kubernetes": "the lighthousejobs resource of lighthouse.jenkins.io/v1alpha1 is not served, is its CRD installed?",
+			},
+		},
+		{
+			name:         "shutting down",
+			resources:    []*metav1.APIResourceList{lighthouseResources, tektonResources},
+			shuttingDown: true,
+			expected:     map[string]string{"shutdown": "shutting down"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			configAgent := &config.Agent{}
+			if !tc.noConfig {
+				configAgent.Set(&config.Config{})
+			}
+			pluginAgent := &plugins.ConfigAgent{}
+			pluginAgent.Set(&plugins.Configuration{})
+			kubeClient := fake.NewSimpleClientset()
+			kubeClient.Discovery().(*fakediscovery.FakeDiscovery).Resources = tc.resources
+			o := &WebhooksController{
+				kubeClient:   kubeClient,
+				server:       &Server{ConfigAgent: configAgent, Plugins: pluginAgent},
+				shuttingDown: tc.shuttingDown,
+			}
+
+			response := health.Run(context.Background(), o.ReadinessChecks(true))
+			failures := map[string]string{}
+			for _, r := range response.Checks {
+				if !r.OK {
+					failures[r.Name] = r.Error
+				}
+			}
+			assert.Equal(t, tc.expected, failures)
+		})
+	}
+}
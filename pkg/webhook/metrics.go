@@ -30,11 +30,27 @@ var (
 		Name: "prow_webhook_response_codes",
 		Help: "A counter of the different responses hook has responded to webhooks with.",
 	}, []string{"response_code"})
+	duplicateDeliveryCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "lighthouse_webhook_duplicate_deliveries",
+		Help: "A counter of the redelivered webhooks which were skipped as they were already processed.",
+	}, []string{"store"})
+	pluginHandlerCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "lighthouse_plugin_handled_events",
+		Help: "A counter of the events handled by the plugins, by result: success, error or panic.",
+	}, []string{"plugin", "event_type", "result"})
+)
+
+const (
+	pluginResultSuccess = "success"
+	pluginResultError   = "error"
+	pluginResultPanic   = "panic"
 )
 
 func init() {
 	prometheus.MustRegister(webhookCounter)
 	prometheus.MustRegister(responseCounter)
+	prometheus.MustRegister(duplicateDeliveryCounter)
+	prometheus.MustRegister(pluginHandlerCounter)
 }
 
 // Metrics is a set of metrics gathered by hook.
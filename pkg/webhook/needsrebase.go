@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/interrupts"
+	"github.com/jenkins-x/lighthouse/pkg/plugins/needsrebase"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+)
+
+// startNeedsRebaseSweep periodically checks the open pull requests of the repositories the needs-rebase plugin is
+// enabled for, as they can start conflicting with their base branch without any webhook for the pull request itself.
+// Only the elected replica checks them so that the pull requests are not labelled and commented once per replica.
+func (o *WebhooksController) startNeedsRebaseSweep() {
+	interrupts.Tick(o.sweepNeedsRebase, func() time.Duration {
+		if cfg := o.server.Plugins.Config(); cfg != nil && cfg.NeedsRebase.SweepIntervalDuration > 0 {
+			return cfg.NeedsRebase.SweepIntervalDuration
+		}
+		// check again later in case the sweep gets enabled
+		return time.Hour
+	})
+}
+
+func (o *WebhooksController) sweepNeedsRebase() {
+	pluginConfig := o.server.Plugins.Config()
+	if pluginConfig == nil || pluginConfig.NeedsRebase.SweepIntervalDuration <= 0 || !o.sweepLeader.IsLeader() {
+		return
+	}
+	orgs, repos := pluginConfig.EnabledReposForPlugin(needsrebase.PluginName)
+	l := logrus.WithField("plugin", needsrebase.PluginName)
+
+	// use a client per owner so that each one uses the right GitHub App token
+	sweep := func(owner string, orgs, repos []string) {
+		scmClient, _, _, _, err := util.GetSCMClient(owner, o.server.ConfigAgent.Config)
+		if err != nil {
+			l.WithError(err).WithField("owner", owner).Error("failed to create SCM client")
+			return
+		}
+		if err := needsrebase.HandleAll(l.WithField("owner", owner), scmClient, pluginConfig, orgs, repos); err != nil {
+			l.WithError(err).WithField("owner", owner).Error("failed to check pull requests for merge conflicts")
+		}
+	}
+	for _, org := range orgs {
+		sweep(org, []string{org}, nil)
+	}
+	for _, repo := range repos {
+		sweep(strings.SplitN(repo, "/", 2)[0], nil, []string{repo})
+	}
+}
@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/pluginhelp"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+)
+
+// PluginHelpPath is the URL path of the reference of the plugins and their commands
+const PluginHelpPath = "/plugin-help"
+
+// PluginHelp serves the reference of the plugins and their commands, generated from the metadata of the registered
+// plugins and the plugin configuration. The 'repo' query parameter restricts it to the plugins enabled for an org/repo.
+// It is served as JSON unless the 'format' query parameter is 'markdown', which renders a table of the commands.
+func (o *WebhooksController) PluginHelp(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+	if repo != "" && len(strings.Split(repo, "/")) != 2 {
+		http.Error(w, fmt.Sprintf("invalid repo %q, it must be of the form org/repo", repo), http.StatusBadRequest)
+		return
+	}
+	help := generatePluginHelp(o.server.Plugins, util.GitKind(o.server.ConfigAgent.Config), repo)
+
+	if r.URL.Query().Get("format") == "markdown" {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		if _, err := w.Write([]byte(commandsMarkdown(help))); err != nil {
+			logrus.WithError(err).Error("failed to write the plugin help")
+		}
+		return
+	}
+	b, err := json.Marshal(help)
+	if err != nil {
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: %s", err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(b); err != nil {
+		logrus.WithError(err).Error("failed to write the plugin help")
+	}
+}
+
+// generatePluginHelp generates the help of the plugins enabled for the given org/repo, or of all the registered
+// plugins if it is empty, along with the repos enabling them
+func generatePluginHelp(pa *plugins.ConfigAgent, provider, fullName string) *pluginhelp.Help {
+	cfg := pa.Config()
+	help := &pluginhelp.Help{
+		RepoPlugins: map[string][]string{},
+		PluginHelp:  map[string]pluginhelp.PluginHelp{},
+	}
+
+	providers := plugins.HelpProviders()
+	var names []string
+	if fullName != "" {
+		parts := strings.Split(fullName, "/")
+		for name := range pa.GetPlugins(parts[0], parts[1], provider) {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		help.AllRepos = []string{fullName}
+		help.RepoPlugins[fullName] = names
+	} else {
+		for name := range providers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		help.RepoPlugins[""] = names
+		for repo, enabled := range cfg.Plugins {
+			help.RepoPlugins[repo] = enabled
+			if strings.Contains(repo, "/") {
+				help.AllRepos = append(help.AllRepos, repo)
+			}
+		}
+		sort.Strings(help.AllRepos)
+	}
+
+	for _, name := range names {
+		enabledRepos := []string{fullName}
+		if fullName == "" {
+			orgs, repos := cfg.EnabledReposForPlugin(name)
+			enabledRepos = append(orgs, repos...)
+			sort.Strings(enabledRepos)
+		}
+		h, err := providers[name](cfg, enabledRepos)
+		if err != nil {
+			logrus.WithError(err).WithField("plugin", name).Warn("failed to generate the configuration help of the plugin")
+		}
+		if h != nil {
+			help.PluginHelp[name] = *h
+		}
+	}
+	return help
+}
+
+// commandsMarkdown renders the commands of the plugins of the help as a markdown table
+func commandsMarkdown(help *pluginhelp.Help) string {
+	var names []string
+	for name := range help.PluginHelp {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	escape := func(s string) string {
+		return strings.Replace(strings.Replace(s, "|", "\\|", -1), "\n", " ", -1)
+	}
+	var sb strings.Builder
+	sb.WriteString("| Plugin | Command | Description | Who can use | Examples |\n")
+	sb.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, name := range names {
+		for _, c := range help.PluginHelp[name].Commands {
+			var examples []string
+			for _, e := range c.Examples {
+				examples = append(examples, "`"+escape(e)+"`")
+			}
+			sb.WriteString(fmt.Sprintf("| %s | `%s` | %s | %s | %s |\n", name, escape(c.Usage), escape(c.Description), escape(c.WhoCanUse), strings.Join(examples, ", ")))
+		}
+	}
+	return sb.String()
+}
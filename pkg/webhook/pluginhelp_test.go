@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/pluginhelp"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginHelp(t *testing.T) {
+	configAgent := &config.Agent{}
+	configAgent.Set(&config.Config{})
+	pluginAgent := &plugins.ConfigAgent{}
+	pluginAgent.Set(&plugins.Configuration{
+		Plugins: map[string][]string{
+			"org":       {"help"},
+			"org/repo":  {"lgtm"},
+			"other/foo": {"hold"},
+		},
+	})
+	o := &WebhooksController{server: &Server{ConfigAgent: configAgent, Plugins: pluginAgent}}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		o.PluginHelp(rec, httptest.NewRequest(http.MethodGet, PluginHelpPath+query, nil))
+		return rec
+	}
+
+	rec := get("")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var help pluginhelp.Help
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &help))
+	assert.Equal(t, []string{"org/repo", "other/foo"}, help.AllRepos)
+	assert.Contains(t, help.RepoPlugins[""], "why")
+	assert.Equal(t, []string{"lgtm"}, help.RepoPlugins["org/repo"])
+	assert.Contains(t, help.PluginHelp, "cat")
+
+	rec = get("?repo=org/repo")
+	require.Equal(t, http.StatusOK, rec.Code)
+	help = pluginhelp.Help{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &help))
+	assert.Equal(t, []string{"org/repo"}, help.AllRepos)
+	assert.Equal(t, map[string][]string{"org/repo": {"help", "lgtm"}}, help.RepoPlugins)
+	assert.Len(t, help.PluginHelp, 2)
+	require.NotEmpty(t, help.PluginHelp["lgtm"].Commands)
+
+	rec = get("?repo=org/repo&format=markdown")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "| Plugin | Command | Description | Who can use | Examples |\n")
+	assert.Contains(t, rec.Body.String(), "| help | `/[lh-][remove-]help\\|good-first-issue` |")
+	assert.Contains(t, rec.Body.String(), "| lgtm | ")
+	assert.NotContains(t, rec.Body.String(), "| hold | ")
+
+	rec = get("?repo=org")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -25,7 +25,9 @@ import (
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/branchcleaner"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/cat"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/cherrypickunapproved"
+	_ "github.com/jenkins-x/lighthouse/pkg/plugins/dco"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/dog"
+	_ "github.com/jenkins-x/lighthouse/pkg/plugins/freeze"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/help"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/hold"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/label"
@@ -33,9 +35,12 @@ import (
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/lifecycle"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/milestone"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/milestonestatus"
+	_ "github.com/jenkins-x/lighthouse/pkg/plugins/needsrebase"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/override"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/owners-label"
+	_ "github.com/jenkins-x/lighthouse/pkg/plugins/policy"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/pony"
+	_ "github.com/jenkins-x/lighthouse/pkg/plugins/releasenote"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/shrug"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/sigmention"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/size"
@@ -44,6 +49,7 @@ import (
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/trigger"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/updateconfig"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/welcome"
+	_ "github.com/jenkins-x/lighthouse/pkg/plugins/why"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/wip"
 	_ "github.com/jenkins-x/lighthouse/pkg/plugins/yuks"
 )
@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/events"
+	"github.com/sirupsen/logrus"
+)
+
+// publishMerged publishes the merge of the pull request of the hook, if it was merged. It is published in the
+// background so that the plugins handle the hook even while the message bus is down, as the SCM providers do not
+// deliver the hook again. The server waits for it when shutting down.
+func (s *Server) publishMerged(l *logrus.Entry, hook *scm.PullRequestHook) {
+	pr := hook.PullRequest
+	if !pr.Merged || (hook.Action != scm.ActionClose && hook.Action != scm.ActionMerge) || !events.Enabled() {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if err := events.Publish(context.Background(), mergedEvent(hook)); err != nil {
+			l.WithError(err).Error("failed to publish the merge of the pull request")
+		}
+	}()
+}
+
+// mergedEvent returns the event of the merge of the pull request of the hook
+func mergedEvent(hook *scm.PullRequestHook) events.Event {
+	pr := hook.PullRequest
+	repo := hook.Repository()
+	sha := pr.MergeSha
+	if sha == "" {
+		sha = pr.Sha
+	}
+	return events.Event{
+		ID:      fmt.Sprintf("%s#%d.%s", repo.FullName, pr.Number, events.PullRequestMerged),
+		Type:    events.PullRequestMerged,
+		Org:     repo.Namespace,
+		Repo:    repo.Name,
+		Number:  pr.Number,
+		BaseRef: pr.Base.Ref,
+		SHA:     sha,
+		Author:  pr.Author.Login,
+	}
+}
@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/events"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type blockingPublisher struct {
+	release   chan struct{}
+	lock      sync.Mutex
+	published []events.Event
+}
+
+func (p *blockingPublisher) Publish(_ context.Context, event events.Event) error {
+	<-p.release
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.published = append(p.published, event)
+	return nil
+}
+
+func TestPublishMergedDoesNotBlockTheHook(t *testing.T) {
+	publisher := &blockingPublisher{release: make(chan struct{})}
+	events.SetPublishers("webhooks", publisher)
+	defer events.SetPublishers("")
+
+	s := &Server{}
+	hook := &scm.PullRequestHook{
+		Action: scm.ActionClose,
+		Repo:   scm.Repository{Namespace: "org", Name: "repo", FullName: "org/repo"},
+		PullRequest: scm.PullRequest{
+			Number:   12,
+			Merged:   true,
+			MergeSha: "merge-sha",
+			Base:     scm.PullRequestBranch{Ref: "master"},
+			Author:   scm.User{Login: "alice"},
+		},
+	}
+	// returns while the message bus does not acknowledge the event
+	s.publishMerged(logrus.WithField("test", t.Name()), hook)
+	close(publisher.release)
+	s.wg.Wait()
+
+	if assert.Len(t, publisher.published, 1) {
+		event := publisher.published[0]
+		assert.Equal(t, "org/repo#12.pullrequest.merged", event.ID)
+		assert.Equal(t, "merge-sha", event.SHA)
+		assert.Equal(t, "alice", event.Author)
+	}
+
+	// pull requests closed without being merged are not published
+	hook.PullRequest.Merged = false
+	s.publishMerged(logrus.WithField("test", t.Name()), hook)
+	s.wg.Wait()
+	assert.Len(t, publisher.published, 1)
+}
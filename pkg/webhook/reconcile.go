@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/lighthouse/pkg/interrupts"
+	"github.com/jenkins-x/lighthouse/pkg/plugins/trigger"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+)
+
+// sweepLease is the name of the Lease electing the replica running the periodic sweeps of the pull requests
+const sweepLease = "lighthouse-webhooks-sweeps"
+
+// startStatusReconcile periodically reconciles the statuses of the open pull requests of the repositories the trigger
+// plugin is enabled for, as their jobs are not triggered if a webhook is not delivered or Lighthouse is down. Only the
+// elected replica reconciles them so that the jobs are not triggered once per replica.
+func (o *WebhooksController) startStatusReconcile() {
+	interrupts.Tick(o.reconcileStatuses, func() time.Duration {
+		if cfg := o.server.Plugins.Config(); cfg != nil && cfg.StatusReconcile.SweepIntervalDuration > 0 {
+			return cfg.StatusReconcile.SweepIntervalDuration
+		}
+		// check again later in case the sweep gets enabled
+		return time.Hour
+	})
+}
+
+func (o *WebhooksController) reconcileStatuses() {
+	pluginConfig := o.server.Plugins.Config()
+	if pluginConfig == nil || pluginConfig.StatusReconcile.SweepIntervalDuration <= 0 || !o.sweepLeader.IsLeader() {
+		return
+	}
+	orgs, repos := pluginConfig.EnabledReposForPlugin(trigger.PluginName)
+	l := logrus.WithField("plugin", trigger.PluginName)
+
+	// use a client per owner so that each one uses the right GitHub App token
+	sweep := func(owner string, orgs, repos []string) {
+		scmClient, _, _, _, err := util.GetSCMClient(owner, o.server.ConfigAgent.Config)
+		if err != nil {
+			l.WithError(err).WithField("owner", owner).Error("failed to create SCM client")
+			return
+		}
+		c := trigger.Client{
+			SCMProviderClient: scmClient,
+			LauncherClient:    o.launcher,
+			Config:            o.server.ConfigAgent.Config(),
+			Logger:            l.WithField("owner", owner),
+			BaseSHAs:          o.baseSHAs,
+		}
+		if err := trigger.ReconcileAll(c, scmClient, o.jobs, pluginConfig, orgs, repos); err != nil {
+			l.WithError(err).WithField("owner", owner).Error("failed to reconcile the statuses of pull requests")
+		}
+	}
+	for _, org := range orgs {
+		sweep(org, []string{org}, nil)
+	}
+	for _, repo := range repos {
+		sweep(strings.SplitN(repo, "/", 2)[0], nil, []string{repo})
+	}
+}
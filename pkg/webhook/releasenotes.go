@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jenkins-x/lighthouse/pkg/admin"
+	"github.com/jenkins-x/lighthouse/pkg/plugins/releasenote"
+	"github.com/jenkins-x/lighthouse/pkg/util"
+	"github.com/sirupsen/logrus"
+)
+
+// ReleaseNotesPath is the URL path of the release notes of the pull requests merged between two refs
+const ReleaseNotesPath = "/release-notes"
+
+// ReleaseNotes serves the release notes of the pull requests of the 'repo' query parameter merged after the 'from' ref
+// up to the 'to' ref, e.g. two tags, for generating changelogs. The release-note plugin must be enabled for the repo.
+// They are served as JSON unless the 'format' query parameter is 'markdown', which renders a changelog. As they may
+// reveal private pull requests and cost many SCM requests, the requests must carry the admin token as a bearer token.
+func (o *WebhooksController) ReleaseNotes(w http.ResponseWriter, r *http.Request) {
+	if !admin.Authorized(r, util.AdminToken()) {
+		http.Error(w, "401 Unauthorized: invalid or missing bearer token", http.StatusUnauthorized)
+		return
+	}
+	query := r.URL.Query()
+	fullName, from, to := query.Get("repo"), query.Get("from"), query.Get("to")
+	parts := strings.Split(fullName, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		http.Error(w, fmt.Sprintf("invalid repo %q, it must be of the form org/repo", fullName), http.StatusBadRequest)
+		return
+	}
+	if from == "" || to == "" {
+		http.Error(w, "the from and to refs are required", http.StatusBadRequest)
+		return
+	}
+	org, repo := parts[0], parts[1]
+	if !o.releaseNotesEnabled(org, repo) {
+		http.Error(w, fmt.Sprintf("the %s plugin is not enabled for %s", releasenote.PluginName, fullName), http.StatusNotFound)
+		return
+	}
+
+	scmClient, _, _, _, err := util.GetSCMClient(org, o.server.ConfigAgent.Config)
+	if err != nil {
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: failed to create SCM client: %s", err.Error()))
+		return
+	}
+	notes, err := releasenote.Notes(scmClient, org, repo, from, to)
+	if err != nil {
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: %s", err.Error()))
+		return
+	}
+
+	if query.Get("format") == "markdown" {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		if _, err := w.Write([]byte(releasenote.Markdown(notes))); err != nil {
+			logrus.WithError(err).Error("failed to write the release notes")
+		}
+		return
+	}
+	if notes == nil {
+		notes = []releasenote.Note{}
+	}
+	b, err := json.Marshal(notes)
+	if err != nil {
+		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: %s", err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(b); err != nil {
+		logrus.WithError(err).Error("failed to write the release notes")
+	}
+}
+
+func (o *WebhooksController) releaseNotesEnabled(org, repo string) bool {
+	pluginConfig := o.server.Plugins.Config()
+	if pluginConfig == nil {
+		return false
+	}
+	orgs, repos := pluginConfig.EnabledReposForPlugin(releasenote.PluginName)
+	for _, enabled := range orgs {
+		if enabled == org {
+			return true
+		}
+	}
+	for _, enabled := range repos {
+		if enabled == org+"/"+repo {
+			return true
+		}
+	}
+	return false
+}
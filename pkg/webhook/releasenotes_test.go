@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/jenkins-x/lighthouse/pkg/config"
+	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleaseNotesRequiresAdminToken(t *testing.T) {
+	configAgent := &config.Agent{}
+	configAgent.Set(&config.Config{})
+	pluginAgent := &plugins.ConfigAgent{}
+	pluginAgent.Set(&plugins.Configuration{Plugins: map[string][]string{"org/repo": {"lgtm"}}})
+	o := &WebhooksController{server: &Server{ConfigAgent: configAgent, Plugins: pluginAgent}}
+
+	get := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, ReleaseNotesPath+"?repo=org/repo&from=v1.0.0&to=v1.1.0", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		o.ReleaseNotes(rec, req)
+		return rec.Code
+	}
+
+	old := os.Getenv("ADMIN_TOKEN")
+	defer os.Setenv("ADMIN_TOKEN", old)
+
+	os.Unsetenv("ADMIN_TOKEN")
+	assert.Equal(t, http.StatusUnauthorized, get(""), "no admin token configured")
+	assert.Equal(t, http.StatusUnauthorized, get("secret"), "no admin token configured")
+
+	os.Setenv("ADMIN_TOKEN", "secret")
+	assert.Equal(t, http.StatusUnauthorized, get(""), "missing token")
+	assert.Equal(t, http.StatusUnauthorized, get("wrong"), "wrong token")
+	assert.Equal(t, http.StatusNotFound, get("secret"), "plugin not enabled")
+}
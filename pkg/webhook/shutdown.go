@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/jenkins-x/lighthouse/pkg/interrupts"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Shutdown stops accepting webhooks, which are then answered with 503 Service Unavailable so that the SCM provider
+// delivers them again, e.g. to another replica, and waits for the webhooks being processed and for the plugin handlers
+// and external plugin calls they started to finish, so that the statuses, labels and comments they report are not
+// lost. It gives up waiting when the context is done.
+func (o *WebhooksController) Shutdown(ctx context.Context) error {
+	o.shutdownLock.Lock()
+	o.shuttingDown = true
+	o.shutdownLock.Unlock()
+
+	logrus.Info("no longer accepting webhooks, waiting for the in-flight events to be processed")
+	if err := interrupts.Drain(ctx, &o.server.wg); err != nil {
+		return errors.Wrap(err, "failed to wait for the in-flight events to be processed")
+	}
+	logrus.Info("all in-flight events processed")
+	return nil
+}
+
+// isShuttingDown returns whether webhooks are no longer accepted
+func (o *WebhooksController) isShuttingDown() bool {
+	o.shutdownLock.RLock()
+	defer o.shutdownLock.RUnlock()
+	return o.shuttingDown
+}
+
+// startEvent registers an event being processed so that shutting down waits for it, or answers 503 and returns false
+// when shutting down
+func (o *WebhooksController) startEvent(w http.ResponseWriter) bool {
+	o.shutdownLock.RLock()
+	defer o.shutdownLock.RUnlock()
+	if o.shuttingDown {
+		responseHTTPError(w, http.StatusServiceUnavailable, "503 Service Unavailable: shutting down")
+		return false
+	}
+	// registered under the lock so that it happens before Shutdown starts waiting
+	o.server.wg.Add(1)
+	return true
+}
+
+// finishEvent marks an event registered by startEvent as processed
+func (o *WebhooksController) finishEvent() {
+	o.server.wg.Done()
+}
@@ -0,0 +1,39 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdown(t *testing.T) {
+	o := &WebhooksController{path: "/hook", server: &Server{}}
+
+	w := httptest.NewRecorder()
+	o.Ready(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	// an event being processed when shutting down
+	require.True(t, o.startEvent(httptest.NewRecorder()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Error(t, o.Shutdown(ctx), "the in-flight event is not processed before the deadline")
+
+	w = httptest.NewRecorder()
+	o.HandleWebhookRequests(w, httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader("{}")))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "new webhooks are rejected so that they are delivered again")
+
+	w = httptest.NewRecorder()
+	o.Ready(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	go o.finishEvent()
+	assert.NoError(t, o.Shutdown(context.Background()))
+}
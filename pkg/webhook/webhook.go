@@ -7,19 +7,25 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/lighthouse/pkg/basesha"
+	lighthouseclient "github.com/jenkins-x/lighthouse/pkg/client/clientset/versioned/typed/lighthouse/v1alpha1"
 	"github.com/jenkins-x/lighthouse/pkg/clients"
 	"github.com/jenkins-x/lighthouse/pkg/config"
 	"github.com/jenkins-x/lighthouse/pkg/git"
 	"github.com/jenkins-x/lighthouse/pkg/launcher"
+	"github.com/jenkins-x/lighthouse/pkg/leaderelection"
 	"github.com/jenkins-x/lighthouse/pkg/metrics"
 	"github.com/jenkins-x/lighthouse/pkg/plugins"
+	"github.com/jenkins-x/lighthouse/pkg/scmprovider"
 	"github.com/jenkins-x/lighthouse/pkg/util"
 	"github.com/jenkins-x/lighthouse/pkg/version"
 	"github.com/jenkins-x/lighthouse/pkg/watcher"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
 )
 
 // WebhooksController holds the command line arguments
@@ -35,6 +41,17 @@ type WebhooksController struct {
 	gitServerURL   string
 	gitClient      git.Client
 	launcher       launcher.PipelineLauncher
+	jobs           lighthouseclient.LighthouseJobInterface
+	kubeClient     kubernetes.Interface
+	deliveries     *deliveryCache
+	sharedStore    *deliveryStore
+	baseSHAs       *basesha.Resolver
+	// sweepLeader tells whether this replica runs the periodic sweeps, which must only run on one replica
+	sweepLeader *leaderelection.Leadership
+
+	// shutdownLock guards shuttingDown, which is set once webhooks are no longer accepted
+	shutdownLock sync.RWMutex
+	shuttingDown bool
 }
 
 // NewWebhooksController creates and configures the controller
@@ -45,6 +62,8 @@ func NewWebhooksController(path, namespace, botName, pluginFilename, configFilen
 		pluginFilename: pluginFilename,
 		configFilename: configFilename,
 		botName:        botName,
+		deliveries:     newDeliveryCache(defaultDeliveryTTL, defaultMaxDeliveries),
+		baseSHAs:       basesha.NewResolver(basesha.DefaultTTL),
 	}
 	var err error
 	o.server, err = o.createHookServer()
@@ -60,11 +79,21 @@ func NewWebhooksController(path, namespace, botName, pluginFilename, configFilen
 	}
 	o.gitClient = gitClient
 
-	_, _, lhClient, _, err := clients.GetAPIClients()
+	_, kubeClient, lhClient, _, err := clients.GetAPIClients()
 	if err != nil {
 		return nil, errors.Wrap(err, "Error creating kubernetes resource clients.")
 	}
-	o.launcher = launcher.NewLauncher(lhClient, o.namespace)
+	o.kubeClient = kubeClient
+	o.launcher = launcher.NewPausingLauncher(launcher.NewLauncher(lhClient, o.namespace), cfg)
+	o.jobs = lhClient.LighthouseV1alpha1().LighthouseJobs(o.namespace)
+	o.sharedStore = newDeliveryStore(kubeClient.CoordinationV1().Leases(o.namespace))
+	o.sweepLeader, err = leaderelection.Track(kubeClient, o.namespace, sweepLease)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to elect the replica running the sweeps")
+	}
+	o.startNeedsRebaseSweep()
+	o.startStatusReconcile()
+	o.startDeliverySweep()
 
 	return o, nil
 }
@@ -83,7 +112,7 @@ func (o *WebhooksController) Health(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// Ready returns either HTTP 204 if the service is Ready to serve requests, otherwise HTTP 503.
+// Ready returns either HTTP 204 if the service is Ready to serve requests, otherwise HTTP 503, e.g. when shutting down.
 func (o *WebhooksController) Ready(w http.ResponseWriter, r *http.Request) {
 	logrus.Debug("Ready check")
 	if o.isReady() {
@@ -109,7 +138,7 @@ func (o *WebhooksController) DefaultHandler(w http.ResponseWriter, r *http.Reque
 
 func (o *WebhooksController) isReady() bool {
 	// TODO a better readiness check
-	return true
+	return !o.isShuttingDown()
 }
 
 // HandleWebhookRequests handles incoming events
@@ -119,6 +148,10 @@ func (o *WebhooksController) HandleWebhookRequests(w http.ResponseWriter, r *htt
 		logrus.WithField("method", r.Method).Debug("invalid http method so returning 200")
 		return
 	}
+	if !o.startEvent(w) {
+		return
+	}
+	defer o.finishEvent()
 	logrus.Debug("about to parse webhook")
 
 	cfg := o.server.ConfigAgent.Config
@@ -159,10 +192,31 @@ func (o *WebhooksController) HandleWebhookRequests(w http.ResponseWriter, r *htt
 		return
 	}
 
+	o.handleWebhook(w, cfg, scmClient, serverURL, webhook, deliveryID(r))
+}
+
+// handleWebhook processes a parsed webhook unless it is a duplicate delivery, and writes the response
+func (o *WebhooksController) handleWebhook(w http.ResponseWriter, cfg config.Getter, scmClient *scm.Client, serverURL string, webhook scm.Webhook, delivery string) {
+	// rejected before recording the delivery so that it is processed when the SCM provider delivers it again
+	if o.server.pool != nil && o.server.pool.full() {
+		rejectedWebhookCounter.Inc()
+		responseHTTPError(w, http.StatusServiceUnavailable, "503 Service Unavailable: too many events queued")
+		return
+	}
+	if o.isDuplicateDelivery(delivery, cfg().WebhookDedupe) {
+		logrus.WithField("DeliveryID", delivery).Info("ignoring duplicate webhook delivery")
+		_, err := w.Write([]byte(fmt.Sprintf("ignored duplicate delivery %s", delivery)))
+		if err != nil {
+			logrus.Debugf("failed to write the response: %v", err)
+		}
+		return
+	}
+
 	ghaSecretDir := util.GetGitHubAppSecretDir()
 
 	var gitCloneUser string
 	var token string
+	var err error
 	if ghaSecretDir != "" {
 		gitCloneUser = util.GitHubAppGitRemoteUsername
 		tokenFinder := util.NewOwnerTokensDir(serverURL, ghaSecretDir)
@@ -198,14 +252,17 @@ func (o *WebhooksController) HandleWebhookRequests(w http.ResponseWriter, r *htt
 		GitClient:         o.gitClient,
 		LighthouseClient:  lhClient.LighthouseV1alpha1().LighthouseJobs(o.namespace),
 		LauncherClient:    o.launcher,
+		BaseSHAs:          o.baseSHAs,
 	}
-	l, output, err := o.ProcessWebHook(logrus.WithField("Webhook", webhook.Kind()), webhook)
+	l, output, err := o.ProcessWebHook(logrus.WithFields(logrus.Fields{"Webhook": webhook.Kind(), "DeliveryID": delivery, scmprovider.EventGUID: delivery}), webhook)
 	if err != nil {
+		o.forgetDelivery(delivery)
 		responseHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("500 Internal Server Error: %s", err.Error()))
 	}
-	// Demux events only to external plugins that require this event.
+	// Demux events only to external plugins that require this event. The calls are made asynchronously but are
+	// registered before the event is finished so that shutting down waits for them.
 	if external := util.ExternalPluginsForEvent(o.server.Plugins, string(webhook.Kind()), webhook.Repository().FullName); len(external) > 0 {
-		go util.CallExternalPluginsWithWebhook(l, external, webhook, util.HMACToken(), &o.server.wg)
+		util.CallExternalPluginsWithWebhook(l, external, webhook, util.HMACToken(), &o.server.wg)
 	}
 
 	_, err = w.Write([]byte(output))
@@ -226,23 +283,38 @@ func (o *WebhooksController) ProcessWebHook(l *logrus.Entry, webhook scm.Webhook
 		"Clone":     repository.Clone,
 		"Webhook":   webhook.Kind(),
 	}
-	l = l.WithFields(logrus.Fields(fields))
+	l = l.WithFields(logrus.Fields(fields)).WithFields(logrus.Fields{
+		scmprovider.OrgLogField:  repository.Namespace,
+		scmprovider.RepoLogField: repository.Name,
+	})
 	_, ok := webhook.(*scm.PingHook)
 	if ok {
 		l.Info("received ping")
 		return l, fmt.Sprintf("pong from lighthouse %s", version.Version), nil
 	}
+	if o.server.ConfigAgent != nil {
+		cfg := o.server.ConfigAgent.Config()
+		if cfg != nil && !cfg.RepoEnabled(repository.Namespace, repository.Name) {
+			l.Infof("ignoring webhook from repository %s/%s as it is excluded by the repo_filter", repository.Namespace, repository.Name)
+			return l, fmt.Sprintf("ignored hook from repository %s/%s", repository.Namespace, repository.Name), nil
+		}
+	}
 	// If we are in GitHub App mode and have a populated config, check if the repository for this webhook is one we actually
 	// know about and error out if not.
 	if util.GetGitHubAppSecretDir() != "" && o.server.ConfigAgent != nil {
 		cfg := o.server.ConfigAgent.Config()
 		if cfg != nil {
-			if len(cfg.GetPostsubmits(repository)) == 0 && len(cfg.GetPresubmits(repository)) == 0 {
+			if len(cfg.GetPostsubmits(repository)) == 0 && len(cfg.GetPresubmits(repository)) == 0 && len(cfg.GetReleases(repository)) == 0 && len(cfg.GetDeployments(repository)) == 0 {
 				l.Infof("webhook from unconfigured repository %s, returning error", repository.Link)
 				return l, "", fmt.Errorf("repository not configured: %s", repository.Link)
 			}
 		}
 	}
+	if pushHook, ok := webhook.(*scm.PushHook); ok && pushHook.After == "" {
+		// some drivers, e.g. gitea, only populate the head commit of a push
+		pushHook.After = pushHook.Commit.Sha
+	}
+	o.trackBaseSHA(webhook)
 	pushHook, ok := webhook.(*scm.PushHook)
 	if ok {
 		fields["Ref"] = pushHook.Ref
@@ -269,6 +341,8 @@ func (o *WebhooksController) ProcessWebHook(l *logrus.Entry, webhook scm.Webhook
 		fields["PR.Title"] = pr.Title
 		fields["PR.Body"] = pr.Body
 
+		o.server.publishMerged(l, prHook)
+
 		l.Info("invoking PR handler")
 
 		o.server.handlePullRequestEvent(l, prHook)
@@ -332,6 +406,29 @@ func (o *WebhooksController) ProcessWebHook(l *logrus.Entry, webhook scm.Webhook
 		o.server.handlePullRequestCommentEvent(l, *prCommentHook)
 		return l, "processed PR comment hook", nil
 	}
+	deployHook, ok := webhook.(*scm.DeployHook)
+	if ok {
+		fields["Environment"] = deployHook.Target
+		fields["Task"] = deployHook.Task
+		fields["Ref"] = deployHook.Ref.Name
+		fields["Ref.Sha"] = deployHook.Ref.Sha
+		fields["Sender.Login"] = deployHook.Sender.Login
+
+		l.Info("invoking Deployment handler")
+
+		o.server.handleDeploymentEvent(l, deployHook)
+		return l, "processed deployment hook", nil
+	}
+	if _, ok := webhook.(*scm.DeploymentStatusHook); ok {
+		// the deployment status hook does not include the deployment so pipelines are only triggered by deployment hooks
+		l.Debug("ignoring deployment status hook")
+		return l, "ignored deployment status hook", nil
+	}
+	if _, ok := webhook.(*scm.ReleaseHook); ok {
+		// the release hook does not include the tag of the release so release jobs are triggered by the push of the tag
+		l.Debug("ignoring release hook")
+		return l, "ignored release hook", nil
+	}
 	prReviewHook, ok := webhook.(*scm.ReviewHook)
 	if ok {
 		action := prReviewHook.Action
@@ -58,6 +58,21 @@ func (suite *WebhookTestSuite) TestProcessWebhookPR() {
 	assert.NotNil(t, logrusEntry)
 }
 
+func (suite *WebhookTestSuite) TestProcessWebhookRelease() {
+	t := suite.T()
+
+	webhook := &scm.ReleaseHook{
+		Action: scm.ActionCreate,
+		Repo:   suite.TestRepo,
+	}
+	l := logrus.WithField("test", t.Name())
+	logrusEntry, message, err := suite.WebhookOptions.ProcessWebHook(l, webhook)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ignored release hook", message)
+	assert.NotNil(t, logrusEntry)
+}
+
 func (suite *WebhookTestSuite) TestProcessWebhookPRReview() {
 	t := suite.T()
 
@@ -119,6 +134,27 @@ func (suite *WebhookTestSuite) TestProcessWebhookUnknownRepo() {
 	assert.EqualError(t, err, fmt.Sprintf("repository not configured: %s", unknownRepo.Link))
 }
 
+func (suite *WebhookTestSuite) TestProcessWebhookFilteredRepo() {
+	t := suite.T()
+
+	cfg := suite.WebhookOptions.server.ConfigAgent.Config()
+	origFilter := cfg.RepoFilter
+	defer func() {
+		cfg.RepoFilter = origFilter
+	}()
+	cfg.RepoFilter.Deny = []string{"default/test-*"}
+
+	webhook := &scm.PullRequestHook{
+		Action: scm.ActionCreate,
+		Repo:   suite.TestRepo,
+	}
+	l := logrus.WithField("test", t.Name())
+	_, message, err := suite.WebhookOptions.ProcessWebHook(l, webhook)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ignored hook from repository default/test-repo", message)
+}
+
 func (suite *WebhookTestSuite) SetupSuite() {
 	t := suite.T()
 	configAgent := &config.Agent{}
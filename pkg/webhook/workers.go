@@ -0,0 +1,163 @@
+package webhook
+
+import (
+	"flag"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultWorkers is the default number of plugin handlers running concurrently
+	DefaultWorkers = 64
+	// DefaultQueueSize is the default number of plugin handlers waiting for a worker above which webhooks are rejected
+	DefaultQueueSize = 2000
+)
+
+var (
+	queueDepthGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "lighthouse_webhook_queue_depth",
+		Help: "The number of plugin handlers waiting for a worker.",
+	})
+	busyWorkersGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "lighthouse_webhook_busy_workers",
+		Help: "The number of workers running a plugin handler.",
+	})
+	queueLatencyGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "lighthouse_webhook_queue_latency_seconds",
+		Help: "How long the last plugin handler started waited for a worker.",
+	})
+	processingLatencyGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "lighthouse_webhook_processing_latency_seconds",
+		Help: "How long the last plugin handler finished took to run.",
+	})
+	rejectedWebhookCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "lighthouse_webhook_rejected",
+		Help: "A counter of the webhooks answered with 503 as the queue of plugin handlers was full.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(queueDepthGauge)
+	prometheus.MustRegister(busyWorkersGauge)
+	prometheus.MustRegister(queueLatencyGauge)
+	prometheus.MustRegister(processingLatencyGauge)
+	prometheus.MustRegister(rejectedWebhookCounter)
+}
+
+// WorkerOptions configures the pool of workers running the plugin handlers from command line flags
+type WorkerOptions struct {
+	// Workers is the number of plugin handlers running concurrently
+	Workers int
+	// QueueSize is the number of plugin handlers waiting for a worker above which webhooks are rejected
+	QueueSize int
+}
+
+// AddFlags adds the flags configuring the workers
+func (o *WorkerOptions) AddFlags(fs *flag.FlagSet) {
+	fs.IntVar(&o.Workers, "workers", DefaultWorkers, "The number of plugin handlers running concurrently.")
+	fs.IntVar(&o.QueueSize, "queue-size", DefaultQueueSize, "The number of plugin handlers waiting for a worker above which webhooks are answered with 503 so that the SCM provider delivers them again later.")
+}
+
+// task is a plugin handler waiting for a worker
+type task struct {
+	run      func()
+	queuedAt time.Time
+}
+
+// workerPool runs the plugin handlers on a bounded number of workers. The handlers are queued per repository and the
+// workers take them from the repositories in turn, so that a storm of events on one repository, e.g. an org-wide label
+// sync, does not starve the others.
+type workerPool struct {
+	lock      sync.Mutex
+	cond      *sync.Cond
+	queueSize int
+	queued    int
+	queues    map[string][]task
+	// ready are the repositories with queued handlers, in the order the workers take them
+	ready []string
+}
+
+// newWorkerPool creates a pool and starts its workers
+func newWorkerPool(workers, queueSize int) *workerPool {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+	p := &workerPool{
+		queueSize: queueSize,
+		queues:    map[string][]task{},
+	}
+	p.cond = sync.NewCond(&p.lock)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// full returns whether the queue is full, in which case new events should be rejected. The handlers of an event
+// accepted while the queue is not full are all queued, so the queue can exceed its size by the handlers of an event.
+func (p *workerPool) full() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.queued >= p.queueSize
+}
+
+// submit queues a handler of an event on the repository
+func (p *workerPool) submit(repo string, run func()) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if len(p.queues[repo]) == 0 {
+		p.ready = append(p.ready, repo)
+	}
+	p.queues[repo] = append(p.queues[repo], task{run: run, queuedAt: time.Now()})
+	p.queued++
+	queueDepthGauge.Set(float64(p.queued))
+	p.cond.Signal()
+}
+
+// next waits for a queued handler, taking them from the repositories in turn
+func (p *workerPool) next() task {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for len(p.ready) == 0 {
+		p.cond.Wait()
+	}
+	repo := p.ready[0]
+	p.ready = p.ready[1:]
+	queue := p.queues[repo]
+	t := queue[0]
+	if len(queue) > 1 {
+		p.queues[repo] = queue[1:]
+		p.ready = append(p.ready, repo)
+	} else {
+		delete(p.queues, repo)
+	}
+	p.queued--
+	queueDepthGauge.Set(float64(p.queued))
+	return t
+}
+
+func (p *workerPool) work() {
+	for {
+		t := p.next()
+		start := time.Now()
+		queueLatencyGauge.Set(start.Sub(t.queuedAt).Seconds())
+		busyWorkersGauge.Inc()
+		t.run()
+		busyWorkersGauge.Dec()
+		processingLatencyGauge.Set(time.Since(start).Seconds())
+	}
+}
+
+// StartWorkers runs the plugin handlers on a bounded pool of workers rather than one goroutine each, so that a storm
+// of webhooks does not exhaust the memory. Webhooks received while the queue is full are answered with 503 so that the
+// SCM provider delivers them again later.
+func (o *WebhooksController) StartWorkers(options WorkerOptions) {
+	logrus.WithField("workers", options.Workers).WithField("queueSize", options.QueueSize).Info("starting the plugin workers")
+	o.server.pool = newWorkerPool(options.Workers, options.QueueSize)
+}
@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerPoolFairness(t *testing.T) {
+	p := newWorkerPool(1, 10)
+
+	// block the only worker so that the handlers queue up
+	blocked := make(chan struct{})
+	release := make(chan struct{})
+	p.submit("org/busy", func() {
+		close(blocked)
+		<-release
+	})
+	<-blocked
+
+	var lock sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	record := func(name string) func() {
+		wg.Add(1)
+		return func() {
+			defer wg.Done()
+			lock.Lock()
+			defer lock.Unlock()
+			order = append(order, name)
+		}
+	}
+	p.submit("org/busy", record("busy-1"))
+	p.submit("org/busy", record("busy-2"))
+	p.submit("org/busy", record("busy-3"))
+	p.submit("org/quiet", record("quiet-1"))
+	p.submit("org/other", record("other-1"))
+	assert.False(t, p.full())
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, []string{"busy-1", "quiet-1", "other-1", "busy-2", "busy-3"}, order, "the repositories are taken in turn")
+}
+
+func TestWorkerPoolFull(t *testing.T) {
+	p := newWorkerPool(1, 2)
+
+	blocked := make(chan struct{})
+	release := make(chan struct{})
+	p.submit("org/repo", func() {
+		close(blocked)
+		<-release
+	})
+	<-blocked
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	p.submit("org/repo", wg.Done)
+	assert.False(t, p.full())
+	p.submit("org/repo", wg.Done)
+	assert.True(t, p.full())
+
+	o := &WebhooksController{server: &Server{pool: p}}
+	w := httptest.NewRecorder()
+	o.handleWebhook(w, nil, nil, "", &scm.PingHook{}, "delivery-1")
+	require.Equal(t, http.StatusServiceUnavailable, w.Code, "webhooks are rejected so that they are delivered again")
+
+	close(release)
+	wg.Wait()
+	assert.False(t, p.full())
+}